@@ -0,0 +1,166 @@
+// Package api holds the conventions shared by the HTTP APIs of the services: the routes are served under /v1, the
+// errors are returned in the same JSON envelope, and the unversioned routes of the first releases are kept as
+// deprecated aliases of their /v1 successors.
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// Prefix is the prefix of the routes of the current version of the APIs.
+const Prefix = "/v1"
+
+// Codes of the errors, telling clients how to handle them without parsing the message.
+const (
+	CodeInvalidArgument  = "invalid_argument"
+	CodeUnauthenticated  = "unauthenticated"
+	CodePermissionDenied = "permission_denied"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeTooLarge         = "payload_too_large"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal"
+	CodeBadGateway       = "bad_gateway"
+	CodeUnavailable      = "unavailable"
+)
+
+const localsLegacy = "api_legacy"
+
+// Error is the envelope of the error responses. RequestID is the X-Request-ID of the request, to be quoted when
+// reporting the error, and Details, if any, tells which part of the request is at fault.
+type Error struct {
+	Status    int    `json:"-"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+	Details   any    `json:"details,omitempty"`
+}
+
+// NewError returns an error with the code of the status, to be returned by a handler.
+func NewError(status int, message string) *Error {
+	return &Error{Status: status, Code: CodeForStatus(status), Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithCode sets a more specific code than the one of the status, e.g. payment_denied.
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+	return e
+}
+
+// CodeForStatus returns the code of the errors answered with the status.
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway:
+		return CodeBadGateway
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeInvalidArgument
+}
+
+// ErrorHandler answers the errors returned by the handlers with the envelope: an *Error as is, a *fiber.Error, e.g.
+// from the handlers of the shared modules or for an unknown route, with the code of its status. Any other error is
+// logged and answered with a 500, so internal details are not leaked.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var apiErr *Error
+	var fiberErr *fiber.Error
+	var envelope Error
+	switch {
+	case errors.As(err, &apiErr):
+		envelope = *apiErr
+	case errors.As(err, &fiberErr):
+		envelope = *NewError(fiberErr.Code, fiberErr.Message)
+	default:
+		slog.ErrorContext(c.UserContext(), "error handling request", slog.String("method", c.Method()),
+			slog.String("path", c.Path()), slog.Any("error", err))
+		envelope = *NewError(http.StatusInternalServerError, "internal server error")
+	}
+	envelope.RequestID = RequestID(c)
+
+	return c.Status(envelope.Status).JSON(envelope)
+}
+
+// RequestID returns the ID given to the request by the requestid middleware.
+func RequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	return id
+}
+
+// LegacyAliases serves the unversioned routes as aliases of their /v1 successors, flagged as deprecated with the
+// Deprecation and Link headers. Paths under one of the exceptions, e.g. the web pages, are served as they are.
+func LegacyAliases(exceptions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		if underPath(path, Prefix) {
+			return c.Next()
+		}
+		for _, exception := range exceptions {
+			if underPath(path, exception) {
+				return c.Next()
+			}
+		}
+
+		successor := Prefix + path
+		c.Set("Deprecation", "true")
+		c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		c.Locals(localsLegacy, true)
+		c.Path(successor)
+
+		return c.Next()
+	}
+}
+
+// IsLegacy tells whether the request was made to the deprecated alias of the route, for the few routes whose
+// responses changed in /v1.
+func IsLegacy(c *fiber.Ctx) bool {
+	legacy, _ := c.Locals(localsLegacy).(bool)
+	return legacy
+}
+
+func underPath(path string, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// Spec serves the OpenAPI document of the service.
+func Spec(document []byte) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "application/yaml")
+		return c.Send(document)
+	}
+}
@@ -0,0 +1,140 @@
+package api_test
+
+import (
+	"errors"
+	"flarrocca/api"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	app.Use(requestid.New(requestid.Config{Generator: func() string { return "req-1" }}))
+	app.Use(api.LegacyAliases("/report"))
+
+	app.Get("/report", func(c *fiber.Ctx) error {
+		return c.SendString("report page")
+	})
+	v1 := app.Group(api.Prefix)
+	v1.Get("/cases/:id", func(c *fiber.Ctx) error {
+		switch c.Params("id") {
+		case "1":
+			return c.JSON(fiber.Map{"id": 1, "legacy": api.IsLegacy(c)})
+		case "404":
+			return fiber.NewError(http.StatusNotFound, "case not found")
+		case "409":
+			return api.NewError(http.StatusConflict, "case is closed").WithDetails(fiber.Map{"status": "closed"})
+		case "denied":
+			return api.NewError(http.StatusForbidden, "payment denied").WithCode("payment_denied")
+		}
+		return errors.New("database is locked")
+	})
+
+	return app
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Success - No error",
+			path:       "/v1/cases/1",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":1,"legacy":false}`,
+		},
+		{
+			name:       "Failure - Fiber error",
+			path:       "/v1/cases/404",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":"not_found","message":"case not found","request_id":"req-1"}`,
+		},
+		{
+			name:       "Failure - Error with details",
+			path:       "/v1/cases/409",
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"code":"conflict","message":"case is closed","request_id":"req-1","details":{"status":"closed"}}`,
+		},
+		{
+			name:       "Failure - Error with a specific code",
+			path:       "/v1/cases/denied",
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"code":"payment_denied","message":"payment denied","request_id":"req-1"}`,
+		},
+		{
+			name:       "Failure - Internal error not leaked",
+			path:       "/v1/cases/500",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":"internal","message":"internal server error","request_id":"req-1"}`,
+		},
+		{
+			name:       "Failure - Unknown route",
+			path:       "/v1/disputes",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":"not_found","message":"Cannot GET /v1/disputes","request_id":"req-1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newTestApp().Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tt.wantBody, string(body))
+		})
+	}
+}
+
+func TestLegacyAliases(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		wantBody       string
+		wantDeprecated bool
+	}{
+		{
+			name:           "Success - Alias of the /v1 route",
+			path:           "/cases/1",
+			wantBody:       `{"id":1,"legacy":true}`,
+			wantDeprecated: true,
+		},
+		{
+			name:     "Success - /v1 route",
+			path:     "/v1/cases/1",
+			wantBody: `{"id":1,"legacy":false}`,
+		},
+		{
+			name:     "Success - Exception",
+			path:     "/report",
+			wantBody: "report page",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newTestApp().Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tt.wantBody, string(body))
+			if tt.wantDeprecated {
+				assert.Equal(t, "true", resp.Header.Get("Deprecation"))
+				assert.Equal(t, `</v1/cases/1>; rel="successor-version"`, resp.Header.Get("Link"))
+			} else {
+				assert.Empty(t, resp.Header.Get("Deprecation"))
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package auth authenticates the calls between the services with short-lived JWTs. A client signs its own tokens, with
+// its Ed25519 private key or its own secret, for the scopes it needs; the called service checks the signature with the
+// key of the client, the audience and the lifetime of the token, and that the client is allowed the scope of the route.
+package auth
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ScopeComplianceCheck allows the compliance checks and the blocked card snapshot.
+	ScopeComplianceCheck = "compliance:check"
+	// ScopeComplianceCases allows opening a case for a card, e.g. after a chargeback.
+	ScopeComplianceCases = "compliance:cases"
+	// ScopeComplianceEvents allows following the events of compliance-service.
+	ScopeComplianceEvents = "compliance:events"
+	// ScopeComplianceAdmin allows the back-office routes: lists, imports, screening, risk ratings, KYC reviews, cases,
+	// reinstatements and webhooks.
+	ScopeComplianceAdmin = "compliance:admin"
+	// ScopeCompliancePII allows reading the decrypted ID documents and the Suspicious Activity Reports.
+	ScopeCompliancePII = "compliance:pii"
+
+	// ScopePaymentNotify allows notifying payment-service of the reported cards and invalidating its cached verdicts.
+	ScopePaymentNotify = "payment:notify"
+	// ScopePaymentRead allows reading the payments of a user, for the case exports of compliance-service.
+	ScopePaymentRead = "payment:read"
+	// ScopePaymentAdmin allows the back-office routes of payment-service: payment reviews, alerts, disputes, the
+	// compliance cache statistics and webhooks.
+	ScopePaymentAdmin = "payment:admin"
+
+	// AudienceCompliance is the audience of the tokens accepted by compliance-service.
+	AudienceCompliance = "compliance-service"
+	// AudiencePayment is the audience of the tokens accepted by payment-service.
+	AudiencePayment = "payment-service"
+
+	// LocalsSubject is the key of the subject of the token in the locals of an authenticated request.
+	LocalsSubject = "auth_subject"
+
+	defaultTokenTTL    = 5 * time.Minute
+	defaultMaxTokenTTL = time.Hour
+)
+
+// privilegedScopes may only be granted by the clients signing with an Ed25519 key: whoever holds a secret can sign any
+// token with it, the verifying service included.
+var privilegedScopes = []string{ScopeComplianceAdmin, ScopeCompliancePII, ScopePaymentAdmin}
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+// Claims are the claims of the tokens. The issuer is the client signing the token, e.g. payment-service or the admin
+// tooling, and the subject who the call is made for, e.g. the compliance officer using the tooling. Scope holds the
+// space-separated scopes granted.
+type Claims struct {
+	Scope string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+func (c Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+func (c Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,103 @@
+// authtool generates the Ed25519 keys of the clients and issues tokens for the admin tooling, e.g.:
+//
+//	authtool keygen -client payment-service -out ./keys
+//	AUTH_JWT_PRIVATE_KEY=./keys/admin.pem authtool token -client admin -subject alice -scope "compliance:admin compliance:pii"
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"flarrocca/auth"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: authtool keygen|token [flags]")
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "token":
+		err = token(os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command %q, available commands: keygen, token", os.Args[1])
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// keygen writes the private key of the client to <client>.pem, to be set in AUTH_JWT_PRIVATE_KEY of the client, and
+// its public key to <client>.pub, to be copied to AUTH_JWT_PUBLIC_KEYS_DIR of the services it calls.
+func keygen(args []string) error {
+	flags := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	client := flags.String("client", "", "name of the client, the issuer of its tokens")
+	out := flags.String("out", ".", "directory the keys are written to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *client == "" {
+		return errors.New("-client is required")
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	privatePath := filepath.Join(*out, *client+".pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		return err
+	}
+	publicPath := filepath.Join(*out, *client+".pub")
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("private key written to %s, public key to %s\n", privatePath, publicPath)
+
+	return nil
+}
+
+// token prints a token signed with AUTH_JWT_PRIVATE_KEY or AUTH_JWT_SECRET.
+func token(args []string) error {
+	flags := flag.NewFlagSet("token", flag.ContinueOnError)
+	client := flags.String("client", "admin", "client issuing the token, allowed the scopes in the auth clients file")
+	subject := flags.String("subject", "", "who the calls are made for, e.g. the compliance officer; the client by default")
+	scope := flags.String("scope", auth.ScopeComplianceAdmin, "space-separated scopes")
+	audience := flags.String("audience", auth.AudienceCompliance, "service the token is sent to")
+	ttl := flags.Duration("ttl", 15*time.Minute, "lifetime of the token")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *subject == "" {
+		*subject = *client
+	}
+
+	signed, err := auth.IssueToken(*client, *subject, *audience, strings.Fields(*scope), *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(signed)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequireScope lets a request through when its bearer token grants the scope, keeping the subject of the token in the
+// locals under LocalsSubject. A nil verifier lets every request through.
+func RequireScope(verifier Verifier, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if verifier == nil {
+			return c.Next()
+		}
+
+		token, found := bearerToken(c.Get(fiber.HeaderAuthorization))
+		if !found {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer scope="`+scope+`"`)
+			return fiber.NewError(fiber.StatusUnauthorized, "a bearer token is required")
+		}
+
+		claims, err := verifier.Verify(token, scope)
+		if err != nil {
+			if errors.Is(err, ErrForbidden) {
+				c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				return fiber.NewError(fiber.StatusForbidden, err.Error())
+			}
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+
+		c.Locals(LocalsSubject, claims.Subject)
+		return c.Next()
+	}
+}
+
+// Subject returns the subject of the token of the request, or an empty string when authentication is disabled.
+func Subject(c *fiber.Ctx) string {
+	subject, _ := c.Locals(LocalsSubject).(string)
+	return subject
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// UnaryServerInterceptor requires a bearer token in the authorization metadata granting the scope of the method, given
+// by full method name. Methods missing from scopes are denied. A nil verifier lets every call through.
+func UnaryServerInterceptor(verifier Verifier, scopes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if verifier == nil {
+			return handler(ctx, req)
+		}
+
+		scope, found := scopes[info.FullMethod]
+		if !found {
+			return nil, status.Errorf(codes.PermissionDenied, "no scope grants %s", info.FullMethod)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		authorization := md.Get("authorization")
+		if len(authorization) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "a bearer token is required")
+		}
+		token, found := bearerToken(authorization[0])
+		if !found {
+			return nil, status.Error(codes.Unauthenticated, "a bearer token is required")
+		}
+
+		if _, err := verifier.Verify(token, scope); err != nil {
+			if errors.Is(err, ErrForbidden) {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+type tokenCredentials struct {
+	tokenSource TokenSource
+}
+
+// PerRPCCredentials sends a token of the source with every gRPC call. Transport security is not required, as the
+// services call each other over the internal network.
+func PerRPCCredentials(tokenSource TokenSource) credentials.PerRPCCredentials {
+	return tokenCredentials{tokenSource: tokenSource}
+}
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := t.tokenSource.Token()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
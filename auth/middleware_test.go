@@ -0,0 +1,180 @@
+package auth_test
+
+import (
+	"context"
+	"flarrocca/auth"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeVerifier accepts the tokens it knows, granting their scopes to a subject.
+type fakeVerifier map[string]auth.Claims
+
+func (f fakeVerifier) Verify(token string, scope string) (auth.Claims, error) {
+	claims, found := f[token]
+	if !found {
+		return auth.Claims{}, fmt.Errorf("%w: invalid token", auth.ErrUnauthenticated)
+	}
+	if !claims.HasScope(scope) {
+		return auth.Claims{}, fmt.Errorf("%w: scope %s is required", auth.ErrForbidden, scope)
+	}
+	return claims, nil
+}
+
+func newFakeVerifier() fakeVerifier {
+	officer := auth.Claims{Scope: auth.ScopeComplianceAdmin}
+	officer.Subject = "alice"
+	payment := auth.Claims{Scope: auth.ScopeComplianceCheck}
+	payment.Subject = "payment-service"
+	return fakeVerifier{"officer-token": officer, "payment-token": payment}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name          string
+		verifier      auth.Verifier
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		{
+			name:          "Success - Scope granted",
+			verifier:      newFakeVerifier(),
+			authorization: "Bearer officer-token",
+			wantStatus:    fiber.StatusOK,
+			wantBody:      "alice",
+		},
+		{
+			name:          "Success - Scheme is case insensitive",
+			verifier:      newFakeVerifier(),
+			authorization: "bearer officer-token",
+			wantStatus:    fiber.StatusOK,
+			wantBody:      "alice",
+		},
+		{
+			name:       "Success - Authentication disabled",
+			verifier:   nil,
+			wantStatus: fiber.StatusOK,
+			wantBody:   "",
+		},
+		{
+			name:       "Fail - Missing token",
+			verifier:   newFakeVerifier(),
+			wantStatus: fiber.StatusUnauthorized,
+			wantBody:   "a bearer token is required",
+		},
+		{
+			name:          "Fail - Invalid token",
+			verifier:      newFakeVerifier(),
+			authorization: "Bearer forged-token",
+			wantStatus:    fiber.StatusUnauthorized,
+			wantBody:      "unauthenticated: invalid token",
+		},
+		{
+			name:          "Fail - Scope not granted",
+			verifier:      newFakeVerifier(),
+			authorization: "Bearer payment-token",
+			wantStatus:    fiber.StatusForbidden,
+			wantBody:      "forbidden: scope compliance:admin is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/lists", auth.RequireScope(tt.verifier, auth.ScopeComplianceAdmin), func(c *fiber.Ctx) error {
+				return c.SendString(auth.Subject(c))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/lists", nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tt.wantBody, string(body))
+			if tt.wantStatus != fiber.StatusOK {
+				assert.NotEmpty(t, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	scopes := map[string]string{"/compliance.v1.ComplianceService/CheckCompliance": auth.ScopeComplianceCheck}
+
+	tests := []struct {
+		name          string
+		verifier      auth.Verifier
+		method        string
+		authorization string
+		wantCode      codes.Code
+	}{
+		{
+			name:          "Success - Scope granted",
+			verifier:      newFakeVerifier(),
+			method:        "/compliance.v1.ComplianceService/CheckCompliance",
+			authorization: "Bearer payment-token",
+			wantCode:      codes.OK,
+		},
+		{
+			name:     "Success - Authentication disabled",
+			verifier: nil,
+			method:   "/compliance.v1.ComplianceService/RequestCardReview",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Fail - Missing token",
+			verifier: newFakeVerifier(),
+			method:   "/compliance.v1.ComplianceService/CheckCompliance",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:          "Fail - Invalid token",
+			verifier:      newFakeVerifier(),
+			method:        "/compliance.v1.ComplianceService/CheckCompliance",
+			authorization: "Bearer forged-token",
+			wantCode:      codes.Unauthenticated,
+		},
+		{
+			name:          "Fail - Scope not granted",
+			verifier:      newFakeVerifier(),
+			method:        "/compliance.v1.ComplianceService/CheckCompliance",
+			authorization: "Bearer officer-token",
+			wantCode:      codes.PermissionDenied,
+		},
+		{
+			name:          "Fail - Method without scope",
+			verifier:      newFakeVerifier(),
+			method:        "/compliance.v1.ComplianceService/RequestCardReview",
+			authorization: "Bearer payment-token",
+			wantCode:      codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+			handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+			_, err := auth.UnaryServerInterceptor(tt.verifier, scopes)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoSigningKey is returned when neither AUTH_JWT_PRIVATE_KEY nor AUTH_JWT_SECRET is set.
+var ErrNoSigningKey = errors.New("no signing key, set AUTH_JWT_PRIVATE_KEY or AUTH_JWT_SECRET")
+
+// TokenSource returns a valid token for the calls of a client.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+type signer struct {
+	method jwt.SigningMethod
+	key    any
+}
+
+// newSignerFromEnv signs with the Ed25519 private key in the PEM file at AUTH_JWT_PRIVATE_KEY, or else with
+// AUTH_JWT_SECRET, the secret of the client only, also stored as <client>.secret by the services it calls.
+func newSignerFromEnv() (*signer, error) {
+	if path := os.Getenv("AUTH_JWT_PRIVATE_KEY"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the signing key: %w", err)
+		}
+		key, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the signing key %s: %w", path, err)
+		}
+		return &signer{method: jwt.SigningMethodEdDSA, key: key}, nil
+	}
+
+	if secret := os.Getenv("AUTH_JWT_SECRET"); secret != "" {
+		return &signer{method: jwt.SigningMethodHS256, key: []byte(secret)}, nil
+	}
+
+	return nil, ErrNoSigningKey
+}
+
+func (s *signer) sign(client string, subject string, audience string, scopes []string, issuedAt time.Time, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(s.method, Claims{
+		Scope: strings.Join(scopes, " "),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    client,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(ttl)),
+		},
+	})
+	token.Header["kid"] = client
+
+	return token.SignedString(s.key)
+}
+
+// IssueToken signs a single token, e.g. for a compliance officer calling the back-office routes with the admin tooling.
+func IssueToken(client string, subject string, audience string, scopes []string, ttl time.Duration) (string, error) {
+	signer, err := newSignerFromEnv()
+	if err != nil {
+		return "", err
+	}
+
+	return signer.sign(client, subject, audience, scopes, time.Now(), ttl)
+}
+
+type tokenSource struct {
+	signer   *signer
+	client   string
+	audience string
+	scopes   []string
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	renewAt time.Time
+}
+
+// NewTokenSource signs the tokens of the client, the issuer and subject of its own calls, for the scopes it needs.
+// Tokens last AUTH_JWT_TTL (default 5m) and are renewed once half of it has passed. The source is nil when no signing
+// key is configured, the calls are then sent without a token.
+func NewTokenSource(client string, audience string, scopes []string) (TokenSource, error) {
+	signer, err := newSignerFromEnv()
+	if errors.Is(err, ErrNoSigningKey) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenSource{
+		signer:   signer,
+		client:   client,
+		audience: audience,
+		scopes:   scopes,
+		ttl:      durationFromEnv("AUTH_JWT_TTL", defaultTokenTTL),
+		now:      time.Now,
+	}, nil
+}
+
+func (s *tokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Before(s.renewAt) {
+		return s.token, nil
+	}
+
+	token, err := s.signer.sign(s.client, s.client, s.audience, s.scopes, now, s.ttl)
+	if err != nil {
+		return "", err
+	}
+
+	s.token, s.renewAt = token, now.Add(s.ttl/2)
+	return token, nil
+}
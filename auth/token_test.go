@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenSource(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	source := &tokenSource{
+		signer:   &signer{method: jwt.SigningMethodHS256, key: []byte("test-secret")},
+		client:   "payment-service",
+		audience: AudienceCompliance,
+		scopes:   []string{ScopeComplianceCheck, ScopeComplianceCases},
+		ttl:      4 * time.Minute,
+		now:      func() time.Time { return now },
+	}
+
+	first, err := source.Token()
+	assert.NoError(t, err)
+
+	var claims Claims
+	_, err = jwt.ParseWithClaims(first, &claims, func(*jwt.Token) (any, error) { return []byte("test-secret"), nil },
+		jwt.WithTimeFunc(func() time.Time { return now }))
+	assert.NoError(t, err)
+	assert.Equal(t, "payment-service", claims.Issuer)
+	assert.Equal(t, "payment-service", claims.Subject)
+	assert.Equal(t, jwt.ClaimStrings{AudienceCompliance}, claims.Audience)
+	assert.Equal(t, now.Add(4*time.Minute), claims.ExpiresAt.Time.UTC())
+	assert.True(t, claims.HasScope(ScopeComplianceCases))
+
+	now = now.Add(time.Minute)
+	cached, err := source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, first, cached)
+
+	now = now.Add(time.Minute)
+	renewed, err := source.Token()
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, renewed)
+}
+
+func TestNewTokenSource(t *testing.T) {
+	t.Setenv("AUTH_JWT_PRIVATE_KEY", "")
+	t.Setenv("AUTH_JWT_SECRET", "")
+	source, err := NewTokenSource("payment-service", AudienceCompliance, []string{ScopeComplianceCheck})
+	assert.NoError(t, err)
+	assert.Nil(t, source)
+
+	t.Setenv("AUTH_JWT_SECRET", "test-secret")
+	source, err = NewTokenSource("payment-service", AudienceCompliance, []string{ScopeComplianceCheck})
+	assert.NoError(t, err)
+	assert.NotNil(t, source)
+
+	t.Setenv("AUTH_JWT_PRIVATE_KEY", "./missing.pem")
+	_, err = NewTokenSource("payment-service", AudienceCompliance, []string{ScopeComplianceCheck})
+	assert.Error(t, err)
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultClientsPath = "./database/auth_clients.csv"
+	minSecretLength    = 32
+)
+
+// Verifier checks the token of a call needing the scope. ErrUnauthenticated is returned for a missing, invalid or
+// expired token, ErrForbidden for a valid token not granting the scope.
+type Verifier interface {
+	Verify(token string, scope string) (Claims, error)
+}
+
+type verifier struct {
+	// secrets and publicKeys hold the key of each client, a token being verified with the key of its issuer.
+	secrets    map[string][]byte
+	publicKeys map[string]crypto.PublicKey
+	// clients holds the scopes each client may grant in its tokens.
+	clients  map[string][]string
+	methods  []string
+	audience string
+	maxTTL   time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier checks the tokens sent to the audience, signed with the key of their client: its Ed25519 public key, read
+// from <client>.pub in AUTH_JWT_PUBLIC_KEYS_DIR, or its own HS256 secret, read from <client>.secret in
+// AUTH_JWT_SECRETS_DIR. A secret is known to both ends, so the clients allowed a privileged scope, e.g.
+// compliance:admin, must use an Ed25519 key. The clients and the scopes they may grant are read from the CSV file at
+// AUTH_CLIENTS_PATH (default ./database/auth_clients.csv), and tokens lasting more than AUTH_JWT_MAX_TTL (default 1h)
+// are rejected. Without any key the verifier cannot be created, unless AUTH_DISABLED is true: it is then nil, and
+// every call is let through.
+func NewVerifier(audience string) (Verifier, error) {
+	if os.Getenv("AUTH_DISABLED") == "true" {
+		return nil, nil
+	}
+
+	v := &verifier{
+		secrets:    map[string][]byte{},
+		publicKeys: map[string]crypto.PublicKey{},
+		audience:   audience,
+		maxTTL:     durationFromEnv("AUTH_JWT_MAX_TTL", defaultMaxTokenTTL),
+		now:        time.Now,
+	}
+
+	err := readKeys(os.Getenv("AUTH_JWT_SECRETS_DIR"), ".secret", func(client string, data []byte) error {
+		secret := bytes.TrimSpace(data)
+		if len(secret) < minSecretLength {
+			return fmt.Errorf("the secret of client %s must hold at least %d bytes", client, minSecretLength)
+		}
+		v.secrets[client] = secret
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(v.secrets) > 0 {
+		v.methods = append(v.methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	err = readKeys(os.Getenv("AUTH_JWT_PUBLIC_KEYS_DIR"), ".pub", func(client string, data []byte) error {
+		key, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return fmt.Errorf("error parsing the public key of client %s: %w", client, err)
+		}
+		v.publicKeys[client] = key
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(v.publicKeys) > 0 {
+		v.methods = append(v.methods, jwt.SigningMethodEdDSA.Alg())
+	}
+
+	if len(v.methods) == 0 {
+		return nil, errors.New("no verification key, set AUTH_JWT_PUBLIC_KEYS_DIR or AUTH_JWT_SECRETS_DIR, or AUTH_DISABLED=true")
+	}
+
+	path := os.Getenv("AUTH_CLIENTS_PATH")
+	if path == "" {
+		path = defaultClientsPath
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening auth clients: %w", err)
+	}
+	defer file.Close()
+
+	if v.clients, err = readClients(file); err != nil {
+		return nil, fmt.Errorf("error reading auth clients %s: %w", path, err)
+	}
+
+	for client := range v.secrets {
+		for _, scope := range v.clients[client] {
+			if slices.Contains(privilegedScopes, scope) {
+				return nil, fmt.Errorf("client %s is allowed scope %s and must sign with an Ed25519 key, remove its secret", client, scope)
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// readKeys reads the key of each client from the <client><extension> files of the directory, if set.
+func readKeys(dir string, extension string, read func(client string, data []byte) error) error {
+	if dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+extension))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading key: %w", err)
+		}
+		if err := read(strings.TrimSuffix(filepath.Base(path), extension), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readClients reads a CSV file of clients and the space-separated scopes they may grant, with a client,scopes header.
+func readClients(r io.Reader) (map[string][]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || len(records[0]) != 2 || records[0][0] != "client" || records[0][1] != "scopes" {
+		return nil, errors.New("the header must be client,scopes")
+	}
+
+	clients := make(map[string][]string, len(records)-1)
+	for i, record := range records[1:] {
+		client := strings.TrimSpace(record[0])
+		if client == "" {
+			return nil, fmt.Errorf("line %d: client is required", i+2)
+		}
+		clients[client] = strings.Fields(record[1])
+	}
+
+	return clients, nil
+}
+
+func (v *verifier) Verify(token string, scope string) (Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, v.key,
+		jwt.WithValidMethods(v.methods),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(v.now))
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if claims.IssuedAt == nil || claims.ExpiresAt.Sub(claims.IssuedAt.Time) > v.maxTTL {
+		return Claims{}, fmt.Errorf("%w: tokens must be issued for at most %s", ErrUnauthenticated, v.maxTTL)
+	}
+
+	allowed, found := v.clients[claims.Issuer]
+	if !found {
+		return Claims{}, fmt.Errorf("%w: unknown client %q", ErrForbidden, claims.Issuer)
+	}
+	for _, granted := range claims.Scopes() {
+		if !slices.Contains(allowed, granted) {
+			return Claims{}, fmt.Errorf("%w: client %s may not grant scope %s", ErrForbidden, claims.Issuer, granted)
+		}
+	}
+	if !claims.HasScope(scope) {
+		return Claims{}, fmt.Errorf("%w: scope %s is required", ErrForbidden, scope)
+	}
+
+	return claims, nil
+}
+
+// key returns the key of the client signing the token, its secret or its public key depending on the signing method.
+func (v *verifier) key(token *jwt.Token) (any, error) {
+	client, err := token.Claims.GetIssuer()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method == jwt.SigningMethodHS256 {
+		secret, found := v.secrets[client]
+		if !found {
+			return nil, fmt.Errorf("no secret for client %q", client)
+		}
+		return secret, nil
+	}
+
+	key, found := v.publicKeys[client]
+	if !found {
+		return nil, fmt.Errorf("no public key for client %q", client)
+	}
+	return key, nil
+}
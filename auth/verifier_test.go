@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	secret := []byte("payment-service-secret-of-32-bytes")
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	adminKey, adminPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	reportingKey, reportingPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	hmacSigner := &signer{method: jwt.SigningMethodHS256, key: secret}
+	edSigner := &signer{method: jwt.SigningMethodEdDSA, key: privateKey}
+	adminSigner := &signer{method: jwt.SigningMethodEdDSA, key: adminPrivateKey}
+
+	v := &verifier{
+		secrets: map[string][]byte{"payment-service": secret},
+		publicKeys: map[string]crypto.PublicKey{"payment-service": publicKey, "admin": adminKey,
+			"reporting": reportingKey},
+		clients: map[string][]string{
+			"payment-service": {ScopeComplianceCheck, ScopeComplianceCases},
+			"admin":           {ScopeComplianceAdmin, ScopeCompliancePII},
+		},
+		methods:  []string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodEdDSA.Alg()},
+		audience: AudienceCompliance,
+		maxTTL:   time.Hour,
+		now:      func() time.Time { return now },
+	}
+
+	sign := func(s *signer, client, subject, audience string, scopes []string, issuedAt time.Time, ttl time.Duration) string {
+		token, err := s.sign(client, subject, audience, scopes, issuedAt, ttl)
+		assert.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		scope       string
+		wantSubject string
+		wantErr     error
+	}{
+		{
+			name:        "Success - Secret of the client",
+			token:       sign(hmacSigner, "payment-service", "payment-service", AudienceCompliance, []string{ScopeComplianceCheck}, now.Add(-time.Minute), 5*time.Minute),
+			scope:       ScopeComplianceCheck,
+			wantSubject: "payment-service",
+		},
+		{
+			name:        "Success - Officer of the admin tooling",
+			token:       sign(adminSigner, "admin", "alice", AudienceCompliance, []string{ScopeComplianceAdmin, ScopeCompliancePII}, now.Add(-time.Minute), 15*time.Minute),
+			scope:       ScopeCompliancePII,
+			wantSubject: "alice",
+		},
+		{
+			name:        "Success - Key of the client",
+			token:       sign(edSigner, "payment-service", "payment-service", AudienceCompliance, []string{ScopeComplianceCheck, ScopeComplianceCases}, now, 5*time.Minute),
+			scope:       ScopeComplianceCheck,
+			wantSubject: "payment-service",
+		},
+		{
+			name:    "Fail - Signed with another key",
+			token:   sign(&signer{method: jwt.SigningMethodEdDSA, key: otherKey}, "payment-service", "payment-service", AudienceCompliance, []string{ScopeComplianceCheck}, now, 5*time.Minute),
+			scope:   ScopeComplianceCheck,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - Admin token signed with the secret of another client",
+			token:   sign(hmacSigner, "admin", "alice", AudienceCompliance, []string{ScopeComplianceAdmin, ScopeCompliancePII}, now, 5*time.Minute),
+			scope:   ScopeCompliancePII,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - No key for the client",
+			token:   sign(&signer{method: jwt.SigningMethodEdDSA, key: otherKey}, "partner", "partner", AudienceCompliance, []string{ScopeComplianceCheck}, now, 5*time.Minute),
+			scope:   ScopeComplianceCheck,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - Key of the client with another signing method",
+			token:   sign(&signer{method: jwt.SigningMethodHS256, key: secret}, "admin", "alice", AudienceCompliance, []string{ScopeComplianceAdmin}, now, 5*time.Minute),
+			scope:   ScopeComplianceAdmin,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - Expired",
+			token:   sign(hmacSigner, "payment-service", "payment-service", AudienceCompliance, []string{ScopeComplianceCheck}, now.Add(-10*time.Minute), 5*time.Minute),
+			scope:   ScopeComplianceCheck,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - Issued for too long",
+			token:   sign(adminSigner, "admin", "alice", AudienceCompliance, []string{ScopeComplianceAdmin}, now, 24*time.Hour),
+			scope:   ScopeComplianceAdmin,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - Other audience",
+			token:   sign(hmacSigner, "payment-service", "payment-service", "payment-service", []string{ScopeComplianceCheck}, now, 5*time.Minute),
+			scope:   ScopeComplianceCheck,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - Malformed",
+			token:   "not-a-token",
+			scope:   ScopeComplianceCheck,
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "Fail - Unknown client",
+			token:   sign(&signer{method: jwt.SigningMethodEdDSA, key: reportingPrivateKey}, "reporting", "reporting", AudienceCompliance, []string{ScopeComplianceCheck}, now, 5*time.Minute),
+			scope:   ScopeComplianceCheck,
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "Fail - Scope not allowed to the client",
+			token:   sign(hmacSigner, "payment-service", "payment-service", AudienceCompliance, []string{ScopeComplianceCheck, ScopeComplianceAdmin}, now, 5*time.Minute),
+			scope:   ScopeComplianceCheck,
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "Fail - Scope not granted",
+			token:   sign(hmacSigner, "payment-service", "payment-service", AudienceCompliance, []string{ScopeComplianceCheck}, now, 5*time.Minute),
+			scope:   ScopeComplianceAdmin,
+			wantErr: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(tt.token, tt.scope)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSubject, claims.Subject)
+		})
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	publicDER, _ := x509.MarshalPKIXPublicKey(publicKey)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	clients := "client,scopes\npayment-service,compliance:check compliance:cases\nadmin,compliance:admin compliance:pii\n"
+
+	tests := []struct {
+		name    string
+		secrets map[string]string
+		keys    map[string][]byte
+		wantErr string
+	}{
+		{
+			name:    "Success - Ed25519 key of the admin tooling, secret of payment-service",
+			secrets: map[string]string{"payment-service": "payment-service-secret-of-32-bytes\n"},
+			keys:    map[string][]byte{"admin": publicPEM},
+		},
+		{
+			name:    "Fail - Secret of a client allowed privileged scopes",
+			secrets: map[string]string{"admin": "admin-secret-of-at-least-32-bytes"},
+			keys:    map[string][]byte{"payment-service": publicPEM},
+			wantErr: "client admin is allowed scope compliance:admin and must sign with an Ed25519 key, remove its secret",
+		},
+		{
+			name:    "Fail - Short secret",
+			secrets: map[string]string{"payment-service": "change-me"},
+			wantErr: "the secret of client payment-service must hold at least 32 bytes",
+		},
+		{
+			name:    "Fail - No key",
+			wantErr: "no verification key, set AUTH_JWT_PUBLIC_KEYS_DIR or AUTH_JWT_SECRETS_DIR, or AUTH_DISABLED=true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for client, secret := range tt.secrets {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, client+".secret"), []byte(secret), 0o600))
+			}
+			for client, key := range tt.keys {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, client+".pub"), key, 0o644))
+			}
+			clientsPath := filepath.Join(dir, "auth_clients.csv")
+			assert.NoError(t, os.WriteFile(clientsPath, []byte(clients), 0o644))
+			t.Setenv("AUTH_JWT_SECRETS_DIR", dir)
+			t.Setenv("AUTH_JWT_PUBLIC_KEYS_DIR", dir)
+			t.Setenv("AUTH_CLIENTS_PATH", clientsPath)
+
+			v, err := NewVerifier(AudienceCompliance)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, v)
+		})
+	}
+}
+
+func TestReadClients(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{
+			name:  "Success",
+			input: "client,scopes\npayment-service,compliance:check compliance:cases\nadmin,compliance:admin\n",
+			want: map[string][]string{
+				"payment-service": {ScopeComplianceCheck, ScopeComplianceCases},
+				"admin":           {ScopeComplianceAdmin},
+			},
+		},
+		{
+			name:    "Fail - Header",
+			input:   "name,scopes\nadmin,compliance:admin\n",
+			wantErr: true,
+		},
+		{
+			name:    "Fail - Missing client",
+			input:   "client,scopes\n,compliance:admin\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readClients(strings.NewReader(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runCommand runs a one-off command instead of the HTTP server, e.g.:
+//
+//	compliance-service import-cards -source visa-cams -file feed.csv -report report.csv
+//	compliance-service refresh-sanctions
+//	compliance-service refresh-pep
+func runCommand(args []string, cardImportService service.CardImportService, screeningService service.ScreeningService, pepService service.PEPService) error {
+	switch args[0] {
+	case "import-cards":
+		return importCards(args[1:], cardImportService)
+	case "refresh-sanctions":
+		return refreshSanctions(screeningService)
+	case "refresh-pep":
+		return refreshPEPList(pepService)
+	}
+
+	return fmt.Errorf("unknown command %q, available commands: import-cards, refresh-sanctions, refresh-pep", args[0])
+}
+
+// refreshSanctions reloads the list from SANCTIONS_LIST_PATH, meant to be scheduled after each download of the list.
+func refreshSanctions(screeningService service.ScreeningService) error {
+	refresh, err := screeningService.RefreshSanctions()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("sanctions list refreshed: %d entries, %d users screened, %d new hits\n", refresh.Entries, refresh.UsersScreened, refresh.NewHits)
+
+	return nil
+}
+
+// refreshPEPList reloads the list from PEP_LIST_PATH and rates every user again.
+func refreshPEPList(pepService service.PEPService) error {
+	refresh, err := pepService.RefreshPEPList()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("PEP list refreshed: %d entries, %d users screened, %d medium risk, %d high risk\n", refresh.Entries, refresh.UsersScreened, refresh.MediumRisk, refresh.HighRisk)
+
+	return nil
+}
+
+func importCards(args []string, cardImportService service.CardImportService) error {
+	flags := flag.NewFlagSet("import-cards", flag.ContinueOnError)
+	file := flags.String("file", "", "path of the CSV or JSON lines feed to import")
+	source := flags.String("source", "", "name of the feed, stored with every blocked card")
+	format := flags.String("format", "", "csv or jsonl, guessed from the file extension when empty")
+	resume := flags.Int64("resume", 0, "ID of a failed import to resume with the same file")
+	report := flags.String("report", "", "path where the per-row CSV report is written")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	feed, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer feed.Close()
+
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*file)) {
+		case ".csv":
+			*format = service.CardImportFormatCSV
+		case ".jsonl", ".ndjson":
+			*format = service.CardImportFormatJSONL
+		}
+	}
+
+	var cardImport repository.CardImport
+	if *resume != 0 {
+		cardImport, err = cardImportService.ResumeImport(*resume, feed)
+	} else {
+		cardImport, err = cardImportService.StartImport(*source, filepath.Base(*file), *format, feed)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("card import %d %s: %d rows, %d imported, %d duplicates, %d invalid\n",
+		cardImport.ID, cardImport.Status, cardImport.RowsProcessed, cardImport.Imported, cardImport.Duplicates, cardImport.Invalid)
+
+	if *report == "" {
+		return nil
+	}
+
+	reportFile, err := os.Create(*report)
+	if err != nil {
+		return err
+	}
+	defer reportFile.Close()
+
+	return cardImportService.WriteReport(cardImport.ID, reportFile)
+}
@@ -2,6 +2,7 @@
 CREATE TABLE IF NOT EXISTS users (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     user_name TEXT UNIQUE NOT NULL,
+    full_name TEXT NOT NULL DEFAULT '',
     secret_code TEXT NOT NULL
 );
 
@@ -9,8 +10,9 @@ CREATE TABLE IF NOT EXISTS users (
 CREATE TABLE IF NOT EXISTS cards (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     user_id INTEGER NOT NULL,
-    card_number TEXT UNIQUE NOT NULL,
-    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    card_number TEXT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    UNIQUE (user_id, card_number)
 );
 
 -- Create reported_cards table
@@ -24,10 +26,325 @@ CREATE TABLE IF NOT EXISTS reported_cards (
     UNIQUE (user_id, card_id)
 );
 
+-- Create blocked_cards table, keyed on the PAN fingerprint so a blocked card is declined for every user it is linked to
+CREATE TABLE IF NOT EXISTS blocked_cards (
+    card_fingerprint TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    blocked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create card_imports table, tracking the progress of each compromised card feed import so it can be resumed
+CREATE TABLE IF NOT EXISTS card_imports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source TEXT NOT NULL,
+    file_name TEXT NOT NULL DEFAULT '',
+    format TEXT NOT NULL,
+    status TEXT NOT NULL,
+    rows_processed INTEGER NOT NULL DEFAULT 0,
+    imported INTEGER NOT NULL DEFAULT 0,
+    duplicates INTEGER NOT NULL DEFAULT 0,
+    invalid INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Create card_import_rows table, the per-row result report of an import. PANs are never stored, only their fingerprint
+CREATE TABLE IF NOT EXISTS card_import_rows (
+    import_id INTEGER NOT NULL,
+    row_number INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    card_fingerprint TEXT NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (import_id) REFERENCES card_imports (id) ON DELETE CASCADE,
+    PRIMARY KEY (import_id, row_number)
+);
+
+-- Create cases table
+CREATE TABLE IF NOT EXISTS cases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    card_id INTEGER,
+    source TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    assignee TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (card_id) REFERENCES cards (id) ON DELETE SET NULL
+);
+
+-- Create case_transactions table
+CREATE TABLE IF NOT EXISTS case_transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    case_id INTEGER NOT NULL,
+    transaction_id TEXT NOT NULL,
+    card_id INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    occurred_at TIMESTAMP,
+    attached_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (case_id) REFERENCES cases (id) ON DELETE CASCADE,
+    UNIQUE (case_id, transaction_id)
+);
+
+-- Create case_notes table
+CREATE TABLE IF NOT EXISTS case_notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    case_id INTEGER NOT NULL,
+    author TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (case_id) REFERENCES cases (id) ON DELETE CASCADE
+);
+
+-- Create list_entries table
+CREATE TABLE IF NOT EXISTS list_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    list_type TEXT NOT NULL,
+    entry_type TEXT NOT NULL,
+    value TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (list_type, entry_type, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_list_entries_lookup ON list_entries (entry_type, value);
+
+-- Create sanctions_entries table. Entries missing from the latest load of their list are kept as inactive
+CREATE TABLE IF NOT EXISTS sanctions_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    list_name TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    entry_type TEXT NOT NULL,
+    programs TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT 1,
+    loaded_at TIMESTAMP NOT NULL,
+    UNIQUE (list_name, external_id)
+);
+
+-- Create sanctions_names table, the primary name and the aliases of each entry
+CREATE TABLE IF NOT EXISTS sanctions_names (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    FOREIGN KEY (entry_id) REFERENCES sanctions_entries (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sanctions_names_entry ON sanctions_names (entry_id);
+
+-- Create screening_hits table, the review queue of users matching a sanctions entry
+CREATE TABLE IF NOT EXISTS screening_hits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    entry_id INTEGER NOT NULL,
+    screened_name TEXT NOT NULL,
+    matched_name TEXT NOT NULL,
+    score REAL NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    reviewer TEXT NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (entry_id) REFERENCES sanctions_entries (id) ON DELETE CASCADE,
+    UNIQUE (user_id, entry_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_screening_hits_status ON screening_hits (status);
+
+-- Create pep_entries table, the politically exposed persons, their relatives and close associates, and the subjects
+-- of adverse media. Entries missing from the latest load of the list are kept as inactive
+CREATE TABLE IF NOT EXISTS pep_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    external_id TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    category TEXT NOT NULL,
+    position TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT 1,
+    loaded_at TIMESTAMP NOT NULL
+);
+
+-- Create pep_names table, the primary name and the aliases of each entry
+CREATE TABLE IF NOT EXISTS pep_names (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    FOREIGN KEY (entry_id) REFERENCES pep_entries (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pep_names_entry ON pep_names (entry_id);
+
+-- Create pep_matches table, the entries each user matched at the latest screening
+CREATE TABLE IF NOT EXISTS pep_matches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    entry_id INTEGER NOT NULL,
+    screened_name TEXT NOT NULL,
+    matched_name TEXT NOT NULL,
+    score REAL NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (entry_id) REFERENCES pep_entries (id) ON DELETE CASCADE,
+    UNIQUE (user_id, entry_id)
+);
+
+-- Create user_risk_ratings table, the enhanced due diligence marker of each screened user. A rating set by a
+-- reviewer is kept over the ones computed by later screenings, until the user matches a new entry
+CREATE TABLE IF NOT EXISTS user_risk_ratings (
+    user_id INTEGER PRIMARY KEY,
+    rating TEXT NOT NULL,
+    source TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    reviewer TEXT NOT NULL DEFAULT '',
+    rated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_risk_ratings_rating ON user_risk_ratings (rating);
+
+-- Create kyc_profiles table, a user without a profile is unverified. The ID document number is encrypted by the service
+CREATE TABLE IF NOT EXISTS kyc_profiles (
+    user_id INTEGER PRIMARY KEY,
+    legal_name TEXT NOT NULL,
+    date_of_birth TEXT NOT NULL,
+    address_line TEXT NOT NULL,
+    city TEXT NOT NULL,
+    postal_code TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL,
+    nationality TEXT NOT NULL,
+    id_document_type TEXT NOT NULL,
+    id_document_number TEXT NOT NULL,
+    id_document_last4 TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    tier INTEGER NOT NULL DEFAULT 0,
+    reviewer TEXT NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    submitted_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_profiles_status ON kyc_profiles (status);
+
+-- Create sars table, one Suspicious Activity Report per case. The subject and the transactions are a snapshot taken
+-- when the report is drafted; the ID document number is encrypted by the service
+CREATE TABLE IF NOT EXISTS sars (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    case_id INTEGER UNIQUE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    subject_user_id INTEGER NOT NULL,
+    subject_name TEXT NOT NULL DEFAULT '',
+    subject_date_of_birth TEXT NOT NULL DEFAULT '',
+    subject_address_line TEXT NOT NULL DEFAULT '',
+    subject_city TEXT NOT NULL DEFAULT '',
+    subject_postal_code TEXT NOT NULL DEFAULT '',
+    subject_country TEXT NOT NULL DEFAULT '',
+    subject_id_document_type TEXT NOT NULL DEFAULT '',
+    subject_id_document_number TEXT NOT NULL DEFAULT '',
+    subject_id_document_last4 TEXT NOT NULL DEFAULT '',
+    activity_types TEXT NOT NULL DEFAULT '',
+    narrative TEXT NOT NULL DEFAULT '',
+    prepared_by TEXT NOT NULL,
+    filing_reference TEXT NOT NULL DEFAULT '',
+    status_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    filed_at TIMESTAMP,
+    FOREIGN KEY (case_id) REFERENCES cases (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sars_status ON sars (status);
+
+CREATE TABLE IF NOT EXISTS sar_transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sar_id INTEGER NOT NULL,
+    transaction_id TEXT NOT NULL,
+    card_id INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (sar_id) REFERENCES sars (id) ON DELETE CASCADE
+);
+
+-- Create sar_access_log table, the audit trail of who read or changed each report
+CREATE TABLE IF NOT EXISTS sar_access_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sar_id INTEGER NOT NULL,
+    officer TEXT NOT NULL,
+    action TEXT NOT NULL,
+    accessed_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (sar_id) REFERENCES sars (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sar_access_log_sar ON sar_access_log (sar_id);
+
+-- Create events table, the outbox of the domain events, written in the same transaction as the change they describe.
+-- The id is the cursor consumers resume from.
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_created_at ON events (created_at);
+
+-- Create event_cursors table, the last event each consumer of the outbox within the service has handled
+CREATE TABLE IF NOT EXISTS event_cursors (
+    consumer TEXT PRIMARY KEY,
+    last_event_id INTEGER NOT NULL
+);
+
+-- Create webhook_endpoints table, the partner URLs notified of the events of the given comma-separated types, or of
+-- every event when empty. Deleted endpoints are deactivated to keep their delivery log.
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Create webhook_deliveries table, an event to send to an endpoint. Deliveries failing every attempt stay with the dead
+-- status until redelivered.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+-- Create webhook_delivery_attempts table, the delivery log
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delivery_id INTEGER NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL,
+    attempted_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE
+);
+
 -- DUMMY DATA
-INSERT OR IGNORE INTO users (user_name, secret_code) VALUES 
-    ('john_doe', '$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca'),   -- secret_code: hashed_secret_123
-    ('jane_smith', '$2a$10$xe4/MMDeSW5Qj59sXAriS.3tMjMPzlQh6MX/Qr2frrNggCiI.29ZO'); -- secret_code: hashed_secret_456
+INSERT OR IGNORE INTO users (user_name, full_name, secret_code) VALUES 
+    ('john_doe', 'John Doe', '$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca'),     -- secret_code: hashed_secret_123
+    ('jane_smith', 'Jane Smith', '$2a$10$xe4/MMDeSW5Qj59sXAriS.3tMjMPzlQh6MX/Qr2frrNggCiI.29ZO'); -- secret_code: hashed_secret_456
 
 INSERT OR IGNORE INTO cards (user_id, card_number) VALUES 
     (1, '1234-5678-9012-3456'),
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flarrocca/logging"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+)
+
+const defaultGRPCPort = "9090"
+
+// serveGRPC serves the gRPC API on COMPLIANCE_GRPC_PORT, next to the HTTP routes.
+func serveGRPC(server *grpc.Server) {
+	port := os.Getenv("COMPLIANCE_GRPC_PORT")
+	if port == "" {
+		port = defaultGRPCPort
+	}
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		logging.Fatal("error listening for gRPC", err)
+	}
+
+	logging.Fatal("error serving gRPC", server.Serve(listener))
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flarrocca/api"
+	"flarrocca/compliant-service/service"
+	"flarrocca/logging"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CardImportHandler struct {
+	cardImportService service.CardImportService
+}
+
+func NewCardImportHandler(cardImportService service.CardImportService) *CardImportHandler {
+	return &CardImportHandler{cardImportService: cardImportService}
+}
+
+// StartImport reads the feed from the request body, as CSV or JSON lines depending on the format
+// query parameter or, when missing, on the content type.
+func (h *CardImportHandler) StartImport(c *fiber.Ctx) error {
+	format := c.Query("format")
+	if format == "" {
+		format = feedFormat(c.Get(fiber.HeaderContentType))
+	}
+
+	cardImport, err := h.cardImportService.StartImport(c.Query("source"), c.Query("file_name"), format, requestBody(c))
+	if err != nil {
+		return cardImportErrorResponse(cardImport.ID, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(cardImport)
+}
+
+func (h *CardImportHandler) ResumeImport(c *fiber.Ctx) error {
+	importID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for import ID: %s", err))
+	}
+
+	cardImport, err := h.cardImportService.ResumeImport(importID, requestBody(c))
+	if err != nil {
+		return cardImportErrorResponse(cardImport.ID, err)
+	}
+
+	return c.JSON(cardImport)
+}
+
+func (h *CardImportHandler) GetImport(c *fiber.Ctx) error {
+	importID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for import ID: %s", err))
+	}
+
+	cardImport, err := h.cardImportService.GetImport(importID)
+	if err != nil {
+		return cardImportErrorResponse(0, err)
+	}
+
+	return c.JSON(cardImport)
+}
+
+// GetReport streams the per-row result of the import as CSV.
+func (h *CardImportHandler) GetReport(c *fiber.Ctx) error {
+	importID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for import ID: %s", err))
+	}
+
+	if _, err := h.cardImportService.GetImport(importID); err != nil {
+		return cardImportErrorResponse(0, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="card_import_%d.csv"`, importID))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.cardImportService.WriteReport(importID, w); err != nil {
+			slog.Error("error writing card import report", slog.Int64("import_id", importID), logging.Err(err))
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+func feedFormat(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return service.CardImportFormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"), strings.HasPrefix(contentType, "application/jsonl"):
+		return service.CardImportFormatJSONL
+	}
+	return ""
+}
+
+// requestBody returns the body as a stream when the server streams request bodies, so large feeds are not held in memory.
+func requestBody(c *fiber.Ctx) io.Reader {
+	if stream := c.Context().RequestBodyStream(); stream != nil {
+		return stream
+	}
+	return bytes.NewReader(c.Body())
+}
+
+// cardImportErrorResponse includes the import ID in the details when the import was created, so the caller knows what
+// to resume.
+func cardImportErrorResponse(importID int64, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrCardImportNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrCardImportCompleted), errors.Is(err, service.ErrCardImportRunning):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidCardImport):
+		status = http.StatusBadRequest
+	}
+
+	apiErr := api.NewError(status, err.Error())
+	if importID != 0 {
+		apiErr.WithDetails(fiber.Map{"import_id": importID})
+	}
+	return apiErr
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCardImportApp(cardImportServiceMock *mock.MockCardImportService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &CardImportHandler{cardImportService: cardImportServiceMock}
+
+	app.Post("/blocked_cards/imports", handler.StartImport)
+	app.Get("/blocked_cards/imports/:id", handler.GetImport)
+	app.Post("/blocked_cards/imports/:id/resume", handler.ResumeImport)
+	app.Get("/blocked_cards/imports/:id/report", handler.GetReport)
+
+	return app
+}
+
+func TestCardImportHandler(t *testing.T) {
+	type input struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+	}
+
+	type depFields struct {
+		cardImportServiceMock *mock.MockCardImportService
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - CSV feed imported",
+			input: input{
+				method:      http.MethodPost,
+				path:        "/blocked_cards/imports?source=visa-cams&file_name=feed.csv",
+				contentType: "text/csv",
+				body:        "pan\n4111111111111111\n",
+			},
+			on: func(dep *depFields, in input) {
+				dep.cardImportServiceMock.EXPECT().StartImport("visa-cams", "feed.csv", service.CardImportFormatCSV, gomock.Any()).
+					DoAndReturn(func(source, fileName, format string, feed io.Reader) (repository.CardImport, error) {
+						body, _ := io.ReadAll(feed)
+						assert.Equal(t, in.body, string(body))
+						return repository.CardImport{ID: 7, Source: source, Format: format, Status: service.CardImportStatusCompleted, RowsProcessed: 1, Imported: 1}, nil
+					})
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"rows_processed":1`)
+			},
+		},
+		{
+			name: "Failure - Import stopped half way returns the import to resume",
+			input: input{
+				method:      http.MethodPost,
+				path:        "/blocked_cards/imports?source=visa-cams&format=jsonl",
+				contentType: "application/octet-stream",
+				body:        `{"pan": "4111111111111111"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.cardImportServiceMock.EXPECT().StartImport("visa-cams", "", service.CardImportFormatJSONL, gomock.Any()).
+					Return(repository.CardImport{ID: 7}, fmt.Errorf("card import 7 stopped after row 500: %w", errors.New("database is locked")))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "internal", "message": "card import 7 stopped after row 500: database is locked", "request_id": "", "details": {"import_id": 7}}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid feed",
+			input: input{
+				method:      http.MethodPost,
+				path:        "/blocked_cards/imports?source=visa-cams",
+				contentType: "text/csv",
+				body:        "number\n4111111111111111\n",
+			},
+			on: func(dep *depFields, in input) {
+				dep.cardImportServiceMock.EXPECT().StartImport("visa-cams", "", service.CardImportFormatCSV, gomock.Any()).
+					Return(repository.CardImport{}, fmt.Errorf("%w: CSV header must have a pan or card_number column", service.ErrInvalidCardImport))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Resume completed import",
+			input: input{
+				method:      http.MethodPost,
+				path:        "/blocked_cards/imports/7/resume",
+				contentType: "text/csv",
+				body:        "pan\n4111111111111111\n",
+			},
+			on: func(dep *depFields, in input) {
+				dep.cardImportServiceMock.EXPECT().ResumeImport(int64(7), gomock.Any()).
+					Return(repository.CardImport{ID: 7, Status: service.CardImportStatusCompleted}, service.ErrCardImportCompleted)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Report streamed as CSV",
+			input: input{
+				method: http.MethodGet,
+				path:   "/blocked_cards/imports/7/report",
+			},
+			on: func(dep *depFields, in input) {
+				dep.cardImportServiceMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{ID: 7}, nil)
+				dep.cardImportServiceMock.EXPECT().WriteReport(int64(7), gomock.Any()).DoAndReturn(func(importID int64, w io.Writer) error {
+					_, err := io.WriteString(w, "row,status,card_fingerprint,detail\n1,invalid,,missing card number\n")
+					return err
+				})
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, "row,status,card_fingerprint,detail\n1,invalid,,missing card number\n", string(body))
+			},
+		},
+		{
+			name: "Failure - Report of unknown import",
+			input: input{
+				method: http.MethodGet,
+				path:   "/blocked_cards/imports/9/report",
+			},
+			on: func(dep *depFields, in input) {
+				dep.cardImportServiceMock.EXPECT().GetImport(int64(9)).Return(repository.CardImport{}, service.ErrCardImportNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cardImportServiceMock := mock.NewMockCardImportService(ctrl)
+			tt.on(&depFields{cardImportServiceMock: cardImportServiceMock}, tt.input)
+
+			app := newTestCardImportApp(cardImportServiceMock)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", tt.input.contentType)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
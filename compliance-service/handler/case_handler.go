@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CaseHandler struct {
+	caseService service.CaseService
+}
+
+func NewCaseHandler(caseService service.CaseService) *CaseHandler {
+	return &CaseHandler{caseService: caseService}
+}
+
+func (h *CaseHandler) OpenCase(c *fiber.Ctx) error {
+	var req struct {
+		UserID       int64                        `json:"user_id"`
+		CardID       int64                        `json:"card_id"`
+		Source       string                       `json:"source"`
+		Transactions []repository.CaseTransaction `json:"transactions"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	if req.UserID == 0 || req.Source == "" {
+		return fiber.NewError(http.StatusBadRequest, "user id and source are required")
+	}
+
+	caseID, err := h.caseService.OpenCase(req.UserID, req.CardID, req.Source, req.Transactions)
+	if err != nil {
+		return caseErrorResponse(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"case_id": caseID})
+}
+
+func (h *CaseHandler) ListCases(c *fiber.Ctx) error {
+	cases, err := h.caseService.ListCases(c.Query("status"), c.Query("assignee"))
+	if err != nil {
+		return caseErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"cases": cases})
+}
+
+func (h *CaseHandler) AttachTransaction(c *fiber.Ctx) error {
+	caseID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for case ID: %s", err))
+	}
+
+	var transaction repository.CaseTransaction
+	if err := c.BodyParser(&transaction); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	if transaction.TransactionID == "" || transaction.CardID == 0 {
+		return fiber.NewError(http.StatusBadRequest, "transaction id and card id are required")
+	}
+
+	if err := h.caseService.AttachTransaction(caseID, transaction); err != nil {
+		return caseErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"message": "transaction attached to the case"})
+}
+
+func (h *CaseHandler) AddNote(c *fiber.Ctx) error {
+	caseID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for case ID: %s", err))
+	}
+
+	var req struct {
+		Author string `json:"author"`
+		Body   string `json:"body"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	if req.Author == "" || req.Body == "" {
+		return fiber.NewError(http.StatusBadRequest, "author and body are required")
+	}
+
+	if err := h.caseService.AddNote(caseID, req.Author, req.Body); err != nil {
+		return caseErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"message": "note added to the case"})
+}
+
+func (h *CaseHandler) AssignCase(c *fiber.Ctx) error {
+	caseID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for case ID: %s", err))
+	}
+
+	var req struct {
+		Assignee string `json:"assignee"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.Assignee == "" {
+		return fiber.NewError(http.StatusBadRequest, "assignee is required")
+	}
+
+	if err := h.caseService.AssignCase(caseID, req.Assignee); err != nil {
+		return caseErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"message": fmt.Sprintf("case assigned to %s", req.Assignee)})
+}
+
+func (h *CaseHandler) UpdateCaseStatus(c *fiber.Ctx) error {
+	caseID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for case ID: %s", err))
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.Status == "" {
+		return fiber.NewError(http.StatusBadRequest, "status is required")
+	}
+
+	if err := h.caseService.UpdateCaseStatus(caseID, req.Status); err != nil {
+		return caseErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"message": fmt.Sprintf("case status updated to %s", req.Status)})
+}
+
+func (h *CaseHandler) ExportCase(c *fiber.Ctx) error {
+	caseID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for case ID: %s", err))
+	}
+
+	bundle, err := h.caseService.ExportCase(caseID)
+	if err != nil {
+		return caseErrorResponse(err)
+	}
+
+	c.Attachment(fmt.Sprintf("case_%d.json", caseID))
+	return c.JSON(bundle)
+}
+
+func caseErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrCaseNotFound), errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrCardNotFound),
+		errors.Is(err, service.ErrCaseTransactionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidCaseSource), errors.Is(err, service.ErrInvalidCaseTransition), errors.Is(err, service.ErrCaseTransactionMismatch):
+		status = http.StatusBadRequest
+	}
+
+	return fiber.NewError(status, err.Error())
+}
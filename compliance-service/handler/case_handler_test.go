@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCaseApp(caseServiceMock *mock.MockCaseService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &CaseHandler{caseService: caseServiceMock}
+
+	app.Get("/cases", handler.ListCases)
+	app.Post("/cases", handler.OpenCase)
+	app.Post("/cases/:id/transactions", handler.AttachTransaction)
+	app.Post("/cases/:id/notes", handler.AddNote)
+	app.Put("/cases/:id/assignee", handler.AssignCase)
+	app.Put("/cases/:id/status", handler.UpdateCaseStatus)
+	app.Get("/cases/:id/export", handler.ExportCase)
+
+	return app
+}
+
+func TestCaseHandler(t *testing.T) {
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	type depFields struct {
+		caseServiceMock *mock.MockCaseService
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Case opened",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases",
+				body: `{"user_id": 1, "card_id": 2, "source": "high_risk_payment",
+					"transactions": [{"transaction_id": "txn_1", "card_id": 2, "amount": 10, "occurred_at": "2025-02-27T18:30:00Z"}]}`,
+			},
+			on: func(dep *depFields, in input) {
+				occurredAt := time.Date(2025, 2, 27, 18, 30, 0, 0, time.UTC)
+				dep.caseServiceMock.EXPECT().OpenCase(int64(1), int64(2), service.CaseSourceHighRiskPayment,
+					[]repository.CaseTransaction{{TransactionID: "txn_1", CardID: 2, Amount: 10, OccurredAt: &occurredAt}}).Return(int64(5), nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"case_id": 5}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Open case without source",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases",
+				body:   `{"user_id": 1}`,
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user id and source are required", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Open case with invalid source",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases",
+				body:   `{"user_id": 1, "source": "unknown"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().OpenCase(int64(1), int64(0), "unknown", nil).Return(int64(0), service.ErrInvalidCaseSource)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "invalid case source", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Open case for a card of another user",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases",
+				body:   `{"user_id": 1, "card_id": 3, "source": "manual"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().OpenCase(int64(1), int64(3), service.CaseSourceManual, nil).Return(int64(0), service.ErrCardNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "not_found", "message": "card not found", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Open case with a transaction unknown to payment-service",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases",
+				body:   `{"user_id": 1, "card_id": 2, "source": "manual", "transactions": [{"transaction_id": "txn_9", "card_id": 2}]}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().OpenCase(int64(1), int64(2), service.CaseSourceManual, gomock.Any()).
+					Return(int64(0), fmt.Errorf("%w: txn_9", service.ErrCaseTransactionNotFound))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Cases listed",
+			input: input{
+				method: http.MethodGet,
+				path:   "/cases?status=open&assignee=alice",
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().ListCases("open", "alice").Return([]repository.Case{{ID: 5, UserID: 1, Status: "open", Assignee: "alice"}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"assignee":"alice"`)
+			},
+		},
+		{
+			name: "Success - Transaction attached",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases/5/transactions",
+				body:   `{"transaction_id": "txn_1", "card_id": 2, "amount": 10}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().AttachTransaction(int64(5), repository.CaseTransaction{TransactionID: "txn_1", CardID: 2, Amount: 10}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Attach transaction not matching its payment",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases/5/transactions",
+				body:   `{"transaction_id": "txn_1", "card_id": 2, "amount": 9999}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().AttachTransaction(int64(5), gomock.Any()).Return(fmt.Errorf("%w: txn_1", service.ErrCaseTransactionMismatch))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "transaction does not match the payment recorded by payment-service: txn_1", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Attach transaction to unknown case",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases/99/transactions",
+				body:   `{"transaction_id": "txn_1", "card_id": 2, "amount": 10}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().AttachTransaction(int64(99), gomock.Any()).Return(service.ErrCaseNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "not_found", "message": "case not found", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid case ID",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases/abc/notes",
+				body:   `{"author": "alice", "body": "called the customer"}`,
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), "invalid data type for case ID")
+			},
+		},
+		{
+			name: "Success - Note added",
+			input: input{
+				method: http.MethodPost,
+				path:   "/cases/5/notes",
+				body:   `{"author": "alice", "body": "called the customer"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().AddNote(int64(5), "alice", "called the customer").Return(nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Case assigned",
+			input: input{
+				method: http.MethodPut,
+				path:   "/cases/5/assignee",
+				body:   `{"assignee": "alice"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().AssignCase(int64(5), "alice").Return(nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"message": "case assigned to alice"}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid status transition",
+			input: input{
+				method: http.MethodPut,
+				path:   "/cases/5/status",
+				body:   `{"status": "confirmed_fraud"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().UpdateCaseStatus(int64(5), "confirmed_fraud").Return(service.ErrInvalidCaseTransition)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "invalid case status transition", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Success - Case exported",
+			input: input{
+				method: http.MethodGet,
+				path:   "/cases/5/export",
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().ExportCase(int64(5)).Return(service.CaseBundle{
+					Case:    repository.Case{ID: 5, UserID: 1},
+					Subject: service.CaseSubject{ID: 1, UserName: "john_doe"},
+				}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, `attachment; filename="case_5.json"`, resp.Header.Get("Content-Disposition"))
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"user_name":"john_doe"`)
+			},
+		},
+		{
+			name: "Failure - Internal service error on export",
+			input: input{
+				method: http.MethodGet,
+				path:   "/cases/5/export",
+			},
+			on: func(dep *depFields, in input) {
+				dep.caseServiceMock.EXPECT().ExportCase(int64(5)).Return(service.CaseBundle{}, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			caseServiceMock := mock.NewMockCaseService(ctrl)
+			tt.on(&depFields{caseServiceMock: caseServiceMock}, tt.input)
+
+			app := newTestCaseApp(caseServiceMock)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"math"
+
+	compliancev1 "flarrocca/proto/compliance/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ComplianceGRPCServer serves the compliance.v1 API, the gRPC counterpart of /check_user, /check_users and /cases used by
+// payment-service.
+type ComplianceGRPCServer struct {
+	compliancev1.UnimplementedComplianceServiceServer
+	complianceService service.ComplianceService
+	caseService       service.CaseService
+}
+
+func NewComplianceGRPCServer(complianceService service.ComplianceService, caseService service.CaseService) *ComplianceGRPCServer {
+	return &ComplianceGRPCServer{complianceService: complianceService, caseService: caseService}
+}
+
+func (s *ComplianceGRPCServer) CheckCompliance(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+	if req.GetUserId() <= 0 || req.GetCardId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user id and card id are required")
+	}
+	if req.GetAmount() < 0 || math.IsNaN(req.GetAmount()) || math.IsInf(req.GetAmount(), 0) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid amount: %v", req.GetAmount())
+	}
+
+	paymentContext := req.GetContext()
+	result, err := s.complianceService.CheckComplianceStatus(service.ComplianceCheck{
+		UserID:     req.GetUserId(),
+		CardID:     req.GetCardId(),
+		Amount:     req.GetAmount(),
+		IPAddress:  paymentContext.GetIpAddress(),
+		Email:      paymentContext.GetEmail(),
+		DeviceID:   paymentContext.GetDeviceId(),
+		MerchantID: paymentContext.GetMerchantId(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking user status: %s", result.Message)
+	}
+	recordComplianceDecision(ctx, req.GetUserId(), req.GetCardId(), result)
+
+	return &compliancev1.CheckComplianceResponse{
+		Compliant:    result.IsCompliance,
+		Message:      result.Message,
+		RiskRating:   result.RiskRating,
+		ManualReview: result.ManualReview,
+		Limits:       paymentLimitsMessage(result.Limits),
+	}, nil
+}
+
+func paymentLimitsMessage(limits *service.PaymentLimits) *compliancev1.PaymentLimits {
+	if limits == nil {
+		return nil
+	}
+	return &compliancev1.PaymentLimits{
+		KycStatus:     limits.KYCStatus,
+		KycTier:       int32(limits.KYCTier),
+		KycLimit:      limits.KYCLimit,
+		HighRiskLimit: limits.HighRiskLimit,
+		ReviewAmount:  limits.ReviewAmount,
+	}
+}
+
+func (s *ComplianceGRPCServer) CheckComplianceBatch(ctx context.Context, req *compliancev1.CheckComplianceBatchRequest) (*compliancev1.CheckComplianceBatchResponse, error) {
+	checks := make([]service.ComplianceCheck, 0, len(req.GetChecks()))
+	for i, check := range req.GetChecks() {
+		if check.GetUserId() <= 0 || check.GetCardId() <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "check %d: user id and card id are required", i)
+		}
+		if check.GetAmount() < 0 || math.IsNaN(check.GetAmount()) || math.IsInf(check.GetAmount(), 0) {
+			return nil, status.Errorf(codes.InvalidArgument, "check %d: invalid amount: %v", i, check.GetAmount())
+		}
+
+		paymentContext := check.GetContext()
+		checks = append(checks, service.ComplianceCheck{
+			UserID:     check.GetUserId(),
+			CardID:     check.GetCardId(),
+			Amount:     check.GetAmount(),
+			IPAddress:  paymentContext.GetIpAddress(),
+			Email:      paymentContext.GetEmail(),
+			DeviceID:   paymentContext.GetDeviceId(),
+			MerchantID: paymentContext.GetMerchantId(),
+		})
+	}
+
+	results, err := s.complianceService.CheckComplianceStatuses(checks)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBatchCheck) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "error checking user statuses: %s", err)
+	}
+
+	resp := &compliancev1.CheckComplianceBatchResponse{Results: make([]*compliancev1.CheckComplianceResponse, 0, len(results))}
+	for _, result := range results {
+		recordComplianceDecision(ctx, result.UserID, result.CardID, result.ComplianceResult)
+		resp.Results = append(resp.Results, &compliancev1.CheckComplianceResponse{
+			Compliant:    result.IsCompliance,
+			Message:      result.Message,
+			RiskRating:   result.RiskRating,
+			ManualReview: result.ManualReview,
+			Limits:       paymentLimitsMessage(result.Limits),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *ComplianceGRPCServer) RequestCardReview(ctx context.Context, req *compliancev1.RequestCardReviewRequest) (*compliancev1.RequestCardReviewResponse, error) {
+	if req.GetUserId() <= 0 || req.GetSource() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id and source are required")
+	}
+
+	transactions := make([]repository.CaseTransaction, 0, len(req.GetTransactions()))
+	for _, transaction := range req.GetTransactions() {
+		caseTransaction := repository.CaseTransaction{
+			TransactionID: transaction.GetTransactionId(),
+			CardID:        transaction.GetCardId(),
+			Amount:        transaction.GetAmount(),
+		}
+		if transaction.GetOccurredAt() != nil {
+			occurredAt := transaction.GetOccurredAt().AsTime()
+			caseTransaction.OccurredAt = &occurredAt
+		}
+		transactions = append(transactions, caseTransaction)
+	}
+
+	caseID, err := s.caseService.OpenCase(req.GetUserId(), req.GetCardId(), req.GetSource(), transactions)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCaseSource), errors.Is(err, service.ErrCaseTransactionMismatch):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrCardNotFound), errors.Is(err, service.ErrCaseTransactionNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &compliancev1.RequestCardReviewResponse{CaseId: caseID}, nil
+}
+
+func (s *ComplianceGRPCServer) ListBlockedCards(ctx context.Context, req *compliancev1.ListBlockedCardsRequest) (*compliancev1.ListBlockedCardsResponse, error) {
+	cards, err := s.complianceService.ListBlockedCards()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error listing blocked cards: %s", err)
+	}
+
+	resp := &compliancev1.ListBlockedCardsResponse{Cards: make([]*compliancev1.BlockedCard, 0, len(cards))}
+	for _, card := range cards {
+		resp.Cards = append(resp.Cards, &compliancev1.BlockedCard{UserId: card.UserID, CardId: card.CardID})
+	}
+
+	return resp, nil
+}
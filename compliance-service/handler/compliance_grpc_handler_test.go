@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"math"
+	"testing"
+	"time"
+
+	compliancev1 "flarrocca/proto/compliance/v1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestComplianceGRPCServerCheckCompliance(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      *compliancev1.CheckComplianceRequest
+		on         func(complianceServiceMock *mock.MockComplianceService)
+		assertFunc func(t *testing.T, resp *compliancev1.CheckComplianceResponse, err error)
+	}{
+		{
+			name: "Success - Payment context forwarded and result mapped",
+			input: &compliancev1.CheckComplianceRequest{UserId: 2, CardId: 3, Amount: 750,
+				Context: &compliancev1.PaymentContext{IpAddress: "203.0.113.7", Email: "john@example.com", DeviceId: "device-1", MerchantId: "merchant-1"}},
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{UserID: 2, CardID: 3, Amount: 750,
+					IPAddress: "203.0.113.7", Email: "john@example.com", DeviceID: "device-1", MerchantID: "merchant-1"}).
+					Return(service.ComplianceResult{Message: "payment amount 750.00 of a high-risk user requires manual review", RiskRating: "high", ManualReview: true}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceResponse, err error) {
+				assert.NoError(t, err)
+				assert.False(t, resp.GetCompliant())
+				assert.True(t, resp.GetManualReview())
+				assert.Equal(t, "high", resp.GetRiskRating())
+				assert.Equal(t, "payment amount 750.00 of a high-risk user requires manual review", resp.GetMessage())
+			},
+		},
+		{
+			name:  "Success - Check without payment context",
+			input: &compliancev1.CheckComplianceRequest{UserId: 1, CardId: 1},
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{UserID: 1, CardID: 1}).
+					Return(service.ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: "low"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceResponse, err error) {
+				assert.NoError(t, err)
+				assert.True(t, resp.GetCompliant())
+			},
+		},
+		{
+			name:  "Failure - Missing card ID",
+			input: &compliancev1.CheckComplianceRequest{UserId: 1},
+			on:    func(complianceServiceMock *mock.MockComplianceService) {},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:  "Failure - Invalid amount",
+			input: &compliancev1.CheckComplianceRequest{UserId: 1, CardId: 1, Amount: math.NaN()},
+			on:    func(complianceServiceMock *mock.MockComplianceService) {},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:  "Failure - Error checking the user",
+			input: &compliancev1.CheckComplianceRequest{UserId: 1, CardId: 1, Amount: 10},
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatus(gomock.Any()).
+					Return(service.ComplianceResult{Message: "error retrieving user cards"}, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceResponse, err error) {
+				assert.Equal(t, codes.Internal, status.Code(err))
+				assert.Equal(t, "error checking user status: error retrieving user cards", status.Convert(err).Message())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceServiceMock := mock.NewMockComplianceService(ctrl)
+			tt.on(complianceServiceMock)
+
+			server := NewComplianceGRPCServer(complianceServiceMock, mock.NewMockCaseService(ctrl))
+			resp, err := server.CheckCompliance(context.Background(), tt.input)
+			tt.assertFunc(t, resp, err)
+		})
+	}
+}
+
+func TestComplianceGRPCServerCheckComplianceBatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      *compliancev1.CheckComplianceBatchRequest
+		on         func(complianceServiceMock *mock.MockComplianceService)
+		assertFunc func(t *testing.T, resp *compliancev1.CheckComplianceBatchResponse, err error)
+	}{
+		{
+			name: "Success - Verdicts in the order of the checks",
+			input: &compliancev1.CheckComplianceBatchRequest{Checks: []*compliancev1.CheckComplianceRequest{
+				{UserId: 2, CardId: 3, Amount: 20, Context: &compliancev1.PaymentContext{MerchantId: "merchant-1"}},
+				{UserId: 1, CardId: 9},
+			}},
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatuses([]service.ComplianceCheck{{UserID: 2, CardID: 3, Amount: 20, MerchantID: "merchant-1"}, {UserID: 1, CardID: 9}}).
+					Return([]service.BatchComplianceResult{
+						{UserID: 2, CardID: 3, ComplianceResult: service.ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: "low"}},
+						{UserID: 1, CardID: 9, ComplianceResult: service.ComplianceResult{Message: "the provided card does not belong to the user"}},
+					}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceBatchResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, resp.GetResults(), 2)
+				assert.True(t, resp.GetResults()[0].GetCompliant())
+				assert.Equal(t, "low", resp.GetResults()[0].GetRiskRating())
+				assert.False(t, resp.GetResults()[1].GetCompliant())
+				assert.Equal(t, "the provided card does not belong to the user", resp.GetResults()[1].GetMessage())
+			},
+		},
+		{
+			name:  "Failure - Missing user ID",
+			input: &compliancev1.CheckComplianceBatchRequest{Checks: []*compliancev1.CheckComplianceRequest{{UserId: 1, CardId: 1}, {CardId: 2}}},
+			on:    func(complianceServiceMock *mock.MockComplianceService) {},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceBatchResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				assert.Equal(t, "check 1: user id and card id are required", status.Convert(err).Message())
+			},
+		},
+		{
+			name:  "Failure - Empty batch",
+			input: &compliancev1.CheckComplianceBatchRequest{},
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatuses([]service.ComplianceCheck{}).Return(nil, service.ErrInvalidBatchCheck)
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceBatchResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:  "Failure - Error checking the users",
+			input: &compliancev1.CheckComplianceBatchRequest{Checks: []*compliancev1.CheckComplianceRequest{{UserId: 1, CardId: 1}}},
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatuses(gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.CheckComplianceBatchResponse, err error) {
+				assert.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceServiceMock := mock.NewMockComplianceService(ctrl)
+			tt.on(complianceServiceMock)
+
+			server := NewComplianceGRPCServer(complianceServiceMock, mock.NewMockCaseService(ctrl))
+			resp, err := server.CheckComplianceBatch(context.Background(), tt.input)
+			tt.assertFunc(t, resp, err)
+		})
+	}
+}
+
+func TestComplianceGRPCServerRequestCardReview(t *testing.T) {
+	occurredAt := time.Date(2025, 2, 27, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		input      *compliancev1.RequestCardReviewRequest
+		on         func(caseServiceMock *mock.MockCaseService)
+		assertFunc func(t *testing.T, resp *compliancev1.RequestCardReviewResponse, err error)
+	}{
+		{
+			name: "Success - Case opened",
+			input: &compliancev1.RequestCardReviewRequest{UserId: 1, CardId: 2, Source: "chargeback",
+				Transactions: []*compliancev1.CaseTransaction{
+					{TransactionId: "txn_1", CardId: 2, Amount: 99.5, OccurredAt: timestamppb.New(occurredAt)},
+					{TransactionId: "txn_2", CardId: 2, Amount: 10},
+				}},
+			on: func(caseServiceMock *mock.MockCaseService) {
+				caseServiceMock.EXPECT().OpenCase(int64(1), int64(2), "chargeback", []repository.CaseTransaction{
+					{TransactionID: "txn_1", CardID: 2, Amount: 99.5, OccurredAt: &occurredAt},
+					{TransactionID: "txn_2", CardID: 2, Amount: 10},
+				}).Return(int64(7), nil)
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.RequestCardReviewResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(7), resp.GetCaseId())
+			},
+		},
+		{
+			name:  "Failure - Unknown source",
+			input: &compliancev1.RequestCardReviewRequest{UserId: 1, CardId: 2, Source: "rumour"},
+			on: func(caseServiceMock *mock.MockCaseService) {
+				caseServiceMock.EXPECT().OpenCase(int64(1), int64(2), "rumour", []repository.CaseTransaction{}).Return(int64(0), service.ErrInvalidCaseSource)
+			},
+			assertFunc: func(t *testing.T, resp *compliancev1.RequestCardReviewResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:  "Failure - Missing source",
+			input: &compliancev1.RequestCardReviewRequest{UserId: 1, CardId: 2},
+			on:    func(caseServiceMock *mock.MockCaseService) {},
+			assertFunc: func(t *testing.T, resp *compliancev1.RequestCardReviewResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			caseServiceMock := mock.NewMockCaseService(ctrl)
+			tt.on(caseServiceMock)
+
+			server := NewComplianceGRPCServer(mock.NewMockComplianceService(ctrl), caseServiceMock)
+			resp, err := server.RequestCardReview(context.Background(), tt.input)
+			tt.assertFunc(t, resp, err)
+		})
+	}
+}
+
+func TestComplianceGRPCServerListBlockedCards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	complianceServiceMock := mock.NewMockComplianceService(ctrl)
+	server := NewComplianceGRPCServer(complianceServiceMock, mock.NewMockCaseService(ctrl))
+
+	complianceServiceMock.EXPECT().ListBlockedCards().Return([]repository.BlockedCard{{UserID: 1, CardID: 2}}, nil)
+	resp, err := server.ListBlockedCards(context.Background(), &compliancev1.ListBlockedCardsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetCards(), 1)
+	assert.Equal(t, int64(2), resp.GetCards()[0].GetCardId())
+
+	complianceServiceMock.EXPECT().ListBlockedCards().Return(nil, errors.New("database error"))
+	_, err = server.ListBlockedCards(context.Background(), &compliancev1.ListBlockedCardsRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
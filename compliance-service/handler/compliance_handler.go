@@ -1,8 +1,15 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
 	"flarrocca/compliant-service/service"
+	"flarrocca/logging"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -17,58 +24,228 @@ func NewUserHandler(complianceService service.ComplianceService) *ComplianceHand
 	return &ComplianceHandler{complianceService: complianceService}
 }
 
+// legacyComplianceResult is the verdict answered by the deprecated /check_user and /check_users, with the misspelled
+// complaiance key their clients still read.
+type legacyComplianceResult struct {
+	IsCompliance   bool                   `json:"complaiance"`
+	Message        string                 `json:"message"`
+	RiskRating     string                 `json:"risk_rating,omitempty"`
+	ManualReview   bool                   `json:"manual_review,omitempty"`
+	MatchedEntries []repository.ListEntry `json:"matched_entries,omitempty"`
+}
+
+type legacyBatchComplianceResult struct {
+	UserID int64 `json:"user_id"`
+	CardID int64 `json:"card_id"`
+	legacyComplianceResult
+}
+
+func newLegacyComplianceResult(result service.ComplianceResult) legacyComplianceResult {
+	return legacyComplianceResult{
+		IsCompliance:   result.IsCompliance,
+		Message:        result.Message,
+		RiskRating:     result.RiskRating,
+		ManualReview:   result.ManualReview,
+		MatchedEntries: result.MatchedEntries,
+	}
+}
+
+// ReportStolenCards is called from the public report page. The deprecated /report_cards answers in plain text.
 func (h *ComplianceHandler) ReportStolenCards(c *fiber.Ctx) error {
 	userName := c.FormValue("user_name")
 	secretCode := c.FormValue("secret_code")
 	if userName == "" || secretCode == "" {
-		return c.Status(http.StatusBadRequest).SendString("user name and secret code are required")
+		return fiber.NewError(http.StatusBadRequest, "user name and secret code are required")
 	}
 
 	message, err := h.complianceService.ReportStolenCards(userName, secretCode)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return fiber.NewError(http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidCredentials):
+			return fiber.NewError(http.StatusUnauthorized, err.Error())
+		}
+		return fiber.NewError(http.StatusInternalServerError, fmt.Sprintf("error reporting cards: %s", err))
 	}
 
-	return c.SendString(message)
+	if api.IsLegacy(c) {
+		return c.SendString(message)
+	}
+	return c.JSON(fiber.Map{"message": message})
 }
 
 func (h *ComplianceHandler) CheckComplianceStatus(c *fiber.Ctx) error {
 	paramUserID := c.Query("user_id")
 	if paramUserID == "" {
-		return c.Status(http.StatusBadRequest).SendString("user id is required")
+		return fiber.NewError(http.StatusBadRequest, "user id is required")
 	}
 
 	paramCardID := c.Query("card_id")
 	if paramCardID == "" {
-		return c.Status(http.StatusBadRequest).SendString("card id is required")
+		return fiber.NewError(http.StatusBadRequest, "card id is required")
 	}
 
-	userID, err := strconv.Atoi(paramUserID)
+	userID, err := strconv.ParseInt(paramUserID, 10, 64)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"complaiance": false,
-			"message":     fmt.Sprintf("invalid data type for user ID: %s", err),
-		})
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
 	}
 
-	cardID, err := strconv.Atoi(paramCardID)
+	cardID, err := strconv.ParseInt(paramCardID, 10, 64)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"complaiance": false,
-			"message":     fmt.Sprintf("invalid data type for card ID: %s", err),
-		})
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for card ID: %s", err))
 	}
 
-	isCompliance, message, err := h.complianceService.CheckComplianceStatus(int64(userID), int64(cardID))
+	var amount float64
+	if paramAmount := c.Query("amount"); paramAmount != "" {
+		amount, err = strconv.ParseFloat(paramAmount, 64)
+		if err != nil || amount < 0 || math.IsNaN(amount) {
+			return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid amount: %s", paramAmount))
+		}
+	}
+
+	result, err := h.complianceService.CheckComplianceStatus(service.ComplianceCheck{
+		UserID:     userID,
+		CardID:     cardID,
+		Amount:     amount,
+		IPAddress:  c.Query("ip_address"),
+		Email:      c.Query("email"),
+		DeviceID:   c.Query("device_id"),
+		MerchantID: c.Query("merchant_id"),
+	})
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"complaiance": isCompliance,
-			"message":     fmt.Sprintf("error checking user status: %s", message),
+		return fiber.NewError(http.StatusInternalServerError, fmt.Sprintf("error checking user status: %s", result.Message))
+	}
+	recordComplianceDecision(c.UserContext(), userID, cardID, result)
+
+	if api.IsLegacy(c) {
+		return c.JSON(newLegacyComplianceResult(result))
+	}
+	return c.JSON(result)
+}
+
+// CheckComplianceStatuses checks many payments at once, e.g. for settlement batches and subscription billing runs. The
+// verdicts are returned in the order of the checks; a card failing its check does not fail the batch.
+func (h *ComplianceHandler) CheckComplianceStatuses(c *fiber.Ctx) error {
+	var req struct {
+		Checks []struct {
+			UserID     int64   `json:"user_id"`
+			CardID     int64   `json:"card_id"`
+			Amount     float64 `json:"amount"`
+			IPAddress  string  `json:"ip_address"`
+			Email      string  `json:"email"`
+			DeviceID   string  `json:"device_id"`
+			MerchantID string  `json:"merchant_id"`
+		} `json:"checks"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	checks := make([]service.ComplianceCheck, 0, len(req.Checks))
+	for i, check := range req.Checks {
+		if check.UserID <= 0 || check.CardID <= 0 {
+			return api.NewError(http.StatusBadRequest, fmt.Sprintf("check %d: user id and card id are required", i)).
+				WithDetails(fiber.Map{"check": i})
+		}
+		if check.Amount < 0 {
+			return api.NewError(http.StatusBadRequest, fmt.Sprintf("check %d: invalid amount: %v", i, check.Amount)).
+				WithDetails(fiber.Map{"check": i})
+		}
+		checks = append(checks, service.ComplianceCheck{
+			UserID:     check.UserID,
+			CardID:     check.CardID,
+			Amount:     check.Amount,
+			IPAddress:  check.IPAddress,
+			Email:      check.Email,
+			DeviceID:   check.DeviceID,
+			MerchantID: check.MerchantID,
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"complaiance": isCompliance,
-		"message":     message,
-	})
+	results, err := h.complianceService.CheckComplianceStatuses(checks)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBatchCheck) {
+			return fiber.NewError(http.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(http.StatusInternalServerError, fmt.Sprintf("error checking user statuses: %s", err))
+	}
+	for _, result := range results {
+		recordComplianceDecision(c.UserContext(), result.UserID, result.CardID, result.ComplianceResult)
+	}
+
+	if api.IsLegacy(c) {
+		legacyResults := make([]legacyBatchComplianceResult, 0, len(results))
+		for _, result := range results {
+			legacyResults = append(legacyResults, legacyBatchComplianceResult{
+				UserID:                 result.UserID,
+				CardID:                 result.CardID,
+				legacyComplianceResult: newLegacyComplianceResult(result.ComplianceResult),
+			})
+		}
+		return c.JSON(fiber.Map{"results": legacyResults})
+	}
+	return c.JSON(fiber.Map{"results": results})
+}
+
+// ListBlockedCards lists the cards a payment would be denied for, to be kept by payment-service as a stand-in snapshot.
+func (h *ComplianceHandler) ListBlockedCards(c *fiber.Ctx) error {
+	cards, err := h.complianceService.ListBlockedCards()
+	if err != nil {
+		return fiber.NewError(http.StatusInternalServerError, fmt.Sprintf("error listing blocked cards: %s", err))
+	}
+
+	return c.JSON(fiber.Map{"blocked_cards": cards})
+}
+
+// ReinstateCard lifts the block of a card reported or imported as compromised by mistake.
+func (h *ComplianceHandler) ReinstateCard(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
+	}
+
+	cardID, err := strconv.ParseInt(c.Params("card_id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for card ID: %s", err))
+	}
+
+	if err := h.complianceService.ReinstateCard(userID, cardID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrCardNotFound):
+			return fiber.NewError(http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrCardNotBlocked):
+			return fiber.NewError(http.StatusConflict, err.Error())
+		}
+		return fiber.NewError(http.StatusInternalServerError, fmt.Sprintf("error reinstating card: %s", err))
+	}
+
+	return c.JSON(fiber.Map{"message": "card reinstated"})
+}
+
+// recordComplianceDecision logs and counts the verdict of a check, compliant, denied or manual_review, with the list
+// entries it matched. It is shared by the HTTP and gRPC APIs.
+func recordComplianceDecision(ctx context.Context, userID int64, cardID int64, result service.ComplianceResult) {
+	decision := "denied"
+	switch {
+	case result.ManualReview:
+		decision = "manual_review"
+	case result.IsCompliance:
+		decision = "compliant"
+	}
+
+	attrs := []slog.Attr{logging.UserID(userID), logging.CardID(cardID), logging.Decision(decision)}
+	if result.RiskRating != "" {
+		attrs = append(attrs, slog.String("risk_rating", result.RiskRating))
+	}
+	if decision != "compliant" {
+		attrs = append(attrs, slog.String("reason", result.Message))
+	}
+	slog.LogAttrs(ctx, slog.LevelInfo, "compliance checked", attrs...)
+
+	complianceChecksTotal.WithLabelValues(decision).Inc()
+	for _, entry := range result.MatchedEntries {
+		listHitsTotal.WithLabelValues(entry.ListType, entry.EntryType).Inc()
+	}
 }
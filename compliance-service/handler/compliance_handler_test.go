@@ -2,6 +2,9 @@ package handler
 
 import (
 	"errors"
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
 	"flarrocca/compliant-service/service/mock"
 	"fmt"
 	"io"
@@ -20,6 +23,7 @@ func TestReportStolenCardHandler(t *testing.T) {
 	type input struct {
 		userName   string
 		secretCode string
+		legacy     bool
 	}
 
 	type output struct {
@@ -49,6 +53,23 @@ func TestReportStolenCardHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusOK, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"message": "all the cards linked to the provided user are now blocked. Contact with @support-team for more information."}`, string(body))
+			},
+		},
+		{
+			name: "Success - Cards reported on the deprecated route",
+			input: input{
+				userName:   "john_doe",
+				secretCode: "secure123",
+				legacy:     true,
+			},
+			on: func(dep *depFields, in input) {
+				dep.complianceServiceMock.EXPECT().ReportStolenCards(in.userName, in.secretCode).Return("all the cards linked to the provided user are now blocked. Contact with @support-team for more information.", nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "true", resp.Header.Get("Deprecation"))
+				body, _ := io.ReadAll(resp.Body)
 				assert.Equal(t, "all the cards linked to the provided user are now blocked. Contact with @support-team for more information.", string(body))
 			},
 		},
@@ -62,15 +83,30 @@ func TestReportStolenCardHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.Equal(t, "user name and secret code are required", string(body))
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user name and secret code are required", "request_id": ""}`, string(body))
 			},
 		},
 		{
-			name: "Failure - Internal service error",
+			name: "Failure - Wrong secret code",
 			input: input{
 				userName:   "john_doe",
 				secretCode: "wrong_secret",
 			},
+			on: func(dep *depFields, in input) {
+				dep.complianceServiceMock.EXPECT().ReportStolenCards(in.userName, in.secretCode).Return("", service.ErrInvalidCredentials)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "unauthenticated", "message": "invalid user name or secret code", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Internal service error",
+			input: input{
+				userName:   "john_doe",
+				secretCode: "secure123",
+			},
 			on: func(dep *depFields, in input) {
 				dep.complianceServiceMock.EXPECT().ReportStolenCards(in.userName, in.secretCode).
 					Return("", errors.New("internal error"))
@@ -78,14 +114,14 @@ func TestReportStolenCardHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.Equal(t, "internal error", string(body))
+				assert.JSONEq(t, `{"code": "internal", "message": "error reporting cards: internal error", "request_id": ""}`, string(body))
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			app := fiber.New()
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
 
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
@@ -94,14 +130,19 @@ func TestReportStolenCardHandler(t *testing.T) {
 			tt.on(&depFields{complianceServiceMock: complianceServiceMock}, tt.input)
 
 			handler := &ComplianceHandler{complianceService: complianceServiceMock}
-			app.Post("/report_cards", handler.ReportStolenCards)
+			app.Use(api.LegacyAliases())
+			app.Post("/v1/report_cards", handler.ReportStolenCards)
 
 			form := url.Values{}
 			form.Set("user_name", tt.input.userName)
 			form.Set("secret_code", tt.input.secretCode)
 			body := strings.NewReader(form.Encode())
 
-			req := httptest.NewRequest(http.MethodPost, "/report_cards", body)
+			path := "/v1/report_cards"
+			if tt.input.legacy {
+				path = "/report_cards"
+			}
+			req := httptest.NewRequest(http.MethodPost, path, body)
 			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 			resp, err := app.Test(req)
@@ -114,8 +155,10 @@ func TestReportStolenCardHandler(t *testing.T) {
 
 func TestCheckComplianceStatusHandler(t *testing.T) {
 	type input struct {
-		userID string
-		cardID string
+		userID       string
+		cardID       string
+		paymentQuery string
+		legacy       bool
 	}
 
 	type depFields struct {
@@ -135,11 +178,28 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 				cardID: "456",
 			},
 			on: func(dep *depFields, in input) {
-				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(int64(123), int64(456)).Return(false, "user is blocked", nil)
+				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{UserID: 123, CardID: 456}).Return(service.ComplianceResult{Message: "user is blocked"}, nil)
 			},
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusOK, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"compliant": false, "message": "user is blocked"}`, string(body))
+			},
+		},
+		{
+			name: "Success - Misspelled key kept on the deprecated route",
+			input: input{
+				userID: "123",
+				cardID: "456",
+				legacy: true,
+			},
+			on: func(dep *depFields, in input) {
+				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{UserID: 123, CardID: 456}).Return(service.ComplianceResult{Message: "user is blocked"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, `</v1/check_user>; rel="successor-version"`, resp.Header.Get("Link"))
+				body, _ := io.ReadAll(resp.Body)
 				assert.JSONEq(t, `{"complaiance": false, "message": "user is blocked"}`, string(body))
 			},
 		},
@@ -150,12 +210,65 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 				cardID: "123",
 			},
 			on: func(dep *depFields, in input) {
-				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(int64(456), int64(123)).Return(true, "user is active", nil)
+				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{UserID: 456, CardID: 123}).Return(service.ComplianceResult{IsCompliance: true, Message: "user is active"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"compliant": true, "message": "user is active"}`, string(body))
+			},
+		},
+		{
+			name: "Success - Payment context checked against the lists",
+			input: input{
+				userID:       "456",
+				cardID:       "123",
+				paymentQuery: "&ip_address=203.0.113.7&email=alice@example.com&device_id=device-1&merchant_id=merchant-1",
+			},
+			on: func(dep *depFields, in input) {
+				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{
+					UserID: 456, CardID: 123, IPAddress: "203.0.113.7", Email: "alice@example.com", DeviceID: "device-1", MerchantID: "merchant-1",
+				}).Return(service.ComplianceResult{
+					Message:        "payment blocked by deny list: ip 203.0.113.0/24 (botnet)",
+					MatchedEntries: []repository.ListEntry{{ID: 1, ListType: "deny", EntryType: "ip", Value: "203.0.113.0/24", Reason: "botnet"}},
+				}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"compliant":false`)
+				assert.Contains(t, string(body), `"matched_entries":[{"id":1,"list_type":"deny","entry_type":"ip","value":"203.0.113.0/24","reason":"botnet"`)
+			},
+		},
+		{
+			name: "Success - Amount checked against the KYC limits",
+			input: input{
+				userID:       "456",
+				cardID:       "123",
+				paymentQuery: "&amount=250.5",
+			},
+			on: func(dep *depFields, in input) {
+				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{UserID: 456, CardID: 123, Amount: 250.5}).
+					Return(service.ComplianceResult{Message: "payment amount 250.50 exceeds the 150.00 limit of KYC tier 0 (unverified), a higher verification tier is required"}, nil)
 			},
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusOK, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.JSONEq(t, `{"complaiance": true, "message": "user is active"}`, string(body))
+				assert.Contains(t, string(body), "limit of KYC tier 0")
+			},
+		},
+		{
+			name: "Failure - Invalid amount",
+			input: input{
+				userID:       "456",
+				cardID:       "123",
+				paymentQuery: "&amount=-5",
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), "invalid amount: -5")
 			},
 		},
 		{
@@ -168,7 +281,7 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.Equal(t, "user id is required", string(body))
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user id is required", "request_id": ""}`, string(body))
 			},
 		},
 		{
@@ -181,7 +294,7 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.Equal(t, "card id is required", string(body))
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "card id is required", "request_id": ""}`, string(body))
 			},
 		},
 		{
@@ -192,7 +305,7 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 			},
 			on: func(dep *depFields, in input) {},
 			assertFunc: func(t *testing.T, resp *http.Response) {
-				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
 				assert.Contains(t, string(body), "invalid data type for user ID")
 			},
@@ -205,7 +318,7 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 			},
 			on: func(dep *depFields, in input) {},
 			assertFunc: func(t *testing.T, resp *http.Response) {
-				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
 				assert.Contains(t, string(body), "invalid data type for card ID")
 			},
@@ -217,19 +330,19 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 				cardID: "456",
 			},
 			on: func(dep *depFields, in input) {
-				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(int64(789), int64(456)).Return(false, "error", errors.New("error checking user status"))
+				dep.complianceServiceMock.EXPECT().CheckComplianceStatus(service.ComplianceCheck{UserID: 789, CardID: 456}).Return(service.ComplianceResult{Message: "error"}, errors.New("error checking user status"))
 			},
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.JSONEq(t, `{"complaiance": false, "message": "error checking user status: error"}`, string(body))
+				assert.JSONEq(t, `{"code": "internal", "message": "error checking user status: error", "request_id": ""}`, string(body))
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			app := fiber.New()
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
 
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
@@ -238,12 +351,263 @@ func TestCheckComplianceStatusHandler(t *testing.T) {
 			tt.on(&depFields{complianceServiceMock: complianceServiceMock}, tt.input)
 
 			handler := &ComplianceHandler{complianceService: complianceServiceMock}
-			app.Get("/check_user", handler.CheckComplianceStatus)
+			app.Use(api.LegacyAliases())
+			app.Get("/v1/check_user", handler.CheckComplianceStatus)
 
-			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/check_user?user_id=%s&card_id=%s", tt.input.userID, tt.input.cardID), nil)
+			path := "/v1/check_user"
+			if tt.input.legacy {
+				path = "/check_user"
+			}
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("%s?user_id=%s&card_id=%s%s", path, tt.input.userID, tt.input.cardID, tt.input.paymentQuery), nil)
 			resp, err := app.Test(req)
 			assert.NoError(t, err)
 			tt.assertFunc(t, resp)
 		})
 	}
 }
+
+func TestCheckComplianceStatusesHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		legacy     bool
+		on         func(complianceServiceMock *mock.MockComplianceService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Verdicts in the order of the checks",
+			body: `{"checks": [{"user_id": 1, "card_id": 2, "amount": 9.99, "merchant_id": "m-1"}, {"user_id": 3, "card_id": 4}]}`,
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatuses([]service.ComplianceCheck{
+					{UserID: 1, CardID: 2, Amount: 9.99, MerchantID: "m-1"},
+					{UserID: 3, CardID: 4},
+				}).Return([]service.BatchComplianceResult{
+					{UserID: 1, CardID: 2, ComplianceResult: service.ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: "low"}},
+					{UserID: 3, CardID: 4, ComplianceResult: service.ComplianceResult{Message: "the provided card does not belong to the user"}},
+				}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"results": [
+					{"user_id": 1, "card_id": 2, "compliant": true, "message": "user is compliance", "risk_rating": "low"},
+					{"user_id": 3, "card_id": 4, "compliant": false, "message": "the provided card does not belong to the user"}
+				]}`, string(body))
+			},
+		},
+		{
+			name:   "Success - Misspelled key kept on the deprecated route",
+			body:   `{"checks": [{"user_id": 1, "card_id": 2}]}`,
+			legacy: true,
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatuses([]service.ComplianceCheck{{UserID: 1, CardID: 2}}).
+					Return([]service.BatchComplianceResult{
+						{UserID: 1, CardID: 2, ComplianceResult: service.ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: "low"}},
+					}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"results": [
+					{"user_id": 1, "card_id": 2, "complaiance": true, "message": "user is compliance", "risk_rating": "low"}
+				]}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Missing card ID",
+			body: `{"checks": [{"user_id": 1, "card_id": 2}, {"user_id": 3}]}`,
+			on:   func(complianceServiceMock *mock.MockComplianceService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "check 1: user id and card id are required", "request_id": "",
+					"details": {"check": 1}}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid payload",
+			body: `{"checks": {}}`,
+			on:   func(complianceServiceMock *mock.MockComplianceService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Empty batch",
+			body: `{"checks": []}`,
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatuses([]service.ComplianceCheck{}).
+					Return(nil, fmt.Errorf("%w: between 1 and 1000 checks are required", service.ErrInvalidBatchCheck))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			body: `{"checks": [{"user_id": 1, "card_id": 2}]}`,
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().CheckComplianceStatuses(gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceServiceMock := mock.NewMockComplianceService(ctrl)
+			tt.on(complianceServiceMock)
+
+			handler := &ComplianceHandler{complianceService: complianceServiceMock}
+			app.Use(api.LegacyAliases())
+			app.Post("/v1/check_users", handler.CheckComplianceStatuses)
+
+			path := "/v1/check_users"
+			if tt.legacy {
+				path = "/check_users"
+			}
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestListBlockedCardsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		on         func(complianceServiceMock *mock.MockComplianceService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Blocked cards listed",
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().ListBlockedCards().Return([]repository.BlockedCard{{UserID: 1, CardID: 2}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"blocked_cards": [{"user_id": 1, "card_id": 2}]}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Database error",
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().ListBlockedCards().Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceServiceMock := mock.NewMockComplianceService(ctrl)
+			tt.on(complianceServiceMock)
+
+			handler := &ComplianceHandler{complianceService: complianceServiceMock}
+			app.Get("/blocked_cards", handler.ListBlockedCards)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/blocked_cards", nil))
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestReinstateCardHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		on         func(complianceServiceMock *mock.MockComplianceService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Card reinstated",
+			url:  "/users/3/cards/7/reinstate",
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().ReinstateCard(int64(3), int64(7)).Return(nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"message": "card reinstated"}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Card not found",
+			url:  "/users/3/cards/8/reinstate",
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().ReinstateCard(int64(3), int64(8)).Return(service.ErrCardNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Card not blocked",
+			url:  "/users/3/cards/7/reinstate",
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().ReinstateCard(int64(3), int64(7)).Return(service.ErrCardNotBlocked)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "conflict", "message": "card is not blocked", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid card ID",
+			url:  "/users/3/cards/abc/reinstate",
+			on:   func(complianceServiceMock *mock.MockComplianceService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			url:  "/users/3/cards/7/reinstate",
+			on: func(complianceServiceMock *mock.MockComplianceService) {
+				complianceServiceMock.EXPECT().ReinstateCard(int64(3), int64(7)).Return(errors.New("database locked"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceServiceMock := mock.NewMockComplianceService(ctrl)
+			tt.on(complianceServiceMock)
+
+			handler := &ComplianceHandler{complianceService: complianceServiceMock}
+			app.Post("/users/:id/cards/:card_id/reinstate", handler.ReinstateCard)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, tt.url, nil))
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"database/sql"
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/contract"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// providerStates puts the database seeded by init.sql in the states the consumers record their interactions in.
+var providerStates = map[string][]string{
+	"user 1 owns card 1": nil,
+	"user 2 is rated high risk": {
+		`INSERT INTO user_risk_ratings (user_id, rating, source, reason, rated_at)
+			VALUES (2, 'high', 'pep', 'matches PEP list entry', CURRENT_TIMESTAMP)`,
+		`INSERT INTO kyc_profiles (user_id, legal_name, date_of_birth, address_line, city, country, nationality,
+			id_document_type, id_document_number, id_document_last4, status, tier, submitted_at)
+			VALUES (2, 'Jane Smith', '1985-04-12', '1 Main Street', 'London', 'GB', 'GB', 'passport', 'encrypted', '6789',
+			'verified', 2, CURRENT_TIMESTAMP)`,
+	},
+	"card 2 of user 1 is reported stolen": {
+		`INSERT INTO reported_cards (user_id, card_id) VALUES (1, 2)`,
+	},
+}
+
+// consumerPayments are the payments recorded by payment-service, read back by compliance-service to check the
+// transactions attached to the cases payment-service opens.
+var consumerPayments = map[string]string{
+	"/v1/users/1/payments": `{"payments": [{"id": "txn_123456", "card_id": 1, "amount": 100.5, "status": "approved",
+		"created_at": "2025-02-27T18:30:00Z"}]}`,
+	"/v1/users/2/payments": `{"payments": [{"id": "txn_654321", "card_id": 3, "amount": 750, "status": "pending_review",
+		"created_at": "2025-03-01T10:00:00Z"}]}`,
+}
+
+// newContractTestApp serves the routes called by payment-service with the real handlers, services and repositories,
+// on a database seeded by init.sql and put in the state.
+func newContractTestApp(t *testing.T, state string) *fiber.App {
+	statements, found := providerStates[state]
+	if !found {
+		t.Fatalf("unknown provider state %q", state)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "compliance.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	initSQL, err := os.ReadFile(filepath.Join("..", "database", "init.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range append([]string{string(initSQL)}, statements...) {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("error setting up state %q: %v", state, err)
+		}
+	}
+
+	paymentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payments, found := consumerPayments[r.URL.Path]
+		if !found {
+			payments = `{"payments": []}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(payments))
+	}))
+	t.Cleanup(paymentService.Close)
+	t.Setenv("PAYMENT_SERVICE_URL", paymentService.URL)
+
+	userRepository := repository.NewUserRepository(db)
+	cardRepository := repository.NewCardRepository(db)
+	stolenCardRepository := repository.NewStolenCardRepository(db)
+	caseRepository := repository.NewCaseRepository(db)
+	complianceService := service.NewComplianceService(userRepository, cardRepository, stolenCardRepository, caseRepository,
+		repository.NewPaymentRepository(nil), repository.NewListEntryRepository(db), repository.NewSanctionsRepository(db),
+		repository.NewKYCRepository(db), repository.NewPEPRepository(db))
+	complianceHandler := NewUserHandler(complianceService)
+	caseHandler := NewCaseHandler(service.NewCaseService(caseRepository, userRepository, cardRepository, stolenCardRepository,
+		repository.NewPaymentRepository(nil)))
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	v1 := app.Group(api.Prefix)
+	v1.Get("/check_user", complianceHandler.CheckComplianceStatus)
+	v1.Post("/check_users", complianceHandler.CheckComplianceStatuses)
+	v1.Get("/blocked_cards", complianceHandler.ListBlockedCards)
+	v1.Post("/cases", caseHandler.OpenCase)
+
+	return app
+}
+
+func TestPaymentServiceContract(t *testing.T) {
+	pact, err := contract.Read(filepath.Join("..", "..", contract.Dir), "payment-service", "compliance-service")
+	if err != nil {
+		t.Fatalf("error reading the pact of payment-service, written by its test suite: %v", err)
+	}
+
+	contract.Verify(t, pact, func(t *testing.T, state string) func(*http.Request) (*http.Response, error) {
+		app := newContractTestApp(t, state)
+		return func(req *http.Request) (*http.Response, error) {
+			return app.Test(req)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultEventLimit = 100
+	defaultEventWait  = 30 * time.Second
+	maxEventWait      = time.Minute
+
+	eventStreamHeartbeat   = 15 * time.Second
+	eventStreamMaxDuration = 10 * time.Minute
+	eventStreamRetry       = 3 * time.Second
+)
+
+type EventHandler struct {
+	eventService service.EventService
+	// heartbeat is how often a comment is sent on an idle stream, and maxStream how long a stream lasts before the
+	// client has to reconnect with the Last-Event-ID it got.
+	heartbeat time.Duration
+	maxStream time.Duration
+}
+
+func NewEventHandler(eventService service.EventService) *EventHandler {
+	return &EventHandler{eventService: eventService, heartbeat: eventStreamHeartbeat, maxStream: eventStreamMaxDuration}
+}
+
+type eventQuery struct {
+	after      int64
+	eventTypes []string
+	limit      int
+	wait       time.Duration
+}
+
+// ListEvents returns the events after the cursor given by the after query parameter or the Last-Event-ID header.
+// Clients accepting text/event-stream get them as server-sent events. Others get a JSON batch, waiting up to the
+// wait query parameter for the first event, and resume from its next_cursor.
+func (h *EventHandler) ListEvents(c *fiber.Ctx) error {
+	query, err := parseEventQuery(c)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if strings.Contains(c.Get(fiber.HeaderAccept), "text/event-stream") {
+		return h.streamEvents(c, query)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), query.wait)
+	defer cancel()
+	events, err := h.eventService.WaitForEvents(ctx, query.after, query.eventTypes, query.limit)
+	if err != nil {
+		return eventErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"events": events, "next_cursor": nextCursor(query.after, events)})
+}
+
+// streamEvents checks the cursor before the stream starts, so an expired one is answered with an error status.
+func (h *EventHandler) streamEvents(c *fiber.Ctx, query eventQuery) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	events, err := h.eventService.WaitForEvents(ctx, query.after, query.eventTypes, query.limit)
+	if err != nil {
+		return eventErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		fmt.Fprintf(w, "retry: %d\n\n", eventStreamRetry.Milliseconds())
+		after := writeEvents(w, query.after, events)
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		deadline := time.Now().Add(h.maxStream)
+		for wait := time.Until(deadline); wait > 0; wait = time.Until(deadline) {
+			ctx, cancel := context.WithTimeout(context.Background(), min(wait, h.heartbeat))
+			events, err := h.eventService.WaitForEvents(ctx, after, query.eventTypes, query.limit)
+			cancel()
+			if err != nil {
+				data, _ := json.Marshal(fiber.Map{"message": err.Error()})
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+				w.Flush()
+				return
+			}
+
+			if len(events) == 0 {
+				fmt.Fprint(w, ": keepalive\n\n")
+			}
+			after = writeEvents(w, after, events)
+			// fails once the client is gone
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeEvents writes the events in the server-sent events format and returns the cursor after them.
+func writeEvents(w *bufio.Writer, after int64, events []repository.Event) int64 {
+	for _, event := range events {
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	}
+	return nextCursor(after, events)
+}
+
+func nextCursor(after int64, events []repository.Event) int64 {
+	if len(events) == 0 {
+		return after
+	}
+	return events[len(events)-1].ID
+}
+
+func parseEventQuery(c *fiber.Ctx) (eventQuery, error) {
+	query := eventQuery{limit: defaultEventLimit, wait: defaultEventWait}
+
+	cursor := c.Query("after", c.Get("Last-Event-ID"))
+	if cursor != "" {
+		after, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid data type for cursor: %s", err)
+		}
+		query.after = after
+	}
+
+	for _, eventType := range strings.Split(c.Query("types"), ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			query.eventTypes = append(query.eventTypes, eventType)
+		}
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return query, fmt.Errorf("invalid data type for limit: %s", err)
+		}
+		query.limit = value
+	}
+
+	if wait := c.Query("wait"); wait != "" {
+		value, err := time.ParseDuration(wait)
+		if err != nil || value < 0 || value > maxEventWait {
+			return query, fmt.Errorf("wait must be a duration between 0s and %s", maxEventWait)
+		}
+		query.wait = value
+	}
+
+	return query, nil
+}
+
+func eventErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrCursorExpired):
+		status = http.StatusGone
+	case errors.Is(err, service.ErrInvalidEventQuery):
+		status = http.StatusBadRequest
+	}
+	return fiber.NewError(status, err.Error())
+}
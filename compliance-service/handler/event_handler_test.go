@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEventApp(eventServiceMock *mock.MockEventService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &EventHandler{eventService: eventServiceMock, heartbeat: 10 * time.Millisecond, maxStream: 50 * time.Millisecond}
+
+	app.Get("/events", handler.ListEvents)
+
+	return app
+}
+
+func TestEventHandler(t *testing.T) {
+	reported := repository.Event{ID: 42, Type: repository.EventCardReported, Payload: []byte(`{"user_id":3,"card_id":7}`),
+		CreatedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)}
+
+	type input struct {
+		path    string
+		headers map[string]string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(eventServiceMock *mock.MockEventService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:  "Success - Long-poll returns the events and the cursor to resume from",
+			input: input{path: "/events?after=41&types=CardReported,%20UserLocked&limit=10&wait=5s"},
+			on: func(eventServiceMock *mock.MockEventService) {
+				eventServiceMock.EXPECT().WaitForEvents(gomock.Any(), int64(41), []string{repository.EventCardReported, repository.EventUserLocked}, 10).
+					DoAndReturn(func(ctx context.Context, after int64, eventTypes []string, limit int) ([]repository.Event, error) {
+						deadline, _ := ctx.Deadline()
+						assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+						return []repository.Event{reported}, nil
+					})
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"events":[{"id":42,"type":"CardReported","payload":{"user_id":3,"card_id":7},"created_at":"2025-03-01T10:00:00Z"}],
+					"next_cursor":42}`, string(body))
+			},
+		},
+		{
+			name:  "Success - Nothing new keeps the cursor",
+			input: input{path: "/events?wait=0s", headers: map[string]string{"Last-Event-ID": "42"}},
+			on: func(eventServiceMock *mock.MockEventService) {
+				eventServiceMock.EXPECT().WaitForEvents(gomock.Any(), int64(42), nil, defaultEventLimit).Return([]repository.Event{}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"events":[],"next_cursor":42}`, string(body))
+			},
+		},
+		{
+			name:  "Success - Events streamed with heartbeats",
+			input: input{path: "/events?after=41", headers: map[string]string{"Accept": "text/event-stream"}},
+			on: func(eventServiceMock *mock.MockEventService) {
+				gomock.InOrder(
+					eventServiceMock.EXPECT().WaitForEvents(gomock.Any(), int64(41), nil, defaultEventLimit).Return([]repository.Event{reported}, nil),
+					eventServiceMock.EXPECT().WaitForEvents(gomock.Any(), int64(42), nil, defaultEventLimit).
+						DoAndReturn(func(ctx context.Context, after int64, eventTypes []string, limit int) ([]repository.Event, error) {
+							<-ctx.Done()
+							return []repository.Event{}, nil
+						}).MinTimes(1),
+				)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "text/event-stream", resp.Header.Get(fiber.HeaderContentType))
+
+				reader := bufio.NewReader(resp.Body)
+				var lines []string
+				for i := 0; i < 6; i++ {
+					line, err := reader.ReadString('\n')
+					assert.NoError(t, err)
+					lines = append(lines, line)
+				}
+				assert.Equal(t, []string{"retry: 3000\n", "\n", "id: 42\n", "event: CardReported\n",
+					`data: {"id":42,"type":"CardReported","payload":{"user_id":3,"card_id":7},"created_at":"2025-03-01T10:00:00Z"}` + "\n", "\n"}, lines)
+
+				rest, _ := io.ReadAll(reader)
+				assert.Contains(t, string(rest), ": keepalive\n\n")
+			},
+		},
+		{
+			name:  "Failure - Expired cursor",
+			input: input{path: "/events?after=10", headers: map[string]string{"Accept": "text/event-stream"}},
+			on: func(eventServiceMock *mock.MockEventService) {
+				eventServiceMock.EXPECT().WaitForEvents(gomock.Any(), int64(10), nil, defaultEventLimit).
+					Return(nil, fmt.Errorf("%w, resume from cursor 29", service.ErrCursorExpired))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusGone, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code":"not_found","message":"events after the cursor were purged, resume from cursor 29","request_id":""}`, string(body))
+			},
+		},
+		{
+			name:  "Failure - Unknown event type",
+			input: input{path: "/events?types=CardStolen"},
+			on: func(eventServiceMock *mock.MockEventService) {
+				eventServiceMock.EXPECT().WaitForEvents(gomock.Any(), int64(0), []string{"CardStolen"}, defaultEventLimit).
+					Return(nil, fmt.Errorf("%w: unknown event type CardStolen", service.ErrInvalidEventQuery))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Failure - Invalid cursor",
+			input: input{path: "/events?after=abc"},
+			on:    func(eventServiceMock *mock.MockEventService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Failure - Wait too long",
+			input: input{path: "/events?wait=5m"},
+			on:    func(eventServiceMock *mock.MockEventService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code":"invalid_argument","message":"wait must be a duration between 0s and 1m0s","request_id":""}`, string(body))
+			},
+		},
+		{
+			name:  "Failure - Service error",
+			input: input{path: "/events"},
+			on: func(eventServiceMock *mock.MockEventService) {
+				eventServiceMock.EXPECT().WaitForEvents(gomock.Any(), int64(0), nil, defaultEventLimit).Return(nil, errors.New("database is locked"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eventServiceMock := mock.NewMockEventService(ctrl)
+			tt.on(eventServiceMock)
+
+			req := httptest.NewRequest(http.MethodGet, tt.input.path, nil)
+			for name, value := range tt.input.headers {
+				req.Header.Set(name, value)
+			}
+
+			resp, err := newTestEventApp(eventServiceMock).Test(req, -1)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type KYCHandler struct {
+	kycService service.KYCService
+}
+
+func NewKYCHandler(kycService service.KYCService) *KYCHandler {
+	return &KYCHandler{kycService: kycService}
+}
+
+func (h *KYCHandler) SubmitProfile(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
+	}
+
+	var req service.KYCSubmission
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	profile, err := h.kycService.SubmitProfile(userID, req)
+	if err != nil {
+		return kycErrorResponse(err)
+	}
+
+	return c.JSON(profile)
+}
+
+func (h *KYCHandler) GetProfile(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
+	}
+
+	profile, err := h.kycService.GetProfile(userID)
+	if err != nil {
+		return kycErrorResponse(err)
+	}
+
+	return c.JSON(profile)
+}
+
+func (h *KYCHandler) ListProfiles(c *fiber.Ctx) error {
+	profiles, err := h.kycService.ListProfiles(c.Query("status"))
+	if err != nil {
+		return kycErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"profiles": profiles})
+}
+
+func (h *KYCHandler) ReviewProfile(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
+	}
+
+	var req struct {
+		Status   string `json:"status"`
+		Tier     int    `json:"tier"`
+		Reviewer string `json:"reviewer"`
+		Note     string `json:"note"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	profile, err := h.kycService.ReviewProfile(userID, req.Status, req.Tier, req.Reviewer, req.Note)
+	if err != nil {
+		return kycErrorResponse(err)
+	}
+
+	return c.JSON(profile)
+}
+
+// RevealIDDocument returns the decrypted ID document number, so a reviewer can check it against the document.
+func (h *KYCHandler) RevealIDDocument(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
+	}
+
+	documentNumber, err := h.kycService.RevealIDDocumentNumber(userID)
+	if err != nil {
+		return kycErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"user_id": userID, "id_document_number": documentNumber})
+}
+
+func kycErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrKYCProfileNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrKYCProfileNotPending):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidKYCProfile), errors.Is(err, service.ErrInvalidKYCReview):
+		status = http.StatusBadRequest
+	}
+
+	return fiber.NewError(status, err.Error())
+}
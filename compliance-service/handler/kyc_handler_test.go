@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKYCApp(kycServiceMock *mock.MockKYCService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &KYCHandler{kycService: kycServiceMock}
+
+	app.Get("/kyc", handler.ListProfiles)
+	app.Get("/users/:id/kyc", handler.GetProfile)
+	app.Put("/users/:id/kyc", handler.SubmitProfile)
+	app.Put("/users/:id/kyc/review", handler.ReviewProfile)
+	app.Get("/users/:id/kyc/id_document", handler.RevealIDDocument)
+
+	return app
+}
+
+func TestKYCHandler(t *testing.T) {
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	type depFields struct {
+		kycServiceMock *mock.MockKYCService
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Profile submitted without echoing the document number",
+			input: input{
+				method: http.MethodPut,
+				path:   "/users/1/kyc",
+				body: `{"legal_name": "John Doe", "date_of_birth": "1990-05-17", "address_line": "1 Main St", "city": "Springfield", "country": "US",
+					"nationality": "US", "id_document_type": "passport", "id_document_number": "X123456789"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.kycServiceMock.EXPECT().SubmitProfile(int64(1), service.KYCSubmission{LegalName: "John Doe", DateOfBirth: "1990-05-17", AddressLine: "1 Main St",
+					City: "Springfield", Country: "US", Nationality: "US", IDDocumentType: "passport", IDDocumentNumber: "X123456789"}).
+					Return(repository.KYCProfile{UserID: 1, LegalName: "John Doe", IDDocumentNumber: "ciphertext", IDDocumentLast4: "6789", Status: "pending"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"status":"pending"`)
+				assert.Contains(t, string(body), `"id_document_last4":"6789"`)
+				assert.NotContains(t, string(body), "ciphertext")
+			},
+		},
+		{
+			name: "Failure - Invalid profile",
+			input: input{
+				method: http.MethodPut,
+				path:   "/users/1/kyc",
+				body:   `{"legal_name": "John Doe"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.kycServiceMock.EXPECT().SubmitProfile(int64(1), gomock.Any()).
+					Return(repository.KYCProfile{}, fmt.Errorf("%w: address line must be between 1 and 256 characters", service.ErrInvalidKYCProfile))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Unknown user",
+			input: input{
+				method: http.MethodGet,
+				path:   "/users/9/kyc",
+			},
+			on: func(dep *depFields, in input) {
+				dep.kycServiceMock.EXPECT().GetProfile(int64(9)).Return(repository.KYCProfile{}, service.ErrUserNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Pending profiles listed",
+			input: input{
+				method: http.MethodGet,
+				path:   "/kyc?status=pending",
+			},
+			on: func(dep *depFields, in input) {
+				dep.kycServiceMock.EXPECT().ListProfiles("pending").Return([]repository.KYCProfile{{UserID: 1, Status: "pending"}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"profiles":[{"user_id":1`)
+			},
+		},
+		{
+			name: "Success - Profile verified",
+			input: input{
+				method: http.MethodPut,
+				path:   "/users/1/kyc/review",
+				body:   `{"status": "verified", "tier": 2, "reviewer": "alice"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.kycServiceMock.EXPECT().ReviewProfile(int64(1), "verified", 2, "alice", "").
+					Return(repository.KYCProfile{UserID: 1, Status: "verified", Tier: 2, Reviewer: "alice"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"tier":2`)
+			},
+		},
+		{
+			name: "Failure - Profile not pending",
+			input: input{
+				method: http.MethodPut,
+				path:   "/users/1/kyc/review",
+				body:   `{"status": "rejected", "reviewer": "alice", "note": "forged"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.kycServiceMock.EXPECT().ReviewProfile(int64(1), "rejected", 0, "alice", "forged").Return(repository.KYCProfile{}, service.ErrKYCProfileNotPending)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Document number revealed",
+			input: input{
+				method: http.MethodGet,
+				path:   "/users/1/kyc/id_document",
+			},
+			on: func(dep *depFields, in input) {
+				dep.kycServiceMock.EXPECT().RevealIDDocumentNumber(int64(1)).Return("X123456789", nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"user_id": 1, "id_document_number": "X123456789"}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid user ID",
+			input: input{
+				method: http.MethodGet,
+				path:   "/users/abc/kyc",
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			kycServiceMock := mock.NewMockKYCService(ctrl)
+			tt.on(&depFields{kycServiceMock: kycServiceMock}, tt.input)
+
+			app := newTestKYCApp(kycServiceMock)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ListHandler struct {
+	listService service.ListService
+}
+
+func NewListHandler(listService service.ListService) *ListHandler {
+	return &ListHandler{listService: listService}
+}
+
+func (h *ListHandler) ListEntries(c *fiber.Ctx) error {
+	entries, err := h.listService.ListEntries(c.Query("list_type"), c.Query("entry_type"))
+	if err != nil {
+		return listErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"entries": entries})
+}
+
+func (h *ListHandler) AddEntry(c *fiber.Ctx) error {
+	var req repository.ListEntry
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	entry, err := h.listService.AddEntry(req)
+	if err != nil {
+		return listErrorResponse(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(entry)
+}
+
+// ImportEntries accepts either a JSON body {"entries": [...]} or a CSV file with the header
+// list_type,entry_type,value,reason,expires_at where expires_at is optional and in RFC 3339 format.
+func (h *ListHandler) ImportEntries(c *fiber.Ctx) error {
+	var entries []repository.ListEntry
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), "text/csv") {
+		parsed, err := parseListEntriesCSV(bytes.NewReader(c.Body()))
+		if err != nil {
+			return fiber.NewError(http.StatusBadRequest, err.Error())
+		}
+		entries = parsed
+	} else {
+		var req struct {
+			Entries []repository.ListEntry `json:"entries"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+		}
+		entries = req.Entries
+	}
+
+	imported, err := h.listService.ImportEntries(entries)
+	if err != nil {
+		return listErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"imported": imported})
+}
+
+func (h *ListHandler) UpdateEntry(c *fiber.Ctx) error {
+	entryID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for list entry ID: %s", err))
+	}
+
+	var req struct {
+		Reason    string     `json:"reason"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	entry, err := h.listService.UpdateEntry(entryID, req.Reason, req.ExpiresAt)
+	if err != nil {
+		return listErrorResponse(err)
+	}
+
+	return c.JSON(entry)
+}
+
+func (h *ListHandler) DeleteEntry(c *fiber.Ctx) error {
+	entryID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for list entry ID: %s", err))
+	}
+
+	if err := h.listService.DeleteEntry(entryID); err != nil {
+		return listErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"message": fmt.Sprintf("list entry %d deleted", entryID)})
+}
+
+func parseListEntriesCSV(r io.Reader) ([]repository.ListEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("invalid CSV: missing header")
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"list_type", "entry_type", "value", "reason"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid CSV: missing column %s", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []repository.ListEntry
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %s", err)
+		}
+
+		entry := repository.ListEntry{
+			ListType:  field(record, "list_type"),
+			EntryType: field(record, "entry_type"),
+			Value:     field(record, "value"),
+			Reason:    field(record, "reason"),
+		}
+		if expiresAt := field(record, "expires_at"); expiresAt != "" {
+			parsed, err := time.Parse(time.RFC3339, expiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid expires_at: %s", row, expiresAt)
+			}
+			entry.ExpiresAt = &parsed
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func listErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrListEntryNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrListEntryExists):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidListEntry):
+		status = http.StatusBadRequest
+	}
+
+	return fiber.NewError(status, err.Error())
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestListApp(listServiceMock *mock.MockListService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &ListHandler{listService: listServiceMock}
+
+	app.Get("/lists", handler.ListEntries)
+	app.Post("/lists", handler.AddEntry)
+	app.Post("/lists/import", handler.ImportEntries)
+	app.Put("/lists/:id", handler.UpdateEntry)
+	app.Delete("/lists/:id", handler.DeleteEntry)
+
+	return app
+}
+
+func TestListHandler(t *testing.T) {
+	expiresAt := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+	}
+
+	type depFields struct {
+		listServiceMock *mock.MockListService
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Entry added",
+			input: input{
+				method: http.MethodPost,
+				path:   "/lists",
+				body:   `{"list_type": "deny", "entry_type": "ip", "value": "203.0.113.0/24", "reason": "botnet"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.listServiceMock.EXPECT().AddEntry(repository.ListEntry{ListType: "deny", EntryType: "ip", Value: "203.0.113.0/24", Reason: "botnet"}).
+					Return(repository.ListEntry{ID: 3, ListType: "deny", EntryType: "ip", Value: "203.0.113.0/24", Reason: "botnet"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"id":3`)
+			},
+		},
+		{
+			name: "Failure - Entry already exists",
+			input: input{
+				method: http.MethodPost,
+				path:   "/lists",
+				body:   `{"list_type": "deny", "entry_type": "ip", "value": "203.0.113.0/24", "reason": "botnet"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.listServiceMock.EXPECT().AddEntry(gomock.Any()).Return(repository.ListEntry{}, service.ErrListEntryExists)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - CSV imported",
+			input: input{
+				method:      http.MethodPost,
+				path:        "/lists/import",
+				contentType: "text/csv",
+				body:        "list_type,entry_type,value,reason,expires_at\ndeny,device_id,device-1,emulator,2025-04-01T00:00:00Z\nallow,ip,203.0.113.7,office,\n",
+			},
+			on: func(dep *depFields, in input) {
+				dep.listServiceMock.EXPECT().ImportEntries([]repository.ListEntry{
+					{ListType: "deny", EntryType: "device_id", Value: "device-1", Reason: "emulator", ExpiresAt: &expiresAt},
+					{ListType: "allow", EntryType: "ip", Value: "203.0.113.7", Reason: "office"},
+				}).Return(2, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"imported": 2}`, string(body))
+			},
+		},
+		{
+			name: "Failure - CSV with invalid expiry",
+			input: input{
+				method:      http.MethodPost,
+				path:        "/lists/import",
+				contentType: "text/csv",
+				body:        "list_type,entry_type,value,reason,expires_at\ndeny,device_id,device-1,emulator,next week\n",
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "row 1: invalid expires_at: next week", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid row in JSON import",
+			input: input{
+				method: http.MethodPost,
+				path:   "/lists/import",
+				body:   `{"entries": [{"list_type": "deny", "entry_type": "ip", "value": "not-an-ip", "reason": "botnet"}]}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.listServiceMock.EXPECT().ImportEntries(gomock.Any()).
+					Return(0, fmt.Errorf("row 1: %w: invalid IP address or CIDR: not-an-ip", service.ErrInvalidListEntry))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Entry updated",
+			input: input{
+				method: http.MethodPut,
+				path:   "/lists/3",
+				body:   `{"reason": "confirmed botnet", "expires_at": "2025-04-01T00:00:00Z"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.listServiceMock.EXPECT().UpdateEntry(int64(3), "confirmed botnet", &expiresAt).
+					Return(repository.ListEntry{ID: 3, Reason: "confirmed botnet", ExpiresAt: &expiresAt}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Deleted entry not found",
+			input: input{
+				method: http.MethodDelete,
+				path:   "/lists/9",
+			},
+			on: func(dep *depFields, in input) {
+				dep.listServiceMock.EXPECT().DeleteEntry(int64(9)).Return(service.ErrListEntryNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			listServiceMock := mock.NewMockListService(ctrl)
+			tt.on(&depFields{listServiceMock: listServiceMock}, tt.input)
+
+			app := newTestListApp(listServiceMock)
+
+			contentType := tt.input.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", contentType)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	complianceChecksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "compliance_checks_total",
+		Help: "Payments checked over HTTP or gRPC, by decision (compliant, denied or manual_review).",
+	}, []string{"decision"})
+
+	listHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "compliance_list_hits_total",
+		Help: "Deny and allow list entries matched by the checked payments, by list type and entry type.",
+	}, []string{"list_type", "entry_type"})
+)
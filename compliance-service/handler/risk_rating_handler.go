@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RiskRatingHandler struct {
+	pepService service.PEPService
+}
+
+func NewRiskRatingHandler(pepService service.PEPService) *RiskRatingHandler {
+	return &RiskRatingHandler{pepService: pepService}
+}
+
+// RefreshPEPList reloads the PEP list file and rates every user against it.
+func (h *RiskRatingHandler) RefreshPEPList(c *fiber.Ctx) error {
+	refresh, err := h.pepService.RefreshPEPList()
+	if err != nil {
+		return riskRatingErrorResponse(err)
+	}
+
+	return c.JSON(refresh)
+}
+
+func (h *RiskRatingHandler) ListRiskRatings(c *fiber.Ctx) error {
+	ratings, err := h.pepService.ListRiskRatings(c.Query("rating"))
+	if err != nil {
+		return riskRatingErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"risk_ratings": ratings})
+}
+
+func (h *RiskRatingHandler) GetRiskRating(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
+	}
+
+	rating, err := h.pepService.GetRiskRating(userID)
+	if err != nil {
+		return riskRatingErrorResponse(err)
+	}
+
+	return c.JSON(rating)
+}
+
+func (h *RiskRatingHandler) SetRiskRating(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for user ID: %s", err))
+	}
+
+	var req struct {
+		Rating   string `json:"rating"`
+		Reviewer string `json:"reviewer"`
+		Reason   string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	rating, err := h.pepService.SetRiskRating(userID, req.Rating, req.Reviewer, req.Reason)
+	if err != nil {
+		return riskRatingErrorResponse(err)
+	}
+
+	return c.JSON(rating)
+}
+
+func riskRatingErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrRiskRatingNotFound), errors.Is(err, service.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidRiskRating):
+		status = http.StatusBadRequest
+	}
+
+	return fiber.NewError(status, err.Error())
+}
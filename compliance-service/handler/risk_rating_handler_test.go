@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRiskRatingApp(pepServiceMock *mock.MockPEPService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := NewRiskRatingHandler(pepServiceMock)
+
+	app.Post("/pep/refresh", handler.RefreshPEPList)
+	app.Get("/risk_ratings", handler.ListRiskRatings)
+	app.Get("/users/:id/risk_rating", handler.GetRiskRating)
+	app.Put("/users/:id/risk_rating", handler.SetRiskRating)
+
+	return app
+}
+
+func TestRiskRatingHandler(t *testing.T) {
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(pepServiceMock *mock.MockPEPService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:  "Success - PEP list refreshed",
+			input: input{method: http.MethodPost, path: "/pep/refresh"},
+			on: func(pepServiceMock *mock.MockPEPService) {
+				pepServiceMock.EXPECT().RefreshPEPList().Return(service.PEPRefresh{Entries: 4, UsersScreened: 3, MediumRisk: 1, HighRisk: 1}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"high_risk":1`)
+			},
+		},
+		{
+			name:  "Success - High risk users listed",
+			input: input{method: http.MethodGet, path: "/risk_ratings?rating=high"},
+			on: func(pepServiceMock *mock.MockPEPService) {
+				pepServiceMock.EXPECT().ListRiskRatings("high").Return([]repository.RiskRating{{UserID: 2, Rating: "high", Source: "screening"}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"risk_ratings":[{"user_id":2,"rating":"high"`)
+			},
+		},
+		{
+			name:  "Failure - Unknown rating filter",
+			input: input{method: http.MethodGet, path: "/risk_ratings?rating=critical"},
+			on: func(pepServiceMock *mock.MockPEPService) {
+				pepServiceMock.EXPECT().ListRiskRatings("critical").Return(nil, fmt.Errorf("%w: rating must be one of low, medium, high", service.ErrInvalidRiskRating))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Success - Rating with its matches",
+			input: input{method: http.MethodGet, path: "/users/2/risk_rating"},
+			on: func(pepServiceMock *mock.MockPEPService) {
+				pepServiceMock.EXPECT().GetRiskRating(int64(2)).Return(repository.RiskRating{UserID: 2, Rating: "high", Source: "screening",
+					Matches: []repository.PEPMatch{{EntryID: 1, EntryName: "Elena Marchetti", Category: "pep"}}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"entry_name":"Elena Marchetti"`)
+			},
+		},
+		{
+			name:  "Failure - User never rated",
+			input: input{method: http.MethodGet, path: "/users/9/risk_rating"},
+			on: func(pepServiceMock *mock.MockPEPService) {
+				pepServiceMock.EXPECT().GetRiskRating(int64(9)).Return(repository.RiskRating{}, service.ErrRiskRatingNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Rating set by a reviewer",
+			input: input{
+				method: http.MethodPut,
+				path:   "/users/2/risk_rating",
+				body:   `{"rating": "low", "reviewer": "alice", "reason": "different date of birth than the PEP"}`,
+			},
+			on: func(pepServiceMock *mock.MockPEPService) {
+				pepServiceMock.EXPECT().SetRiskRating(int64(2), "low", "alice", "different date of birth than the PEP").
+					Return(repository.RiskRating{UserID: 2, Rating: "low", Source: "manual", Reviewer: "alice"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"source":"manual"`)
+			},
+		},
+		{
+			name: "Failure - Rated user not found",
+			input: input{
+				method: http.MethodPut,
+				path:   "/users/9/risk_rating",
+				body:   `{"rating": "high", "reviewer": "alice", "reason": "confirmed"}`,
+			},
+			on: func(pepServiceMock *mock.MockPEPService) {
+				pepServiceMock.EXPECT().SetRiskRating(int64(9), "high", "alice", "confirmed").Return(repository.RiskRating{}, service.ErrUserNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Failure - Invalid user ID",
+			input: input{method: http.MethodPut, path: "/users/abc/risk_rating", body: `{"rating": "high"}`},
+			on:    func(pepServiceMock *mock.MockPEPService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pepServiceMock := mock.NewMockPEPService(ctrl)
+			tt.on(pepServiceMock)
+
+			app := newTestRiskRatingApp(pepServiceMock)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
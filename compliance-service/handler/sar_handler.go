@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/auth"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderComplianceOfficer identifies the compliance officer accessing the Suspicious Activity Reports when
+// authentication is disabled. Otherwise the officer is the subject of the token.
+const HeaderComplianceOfficer = "X-Compliance-Officer"
+
+func complianceOfficer(c *fiber.Ctx) string {
+	if subject := auth.Subject(c); subject != "" {
+		return subject
+	}
+	return c.Get(HeaderComplianceOfficer)
+}
+
+type SARHandler struct {
+	sarService service.SARService
+}
+
+func NewSARHandler(sarService service.SARService) *SARHandler {
+	return &SARHandler{sarService: sarService}
+}
+
+func (h *SARHandler) DraftSAR(c *fiber.Ctx) error {
+	caseID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for case ID: %s", err))
+	}
+
+	var req struct {
+		ActivityTypes []string `json:"activity_types"`
+		Narrative     string   `json:"narrative"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	sar, err := h.sarService.DraftSAR(complianceOfficer(c), caseID, req.ActivityTypes, req.Narrative)
+	if err != nil {
+		return sarErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusCreated).JSON(sar)
+}
+
+func (h *SARHandler) ListSARs(c *fiber.Ctx) error {
+	sars, err := h.sarService.ListSARs(complianceOfficer(c), c.Query("status"))
+	if err != nil {
+		return sarErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"sars": sars})
+}
+
+func (h *SARHandler) GetSAR(c *fiber.Ctx) error {
+	sarID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for SAR ID: %s", err))
+	}
+
+	sar, err := h.sarService.GetSAR(complianceOfficer(c), sarID)
+	if err != nil {
+		return sarErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(sar)
+}
+
+func (h *SARHandler) UpdateDraft(c *fiber.Ctx) error {
+	sarID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for SAR ID: %s", err))
+	}
+
+	var req service.SARUpdate
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	sar, err := h.sarService.UpdateDraft(complianceOfficer(c), sarID, req)
+	if err != nil {
+		return sarErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(sar)
+}
+
+func (h *SARHandler) UpdateStatus(c *fiber.Ctx) error {
+	sarID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for SAR ID: %s", err))
+	}
+
+	var req struct {
+		Status    string `json:"status"`
+		Reference string `json:"reference"`
+		Note      string `json:"note"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	sar, err := h.sarService.UpdateStatus(complianceOfficer(c), sarID, req.Status, req.Reference, req.Note)
+	if err != nil {
+		return sarErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(sar)
+}
+
+// ExportSAR returns the report as FinCEN-style XML (format=xml, the default) or as a plain-text summary (format=text).
+func (h *SARHandler) ExportSAR(c *fiber.Ctx) error {
+	sarID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for SAR ID: %s", err))
+	}
+
+	officer := complianceOfficer(c)
+	switch format := c.Query("format", "xml"); format {
+	case "xml":
+		body, err := h.sarService.ExportXML(officer, sarID)
+		if err != nil {
+			return sarErrorResponse(err)
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="sar-%d.xml"`, sarID))
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+		return c.Send(body)
+	case "text":
+		summary, err := h.sarService.ExportSummary(officer, sarID)
+		if err != nil {
+			return sarErrorResponse(err)
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(summary)
+	default:
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid format: %s, must be xml or text", format))
+	}
+}
+
+func (h *SARHandler) ListAccess(c *fiber.Ctx) error {
+	sarID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for SAR ID: %s", err))
+	}
+
+	accesses, err := h.sarService.ListAccess(complianceOfficer(c), sarID)
+	if err != nil {
+		return sarErrorResponse(err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"access_log": accesses})
+}
+
+func sarErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrSARAccessDenied):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrSARNotFound), errors.Is(err, service.ErrCaseNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrSARExists), errors.Is(err, service.ErrSARNotDraft), errors.Is(err, service.ErrCaseNotConcluded),
+		errors.Is(err, service.ErrInvalidSARTransition):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidSAR), errors.Is(err, service.ErrIncompleteSAR):
+		status = http.StatusBadRequest
+	}
+
+	return fiber.NewError(status, err.Error())
+}
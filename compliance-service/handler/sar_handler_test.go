@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"flarrocca/api"
+	"flarrocca/auth"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSARApp(sarServiceMock *mock.MockSARService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &SARHandler{sarService: sarServiceMock}
+
+	app.Post("/cases/:id/sar", handler.DraftSAR)
+	app.Get("/sars", handler.ListSARs)
+	app.Get("/sars/:id", handler.GetSAR)
+	app.Put("/sars/:id", handler.UpdateDraft)
+	app.Put("/sars/:id/status", handler.UpdateStatus)
+	app.Get("/sars/:id/export", handler.ExportSAR)
+	app.Get("/sars/:id/access_log", handler.ListAccess)
+
+	return app
+}
+
+func TestSARHandler(t *testing.T) {
+	type input struct {
+		method  string
+		path    string
+		body    string
+		officer string
+	}
+
+	type depFields struct {
+		sarServiceMock *mock.MockSARService
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - SAR drafted from a case",
+			input: input{
+				method:  http.MethodPost,
+				path:    "/cases/7/sar",
+				body:    `{"activity_types": ["structuring"]}`,
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {
+				dep.sarServiceMock.EXPECT().DraftSAR("alice", int64(7), []string{"structuring"}, "").
+					Return(repository.SAR{ID: 3, CaseID: 7, Status: "draft", Subject: repository.SARSubject{UserID: 1, IDDocumentNumber: "ciphertext"}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"status":"draft"`)
+				assert.NotContains(t, string(body), "ciphertext")
+			},
+		},
+		{
+			name: "Failure - Case not concluded",
+			input: input{
+				method:  http.MethodPost,
+				path:    "/cases/7/sar",
+				body:    `{}`,
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {
+				dep.sarServiceMock.EXPECT().DraftSAR("alice", int64(7), nil, "").
+					Return(repository.SAR{}, fmt.Errorf("%w: the case is open", service.ErrCaseNotConcluded))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Not a compliance officer",
+			input: input{
+				method: http.MethodGet,
+				path:   "/sars/3",
+			},
+			on: func(dep *depFields, in input) {
+				dep.sarServiceMock.EXPECT().GetSAR("", int64(3)).Return(repository.SAR{}, service.ErrSARAccessDenied)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Filing an incomplete SAR",
+			input: input{
+				method:  http.MethodPut,
+				path:    "/sars/3/status",
+				body:    `{"status": "filed"}`,
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {
+				dep.sarServiceMock.EXPECT().UpdateStatus("alice", int64(3), "filed", "", "").
+					Return(repository.SAR{}, fmt.Errorf("%w: missing subject date of birth", service.ErrIncompleteSAR))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "incomplete SAR: missing subject date of birth", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Success - Draft updated",
+			input: input{
+				method:  http.MethodPut,
+				path:    "/sars/3",
+				body:    `{"narrative": "updated narrative"}`,
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {
+				dep.sarServiceMock.EXPECT().UpdateDraft("alice", int64(3), gomock.Any()).DoAndReturn(
+					func(officer string, sarID int64, update service.SARUpdate) (repository.SAR, error) {
+						assert.Nil(t, update.Subject)
+						assert.Equal(t, "updated narrative", *update.Narrative)
+						return repository.SAR{ID: 3, Narrative: *update.Narrative}, nil
+					})
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - XML export",
+			input: input{
+				method:  http.MethodGet,
+				path:    "/sars/3/export",
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {
+				dep.sarServiceMock.EXPECT().ExportXML("alice", int64(3)).Return([]byte("<EFilingBatchXML/>"), nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/xml; charset=utf-8", resp.Header.Get("Content-Type"))
+				assert.Equal(t, `attachment; filename="sar-3.xml"`, resp.Header.Get("Content-Disposition"))
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, "<EFilingBatchXML/>", string(body))
+			},
+		},
+		{
+			name: "Success - Text summary",
+			input: input{
+				method:  http.MethodGet,
+				path:    "/sars/3/export?format=text",
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {
+				dep.sarServiceMock.EXPECT().ExportSummary("alice", int64(3)).Return("SUSPICIOUS ACTIVITY REPORT 3", nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+			},
+		},
+		{
+			name: "Failure - Unknown export format",
+			input: input{
+				method:  http.MethodGet,
+				path:    "/sars/3/export?format=pdf",
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Invalid SAR ID",
+			input: input{
+				method:  http.MethodGet,
+				path:    "/sars/abc/access_log",
+				officer: "alice",
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sarServiceMock := mock.NewMockSARService(ctrl)
+			tt.on(&depFields{sarServiceMock: sarServiceMock}, tt.input)
+
+			app := newTestSARApp(sarServiceMock)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.input.officer != "" {
+				req.Header.Set(HeaderComplianceOfficer, tt.input.officer)
+			}
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestSARHandlerOfficerFromToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sarServiceMock := mock.NewMockSARService(ctrl)
+	sarServiceMock.EXPECT().ListSARs("bob", "").Return([]repository.SAR{}, nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &SARHandler{sarService: sarServiceMock}
+	app.Get("/sars", func(c *fiber.Ctx) error {
+		c.Locals(auth.LocalsSubject, "bob")
+		return c.Next()
+	}, handler.ListSARs)
+
+	// the header is ignored once the officer is authenticated
+	req := httptest.NewRequest(http.MethodGet, "/sars", nil)
+	req.Header.Set(HeaderComplianceOfficer, "alice")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
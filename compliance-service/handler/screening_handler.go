@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/compliant-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ScreeningHandler struct {
+	screeningService service.ScreeningService
+	userService      service.UserService
+}
+
+func NewScreeningHandler(screeningService service.ScreeningService, userService service.UserService) *ScreeningHandler {
+	return &ScreeningHandler{screeningService: screeningService, userService: userService}
+}
+
+// CreateUser registers a user, who is screened against the sanctions lists right away.
+func (h *ScreeningHandler) CreateUser(c *fiber.Ctx) error {
+	var req struct {
+		UserName   string `json:"user_name"`
+		FullName   string `json:"full_name"`
+		SecretCode string `json:"secret_code"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	user, err := h.userService.CreateUser(req.UserName, req.FullName, req.SecretCode)
+	if err != nil {
+		return screeningErrorResponse(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(user)
+}
+
+func (h *ScreeningHandler) ListHits(c *fiber.Ctx) error {
+	hits, err := h.screeningService.ListHits(c.Query("status"))
+	if err != nil {
+		return screeningErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"hits": hits})
+}
+
+func (h *ScreeningHandler) ReviewHit(c *fiber.Ctx) error {
+	hitID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for screening hit ID: %s", err))
+	}
+
+	var req struct {
+		Status   string `json:"status"`
+		Reviewer string `json:"reviewer"`
+		Note     string `json:"note"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	hit, err := h.screeningService.ReviewHit(hitID, req.Status, req.Reviewer, req.Note)
+	if err != nil {
+		return screeningErrorResponse(err)
+	}
+
+	return c.JSON(hit)
+}
+
+// RefreshSanctions reloads the sanctions list file and screens every user against it.
+func (h *ScreeningHandler) RefreshSanctions(c *fiber.Ctx) error {
+	refresh, err := h.screeningService.RefreshSanctions()
+	if err != nil {
+		return screeningErrorResponse(err)
+	}
+
+	return c.JSON(refresh)
+}
+
+func screeningErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrScreeningHitNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrUserExists):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidScreeningReview), errors.Is(err, service.ErrInvalidUser):
+		status = http.StatusBadRequest
+	}
+
+	return fiber.NewError(status, err.Error())
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/api"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/service"
+	"flarrocca/compliant-service/service/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScreeningApp(screeningServiceMock *mock.MockScreeningService, userServiceMock *mock.MockUserService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &ScreeningHandler{screeningService: screeningServiceMock, userService: userServiceMock}
+
+	app.Post("/users", handler.CreateUser)
+	app.Get("/screening/hits", handler.ListHits)
+	app.Put("/screening/hits/:id", handler.ReviewHit)
+	app.Post("/sanctions/refresh", handler.RefreshSanctions)
+
+	return app
+}
+
+func TestScreeningHandler(t *testing.T) {
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	type depFields struct {
+		screeningServiceMock *mock.MockScreeningService
+		userServiceMock      *mock.MockUserService
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - User created",
+			input: input{
+				method: http.MethodPost,
+				path:   "/users",
+				body:   `{"user_name": "maria_garcia", "full_name": "María García", "secret_code": "secret-123"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.userServiceMock.EXPECT().CreateUser("maria_garcia", "María García", "secret-123").
+					Return(service.CreatedUser{User: repository.User{ID: 3, UserName: "maria_garcia", FullName: "María García"}, ScreeningHits: 1}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"id":3`)
+				assert.Contains(t, string(body), `"screening_hits":1`)
+			},
+		},
+		{
+			name: "Failure - User already exists",
+			input: input{
+				method: http.MethodPost,
+				path:   "/users",
+				body:   `{"user_name": "john_doe", "full_name": "John Doe", "secret_code": "secret-123"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.userServiceMock.EXPECT().CreateUser("john_doe", "John Doe", "secret-123").Return(service.CreatedUser{}, service.ErrUserExists)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Pending hits listed",
+			input: input{
+				method: http.MethodGet,
+				path:   "/screening/hits?status=pending",
+			},
+			on: func(dep *depFields, in input) {
+				dep.screeningServiceMock.EXPECT().ListHits("pending").
+					Return([]repository.ScreeningHit{{ID: 1, UserID: 3, EntryID: 7, MatchedName: "Maria Garcias", Score: 0.95, Status: "pending"}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"matched_name":"Maria Garcias"`)
+			},
+		},
+		{
+			name: "Success - Hit confirmed",
+			input: input{
+				method: http.MethodPut,
+				path:   "/screening/hits/1",
+				body:   `{"status": "confirmed", "reviewer": "alice", "note": "same date of birth"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.screeningServiceMock.EXPECT().ReviewHit(int64(1), "confirmed", "alice", "same date of birth").
+					Return(repository.ScreeningHit{ID: 1, Status: "confirmed", Reviewer: "alice"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"status":"confirmed"`)
+			},
+		},
+		{
+			name: "Failure - Invalid review",
+			input: input{
+				method: http.MethodPut,
+				path:   "/screening/hits/1",
+				body:   `{"status": "maybe", "reviewer": "alice"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.screeningServiceMock.EXPECT().ReviewHit(int64(1), "maybe", "alice", "").
+					Return(repository.ScreeningHit{}, fmt.Errorf("%w: status must be confirmed or dismissed", service.ErrInvalidScreeningReview))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Reviewed hit not found",
+			input: input{
+				method: http.MethodPut,
+				path:   "/screening/hits/9",
+				body:   `{"status": "dismissed", "reviewer": "alice"}`,
+			},
+			on: func(dep *depFields, in input) {
+				dep.screeningServiceMock.EXPECT().ReviewHit(int64(9), "dismissed", "alice", "").Return(repository.ScreeningHit{}, service.ErrScreeningHitNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Invalid hit ID",
+			input: input{
+				method: http.MethodPut,
+				path:   "/screening/hits/abc",
+				body:   `{"status": "dismissed", "reviewer": "alice"}`,
+			},
+			on: func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Sanctions refreshed",
+			input: input{
+				method: http.MethodPost,
+				path:   "/sanctions/refresh",
+			},
+			on: func(dep *depFields, in input) {
+				dep.screeningServiceMock.EXPECT().RefreshSanctions().Return(service.SanctionsRefresh{Entries: 12, UsersScreened: 2, NewHits: 1}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"new_hits":1`)
+			},
+		},
+		{
+			name: "Failure - Sanctions file missing",
+			input: input{
+				method: http.MethodPost,
+				path:   "/sanctions/refresh",
+			},
+			on: func(dep *depFields, in input) {
+				dep.screeningServiceMock.EXPECT().RefreshSanctions().Return(service.SanctionsRefresh{}, errors.New("open ./database/sdn.xml: no such file or directory"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dep := &depFields{
+				screeningServiceMock: mock.NewMockScreeningService(ctrl),
+				userServiceMock:      mock.NewMockUserService(ctrl),
+			}
+			tt.on(dep, tt.input)
+
+			app := newTestScreeningApp(dep.screeningServiceMock, dep.userServiceMock)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -1,58 +1,235 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	_ "embed"
+	"flarrocca/api"
+	"flarrocca/auth"
 	"flarrocca/compliant-service/handler"
 	"flarrocca/compliant-service/repository"
 	"flarrocca/compliant-service/service"
-	"log"
+	"flarrocca/logging"
+	"flarrocca/metrics"
+	"flarrocca/migrate"
+	"flarrocca/webhook"
+	"fmt"
+	"log/slog"
 	"os"
 
+	compliancev1 "flarrocca/proto/compliance/v1"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/gofiber/template/html/v2"
 	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/grpc"
 )
 
+//go:embed openapi.yaml
+var openAPISpec []byte
+
 func initDB() *sql.DB {
 	db, err := sql.Open("sqlite3", "./database/compliance.db")
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("error opening the database", err)
+	}
+
+	// The tables created by an older init.sql are upgraded first, init.sql only creating the missing ones.
+	if err := migrate.Run(db, repository.Migrations); err != nil {
+		logging.Fatal("error migrating the database", err)
 	}
 
 	initSQL, err := os.ReadFile("./database/init.sql")
 	if err != nil {
-		log.Fatal("error reading init.sql:", err)
+		logging.Fatal("error reading init.sql", err)
 	}
 
 	_, err = db.Exec(string(initSQL))
 	if err != nil {
-		log.Fatal("error executing init.sql:", err)
+		logging.Fatal("error executing init.sql", err)
 	}
 
+	metrics.RegisterDB(db, "compliance")
 	return db
 }
 
+// newPaymentTokenSource signs the calls to payment-service with AUTH_JWT_PRIVATE_KEY or AUTH_JWT_SECRET, for the scope
+// compliance-service is allowed.
+func newPaymentTokenSource() auth.TokenSource {
+	tokenSource, err := auth.NewTokenSource("compliance-service", auth.AudiencePayment, []string{auth.ScopePaymentNotify, auth.ScopePaymentRead})
+	if err != nil {
+		logging.Fatal("error setting up payment authentication", err)
+	}
+	if tokenSource == nil {
+		slog.Warn("no signing key, payment-service is called without a token")
+	}
+	return tokenSource
+}
+
 func main() {
+	logging.Setup("compliance-service")
+	if err := service.LoadCardFingerprintKey(); err != nil {
+		logging.Fatal("error loading the card fingerprint key", err)
+	}
 	db := initDB()
 
 	userRepository := repository.NewUserRepository(db)
 	cardRepository := repository.NewCardRepository(db)
 	stolenCardRepository := repository.NewStolenCardRepository(db)
-	complianceService := service.NewComplianceService(userRepository, cardRepository, stolenCardRepository)
+	caseRepository := repository.NewCaseRepository(db)
+	paymentRepository := repository.NewPaymentRepository(newPaymentTokenSource())
+	listEntryRepository := repository.NewListEntryRepository(db)
+	sanctionsRepository := repository.NewSanctionsRepository(db)
+	kycRepository := repository.NewKYCRepository(db)
+	pepRepository := repository.NewPEPRepository(db)
+	complianceService := service.NewComplianceService(userRepository, cardRepository, stolenCardRepository, caseRepository, paymentRepository, listEntryRepository,
+		sanctionsRepository, kycRepository, pepRepository)
 	complianceHandler := handler.NewUserHandler(complianceService)
+	caseService := service.NewCaseService(caseRepository, userRepository, cardRepository, stolenCardRepository, paymentRepository)
+	caseHandler := handler.NewCaseHandler(caseService)
+	listService := service.NewListService(listEntryRepository, paymentRepository)
+	listHandler := handler.NewListHandler(listService)
+	cardImportRepository := repository.NewCardImportRepository(db)
+	cardImportService := service.NewCardImportService(cardImportRepository)
+	cardImportHandler := handler.NewCardImportHandler(cardImportService)
+	screeningService := service.NewScreeningService(sanctionsRepository, userRepository, paymentRepository)
+	pepService := service.NewPEPService(pepRepository, userRepository, paymentRepository)
+	userService := service.NewUserService(userRepository, screeningService, pepService)
+	screeningHandler := handler.NewScreeningHandler(screeningService, userService)
+	riskRatingHandler := handler.NewRiskRatingHandler(pepService)
+	kycService, err := service.NewKYCService(kycRepository, userRepository, paymentRepository)
+	if err != nil {
+		logging.Fatal("error setting up KYC", err)
+	}
+	kycHandler := handler.NewKYCHandler(kycService)
+	sarRepository := repository.NewSARRepository(db)
+	sarService, err := service.NewSARService(sarRepository, caseRepository, kycRepository)
+	if err != nil {
+		logging.Fatal("error setting up the Suspicious Activity Reports", err)
+	}
+	sarHandler := handler.NewSARHandler(sarService)
+	eventRepository := repository.NewEventRepository(db)
+	eventService := service.NewEventService(eventRepository)
+	eventHandler := handler.NewEventHandler(eventService)
+	webhookService := webhook.NewService(webhook.NewRepository(db), repository.EventTypes)
+
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], cardImportService, screeningService, pepService); err != nil {
+			logging.Fatal("error running command", err)
+		}
+		return
+	}
+
+	verifier, err := auth.NewVerifier(auth.AudienceCompliance)
+	if err != nil {
+		logging.Fatal("error setting up authentication", err)
+	}
+	if verifier == nil {
+		slog.Warn("authentication is disabled, the internal API is open to anyone reaching the service")
+	}
+	check := auth.RequireScope(verifier, auth.ScopeComplianceCheck)
+	cases := auth.RequireScope(verifier, auth.ScopeComplianceCases)
+	events := auth.RequireScope(verifier, auth.ScopeComplianceEvents)
+	admin := auth.RequireScope(verifier, auth.ScopeComplianceAdmin)
+	pii := auth.RequireScope(verifier, auth.ScopeCompliancePII)
 
 	tmplEngine := html.New("./views", ".html")
-	app := fiber.New(fiber.Config{Views: setVueCompatibleDelimiters(tmplEngine)})
+	// Request bodies are streamed so compromised card feeds larger than memory can be uploaded.
+	app := fiber.New(fiber.Config{Views: setVueCompatibleDelimiters(tmplEngine), StreamRequestBody: true,
+		ErrorHandler: api.ErrorHandler})
+	// The request ID sent by payment-service, or a new one, is logged with every record of the request, and every
+	// request is counted by route on /metrics.
+	app.Use(requestid.New(), logging.Middleware(), metrics.Middleware())
+	app.Get(metrics.Path, metrics.Handler())
+	// The unversioned routes of the first releases are deprecated aliases of the /v1 routes, except for the report page.
+	app.Use(api.LegacyAliases("/report", "/static"))
 
+	// The report page, the stolen card reports sent from it and the OpenAPI document are public, the other routes need
+	// a token granting their scope.
 	app.Static("/static", "./views/static")
 	app.Get("/report", func(c *fiber.Ctx) error {
 		return c.Render("report", fiber.Map{})
 	})
 
-	app.Post("/report_cards", complianceHandler.ReportStolenCards)
-	app.Get("/check_user", complianceHandler.CheckComplianceStatus)
+	v1 := app.Group(api.Prefix)
+	v1.Get("/openapi.yaml", api.Spec(openAPISpec))
+	v1.Post("/report_cards", complianceHandler.ReportStolenCards)
+	v1.Get("/check_user", check, complianceHandler.CheckComplianceStatus)
+	v1.Post("/check_users", check, complianceHandler.CheckComplianceStatuses)
+	v1.Get("/blocked_cards", check, complianceHandler.ListBlockedCards)
+	v1.Post("/users/:id/cards/:card_id/reinstate", admin, complianceHandler.ReinstateCard)
+
+	v1.Get("/cases", admin, caseHandler.ListCases)
+	v1.Post("/cases", cases, caseHandler.OpenCase)
+	v1.Post("/cases/:id/transactions", admin, caseHandler.AttachTransaction)
+	v1.Post("/cases/:id/notes", admin, caseHandler.AddNote)
+	v1.Put("/cases/:id/assignee", admin, caseHandler.AssignCase)
+	v1.Put("/cases/:id/status", admin, caseHandler.UpdateCaseStatus)
+	v1.Get("/cases/:id/export", admin, caseHandler.ExportCase)
+
+	v1.Get("/lists", admin, listHandler.ListEntries)
+	v1.Post("/lists", admin, listHandler.AddEntry)
+	v1.Post("/lists/import", admin, listHandler.ImportEntries)
+	v1.Put("/lists/:id", admin, listHandler.UpdateEntry)
+	v1.Delete("/lists/:id", admin, listHandler.DeleteEntry)
+
+	v1.Post("/blocked_cards/imports", admin, cardImportHandler.StartImport)
+	v1.Get("/blocked_cards/imports/:id", admin, cardImportHandler.GetImport)
+	v1.Post("/blocked_cards/imports/:id/resume", admin, cardImportHandler.ResumeImport)
+	v1.Get("/blocked_cards/imports/:id/report", admin, cardImportHandler.GetReport)
+
+	v1.Post("/users", admin, screeningHandler.CreateUser)
+	v1.Get("/screening/hits", admin, screeningHandler.ListHits)
+	v1.Put("/screening/hits/:id", admin, screeningHandler.ReviewHit)
+	v1.Post("/sanctions/refresh", admin, screeningHandler.RefreshSanctions)
+
+	v1.Post("/pep/refresh", admin, riskRatingHandler.RefreshPEPList)
+	v1.Get("/risk_ratings", admin, riskRatingHandler.ListRiskRatings)
+	v1.Get("/users/:id/risk_rating", admin, riskRatingHandler.GetRiskRating)
+	v1.Put("/users/:id/risk_rating", admin, riskRatingHandler.SetRiskRating)
+
+	v1.Get("/kyc", admin, kycHandler.ListProfiles)
+	v1.Get("/users/:id/kyc", admin, kycHandler.GetProfile)
+	v1.Put("/users/:id/kyc", admin, kycHandler.SubmitProfile)
+	v1.Put("/users/:id/kyc/review", admin, kycHandler.ReviewProfile)
+	v1.Get("/users/:id/kyc/id_document", pii, kycHandler.RevealIDDocument)
+
+	v1.Post("/cases/:id/sar", pii, sarHandler.DraftSAR)
+	v1.Get("/sars", pii, sarHandler.ListSARs)
+	v1.Get("/sars/:id", pii, sarHandler.GetSAR)
+	v1.Put("/sars/:id", pii, sarHandler.UpdateDraft)
+	v1.Put("/sars/:id/status", pii, sarHandler.UpdateStatus)
+	v1.Get("/sars/:id/export", pii, sarHandler.ExportSAR)
+	v1.Get("/sars/:id/access_log", pii, sarHandler.ListAccess)
+
+	v1.Get("/events", events, eventHandler.ListEvents)
+	v1.Use("/webhooks", admin)
+	webhook.NewHandler(webhookService).RegisterRoutes(v1)
+
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(), auth.UnaryServerInterceptor(verifier, map[string]string{
+		compliancev1.ComplianceService_CheckCompliance_FullMethodName:      auth.ScopeComplianceCheck,
+		compliancev1.ComplianceService_CheckComplianceBatch_FullMethodName: auth.ScopeComplianceCheck,
+		compliancev1.ComplianceService_ListBlockedCards_FullMethodName:     auth.ScopeComplianceCheck,
+		compliancev1.ComplianceService_RequestCardReview_FullMethodName:    auth.ScopeComplianceCases,
+	})))
+	compliancev1.RegisterComplianceServiceServer(grpcServer, handler.NewComplianceGRPCServer(complianceService, caseService))
+	go serveGRPC(grpcServer)
+	go eventService.RunRelay(context.Background())
+	// the outbox events are published to the webhook endpoints, with their outbox ID so partners can skip duplicates
+	go eventService.RunConsumer(context.Background(), "webhooks", nil, func(event repository.Event) error {
+		return webhookService.Publish(fmt.Sprintf("evt_%d", event.ID), event.Type, event.Payload)
+	})
+	// the card reports are delivered to payment-service, retried until it answers, so it flags the payments made
+	// with the cards before the report
+	go eventService.RunConsumer(context.Background(), "payment-service", []string{repository.EventCardReported}, complianceService.NotifyCardReported)
+	// the blocked and reinstated PANs drop the verdicts payment-service cached for every card linked to them, retried
+	// until it answers, so a blocked card is never paid with on a stale verdict
+	go eventService.RunConsumer(context.Background(), "payment-service-verdicts", []string{repository.EventCardBlocked, repository.EventCardReinstated},
+		complianceService.InvalidateCardVerdicts)
+	go webhookService.RunDispatcher(context.Background())
 
-	log.Fatal(app.Listen(":8080"))
+	logging.Fatal("error serving", app.Listen(":8080"))
 }
 
 // setVueCompatibleDelimiters avoid conflicts with Vue.js {{ }}
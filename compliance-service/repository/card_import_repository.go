@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	CardImportRowImported  = "imported"
+	CardImportRowDuplicate = "duplicate"
+	CardImportRowInvalid   = "invalid"
+)
+
+type CardImport struct {
+	ID            int64     `json:"id"`
+	Source        string    `json:"source"`
+	FileName      string    `json:"file_name,omitempty"`
+	Format        string    `json:"format"`
+	Status        string    `json:"status"`
+	RowsProcessed int64     `json:"rows_processed"`
+	Imported      int64     `json:"imported"`
+	Duplicates    int64     `json:"duplicates"`
+	Invalid       int64     `json:"invalid"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type CardImportRow struct {
+	RowNumber       int64  `json:"row"`
+	Status          string `json:"status"`
+	CardFingerprint string `json:"card_fingerprint,omitempty"`
+	Detail          string `json:"detail,omitempty"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source card_import_repository.go -destination mock/card_import_repository_mock.go -package mock
+type CardImportRepository interface {
+	CreateImport(cardImport CardImport) (int64, error)
+	GetImport(importID int64) (CardImport, error)
+	SaveBatch(importID int64, source string, rows []CardImportRow, updatedAt time.Time) ([]CardImportRow, error)
+	ClaimImport(importID int64, staleBefore time.Time, updatedAt time.Time) error
+	FinishImport(importID int64, status string, errorMessage string, updatedAt time.Time) error
+	GetImportRows(importID int64, afterRow int64, limit int) ([]CardImportRow, error)
+}
+
+type cardImportRepository struct {
+	db *sql.DB
+}
+
+func NewCardImportRepository(db *sql.DB) CardImportRepository {
+	return &cardImportRepository{db: db}
+}
+
+func (r *cardImportRepository) CreateImport(cardImport CardImport) (int64, error) {
+	result, err := r.db.Exec("INSERT INTO card_imports (source, file_name, format, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		cardImport.Source, cardImport.FileName, cardImport.Format, cardImport.Status, cardImport.CreatedAt, cardImport.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *cardImportRepository) GetImport(importID int64) (CardImport, error) {
+	var cardImport CardImport
+	err := r.db.QueryRow("SELECT id, source, file_name, format, status, rows_processed, imported, duplicates, invalid, error, created_at, updated_at FROM card_imports WHERE id = ?", importID).
+		Scan(&cardImport.ID, &cardImport.Source, &cardImport.FileName, &cardImport.Format, &cardImport.Status, &cardImport.RowsProcessed,
+			&cardImport.Imported, &cardImport.Duplicates, &cardImport.Invalid, &cardImport.Error, &cardImport.CreatedAt, &cardImport.UpdatedAt)
+	return cardImport, err
+}
+
+// SaveBatch blocks the cards of the rows marked as imported, records the result of every row and moves the import
+// checkpoint past the last row, all in one transaction. Rows whose card was already blocked are returned as duplicates.
+func (r *cardImportRepository) SaveBatch(importID int64, source string, rows []CardImportRow, updatedAt time.Time) ([]CardImportRow, error) {
+	if len(rows) == 0 {
+		return rows, nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := saveCardImportBatch(tx, importID, source, rows, updatedAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func saveCardImportBatch(tx *sql.Tx, importID int64, source string, rows []CardImportRow, updatedAt time.Time) ([]CardImportRow, error) {
+	blockStmt, err := tx.Prepare("INSERT OR IGNORE INTO blocked_cards (card_fingerprint, source) VALUES (?, ?)")
+	if err != nil {
+		return nil, err
+	}
+	defer blockStmt.Close()
+
+	rowStmt, err := tx.Prepare("INSERT OR REPLACE INTO card_import_rows (import_id, row_number, status, card_fingerprint, detail) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return nil, err
+	}
+	defer rowStmt.Close()
+
+	saved := make([]CardImportRow, 0, len(rows))
+	counts := map[string]int64{}
+	for _, row := range rows {
+		if row.Status == CardImportRowImported {
+			result, err := blockStmt.Exec(row.CardFingerprint, source)
+			if err != nil {
+				return nil, err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return nil, err
+			}
+			if affected == 0 {
+				row.Status, row.Detail = CardImportRowDuplicate, "card already blocked"
+			} else if err := insertEvent(tx, EventCardBlocked, CardBlockedEvent{CardFingerprint: row.CardFingerprint, Source: source, ImportID: importID}); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := rowStmt.Exec(importID, row.RowNumber, row.Status, row.CardFingerprint, row.Detail); err != nil {
+			return nil, err
+		}
+		counts[row.Status]++
+		saved = append(saved, row)
+	}
+
+	_, err = tx.Exec("UPDATE card_imports SET rows_processed = ?, imported = imported + ?, duplicates = duplicates + ?, invalid = invalid + ?, updated_at = ? WHERE id = ?",
+		rows[len(rows)-1].RowNumber, counts[CardImportRowImported], counts[CardImportRowDuplicate], counts[CardImportRowInvalid], updatedAt, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// ClaimImport moves a failed import back to running, or takes over a running one not updated since staleBefore, left
+// behind by a worker that stopped without marking it failed. The status is checked by the update itself, so two workers
+// never claim the same import; sql.ErrNoRows is returned when the import is not found or cannot be claimed.
+func (r *cardImportRepository) ClaimImport(importID int64, staleBefore time.Time, updatedAt time.Time) error {
+	result, err := r.db.Exec(`UPDATE card_imports SET status = 'running', error = '', updated_at = ?
+		WHERE id = ? AND (status = 'failed' OR (status = 'running' AND updated_at < ?))`, updatedAt, importID, staleBefore)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (r *cardImportRepository) FinishImport(importID int64, status string, errorMessage string, updatedAt time.Time) error {
+	result, err := r.db.Exec("UPDATE card_imports SET status = ?, error = ?, updated_at = ? WHERE id = ?", status, errorMessage, updatedAt, importID)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// GetImportRows pages through the report of an import, returning up to limit rows after the given row number.
+func (r *cardImportRepository) GetImportRows(importID int64, afterRow int64, limit int) ([]CardImportRow, error) {
+	rows, err := r.db.Query("SELECT row_number, status, card_fingerprint, detail FROM card_import_rows WHERE import_id = ? AND row_number > ? ORDER BY row_number LIMIT ?",
+		importID, afterRow, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var importRows []CardImportRow
+	for rows.Next() {
+		var row CardImportRow
+		if err := rows.Scan(&row.RowNumber, &row.Status, &row.CardFingerprint, &row.Detail); err != nil {
+			return nil, err
+		}
+		importRows = append(importRows, row)
+	}
+
+	return importRows, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveCardImportBatch(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	rows := []CardImportRow{
+		{RowNumber: 1, Status: CardImportRowImported, CardFingerprint: "fp-1"},
+		{RowNumber: 2, Status: CardImportRowImported, CardFingerprint: "fp-2"},
+		{RowNumber: 3, Status: CardImportRowInvalid, Detail: "card number fails the Luhn check"},
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, saved []CardImportRow, err error)
+	}{
+		{
+			name: "Success - Card already blocked reported as duplicate",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				blockStmt := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO blocked_cards \(card_fingerprint, source\) VALUES \(\?, \?\)`)
+				rowStmt := dbMock.ExpectPrepare(`INSERT OR REPLACE INTO card_import_rows \(import_id, row_number, status, card_fingerprint, detail\) VALUES \(\?, \?, \?, \?, \?\)`)
+				blockStmt.ExpectExec().WithArgs("fp-1", "visa-cams").WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEvent(dbMock, EventCardBlocked, `{"card_fingerprint":"fp-1","source":"visa-cams","import_id":7}`)
+				rowStmt.ExpectExec().WithArgs(int64(7), int64(1), CardImportRowImported, "fp-1", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				blockStmt.ExpectExec().WithArgs("fp-2", "visa-cams").WillReturnResult(sqlmock.NewResult(0, 0))
+				rowStmt.ExpectExec().WithArgs(int64(7), int64(2), CardImportRowDuplicate, "fp-2", "card already blocked").WillReturnResult(sqlmock.NewResult(2, 1))
+				rowStmt.ExpectExec().WithArgs(int64(7), int64(3), CardImportRowInvalid, "", "card number fails the Luhn check").WillReturnResult(sqlmock.NewResult(3, 1))
+				dbMock.ExpectExec(`UPDATE card_imports SET rows_processed = \?, imported = imported \+ \?, duplicates = duplicates \+ \?, invalid = invalid \+ \?, updated_at = \? WHERE id = \?`).
+					WithArgs(int64(3), int64(1), int64(1), int64(1), now, int64(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, saved []CardImportRow, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, CardImportRowImported, saved[0].Status)
+				assert.Equal(t, CardImportRowDuplicate, saved[1].Status)
+				assert.Equal(t, CardImportRowInvalid, saved[2].Status)
+			},
+		},
+		{
+			name: "Failure - Error rolls back the batch and its checkpoint",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				blockStmt := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO blocked_cards`)
+				dbMock.ExpectPrepare(`INSERT OR REPLACE INTO card_import_rows`)
+				blockStmt.ExpectExec().WithArgs("fp-1", "visa-cams").WillReturnError(errors.New("database is locked"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, saved []CardImportRow, err error) {
+				assert.Nil(t, saved)
+				assert.EqualError(t, err, "database is locked")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			saved, err := NewCardImportRepository(db).SaveBatch(7, "visa-cams", rows, now)
+			tt.assertFunc(t, saved, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClaimCardImport(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		affected   int64
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:     "Success - Failed or stale import claimed",
+			affected: 1,
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:     "Failure - Import running, completed or not found",
+			affected: 0,
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			dbMock.ExpectExec(`UPDATE card_imports SET status = 'running', error = '', updated_at = \? WHERE id = \? AND \(status = 'failed' OR \(status = 'running' AND updated_at < \?\)\)`).
+				WithArgs(now, int64(7), now.Add(-10*time.Minute)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := NewCardImportRepository(db).ClaimImport(7, now.Add(-10*time.Minute), now)
+
+			tt.assertFunc(t, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetCardImportRows(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT row_number, status, card_fingerprint, detail FROM card_import_rows WHERE import_id = \? AND row_number > \? ORDER BY row_number LIMIT \?`).
+		WithArgs(int64(7), int64(1000), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"row_number", "status", "card_fingerprint", "detail"}).
+			AddRow(1001, CardImportRowImported, "fp-1", "").
+			AddRow(1002, CardImportRowInvalid, "", "missing card number"))
+
+	rows, err := NewCardImportRepository(db).GetImportRows(7, 1000, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []CardImportRow{
+		{RowNumber: 1001, Status: CardImportRowImported, CardFingerprint: "fp-1"},
+		{RowNumber: 1002, Status: CardImportRowInvalid, Detail: "missing card number"},
+	}, rows)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
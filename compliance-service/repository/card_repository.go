@@ -2,12 +2,42 @@ package repository
 
 import (
 	"database/sql"
+	"strings"
 )
 
+type Card struct {
+	ID         int64
+	CardNumber string
+	// UserID is only set by ListCards, the other methods look up the cards of a known user.
+	UserID int64
+}
+
+// UserCard identifies a card of a user in a batch check.
+type UserCard struct {
+	UserID int64
+	CardID int64
+}
+
+// CardStatus is what the compliance check of a card reads from the database, fetched for many cards at once. KYCStatus
+// is empty for a user without a KYC profile and RiskRating for a user not rated yet.
+type CardStatus struct {
+	UserID     int64
+	CardID     int64
+	CardNumber string
+	Reported   bool
+	Sanctioned bool
+	KYCStatus  string
+	KYCTier    int
+	RiskRating string
+}
+
 // Run from the /repository folder the following command to generate the mock:
 // mockgen -source card_repository.go -destination mock/card_repository_mock.go -package mock
 type CardRepository interface {
 	GetUserCards(userID int64) ([]int64, error)
+	GetUserCardDetails(userID int64) ([]Card, error)
+	ListCards() ([]Card, error)
+	GetCardStatuses(cards []UserCard) ([]CardStatus, error)
 }
 
 type cardRepository struct {
@@ -36,3 +66,81 @@ func (r *cardRepository) GetUserCards(userID int64) ([]int64, error) {
 
 	return cardIDs, nil
 }
+
+func (r *cardRepository) GetUserCardDetails(userID int64) ([]Card, error) {
+	rows, err := r.db.Query("SELECT id, card_number FROM cards WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []Card
+	for rows.Next() {
+		var card Card
+		if err := rows.Scan(&card.ID, &card.CardNumber); err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// ListCards returns the cards of every user, ordered by ID.
+func (r *cardRepository) ListCards() ([]Card, error) {
+	rows, err := r.db.Query("SELECT id, user_id, card_number FROM cards ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []Card
+	for rows.Next() {
+		var card Card
+		if err := rows.Scan(&card.ID, &card.UserID, &card.CardNumber); err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// GetCardStatuses reads the status of the cards with a single query. The cards not linked to the given user are left out.
+func (r *cardRepository) GetCardStatuses(cards []UserCard) ([]CardStatus, error) {
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, 2*len(cards))
+	for _, card := range cards {
+		args = append(args, card.UserID, card.CardID)
+	}
+
+	rows, err := r.db.Query(`WITH requested (user_id, card_id) AS (VALUES `+strings.TrimSuffix(strings.Repeat("(?, ?), ", len(cards)), ", ")+`)
+		SELECT DISTINCT c.user_id, c.id, c.card_number,
+			EXISTS(SELECT 1 FROM reported_cards rc WHERE rc.user_id = c.user_id AND rc.card_id = c.id),
+			EXISTS(SELECT 1 FROM screening_hits h JOIN sanctions_entries e ON e.id = h.entry_id
+				WHERE h.user_id = c.user_id AND h.status = 'confirmed' AND e.active = 1),
+			COALESCE(k.status, ''), COALESCE(k.tier, 0), COALESCE(rr.rating, '')
+		FROM requested q
+		JOIN cards c ON c.user_id = q.user_id AND c.id = q.card_id
+		LEFT JOIN kyc_profiles k ON k.user_id = c.user_id
+		LEFT JOIN user_risk_ratings rr ON rr.user_id = c.user_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []CardStatus
+	for rows.Next() {
+		var status CardStatus
+		if err := rows.Scan(&status.UserID, &status.CardID, &status.CardNumber, &status.Reported, &status.Sanctioned, &status.KYCStatus,
+			&status.KYCTier, &status.RiskRating); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
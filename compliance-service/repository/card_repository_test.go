@@ -102,3 +102,106 @@ func TestGetUserCards(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserCardDetails(t *testing.T) {
+	type input struct {
+		userID int64
+	}
+
+	type output struct {
+		cards []Card
+		err   error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Cards found",
+			input: input{
+				userID: 1,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery("SELECT id, card_number FROM cards WHERE user_id = ?").
+					WithArgs(in.userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "card_number"}).
+						AddRow(1, "1234-5678-9012-3456").
+						AddRow(2, "9876-5432-1098-7654"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, []Card{{ID: 1, CardNumber: "1234-5678-9012-3456"}, {ID: 2, CardNumber: "9876-5432-1098-7654"}}, out.cards)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			input: input{
+				userID: 1,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery("SELECT id, card_number FROM cards WHERE user_id = ?").
+					WithArgs(in.userID).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.cards)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			cardRepository := NewCardRepository(db)
+			tt.on(dbMock, tt.input)
+
+			cards, err := cardRepository.GetUserCardDetails(tt.input.userID)
+			tt.assertFunc(t, output{cards, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListCards(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT id, user_id, card_number FROM cards ORDER BY id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_number"}).
+			AddRow(1, 1, "1234-5678-9012-3456").
+			AddRow(3, 2, "9876-5432-1098-7654"))
+
+	cards, err := NewCardRepository(db).ListCards()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Card{{ID: 1, UserID: 1, CardNumber: "1234-5678-9012-3456"}, {ID: 3, UserID: 2, CardNumber: "9876-5432-1098-7654"}}, cards)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetCardStatuses(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`WITH requested \(user_id, card_id\) AS \(VALUES \(\?, \?\), \(\?, \?\), \(\?, \?\)\)\s+SELECT DISTINCT c.user_id, c.id, c.card_number, (.+)
+		FROM requested q\s+JOIN cards c ON c.user_id = q.user_id AND c.id = q.card_id`).
+		WithArgs(int64(1), int64(1), int64(1), int64(9), int64(2), int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "id", "card_number", "reported", "sanctioned", "kyc_status", "kyc_tier", "rating"}).
+			AddRow(1, 1, "1234-5678-9012-3456", true, false, "verified", 2, "low").
+			AddRow(2, 3, "9876-5432-1098-7654", false, true, "", 0, ""))
+
+	statuses, err := NewCardRepository(db).GetCardStatuses([]UserCard{{UserID: 1, CardID: 1}, {UserID: 1, CardID: 9}, {UserID: 2, CardID: 3}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []CardStatus{
+		{UserID: 1, CardID: 1, CardNumber: "1234-5678-9012-3456", Reported: true, KYCStatus: "verified", KYCTier: 2, RiskRating: "low"},
+		{UserID: 2, CardID: 3, CardNumber: "9876-5432-1098-7654", Sanctioned: true},
+	}, statuses)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
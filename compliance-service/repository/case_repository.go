@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Case struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	CardID    int64     `json:"card_id,omitempty"`
+	Source    string    `json:"source"`
+	Status    string    `json:"status"`
+	Assignee  string    `json:"assignee,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CaseTransaction is a payment attached to a case. OccurredAt is when the payment was made, nil for the transactions
+// attached before it was recorded.
+type CaseTransaction struct {
+	TransactionID string     `json:"transaction_id"`
+	CardID        int64      `json:"card_id"`
+	Amount        float64    `json:"amount"`
+	OccurredAt    *time.Time `json:"occurred_at,omitempty"`
+	AttachedAt    time.Time  `json:"attached_at"`
+}
+
+type CaseNote struct {
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source case_repository.go -destination mock/case_repository_mock.go -package mock
+type CaseRepository interface {
+	CreateCase(userID int64, cardID int64, source string) (int64, error)
+	GetCase(caseID int64) (Case, error)
+	ListCases(status string, assignee string) ([]Case, error)
+	GetUserCases(userID int64) ([]Case, error)
+	UpdateCaseStatus(caseID int64, status string, fromStatuses []string) error
+	AssignCase(caseID int64, assignee string) error
+	AttachTransaction(caseID int64, transaction CaseTransaction) error
+	GetCaseTransactions(caseID int64) ([]CaseTransaction, error)
+	AddNote(caseID int64, author string, body string) error
+	GetCaseNotes(caseID int64) ([]CaseNote, error)
+}
+
+type caseRepository struct {
+	db *sql.DB
+}
+
+func NewCaseRepository(db *sql.DB) CaseRepository {
+	return &caseRepository{db: db}
+}
+
+// CreateCase opens a new case. A zero cardID means the case covers every card of the user.
+func (r *caseRepository) CreateCase(userID int64, cardID int64, source string) (int64, error) {
+	var nullableCardID sql.NullInt64
+	if cardID != 0 {
+		nullableCardID = sql.NullInt64{Int64: cardID, Valid: true}
+	}
+
+	result, err := r.db.Exec("INSERT INTO cases (user_id, card_id, source) VALUES (?, ?, ?)", userID, nullableCardID, source)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *caseRepository) GetCase(caseID int64) (Case, error) {
+	row := r.db.QueryRow("SELECT id, user_id, card_id, source, status, assignee, created_at, updated_at FROM cases WHERE id = ?", caseID)
+	return scanCase(row)
+}
+
+func (r *caseRepository) ListCases(status string, assignee string) ([]Case, error) {
+	query := "SELECT id, user_id, card_id, source, status, assignee, created_at, updated_at FROM cases WHERE 1 = 1"
+	var args []any
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	if assignee != "" {
+		query += " AND assignee = ?"
+		args = append(args, assignee)
+	}
+	query += " ORDER BY id DESC"
+
+	return r.queryCases(query, args...)
+}
+
+func (r *caseRepository) GetUserCases(userID int64) ([]Case, error) {
+	return r.queryCases("SELECT id, user_id, card_id, source, status, assignee, created_at, updated_at FROM cases WHERE user_id = ? ORDER BY id DESC", userID)
+}
+
+// UpdateCaseStatus moves the case to the status when it is in one of fromStatuses, in a single statement so two
+// concurrent updates cannot both move it. sql.ErrNoRows is returned when the case is not found or in another status.
+func (r *caseRepository) UpdateCaseStatus(caseID int64, status string, fromStatuses []string) error {
+	if len(fromStatuses) == 0 {
+		return sql.ErrNoRows
+	}
+
+	args := []any{status, caseID}
+	for _, fromStatus := range fromStatuses {
+		args = append(args, fromStatus)
+	}
+	result, err := r.db.Exec("UPDATE cases SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status IN ("+placeholders(len(fromStatuses))+")", args...)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (r *caseRepository) AssignCase(caseID int64, assignee string) error {
+	return r.updateCase("UPDATE cases SET assignee = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", assignee, caseID)
+}
+
+func (r *caseRepository) AttachTransaction(caseID int64, transaction CaseTransaction) error {
+	var occurredAt sql.NullTime
+	if transaction.OccurredAt != nil {
+		occurredAt = sql.NullTime{Time: transaction.OccurredAt.UTC(), Valid: true}
+	}
+
+	_, err := r.db.Exec("INSERT INTO case_transactions (case_id, transaction_id, card_id, amount, occurred_at) VALUES (?, ?, ?, ?, ?)",
+		caseID, transaction.TransactionID, transaction.CardID, transaction.Amount, occurredAt)
+	return err
+}
+
+func (r *caseRepository) GetCaseTransactions(caseID int64) ([]CaseTransaction, error) {
+	rows, err := r.db.Query("SELECT transaction_id, card_id, amount, occurred_at, attached_at FROM case_transactions WHERE case_id = ? ORDER BY id", caseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []CaseTransaction
+	for rows.Next() {
+		var transaction CaseTransaction
+		var occurredAt sql.NullTime
+		if err := rows.Scan(&transaction.TransactionID, &transaction.CardID, &transaction.Amount, &occurredAt, &transaction.AttachedAt); err != nil {
+			return nil, err
+		}
+		if occurredAt.Valid {
+			transaction.OccurredAt = &occurredAt.Time
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+func (r *caseRepository) AddNote(caseID int64, author string, body string) error {
+	_, err := r.db.Exec("INSERT INTO case_notes (case_id, author, body) VALUES (?, ?, ?)", caseID, author, body)
+	return err
+}
+
+func (r *caseRepository) GetCaseNotes(caseID int64) ([]CaseNote, error) {
+	rows, err := r.db.Query("SELECT author, body, created_at FROM case_notes WHERE case_id = ? ORDER BY id", caseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []CaseNote
+	for rows.Next() {
+		var note CaseNote
+		if err := rows.Scan(&note.Author, &note.Body, &note.CreatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, nil
+}
+
+func (r *caseRepository) updateCase(query string, value string, caseID int64) error {
+	result, err := r.db.Exec(query, value, caseID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *caseRepository) queryCases(query string, args ...any) ([]Case, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cases []Case
+	for rows.Next() {
+		c, err := scanCase(rows)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+
+	return cases, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCase(row rowScanner) (Case, error) {
+	var c Case
+	var cardID sql.NullInt64
+	var assignee sql.NullString
+	if err := row.Scan(&c.ID, &c.UserID, &cardID, &c.Source, &c.Status, &assignee, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return Case{}, err
+	}
+	c.CardID = cardID.Int64
+	c.Assignee = assignee.String
+	return c, nil
+}
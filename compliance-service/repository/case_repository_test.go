@@ -0,0 +1,435 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var caseColumns = []string{"id", "user_id", "card_id", "source", "status", "assignee", "created_at", "updated_at"}
+
+func TestCreateCase(t *testing.T) {
+	type input struct {
+		userID int64
+		cardID int64
+		source string
+	}
+
+	type output struct {
+		caseID int64
+		err    error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Case for every card of the user",
+			input: input{
+				userID: 1,
+				source: "card_report",
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectExec(`INSERT INTO cases \(user_id, card_id, source\) VALUES \(\?, \?, \?\)`).
+					WithArgs(in.userID, sql.NullInt64{}, in.source).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, int64(7), out.caseID)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Success - Case for a single card",
+			input: input{
+				userID: 1,
+				cardID: 2,
+				source: "high_risk_payment",
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectExec(`INSERT INTO cases \(user_id, card_id, source\) VALUES \(\?, \?, \?\)`).
+					WithArgs(in.userID, sql.NullInt64{Int64: in.cardID, Valid: true}, in.source).
+					WillReturnResult(sqlmock.NewResult(8, 1))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, int64(8), out.caseID)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			input: input{
+				userID: 1,
+				source: "manual",
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectExec(`INSERT INTO cases \(user_id, card_id, source\) VALUES \(\?, \?, \?\)`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			caseRepository := NewCaseRepository(db)
+			tt.on(dbMock, tt.input)
+
+			caseID, err := caseRepository.CreateCase(tt.input.userID, tt.input.cardID, tt.input.source)
+			tt.assertFunc(t, output{caseID, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetCase(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type input struct {
+		caseID int64
+	}
+
+	type output struct {
+		c   Case
+		err error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Case found",
+			input: input{
+				caseID: 7,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery("SELECT (.+) FROM cases WHERE id = ?").
+					WithArgs(in.caseID).
+					WillReturnRows(sqlmock.NewRows(caseColumns).AddRow(7, 1, nil, "card_report", "open", nil, createdAt, createdAt))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, Case{ID: 7, UserID: 1, Source: "card_report", Status: "open", CreatedAt: createdAt, UpdatedAt: createdAt}, out.c)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Case not found",
+			input: input{
+				caseID: 99,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery("SELECT (.+) FROM cases WHERE id = ?").
+					WithArgs(in.caseID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.c)
+				assert.ErrorIs(t, out.err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			caseRepository := NewCaseRepository(db)
+			tt.on(dbMock, tt.input)
+
+			c, err := caseRepository.GetCase(tt.input.caseID)
+			tt.assertFunc(t, output{c, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListCases(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type input struct {
+		status   string
+		assignee string
+	}
+
+	type output struct {
+		cases []Case
+		err   error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name:  "Success - Without filters",
+			input: input{},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM cases WHERE 1 = 1 ORDER BY id DESC`).
+					WillReturnRows(sqlmock.NewRows(caseColumns).
+						AddRow(2, 1, 2, "high_risk_payment", "investigating", "alice", createdAt, createdAt).
+						AddRow(1, 1, nil, "card_report", "open", nil, createdAt, createdAt))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Len(t, out.cases, 2)
+				assert.Equal(t, int64(2), out.cases[0].CardID)
+				assert.Equal(t, "alice", out.cases[0].Assignee)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Success - Filtered by status and assignee",
+			input: input{
+				status:   "investigating",
+				assignee: "alice",
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM cases WHERE 1 = 1 AND status = \? AND assignee = \? ORDER BY id DESC`).
+					WithArgs(in.status, in.assignee).
+					WillReturnRows(sqlmock.NewRows(caseColumns).
+						AddRow(2, 1, 2, "high_risk_payment", "investigating", "alice", createdAt, createdAt))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Len(t, out.cases, 1)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:  "Failure - Database error",
+			input: input{},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM cases`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.cases)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			caseRepository := NewCaseRepository(db)
+			tt.on(dbMock, tt.input)
+
+			cases, err := caseRepository.ListCases(tt.input.status, tt.input.assignee)
+			tt.assertFunc(t, output{cases, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateCaseStatus(t *testing.T) {
+	type input struct {
+		caseID       int64
+		status       string
+		fromStatuses []string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Status updated",
+			input: input{
+				caseID:       7,
+				status:       "investigating",
+				fromStatuses: []string{"dismissed", "open"},
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectExec(`UPDATE cases SET status = \?, updated_at = CURRENT_TIMESTAMP WHERE id = \? AND status IN \(\?, \?\)`).
+					WithArgs(in.status, in.caseID, "dismissed", "open").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Case not found or in another status",
+			input: input{
+				caseID:       99,
+				status:       "investigating",
+				fromStatuses: []string{"open"},
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectExec(`UPDATE cases SET status = \?, updated_at = CURRENT_TIMESTAMP WHERE id = \? AND status IN \(\?\)`).
+					WithArgs(in.status, in.caseID, "open").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "Failure - No status to move from",
+			input: input{
+				caseID: 7,
+				status: "archived",
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			input: input{
+				caseID:       7,
+				status:       "investigating",
+				fromStatuses: []string{"open"},
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectExec(`UPDATE cases SET status = \?`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			caseRepository := NewCaseRepository(db)
+			tt.on(dbMock, tt.input)
+
+			err := caseRepository.UpdateCaseStatus(tt.input.caseID, tt.input.status, tt.input.fromStatuses)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAssignCase(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`UPDATE cases SET assignee = \?, updated_at = CURRENT_TIMESTAMP WHERE id = \?`).
+		WithArgs("alice", int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := NewCaseRepository(db).AssignCase(7, "alice")
+
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCaseTransactions(t *testing.T) {
+	attachedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	occurredAt := time.Date(2025, 2, 27, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, transactions []CaseTransaction, err error)
+	}{
+		{
+			name: "Success - Transactions attached and listed",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`INSERT INTO case_transactions \(case_id, transaction_id, card_id, amount, occurred_at\) VALUES \(\?, \?, \?, \?, \?\)`).
+					WithArgs(int64(7), "txn_1234567", int64(2), 100.5, occurredAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectQuery(`SELECT transaction_id, card_id, amount, occurred_at, attached_at FROM case_transactions WHERE case_id = \? ORDER BY id`).
+					WithArgs(int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "card_id", "amount", "occurred_at", "attached_at"}).
+						AddRow("txn_1234567", 2, 100.5, occurredAt, attachedAt).
+						AddRow("txn_7654321", 2, 20.0, nil, attachedAt))
+			},
+			assertFunc: func(t *testing.T, transactions []CaseTransaction, err error) {
+				assert.Equal(t, []CaseTransaction{
+					{TransactionID: "txn_1234567", CardID: 2, Amount: 100.5, OccurredAt: &occurredAt, AttachedAt: attachedAt},
+					{TransactionID: "txn_7654321", CardID: 2, Amount: 20, AttachedAt: attachedAt},
+				}, transactions)
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Duplicated transaction",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`INSERT INTO case_transactions`).
+					WillReturnError(errors.New("UNIQUE constraint failed: case_transactions.case_id, case_transactions.transaction_id"))
+			},
+			assertFunc: func(t *testing.T, transactions []CaseTransaction, err error) {
+				assert.Empty(t, transactions)
+				assert.EqualError(t, err, "UNIQUE constraint failed: case_transactions.case_id, case_transactions.transaction_id")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			caseRepository := NewCaseRepository(db)
+			tt.on(dbMock)
+
+			var transactions []CaseTransaction
+			err := caseRepository.AttachTransaction(7, CaseTransaction{TransactionID: "txn_1234567", CardID: 2, Amount: 100.5, OccurredAt: &occurredAt})
+			if err == nil {
+				transactions, err = caseRepository.GetCaseTransactions(7)
+			}
+			tt.assertFunc(t, transactions, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCaseNotes(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`INSERT INTO case_notes \(case_id, author, body\) VALUES \(\?, \?, \?\)`).
+		WithArgs(int64(7), "alice", "customer confirmed the theft").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectQuery(`SELECT author, body, created_at FROM case_notes WHERE case_id = \? ORDER BY id`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"author", "body", "created_at"}).
+			AddRow("alice", "customer confirmed the theft", createdAt))
+
+	caseRepository := NewCaseRepository(db)
+	err := caseRepository.AddNote(7, "alice", "customer confirmed the theft")
+	assert.NoError(t, err)
+
+	notes, err := caseRepository.GetCaseNotes(7)
+	assert.NoError(t, err)
+	assert.Equal(t, []CaseNote{{Author: "alice", Body: "customer confirmed the theft", CreatedAt: createdAt}}, notes)
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Domain events written to the outbox.
+const (
+	EventCardReported   = "CardReported"
+	EventCardBlocked    = "CardBlocked"
+	EventCardReinstated = "CardReinstated"
+	EventUserLocked     = "UserLocked"
+)
+
+var EventTypes = []string{EventCardReported, EventCardBlocked, EventCardReinstated, EventUserLocked}
+
+// Event is a domain change recorded in the outbox. Its ID orders the events and is the cursor consumers resume from.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type CardReportedEvent struct {
+	UserID int64 `json:"user_id"`
+	CardID int64 `json:"card_id"`
+}
+
+// CardBlockedEvent is written when a PAN is blocked for every user it is linked to. ImportID is set for the cards of
+// a compromised card feed.
+type CardBlockedEvent struct {
+	CardFingerprint string `json:"card_fingerprint"`
+	Source          string `json:"source"`
+	ImportID        int64  `json:"import_id,omitempty"`
+}
+
+type CardReinstatedEvent struct {
+	UserID          int64  `json:"user_id"`
+	CardID          int64  `json:"card_id"`
+	CardFingerprint string `json:"card_fingerprint"`
+}
+
+// UserLockedEvent is written when every payment of the user gets denied, after a confirmed sanctions match or a
+// rejected identity verification.
+type UserLockedEvent struct {
+	UserID int64  `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source event_repository.go -destination mock/event_repository_mock.go -package mock
+type EventRepository interface {
+	ListEvents(after int64, eventTypes []string, limit int) ([]Event, error)
+	GetEventBounds() (oldestID int64, latestID int64, err error)
+	DeleteEventsBefore(before time.Time) (int64, error)
+	GetCursor(consumer string) (int64, error)
+	SaveCursor(consumer string, lastEventID int64) error
+}
+
+type eventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) EventRepository {
+	return &eventRepository{db: db}
+}
+
+// insertEvent adds an event to the outbox within the transaction of the change it describes, so the event is
+// recorded if and only if the change is committed.
+func insertEvent(tx *sql.Tx, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO events (event_type, payload, created_at) VALUES (?, ?, ?)", eventType, string(data), time.Now().UTC())
+	return err
+}
+
+// ListEvents returns up to limit events after the cursor, oldest first, only of the given types if any.
+func (r *eventRepository) ListEvents(after int64, eventTypes []string, limit int) ([]Event, error) {
+	query := "SELECT id, event_type, payload, created_at FROM events WHERE id > ?"
+	args := []any{after}
+	if len(eventTypes) > 0 {
+		query += " AND event_type IN (" + placeholders(len(eventTypes)) + ")"
+		for _, eventType := range eventTypes {
+			args = append(args, eventType)
+		}
+	}
+	query += " ORDER BY id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var event Event
+		var payload string
+		if err := rows.Scan(&event.ID, &event.Type, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetEventBounds returns the IDs of the oldest and latest events kept, both 0 when there is none.
+func (r *eventRepository) GetEventBounds() (int64, int64, error) {
+	var oldestID, latestID int64
+	err := r.db.QueryRow("SELECT COALESCE(MIN(id), 0), COALESCE(MAX(id), 0) FROM events").Scan(&oldestID, &latestID)
+	return oldestID, latestID, err
+}
+
+// DeleteEventsBefore purges the events created before the given time. The latest event is always kept, so a cursor
+// older than every event left can still be told apart from one that is up to date.
+func (r *eventRepository) DeleteEventsBefore(before time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM events WHERE created_at < ? AND id < (SELECT MAX(id) FROM events)", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetCursor returns the last event the consumer has handled, 0 when it has not handled any yet.
+func (r *eventRepository) GetCursor(consumer string) (int64, error) {
+	var lastEventID int64
+	err := r.db.QueryRow("SELECT last_event_id FROM event_cursors WHERE consumer = ?", consumer).Scan(&lastEventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return lastEventID, err
+}
+
+func (r *eventRepository) SaveCursor(consumer string, lastEventID int64) error {
+	_, err := r.db.Exec(`INSERT INTO event_cursors (consumer, last_event_id) VALUES (?, ?)
+		ON CONFLICT (consumer) DO UPDATE SET last_event_id = excluded.last_event_id`, consumer, lastEventID)
+	return err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectEvent expects an event written to the outbox within the transaction of a change.
+func expectEvent(dbMock sqlmock.Sqlmock, eventType string, payload string) {
+	dbMock.ExpectExec(`INSERT INTO events \(event_type, payload, created_at\) VALUES \(\?, \?, \?\)`).
+		WithArgs(eventType, payload, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestListEvents(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		eventTypes []string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, events []Event, err error)
+	}{
+		{
+			name: "Success - Events after the cursor",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT id, event_type, payload, created_at FROM events WHERE id > \? ORDER BY id LIMIT \?`).
+					WithArgs(int64(4), 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "event_type", "payload", "created_at"}).
+						AddRow(5, EventCardReported, `{"user_id":1,"card_id":2}`, createdAt).
+						AddRow(6, EventUserLocked, `{"user_id":1,"reason":"sanctions_match"}`, createdAt))
+			},
+			assertFunc: func(t *testing.T, events []Event, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []Event{
+					{ID: 5, Type: EventCardReported, Payload: json.RawMessage(`{"user_id":1,"card_id":2}`), CreatedAt: createdAt},
+					{ID: 6, Type: EventUserLocked, Payload: json.RawMessage(`{"user_id":1,"reason":"sanctions_match"}`), CreatedAt: createdAt},
+				}, events)
+			},
+		},
+		{
+			name:       "Success - Only the given types",
+			eventTypes: []string{EventCardReported, EventCardReinstated},
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT id, event_type, payload, created_at FROM events WHERE id > \? AND event_type IN \(\?, \?\) ORDER BY id LIMIT \?`).
+					WithArgs(int64(4), EventCardReported, EventCardReinstated, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "event_type", "payload", "created_at"}))
+			},
+			assertFunc: func(t *testing.T, events []Event, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []Event{}, events)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT id, event_type, payload, created_at FROM events`).WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, events []Event, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			events, err := NewEventRepository(db).ListEvents(4, tt.eventTypes, 2)
+
+			tt.assertFunc(t, events, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetEventBounds(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT COALESCE\(MIN\(id\), 0\), COALESCE\(MAX\(id\), 0\) FROM events`).
+		WillReturnRows(sqlmock.NewRows([]string{"oldest", "latest"}).AddRow(3, 9))
+
+	oldestID, latestID, err := NewEventRepository(db).GetEventBounds()
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), oldestID)
+	assert.Equal(t, int64(9), latestID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteEventsBefore(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	before := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	dbMock.ExpectExec(`DELETE FROM events WHERE created_at < \? AND id < \(SELECT MAX\(id\) FROM events\)`).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 12))
+
+	deleted, err := NewEventRepository(db).DeleteEventsBefore(before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), deleted)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestEventCursor(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT last_event_id FROM event_cursors WHERE consumer = \?`).WithArgs("webhooks").WillReturnError(sql.ErrNoRows)
+	dbMock.ExpectExec(`INSERT INTO event_cursors \(consumer, last_event_id\) VALUES \(\?, \?\)\s+ON CONFLICT \(consumer\) DO UPDATE SET last_event_id = excluded.last_event_id`).
+		WithArgs("webhooks", int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(`SELECT last_event_id FROM event_cursors`).WithArgs("webhooks").WillReturnRows(sqlmock.NewRows([]string{"last_event_id"}).AddRow(42))
+
+	repository := NewEventRepository(db)
+
+	cursor, err := repository.GetCursor("webhooks")
+	assert.NoError(t, err)
+	assert.Zero(t, cursor, "a new consumer starts from the first event")
+
+	assert.NoError(t, repository.SaveCursor("webhooks", 42))
+
+	cursor, err = repository.GetCursor("webhooks")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), cursor)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// KYCProfile holds the identity of a user. IDDocumentNumber is the encrypted document number and is never sent to clients.
+type KYCProfile struct {
+	UserID           int64      `json:"user_id"`
+	LegalName        string     `json:"legal_name,omitempty"`
+	DateOfBirth      string     `json:"date_of_birth,omitempty"`
+	AddressLine      string     `json:"address_line,omitempty"`
+	City             string     `json:"city,omitempty"`
+	PostalCode       string     `json:"postal_code,omitempty"`
+	Country          string     `json:"country,omitempty"`
+	Nationality      string     `json:"nationality,omitempty"`
+	IDDocumentType   string     `json:"id_document_type,omitempty"`
+	IDDocumentNumber string     `json:"-"`
+	IDDocumentLast4  string     `json:"id_document_last4,omitempty"`
+	Status           string     `json:"status"`
+	Tier             int        `json:"tier"`
+	Reviewer         string     `json:"reviewer,omitempty"`
+	ReviewNote       string     `json:"review_note,omitempty"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source kyc_repository.go -destination mock/kyc_repository_mock.go -package mock
+type KYCRepository interface {
+	SaveProfile(profile KYCProfile) error
+	GetProfile(userID int64) (KYCProfile, error)
+	ListProfiles(status string) ([]KYCProfile, error)
+	ReviewProfile(userID int64, status string, tier int, reviewer string, note string, reviewedAt time.Time) error
+}
+
+type kycRepository struct {
+	db *sql.DB
+}
+
+func NewKYCRepository(db *sql.DB) KYCRepository {
+	return &kycRepository{db: db}
+}
+
+// SaveProfile creates or replaces the profile of the user, clearing the outcome of any previous review.
+func (r *kycRepository) SaveProfile(profile KYCProfile) error {
+	_, err := r.db.Exec(`INSERT INTO kyc_profiles (user_id, legal_name, date_of_birth, address_line, city, postal_code, country, nationality,
+			id_document_type, id_document_number, id_document_last4, status, tier, submitted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET legal_name = excluded.legal_name, date_of_birth = excluded.date_of_birth, address_line = excluded.address_line,
+			city = excluded.city, postal_code = excluded.postal_code, country = excluded.country, nationality = excluded.nationality,
+			id_document_type = excluded.id_document_type, id_document_number = excluded.id_document_number, id_document_last4 = excluded.id_document_last4,
+			status = excluded.status, tier = excluded.tier, reviewer = '', review_note = '', submitted_at = excluded.submitted_at, reviewed_at = NULL`,
+		profile.UserID, profile.LegalName, profile.DateOfBirth, profile.AddressLine, profile.City, profile.PostalCode, profile.Country, profile.Nationality,
+		profile.IDDocumentType, profile.IDDocumentNumber, profile.IDDocumentLast4, profile.Status, profile.Tier, nullableTime(profile.SubmittedAt))
+	return err
+}
+
+func (r *kycRepository) GetProfile(userID int64) (KYCProfile, error) {
+	return scanKYCProfile(r.db.QueryRow(kycProfileQuery+" WHERE user_id = ?", userID))
+}
+
+func (r *kycRepository) ListProfiles(status string) ([]KYCProfile, error) {
+	query := kycProfileQuery + " WHERE 1 = 1"
+	var args []any
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+
+	rows, err := r.db.Query(query+" ORDER BY submitted_at, user_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []KYCProfile
+	for rows.Next() {
+		profile, err := scanKYCProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// ReviewProfile records the outcome of the review of a pending profile. It returns sql.ErrNoRows when the user has
+// no pending profile. A rejection locks the user, which is written to the outbox.
+func (r *kycRepository) ReviewProfile(userID int64, status string, tier int, reviewer string, note string, reviewedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("UPDATE kyc_profiles SET status = ?, tier = ?, reviewer = ?, review_note = ?, reviewed_at = ? WHERE user_id = ? AND status = 'pending'",
+		status, tier, reviewer, note, reviewedAt, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := expectAffected(result); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if status == "rejected" {
+		if err := insertEvent(tx, EventUserLocked, UserLockedEvent{UserID: userID, Reason: "kyc_rejected"}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const kycProfileQuery = `SELECT user_id, legal_name, date_of_birth, address_line, city, postal_code, country, nationality, id_document_type, id_document_number,
+	id_document_last4, status, tier, reviewer, review_note, submitted_at, reviewed_at FROM kyc_profiles`
+
+func scanKYCProfile(row rowScanner) (KYCProfile, error) {
+	var profile KYCProfile
+	var submittedAt, reviewedAt sql.NullTime
+	if err := row.Scan(&profile.UserID, &profile.LegalName, &profile.DateOfBirth, &profile.AddressLine, &profile.City, &profile.PostalCode, &profile.Country,
+		&profile.Nationality, &profile.IDDocumentType, &profile.IDDocumentNumber, &profile.IDDocumentLast4, &profile.Status, &profile.Tier,
+		&profile.Reviewer, &profile.ReviewNote, &submittedAt, &reviewedAt); err != nil {
+		return KYCProfile{}, err
+	}
+	if submittedAt.Valid {
+		profile.SubmittedAt = &submittedAt.Time
+	}
+	if reviewedAt.Valid {
+		profile.ReviewedAt = &reviewedAt.Time
+	}
+
+	return profile, nil
+}
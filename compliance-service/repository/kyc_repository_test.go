@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var kycProfileRows = []string{"user_id", "legal_name", "date_of_birth", "address_line", "city", "postal_code", "country", "nationality", "id_document_type",
+	"id_document_number", "id_document_last4", "status", "tier", "reviewer", "review_note", "submitted_at", "reviewed_at"}
+
+func TestSaveKYCProfile(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`INSERT INTO kyc_profiles (.+) ON CONFLICT \(user_id\) DO UPDATE SET (.+) reviewer = '', review_note = '', submitted_at = excluded.submitted_at, reviewed_at = NULL`).
+		WithArgs(int64(1), "John Doe", "1990-05-17", "1 Main St", "Springfield", "12345", "US", "US", "passport", "ciphertext", "6789", "pending", 0,
+			sql.NullTime{Time: now, Valid: true}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := NewKYCRepository(db).SaveProfile(KYCProfile{UserID: 1, LegalName: "John Doe", DateOfBirth: "1990-05-17", AddressLine: "1 Main St", City: "Springfield",
+		PostalCode: "12345", Country: "US", Nationality: "US", IDDocumentType: "passport", IDDocumentNumber: "ciphertext", IDDocumentLast4: "6789",
+		Status: "pending", SubmittedAt: &now})
+
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetAndListKYCProfiles(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	reviewedAt := now.Add(time.Hour)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT (.+) FROM kyc_profiles WHERE user_id = \?`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(kycProfileRows).
+			AddRow(1, "John Doe", "1990-05-17", "1 Main St", "Springfield", "", "US", "US", "passport", "ciphertext", "6789", "verified", 1, "alice", "", now, reviewedAt))
+	dbMock.ExpectQuery(`SELECT (.+) FROM kyc_profiles WHERE user_id = \?`).
+		WithArgs(int64(2)).
+		WillReturnError(sql.ErrNoRows)
+	dbMock.ExpectQuery(`SELECT (.+) FROM kyc_profiles WHERE 1 = 1 AND status = \? ORDER BY submitted_at, user_id`).
+		WithArgs("pending").
+		WillReturnRows(sqlmock.NewRows(kycProfileRows).
+			AddRow(3, "Jane Smith", "1985-01-02", "2 High St", "Leeds", "LS1", "GB", "GB", "national_id", "ciphertext", "1234", "pending", 0, "", "", now, nil))
+
+	repository := NewKYCRepository(db)
+
+	profile, err := repository.GetProfile(1)
+	assert.NoError(t, err)
+	assert.Equal(t, KYCProfile{UserID: 1, LegalName: "John Doe", DateOfBirth: "1990-05-17", AddressLine: "1 Main St", City: "Springfield", Country: "US",
+		Nationality: "US", IDDocumentType: "passport", IDDocumentNumber: "ciphertext", IDDocumentLast4: "6789", Status: "verified", Tier: 1, Reviewer: "alice",
+		SubmittedAt: &now, ReviewedAt: &reviewedAt}, profile)
+
+	_, err = repository.GetProfile(2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	profiles, err := repository.ListProfiles("pending")
+	assert.NoError(t, err)
+	assert.Len(t, profiles, 1)
+	assert.Equal(t, int64(3), profiles[0].UserID)
+	assert.Nil(t, profiles[0].ReviewedAt)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReviewKYCProfile(t *testing.T) {
+	reviewedAt := time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`UPDATE kyc_profiles SET status = \?, tier = \?, reviewer = \?, review_note = \?, reviewed_at = \? WHERE user_id = \? AND status = 'pending'`).
+		WithArgs("verified", 2, "alice", "passport checked", reviewedAt, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`UPDATE kyc_profiles SET status = \?`).
+		WithArgs("rejected", 0, "alice", "forged document", reviewedAt, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectRollback()
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`UPDATE kyc_profiles SET status = \?`).
+		WithArgs("rejected", 0, "alice", "forged document", reviewedAt, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(dbMock, EventUserLocked, `{"user_id":3,"reason":"kyc_rejected"}`)
+	dbMock.ExpectCommit()
+
+	repository := NewKYCRepository(db)
+
+	assert.NoError(t, repository.ReviewProfile(1, "verified", 2, "alice", "passport checked", reviewedAt))
+	assert.ErrorIs(t, repository.ReviewProfile(2, "rejected", 0, "alice", "forged document", reviewedAt), sql.ErrNoRows)
+	assert.NoError(t, repository.ReviewProfile(3, "rejected", 0, "alice", "forged document", reviewedAt))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
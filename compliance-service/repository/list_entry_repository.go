@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type ListEntry struct {
+	ID        int64      `json:"id"`
+	ListType  string     `json:"list_type"`
+	EntryType string     `json:"entry_type"`
+	Value     string     `json:"value"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source list_entry_repository.go -destination mock/list_entry_repository_mock.go -package mock
+type ListEntryRepository interface {
+	CreateEntry(entry ListEntry) (int64, error)
+	UpsertEntries(entries []ListEntry) error
+	GetEntry(entryID int64) (ListEntry, error)
+	ListEntries(listType string, entryType string) ([]ListEntry, error)
+	UpdateEntry(entryID int64, reason string, expiresAt *time.Time, updatedAt time.Time) error
+	DeleteEntry(entryID int64) error
+	FindActiveEntries(entryType string, values []string, now time.Time) ([]ListEntry, error)
+	GetActiveEntries(entryType string, now time.Time) ([]ListEntry, error)
+}
+
+type listEntryRepository struct {
+	db *sql.DB
+}
+
+func NewListEntryRepository(db *sql.DB) ListEntryRepository {
+	return &listEntryRepository{db: db}
+}
+
+const listEntryColumns = "id, list_type, entry_type, value, reason, expires_at, created_at, updated_at"
+
+func (r *listEntryRepository) CreateEntry(entry ListEntry) (int64, error) {
+	result, err := r.db.Exec("INSERT INTO list_entries (list_type, entry_type, value, reason, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		entry.ListType, entry.EntryType, entry.Value, entry.Reason, nullableTime(entry.ExpiresAt), entry.CreatedAt, entry.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// UpsertEntries stores all the entries in a single transaction. Entries already on the list get their
+// reason and expiry replaced, so importing the same file twice leaves the lists unchanged.
+func (r *listEntryRepository) UpsertEntries(entries []ListEntry) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO list_entries (list_type, entry_type, value, reason, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (list_type, entry_type, value) DO UPDATE SET reason = excluded.reason, expires_at = excluded.expires_at, updated_at = excluded.updated_at`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		_, err := stmt.Exec(entry.ListType, entry.EntryType, entry.Value, entry.Reason, nullableTime(entry.ExpiresAt), entry.CreatedAt, entry.UpdatedAt)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *listEntryRepository) GetEntry(entryID int64) (ListEntry, error) {
+	return scanListEntry(r.db.QueryRow("SELECT "+listEntryColumns+" FROM list_entries WHERE id = ?", entryID))
+}
+
+func (r *listEntryRepository) ListEntries(listType string, entryType string) ([]ListEntry, error) {
+	query := "SELECT " + listEntryColumns + " FROM list_entries WHERE 1 = 1"
+	var args []any
+	if listType != "" {
+		query += " AND list_type = ?"
+		args = append(args, listType)
+	}
+	if entryType != "" {
+		query += " AND entry_type = ?"
+		args = append(args, entryType)
+	}
+	query += " ORDER BY id"
+
+	return r.queryListEntries(query, args...)
+}
+
+func (r *listEntryRepository) UpdateEntry(entryID int64, reason string, expiresAt *time.Time, updatedAt time.Time) error {
+	result, err := r.db.Exec("UPDATE list_entries SET reason = ?, expires_at = ?, updated_at = ? WHERE id = ?", reason, nullableTime(expiresAt), updatedAt, entryID)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (r *listEntryRepository) DeleteEntry(entryID int64) error {
+	result, err := r.db.Exec("DELETE FROM list_entries WHERE id = ?", entryID)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// FindActiveEntries returns the unexpired entries of both lists whose value is one of the given values.
+func (r *listEntryRepository) FindActiveEntries(entryType string, values []string, now time.Time) ([]ListEntry, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	args := []any{entryType, now}
+	for _, value := range values {
+		args = append(args, value)
+	}
+
+	return r.queryListEntries("SELECT "+listEntryColumns+" FROM list_entries WHERE entry_type = ? AND (expires_at IS NULL OR expires_at > ?) AND value IN ("+
+		placeholders(len(values))+") ORDER BY id", args...)
+}
+
+// GetActiveEntries returns every unexpired entry of the given type, for the types matched by range rather than by value.
+func (r *listEntryRepository) GetActiveEntries(entryType string, now time.Time) ([]ListEntry, error) {
+	return r.queryListEntries("SELECT "+listEntryColumns+" FROM list_entries WHERE entry_type = ? AND (expires_at IS NULL OR expires_at > ?) ORDER BY id", entryType, now)
+}
+
+func (r *listEntryRepository) queryListEntries(query string, args ...any) ([]ListEntry, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ListEntry
+	for rows.Next() {
+		entry, err := scanListEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func scanListEntry(row rowScanner) (ListEntry, error) {
+	var entry ListEntry
+	var expiresAt sql.NullTime
+	if err := row.Scan(&entry.ID, &entry.ListType, &entry.EntryType, &entry.Value, &entry.Reason, &expiresAt, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
+		return ListEntry{}, err
+	}
+	if expiresAt.Valid {
+		entry.ExpiresAt = &expiresAt.Time
+	}
+
+	return entry, nil
+}
+
+func nullableTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var listEntryRows = []string{"id", "list_type", "entry_type", "value", "reason", "expires_at", "created_at", "updated_at"}
+
+func TestCreateListEntry(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.AddDate(0, 1, 0)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`INSERT INTO list_entries \(list_type, entry_type, value, reason, expires_at, created_at, updated_at\) VALUES \(\?, \?, \?, \?, \?, \?, \?\)`).
+		WithArgs("deny", "ip", "203.0.113.0/24", "botnet", sql.NullTime{Time: expiresAt, Valid: true}, now, now).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	entryID, err := NewListEntryRepository(db).CreateEntry(ListEntry{ListType: "deny", EntryType: "ip", Value: "203.0.113.0/24", Reason: "botnet", ExpiresAt: &expiresAt, CreatedAt: now, UpdatedAt: now})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), entryID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpsertListEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []ListEntry{
+		{ListType: "deny", EntryType: "device_id", Value: "device-1", Reason: "emulator", CreatedAt: now, UpdatedAt: now},
+		{ListType: "allow", EntryType: "ip", Value: "203.0.113.7", Reason: "office", CreatedAt: now, UpdatedAt: now},
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Entries upserted",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				prepared := dbMock.ExpectPrepare(`INSERT INTO list_entries (.+) ON CONFLICT \(list_type, entry_type, value\) DO UPDATE SET reason = excluded.reason, expires_at = excluded.expires_at, updated_at = excluded.updated_at`)
+				prepared.ExpectExec().WithArgs("deny", "device_id", "device-1", "emulator", sql.NullTime{}, now, now).WillReturnResult(sqlmock.NewResult(1, 1))
+				prepared.ExpectExec().WithArgs("allow", "ip", "203.0.113.7", "office", sql.NullTime{}, now, now).WillReturnResult(sqlmock.NewResult(2, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Error rolls back the whole import",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				prepared := dbMock.ExpectPrepare(`INSERT INTO list_entries`)
+				prepared.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+				prepared.ExpectExec().WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewListEntryRepository(db).UpsertEntries(entries)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.AddDate(0, 1, 0)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT (.+) FROM list_entries WHERE 1 = 1 AND list_type = \? AND entry_type = \? ORDER BY id`).
+		WithArgs("deny", "ip").
+		WillReturnRows(sqlmock.NewRows(listEntryRows).
+			AddRow(1, "deny", "ip", "203.0.113.0/24", "botnet", expiresAt, now, now).
+			AddRow(2, "deny", "ip", "198.51.100.1", "card testing", nil, now, now))
+
+	entries, err := NewListEntryRepository(db).ListEntries("deny", "ip")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ListEntry{
+		{ID: 1, ListType: "deny", EntryType: "ip", Value: "203.0.113.0/24", Reason: "botnet", ExpiresAt: &expiresAt, CreatedAt: now, UpdatedAt: now},
+		{ID: 2, ListType: "deny", EntryType: "ip", Value: "198.51.100.1", Reason: "card testing", CreatedAt: now, UpdatedAt: now},
+	}, entries)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFindActiveListEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT (.+) FROM list_entries WHERE entry_type = \? AND \(expires_at IS NULL OR expires_at > \?\) AND value IN \(\?, \?\) ORDER BY id`).
+		WithArgs("email_domain", now, "mail.example.com", "example.com").
+		WillReturnRows(sqlmock.NewRows(listEntryRows).AddRow(4, "deny", "email_domain", "example.com", "disposable", nil, now, now))
+	dbMock.ExpectQuery(`SELECT (.+) FROM list_entries WHERE entry_type = \? AND \(expires_at IS NULL OR expires_at > \?\) ORDER BY id`).
+		WithArgs("bin", now).
+		WillReturnRows(sqlmock.NewRows(listEntryRows))
+
+	listEntryRepository := NewListEntryRepository(db)
+
+	entries, err := listEntryRepository.FindActiveEntries("email_domain", []string{"mail.example.com", "example.com"}, now)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = listEntryRepository.FindActiveEntries("device_id", nil, now)
+	assert.NoError(t, err)
+	assert.Nil(t, entries)
+
+	entries, err = listEntryRepository.GetActiveEntries("bin", now)
+	assert.NoError(t, err)
+	assert.Nil(t, entries)
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateAndDeleteListEntry(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		call       func(listEntryRepository ListEntryRepository) error
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Entry updated",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`UPDATE list_entries SET reason = \?, expires_at = \?, updated_at = \? WHERE id = \?`).
+					WithArgs("confirmed botnet", sql.NullTime{}, now, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(listEntryRepository ListEntryRepository) error {
+				return listEntryRepository.UpdateEntry(1, "confirmed botnet", nil, now)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Updated entry not found",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`UPDATE list_entries`).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			call: func(listEntryRepository ListEntryRepository) error {
+				return listEntryRepository.UpdateEntry(1, "confirmed botnet", nil, now)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "Success - Entry deleted",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`DELETE FROM list_entries WHERE id = \?`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(listEntryRepository ListEntryRepository) error {
+				return listEntryRepository.DeleteEntry(1)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Deleted entry not found",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`DELETE FROM list_entries`).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			call: func(listEntryRepository ListEntryRepository) error {
+				return listEntryRepository.DeleteEntry(1)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+			tt.assertFunc(t, tt.call(NewListEntryRepository(db)))
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
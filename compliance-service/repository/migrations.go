@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"database/sql"
+	"flarrocca/migrate"
+)
+
+// Migrations upgrade a database created by an older database/init.sql, they run before it. A column or a constraint
+// added to a table of init.sql needs a migration here, with the next version.
+var Migrations = []migrate.Migration{
+	{Version: 1, Description: "add users.full_name", Up: migrate.AddColumn("users", "full_name", "TEXT NOT NULL DEFAULT ''")},
+	{Version: 2, Description: "make cards unique per user", Up: rebuildCardsUniquePerUser},
+	{Version: 3, Description: "add case_transactions.occurred_at", Up: migrate.AddColumn("case_transactions", "occurred_at", "TIMESTAMP")},
+}
+
+// rebuildCardsUniquePerUser relaxes UNIQUE (card_number) to UNIQUE (user_id, card_number), for a PAN to be linked to
+// several users. SQLite cannot change the constraints of a table, so it is copied into a new one, keeping the IDs.
+func rebuildCardsUniquePerUser(tx *sql.Tx) error {
+	var uniquePAN int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM pragma_index_list('cards') AS list
+		WHERE list."unique" = 1 AND (SELECT group_concat(name) FROM pragma_index_info(list.name)) = 'card_number'`).
+		Scan(&uniquePAN)
+	if err != nil || uniquePAN == 0 {
+		return err
+	}
+
+	statements := []string{
+		`CREATE TABLE cards_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			card_number TEXT NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+			UNIQUE (user_id, card_number)
+		)`,
+		"INSERT INTO cards_new (id, user_id, card_number) SELECT id, user_id, card_number FROM cards",
+		"DROP TABLE cards",
+		"ALTER TABLE cards_new RENAME TO cards",
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"flarrocca/migrate"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+// initDatabase migrates the database and runs init.sql on it, as the service does at startup.
+func initDatabase(t *testing.T, db *sql.DB) {
+	assert.NoError(t, migrate.Run(db, Migrations))
+	initSQL, err := os.ReadFile(filepath.Join("..", "database", "init.sql"))
+	assert.NoError(t, err)
+	_, err = db.Exec(string(initSQL))
+	assert.NoError(t, err)
+}
+
+func openTestDatabase(t *testing.T, schema string) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "compliance.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if schema != "" {
+		initSQL, err := os.ReadFile(schema)
+		assert.NoError(t, err)
+		_, err = db.Exec(string(initSQL))
+		assert.NoError(t, err)
+	}
+	return db
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "Success - New database"},
+		{name: "Success - Database created by the first release", schema: filepath.Join("testdata", "baseline_init.sql")},
+		{name: "Success - Database created before the case transactions were dated", schema: filepath.Join("testdata", "case_transactions_init.sql")},
+		{name: "Success - Database already up to date", schema: filepath.Join("..", "database", "init.sql")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDatabase(t, tt.schema)
+
+			initDatabase(t, db)
+			// The service restarts on a migrated database.
+			initDatabase(t, db)
+
+			version, err := migrate.Version(db)
+			assert.NoError(t, err)
+			assert.Equal(t, Migrations[len(Migrations)-1].Version, version)
+
+			var fullName string
+			assert.NoError(t, db.QueryRow("SELECT full_name FROM users WHERE user_name = 'jane_smith'").Scan(&fullName))
+			_, err = NewUserRepository(db).ListUsers()
+			assert.NoError(t, err)
+
+			_, err = NewCaseRepository(db).GetCaseTransactions(1)
+			assert.NoError(t, err)
+
+			// A PAN is unique per user only, the card of user 1 can be linked to user 2 too.
+			var cardID int64
+			assert.NoError(t, db.QueryRow("SELECT id FROM cards WHERE card_number = '1234-5678-9012-3456'").Scan(&cardID))
+			assert.Equal(t, int64(1), cardID)
+			_, err = db.Exec("INSERT INTO cards (user_id, card_number) VALUES (2, '1234-5678-9012-3456')")
+			assert.NoError(t, err)
+			_, err = db.Exec("INSERT INTO cards (user_id, card_number) VALUES (1, '1234-5678-9012-3456')")
+			assert.ErrorContains(t, err, "UNIQUE constraint failed: cards.user_id, cards.card_number")
+		})
+	}
+}
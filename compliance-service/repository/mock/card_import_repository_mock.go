@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: card_import_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCardImportRepository is a mock of CardImportRepository interface.
+type MockCardImportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCardImportRepositoryMockRecorder
+}
+
+// MockCardImportRepositoryMockRecorder is the mock recorder for MockCardImportRepository.
+type MockCardImportRepositoryMockRecorder struct {
+	mock *MockCardImportRepository
+}
+
+// NewMockCardImportRepository creates a new mock instance.
+func NewMockCardImportRepository(ctrl *gomock.Controller) *MockCardImportRepository {
+	mock := &MockCardImportRepository{ctrl: ctrl}
+	mock.recorder = &MockCardImportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCardImportRepository) EXPECT() *MockCardImportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimImport mocks base method.
+func (m *MockCardImportRepository) ClaimImport(importID int64, staleBefore, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimImport", importID, staleBefore, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimImport indicates an expected call of ClaimImport.
+func (mr *MockCardImportRepositoryMockRecorder) ClaimImport(importID, staleBefore, updatedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimImport", reflect.TypeOf((*MockCardImportRepository)(nil).ClaimImport), importID, staleBefore, updatedAt)
+}
+
+// CreateImport mocks base method.
+func (m *MockCardImportRepository) CreateImport(cardImport repository.CardImport) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImport", cardImport)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImport indicates an expected call of CreateImport.
+func (mr *MockCardImportRepositoryMockRecorder) CreateImport(cardImport interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImport", reflect.TypeOf((*MockCardImportRepository)(nil).CreateImport), cardImport)
+}
+
+// FinishImport mocks base method.
+func (m *MockCardImportRepository) FinishImport(importID int64, status, errorMessage string, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishImport", importID, status, errorMessage, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishImport indicates an expected call of FinishImport.
+func (mr *MockCardImportRepositoryMockRecorder) FinishImport(importID, status, errorMessage, updatedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishImport", reflect.TypeOf((*MockCardImportRepository)(nil).FinishImport), importID, status, errorMessage, updatedAt)
+}
+
+// GetImport mocks base method.
+func (m *MockCardImportRepository) GetImport(importID int64) (repository.CardImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImport", importID)
+	ret0, _ := ret[0].(repository.CardImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImport indicates an expected call of GetImport.
+func (mr *MockCardImportRepositoryMockRecorder) GetImport(importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImport", reflect.TypeOf((*MockCardImportRepository)(nil).GetImport), importID)
+}
+
+// GetImportRows mocks base method.
+func (m *MockCardImportRepository) GetImportRows(importID, afterRow int64, limit int) ([]repository.CardImportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImportRows", importID, afterRow, limit)
+	ret0, _ := ret[0].([]repository.CardImportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImportRows indicates an expected call of GetImportRows.
+func (mr *MockCardImportRepositoryMockRecorder) GetImportRows(importID, afterRow, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportRows", reflect.TypeOf((*MockCardImportRepository)(nil).GetImportRows), importID, afterRow, limit)
+}
+
+// SaveBatch mocks base method.
+func (m *MockCardImportRepository) SaveBatch(importID int64, source string, rows []repository.CardImportRow, updatedAt time.Time) ([]repository.CardImportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", importID, source, rows, updatedAt)
+	ret0, _ := ret[0].([]repository.CardImportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockCardImportRepositoryMockRecorder) SaveBatch(importID, source, rows, updatedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockCardImportRepository)(nil).SaveBatch), importID, source, rows, updatedAt)
+}
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	repository "flarrocca/compliant-service/repository"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -33,6 +34,36 @@ func (m *MockCardRepository) EXPECT() *MockCardRepositoryMockRecorder {
 	return m.recorder
 }
 
+// GetCardStatuses mocks base method.
+func (m *MockCardRepository) GetCardStatuses(cards []repository.UserCard) ([]repository.CardStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardStatuses", cards)
+	ret0, _ := ret[0].([]repository.CardStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardStatuses indicates an expected call of GetCardStatuses.
+func (mr *MockCardRepositoryMockRecorder) GetCardStatuses(cards interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardStatuses", reflect.TypeOf((*MockCardRepository)(nil).GetCardStatuses), cards)
+}
+
+// GetUserCardDetails mocks base method.
+func (m *MockCardRepository) GetUserCardDetails(userID int64) ([]repository.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCardDetails", userID)
+	ret0, _ := ret[0].([]repository.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCardDetails indicates an expected call of GetUserCardDetails.
+func (mr *MockCardRepositoryMockRecorder) GetUserCardDetails(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCardDetails", reflect.TypeOf((*MockCardRepository)(nil).GetUserCardDetails), userID)
+}
+
 // GetUserCards mocks base method.
 func (m *MockCardRepository) GetUserCards(userID int64) ([]int64, error) {
 	m.ctrl.T.Helper()
@@ -47,3 +78,18 @@ func (mr *MockCardRepositoryMockRecorder) GetUserCards(userID interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCards", reflect.TypeOf((*MockCardRepository)(nil).GetUserCards), userID)
 }
+
+// ListCards mocks base method.
+func (m *MockCardRepository) ListCards() ([]repository.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCards")
+	ret0, _ := ret[0].([]repository.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCards indicates an expected call of ListCards.
+func (mr *MockCardRepositoryMockRecorder) ListCards() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCards", reflect.TypeOf((*MockCardRepository)(nil).ListCards))
+}
@@ -0,0 +1,222 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: case_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCaseRepository is a mock of CaseRepository interface.
+type MockCaseRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaseRepositoryMockRecorder
+}
+
+// MockCaseRepositoryMockRecorder is the mock recorder for MockCaseRepository.
+type MockCaseRepositoryMockRecorder struct {
+	mock *MockCaseRepository
+}
+
+// NewMockCaseRepository creates a new mock instance.
+func NewMockCaseRepository(ctrl *gomock.Controller) *MockCaseRepository {
+	mock := &MockCaseRepository{ctrl: ctrl}
+	mock.recorder = &MockCaseRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaseRepository) EXPECT() *MockCaseRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddNote mocks base method.
+func (m *MockCaseRepository) AddNote(caseID int64, author, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNote", caseID, author, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNote indicates an expected call of AddNote.
+func (mr *MockCaseRepositoryMockRecorder) AddNote(caseID, author, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNote", reflect.TypeOf((*MockCaseRepository)(nil).AddNote), caseID, author, body)
+}
+
+// AssignCase mocks base method.
+func (m *MockCaseRepository) AssignCase(caseID int64, assignee string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignCase", caseID, assignee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignCase indicates an expected call of AssignCase.
+func (mr *MockCaseRepositoryMockRecorder) AssignCase(caseID, assignee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignCase", reflect.TypeOf((*MockCaseRepository)(nil).AssignCase), caseID, assignee)
+}
+
+// AttachTransaction mocks base method.
+func (m *MockCaseRepository) AttachTransaction(caseID int64, transaction repository.CaseTransaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTransaction", caseID, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTransaction indicates an expected call of AttachTransaction.
+func (mr *MockCaseRepositoryMockRecorder) AttachTransaction(caseID, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTransaction", reflect.TypeOf((*MockCaseRepository)(nil).AttachTransaction), caseID, transaction)
+}
+
+// CreateCase mocks base method.
+func (m *MockCaseRepository) CreateCase(userID, cardID int64, source string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCase", userID, cardID, source)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCase indicates an expected call of CreateCase.
+func (mr *MockCaseRepositoryMockRecorder) CreateCase(userID, cardID, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCase", reflect.TypeOf((*MockCaseRepository)(nil).CreateCase), userID, cardID, source)
+}
+
+// GetCase mocks base method.
+func (m *MockCaseRepository) GetCase(caseID int64) (repository.Case, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCase", caseID)
+	ret0, _ := ret[0].(repository.Case)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCase indicates an expected call of GetCase.
+func (mr *MockCaseRepositoryMockRecorder) GetCase(caseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCase", reflect.TypeOf((*MockCaseRepository)(nil).GetCase), caseID)
+}
+
+// GetCaseNotes mocks base method.
+func (m *MockCaseRepository) GetCaseNotes(caseID int64) ([]repository.CaseNote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCaseNotes", caseID)
+	ret0, _ := ret[0].([]repository.CaseNote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCaseNotes indicates an expected call of GetCaseNotes.
+func (mr *MockCaseRepositoryMockRecorder) GetCaseNotes(caseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCaseNotes", reflect.TypeOf((*MockCaseRepository)(nil).GetCaseNotes), caseID)
+}
+
+// GetCaseTransactions mocks base method.
+func (m *MockCaseRepository) GetCaseTransactions(caseID int64) ([]repository.CaseTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCaseTransactions", caseID)
+	ret0, _ := ret[0].([]repository.CaseTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCaseTransactions indicates an expected call of GetCaseTransactions.
+func (mr *MockCaseRepositoryMockRecorder) GetCaseTransactions(caseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCaseTransactions", reflect.TypeOf((*MockCaseRepository)(nil).GetCaseTransactions), caseID)
+}
+
+// GetUserCases mocks base method.
+func (m *MockCaseRepository) GetUserCases(userID int64) ([]repository.Case, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCases", userID)
+	ret0, _ := ret[0].([]repository.Case)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCases indicates an expected call of GetUserCases.
+func (mr *MockCaseRepositoryMockRecorder) GetUserCases(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCases", reflect.TypeOf((*MockCaseRepository)(nil).GetUserCases), userID)
+}
+
+// ListCases mocks base method.
+func (m *MockCaseRepository) ListCases(status, assignee string) ([]repository.Case, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCases", status, assignee)
+	ret0, _ := ret[0].([]repository.Case)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCases indicates an expected call of ListCases.
+func (mr *MockCaseRepositoryMockRecorder) ListCases(status, assignee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCases", reflect.TypeOf((*MockCaseRepository)(nil).ListCases), status, assignee)
+}
+
+// UpdateCaseStatus mocks base method.
+func (m *MockCaseRepository) UpdateCaseStatus(caseID int64, status string, fromStatuses []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCaseStatus", caseID, status, fromStatuses)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCaseStatus indicates an expected call of UpdateCaseStatus.
+func (mr *MockCaseRepositoryMockRecorder) UpdateCaseStatus(caseID, status, fromStatuses interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCaseStatus", reflect.TypeOf((*MockCaseRepository)(nil).UpdateCaseStatus), caseID, status, fromStatuses)
+}
+
+// MockrowScanner is a mock of rowScanner interface.
+type MockrowScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowScannerMockRecorder
+}
+
+// MockrowScannerMockRecorder is the mock recorder for MockrowScanner.
+type MockrowScannerMockRecorder struct {
+	mock *MockrowScanner
+}
+
+// NewMockrowScanner creates a new mock instance.
+func NewMockrowScanner(ctrl *gomock.Controller) *MockrowScanner {
+	mock := &MockrowScanner{ctrl: ctrl}
+	mock.recorder = &MockrowScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrowScanner) EXPECT() *MockrowScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *MockrowScanner) Scan(dest ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockrowScannerMockRecorder) Scan(dest ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockrowScanner)(nil).Scan), dest...)
+}
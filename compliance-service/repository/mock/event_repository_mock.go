@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: event_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEventRepository is a mock of EventRepository interface.
+type MockEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepositoryMockRecorder
+}
+
+// MockEventRepositoryMockRecorder is the mock recorder for MockEventRepository.
+type MockEventRepositoryMockRecorder struct {
+	mock *MockEventRepository
+}
+
+// NewMockEventRepository creates a new mock instance.
+func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
+	mock := &MockEventRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteEventsBefore mocks base method.
+func (m *MockEventRepository) DeleteEventsBefore(before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEventsBefore", before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteEventsBefore indicates an expected call of DeleteEventsBefore.
+func (mr *MockEventRepositoryMockRecorder) DeleteEventsBefore(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEventsBefore", reflect.TypeOf((*MockEventRepository)(nil).DeleteEventsBefore), before)
+}
+
+// GetCursor mocks base method.
+func (m *MockEventRepository) GetCursor(consumer string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCursor", consumer)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCursor indicates an expected call of GetCursor.
+func (mr *MockEventRepositoryMockRecorder) GetCursor(consumer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCursor", reflect.TypeOf((*MockEventRepository)(nil).GetCursor), consumer)
+}
+
+// GetEventBounds mocks base method.
+func (m *MockEventRepository) GetEventBounds() (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventBounds")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEventBounds indicates an expected call of GetEventBounds.
+func (mr *MockEventRepositoryMockRecorder) GetEventBounds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventBounds", reflect.TypeOf((*MockEventRepository)(nil).GetEventBounds))
+}
+
+// ListEvents mocks base method.
+func (m *MockEventRepository) ListEvents(after int64, eventTypes []string, limit int) ([]repository.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", after, eventTypes, limit)
+	ret0, _ := ret[0].([]repository.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents.
+func (mr *MockEventRepositoryMockRecorder) ListEvents(after, eventTypes, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockEventRepository)(nil).ListEvents), after, eventTypes, limit)
+}
+
+// SaveCursor mocks base method.
+func (m *MockEventRepository) SaveCursor(consumer string, lastEventID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCursor", consumer, lastEventID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCursor indicates an expected call of SaveCursor.
+func (mr *MockEventRepositoryMockRecorder) SaveCursor(consumer, lastEventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCursor", reflect.TypeOf((*MockEventRepository)(nil).SaveCursor), consumer, lastEventID)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: kyc_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockKYCRepository is a mock of KYCRepository interface.
+type MockKYCRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockKYCRepositoryMockRecorder
+}
+
+// MockKYCRepositoryMockRecorder is the mock recorder for MockKYCRepository.
+type MockKYCRepositoryMockRecorder struct {
+	mock *MockKYCRepository
+}
+
+// NewMockKYCRepository creates a new mock instance.
+func NewMockKYCRepository(ctrl *gomock.Controller) *MockKYCRepository {
+	mock := &MockKYCRepository{ctrl: ctrl}
+	mock.recorder = &MockKYCRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKYCRepository) EXPECT() *MockKYCRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetProfile mocks base method.
+func (m *MockKYCRepository) GetProfile(userID int64) (repository.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", userID)
+	ret0, _ := ret[0].(repository.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockKYCRepositoryMockRecorder) GetProfile(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockKYCRepository)(nil).GetProfile), userID)
+}
+
+// ListProfiles mocks base method.
+func (m *MockKYCRepository) ListProfiles(status string) ([]repository.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProfiles", status)
+	ret0, _ := ret[0].([]repository.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProfiles indicates an expected call of ListProfiles.
+func (mr *MockKYCRepositoryMockRecorder) ListProfiles(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProfiles", reflect.TypeOf((*MockKYCRepository)(nil).ListProfiles), status)
+}
+
+// ReviewProfile mocks base method.
+func (m *MockKYCRepository) ReviewProfile(userID int64, status string, tier int, reviewer, note string, reviewedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewProfile", userID, status, tier, reviewer, note, reviewedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewProfile indicates an expected call of ReviewProfile.
+func (mr *MockKYCRepositoryMockRecorder) ReviewProfile(userID, status, tier, reviewer, note, reviewedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewProfile", reflect.TypeOf((*MockKYCRepository)(nil).ReviewProfile), userID, status, tier, reviewer, note, reviewedAt)
+}
+
+// SaveProfile mocks base method.
+func (m *MockKYCRepository) SaveProfile(profile repository.KYCProfile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveProfile", profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProfile indicates an expected call of SaveProfile.
+func (mr *MockKYCRepositoryMockRecorder) SaveProfile(profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProfile", reflect.TypeOf((*MockKYCRepository)(nil).SaveProfile), profile)
+}
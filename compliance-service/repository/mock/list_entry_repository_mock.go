@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: list_entry_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockListEntryRepository is a mock of ListEntryRepository interface.
+type MockListEntryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockListEntryRepositoryMockRecorder
+}
+
+// MockListEntryRepositoryMockRecorder is the mock recorder for MockListEntryRepository.
+type MockListEntryRepositoryMockRecorder struct {
+	mock *MockListEntryRepository
+}
+
+// NewMockListEntryRepository creates a new mock instance.
+func NewMockListEntryRepository(ctrl *gomock.Controller) *MockListEntryRepository {
+	mock := &MockListEntryRepository{ctrl: ctrl}
+	mock.recorder = &MockListEntryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockListEntryRepository) EXPECT() *MockListEntryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateEntry mocks base method.
+func (m *MockListEntryRepository) CreateEntry(entry repository.ListEntry) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", entry)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockListEntryRepositoryMockRecorder) CreateEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockListEntryRepository)(nil).CreateEntry), entry)
+}
+
+// DeleteEntry mocks base method.
+func (m *MockListEntryRepository) DeleteEntry(entryID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEntry", entryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEntry indicates an expected call of DeleteEntry.
+func (mr *MockListEntryRepositoryMockRecorder) DeleteEntry(entryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntry", reflect.TypeOf((*MockListEntryRepository)(nil).DeleteEntry), entryID)
+}
+
+// FindActiveEntries mocks base method.
+func (m *MockListEntryRepository) FindActiveEntries(entryType string, values []string, now time.Time) ([]repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveEntries", entryType, values, now)
+	ret0, _ := ret[0].([]repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveEntries indicates an expected call of FindActiveEntries.
+func (mr *MockListEntryRepositoryMockRecorder) FindActiveEntries(entryType, values, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveEntries", reflect.TypeOf((*MockListEntryRepository)(nil).FindActiveEntries), entryType, values, now)
+}
+
+// GetActiveEntries mocks base method.
+func (m *MockListEntryRepository) GetActiveEntries(entryType string, now time.Time) ([]repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveEntries", entryType, now)
+	ret0, _ := ret[0].([]repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveEntries indicates an expected call of GetActiveEntries.
+func (mr *MockListEntryRepositoryMockRecorder) GetActiveEntries(entryType, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveEntries", reflect.TypeOf((*MockListEntryRepository)(nil).GetActiveEntries), entryType, now)
+}
+
+// GetEntry mocks base method.
+func (m *MockListEntryRepository) GetEntry(entryID int64) (repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", entryID)
+	ret0, _ := ret[0].(repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockListEntryRepositoryMockRecorder) GetEntry(entryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockListEntryRepository)(nil).GetEntry), entryID)
+}
+
+// ListEntries mocks base method.
+func (m *MockListEntryRepository) ListEntries(listType, entryType string) ([]repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", listType, entryType)
+	ret0, _ := ret[0].([]repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockListEntryRepositoryMockRecorder) ListEntries(listType, entryType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockListEntryRepository)(nil).ListEntries), listType, entryType)
+}
+
+// UpdateEntry mocks base method.
+func (m *MockListEntryRepository) UpdateEntry(entryID int64, reason string, expiresAt *time.Time, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEntry", entryID, reason, expiresAt, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEntry indicates an expected call of UpdateEntry.
+func (mr *MockListEntryRepositoryMockRecorder) UpdateEntry(entryID, reason, expiresAt, updatedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEntry", reflect.TypeOf((*MockListEntryRepository)(nil).UpdateEntry), entryID, reason, expiresAt, updatedAt)
+}
+
+// UpsertEntries mocks base method.
+func (m *MockListEntryRepository) UpsertEntries(entries []repository.ListEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEntries", entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertEntries indicates an expected call of UpsertEntries.
+func (mr *MockListEntryRepositoryMockRecorder) UpsertEntries(entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEntries", reflect.TypeOf((*MockListEntryRepository)(nil).UpsertEntries), entries)
+}
@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: payment_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPaymentRepository is a mock of PaymentRepository interface.
+type MockPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRepositoryMockRecorder
+}
+
+// MockPaymentRepositoryMockRecorder is the mock recorder for MockPaymentRepository.
+type MockPaymentRepositoryMockRecorder struct {
+	mock *MockPaymentRepository
+}
+
+// NewMockPaymentRepository creates a new mock instance.
+func NewMockPaymentRepository(ctrl *gomock.Controller) *MockPaymentRepository {
+	mock := &MockPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// InvalidateAllVerdicts mocks base method.
+func (m *MockPaymentRepository) InvalidateAllVerdicts() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateAllVerdicts")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateAllVerdicts indicates an expected call of InvalidateAllVerdicts.
+func (mr *MockPaymentRepositoryMockRecorder) InvalidateAllVerdicts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateAllVerdicts", reflect.TypeOf((*MockPaymentRepository)(nil).InvalidateAllVerdicts))
+}
+
+// InvalidateUserVerdicts mocks base method.
+func (m *MockPaymentRepository) InvalidateUserVerdicts(userIDs []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateUserVerdicts", userIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateUserVerdicts indicates an expected call of InvalidateUserVerdicts.
+func (mr *MockPaymentRepositoryMockRecorder) InvalidateUserVerdicts(userIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUserVerdicts", reflect.TypeOf((*MockPaymentRepository)(nil).InvalidateUserVerdicts), userIDs)
+}
+
+// InvalidateVerdicts mocks base method.
+func (m *MockPaymentRepository) InvalidateVerdicts(cards []repository.BlockedCard) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateVerdicts", cards)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateVerdicts indicates an expected call of InvalidateVerdicts.
+func (mr *MockPaymentRepositoryMockRecorder) InvalidateVerdicts(cards interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateVerdicts", reflect.TypeOf((*MockPaymentRepository)(nil).InvalidateVerdicts), cards)
+}
+
+// ListUserPayments mocks base method.
+func (m *MockPaymentRepository) ListUserPayments(userID int64, since time.Time) ([]repository.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserPayments", userID, since)
+	ret0, _ := ret[0].([]repository.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserPayments indicates an expected call of ListUserPayments.
+func (mr *MockPaymentRepositoryMockRecorder) ListUserPayments(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserPayments", reflect.TypeOf((*MockPaymentRepository)(nil).ListUserPayments), userID, since)
+}
+
+// NotifyCardsReported mocks base method.
+func (m *MockPaymentRepository) NotifyCardsReported(userID int64, cardIDs []int64, reportedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyCardsReported", userID, cardIDs, reportedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyCardsReported indicates an expected call of NotifyCardsReported.
+func (mr *MockPaymentRepositoryMockRecorder) NotifyCardsReported(userID, cardIDs, reportedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyCardsReported", reflect.TypeOf((*MockPaymentRepository)(nil).NotifyCardsReported), userID, cardIDs, reportedAt)
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pep_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPEPRepository is a mock of PEPRepository interface.
+type MockPEPRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPEPRepositoryMockRecorder
+}
+
+// MockPEPRepositoryMockRecorder is the mock recorder for MockPEPRepository.
+type MockPEPRepositoryMockRecorder struct {
+	mock *MockPEPRepository
+}
+
+// NewMockPEPRepository creates a new mock instance.
+func NewMockPEPRepository(ctrl *gomock.Controller) *MockPEPRepository {
+	mock := &MockPEPRepository{ctrl: ctrl}
+	mock.recorder = &MockPEPRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPEPRepository) EXPECT() *MockPEPRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetActiveNames mocks base method.
+func (m *MockPEPRepository) GetActiveNames() ([]repository.PEPName, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveNames")
+	ret0, _ := ret[0].([]repository.PEPName)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveNames indicates an expected call of GetActiveNames.
+func (mr *MockPEPRepositoryMockRecorder) GetActiveNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveNames", reflect.TypeOf((*MockPEPRepository)(nil).GetActiveNames))
+}
+
+// GetRating mocks base method.
+func (m *MockPEPRepository) GetRating(userID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRating", userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRating indicates an expected call of GetRating.
+func (mr *MockPEPRepositoryMockRecorder) GetRating(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRating", reflect.TypeOf((*MockPEPRepository)(nil).GetRating), userID)
+}
+
+// GetRiskRating mocks base method.
+func (m *MockPEPRepository) GetRiskRating(userID int64) (repository.RiskRating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskRating", userID)
+	ret0, _ := ret[0].(repository.RiskRating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskRating indicates an expected call of GetRiskRating.
+func (mr *MockPEPRepositoryMockRecorder) GetRiskRating(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskRating", reflect.TypeOf((*MockPEPRepository)(nil).GetRiskRating), userID)
+}
+
+// ListRiskRatings mocks base method.
+func (m *MockPEPRepository) ListRiskRatings(rating string) ([]repository.RiskRating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRiskRatings", rating)
+	ret0, _ := ret[0].([]repository.RiskRating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRiskRatings indicates an expected call of ListRiskRatings.
+func (mr *MockPEPRepositoryMockRecorder) ListRiskRatings(rating interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRiskRatings", reflect.TypeOf((*MockPEPRepository)(nil).ListRiskRatings), rating)
+}
+
+// ReplaceEntries mocks base method.
+func (m *MockPEPRepository) ReplaceEntries(entries []repository.PEPEntry, loadedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceEntries", entries, loadedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceEntries indicates an expected call of ReplaceEntries.
+func (mr *MockPEPRepositoryMockRecorder) ReplaceEntries(entries, loadedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceEntries", reflect.TypeOf((*MockPEPRepository)(nil).ReplaceEntries), entries, loadedAt)
+}
+
+// SaveScreenedRatings mocks base method.
+func (m *MockPEPRepository) SaveScreenedRatings(ratings []repository.RiskRating) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveScreenedRatings", ratings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveScreenedRatings indicates an expected call of SaveScreenedRatings.
+func (mr *MockPEPRepositoryMockRecorder) SaveScreenedRatings(ratings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveScreenedRatings", reflect.TypeOf((*MockPEPRepository)(nil).SaveScreenedRatings), ratings)
+}
+
+// SetRating mocks base method.
+func (m *MockPEPRepository) SetRating(rating repository.RiskRating) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRating", rating)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRating indicates an expected call of SetRating.
+func (mr *MockPEPRepositoryMockRecorder) SetRating(rating interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRating", reflect.TypeOf((*MockPEPRepository)(nil).SetRating), rating)
+}
@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sanctions_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSanctionsRepository is a mock of SanctionsRepository interface.
+type MockSanctionsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSanctionsRepositoryMockRecorder
+}
+
+// MockSanctionsRepositoryMockRecorder is the mock recorder for MockSanctionsRepository.
+type MockSanctionsRepositoryMockRecorder struct {
+	mock *MockSanctionsRepository
+}
+
+// NewMockSanctionsRepository creates a new mock instance.
+func NewMockSanctionsRepository(ctrl *gomock.Controller) *MockSanctionsRepository {
+	mock := &MockSanctionsRepository{ctrl: ctrl}
+	mock.recorder = &MockSanctionsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSanctionsRepository) EXPECT() *MockSanctionsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateHits mocks base method.
+func (m *MockSanctionsRepository) CreateHits(hits []repository.ScreeningHit) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHits", hits)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHits indicates an expected call of CreateHits.
+func (mr *MockSanctionsRepositoryMockRecorder) CreateHits(hits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHits", reflect.TypeOf((*MockSanctionsRepository)(nil).CreateHits), hits)
+}
+
+// GetActiveNames mocks base method.
+func (m *MockSanctionsRepository) GetActiveNames() ([]repository.SanctionsName, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveNames")
+	ret0, _ := ret[0].([]repository.SanctionsName)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveNames indicates an expected call of GetActiveNames.
+func (mr *MockSanctionsRepositoryMockRecorder) GetActiveNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveNames", reflect.TypeOf((*MockSanctionsRepository)(nil).GetActiveNames))
+}
+
+// GetHit mocks base method.
+func (m *MockSanctionsRepository) GetHit(hitID int64) (repository.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHit", hitID)
+	ret0, _ := ret[0].(repository.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHit indicates an expected call of GetHit.
+func (mr *MockSanctionsRepositoryMockRecorder) GetHit(hitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHit", reflect.TypeOf((*MockSanctionsRepository)(nil).GetHit), hitID)
+}
+
+// HasConfirmedHit mocks base method.
+func (m *MockSanctionsRepository) HasConfirmedHit(userID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasConfirmedHit", userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasConfirmedHit indicates an expected call of HasConfirmedHit.
+func (mr *MockSanctionsRepositoryMockRecorder) HasConfirmedHit(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasConfirmedHit", reflect.TypeOf((*MockSanctionsRepository)(nil).HasConfirmedHit), userID)
+}
+
+// ListHits mocks base method.
+func (m *MockSanctionsRepository) ListHits(status string) ([]repository.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHits", status)
+	ret0, _ := ret[0].([]repository.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHits indicates an expected call of ListHits.
+func (mr *MockSanctionsRepositoryMockRecorder) ListHits(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHits", reflect.TypeOf((*MockSanctionsRepository)(nil).ListHits), status)
+}
+
+// ReplaceEntries mocks base method.
+func (m *MockSanctionsRepository) ReplaceEntries(listName string, entries []repository.SanctionsEntry, loadedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceEntries", listName, entries, loadedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceEntries indicates an expected call of ReplaceEntries.
+func (mr *MockSanctionsRepositoryMockRecorder) ReplaceEntries(listName, entries, loadedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceEntries", reflect.TypeOf((*MockSanctionsRepository)(nil).ReplaceEntries), listName, entries, loadedAt)
+}
+
+// ReviewHit mocks base method.
+func (m *MockSanctionsRepository) ReviewHit(hitID int64, status, reviewer, note string, reviewedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewHit", hitID, status, reviewer, note, reviewedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewHit indicates an expected call of ReviewHit.
+func (mr *MockSanctionsRepositoryMockRecorder) ReviewHit(hitID, status, reviewer, note, reviewedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewHit", reflect.TypeOf((*MockSanctionsRepository)(nil).ReviewHit), hitID, status, reviewer, note, reviewedAt)
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sar_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSARRepository is a mock of SARRepository interface.
+type MockSARRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSARRepositoryMockRecorder
+}
+
+// MockSARRepositoryMockRecorder is the mock recorder for MockSARRepository.
+type MockSARRepositoryMockRecorder struct {
+	mock *MockSARRepository
+}
+
+// NewMockSARRepository creates a new mock instance.
+func NewMockSARRepository(ctrl *gomock.Controller) *MockSARRepository {
+	mock := &MockSARRepository{ctrl: ctrl}
+	mock.recorder = &MockSARRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSARRepository) EXPECT() *MockSARRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateSAR mocks base method.
+func (m *MockSARRepository) CreateSAR(sar repository.SAR) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSAR", sar)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSAR indicates an expected call of CreateSAR.
+func (mr *MockSARRepositoryMockRecorder) CreateSAR(sar interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSAR", reflect.TypeOf((*MockSARRepository)(nil).CreateSAR), sar)
+}
+
+// GetSAR mocks base method.
+func (m *MockSARRepository) GetSAR(sarID int64) (repository.SAR, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSAR", sarID)
+	ret0, _ := ret[0].(repository.SAR)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSAR indicates an expected call of GetSAR.
+func (mr *MockSARRepositoryMockRecorder) GetSAR(sarID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSAR", reflect.TypeOf((*MockSARRepository)(nil).GetSAR), sarID)
+}
+
+// ListAccess mocks base method.
+func (m *MockSARRepository) ListAccess(sarID int64) ([]repository.SARAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccess", sarID)
+	ret0, _ := ret[0].([]repository.SARAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccess indicates an expected call of ListAccess.
+func (mr *MockSARRepositoryMockRecorder) ListAccess(sarID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccess", reflect.TypeOf((*MockSARRepository)(nil).ListAccess), sarID)
+}
+
+// ListSARs mocks base method.
+func (m *MockSARRepository) ListSARs(status string) ([]repository.SAR, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSARs", status)
+	ret0, _ := ret[0].([]repository.SAR)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSARs indicates an expected call of ListSARs.
+func (mr *MockSARRepositoryMockRecorder) ListSARs(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSARs", reflect.TypeOf((*MockSARRepository)(nil).ListSARs), status)
+}
+
+// LogAccess mocks base method.
+func (m *MockSARRepository) LogAccess(sarID int64, officer, action string, accessedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogAccess", sarID, officer, action, accessedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogAccess indicates an expected call of LogAccess.
+func (mr *MockSARRepositoryMockRecorder) LogAccess(sarID, officer, action, accessedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogAccess", reflect.TypeOf((*MockSARRepository)(nil).LogAccess), sarID, officer, action, accessedAt)
+}
+
+// UpdateDraft mocks base method.
+func (m *MockSARRepository) UpdateDraft(sar repository.SAR) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraft", sar)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockSARRepositoryMockRecorder) UpdateDraft(sar interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockSARRepository)(nil).UpdateDraft), sar)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockSARRepository) UpdateStatus(sarID int64, fromStatus, status, reference, note string, filedAt *time.Time, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", sarID, fromStatus, status, reference, note, filedAt, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockSARRepositoryMockRecorder) UpdateStatus(sarID, fromStatus, status, reference, note, filedAt, updatedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockSARRepository)(nil).UpdateStatus), sarID, fromStatus, status, reference, note, filedAt, updatedAt)
+}
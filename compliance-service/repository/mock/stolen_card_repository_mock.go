@@ -5,6 +5,7 @@
 package mock
 
 import (
+	repository "flarrocca/compliant-service/repository"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -33,6 +34,50 @@ func (m *MockStolenCardRepository) EXPECT() *MockStolenCardRepositoryMockRecorde
 	return m.recorder
 }
 
+// BlockCards mocks base method.
+func (m *MockStolenCardRepository) BlockCards(cardFingerprints []string, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockCards", cardFingerprints, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockCards indicates an expected call of BlockCards.
+func (mr *MockStolenCardRepositoryMockRecorder) BlockCards(cardFingerprints, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockCards", reflect.TypeOf((*MockStolenCardRepository)(nil).BlockCards), cardFingerprints, source)
+}
+
+// FindBlockedFingerprints mocks base method.
+func (m *MockStolenCardRepository) FindBlockedFingerprints(cardFingerprints []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBlockedFingerprints", cardFingerprints)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBlockedFingerprints indicates an expected call of FindBlockedFingerprints.
+func (mr *MockStolenCardRepositoryMockRecorder) FindBlockedFingerprints(cardFingerprints interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBlockedFingerprints", reflect.TypeOf((*MockStolenCardRepository)(nil).FindBlockedFingerprints), cardFingerprints)
+}
+
+// IsCardBlocked mocks base method.
+func (m *MockStolenCardRepository) IsCardBlocked(cardFingerprint string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCardBlocked", cardFingerprint)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsCardBlocked indicates an expected call of IsCardBlocked.
+func (mr *MockStolenCardRepositoryMockRecorder) IsCardBlocked(cardFingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCardBlocked", reflect.TypeOf((*MockStolenCardRepository)(nil).IsCardBlocked), cardFingerprint)
+}
+
 // IsCardReported mocks base method.
 func (m *MockStolenCardRepository) IsCardReported(userID, cardID int64) (bool, error) {
 	m.ctrl.T.Helper()
@@ -48,6 +93,50 @@ func (mr *MockStolenCardRepositoryMockRecorder) IsCardReported(userID, cardID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCardReported", reflect.TypeOf((*MockStolenCardRepository)(nil).IsCardReported), userID, cardID)
 }
 
+// ListBlockedFingerprints mocks base method.
+func (m *MockStolenCardRepository) ListBlockedFingerprints() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlockedFingerprints")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlockedFingerprints indicates an expected call of ListBlockedFingerprints.
+func (mr *MockStolenCardRepositoryMockRecorder) ListBlockedFingerprints() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlockedFingerprints", reflect.TypeOf((*MockStolenCardRepository)(nil).ListBlockedFingerprints))
+}
+
+// ListReportedCards mocks base method.
+func (m *MockStolenCardRepository) ListReportedCards() ([]repository.BlockedCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReportedCards")
+	ret0, _ := ret[0].([]repository.BlockedCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReportedCards indicates an expected call of ListReportedCards.
+func (mr *MockStolenCardRepositoryMockRecorder) ListReportedCards() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReportedCards", reflect.TypeOf((*MockStolenCardRepository)(nil).ListReportedCards))
+}
+
+// ReinstateCard mocks base method.
+func (m *MockStolenCardRepository) ReinstateCard(userID, cardID int64, cardFingerprint string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReinstateCard", userID, cardID, cardFingerprint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReinstateCard indicates an expected call of ReinstateCard.
+func (mr *MockStolenCardRepositoryMockRecorder) ReinstateCard(userID, cardID, cardFingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReinstateCard", reflect.TypeOf((*MockStolenCardRepository)(nil).ReinstateCard), userID, cardID, cardFingerprint)
+}
+
 // ReportStolenCards mocks base method.
 func (m *MockStolenCardRepository) ReportStolenCards(userID int64, cardIDs []int64) error {
 	m.ctrl.T.Helper()
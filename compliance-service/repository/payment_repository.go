@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"flarrocca/auth"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const paymentRequestTimeout = 5 * time.Second
+
+// Payment is a payment of a user recorded by payment-service, with the merchant and the country of the client IP when
+// they were sent.
+type Payment struct {
+	ID             string    `json:"id"`
+	CardID         int64     `json:"card_id"`
+	Amount         float64   `json:"amount"`
+	Status         string    `json:"status"`
+	SuspectedFraud bool      `json:"suspected_fraud"`
+	MerchantID     string    `json:"merchant_id,omitempty"`
+	IPCountry      string    `json:"ip_country,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source payment_repository.go -destination mock/payment_repository_mock.go -package mock
+type PaymentRepository interface {
+	NotifyCardsReported(userID int64, cardIDs []int64, reportedAt time.Time) error
+	InvalidateVerdicts(cards []BlockedCard) error
+	InvalidateUserVerdicts(userIDs []int64) error
+	InvalidateAllVerdicts() error
+	ListUserPayments(userID int64, since time.Time) ([]Payment, error)
+}
+
+type paymentRepository struct {
+	paymentBaseURL string
+	client         *http.Client
+	tokenSource    auth.TokenSource
+}
+
+// NewPaymentRepository calls payment-service with a token of the source, or without a token when the source is nil.
+func NewPaymentRepository(tokenSource auth.TokenSource) PaymentRepository {
+	paymentBaseURL := os.Getenv("PAYMENT_SERVICE_URL")
+	if paymentBaseURL == "" {
+		paymentBaseURL = "http://localhost:8081"
+	}
+	return &paymentRepository{
+		paymentBaseURL: paymentBaseURL,
+		client:         &http.Client{Timeout: paymentRequestTimeout},
+		tokenSource:    tokenSource,
+	}
+}
+
+// NotifyCardsReported lets payment-service flag the payments made with the cards before they were reported.
+func (r *paymentRepository) NotifyCardsReported(userID int64, cardIDs []int64, reportedAt time.Time) error {
+	return r.post("/v1/cards_reported", map[string]any{
+		"user_id":     userID,
+		"card_ids":    cardIDs,
+		"reported_at": reportedAt,
+	})
+}
+
+// InvalidateVerdicts drops the verdicts payment-service cached for the cards, so a card blocked or reinstated is
+// checked again on its next payment.
+func (r *paymentRepository) InvalidateVerdicts(cards []BlockedCard) error {
+	if len(cards) == 0 {
+		return nil
+	}
+	return r.invalidate(map[string]any{"cards": cards})
+}
+
+// InvalidateUserVerdicts drops the verdicts payment-service cached for every card of the users, so a change of their
+// sanctions, KYC or risk status applies to their next payment.
+func (r *paymentRepository) InvalidateUserVerdicts(userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return r.invalidate(map[string]any{"user_ids": userIDs})
+}
+
+// InvalidateAllVerdicts drops every verdict payment-service cached, for changes that may affect any card.
+func (r *paymentRepository) InvalidateAllVerdicts() error {
+	return r.invalidate(map[string]any{"all": true})
+}
+
+// ListUserPayments returns the payments of the user since the provided time, newest first, as many as payment-service
+// returns.
+func (r *paymentRepository) ListUserPayments(userID int64, since time.Time) ([]Payment, error) {
+	var result struct {
+		Payments []struct {
+			Payment
+			Context *struct {
+				MerchantID string `json:"merchant_id"`
+				IPCountry  string `json:"ip_country"`
+			} `json:"context"`
+		} `json:"payments"`
+	}
+	path := fmt.Sprintf("/v1/users/%d/payments?since=%s", userID, url.QueryEscape(since.UTC().Format(time.RFC3339)))
+	if err := r.do(http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	payments := make([]Payment, 0, len(result.Payments))
+	for _, payment := range result.Payments {
+		if payment.Context != nil {
+			payment.MerchantID = payment.Context.MerchantID
+			payment.IPCountry = payment.Context.IPCountry
+		}
+		payments = append(payments, payment.Payment)
+	}
+	return payments, nil
+}
+
+// invalidate makes a single attempt, the changes that must never be served from a stale verdict are retried by the
+// consumers of their outbox events.
+func (r *paymentRepository) invalidate(payload map[string]any) error {
+	return r.post("/v1/compliance_cache/invalidations", payload)
+}
+
+func (r *paymentRepository) post(path string, payload any) error {
+	return r.do(http.MethodPost, path, payload, nil)
+}
+
+// do sends the payload, if not nil, as JSON and decodes the response into result, if not nil.
+func (r *paymentRepository) do(method string, path string, payload any, result any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, r.paymentBaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.tokenSource != nil {
+		token, err := r.tokenSource.Token()
+		if err != nil {
+			return fmt.Errorf("error signing the token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("payment service returned status code: %d", resp.StatusCode)
+	}
+
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"flarrocca/auth"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifyCardsReported(t *testing.T) {
+	reportedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		mockServer func() *httptest.Server
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Payment service notified",
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.Equal(t, "/v1/cards_reported", r.URL.Path)
+
+					var body struct {
+						UserID     int64     `json:"user_id"`
+						CardIDs    []int64   `json:"card_ids"`
+						ReportedAt time.Time `json:"reported_at"`
+					}
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					assert.Equal(t, int64(1), body.UserID)
+					assert.Equal(t, []int64{1, 2}, body.CardIDs)
+					assert.True(t, reportedAt.Equal(body.ReportedAt))
+
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Payment service returned non-2xx status",
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+				}))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "payment service returned status code: 500")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.mockServer()
+			defer server.Close()
+
+			paymentRepository := &paymentRepository{paymentBaseURL: server.URL, client: &http.Client{}}
+			err := paymentRepository.NotifyCardsReported(1, []int64{1, 2}, reportedAt)
+
+			tt.assertFunc(t, err)
+		})
+	}
+}
+
+func TestInvalidateVerdicts(t *testing.T) {
+	tests := []struct {
+		name          string
+		invalidate    func(paymentRepository PaymentRepository) error
+		failures      int32
+		expectedBody  string
+		expectedCalls int32
+		assertFunc    func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Cards invalidated",
+			invalidate: func(paymentRepository PaymentRepository) error {
+				return paymentRepository.InvalidateVerdicts([]BlockedCard{{UserID: 3, CardID: 7}})
+			},
+			expectedBody:  `{"cards": [{"user_id": 3, "card_id": 7}]}`,
+			expectedCalls: 1,
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Success - Users invalidated",
+			invalidate: func(paymentRepository PaymentRepository) error {
+				return paymentRepository.InvalidateUserVerdicts([]int64{3})
+			},
+			expectedBody:  `{"user_ids": [3]}`,
+			expectedCalls: 1,
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Success - Every verdict invalidated",
+			invalidate: func(paymentRepository PaymentRepository) error {
+				return paymentRepository.InvalidateAllVerdicts()
+			},
+			expectedBody:  `{"all": true}`,
+			expectedCalls: 1,
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Success - Nothing to invalidate",
+			invalidate: func(paymentRepository PaymentRepository) error {
+				return paymentRepository.InvalidateVerdicts(nil)
+			},
+			expectedCalls: 0,
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Payment service unavailable, not retried",
+			invalidate: func(paymentRepository PaymentRepository) error {
+				return paymentRepository.InvalidateAllVerdicts()
+			},
+			failures:      1,
+			expectedBody:  `{"all": true}`,
+			expectedCalls: 1,
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "payment service returned status code: 503")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/v1/compliance_cache/invalidations", r.URL.Path)
+				body, _ := io.ReadAll(r.Body)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+
+				if calls.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			paymentRepository := &paymentRepository{paymentBaseURL: server.URL, client: &http.Client{}}
+			err := tt.invalidate(paymentRepository)
+
+			tt.assertFunc(t, err)
+			assert.Equal(t, tt.expectedCalls, calls.Load())
+		})
+	}
+}
+
+func TestListUserPayments(t *testing.T) {
+	since := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		mockServer func() *httptest.Server
+		assertFunc func(t *testing.T, payments []Payment, err error)
+	}{
+		{
+			name: "Success - Payments returned",
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, http.MethodGet, r.Method)
+					assert.Equal(t, "/v1/users/1/payments", r.URL.Path)
+					assert.Equal(t, "2025-03-01T12:00:00Z", r.URL.Query().Get("since"))
+
+					w.Write([]byte(`{"payments": [
+						{"id": "txn_2", "user_id": 1, "card_id": 2, "amount": 10, "status": "declined", "suspected_fraud": false,
+							"created_at": "2025-03-02T09:00:00Z", "context": {"merchant_id": "grocer-001", "ip_country": "GB", "billing_address": {}}},
+						{"id": "txn_1", "user_id": 1, "card_id": 3, "amount": 20.5, "status": "approved", "suspected_fraud": true,
+							"created_at": "2025-03-01T13:00:00Z"}]}`))
+				}))
+			},
+			assertFunc: func(t *testing.T, payments []Payment, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []Payment{
+					{ID: "txn_2", CardID: 2, Amount: 10, Status: "declined", MerchantID: "grocer-001", IPCountry: "GB",
+						CreatedAt: time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)},
+					{ID: "txn_1", CardID: 3, Amount: 20.5, Status: "approved", SuspectedFraud: true, CreatedAt: time.Date(2025, 3, 1, 13, 0, 0, 0, time.UTC)},
+				}, payments)
+			},
+		},
+		{
+			name: "Failure - Payment service returned non-2xx status",
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			assertFunc: func(t *testing.T, payments []Payment, err error) {
+				assert.Nil(t, payments)
+				assert.EqualError(t, err, "payment service returned status code: 403")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.mockServer()
+			defer server.Close()
+
+			paymentRepository := &paymentRepository{paymentBaseURL: server.URL, client: &http.Client{}}
+			payments, err := paymentRepository.ListUserPayments(1, since)
+
+			tt.assertFunc(t, payments, err)
+		})
+	}
+}
+
+type fakeTokenSource struct {
+	token string
+	err   error
+}
+
+func (f fakeTokenSource) Token() (string, error) {
+	return f.token, f.err
+}
+
+func TestPaymentRepositoryToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokenSource auth.TokenSource
+		assertFunc  func(t *testing.T, authorization string, err error)
+	}{
+		{
+			name:        "Success - Bearer token sent",
+			tokenSource: fakeTokenSource{token: "signed-token"},
+			assertFunc: func(t *testing.T, authorization string, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "Bearer signed-token", authorization)
+			},
+		},
+		{
+			name: "Success - No token without a source",
+			assertFunc: func(t *testing.T, authorization string, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, authorization)
+			},
+		},
+		{
+			name:        "Failure - Token not signed",
+			tokenSource: fakeTokenSource{err: errors.New("no key")},
+			assertFunc: func(t *testing.T, authorization string, err error) {
+				assert.EqualError(t, err, "error signing the token: no key")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			paymentRepository := &paymentRepository{paymentBaseURL: server.URL, client: &http.Client{}, tokenSource: tt.tokenSource}
+			err := paymentRepository.NotifyCardsReported(1, []int64{1, 2}, time.Now())
+
+			tt.assertFunc(t, authorization, err)
+		})
+	}
+}
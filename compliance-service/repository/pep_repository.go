@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+)
+
+// PEPEntry is a person on the PEP list: a politically exposed person, a relative or close associate of one, or the
+// subject of adverse media, depending on the category.
+type PEPEntry struct {
+	ID         int64    `json:"id"`
+	ExternalID string   `json:"external_id"`
+	Name       string   `json:"name"`
+	Category   string   `json:"category"`
+	Position   string   `json:"position,omitempty"`
+	Country    string   `json:"country,omitempty"`
+	Aliases    []string `json:"aliases,omitempty"`
+}
+
+// PEPName is one of the names, primary or alias, a PEP list entry is known by.
+type PEPName struct {
+	EntryID   int64
+	EntryName string
+	Category  string
+	Name      string
+}
+
+type PEPMatch struct {
+	EntryID      int64     `json:"entry_id"`
+	EntryName    string    `json:"entry_name,omitempty"`
+	Category     string    `json:"category"`
+	Position     string    `json:"position,omitempty"`
+	Country      string    `json:"country,omitempty"`
+	ScreenedName string    `json:"screened_name"`
+	MatchedName  string    `json:"matched_name"`
+	Score        float64   `json:"score"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RiskRating is the due diligence rating of a user, computed from the PEP matches or set by a reviewer.
+type RiskRating struct {
+	UserID   int64      `json:"user_id"`
+	Rating   string     `json:"rating"`
+	Source   string     `json:"source"`
+	Reason   string     `json:"reason,omitempty"`
+	Reviewer string     `json:"reviewer,omitempty"`
+	RatedAt  time.Time  `json:"rated_at"`
+	Matches  []PEPMatch `json:"matches,omitempty"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source pep_repository.go -destination mock/pep_repository_mock.go -package mock
+type PEPRepository interface {
+	ReplaceEntries(entries []PEPEntry, loadedAt time.Time) error
+	GetActiveNames() ([]PEPName, error)
+	SaveScreenedRatings(ratings []RiskRating) error
+	SetRating(rating RiskRating) error
+	GetRating(userID int64) (string, error)
+	GetRiskRating(userID int64) (RiskRating, error)
+	ListRiskRatings(rating string) ([]RiskRating, error)
+}
+
+type pepRepository struct {
+	db *sql.DB
+}
+
+func NewPEPRepository(db *sql.DB) PEPRepository {
+	return &pepRepository{db: db}
+}
+
+// ReplaceEntries loads a new version of the list in one transaction. Entries keep their ID across loads and entries
+// no longer on the list are deactivated instead of deleted.
+func (r *pepRepository) ReplaceEntries(entries []PEPEntry, loadedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := replacePEPEntries(tx, entries, loadedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replacePEPEntries(tx *sql.Tx, entries []PEPEntry, loadedAt time.Time) error {
+	if _, err := tx.Exec("UPDATE pep_entries SET active = 0"); err != nil {
+		return err
+	}
+
+	upsertStmt, err := tx.Prepare(`INSERT INTO pep_entries (external_id, name, category, position, country, active, loaded_at) VALUES (?, ?, ?, ?, ?, 1, ?)
+		ON CONFLICT (external_id) DO UPDATE SET name = excluded.name, category = excluded.category, position = excluded.position, country = excluded.country,
+		active = 1, loaded_at = excluded.loaded_at`)
+	if err != nil {
+		return err
+	}
+	defer upsertStmt.Close()
+
+	idStmt, err := tx.Prepare("SELECT id FROM pep_entries WHERE external_id = ?")
+	if err != nil {
+		return err
+	}
+	defer idStmt.Close()
+
+	deleteNamesStmt, err := tx.Prepare("DELETE FROM pep_names WHERE entry_id = ?")
+	if err != nil {
+		return err
+	}
+	defer deleteNamesStmt.Close()
+
+	nameStmt, err := tx.Prepare("INSERT INTO pep_names (entry_id, name) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer nameStmt.Close()
+
+	for _, entry := range entries {
+		if _, err := upsertStmt.Exec(entry.ExternalID, entry.Name, entry.Category, entry.Position, entry.Country, loadedAt); err != nil {
+			return err
+		}
+
+		var entryID int64
+		if err := idStmt.QueryRow(entry.ExternalID).Scan(&entryID); err != nil {
+			return err
+		}
+
+		if _, err := deleteNamesStmt.Exec(entryID); err != nil {
+			return err
+		}
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if _, err := nameStmt.Exec(entryID, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *pepRepository) GetActiveNames() ([]PEPName, error) {
+	rows, err := r.db.Query("SELECT e.id, e.name, e.category, n.name FROM pep_names n JOIN pep_entries e ON e.id = n.entry_id WHERE e.active = 1 ORDER BY e.id, n.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []PEPName
+	for rows.Next() {
+		var name PEPName
+		if err := rows.Scan(&name.EntryID, &name.EntryName, &name.Category, &name.Name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// SaveScreenedRatings replaces the matches of each screened user and stores the rating computed from them. A rating
+// set by a reviewer is kept, unless the user now matches an entry they did not match before.
+func (r *pepRepository) SaveScreenedRatings(ratings []RiskRating) error {
+	if len(ratings) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := saveScreenedRatings(tx, ratings); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func saveScreenedRatings(tx *sql.Tx, ratings []RiskRating) error {
+	matchedStmt, err := tx.Prepare("SELECT entry_id FROM pep_matches WHERE user_id = ?")
+	if err != nil {
+		return err
+	}
+	defer matchedStmt.Close()
+
+	deleteMatchesStmt, err := tx.Prepare("DELETE FROM pep_matches WHERE user_id = ?")
+	if err != nil {
+		return err
+	}
+	defer deleteMatchesStmt.Close()
+
+	matchStmt, err := tx.Prepare("INSERT INTO pep_matches (user_id, entry_id, screened_name, matched_name, score, created_at) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer matchStmt.Close()
+
+	ratingStmt, err := tx.Prepare(`INSERT INTO user_risk_ratings (user_id, rating, source, reason, reviewer, rated_at) VALUES (?, ?, ?, ?, '', ?)
+		ON CONFLICT (user_id) DO UPDATE SET rating = excluded.rating, source = excluded.source, reason = excluded.reason, reviewer = '', rated_at = excluded.rated_at
+		WHERE user_risk_ratings.source != 'manual' OR ?`)
+	if err != nil {
+		return err
+	}
+	defer ratingStmt.Close()
+
+	for _, rating := range ratings {
+		previous, err := matchedEntryIDs(matchedStmt, rating.UserID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := deleteMatchesStmt.Exec(rating.UserID); err != nil {
+			return err
+		}
+
+		newMatch := false
+		for _, match := range rating.Matches {
+			if _, err := matchStmt.Exec(rating.UserID, match.EntryID, match.ScreenedName, match.MatchedName, match.Score, match.CreatedAt); err != nil {
+				return err
+			}
+			newMatch = newMatch || !slices.Contains(previous, match.EntryID)
+		}
+
+		if _, err := ratingStmt.Exec(rating.UserID, rating.Rating, rating.Source, rating.Reason, rating.RatedAt, newMatch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func matchedEntryIDs(stmt *sql.Stmt, userID int64) ([]int64, error) {
+	rows, err := stmt.Query(userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entryIDs []int64
+	for rows.Next() {
+		var entryID int64
+		if err := rows.Scan(&entryID); err != nil {
+			return nil, err
+		}
+		entryIDs = append(entryIDs, entryID)
+	}
+
+	return entryIDs, nil
+}
+
+// SetRating stores a rating set by a reviewer, whatever the current one is.
+func (r *pepRepository) SetRating(rating RiskRating) error {
+	_, err := r.db.Exec(`INSERT INTO user_risk_ratings (user_id, rating, source, reason, reviewer, rated_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET rating = excluded.rating, source = excluded.source, reason = excluded.reason, reviewer = excluded.reviewer, rated_at = excluded.rated_at`,
+		rating.UserID, rating.Rating, rating.Source, rating.Reason, rating.Reviewer, rating.RatedAt)
+	return err
+}
+
+func (r *pepRepository) GetRating(userID int64) (string, error) {
+	var rating string
+	err := r.db.QueryRow("SELECT rating FROM user_risk_ratings WHERE user_id = ?", userID).Scan(&rating)
+	return rating, err
+}
+
+func (r *pepRepository) GetRiskRating(userID int64) (RiskRating, error) {
+	rating, err := scanRiskRating(r.db.QueryRow(riskRatingQuery+" WHERE user_id = ?", userID))
+	if err != nil {
+		return RiskRating{}, err
+	}
+
+	rows, err := r.db.Query(`SELECT m.entry_id, e.name, e.category, e.position, e.country, m.screened_name, m.matched_name, m.score, m.created_at
+		FROM pep_matches m JOIN pep_entries e ON e.id = m.entry_id WHERE m.user_id = ? ORDER BY m.score DESC, m.id`, userID)
+	if err != nil {
+		return RiskRating{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var match PEPMatch
+		if err := rows.Scan(&match.EntryID, &match.EntryName, &match.Category, &match.Position, &match.Country, &match.ScreenedName, &match.MatchedName,
+			&match.Score, &match.CreatedAt); err != nil {
+			return RiskRating{}, err
+		}
+		rating.Matches = append(rating.Matches, match)
+	}
+
+	return rating, nil
+}
+
+func (r *pepRepository) ListRiskRatings(rating string) ([]RiskRating, error) {
+	query := riskRatingQuery + " WHERE 1 = 1"
+	var args []any
+	if rating != "" {
+		query += " AND rating = ?"
+		args = append(args, rating)
+	}
+
+	rows, err := r.db.Query(query+" ORDER BY user_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ratings []RiskRating
+	for rows.Next() {
+		riskRating, err := scanRiskRating(rows)
+		if err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, riskRating)
+	}
+
+	return ratings, nil
+}
+
+const riskRatingQuery = "SELECT user_id, rating, source, reason, reviewer, rated_at FROM user_risk_ratings"
+
+func scanRiskRating(row rowScanner) (RiskRating, error) {
+	var rating RiskRating
+	err := row.Scan(&rating.UserID, &rating.Rating, &rating.Source, &rating.Reason, &rating.Reviewer, &rating.RatedAt)
+	return rating, err
+}
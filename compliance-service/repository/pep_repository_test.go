@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var riskRatingRows = []string{"user_id", "rating", "source", "reason", "reviewer", "rated_at"}
+
+func TestReplacePEPEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []PEPEntry{{ExternalID: "PEP-0003", Name: "Dmitri Volkov", Category: "pep", Position: "Deputy Minister", Country: "XX", Aliases: []string{"Дмитрий Волков"}}}
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`UPDATE pep_entries SET active = 0`).WillReturnResult(sqlmock.NewResult(0, 4))
+	upsert := dbMock.ExpectPrepare(`INSERT INTO pep_entries (.+) ON CONFLICT \(external_id\) DO UPDATE SET`)
+	id := dbMock.ExpectPrepare(`SELECT id FROM pep_entries WHERE external_id = \?`)
+	deleteNames := dbMock.ExpectPrepare(`DELETE FROM pep_names WHERE entry_id = \?`)
+	insertName := dbMock.ExpectPrepare(`INSERT INTO pep_names \(entry_id, name\) VALUES \(\?, \?\)`)
+	upsert.ExpectExec().WithArgs("PEP-0003", "Dmitri Volkov", "pep", "Deputy Minister", "XX", now).WillReturnResult(sqlmock.NewResult(0, 1))
+	id.ExpectQuery().WithArgs("PEP-0003").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	deleteNames.ExpectExec().WithArgs(int64(3)).WillReturnResult(sqlmock.NewResult(0, 2))
+	insertName.ExpectExec().WithArgs(int64(3), "Dmitri Volkov").WillReturnResult(sqlmock.NewResult(1, 1))
+	insertName.ExpectExec().WithArgs(int64(3), "Дмитрий Волков").WillReturnResult(sqlmock.NewResult(2, 1))
+	dbMock.ExpectCommit()
+
+	err := NewPEPRepository(db).ReplaceEntries(entries, now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestSaveScreenedRatings(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	ratings := []RiskRating{{UserID: 2, Rating: "high", Source: "screening", Reason: "matches politically exposed person Elena Marchetti", RatedAt: now,
+		Matches: []PEPMatch{{EntryID: 1, ScreenedName: "Elena Marchetti", MatchedName: "Elena Marchetti", Score: 1, CreatedAt: now}}}}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Manual rating kept while the user matches the same entries",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				matched := dbMock.ExpectPrepare(`SELECT entry_id FROM pep_matches WHERE user_id = \?`)
+				deleteMatches := dbMock.ExpectPrepare(`DELETE FROM pep_matches WHERE user_id = \?`)
+				insertMatch := dbMock.ExpectPrepare(`INSERT INTO pep_matches`)
+				upsertRating := dbMock.ExpectPrepare(`INSERT INTO user_risk_ratings (.+) WHERE user_risk_ratings.source != 'manual' OR \?`)
+				matched.ExpectQuery().WithArgs(int64(2)).WillReturnRows(sqlmock.NewRows([]string{"entry_id"}).AddRow(1))
+				deleteMatches.ExpectExec().WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+				insertMatch.ExpectExec().WithArgs(int64(2), int64(1), "Elena Marchetti", "Elena Marchetti", float64(1), now).WillReturnResult(sqlmock.NewResult(5, 1))
+				upsertRating.ExpectExec().WithArgs(int64(2), "high", "screening", "matches politically exposed person Elena Marchetti", now, false).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Success - New match overrides the manual rating",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				matched := dbMock.ExpectPrepare(`SELECT entry_id FROM pep_matches WHERE user_id = \?`)
+				deleteMatches := dbMock.ExpectPrepare(`DELETE FROM pep_matches WHERE user_id = \?`)
+				insertMatch := dbMock.ExpectPrepare(`INSERT INTO pep_matches`)
+				upsertRating := dbMock.ExpectPrepare(`INSERT INTO user_risk_ratings`)
+				matched.ExpectQuery().WithArgs(int64(2)).WillReturnRows(sqlmock.NewRows([]string{"entry_id"}))
+				deleteMatches.ExpectExec().WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+				insertMatch.ExpectExec().WithArgs(int64(2), int64(1), "Elena Marchetti", "Elena Marchetti", float64(1), now).WillReturnResult(sqlmock.NewResult(5, 1))
+				upsertRating.ExpectExec().WithArgs(int64(2), "high", "screening", "matches politically exposed person Elena Marchetti", now, true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Error rolls back every rating",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectPrepare(`SELECT entry_id FROM pep_matches`).WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewPEPRepository(db).SaveScreenedRatings(ratings)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetRiskRating(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		userID     int64
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, rating RiskRating, err error)
+	}{
+		{
+			name:   "Success - Rating with its matches",
+			userID: 2,
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT user_id, rating, source, reason, reviewer, rated_at FROM user_risk_ratings WHERE user_id = \?`).WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows(riskRatingRows).AddRow(2, "high", "screening", "matches politically exposed person Elena Marchetti", "", now))
+				dbMock.ExpectQuery(`SELECT (.+) FROM pep_matches m JOIN pep_entries e ON e.id = m.entry_id WHERE m.user_id = \?`).WithArgs(int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"entry_id", "name", "category", "position", "country", "screened_name", "matched_name", "score", "created_at"}).
+						AddRow(1, "Elena Marchetti", "pep", "Minister of Finance", "IT", "Elena Marchetti", "Elena Marchetti", 1, now))
+			},
+			assertFunc: func(t *testing.T, rating RiskRating, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, RiskRating{UserID: 2, Rating: "high", Source: "screening", Reason: "matches politically exposed person Elena Marchetti", RatedAt: now,
+					Matches: []PEPMatch{{EntryID: 1, EntryName: "Elena Marchetti", Category: "pep", Position: "Minister of Finance", Country: "IT",
+						ScreenedName: "Elena Marchetti", MatchedName: "Elena Marchetti", Score: 1, CreatedAt: now}}}, rating)
+			},
+		},
+		{
+			name:   "Failure - User never rated",
+			userID: 9,
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`FROM user_risk_ratings WHERE user_id = \?`).WithArgs(int64(9)).WillReturnError(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, rating RiskRating, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			rating, err := NewPEPRepository(db).GetRiskRating(tt.userID)
+			tt.assertFunc(t, rating, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListRiskRatings(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`FROM user_risk_ratings WHERE 1 = 1 AND rating = \? ORDER BY user_id`).WithArgs("high").
+		WillReturnRows(sqlmock.NewRows(riskRatingRows).AddRow(2, "high", "manual", "confirmed by enhanced due diligence", "alice", now))
+
+	ratings, err := NewPEPRepository(db).ListRiskRatings("high")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RiskRating{{UserID: 2, Rating: "high", Source: "manual", Reason: "confirmed by enhanced due diligence", Reviewer: "alice", RatedAt: now}}, ratings)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
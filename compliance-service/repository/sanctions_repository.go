@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type SanctionsEntry struct {
+	ID         int64    `json:"id"`
+	ListName   string   `json:"list_name"`
+	ExternalID string   `json:"external_id"`
+	Name       string   `json:"name"`
+	EntryType  string   `json:"entry_type"`
+	Programs   string   `json:"programs,omitempty"`
+	Aliases    []string `json:"aliases,omitempty"`
+}
+
+// SanctionsName is one of the names, primary or alias, a sanctions entry is known by.
+type SanctionsName struct {
+	EntryID   int64
+	EntryName string
+	Name      string
+}
+
+type ScreeningHit struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	EntryID      int64      `json:"entry_id"`
+	EntryName    string     `json:"entry_name,omitempty"`
+	Programs     string     `json:"programs,omitempty"`
+	ScreenedName string     `json:"screened_name"`
+	MatchedName  string     `json:"matched_name"`
+	Score        float64    `json:"score"`
+	Status       string     `json:"status"`
+	Reviewer     string     `json:"reviewer,omitempty"`
+	ReviewNote   string     `json:"review_note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source sanctions_repository.go -destination mock/sanctions_repository_mock.go -package mock
+type SanctionsRepository interface {
+	ReplaceEntries(listName string, entries []SanctionsEntry, loadedAt time.Time) error
+	GetActiveNames() ([]SanctionsName, error)
+	CreateHits(hits []ScreeningHit) (int, error)
+	ListHits(status string) ([]ScreeningHit, error)
+	GetHit(hitID int64) (ScreeningHit, error)
+	ReviewHit(hitID int64, status string, reviewer string, note string, reviewedAt time.Time) error
+	HasConfirmedHit(userID int64) (bool, error)
+}
+
+type sanctionsRepository struct {
+	db *sql.DB
+}
+
+func NewSanctionsRepository(db *sql.DB) SanctionsRepository {
+	return &sanctionsRepository{db: db}
+}
+
+// ReplaceEntries loads a new version of a list in one transaction. Entries keep their ID across loads, so the hits
+// already reviewed still apply, and entries no longer on the list are deactivated instead of deleted.
+func (r *sanctionsRepository) ReplaceEntries(listName string, entries []SanctionsEntry, loadedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := replaceSanctionsEntries(tx, listName, entries, loadedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceSanctionsEntries(tx *sql.Tx, listName string, entries []SanctionsEntry, loadedAt time.Time) error {
+	if _, err := tx.Exec("UPDATE sanctions_entries SET active = 0 WHERE list_name = ?", listName); err != nil {
+		return err
+	}
+
+	upsertStmt, err := tx.Prepare(`INSERT INTO sanctions_entries (list_name, external_id, name, entry_type, programs, active, loaded_at) VALUES (?, ?, ?, ?, ?, 1, ?)
+		ON CONFLICT (list_name, external_id) DO UPDATE SET name = excluded.name, entry_type = excluded.entry_type, programs = excluded.programs, active = 1, loaded_at = excluded.loaded_at`)
+	if err != nil {
+		return err
+	}
+	defer upsertStmt.Close()
+
+	idStmt, err := tx.Prepare("SELECT id FROM sanctions_entries WHERE list_name = ? AND external_id = ?")
+	if err != nil {
+		return err
+	}
+	defer idStmt.Close()
+
+	deleteNamesStmt, err := tx.Prepare("DELETE FROM sanctions_names WHERE entry_id = ?")
+	if err != nil {
+		return err
+	}
+	defer deleteNamesStmt.Close()
+
+	nameStmt, err := tx.Prepare("INSERT INTO sanctions_names (entry_id, name) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer nameStmt.Close()
+
+	for _, entry := range entries {
+		if _, err := upsertStmt.Exec(listName, entry.ExternalID, entry.Name, entry.EntryType, entry.Programs, loadedAt); err != nil {
+			return err
+		}
+
+		var entryID int64
+		if err := idStmt.QueryRow(listName, entry.ExternalID).Scan(&entryID); err != nil {
+			return err
+		}
+
+		if _, err := deleteNamesStmt.Exec(entryID); err != nil {
+			return err
+		}
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if _, err := nameStmt.Exec(entryID, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *sanctionsRepository) GetActiveNames() ([]SanctionsName, error) {
+	rows, err := r.db.Query("SELECT e.id, e.name, n.name FROM sanctions_names n JOIN sanctions_entries e ON e.id = n.entry_id WHERE e.active = 1 ORDER BY e.id, n.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []SanctionsName
+	for rows.Next() {
+		var name SanctionsName
+		if err := rows.Scan(&name.EntryID, &name.EntryName, &name.Name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// CreateHits adds the hits to the review queue and returns how many are new. A user already matched with an
+// entry is not queued again, whatever the outcome of the previous review.
+func (r *sanctionsRepository) CreateHits(hits []ScreeningHit) (int, error) {
+	if len(hits) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare("INSERT OR IGNORE INTO screening_hits (user_id, entry_id, screened_name, matched_name, score, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	created := 0
+	for _, hit := range hits {
+		result, err := stmt.Exec(hit.UserID, hit.EntryID, hit.ScreenedName, hit.MatchedName, hit.Score, hit.Status, hit.CreatedAt)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		created += int(affected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+func (r *sanctionsRepository) ListHits(status string) ([]ScreeningHit, error) {
+	query := screeningHitQuery + " WHERE 1 = 1"
+	var args []any
+	if status != "" {
+		query += " AND h.status = ?"
+		args = append(args, status)
+	}
+
+	rows, err := r.db.Query(query+" ORDER BY h.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []ScreeningHit
+	for rows.Next() {
+		hit, err := scanScreeningHit(rows)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
+
+func (r *sanctionsRepository) GetHit(hitID int64) (ScreeningHit, error) {
+	return scanScreeningHit(r.db.QueryRow(screeningHitQuery+" WHERE h.id = ?", hitID))
+}
+
+// ReviewHit records the decision on a hit. A confirmed hit locks the user, which is written to the outbox.
+func (r *sanctionsRepository) ReviewHit(hitID int64, status string, reviewer string, note string, reviewedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("UPDATE screening_hits SET status = ?, reviewer = ?, review_note = ?, reviewed_at = ? WHERE id = ?", status, reviewer, note, reviewedAt, hitID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := expectAffected(result); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if status == "confirmed" {
+		var userID int64
+		if err := tx.QueryRow("SELECT user_id FROM screening_hits WHERE id = ?", hitID).Scan(&userID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := insertEvent(tx, EventUserLocked, UserLockedEvent{UserID: userID, Reason: "sanctions_match"}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// HasConfirmedHit tells whether the user was confirmed as matching an entry still on its list.
+func (r *sanctionsRepository) HasConfirmedHit(userID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM screening_hits h JOIN sanctions_entries e ON e.id = h.entry_id
+		WHERE h.user_id = ? AND h.status = 'confirmed' AND e.active = 1)`, userID).Scan(&exists)
+	return exists, err
+}
+
+const screeningHitQuery = `SELECT h.id, h.user_id, h.entry_id, e.name, e.programs, h.screened_name, h.matched_name, h.score, h.status, h.reviewer, h.review_note, h.created_at, h.reviewed_at
+	FROM screening_hits h JOIN sanctions_entries e ON e.id = h.entry_id`
+
+func scanScreeningHit(row rowScanner) (ScreeningHit, error) {
+	var hit ScreeningHit
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&hit.ID, &hit.UserID, &hit.EntryID, &hit.EntryName, &hit.Programs, &hit.ScreenedName, &hit.MatchedName, &hit.Score,
+		&hit.Status, &hit.Reviewer, &hit.ReviewNote, &hit.CreatedAt, &reviewedAt); err != nil {
+		return ScreeningHit{}, err
+	}
+	if reviewedAt.Valid {
+		hit.ReviewedAt = &reviewedAt.Time
+	}
+
+	return hit, nil
+}
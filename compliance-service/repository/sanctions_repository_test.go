@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var screeningHitRows = []string{"id", "user_id", "entry_id", "name", "programs", "screened_name", "matched_name", "score", "status", "reviewer", "review_note", "created_at", "reviewed_at"}
+
+func TestReplaceSanctionsEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []SanctionsEntry{{ExternalID: "90001", Name: "Viktor KRAVCHENKO", EntryType: "individual", Programs: "SAMPLE-1", Aliases: []string{"Виктор КРАВЧЕНКО"}}}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Entries upserted with their names",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`UPDATE sanctions_entries SET active = 0 WHERE list_name = \?`).WithArgs("ofac_sdn").WillReturnResult(sqlmock.NewResult(0, 3))
+				upsert := dbMock.ExpectPrepare(`INSERT INTO sanctions_entries (.+) ON CONFLICT \(list_name, external_id\) DO UPDATE SET`)
+				id := dbMock.ExpectPrepare(`SELECT id FROM sanctions_entries WHERE list_name = \? AND external_id = \?`)
+				deleteNames := dbMock.ExpectPrepare(`DELETE FROM sanctions_names WHERE entry_id = \?`)
+				insertName := dbMock.ExpectPrepare(`INSERT INTO sanctions_names \(entry_id, name\) VALUES \(\?, \?\)`)
+				upsert.ExpectExec().WithArgs("ofac_sdn", "90001", "Viktor KRAVCHENKO", "individual", "SAMPLE-1", now).WillReturnResult(sqlmock.NewResult(0, 1))
+				id.ExpectQuery().WithArgs("ofac_sdn", "90001").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+				deleteNames.ExpectExec().WithArgs(int64(4)).WillReturnResult(sqlmock.NewResult(0, 2))
+				insertName.ExpectExec().WithArgs(int64(4), "Viktor KRAVCHENKO").WillReturnResult(sqlmock.NewResult(1, 1))
+				insertName.ExpectExec().WithArgs(int64(4), "Виктор КРАВЧЕНКО").WillReturnResult(sqlmock.NewResult(2, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Error rolls back the whole load",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`UPDATE sanctions_entries SET active = 0`).WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewSanctionsRepository(db).ReplaceEntries("ofac_sdn", entries, now)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetActiveSanctionsNames(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT e.id, e.name, n.name FROM sanctions_names n JOIN sanctions_entries e ON e.id = n.entry_id WHERE e.active = 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "name"}).
+			AddRow(4, "Viktor KRAVCHENKO", "Viktor KRAVCHENKO").
+			AddRow(4, "Viktor KRAVCHENKO", "Виктор КРАВЧЕНКО"))
+
+	names, err := NewSanctionsRepository(db).GetActiveNames()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SanctionsName{
+		{EntryID: 4, EntryName: "Viktor KRAVCHENKO", Name: "Viktor KRAVCHENKO"},
+		{EntryID: 4, EntryName: "Viktor KRAVCHENKO", Name: "Виктор КРАВЧЕНКО"},
+	}, names)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateScreeningHits(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	hits := []ScreeningHit{
+		{UserID: 1, EntryID: 4, ScreenedName: "Viktor Kravchenko", MatchedName: "Viktor KRAVCHENKO", Score: 1, Status: "pending", CreatedAt: now},
+		{UserID: 2, EntryID: 4, ScreenedName: "Victor Kravchenko", MatchedName: "Viktor KRAVCHENKO", Score: 0.96, Status: "pending", CreatedAt: now},
+	}
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectBegin()
+	prepared := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO screening_hits`)
+	prepared.ExpectExec().WithArgs(int64(1), int64(4), "Viktor Kravchenko", "Viktor KRAVCHENKO", float64(1), "pending", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepared.ExpectExec().WithArgs(int64(2), int64(4), "Victor Kravchenko", "Viktor KRAVCHENKO", 0.96, "pending", now).WillReturnResult(sqlmock.NewResult(8, 1))
+	dbMock.ExpectCommit()
+
+	created, err := NewSanctionsRepository(db).CreateHits(hits)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestScreeningHitReview(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	reviewedAt := now.Add(time.Hour)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT (.+) FROM screening_hits h JOIN sanctions_entries e ON e.id = h.entry_id WHERE 1 = 1 AND h.status = \? ORDER BY h.id`).
+		WithArgs("pending").
+		WillReturnRows(sqlmock.NewRows(screeningHitRows).
+			AddRow(8, 2, 4, "Viktor KRAVCHENKO", "SAMPLE-1", "Victor Kravchenko", "Viktor KRAVCHENKO", 0.96, "pending", "", "", now, nil))
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`UPDATE screening_hits SET status = \?, reviewer = \?, review_note = \?, reviewed_at = \? WHERE id = \?`).
+		WithArgs("confirmed", "alice", "same passport", reviewedAt, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(`SELECT user_id FROM screening_hits WHERE id = \?`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+	expectEvent(dbMock, EventUserLocked, `{"user_id":2,"reason":"sanctions_match"}`)
+	dbMock.ExpectCommit()
+	dbMock.ExpectQuery(`SELECT (.+) FROM screening_hits h JOIN sanctions_entries e ON e.id = h.entry_id WHERE h.id = \?`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows(screeningHitRows).
+			AddRow(8, 2, 4, "Viktor KRAVCHENKO", "SAMPLE-1", "Victor Kravchenko", "Viktor KRAVCHENKO", 0.96, "confirmed", "alice", "same passport", now, reviewedAt))
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`UPDATE screening_hits SET status = \?`).
+		WithArgs("dismissed", "alice", "", reviewedAt, int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectRollback()
+
+	repository := NewSanctionsRepository(db)
+
+	hits, err := repository.ListHits("pending")
+	assert.NoError(t, err)
+	assert.Equal(t, []ScreeningHit{{ID: 8, UserID: 2, EntryID: 4, EntryName: "Viktor KRAVCHENKO", Programs: "SAMPLE-1", ScreenedName: "Victor Kravchenko",
+		MatchedName: "Viktor KRAVCHENKO", Score: 0.96, Status: "pending", CreatedAt: now}}, hits)
+
+	assert.NoError(t, repository.ReviewHit(8, "confirmed", "alice", "same passport", reviewedAt))
+
+	hit, err := repository.GetHit(8)
+	assert.NoError(t, err)
+	assert.Equal(t, "confirmed", hit.Status)
+	assert.Equal(t, &reviewedAt, hit.ReviewedAt)
+
+	assert.ErrorIs(t, repository.ReviewHit(9, "dismissed", "alice", "", reviewedAt), sql.ErrNoRows)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestHasConfirmedHit(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM screening_hits h JOIN sanctions_entries e ON e.id = h.entry_id\s+WHERE h.user_id = \? AND h.status = 'confirmed' AND e.active = 1\)`).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	sanctioned, err := NewSanctionsRepository(db).HasConfirmedHit(2)
+
+	assert.NoError(t, err)
+	assert.True(t, sanctioned)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// SARSubject is the person a Suspicious Activity Report is about. IDDocumentNumber is the encrypted document number
+// and is never sent to clients.
+type SARSubject struct {
+	UserID           int64  `json:"user_id"`
+	Name             string `json:"name"`
+	DateOfBirth      string `json:"date_of_birth"`
+	AddressLine      string `json:"address_line"`
+	City             string `json:"city"`
+	PostalCode       string `json:"postal_code"`
+	Country          string `json:"country"`
+	IDDocumentType   string `json:"id_document_type"`
+	IDDocumentNumber string `json:"-"`
+	IDDocumentLast4  string `json:"id_document_last4"`
+}
+
+type SARTransaction struct {
+	TransactionID string    `json:"transaction_id"`
+	CardID        int64     `json:"card_id"`
+	Amount        float64   `json:"amount"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// SAR is a Suspicious Activity Report drafted from a case. Transactions are only loaded by GetSAR.
+type SAR struct {
+	ID              int64            `json:"id"`
+	CaseID          int64            `json:"case_id"`
+	Status          string           `json:"status"`
+	Subject         SARSubject       `json:"subject"`
+	ActivityTypes   []string         `json:"activity_types"`
+	Narrative       string           `json:"narrative"`
+	PreparedBy      string           `json:"prepared_by"`
+	FilingReference string           `json:"filing_reference,omitempty"`
+	StatusNote      string           `json:"status_note,omitempty"`
+	Transactions    []SARTransaction `json:"transactions,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	FiledAt         *time.Time       `json:"filed_at,omitempty"`
+}
+
+type SARAccess struct {
+	Officer    string    `json:"officer"`
+	Action     string    `json:"action"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source sar_repository.go -destination mock/sar_repository_mock.go -package mock
+type SARRepository interface {
+	CreateSAR(sar SAR) (int64, error)
+	GetSAR(sarID int64) (SAR, error)
+	ListSARs(status string) ([]SAR, error)
+	UpdateDraft(sar SAR) error
+	UpdateStatus(sarID int64, fromStatus string, status string, reference string, note string, filedAt *time.Time, updatedAt time.Time) error
+	LogAccess(sarID int64, officer string, action string, accessedAt time.Time) error
+	ListAccess(sarID int64) ([]SARAccess, error)
+}
+
+type sarRepository struct {
+	db *sql.DB
+}
+
+func NewSARRepository(db *sql.DB) SARRepository {
+	return &sarRepository{db: db}
+}
+
+// CreateSAR stores the report together with its transactions in one transaction.
+func (r *sarRepository) CreateSAR(sar SAR) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`INSERT INTO sars (case_id, status, subject_user_id, subject_name, subject_date_of_birth, subject_address_line, subject_city,
+			subject_postal_code, subject_country, subject_id_document_type, subject_id_document_number, subject_id_document_last4,
+			activity_types, narrative, prepared_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sar.CaseID, sar.Status, sar.Subject.UserID, sar.Subject.Name, sar.Subject.DateOfBirth, sar.Subject.AddressLine, sar.Subject.City,
+		sar.Subject.PostalCode, sar.Subject.Country, sar.Subject.IDDocumentType, sar.Subject.IDDocumentNumber, sar.Subject.IDDocumentLast4,
+		strings.Join(sar.ActivityTypes, ","), sar.Narrative, sar.PreparedBy, sar.CreatedAt, sar.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	sarID, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, transaction := range sar.Transactions {
+		_, err := tx.Exec("INSERT INTO sar_transactions (sar_id, transaction_id, card_id, amount, occurred_at) VALUES (?, ?, ?, ?, ?)",
+			sarID, transaction.TransactionID, transaction.CardID, transaction.Amount, transaction.OccurredAt)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return sarID, nil
+}
+
+func (r *sarRepository) GetSAR(sarID int64) (SAR, error) {
+	sar, err := scanSAR(r.db.QueryRow(sarQuery+" WHERE id = ?", sarID))
+	if err != nil {
+		return SAR{}, err
+	}
+
+	rows, err := r.db.Query("SELECT transaction_id, card_id, amount, occurred_at FROM sar_transactions WHERE sar_id = ? ORDER BY occurred_at, id", sarID)
+	if err != nil {
+		return SAR{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transaction SARTransaction
+		if err := rows.Scan(&transaction.TransactionID, &transaction.CardID, &transaction.Amount, &transaction.OccurredAt); err != nil {
+			return SAR{}, err
+		}
+		sar.Transactions = append(sar.Transactions, transaction)
+	}
+
+	return sar, nil
+}
+
+func (r *sarRepository) ListSARs(status string) ([]SAR, error) {
+	query := sarQuery + " WHERE 1 = 1"
+	var args []any
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+
+	rows, err := r.db.Query(query+" ORDER BY id DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sars []SAR
+	for rows.Next() {
+		sar, err := scanSAR(rows)
+		if err != nil {
+			return nil, err
+		}
+		sars = append(sars, sar)
+	}
+
+	return sars, nil
+}
+
+// UpdateDraft replaces the subject, activity types and narrative of a draft report. It returns sql.ErrNoRows when the
+// report does not exist or is no longer a draft.
+func (r *sarRepository) UpdateDraft(sar SAR) error {
+	result, err := r.db.Exec(`UPDATE sars SET subject_name = ?, subject_date_of_birth = ?, subject_address_line = ?, subject_city = ?,
+			subject_postal_code = ?, subject_country = ?, activity_types = ?, narrative = ?, updated_at = ? WHERE id = ? AND status = 'draft'`,
+		sar.Subject.Name, sar.Subject.DateOfBirth, sar.Subject.AddressLine, sar.Subject.City, sar.Subject.PostalCode, sar.Subject.Country,
+		strings.Join(sar.ActivityTypes, ","), sar.Narrative, sar.UpdatedAt, sar.ID)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// UpdateStatus moves the report from fromStatus to status. filedAt, when not nil, replaces the filing time. It returns
+// sql.ErrNoRows when the report is not in fromStatus anymore.
+func (r *sarRepository) UpdateStatus(sarID int64, fromStatus string, status string, reference string, note string, filedAt *time.Time, updatedAt time.Time) error {
+	result, err := r.db.Exec(`UPDATE sars SET status = ?, filing_reference = ?, status_note = ?, filed_at = COALESCE(?, filed_at), updated_at = ?
+		WHERE id = ? AND status = ?`, status, reference, note, nullableTime(filedAt), updatedAt, sarID, fromStatus)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (r *sarRepository) LogAccess(sarID int64, officer string, action string, accessedAt time.Time) error {
+	_, err := r.db.Exec("INSERT INTO sar_access_log (sar_id, officer, action, accessed_at) VALUES (?, ?, ?, ?)", sarID, officer, action, accessedAt)
+	return err
+}
+
+func (r *sarRepository) ListAccess(sarID int64) ([]SARAccess, error) {
+	rows, err := r.db.Query("SELECT officer, action, accessed_at FROM sar_access_log WHERE sar_id = ? ORDER BY id", sarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accesses []SARAccess
+	for rows.Next() {
+		var access SARAccess
+		if err := rows.Scan(&access.Officer, &access.Action, &access.AccessedAt); err != nil {
+			return nil, err
+		}
+		accesses = append(accesses, access)
+	}
+
+	return accesses, nil
+}
+
+const sarQuery = `SELECT id, case_id, status, subject_user_id, subject_name, subject_date_of_birth, subject_address_line, subject_city,
+	subject_postal_code, subject_country, subject_id_document_type, subject_id_document_number, subject_id_document_last4, activity_types,
+	narrative, prepared_by, filing_reference, status_note, created_at, updated_at, filed_at FROM sars`
+
+func scanSAR(row rowScanner) (SAR, error) {
+	var sar SAR
+	var activityTypes string
+	var filedAt sql.NullTime
+	if err := row.Scan(&sar.ID, &sar.CaseID, &sar.Status, &sar.Subject.UserID, &sar.Subject.Name, &sar.Subject.DateOfBirth, &sar.Subject.AddressLine,
+		&sar.Subject.City, &sar.Subject.PostalCode, &sar.Subject.Country, &sar.Subject.IDDocumentType, &sar.Subject.IDDocumentNumber,
+		&sar.Subject.IDDocumentLast4, &activityTypes, &sar.Narrative, &sar.PreparedBy, &sar.FilingReference, &sar.StatusNote,
+		&sar.CreatedAt, &sar.UpdatedAt, &filedAt); err != nil {
+		return SAR{}, err
+	}
+	sar.ActivityTypes = []string{}
+	if activityTypes != "" {
+		sar.ActivityTypes = strings.Split(activityTypes, ",")
+	}
+	if filedAt.Valid {
+		sar.FiledAt = &filedAt.Time
+	}
+
+	return sar, nil
+}
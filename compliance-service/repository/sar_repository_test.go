@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var sarRows = []string{"id", "case_id", "status", "subject_user_id", "subject_name", "subject_date_of_birth", "subject_address_line", "subject_city",
+	"subject_postal_code", "subject_country", "subject_id_document_type", "subject_id_document_number", "subject_id_document_last4", "activity_types",
+	"narrative", "prepared_by", "filing_reference", "status_note", "created_at", "updated_at", "filed_at"}
+
+func TestCreateSAR(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	sar := SAR{
+		CaseID:        7,
+		Status:        "draft",
+		Subject:       SARSubject{UserID: 1, Name: "John Doe", Country: "US", IDDocumentType: "passport", IDDocumentNumber: "ciphertext", IDDocumentLast4: "6789"},
+		ActivityTypes: []string{"fraud", "structuring"},
+		Narrative:     "narrative",
+		PreparedBy:    "alice",
+		Transactions:  []SARTransaction{{TransactionID: "txn_1", CardID: 2, Amount: 9500, OccurredAt: now}},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, sarID int64, err error)
+	}{
+		{
+			name: "Success - Report and transactions stored",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO sars`).
+					WithArgs(int64(7), "draft", int64(1), "John Doe", "", "", "", "", "US", "passport", "ciphertext", "6789", "fraud,structuring", "narrative", "alice", now, now).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				dbMock.ExpectExec(`INSERT INTO sar_transactions \(sar_id, transaction_id, card_id, amount, occurred_at\) VALUES \(\?, \?, \?, \?, \?\)`).
+					WithArgs(int64(3), "txn_1", int64(2), 9500.0, now).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, sarID int64, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(3), sarID)
+			},
+		},
+		{
+			name: "Failure - Transaction insert error rolls back",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO sars`).WillReturnResult(sqlmock.NewResult(3, 1))
+				dbMock.ExpectExec(`INSERT INTO sar_transactions`).WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, sarID int64, err error) {
+				assert.EqualError(t, err, "database error")
+				assert.Zero(t, sarID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			sarID, err := NewSARRepository(db).CreateSAR(sar)
+			tt.assertFunc(t, sarID, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetAndListSARs(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	filedAt := now.Add(time.Hour)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT (.+) FROM sars WHERE id = \?`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows(sarRows).
+			AddRow(3, 7, "filed", 1, "John Doe", "1990-05-17", "1 Main St", "Springfield", "", "US", "passport", "ciphertext", "6789", "fraud,structuring",
+				"narrative", "alice", "", "", now, filedAt, filedAt))
+	dbMock.ExpectQuery(`SELECT transaction_id, card_id, amount, occurred_at FROM sar_transactions WHERE sar_id = \? ORDER BY occurred_at, id`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "card_id", "amount", "occurred_at"}).AddRow("txn_1", 2, 9500.0, now))
+	dbMock.ExpectQuery(`SELECT (.+) FROM sars WHERE id = \?`).
+		WithArgs(int64(4)).
+		WillReturnError(sql.ErrNoRows)
+	dbMock.ExpectQuery(`SELECT (.+) FROM sars WHERE 1 = 1 AND status = \? ORDER BY id DESC`).
+		WithArgs("draft").
+		WillReturnRows(sqlmock.NewRows(sarRows).
+			AddRow(5, 8, "draft", 2, "", "", "", "", "", "", "", "", "", "", "", "bob", "", "", now, now, nil))
+
+	repository := NewSARRepository(db)
+
+	sar, err := repository.GetSAR(3)
+	assert.NoError(t, err)
+	assert.Equal(t, SAR{ID: 3, CaseID: 7, Status: "filed",
+		Subject: SARSubject{UserID: 1, Name: "John Doe", DateOfBirth: "1990-05-17", AddressLine: "1 Main St", City: "Springfield", Country: "US",
+			IDDocumentType: "passport", IDDocumentNumber: "ciphertext", IDDocumentLast4: "6789"},
+		ActivityTypes: []string{"fraud", "structuring"}, Narrative: "narrative", PreparedBy: "alice",
+		Transactions: []SARTransaction{{TransactionID: "txn_1", CardID: 2, Amount: 9500, OccurredAt: now}},
+		CreatedAt:    now, UpdatedAt: filedAt, FiledAt: &filedAt}, sar)
+
+	_, err = repository.GetSAR(4)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	sars, err := repository.ListSARs("draft")
+	assert.NoError(t, err)
+	assert.Len(t, sars, 1)
+	assert.Equal(t, []string{}, sars[0].ActivityTypes)
+	assert.Nil(t, sars[0].FiledAt)
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateSAR(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`UPDATE sars SET subject_name = \?, (.+) WHERE id = \? AND status = 'draft'`).
+		WithArgs("John Doe", "1990-05-17", "1 Main St", "Springfield", "", "US", "fraud", "narrative", now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(`UPDATE sars SET status = \?, filing_reference = \?, status_note = \?, filed_at = COALESCE\(\?, filed_at\), updated_at = \? WHERE id = \? AND status = \?`).
+		WithArgs("filed", "", "", sql.NullTime{Time: now, Valid: true}, now, int64(3), "draft").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(`INSERT INTO sar_access_log \(sar_id, officer, action, accessed_at\) VALUES \(\?, \?, \?, \?\)`).
+		WithArgs(int64(3), "alice", "file", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectQuery(`SELECT officer, action, accessed_at FROM sar_access_log WHERE sar_id = \? ORDER BY id`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"officer", "action", "accessed_at"}).AddRow("alice", "file", now))
+
+	repository := NewSARRepository(db)
+
+	err := repository.UpdateDraft(SAR{ID: 3, Subject: SARSubject{Name: "John Doe", DateOfBirth: "1990-05-17", AddressLine: "1 Main St", City: "Springfield", Country: "US"},
+		ActivityTypes: []string{"fraud"}, Narrative: "narrative", UpdatedAt: now})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.NoError(t, repository.UpdateStatus(3, "draft", "filed", "", "", &now, now))
+	assert.NoError(t, repository.LogAccess(3, "alice", "file", now))
+
+	accesses, err := repository.ListAccess(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []SARAccess{{Officer: "alice", Action: "file", AccessedAt: now}}, accesses)
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
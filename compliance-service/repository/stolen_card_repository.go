@@ -4,11 +4,23 @@ import (
 	"database/sql"
 )
 
+// BlockedCard is a card of a user a payment would be denied for.
+type BlockedCard struct {
+	UserID int64 `json:"user_id"`
+	CardID int64 `json:"card_id"`
+}
+
 // Run from the /repository folder the following command to generate the mock:
 // mockgen -source stolen_card_repository.go -destination mock/stolen_card_repository_mock.go -package mock
 type StolenCardRepository interface {
 	ReportStolenCards(userID int64, cardIDs []int64) error
 	IsCardReported(userID int64, cardID int64) (bool, error)
+	BlockCards(cardFingerprints []string, source string) error
+	IsCardBlocked(cardFingerprint string) (bool, error)
+	FindBlockedFingerprints(cardFingerprints []string) ([]string, error)
+	ListReportedCards() ([]BlockedCard, error)
+	ListBlockedFingerprints() ([]string, error)
+	ReinstateCard(userID int64, cardID int64, cardFingerprint string) error
 }
 
 type stolenCardRepository struct {
@@ -38,6 +50,10 @@ func (r *stolenCardRepository) ReportStolenCards(userID int64, cardIDs []int64)
 			tx.Rollback()
 			return err
 		}
+		if err := insertEvent(tx, EventCardReported, CardReportedEvent{UserID: userID, CardID: cardID}); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	return tx.Commit()
@@ -48,3 +64,156 @@ func (r *stolenCardRepository) IsCardReported(userID int64, cardID int64) (bool,
 	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM reported_cards WHERE  user_id = ? AND card_id = ?)", userID, cardID).Scan(&exists)
 	return exists, err
 }
+
+// BlockCards blocks the cards for every user they are linked to. Cards already blocked keep their original source.
+func (r *stolenCardRepository) BlockCards(cardFingerprints []string, source string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT OR IGNORE INTO blocked_cards (card_fingerprint, source) VALUES (?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, cardFingerprint := range cardFingerprints {
+		result, err := stmt.Exec(cardFingerprint, source)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		blocked, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if blocked == 0 {
+			continue
+		}
+		if err := insertEvent(tx, EventCardBlocked, CardBlockedEvent{CardFingerprint: cardFingerprint, Source: source}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *stolenCardRepository) IsCardBlocked(cardFingerprint string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM blocked_cards WHERE card_fingerprint = ?)", cardFingerprint).Scan(&exists)
+	return exists, err
+}
+
+// FindBlockedFingerprints returns which of the fingerprints are blocked.
+func (r *stolenCardRepository) FindBlockedFingerprints(cardFingerprints []string) ([]string, error) {
+	if len(cardFingerprints) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(cardFingerprints))
+	for _, cardFingerprint := range cardFingerprints {
+		args = append(args, cardFingerprint)
+	}
+
+	rows, err := r.db.Query("SELECT card_fingerprint FROM blocked_cards WHERE card_fingerprint IN ("+placeholders(len(cardFingerprints))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocked []string
+	for rows.Next() {
+		var cardFingerprint string
+		if err := rows.Scan(&cardFingerprint); err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, cardFingerprint)
+	}
+
+	return blocked, nil
+}
+
+// ListReportedCards returns the cards reported stolen by their owner, ordered by user and card.
+func (r *stolenCardRepository) ListReportedCards() ([]BlockedCard, error) {
+	rows, err := r.db.Query("SELECT user_id, card_id FROM reported_cards ORDER BY user_id, card_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []BlockedCard
+	for rows.Next() {
+		var card BlockedCard
+		if err := rows.Scan(&card.UserID, &card.CardID); err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+func (r *stolenCardRepository) ListBlockedFingerprints() ([]string, error) {
+	rows, err := r.db.Query("SELECT card_fingerprint FROM blocked_cards")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cardFingerprints []string
+	for rows.Next() {
+		var cardFingerprint string
+		if err := rows.Scan(&cardFingerprint); err != nil {
+			return nil, err
+		}
+		cardFingerprints = append(cardFingerprints, cardFingerprint)
+	}
+
+	return cardFingerprints, nil
+}
+
+// ReinstateCard withdraws the report of the card and unblocks its PAN for every user it is linked to, whatever source
+// blocked it. sql.ErrNoRows is returned when the card was neither reported nor blocked.
+func (r *stolenCardRepository) ReinstateCard(userID int64, cardID int64, cardFingerprint string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	reported, err := tx.Exec("DELETE FROM reported_cards WHERE user_id = ? AND card_id = ?", userID, cardID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	blocked, err := tx.Exec("DELETE FROM blocked_cards WHERE card_fingerprint = ?", cardFingerprint)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var removed int64
+	for _, result := range []sql.Result{reported, blocked} {
+		affected, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		removed += affected
+	}
+	if removed == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := insertEvent(tx, EventCardReinstated, CardReinstatedEvent{UserID: userID, CardID: cardID, CardFingerprint: cardFingerprint}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
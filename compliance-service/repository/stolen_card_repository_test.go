@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
 	"testing"
 
@@ -32,8 +33,11 @@ func TestReportStolenCards(t *testing.T) {
 				stmt := dbMock.ExpectPrepare(`INSERT INTO reported_cards \(user_id, card_id\) VALUES \(\?, \?\)`)
 
 				stmt.ExpectExec().WithArgs(in.userID, in.cardIDs[0]).WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEvent(dbMock, EventCardReported, `{"user_id":1,"card_id":101}`)
 				stmt.ExpectExec().WithArgs(in.userID, in.cardIDs[1]).WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEvent(dbMock, EventCardReported, `{"user_id":1,"card_id":102}`)
 				stmt.ExpectExec().WithArgs(in.userID, in.cardIDs[2]).WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEvent(dbMock, EventCardReported, `{"user_id":1,"card_id":103}`)
 
 				dbMock.ExpectCommit()
 			},
@@ -101,7 +105,9 @@ func TestReportStolenCards(t *testing.T) {
 				stmt := dbMock.ExpectPrepare(`INSERT INTO reported_cards \(user_id, card_id\) VALUES \(\?, \?\)`)
 
 				stmt.ExpectExec().WithArgs(in.userID, in.cardIDs[0]).WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEvent(dbMock, EventCardReported, `{"user_id":1,"card_id":101}`)
 				stmt.ExpectExec().WithArgs(in.userID, in.cardIDs[1]).WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEvent(dbMock, EventCardReported, `{"user_id":1,"card_id":102}`)
 
 				dbMock.ExpectCommit().WillReturnError(errors.New("failed to commit transaction"))
 			},
@@ -204,3 +210,175 @@ func TestIsCardReported(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockCards(t *testing.T) {
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Cards blocked",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO blocked_cards \(card_fingerprint, source\) VALUES \(\?, \?\)`)
+				stmt.ExpectExec().WithArgs("fp-1", "card_report").WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEvent(dbMock, EventCardBlocked, `{"card_fingerprint":"fp-1","source":"card_report"}`)
+				// already blocked, no event
+				stmt.ExpectExec().WithArgs("fp-2", "card_report").WillReturnResult(sqlmock.NewResult(0, 0))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Exec error rolls back",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO blocked_cards`)
+				stmt.ExpectExec().WithArgs("fp-1", "card_report").WillReturnError(errors.New("failed to execute insert"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "failed to execute insert")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewStolenCardRepository(db).BlockCards([]string{"fp-1", "fp-2"}, "card_report")
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIsCardBlocked(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM blocked_cards WHERE card_fingerprint = \?\)`).
+		WithArgs("fp-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	blocked, err := NewStolenCardRepository(db).IsCardBlocked("fp-1")
+
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFindBlockedFingerprints(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT card_fingerprint FROM blocked_cards WHERE card_fingerprint IN \(\?, \?, \?\)`).
+		WithArgs("fp-1", "fp-2", "fp-3").
+		WillReturnRows(sqlmock.NewRows([]string{"card_fingerprint"}).AddRow("fp-2"))
+
+	stolenCardRepository := NewStolenCardRepository(db)
+	blocked, err := stolenCardRepository.FindBlockedFingerprints([]string{"fp-1", "fp-2", "fp-3"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fp-2"}, blocked)
+
+	blocked, err = stolenCardRepository.FindBlockedFingerprints(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, blocked)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestListReportedCards(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT user_id, card_id FROM reported_cards ORDER BY user_id, card_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "card_id"}).AddRow(1, 1).AddRow(1, 2))
+
+	cards, err := NewStolenCardRepository(db).ListReportedCards()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockedCard{{UserID: 1, CardID: 1}, {UserID: 1, CardID: 2}}, cards)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestListBlockedFingerprints(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT card_fingerprint FROM blocked_cards`).WillReturnError(errors.New("database error"))
+
+	cardFingerprints, err := NewStolenCardRepository(db).ListBlockedFingerprints()
+
+	assert.EqualError(t, err, "database error")
+	assert.Empty(t, cardFingerprints)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReinstateCard(t *testing.T) {
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Report withdrawn and PAN unblocked",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`DELETE FROM reported_cards WHERE user_id = \? AND card_id = \?`).WithArgs(3, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(`DELETE FROM blocked_cards WHERE card_fingerprint = \?`).WithArgs("fp").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEvent(dbMock, EventCardReinstated, `{"user_id":3,"card_id":7,"card_fingerprint":"fp"}`)
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Card neither reported nor blocked",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`DELETE FROM reported_cards`).WillReturnResult(sqlmock.NewResult(0, 0))
+				dbMock.ExpectExec(`DELETE FROM blocked_cards`).WillReturnResult(sqlmock.NewResult(0, 0))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "Failure - Error unblocking the PAN",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`DELETE FROM reported_cards`).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(`DELETE FROM blocked_cards`).WillReturnError(errors.New("database locked"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database locked")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewStolenCardRepository(db).ReinstateCard(3, 7, "fp")
+
+			tt.assertFunc(t, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
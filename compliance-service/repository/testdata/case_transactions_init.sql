@@ -0,0 +1,25 @@
+-- The cases of a database created before the date of the case transactions was recorded.
+CREATE TABLE IF NOT EXISTS cases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    card_id INTEGER,
+    source TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    assignee TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS case_transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    case_id INTEGER NOT NULL,
+    transaction_id TEXT NOT NULL,
+    card_id INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    attached_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (case_id) REFERENCES cases (id) ON DELETE CASCADE,
+    UNIQUE (case_id, transaction_id)
+);
+
+INSERT INTO cases (user_id, card_id, source) VALUES (1, 1, 'chargeback');
+INSERT INTO case_transactions (case_id, transaction_id, card_id, amount) VALUES (1, 'txn_1234567', 1, 100.5);
@@ -4,10 +4,19 @@ import (
 	"database/sql"
 )
 
+type User struct {
+	ID       int64  `json:"id"`
+	UserName string `json:"user_name"`
+	FullName string `json:"full_name,omitempty"`
+}
+
 // Run from the /repository folder the following command to generate the mock:
 // mockgen -source user_repository.go -destination mock/user_repository_mock.go -package mock
 type UserRepository interface {
 	GetUser(userName string) (int64, string, error)
+	GetUserName(userID int64) (string, error)
+	CreateUser(userName string, fullName string, hashedSecret string) (int64, error)
+	ListUsers() ([]User, error)
 }
 
 type userRepository struct {
@@ -27,3 +36,40 @@ func (r *userRepository) GetUser(userName string) (int64, string, error) {
 	}
 	return userID, hashedSecret, nil
 }
+
+func (r *userRepository) GetUserName(userID int64) (string, error) {
+	var userName string
+	err := r.db.QueryRow("SELECT user_name FROM users WHERE id = ?", userID).Scan(&userName)
+	if err != nil {
+		return "", err
+	}
+	return userName, nil
+}
+
+func (r *userRepository) CreateUser(userName string, fullName string, hashedSecret string) (int64, error) {
+	result, err := r.db.Exec("INSERT INTO users (user_name, full_name, secret_code) VALUES (?, ?, ?)", userName, fullName, hashedSecret)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *userRepository) ListUsers() ([]User, error) {
+	rows, err := r.db.Query("SELECT id, user_name, full_name FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.UserName, &user.FullName); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
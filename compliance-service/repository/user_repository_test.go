@@ -91,3 +91,92 @@ func TestGetUser(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserName(t *testing.T) {
+	type input struct {
+		userID int64
+	}
+
+	type output struct {
+		userName string
+		err      error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - User found",
+			input: input{
+				userID: 1,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery("SELECT user_name FROM users WHERE id = ?").
+					WithArgs(in.userID).
+					WillReturnRows(sqlmock.NewRows([]string{"user_name"}).AddRow("john_doe"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, "john_doe", out.userName)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - User not found",
+			input: input{
+				userID: 999,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery("SELECT user_name FROM users WHERE id = ?").
+					WithArgs(in.userID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.userName)
+				assert.EqualError(t, out.err, sql.ErrNoRows.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			userRepository := NewUserRepository(db)
+			tt.on(dbMock, tt.input)
+
+			userName, err := userRepository.GetUserName(tt.input.userID)
+			tt.assertFunc(t, output{userName, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateAndListUsers(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`INSERT INTO users \(user_name, full_name, secret_code\) VALUES \(\?, \?, \?\)`).
+		WithArgs("ivan_petrov", "Иван Петров", "hashed").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	dbMock.ExpectQuery(`SELECT id, user_name, full_name FROM users ORDER BY id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "full_name"}).
+			AddRow(1, "john_doe", "John Doe").
+			AddRow(3, "ivan_petrov", "Иван Петров"))
+
+	userRepository := NewUserRepository(db)
+
+	userID, err := userRepository.CreateUser("ivan_petrov", "Иван Петров", "hashed")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), userID)
+
+	users, err := userRepository.ListUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, []User{{ID: 1, UserName: "john_doe", FullName: "John Doe"}, {ID: 3, UserName: "ivan_petrov", FullName: "Иван Петров"}}, users)
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// cardFingerprintKey is the CARD_FINGERPRINT_KEY loaded at startup.
+var cardFingerprintKey []byte
+
+// LoadCardFingerprintKey reads CARD_FINGERPRINT_KEY once, at startup, failing when it is not set or is a placeholder.
+// Fingerprints computed with another key no longer match the blocked cards.
+func LoadCardFingerprintKey() error {
+	key, err := secretKey("CARD_FINGERPRINT_KEY")
+	if err != nil {
+		return err
+	}
+
+	cardFingerprintKey = []byte(key)
+	return nil
+}
+
+// CardFingerprint identifies a PAN without storing it: the HMAC-SHA256 of its digits, keyed with
+// CARD_FINGERPRINT_KEY. The same key must be used everywhere fingerprints are computed or compared.
+func CardFingerprint(cardNumber string) string {
+	mac := hmac.New(sha256.New, cardFingerprintKey)
+	mac.Write([]byte(cardDigits(cardNumber)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cardDigits strips the separators from a card number, e.g. "1234-5678 9012" becomes "123456789012".
+func cardDigits(cardNumber string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, cardNumber)
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/logging"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CardImportFormatCSV   = "csv"
+	CardImportFormatJSONL = "jsonl"
+
+	CardImportStatusRunning   = "running"
+	CardImportStatusCompleted = "completed"
+	CardImportStatusFailed    = "failed"
+
+	defaultCardImportBatchSize = 500
+	cardImportReportPageSize   = 1000
+	// cardImportStaleAfter is how long a running import may go without saving a batch before another worker can resume
+	// it, after its worker stopped without marking it failed.
+	cardImportStaleAfter = 10 * time.Minute
+	// maxCardFeedLineLength caps a line of a JSONL feed, a feed without line breaks is not read in memory at once.
+	maxCardFeedLineLength = 4096
+)
+
+var (
+	ErrCardImportNotFound  = errors.New("card import not found")
+	ErrCardImportCompleted = errors.New("card import already completed")
+	ErrCardImportRunning   = errors.New("card import already running")
+	ErrInvalidCardImport   = errors.New("invalid card import")
+
+	importSourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+	panPattern          = regexp.MustCompile(`^[0-9 -]+$`)
+)
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source card_import_service.go -destination mock/card_import_service_mock.go -package mock
+type CardImportService interface {
+	StartImport(source string, fileName string, format string, feed io.Reader) (repository.CardImport, error)
+	ResumeImport(importID int64, feed io.Reader) (repository.CardImport, error)
+	GetImport(importID int64) (repository.CardImport, error)
+	WriteReport(importID int64, w io.Writer) error
+}
+
+type cardImportService struct {
+	cardImportRepository repository.CardImportRepository
+	batchSize            int
+	now                  func() time.Time
+}
+
+func NewCardImportService(cardImportRepository repository.CardImportRepository) CardImportService {
+	batchSize, err := strconv.Atoi(os.Getenv("CARD_IMPORT_BATCH_SIZE"))
+	if err != nil || batchSize <= 0 {
+		batchSize = defaultCardImportBatchSize
+	}
+
+	return &cardImportService{
+		cardImportRepository: cardImportRepository,
+		batchSize:            batchSize,
+		now:                  func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// StartImport streams a compromised card feed into the blocked cards. If it fails half way, the rows up to the last
+// committed batch are kept and ResumeImport continues from there when given the same file again.
+func (s *cardImportService) StartImport(source string, fileName string, format string, feed io.Reader) (repository.CardImport, error) {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if !importSourcePattern.MatchString(source) {
+		return repository.CardImport{}, fmt.Errorf("%w: source must be 1 to 64 lowercase letters, digits, '.', '_' or '-'", ErrInvalidCardImport)
+	}
+
+	reader, err := newCardFeedReader(format, feed)
+	if err != nil {
+		return repository.CardImport{}, err
+	}
+
+	now := s.now()
+	cardImport := repository.CardImport{
+		Source:    source,
+		FileName:  strings.TrimSpace(fileName),
+		Format:    format,
+		Status:    CardImportStatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	cardImport.ID, err = s.cardImportRepository.CreateImport(cardImport)
+	if err != nil {
+		return repository.CardImport{}, err
+	}
+
+	return s.runImport(cardImport, reader)
+}
+
+// ResumeImport runs an import again over the same file, skipping the rows already processed.
+// Blocking a card is idempotent, so resuming after an unclean stop does not block anything twice. An import still
+// running is refused until it goes cardImportStaleAfter without saving a batch, so two workers never process it at once.
+func (s *cardImportService) ResumeImport(importID int64, feed io.Reader) (repository.CardImport, error) {
+	cardImport, err := s.GetImport(importID)
+	if err != nil {
+		return repository.CardImport{}, err
+	}
+	if cardImport.Status == CardImportStatusCompleted {
+		return cardImport, ErrCardImportCompleted
+	}
+
+	reader, err := newCardFeedReader(cardImport.Format, feed)
+	if err != nil {
+		return repository.CardImport{}, err
+	}
+
+	now := s.now()
+	if err := s.cardImportRepository.ClaimImport(importID, now.Add(-cardImportStaleAfter), now); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return repository.CardImport{}, err
+		}
+		// another worker claimed or completed the import since it was read
+		if cardImport, err = s.GetImport(importID); err != nil {
+			return repository.CardImport{}, err
+		}
+		if cardImport.Status == CardImportStatusCompleted {
+			return cardImport, ErrCardImportCompleted
+		}
+		return cardImport, ErrCardImportRunning
+	}
+	cardImport.Status = CardImportStatusRunning
+
+	return s.runImport(cardImport, reader)
+}
+
+func (s *cardImportService) GetImport(importID int64) (repository.CardImport, error) {
+	cardImport, err := s.cardImportRepository.GetImport(importID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.CardImport{}, ErrCardImportNotFound
+		}
+		return repository.CardImport{}, err
+	}
+
+	return cardImport, nil
+}
+
+// WriteReport writes the result of every row of the import as CSV, one page at a time.
+func (s *cardImportService) WriteReport(importID int64, w io.Writer) error {
+	if _, err := s.GetImport(importID); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"row", "status", "card_fingerprint", "detail"}); err != nil {
+		return err
+	}
+
+	var afterRow int64
+	for {
+		rows, err := s.cardImportRepository.GetImportRows(importID, afterRow, cardImportReportPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := writer.Write([]string{strconv.FormatInt(row.RowNumber, 10), row.Status, row.CardFingerprint, row.Detail}); err != nil {
+				return err
+			}
+			afterRow = row.RowNumber
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+
+		if len(rows) < cardImportReportPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *cardImportService) runImport(cardImport repository.CardImport, reader cardFeedReader) (repository.CardImport, error) {
+	if err := s.processFeed(cardImport, reader); err != nil {
+		if finishErr := s.cardImportRepository.FinishImport(cardImport.ID, CardImportStatusFailed, err.Error(), s.now()); finishErr != nil {
+			slog.Error("error marking card import as failed", slog.Int64("import_id", cardImport.ID), logging.Err(finishErr))
+		}
+		failed, getErr := s.GetImport(cardImport.ID)
+		if getErr != nil {
+			failed = cardImport
+		}
+		return failed, fmt.Errorf("card import %d stopped after row %d: %w", cardImport.ID, failed.RowsProcessed, err)
+	}
+
+	if err := s.cardImportRepository.FinishImport(cardImport.ID, CardImportStatusCompleted, "", s.now()); err != nil {
+		return cardImport, err
+	}
+
+	return s.GetImport(cardImport.ID)
+}
+
+// processFeed validates the rows and saves them in batches. Duplicates within a batch are detected here,
+// duplicates across batches or imports by the repository, so memory use does not grow with the file.
+func (s *cardImportService) processFeed(cardImport repository.CardImport, reader cardFeedReader) error {
+	batch := make([]repository.CardImportRow, 0, s.batchSize)
+	seen := map[string]int64{}
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		// payment-service drops its cached verdicts on the CardBlocked events written with the batch
+		if _, err := s.cardImportRepository.SaveBatch(cardImport.ID, cardImport.Source, batch, s.now()); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		clear(seen)
+		return nil
+	}
+
+	for {
+		rowNumber, cardNumber, rowErr, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if rowNumber <= cardImport.RowsProcessed {
+			continue
+		}
+
+		row := repository.CardImportRow{RowNumber: rowNumber}
+		if rowErr == nil {
+			rowErr = validateCardNumber(cardNumber)
+		}
+		if rowErr != nil {
+			row.Status, row.Detail = repository.CardImportRowInvalid, rowErr.Error()
+		} else {
+			row.CardFingerprint = CardFingerprint(cardNumber)
+			if firstRow, found := seen[row.CardFingerprint]; found {
+				row.Status, row.Detail = repository.CardImportRowDuplicate, fmt.Sprintf("same card as row %d", firstRow)
+			} else {
+				row.Status = repository.CardImportRowImported
+				seen[row.CardFingerprint] = rowNumber
+			}
+		}
+
+		batch = append(batch, row)
+		if len(batch) == s.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+// validateCardNumber accepts 12 to 19 digits, optionally separated by spaces or dashes, passing the Luhn check.
+func validateCardNumber(cardNumber string) error {
+	if !panPattern.MatchString(cardNumber) {
+		return errors.New("card number must contain only digits, spaces or dashes")
+	}
+
+	digits := cardDigits(cardNumber)
+	if len(digits) < 12 || len(digits) > 19 {
+		return errors.New("card number must have between 12 and 19 digits")
+	}
+
+	sum := 0
+	for i := range digits {
+		digit := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	if sum%10 != 0 {
+		return errors.New("card number fails the Luhn check")
+	}
+
+	return nil
+}
+
+// cardFeedReader reads a feed one row at a time. A malformed row is returned as rowErr and the reading goes on,
+// err is only set when the feed itself can no longer be read, or to io.EOF at the end.
+type cardFeedReader interface {
+	next() (rowNumber int64, cardNumber string, rowErr error, err error)
+}
+
+func newCardFeedReader(format string, feed io.Reader) (cardFeedReader, error) {
+	switch format {
+	case CardImportFormatCSV:
+		return newCSVCardFeedReader(feed)
+	case CardImportFormatJSONL:
+		return &jsonlCardFeedReader{reader: bufio.NewReaderSize(feed, maxCardFeedLineLength)}, nil
+	}
+
+	return nil, fmt.Errorf("%w: format must be %s or %s", ErrInvalidCardImport, CardImportFormatCSV, CardImportFormatJSONL)
+}
+
+// csvCardFeedReader reads a CSV feed with a pan or card_number column. Rows are numbered from 1 after the header.
+type csvCardFeedReader struct {
+	reader    *csv.Reader
+	column    int
+	rowNumber int64
+}
+
+func newCSVCardFeedReader(feed io.Reader) (*csvCardFeedReader, error) {
+	reader := csv.NewReader(feed)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing CSV header", ErrInvalidCardImport)
+	}
+	for i, name := range header {
+		if name := strings.ToLower(strings.TrimSpace(name)); name == "pan" || name == "card_number" {
+			return &csvCardFeedReader{reader: reader, column: i}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: CSV header must have a pan or card_number column", ErrInvalidCardImport)
+}
+
+func (r *csvCardFeedReader) next() (int64, string, error, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return 0, "", nil, io.EOF
+	}
+	r.rowNumber++
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return r.rowNumber, "", fmt.Errorf("malformed CSV row: %s", parseErr.Err), nil
+	}
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if r.column >= len(record) {
+		return r.rowNumber, "", errors.New("missing card number"), nil
+	}
+
+	return r.rowNumber, strings.TrimSpace(record[r.column]), nil, nil
+}
+
+// jsonlCardFeedReader reads one JSON object per line with a pan or card_number field.
+// Rows are numbered by line, blank lines are skipped. A line longer than the buffer of the reader is an invalid row.
+type jsonlCardFeedReader struct {
+	reader     *bufio.Reader
+	lineNumber int64
+}
+
+func (r *jsonlCardFeedReader) next() (int64, string, error, error) {
+	for {
+		line, err := r.reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			r.lineNumber++
+			// the rest of the line is skipped without being kept
+			for err == bufio.ErrBufferFull {
+				_, err = r.reader.ReadSlice('\n')
+			}
+			if err != nil && err != io.EOF {
+				return 0, "", nil, err
+			}
+			return r.lineNumber, "", fmt.Errorf("line longer than %d bytes", r.reader.Size()), nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, "", nil, err
+		}
+		if len(line) == 0 && err == io.EOF {
+			return 0, "", nil, io.EOF
+		}
+		r.lineNumber++
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var row struct {
+			PAN        string `json:"pan"`
+			CardNumber string `json:"card_number"`
+		}
+		if err := json.Unmarshal(line, &row); err != nil {
+			return r.lineNumber, "", errors.New("malformed JSON line"), nil
+		}
+		if row.PAN == "" {
+			row.PAN = row.CardNumber
+		}
+
+		return r.lineNumber, strings.TrimSpace(row.PAN), nil, nil
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const cardImportFeed = "pan,reason\n4111 1111 1111 1111,breach\n4111-1111-1111-1111,breach\n4111111111111112,breach\n5500000000000004,breach\n"
+
+func newTestCardImportService(ctrl *gomock.Controller, now time.Time) (*cardImportService, *mock.MockCardImportRepository) {
+	cardImportRepositoryMock := mock.NewMockCardImportRepository(ctrl)
+	return &cardImportService{
+		cardImportRepository: cardImportRepositoryMock,
+		batchSize:            2,
+		now:                  func() time.Time { return now },
+	}, cardImportRepositoryMock
+}
+
+func TestStartCardImport(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	visa, mastercard := CardFingerprint("4111111111111111"), CardFingerprint("5500000000000004")
+
+	tests := []struct {
+		name       string
+		source     string
+		format     string
+		feed       string
+		on         func(cardImportRepositoryMock *mock.MockCardImportRepository)
+		assertFunc func(t *testing.T, cardImport repository.CardImport, err error)
+	}{
+		{
+			name:   "Success - Feed imported in batches",
+			source: "visa-cams",
+			format: CardImportFormatCSV,
+			feed:   cardImportFeed,
+			on: func(cardImportRepositoryMock *mock.MockCardImportRepository) {
+				cardImportRepositoryMock.EXPECT().CreateImport(repository.CardImport{Source: "visa-cams", FileName: "feed.csv", Format: CardImportFormatCSV,
+					Status: CardImportStatusRunning, CreatedAt: now, UpdatedAt: now}).Return(int64(7), nil)
+				gomock.InOrder(
+					cardImportRepositoryMock.EXPECT().SaveBatch(int64(7), "visa-cams", []repository.CardImportRow{
+						{RowNumber: 1, Status: repository.CardImportRowImported, CardFingerprint: visa},
+						{RowNumber: 2, Status: repository.CardImportRowDuplicate, CardFingerprint: visa, Detail: "same card as row 1"},
+					}, now).Return(nil, nil),
+					cardImportRepositoryMock.EXPECT().SaveBatch(int64(7), "visa-cams", []repository.CardImportRow{
+						{RowNumber: 3, Status: repository.CardImportRowInvalid, Detail: "card number fails the Luhn check"},
+						{RowNumber: 4, Status: repository.CardImportRowImported, CardFingerprint: mastercard},
+					}, now).Return(nil, nil),
+				)
+				cardImportRepositoryMock.EXPECT().FinishImport(int64(7), CardImportStatusCompleted, "", now).Return(nil)
+				cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{ID: 7, Status: CardImportStatusCompleted, RowsProcessed: 4}, nil)
+			},
+			assertFunc: func(t *testing.T, cardImport repository.CardImport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, CardImportStatusCompleted, cardImport.Status)
+				assert.Equal(t, int64(4), cardImport.RowsProcessed)
+			},
+		},
+		{
+			name:   "Failure - Batch error stops the import",
+			source: "visa-cams",
+			format: CardImportFormatCSV,
+			feed:   cardImportFeed,
+			on: func(cardImportRepositoryMock *mock.MockCardImportRepository) {
+				cardImportRepositoryMock.EXPECT().CreateImport(gomock.Any()).Return(int64(7), nil)
+				gomock.InOrder(
+					cardImportRepositoryMock.EXPECT().SaveBatch(int64(7), "visa-cams", gomock.Any(), now).Return(nil, nil),
+					cardImportRepositoryMock.EXPECT().SaveBatch(int64(7), "visa-cams", gomock.Any(), now).Return(nil, errors.New("database is locked")),
+				)
+				cardImportRepositoryMock.EXPECT().FinishImport(int64(7), CardImportStatusFailed, "database is locked", now).Return(nil)
+				cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{ID: 7, Status: CardImportStatusFailed, RowsProcessed: 2}, nil)
+			},
+			assertFunc: func(t *testing.T, cardImport repository.CardImport, err error) {
+				assert.EqualError(t, err, "card import 7 stopped after row 2: database is locked")
+				assert.Equal(t, int64(7), cardImport.ID)
+			},
+		},
+		{
+			name:   "Failure - Missing card number column",
+			source: "visa-cams",
+			format: CardImportFormatCSV,
+			feed:   "number,reason\n4111111111111111,breach\n",
+			on:     func(cardImportRepositoryMock *mock.MockCardImportRepository) {},
+			assertFunc: func(t *testing.T, cardImport repository.CardImport, err error) {
+				assert.ErrorIs(t, err, ErrInvalidCardImport)
+				assert.EqualError(t, err, "invalid card import: CSV header must have a pan or card_number column")
+			},
+		},
+		{
+			name:   "Failure - Unknown format",
+			source: "visa-cams",
+			format: "xml",
+			on:     func(cardImportRepositoryMock *mock.MockCardImportRepository) {},
+			assertFunc: func(t *testing.T, cardImport repository.CardImport, err error) {
+				assert.ErrorIs(t, err, ErrInvalidCardImport)
+			},
+		},
+		{
+			name:   "Failure - Invalid source",
+			source: "visa cams",
+			format: CardImportFormatCSV,
+			on:     func(cardImportRepositoryMock *mock.MockCardImportRepository) {},
+			assertFunc: func(t *testing.T, cardImport repository.CardImport, err error) {
+				assert.ErrorIs(t, err, ErrInvalidCardImport)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, cardImportRepositoryMock := newTestCardImportService(ctrl, now)
+			tt.on(cardImportRepositoryMock)
+
+			cardImport, err := service.StartImport(tt.source, "feed.csv", tt.format, strings.NewReader(tt.feed))
+			tt.assertFunc(t, cardImport, err)
+		})
+	}
+}
+
+func TestResumeCardImport(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Success - Processed rows are skipped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service, cardImportRepositoryMock := newTestCardImportService(ctrl, now)
+		failed := repository.CardImport{ID: 7, Source: "visa-cams", Format: CardImportFormatCSV, Status: CardImportStatusFailed, RowsProcessed: 2}
+		cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(failed, nil)
+		cardImportRepositoryMock.EXPECT().ClaimImport(int64(7), now.Add(-cardImportStaleAfter), now).Return(nil)
+		cardImportRepositoryMock.EXPECT().SaveBatch(int64(7), "visa-cams", []repository.CardImportRow{
+			{RowNumber: 3, Status: repository.CardImportRowInvalid, Detail: "card number fails the Luhn check"},
+			{RowNumber: 4, Status: repository.CardImportRowImported, CardFingerprint: CardFingerprint("5500000000000004")},
+		}, now).Return(nil, nil)
+		cardImportRepositoryMock.EXPECT().FinishImport(int64(7), CardImportStatusCompleted, "", now).Return(nil)
+		cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{ID: 7, Status: CardImportStatusCompleted, RowsProcessed: 4}, nil)
+
+		cardImport, err := service.ResumeImport(7, strings.NewReader(cardImportFeed))
+
+		assert.NoError(t, err)
+		assert.Equal(t, CardImportStatusCompleted, cardImport.Status)
+	})
+
+	t.Run("Failure - Import already completed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service, cardImportRepositoryMock := newTestCardImportService(ctrl, now)
+		cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{ID: 7, Status: CardImportStatusCompleted}, nil)
+
+		_, err := service.ResumeImport(7, strings.NewReader(cardImportFeed))
+		assert.ErrorIs(t, err, ErrCardImportCompleted)
+	})
+
+	t.Run("Failure - Import already running", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service, cardImportRepositoryMock := newTestCardImportService(ctrl, now)
+		running := repository.CardImport{ID: 7, Source: "visa-cams", Format: CardImportFormatCSV, Status: CardImportStatusRunning, RowsProcessed: 2}
+		cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(running, nil).Times(2)
+		cardImportRepositoryMock.EXPECT().ClaimImport(int64(7), now.Add(-cardImportStaleAfter), now).Return(sql.ErrNoRows)
+
+		cardImport, err := service.ResumeImport(7, strings.NewReader(cardImportFeed))
+		assert.ErrorIs(t, err, ErrCardImportRunning)
+		assert.Equal(t, CardImportStatusRunning, cardImport.Status)
+	})
+
+	t.Run("Failure - Import completed by another worker", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service, cardImportRepositoryMock := newTestCardImportService(ctrl, now)
+		failed := repository.CardImport{ID: 7, Source: "visa-cams", Format: CardImportFormatCSV, Status: CardImportStatusFailed, RowsProcessed: 2}
+		cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(failed, nil)
+		cardImportRepositoryMock.EXPECT().ClaimImport(int64(7), now.Add(-cardImportStaleAfter), now).Return(sql.ErrNoRows)
+		cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{ID: 7, Status: CardImportStatusCompleted}, nil)
+
+		_, err := service.ResumeImport(7, strings.NewReader(cardImportFeed))
+		assert.ErrorIs(t, err, ErrCardImportCompleted)
+	})
+
+	t.Run("Failure - Import not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service, cardImportRepositoryMock := newTestCardImportService(ctrl, now)
+		cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{}, sql.ErrNoRows)
+
+		_, err := service.ResumeImport(7, strings.NewReader(cardImportFeed))
+		assert.ErrorIs(t, err, ErrCardImportNotFound)
+	})
+}
+
+func TestWriteCardImportReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, cardImportRepositoryMock := newTestCardImportService(ctrl, time.Now())
+	page := make([]repository.CardImportRow, cardImportReportPageSize)
+	for i := range page {
+		page[i] = repository.CardImportRow{RowNumber: int64(i + 1), Status: repository.CardImportRowImported, CardFingerprint: "fp"}
+	}
+
+	cardImportRepositoryMock.EXPECT().GetImport(int64(7)).Return(repository.CardImport{ID: 7}, nil)
+	gomock.InOrder(
+		cardImportRepositoryMock.EXPECT().GetImportRows(int64(7), int64(0), cardImportReportPageSize).Return(page, nil),
+		cardImportRepositoryMock.EXPECT().GetImportRows(int64(7), int64(cardImportReportPageSize), cardImportReportPageSize).
+			Return([]repository.CardImportRow{{RowNumber: 1001, Status: repository.CardImportRowInvalid, Detail: "malformed JSON line"}}, nil),
+	)
+
+	var report bytes.Buffer
+	err := service.WriteReport(7, &report)
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	assert.Len(t, lines, 1002)
+	assert.Equal(t, "row,status,card_fingerprint,detail", lines[0])
+	assert.Equal(t, "1001,invalid,,malformed JSON line", lines[1001])
+}
+
+func TestJSONLCardFeedReader(t *testing.T) {
+	longLine := `{"pan": "4111111111111111", "reason": "` + strings.Repeat("x", 3*maxCardFeedLineLength) + `"}`
+	reader, err := newCardFeedReader(CardImportFormatJSONL, strings.NewReader("{\"pan\": \"4111111111111111\"}\n\nnot json\n"+longLine+"\n"+
+		"{\"card_number\": \"5500000000000004\"}\n"+longLine))
+	assert.NoError(t, err)
+
+	type row struct {
+		rowNumber  int64
+		cardNumber string
+		rowErr     error
+	}
+	var rows []row
+	for {
+		rowNumber, cardNumber, rowErr, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		rows = append(rows, row{rowNumber, cardNumber, rowErr})
+	}
+
+	assert.Equal(t, []row{
+		{1, "4111111111111111", nil},
+		{3, "", errors.New("malformed JSON line")},
+		{4, "", errors.New("line longer than 4096 bytes")},
+		{5, "5500000000000004", nil},
+		{6, "", errors.New("line longer than 4096 bytes")},
+	}, rows)
+}
+
+func TestValidateCardNumber(t *testing.T) {
+	tests := []struct {
+		cardNumber string
+		err        string
+	}{
+		{cardNumber: "4111 1111 1111 1111"},
+		{cardNumber: "5500-0000-0000-0004"},
+		{cardNumber: "4111111111111112", err: "card number fails the Luhn check"},
+		{cardNumber: "41111111", err: "card number must have between 12 and 19 digits"},
+		{cardNumber: "4111x1111x1111x1111", err: "card number must contain only digits, spaces or dashes"},
+		{cardNumber: "", err: "card number must contain only digits, spaces or dashes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cardNumber, func(t *testing.T) {
+			err := validateCardNumber(tt.cardNumber)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
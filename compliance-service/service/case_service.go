@@ -0,0 +1,342 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/logging"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+const (
+	CaseSourceCardReport      = "card_report"
+	CaseSourceHighRiskPayment = "high_risk_payment"
+	CaseSourceChargeback      = "chargeback"
+	CaseSourceManual          = "manual"
+
+	CaseStatusOpen           = "open"
+	CaseStatusInvestigating  = "investigating"
+	CaseStatusConfirmedFraud = "confirmed_fraud"
+	CaseStatusDismissed      = "dismissed"
+
+	// casePaymentsLookback is how long before the opening of a case the payments of the user are exported with it.
+	casePaymentsLookback = 30 * 24 * time.Hour
+)
+
+var (
+	ErrCaseNotFound          = errors.New("case not found")
+	ErrInvalidCaseSource     = errors.New("invalid case source")
+	ErrInvalidCaseTransition = errors.New("invalid case status transition")
+	// ErrCaseTransactionNotFound and ErrCaseTransactionMismatch reject a transaction payment-service has no record of
+	// for the user, or recorded with another card, amount or time.
+	ErrCaseTransactionNotFound = errors.New("transaction not found in the payments of the user")
+	ErrCaseTransactionMismatch = errors.New("transaction does not match the payment recorded by payment-service")
+)
+
+// caseTransitions lists, for each status, the statuses a case may move to.
+var caseTransitions = map[string][]string{
+	CaseStatusOpen:           {CaseStatusInvestigating, CaseStatusDismissed},
+	CaseStatusInvestigating:  {CaseStatusOpen, CaseStatusConfirmedFraud, CaseStatusDismissed},
+	CaseStatusConfirmedFraud: {CaseStatusInvestigating},
+	CaseStatusDismissed:      {CaseStatusInvestigating},
+}
+
+type CaseCard struct {
+	ID           int64  `json:"id"`
+	MaskedNumber string `json:"masked_number"`
+	Reported     bool   `json:"reported"`
+}
+
+type CaseSubject struct {
+	ID       int64  `json:"id"`
+	UserName string `json:"user_name"`
+}
+
+// CaseBundle is the self-contained export of a case handed over to investigators. RecentPayments are the payments of
+// the user with any card, from 30 days before the case was opened, as recorded by payment-service.
+type CaseBundle struct {
+	Case           repository.Case              `json:"case"`
+	Subject        CaseSubject                  `json:"subject"`
+	Cards          []CaseCard                   `json:"cards"`
+	Transactions   []repository.CaseTransaction `json:"transactions"`
+	RecentPayments []repository.Payment         `json:"recent_payments"`
+	Notes          []repository.CaseNote        `json:"notes"`
+	RelatedCases   []repository.Case            `json:"related_cases"`
+	ExportedAt     time.Time                    `json:"exported_at"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source case_service.go -destination mock/case_service_mock.go -package mock
+type CaseService interface {
+	OpenCase(userID int64, cardID int64, source string, transactions []repository.CaseTransaction) (int64, error)
+	ListCases(status string, assignee string) ([]repository.Case, error)
+	AttachTransaction(caseID int64, transaction repository.CaseTransaction) error
+	AddNote(caseID int64, author string, body string) error
+	AssignCase(caseID int64, assignee string) error
+	UpdateCaseStatus(caseID int64, status string) error
+	ExportCase(caseID int64) (CaseBundle, error)
+}
+
+type caseService struct {
+	caseRepository       repository.CaseRepository
+	userRepository       repository.UserRepository
+	cardRepository       repository.CardRepository
+	stolenCardRepository repository.StolenCardRepository
+	paymentRepository    repository.PaymentRepository
+}
+
+func NewCaseService(caseRepository repository.CaseRepository, userRepository repository.UserRepository, cardRepository repository.CardRepository, stolenCardRepository repository.StolenCardRepository,
+	paymentRepository repository.PaymentRepository) CaseService {
+	return &caseService{
+		caseRepository:       caseRepository,
+		userRepository:       userRepository,
+		cardRepository:       cardRepository,
+		stolenCardRepository: stolenCardRepository,
+		paymentRepository:    paymentRepository,
+	}
+}
+
+func (s *caseService) OpenCase(userID int64, cardID int64, source string, transactions []repository.CaseTransaction) (int64, error) {
+	if !slices.Contains([]string{CaseSourceCardReport, CaseSourceHighRiskPayment, CaseSourceChargeback, CaseSourceManual}, source) {
+		return 0, ErrInvalidCaseSource
+	}
+
+	if err := s.checkCaseSubject(userID, cardID); err != nil {
+		return 0, err
+	}
+
+	checked, err := s.checkCaseTransactions(userID, transactions)
+	if err != nil {
+		return 0, err
+	}
+
+	caseID, err := s.caseRepository.CreateCase(userID, cardID, source)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, transaction := range checked {
+		if err := s.caseRepository.AttachTransaction(caseID, transaction); err != nil {
+			slog.Error("error attaching transaction to case", logging.TransactionID(transaction.TransactionID), slog.Int64("case_id", caseID), logging.Err(err))
+		}
+	}
+
+	return caseID, nil
+}
+
+func (s *caseService) ListCases(status string, assignee string) ([]repository.Case, error) {
+	return s.caseRepository.ListCases(status, assignee)
+}
+
+func (s *caseService) AttachTransaction(caseID int64, transaction repository.CaseTransaction) error {
+	c, err := s.getCase(caseID)
+	if err != nil {
+		return err
+	}
+
+	checked, err := s.checkCaseTransactions(c.UserID, []repository.CaseTransaction{transaction})
+	if err != nil {
+		return err
+	}
+	return s.caseRepository.AttachTransaction(caseID, checked[0])
+}
+
+func (s *caseService) AddNote(caseID int64, author string, body string) error {
+	if _, err := s.getCase(caseID); err != nil {
+		return err
+	}
+	return s.caseRepository.AddNote(caseID, author, body)
+}
+
+func (s *caseService) AssignCase(caseID int64, assignee string) error {
+	err := s.caseRepository.AssignCase(caseID, assignee)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCaseNotFound
+	}
+	return err
+}
+
+// UpdateCaseStatus moves the case to the status only from the statuses allowed to precede it, checked by the update
+// itself so a concurrent update cannot, for example, reopen a case closed in the meantime.
+func (s *caseService) UpdateCaseStatus(caseID int64, status string) error {
+	var fromStatuses []string
+	for fromStatus, toStatuses := range caseTransitions {
+		if slices.Contains(toStatuses, status) {
+			fromStatuses = append(fromStatuses, fromStatus)
+		}
+	}
+	slices.Sort(fromStatuses)
+
+	err := s.caseRepository.UpdateCaseStatus(caseID, status, fromStatuses)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	// the case is either missing or in a status the transition is not allowed from
+	if _, err := s.getCase(caseID); err != nil {
+		return err
+	}
+	return ErrInvalidCaseTransition
+}
+
+func (s *caseService) ExportCase(caseID int64) (CaseBundle, error) {
+	c, err := s.getCase(caseID)
+	if err != nil {
+		return CaseBundle{}, err
+	}
+
+	userName, err := s.userRepository.GetUserName(c.UserID)
+	if err != nil {
+		return CaseBundle{}, err
+	}
+
+	cards, err := s.cardRepository.GetUserCardDetails(c.UserID)
+	if err != nil {
+		return CaseBundle{}, err
+	}
+
+	caseCards := make([]CaseCard, 0, len(cards))
+	for _, card := range cards {
+		reported, err := s.stolenCardRepository.IsCardReported(c.UserID, card.ID)
+		if err != nil {
+			return CaseBundle{}, err
+		}
+		caseCards = append(caseCards, CaseCard{ID: card.ID, MaskedNumber: maskCardNumber(card.CardNumber), Reported: reported})
+	}
+
+	transactions, err := s.caseRepository.GetCaseTransactions(caseID)
+	if err != nil {
+		return CaseBundle{}, err
+	}
+
+	// a bundle without the payments would look like the user made none, so the export fails instead
+	payments, err := s.paymentRepository.ListUserPayments(c.UserID, c.CreatedAt.Add(-casePaymentsLookback))
+	if err != nil {
+		return CaseBundle{}, fmt.Errorf("error retrieving the payments of the user: %w", err)
+	}
+
+	notes, err := s.caseRepository.GetCaseNotes(caseID)
+	if err != nil {
+		return CaseBundle{}, err
+	}
+
+	userCases, err := s.caseRepository.GetUserCases(c.UserID)
+	if err != nil {
+		return CaseBundle{}, err
+	}
+
+	relatedCases := []repository.Case{}
+	for _, userCase := range userCases {
+		if userCase.ID != caseID {
+			relatedCases = append(relatedCases, userCase)
+		}
+	}
+
+	return CaseBundle{
+		Case:           c,
+		Subject:        CaseSubject{ID: c.UserID, UserName: userName},
+		Cards:          caseCards,
+		Transactions:   transactions,
+		RecentPayments: payments,
+		Notes:          notes,
+		RelatedCases:   relatedCases,
+		ExportedAt:     time.Now().UTC(),
+	}, nil
+}
+
+// checkCaseSubject makes sure the user of a case exists and owns its card, when the case is about one.
+func (s *caseService) checkCaseSubject(userID int64, cardID int64) error {
+	if _, err := s.userRepository.GetUserName(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if cardID == 0 {
+		return nil
+	}
+
+	cards, err := s.cardRepository.GetUserCardDetails(userID)
+	if err != nil {
+		return err
+	}
+	for _, card := range cards {
+		if card.ID == cardID {
+			return nil
+		}
+	}
+	return ErrCardNotFound
+}
+
+// checkCaseTransactions looks the transactions up in the payments of the user recorded by payment-service, as they are
+// copied into the SARs. The amount and time left out of a transaction are taken from its payment.
+func (s *caseService) checkCaseTransactions(userID int64, transactions []repository.CaseTransaction) ([]repository.CaseTransaction, error) {
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	since := time.Now().UTC().Add(-casePaymentsLookback)
+	for _, transaction := range transactions {
+		if transaction.OccurredAt != nil && transaction.OccurredAt.Before(since) {
+			since = *transaction.OccurredAt
+		}
+	}
+
+	payments, err := s.paymentRepository.ListUserPayments(userID, since.Truncate(time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the payments of the user: %w", err)
+	}
+
+	paymentsByID := make(map[string]repository.Payment, len(payments))
+	for _, payment := range payments {
+		paymentsByID[payment.ID] = payment
+	}
+
+	checked := make([]repository.CaseTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		payment, found := paymentsByID[transaction.TransactionID]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrCaseTransactionNotFound, transaction.TransactionID)
+		}
+
+		// the times are compared to the second, the precision some clients send them with
+		if transaction.CardID != payment.CardID || (transaction.Amount != 0 && transaction.Amount != payment.Amount) ||
+			(transaction.OccurredAt != nil && !transaction.OccurredAt.Truncate(time.Second).Equal(payment.CreatedAt.Truncate(time.Second))) {
+			return nil, fmt.Errorf("%w: %s", ErrCaseTransactionMismatch, transaction.TransactionID)
+		}
+
+		occurredAt := payment.CreatedAt
+		transaction.Amount, transaction.OccurredAt = payment.Amount, &occurredAt
+		checked = append(checked, transaction)
+	}
+
+	return checked, nil
+}
+
+func (s *caseService) getCase(caseID int64) (repository.Case, error) {
+	c, err := s.caseRepository.GetCase(caseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Case{}, ErrCaseNotFound
+	}
+	return c, err
+}
+
+// maskCardNumber hides every digit of the card number except the last four.
+func maskCardNumber(cardNumber string) string {
+	masked := []rune(cardNumber)
+	digits := 0
+	for i := len(masked) - 1; i >= 0; i-- {
+		if masked[i] < '0' || masked[i] > '9' {
+			continue
+		}
+		digits++
+		if digits > 4 {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
@@ -0,0 +1,507 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type caseDepFields struct {
+	caseRepositoryMock       *mock.MockCaseRepository
+	userRepositoryMock       *mock.MockUserRepository
+	cardRepositoryMock       *mock.MockCardRepository
+	stolenCardRepositoryMock *mock.MockStolenCardRepository
+	paymentRepositoryMock    *mock.MockPaymentRepository
+}
+
+func newTestCaseService(ctrl *gomock.Controller) (*caseService, *caseDepFields) {
+	dep := &caseDepFields{
+		caseRepositoryMock:       mock.NewMockCaseRepository(ctrl),
+		userRepositoryMock:       mock.NewMockUserRepository(ctrl),
+		cardRepositoryMock:       mock.NewMockCardRepository(ctrl),
+		stolenCardRepositoryMock: mock.NewMockStolenCardRepository(ctrl),
+		paymentRepositoryMock:    mock.NewMockPaymentRepository(ctrl),
+	}
+
+	return &caseService{
+		caseRepository:       dep.caseRepositoryMock,
+		userRepository:       dep.userRepositoryMock,
+		cardRepository:       dep.cardRepositoryMock,
+		stolenCardRepository: dep.stolenCardRepositoryMock,
+		paymentRepository:    dep.paymentRepositoryMock,
+	}, dep
+}
+
+func TestOpenCase(t *testing.T) {
+	occurredAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	type input struct {
+		userID       int64
+		cardID       int64
+		source       string
+		transactions []repository.CaseTransaction
+	}
+
+	type output struct {
+		caseID int64
+		err    error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*caseDepFields, input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Case opened with transactions",
+			input: input{
+				userID:       1,
+				cardID:       2,
+				source:       CaseSourceHighRiskPayment,
+				transactions: []repository.CaseTransaction{{TransactionID: "txn_1", CardID: 2, Amount: 10}, {TransactionID: "txn_2", CardID: 2, OccurredAt: &occurredAt}},
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("john_doe", nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.userID).Return([]repository.Card{{ID: 1}, {ID: 2}}, nil)
+				dep.paymentRepositoryMock.EXPECT().ListUserPayments(in.userID, gomock.Any()).Return([]repository.Payment{
+					{ID: "txn_2", CardID: 2, Amount: 20, CreatedAt: occurredAt},
+					{ID: "txn_1", CardID: 2, Amount: 10, CreatedAt: occurredAt.Add(-time.Hour)},
+				}, nil)
+				dep.caseRepositoryMock.EXPECT().CreateCase(in.userID, in.cardID, in.source).Return(int64(5), nil)
+				firstOccurredAt, secondOccurredAt := occurredAt.Add(-time.Hour), occurredAt
+				dep.caseRepositoryMock.EXPECT().AttachTransaction(int64(5), repository.CaseTransaction{TransactionID: "txn_1", CardID: 2, Amount: 10,
+					OccurredAt: &firstOccurredAt}).Return(nil)
+				dep.caseRepositoryMock.EXPECT().AttachTransaction(int64(5), repository.CaseTransaction{TransactionID: "txn_2", CardID: 2, Amount: 20,
+					OccurredAt: &secondOccurredAt}).Return(errors.New("UNIQUE constraint failed"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, int64(5), out.caseID)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Invalid source",
+			input: input{
+				userID: 1,
+				source: "unknown",
+			},
+			on: func(dep *caseDepFields, in input) {},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.ErrorIs(t, out.err, ErrInvalidCaseSource)
+			},
+		},
+		{
+			name: "Failure - Unknown user",
+			input: input{
+				userID: 99,
+				source: CaseSourceManual,
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("", sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.ErrorIs(t, out.err, ErrUserNotFound)
+			},
+		},
+		{
+			name: "Failure - Card of another user",
+			input: input{
+				userID: 1,
+				cardID: 3,
+				source: CaseSourceManual,
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("john_doe", nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.userID).Return([]repository.Card{{ID: 1}, {ID: 2}}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.ErrorIs(t, out.err, ErrCardNotFound)
+			},
+		},
+		{
+			name: "Failure - Transaction unknown to payment-service",
+			input: input{
+				userID:       1,
+				source:       CaseSourceManual,
+				transactions: []repository.CaseTransaction{{TransactionID: "txn_9", CardID: 2, Amount: 10}},
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("john_doe", nil)
+				dep.paymentRepositoryMock.EXPECT().ListUserPayments(in.userID, gomock.Any()).Return([]repository.Payment{{ID: "txn_1", CardID: 2, Amount: 10}}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.ErrorIs(t, out.err, ErrCaseTransactionNotFound)
+			},
+		},
+		{
+			name: "Failure - Transaction amount differs from the payment",
+			input: input{
+				userID:       1,
+				source:       CaseSourceManual,
+				transactions: []repository.CaseTransaction{{TransactionID: "txn_1", CardID: 2, Amount: 9999}},
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("john_doe", nil)
+				dep.paymentRepositoryMock.EXPECT().ListUserPayments(in.userID, gomock.Any()).Return([]repository.Payment{{ID: "txn_1", CardID: 2, Amount: 10}}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.ErrorIs(t, out.err, ErrCaseTransactionMismatch)
+			},
+		},
+		{
+			name: "Failure - Transaction time differs from the payment",
+			input: input{
+				userID:       1,
+				source:       CaseSourceManual,
+				transactions: []repository.CaseTransaction{{TransactionID: "txn_1", CardID: 2, OccurredAt: &occurredAt}},
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("john_doe", nil)
+				dep.paymentRepositoryMock.EXPECT().ListUserPayments(in.userID, gomock.Any()).Return([]repository.Payment{
+					{ID: "txn_1", CardID: 2, Amount: 10, CreatedAt: occurredAt.Add(-24 * time.Hour)},
+				}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.ErrorIs(t, out.err, ErrCaseTransactionMismatch)
+			},
+		},
+		{
+			name: "Failure - Payment service unavailable",
+			input: input{
+				userID:       1,
+				source:       CaseSourceManual,
+				transactions: []repository.CaseTransaction{{TransactionID: "txn_1", CardID: 2, Amount: 10}},
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("john_doe", nil)
+				dep.paymentRepositoryMock.EXPECT().ListUserPayments(in.userID, gomock.Any()).Return(nil, errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.EqualError(t, out.err, "error retrieving the payments of the user: connection refused")
+			},
+		},
+		{
+			name: "Failure - Database error",
+			input: input{
+				userID: 1,
+				source: CaseSourceManual,
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUserName(in.userID).Return("john_doe", nil)
+				dep.caseRepositoryMock.EXPECT().CreateCase(in.userID, in.cardID, in.source).Return(int64(0), errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.caseID)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestCaseService(ctrl)
+			tt.on(dep, tt.input)
+
+			caseID, err := service.OpenCase(tt.input.userID, tt.input.cardID, tt.input.source, tt.input.transactions)
+			tt.assertFunc(t, output{caseID, err})
+		})
+	}
+}
+
+func TestUpdateCaseStatus(t *testing.T) {
+	type input struct {
+		caseID int64
+		status string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*caseDepFields, input)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Open case moves to investigating",
+			input: input{
+				caseID: 5,
+				status: CaseStatusInvestigating,
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.caseRepositoryMock.EXPECT().UpdateCaseStatus(in.caseID, in.status,
+					[]string{CaseStatusConfirmedFraud, CaseStatusDismissed, CaseStatusOpen}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Open case cannot be confirmed as fraud without investigation",
+			input: input{
+				caseID: 5,
+				status: CaseStatusConfirmedFraud,
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.caseRepositoryMock.EXPECT().UpdateCaseStatus(in.caseID, in.status, []string{CaseStatusInvestigating}).Return(sql.ErrNoRows)
+				dep.caseRepositoryMock.EXPECT().GetCase(in.caseID).Return(repository.Case{ID: 5, Status: CaseStatusOpen}, nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrInvalidCaseTransition)
+			},
+		},
+		{
+			name: "Failure - Unknown status",
+			input: input{
+				caseID: 5,
+				status: "archived",
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.caseRepositoryMock.EXPECT().UpdateCaseStatus(in.caseID, in.status, nil).Return(sql.ErrNoRows)
+				dep.caseRepositoryMock.EXPECT().GetCase(in.caseID).Return(repository.Case{ID: 5, Status: CaseStatusInvestigating}, nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrInvalidCaseTransition)
+			},
+		},
+		{
+			name: "Failure - Case not found",
+			input: input{
+				caseID: 99,
+				status: CaseStatusInvestigating,
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.caseRepositoryMock.EXPECT().UpdateCaseStatus(in.caseID, in.status, gomock.Any()).Return(sql.ErrNoRows)
+				dep.caseRepositoryMock.EXPECT().GetCase(in.caseID).Return(repository.Case{}, sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrCaseNotFound)
+			},
+		},
+		{
+			name: "Failure - Dismissed case not reopened by a concurrent update",
+			input: input{
+				caseID: 5,
+				status: CaseStatusOpen,
+			},
+			on: func(dep *caseDepFields, in input) {
+				// the case was read as investigating, but dismissed before the update
+				dep.caseRepositoryMock.EXPECT().UpdateCaseStatus(in.caseID, in.status, []string{CaseStatusInvestigating}).Return(sql.ErrNoRows)
+				dep.caseRepositoryMock.EXPECT().GetCase(in.caseID).Return(repository.Case{ID: 5, Status: CaseStatusDismissed}, nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrInvalidCaseTransition)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			input: input{
+				caseID: 5,
+				status: CaseStatusInvestigating,
+			},
+			on: func(dep *caseDepFields, in input) {
+				dep.caseRepositoryMock.EXPECT().UpdateCaseStatus(in.caseID, in.status, gomock.Any()).Return(errors.New("database locked"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database locked")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestCaseService(ctrl)
+			tt.on(dep, tt.input)
+
+			err := service.UpdateCaseStatus(tt.input.caseID, tt.input.status)
+			tt.assertFunc(t, err)
+		})
+	}
+}
+
+func TestAssignCase(t *testing.T) {
+	tests := []struct {
+		name       string
+		on         func(*caseDepFields)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Case assigned",
+			on: func(dep *caseDepFields) {
+				dep.caseRepositoryMock.EXPECT().AssignCase(int64(5), "alice").Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Case not found",
+			on: func(dep *caseDepFields) {
+				dep.caseRepositoryMock.EXPECT().AssignCase(int64(5), "alice").Return(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrCaseNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestCaseService(ctrl)
+			tt.on(dep)
+
+			tt.assertFunc(t, service.AssignCase(5, "alice"))
+		})
+	}
+}
+
+func TestAddNoteAndAttachTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, dep := newTestCaseService(ctrl)
+	transaction := repository.CaseTransaction{TransactionID: "txn_1", CardID: 2, Amount: 10}
+	occurredAt := time.Now().UTC().Add(-time.Hour)
+
+	dep.caseRepositoryMock.EXPECT().GetCase(int64(5)).Return(repository.Case{ID: 5, UserID: 1}, nil).Times(3)
+	dep.caseRepositoryMock.EXPECT().AddNote(int64(5), "alice", "called the customer").Return(nil)
+	dep.paymentRepositoryMock.EXPECT().ListUserPayments(int64(1), gomock.Any()).Return([]repository.Payment{
+		{ID: "txn_1", CardID: 2, Amount: 10, CreatedAt: occurredAt},
+	}, nil).Times(2)
+	dep.caseRepositoryMock.EXPECT().AttachTransaction(int64(5), repository.CaseTransaction{TransactionID: "txn_1", CardID: 2, Amount: 10,
+		OccurredAt: &occurredAt}).Return(nil)
+	dep.caseRepositoryMock.EXPECT().GetCase(int64(99)).Return(repository.Case{}, sql.ErrNoRows).Times(2)
+
+	assert.NoError(t, service.AddNote(5, "alice", "called the customer"))
+	assert.NoError(t, service.AttachTransaction(5, transaction))
+	// the card of the payment is not the card of the transaction
+	assert.ErrorIs(t, service.AttachTransaction(5, repository.CaseTransaction{TransactionID: "txn_1", CardID: 3, Amount: 10}), ErrCaseTransactionMismatch)
+	assert.ErrorIs(t, service.AddNote(99, "alice", "called the customer"), ErrCaseNotFound)
+	assert.ErrorIs(t, service.AttachTransaction(99, transaction), ErrCaseNotFound)
+}
+
+func TestExportCase(t *testing.T) {
+	openedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type output struct {
+		bundle CaseBundle
+		err    error
+	}
+
+	tests := []struct {
+		name       string
+		on         func(*caseDepFields)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Bundle with cards, transactions, payments, notes and related cases",
+			on: func(dep *caseDepFields) {
+				dep.caseRepositoryMock.EXPECT().GetCase(int64(5)).Return(repository.Case{ID: 5, UserID: 1, Source: CaseSourceCardReport, Status: CaseStatusOpen,
+					CreatedAt: openedAt}, nil)
+				dep.userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return([]repository.Card{
+					{ID: 1, CardNumber: "1234-5678-9012-3456"},
+					{ID: 2, CardNumber: "9876-5432-1098-7654"},
+				}, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(int64(1), int64(1)).Return(true, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(int64(1), int64(2)).Return(false, nil)
+				dep.caseRepositoryMock.EXPECT().GetCaseTransactions(int64(5)).Return([]repository.CaseTransaction{{TransactionID: "txn_1", CardID: 1, Amount: 10}}, nil)
+				dep.paymentRepositoryMock.EXPECT().ListUserPayments(int64(1), openedAt.Add(-30*24*time.Hour)).Return([]repository.Payment{
+					{ID: "txn_2", CardID: 2, Amount: 25, Status: "approved"},
+					{ID: "txn_1", CardID: 1, Amount: 10, Status: "approved", SuspectedFraud: true},
+				}, nil)
+				dep.caseRepositoryMock.EXPECT().GetCaseNotes(int64(5)).Return([]repository.CaseNote{{Author: "alice", Body: "called the customer"}}, nil)
+				dep.caseRepositoryMock.EXPECT().GetUserCases(int64(1)).Return([]repository.Case{{ID: 5, UserID: 1}, {ID: 3, UserID: 1}}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, CaseSubject{ID: 1, UserName: "john_doe"}, out.bundle.Subject)
+				assert.Equal(t, []CaseCard{
+					{ID: 1, MaskedNumber: "****-****-****-3456", Reported: true},
+					{ID: 2, MaskedNumber: "****-****-****-7654", Reported: false},
+				}, out.bundle.Cards)
+				assert.Len(t, out.bundle.Transactions, 1)
+				assert.Equal(t, []string{"txn_2", "txn_1"}, []string{out.bundle.RecentPayments[0].ID, out.bundle.RecentPayments[1].ID})
+				assert.Len(t, out.bundle.Notes, 1)
+				assert.Equal(t, []repository.Case{{ID: 3, UserID: 1}}, out.bundle.RelatedCases)
+				assert.False(t, out.bundle.ExportedAt.IsZero())
+			},
+		},
+		{
+			name: "Failure - Case not found",
+			on: func(dep *caseDepFields) {
+				dep.caseRepositoryMock.EXPECT().GetCase(int64(5)).Return(repository.Case{}, sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.bundle)
+				assert.ErrorIs(t, out.err, ErrCaseNotFound)
+			},
+		},
+		{
+			name: "Failure - Payment service unavailable",
+			on: func(dep *caseDepFields) {
+				dep.caseRepositoryMock.EXPECT().GetCase(int64(5)).Return(repository.Case{ID: 5, UserID: 1, CreatedAt: openedAt}, nil)
+				dep.userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(nil, nil)
+				dep.caseRepositoryMock.EXPECT().GetCaseTransactions(int64(5)).Return(nil, nil)
+				dep.paymentRepositoryMock.EXPECT().ListUserPayments(int64(1), gomock.Any()).Return(nil, errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.bundle)
+				assert.EqualError(t, out.err, "error retrieving the payments of the user: connection refused")
+			},
+		},
+		{
+			name: "Failure - Error retrieving user cards",
+			on: func(dep *caseDepFields) {
+				dep.caseRepositoryMock.EXPECT().GetCase(int64(5)).Return(repository.Case{ID: 5, UserID: 1}, nil)
+				dep.userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.bundle)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestCaseService(ctrl)
+			tt.on(dep)
+
+			bundle, err := service.ExportCase(5)
+			tt.assertFunc(t, output{bundle, err})
+		})
+	}
+}
+
+func TestMaskCardNumber(t *testing.T) {
+	assert.Equal(t, "****-****-****-3456", maskCardNumber("1234-5678-9012-3456"))
+	assert.Equal(t, "************3456", maskCardNumber("1234567890123456"))
+	assert.Equal(t, "123", maskCardNumber("123"))
+}
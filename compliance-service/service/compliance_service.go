@@ -1,33 +1,145 @@
 package service
 
 import (
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"flarrocca/compliant-service/repository"
+	"flarrocca/logging"
 	"fmt"
-
-	"slices"
+	"log/slog"
+	"sort"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// BlockSourceCardReport marks the cards blocked because their owner reported them as stolen.
+	BlockSourceCardReport = "card_report"
+
+	// MaxBatchComplianceChecks caps the payments checked by a single batch, so its queries stay within the SQLite limits.
+	MaxBatchComplianceChecks = 1000
+
+	kycRejectedMessage = "user identity verification was rejected"
+)
+
+var (
+	ErrCardNotFound       = errors.New("card not found")
+	ErrCardNotBlocked     = errors.New("card is not blocked")
+	ErrInvalidBatchCheck  = errors.New("invalid batch compliance check")
+	ErrInvalidCredentials = errors.New("invalid user name or secret code")
+)
+
+// ComplianceCheck describes the payment being checked. Only the user and card are required, the amount is checked
+// against the KYC tier limits and the limits of high-risk users, and the other details are matched against the deny
+// and allow lists when provided.
+type ComplianceCheck struct {
+	UserID     int64
+	CardID     int64
+	Amount     float64
+	IPAddress  string
+	Email      string
+	DeviceID   string
+	MerchantID string
+}
+
+// ComplianceResult tells whether the payment can go through. A payment held for manual review is not compliant
+// until a reviewer approves it, so it is never approved by a client unaware of ManualReview. Limits is only set when
+// the verdict depends on the amount alone: a verdict without limits applies to any amount.
+type ComplianceResult struct {
+	IsCompliance   bool                   `json:"compliant"`
+	Message        string                 `json:"message"`
+	RiskRating     string                 `json:"risk_rating,omitempty"`
+	ManualReview   bool                   `json:"manual_review,omitempty"`
+	Limits         *PaymentLimits         `json:"limits,omitempty"`
+	MatchedEntries []repository.ListEntry `json:"matched_entries,omitempty"`
+}
+
+// PaymentLimits are the amount limits of a user who passed every other check, returned so that a client caching the
+// verdict can apply them to the amount of the next payments. 0 means no limit; the high-risk limits are only set for
+// the users rated high risk.
+type PaymentLimits struct {
+	KYCStatus     string  `json:"kyc_status"`
+	KYCTier       int     `json:"kyc_tier"`
+	KYCLimit      float64 `json:"kyc_limit,omitempty"`
+	HighRiskLimit float64 `json:"high_risk_limit,omitempty"`
+	ReviewAmount  float64 `json:"review_amount,omitempty"`
+}
+
+// verdict applies the limits to the amount of a payment of a user with the risk rating.
+func (l PaymentLimits) verdict(rating string, amount float64) ComplianceResult {
+	switch {
+	case l.KYCLimit != 0 && amount > l.KYCLimit:
+		return ComplianceResult{
+			Message: fmt.Sprintf("payment amount %.2f exceeds the %.2f limit of KYC tier %d (%s), a higher verification tier is required",
+				amount, l.KYCLimit, l.KYCTier, l.KYCStatus),
+			RiskRating: rating,
+		}
+	case l.HighRiskLimit != 0 && amount > l.HighRiskLimit:
+		return ComplianceResult{
+			Message:    fmt.Sprintf("payment amount %.2f exceeds the %.2f limit of high-risk users", amount, l.HighRiskLimit),
+			RiskRating: rating,
+		}
+	case l.ReviewAmount != 0 && amount >= l.ReviewAmount:
+		return ComplianceResult{
+			Message:      fmt.Sprintf("payment amount %.2f of a high-risk user requires manual review", amount),
+			RiskRating:   rating,
+			ManualReview: true,
+		}
+	}
+
+	return ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: rating}
+}
+
+// BatchComplianceResult is the verdict of one of the payments of a batch check.
+type BatchComplianceResult struct {
+	UserID int64 `json:"user_id"`
+	CardID int64 `json:"card_id"`
+	ComplianceResult
+}
+
 // Run from the /service folder the following command to generate the mock:
 // mockgen -source compliance_service.go -destination mock/compliance_service_mock.go -package mock
 type ComplianceService interface {
 	ReportStolenCards(userName, secretCode string) (string, error)
-	CheckComplianceStatus(userID int64, cardID int64) (bool, string, error)
+	CheckComplianceStatus(check ComplianceCheck) (ComplianceResult, error)
+	CheckComplianceStatuses(checks []ComplianceCheck) ([]BatchComplianceResult, error)
+	ListBlockedCards() ([]repository.BlockedCard, error)
+	ReinstateCard(userID int64, cardID int64) error
+	NotifyCardReported(event repository.Event) error
+	InvalidateCardVerdicts(event repository.Event) error
 }
 
 type complianceService struct {
 	userRepository       repository.UserRepository
 	cardRepository       repository.CardRepository
 	stolenCardRepository repository.StolenCardRepository
+	caseRepository       repository.CaseRepository
+	paymentRepository    repository.PaymentRepository
+	listEntryRepository  repository.ListEntryRepository
+	sanctionsRepository  repository.SanctionsRepository
+	kycRepository        repository.KYCRepository
+	pepRepository        repository.PEPRepository
+	kycTierLimits        kycTierLimits
+	highRiskLimits       highRiskLimits
 }
 
-func NewComplianceService(userRepository repository.UserRepository, cardRepository repository.CardRepository, stolenCardRepository repository.StolenCardRepository) ComplianceService {
+func NewComplianceService(userRepository repository.UserRepository, cardRepository repository.CardRepository, stolenCardRepository repository.StolenCardRepository,
+	caseRepository repository.CaseRepository, paymentRepository repository.PaymentRepository, listEntryRepository repository.ListEntryRepository,
+	sanctionsRepository repository.SanctionsRepository, kycRepository repository.KYCRepository, pepRepository repository.PEPRepository) ComplianceService {
 	return &complianceService{
 		userRepository:       userRepository,
 		cardRepository:       cardRepository,
 		stolenCardRepository: stolenCardRepository,
+		caseRepository:       caseRepository,
+		paymentRepository:    paymentRepository,
+		listEntryRepository:  listEntryRepository,
+		sanctionsRepository:  sanctionsRepository,
+		kycRepository:        kycRepository,
+		pepRepository:        pepRepository,
+		kycTierLimits:        loadKYCTierLimits(),
+		highRiskLimits:       loadHighRiskLimits(),
 	}
 }
 
@@ -35,24 +147,40 @@ func (s *complianceService) ReportStolenCards(userName, secretCode string) (stri
 	userID, hashedSecret, err := s.userRepository.GetUser(userName)
 	if err != nil {
 		if err.Error() == "sql: no rows in result set" {
-			return "", errors.New("user not found")
+			failedLoginsTotal.WithLabelValues(failedLoginUnknownUser).Inc()
+			return "", ErrUserNotFound
 		}
 		return "", err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(hashedSecret), []byte(secretCode)); err != nil {
-		return "", errors.New("invalid user name or secret code")
+		failedLoginsTotal.WithLabelValues(failedLoginInvalidSecret).Inc()
+		return "", ErrInvalidCredentials
 	}
 
-	cardIDs, err := s.cardRepository.GetUserCards(userID)
+	cards, err := s.cardRepository.GetUserCardDetails(userID)
 	if err != nil {
 		return "", err
 	}
 
-	if len(cardIDs) == 0 {
+	if len(cards) == 0 {
 		return "no cards found for the user.", nil
 	}
 
+	cardIDs := make([]int64, 0, len(cards))
+	cardFingerprints := make([]string, 0, len(cards))
+	for _, card := range cards {
+		cardIDs = append(cardIDs, card.ID)
+		cardFingerprints = append(cardFingerprints, CardFingerprint(card.CardNumber))
+	}
+
+	// The PANs are blocked first, so a report failing afterwards can be submitted again without leaving the cards usable.
+	if err := s.stolenCardRepository.BlockCards(cardFingerprints, BlockSourceCardReport); err != nil {
+		return "", err
+	}
+
+	// payment-service is told about the report, and drops the verdicts it cached for the cards, by the consumers of the
+	// CardBlocked and CardReported events written with it, retrying until they are delivered.
 	err = s.stolenCardRepository.ReportStolenCards(userID, cardIDs)
 	if err != nil {
 		if err.Error() == "UNIQUE constraint failed: reported_cards.user_id, reported_cards.card_id" {
@@ -60,35 +188,340 @@ func (s *complianceService) ReportStolenCards(userName, secretCode string) (stri
 		}
 		return "", err
 	}
+	cardsReportedTotal.Add(float64(len(cardIDs)))
+
+	// The cards are already blocked at this point, the follow-up actions must not fail the report.
+	if _, err := s.caseRepository.CreateCase(userID, 0, CaseSourceCardReport); err != nil {
+		slog.Error("error opening case", logging.UserID(userID), logging.Err(err))
+	}
 
 	return "all the cards linked to the provided user are now blocked. Contact @support-team for more information.", nil
 }
 
-func (s *complianceService) CheckComplianceStatus(userID int64, cardID int64) (bool, string, error) {
-	owned, err := s.isCardOwnedByUser(userID, cardID)
+// NotifyCardReported hands a CardReported event of the outbox to payment-service, so it flags the payments made with
+// the card before the report. The error makes the consumer retry the event.
+func (s *complianceService) NotifyCardReported(event repository.Event) error {
+	var reported repository.CardReportedEvent
+	if err := json.Unmarshal(event.Payload, &reported); err != nil {
+		// retrying would block the consumer on an event that can never be delivered
+		slog.Error("error decoding card report event", slog.Int64("event_id", event.ID), logging.Err(err))
+		return nil
+	}
+
+	return s.paymentRepository.NotifyCardsReported(reported.UserID, []int64{reported.CardID}, event.CreatedAt)
+}
+
+func (s *complianceService) CheckComplianceStatus(check ComplianceCheck) (ComplianceResult, error) {
+	card, owned, err := s.findUserCard(check.UserID, check.CardID)
 	if err != nil {
-		return false, "error retrieving user cards", err
+		return ComplianceResult{Message: "error retrieving user cards"}, err
 	}
 	if !owned {
-		return false, "the provided card does not belong to the user", nil
+		return ComplianceResult{Message: "the provided card does not belong to the user"}, nil
+	}
+
+	// Only the hits confirmed by a reviewer block the user, the pending ones are false positives until proven otherwise.
+	sanctioned, err := s.sanctionsRepository.HasConfirmedHit(check.UserID)
+	if err != nil {
+		return ComplianceResult{Message: "error checking compliance status"}, err
+	}
+	if sanctioned {
+		return ComplianceResult{Message: "user matches a sanctions list entry"}, nil
 	}
 
-	blocked, err := s.stolenCardRepository.IsCardReported(userID, cardID)
+	blocked, err := s.stolenCardRepository.IsCardReported(check.UserID, check.CardID)
 	if err != nil {
-		return false, "error checking compliance status", err
+		return ComplianceResult{Message: "error checking compliance status"}, err
 	}
 	if blocked {
-		return false, "user is currently blocked due to reported stolen card/s", nil
+		return ComplianceResult{Message: "user is currently blocked due to reported stolen card/s"}, nil
+	}
+
+	// The same PAN may be linked to other users, or have been reported by an external feed.
+	cardBlocked, err := s.stolenCardRepository.IsCardBlocked(CardFingerprint(card.CardNumber))
+	if err != nil {
+		return ComplianceResult{Message: "error checking compliance status"}, err
+	}
+	if cardBlocked {
+		return ComplianceResult{Message: "card is blocked due to being reported as stolen or compromised"}, nil
+	}
+
+	matches, denied, err := matchListEntries(s.listEntryRepository, ListAttributes{
+		CardNumber: card.CardNumber,
+		IPAddress:  check.IPAddress,
+		Email:      check.Email,
+		DeviceID:   check.DeviceID,
+		MerchantID: check.MerchantID,
+	}, time.Now().UTC())
+	if err != nil {
+		return ComplianceResult{Message: "error checking deny lists"}, err
+	}
+	if denied != nil {
+		return ComplianceResult{
+			Message:        fmt.Sprintf("payment blocked by deny list: %s %s (%s)", denied.EntryType, denied.Value, denied.Reason),
+			MatchedEntries: matches,
+		}, nil
+	}
+
+	// users without a profile are unverified
+	profile, err := s.kycRepository.GetProfile(check.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ComplianceResult{Message: "error checking KYC status", MatchedEntries: matches}, err
+	}
+	if profile.Status == KYCStatusRejected {
+		return ComplianceResult{Message: kycRejectedMessage, MatchedEntries: matches}, nil
+	}
+
+	rating, err := s.pepRepository.GetRating(check.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ComplianceResult{Message: "error checking risk rating", MatchedEntries: matches}, err
+	}
+	if err != nil {
+		// users are rated when registered and at each refresh of the PEP list, a user not rated yet has no match
+		rating = RiskRatingLow
 	}
 
-	return true, "user is compliance", nil
+	return s.limitedResult(profile.Status, profile.Tier, rating, check.Amount, matches), nil
+}
+
+// limitedResult is the verdict of a payment that passed every other check, given the KYC profile and the risk rating of
+// the user, returned with the limits it was decided on.
+func (s *complianceService) limitedResult(kycStatus string, kycTier int, rating string, amount float64, matches []repository.ListEntry) ComplianceResult {
+	limits := s.paymentLimits(kycStatus, kycTier, rating)
+	result := limits.verdict(rating, amount)
+	result.Limits = &limits
+	result.MatchedEntries = matches
+	return result
+}
+
+// paymentLimits returns the limits of a user with a KYC profile that is not rejected. Users without a profile, given
+// with an empty status, or without a verified profile get the limit of KYCTierNone.
+func (s *complianceService) paymentLimits(kycStatus string, kycTier int, rating string) PaymentLimits {
+	if kycStatus == "" {
+		kycStatus = KYCStatusUnverified
+	}
+	if kycStatus != KYCStatusVerified {
+		kycTier = KYCTierNone
+	}
+
+	limits := PaymentLimits{KYCStatus: kycStatus, KYCTier: kycTier, KYCLimit: s.kycTierLimits[kycTier]}
+	if rating == RiskRatingHigh {
+		limits.HighRiskLimit, limits.ReviewAmount = s.highRiskLimits.paymentLimit, s.highRiskLimits.reviewAmount
+	}
+	return limits
 }
 
-func (s *complianceService) isCardOwnedByUser(userID int64, cardID int64) (bool, error) {
-	cardIDs, err := s.cardRepository.GetUserCards(userID)
+// CheckComplianceStatuses runs the checks of CheckComplianceStatus for up to MaxBatchComplianceChecks payments, with a
+// fixed number of queries whatever the size of the batch. The results are returned in the order of the checks.
+func (s *complianceService) CheckComplianceStatuses(checks []ComplianceCheck) ([]BatchComplianceResult, error) {
+	if len(checks) == 0 || len(checks) > MaxBatchComplianceChecks {
+		return nil, fmt.Errorf("%w: between 1 and %d checks are required", ErrInvalidBatchCheck, MaxBatchComplianceChecks)
+	}
+
+	cards := make([]repository.UserCard, 0, len(checks))
+	for _, check := range checks {
+		cards = append(cards, repository.UserCard{UserID: check.UserID, CardID: check.CardID})
+	}
+
+	statuses, err := s.cardRepository.GetCardStatuses(cards)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	byCard := make(map[repository.UserCard]repository.CardStatus, len(statuses))
+	cardFingerprints := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		byCard[repository.UserCard{UserID: status.UserID, CardID: status.CardID}] = status
+		cardFingerprints = append(cardFingerprints, CardFingerprint(status.CardNumber))
+	}
+
+	blockedFingerprints, err := s.stolenCardRepository.FindBlockedFingerprints(cardFingerprints)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[string]bool, len(blockedFingerprints))
+	for _, cardFingerprint := range blockedFingerprints {
+		blocked[cardFingerprint] = true
+	}
+
+	attributes := make([]ListAttributes, len(checks))
+	for i, check := range checks {
+		attributes[i] = ListAttributes{
+			CardNumber: byCard[cards[i]].CardNumber,
+			IPAddress:  check.IPAddress,
+			Email:      check.Email,
+			DeviceID:   check.DeviceID,
+			MerchantID: check.MerchantID,
+		}
+	}
+	matches, denied, err := matchListEntriesBatch(s.listEntryRepository, attributes, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchComplianceResult, len(checks))
+	for i, check := range checks {
+		results[i] = BatchComplianceResult{UserID: check.UserID, CardID: check.CardID}
+
+		status, owned := byCard[cards[i]]
+		switch {
+		case !owned:
+			results[i].Message = "the provided card does not belong to the user"
+		case status.Sanctioned:
+			results[i].Message = "user matches a sanctions list entry"
+		case status.Reported:
+			results[i].Message = "user is currently blocked due to reported stolen card/s"
+		case blocked[CardFingerprint(status.CardNumber)]:
+			results[i].Message = "card is blocked due to being reported as stolen or compromised"
+		case denied[i] != nil:
+			results[i].Message = fmt.Sprintf("payment blocked by deny list: %s %s (%s)", denied[i].EntryType, denied[i].Value, denied[i].Reason)
+			results[i].MatchedEntries = matches[i]
+		case status.KYCStatus == KYCStatusRejected:
+			results[i].ComplianceResult = ComplianceResult{Message: kycRejectedMessage, MatchedEntries: matches[i]}
+		default:
+			rating := status.RiskRating
+			if rating == "" {
+				rating = RiskRatingLow
+			}
+			results[i].ComplianceResult = s.limitedResult(status.KYCStatus, status.KYCTier, rating, check.Amount, matches[i])
+		}
+	}
+
+	return results, nil
+}
+
+// ListBlockedCards returns the cards reported stolen by their owner and the cards of any user whose PAN is blocked,
+// ordered by user and card.
+func (s *complianceService) ListBlockedCards() ([]repository.BlockedCard, error) {
+	reported, err := s.stolenCardRepository.ListReportedCards()
+	if err != nil {
+		return nil, err
+	}
+
+	cardFingerprints, err := s.stolenCardRepository.ListBlockedFingerprints()
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := s.cardRepository.ListCards()
+	if err != nil {
+		return nil, err
+	}
+
+	blockedFingerprints := make(map[string]bool, len(cardFingerprints))
+	for _, cardFingerprint := range cardFingerprints {
+		blockedFingerprints[cardFingerprint] = true
+	}
+
+	seen := make(map[repository.BlockedCard]bool, len(reported))
+	blocked := make([]repository.BlockedCard, 0, len(reported))
+	for _, card := range reported {
+		seen[card] = true
+		blocked = append(blocked, card)
+	}
+	for _, card := range cards {
+		blockedCard := repository.BlockedCard{UserID: card.UserID, CardID: card.ID}
+		if blockedFingerprints[CardFingerprint(card.CardNumber)] && !seen[blockedCard] {
+			seen[blockedCard] = true
+			blocked = append(blocked, blockedCard)
+		}
+	}
+
+	sort.Slice(blocked, func(i, j int) bool {
+		if blocked[i].UserID != blocked[j].UserID {
+			return blocked[i].UserID < blocked[j].UserID
+		}
+		return blocked[i].CardID < blocked[j].CardID
+	})
+
+	return blocked, nil
+}
+
+// ReinstateCard lifts the block of a card found not to be compromised: its report is withdrawn and its PAN unblocked.
+// The other cards reported with it stay blocked.
+func (s *complianceService) ReinstateCard(userID int64, cardID int64) error {
+	card, owned, err := s.findUserCard(userID, cardID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return ErrCardNotFound
+	}
+
+	cardFingerprint := CardFingerprint(card.CardNumber)
+	if err := s.stolenCardRepository.ReinstateCard(userID, cardID, cardFingerprint); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCardNotBlocked
+		}
+		return err
+	}
+
+	// payment-service drops the verdicts cached for the PAN on the CardReinstated event written with the reinstatement
+	return nil
+}
+
+// InvalidateCardVerdicts hands a CardBlocked or CardReinstated event of the outbox to payment-service, so it checks
+// again the cards of every user linked to the PAN. The error makes the consumer retry the event, a blocked card is
+// never paid with on a verdict cached before its block.
+func (s *complianceService) InvalidateCardVerdicts(event repository.Event) error {
+	var changed struct {
+		CardFingerprint string `json:"card_fingerprint"`
+		ImportID        int64  `json:"import_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &changed); err != nil || changed.CardFingerprint == "" {
+		// retrying would block the consumer on an event that can never be delivered
+		slog.Error("error decoding card event", slog.Int64("event_id", event.ID), slog.String("type", event.Type), logging.Err(err))
+		return nil
+	}
+
+	// The cards of a compromised card feed are mostly unknown here, going through every card for each of them would
+	// cost more than checking every card again.
+	if changed.ImportID != 0 {
+		return s.paymentRepository.InvalidateAllVerdicts()
+	}
+
+	cards, err := s.cardRepository.ListCards()
+	if err != nil {
+		return err
+	}
+
+	var linked []repository.BlockedCard
+	for _, card := range cards {
+		if CardFingerprint(card.CardNumber) == changed.CardFingerprint {
+			linked = append(linked, repository.BlockedCard{UserID: card.UserID, CardID: card.ID})
+		}
+	}
+
+	return s.paymentRepository.InvalidateVerdicts(linked)
+}
+
+// invalidateUserVerdicts tells payment-service to check again every card of the users, once their sanctions, KYC or risk
+// status changed. The change is already stored, so a failure is only logged and the verdicts expire after their TTL.
+func invalidateUserVerdicts(paymentRepository repository.PaymentRepository, userIDs ...int64) {
+	if err := paymentRepository.InvalidateUserVerdicts(userIDs); err != nil {
+		slog.Error("error invalidating the verdicts cached by payment-service", slog.Any("user_ids", userIDs), logging.Err(err))
+	}
+}
+
+// invalidateAllVerdicts tells payment-service to check every card again, for changes that may affect any payment, such as
+// the deny and allow lists or a new version of the sanctions or PEP lists.
+func invalidateAllVerdicts(paymentRepository repository.PaymentRepository) {
+	if err := paymentRepository.InvalidateAllVerdicts(); err != nil {
+		slog.Error("error invalidating the verdicts cached by payment-service", logging.Err(err))
+	}
+}
+
+func (s *complianceService) findUserCard(userID int64, cardID int64) (repository.Card, bool, error) {
+	cards, err := s.cardRepository.GetUserCardDetails(userID)
+	if err != nil {
+		return repository.Card{}, false, err
+	}
+
+	for _, card := range cards {
+		if card.ID == cardID {
+			return card, true, nil
+		}
 	}
 
-	return slices.Contains(cardIDs, cardID), nil
+	return repository.Card{}, false, nil
 }
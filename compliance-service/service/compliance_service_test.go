@@ -1,15 +1,23 @@
 package service
 
 import (
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"flarrocca/compliant-service/repository"
 	"flarrocca/compliant-service/repository/mock"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestReportStolenCard(t *testing.T) {
+	cards := []repository.Card{{ID: 1, CardNumber: "4111-1111-1111-1111"}, {ID: 2, CardNumber: "5500-0000-0000-0004"}}
+	fingerprints := []string{CardFingerprint("4111111111111111"), CardFingerprint("5500000000000004")}
+
 	type input struct {
 		userName   string
 		secretCode string
@@ -24,6 +32,8 @@ func TestReportStolenCard(t *testing.T) {
 		userRepositoryMock       *mock.MockUserRepository
 		cardRepositoryMock       *mock.MockCardRepository
 		stolenCardRepositoryMock *mock.MockStolenCardRepository
+		caseRepositoryMock       *mock.MockCaseRepository
+		paymentRepositoryMock    *mock.MockPaymentRepository
 	}
 
 	tests := []struct {
@@ -40,8 +50,28 @@ func TestReportStolenCard(t *testing.T) {
 			},
 			on: func(dep *depFields, in input) {
 				dep.userRepositoryMock.EXPECT().GetUser(in.userName).Return(int64(1), "$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca", nil)
-				dep.cardRepositoryMock.EXPECT().GetUserCards(int64(1)).Return([]int64{1, 2}, nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().BlockCards(fingerprints, BlockSourceCardReport).Return(nil)
 				dep.stolenCardRepositoryMock.EXPECT().ReportStolenCards(int64(1), []int64{1, 2}).Return(nil)
+				dep.caseRepositoryMock.EXPECT().CreateCase(int64(1), int64(0), CaseSourceCardReport).Return(int64(10), nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, "all the cards linked to the provided user are now blocked. Contact @support-team for more information.", out.response)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Success - Cards blocked even if the follow-up actions fail",
+			input: input{
+				userName:   "john_doe",
+				secretCode: "hashed_secret_123",
+			},
+			on: func(dep *depFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUser(in.userName).Return(int64(1), "$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca", nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().BlockCards(fingerprints, BlockSourceCardReport).Return(nil)
+				dep.stolenCardRepositoryMock.EXPECT().ReportStolenCards(int64(1), []int64{1, 2}).Return(nil)
+				dep.caseRepositoryMock.EXPECT().CreateCase(int64(1), int64(0), CaseSourceCardReport).Return(int64(0), errors.New("database error"))
 			},
 			assertFunc: func(t *testing.T, out output) {
 				assert.Equal(t, "all the cards linked to the provided user are now blocked. Contact @support-team for more information.", out.response)
@@ -98,7 +128,8 @@ func TestReportStolenCard(t *testing.T) {
 			},
 			on: func(dep *depFields, in input) {
 				dep.userRepositoryMock.EXPECT().GetUser(in.userName).Return(int64(1), "$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca", nil)
-				dep.cardRepositoryMock.EXPECT().GetUserCards(int64(1)).Return([]int64{1, 2}, nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().BlockCards(fingerprints, BlockSourceCardReport).Return(nil)
 				dep.stolenCardRepositoryMock.EXPECT().ReportStolenCards(int64(1), []int64{1, 2}).Return(errors.New("UNIQUE constraint failed: reported_cards.user_id, reported_cards.card_id"))
 			},
 			assertFunc: func(t *testing.T, out output) {
@@ -106,6 +137,22 @@ func TestReportStolenCard(t *testing.T) {
 				assert.NoError(t, out.err)
 			},
 		},
+		{
+			name: "Failure - Error blocking the cards",
+			input: input{
+				userName:   "john_doe",
+				secretCode: "hashed_secret_123",
+			},
+			on: func(dep *depFields, in input) {
+				dep.userRepositoryMock.EXPECT().GetUser(in.userName).Return(int64(1), "$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca", nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().BlockCards(fingerprints, BlockSourceCardReport).Return(errors.New("database locked"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.EqualError(t, out.err, "database locked")
+			},
+		},
 		{
 			name: "Failure - Unexpected error in ReportStolenCards",
 			input: input{
@@ -114,7 +161,8 @@ func TestReportStolenCard(t *testing.T) {
 			},
 			on: func(dep *depFields, in input) {
 				dep.userRepositoryMock.EXPECT().GetUser(in.userName).Return(int64(1), "$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca", nil)
-				dep.cardRepositoryMock.EXPECT().GetUserCards(int64(1)).Return([]int64{1, 2}, nil)
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().BlockCards(fingerprints, BlockSourceCardReport).Return(nil)
 				dep.stolenCardRepositoryMock.EXPECT().ReportStolenCards(int64(1), []int64{1, 2}).Return(errors.New("database timeout error"))
 			},
 			assertFunc: func(t *testing.T, out output) {
@@ -132,18 +180,24 @@ func TestReportStolenCard(t *testing.T) {
 			userRepositoryMock := mock.NewMockUserRepository(ctrl)
 			cardRepositoryMock := mock.NewMockCardRepository(ctrl)
 			stolenCardRepositoryMock := mock.NewMockStolenCardRepository(ctrl)
+			caseRepositoryMock := mock.NewMockCaseRepository(ctrl)
+			paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
 
 			tt.on(
 				&depFields{
 					userRepositoryMock:       userRepositoryMock,
 					cardRepositoryMock:       cardRepositoryMock,
 					stolenCardRepositoryMock: stolenCardRepositoryMock,
+					caseRepositoryMock:       caseRepositoryMock,
+					paymentRepositoryMock:    paymentRepositoryMock,
 				}, tt.input)
 
 			complianceService := &complianceService{
 				userRepository:       userRepositoryMock,
 				cardRepository:       cardRepositoryMock,
 				stolenCardRepository: stolenCardRepositoryMock,
+				caseRepository:       caseRepositoryMock,
+				paymentRepository:    paymentRepositoryMock,
 			}
 
 			response, err := complianceService.ReportStolenCards(tt.input.userName, tt.input.secretCode)
@@ -153,107 +207,625 @@ func TestReportStolenCard(t *testing.T) {
 	}
 }
 
-func TestCheckComplianceStatus(t *testing.T) {
-	type input struct {
-		userID int64
-		cardID int64
+func TestReportStolenCardsMetrics(t *testing.T) {
+	cards := []repository.Card{{ID: 1, CardNumber: "4111-1111-1111-1111"}, {ID: 2, CardNumber: "5500-0000-0000-0004"}}
+	hashedSecret := "$2a$10$0cdvmI6GCiqRozURednLDOX0wyWHx9HYOOjQhmdFXOSSKYYUC7Oca"
+
+	tests := []struct {
+		name        string
+		secretCode  string
+		on          func(*mock.MockUserRepository, *mock.MockCardRepository, *mock.MockStolenCardRepository)
+		reported    float64
+		failedLogin string
+	}{
+		{
+			name:       "Success - Reported cards counted",
+			secretCode: "hashed_secret_123",
+			on: func(userRepositoryMock *mock.MockUserRepository, cardRepositoryMock *mock.MockCardRepository, stolenCardRepositoryMock *mock.MockStolenCardRepository) {
+				userRepositoryMock.EXPECT().GetUser("john_doe").Return(int64(1), hashedSecret, nil)
+				cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(cards, nil)
+				stolenCardRepositoryMock.EXPECT().BlockCards(gomock.Any(), BlockSourceCardReport).Return(nil)
+				stolenCardRepositoryMock.EXPECT().ReportStolenCards(int64(1), []int64{1, 2}).Return(nil)
+			},
+			reported: 2,
+		},
+		{
+			name:       "Success - Report already submitted not counted",
+			secretCode: "hashed_secret_123",
+			on: func(userRepositoryMock *mock.MockUserRepository, cardRepositoryMock *mock.MockCardRepository, stolenCardRepositoryMock *mock.MockStolenCardRepository) {
+				userRepositoryMock.EXPECT().GetUser("john_doe").Return(int64(1), hashedSecret, nil)
+				cardRepositoryMock.EXPECT().GetUserCardDetails(int64(1)).Return(cards, nil)
+				stolenCardRepositoryMock.EXPECT().BlockCards(gomock.Any(), BlockSourceCardReport).Return(nil)
+				stolenCardRepositoryMock.EXPECT().ReportStolenCards(int64(1), []int64{1, 2}).Return(errors.New("UNIQUE constraint failed: reported_cards.user_id, reported_cards.card_id"))
+			},
+		},
+		{
+			name:       "Failure - Unknown user counted as a failed login",
+			secretCode: "some_secret",
+			on: func(userRepositoryMock *mock.MockUserRepository, _ *mock.MockCardRepository, _ *mock.MockStolenCardRepository) {
+				userRepositoryMock.EXPECT().GetUser("john_doe").Return(int64(0), "", errors.New("sql: no rows in result set"))
+			},
+			failedLogin: failedLoginUnknownUser,
+		},
+		{
+			name:       "Failure - Invalid secret code counted as a failed login",
+			secretCode: "wrong_secret",
+			on: func(userRepositoryMock *mock.MockUserRepository, _ *mock.MockCardRepository, _ *mock.MockStolenCardRepository) {
+				userRepositoryMock.EXPECT().GetUser("john_doe").Return(int64(1), hashedSecret, nil)
+			},
+			failedLogin: failedLoginInvalidSecret,
+		},
 	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepositoryMock := mock.NewMockUserRepository(ctrl)
+			cardRepositoryMock := mock.NewMockCardRepository(ctrl)
+			stolenCardRepositoryMock := mock.NewMockStolenCardRepository(ctrl)
+			tt.on(userRepositoryMock, cardRepositoryMock, stolenCardRepositoryMock)
+			paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
+			caseRepositoryMock := mock.NewMockCaseRepository(ctrl)
+			caseRepositoryMock.EXPECT().CreateCase(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(10), nil).AnyTimes()
+
+			complianceService := &complianceService{
+				userRepository:       userRepositoryMock,
+				cardRepository:       cardRepositoryMock,
+				stolenCardRepository: stolenCardRepositoryMock,
+				caseRepository:       caseRepositoryMock,
+				paymentRepository:    paymentRepositoryMock,
+			}
+
+			reported := testutil.ToFloat64(cardsReportedTotal)
+			unknownUser := testutil.ToFloat64(failedLoginsTotal.WithLabelValues(failedLoginUnknownUser))
+			invalidSecret := testutil.ToFloat64(failedLoginsTotal.WithLabelValues(failedLoginInvalidSecret))
+
+			_, _ = complianceService.ReportStolenCards("john_doe", tt.secretCode)
+
+			assert.Equal(t, reported+tt.reported, testutil.ToFloat64(cardsReportedTotal))
+			wantUnknownUser, wantInvalidSecret := unknownUser, invalidSecret
+			switch tt.failedLogin {
+			case failedLoginUnknownUser:
+				wantUnknownUser++
+			case failedLoginInvalidSecret:
+				wantInvalidSecret++
+			}
+			assert.Equal(t, wantUnknownUser, testutil.ToFloat64(failedLoginsTotal.WithLabelValues(failedLoginUnknownUser)))
+			assert.Equal(t, wantInvalidSecret, testutil.ToFloat64(failedLoginsTotal.WithLabelValues(failedLoginInvalidSecret)))
+		})
+	}
+}
+
+func TestNotifyCardReported(t *testing.T) {
+	reportedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		payload    string
+		on         func(paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:    "Success - Payment service notified",
+			payload: `{"user_id":1,"card_id":2}`,
+			on: func(paymentRepositoryMock *mock.MockPaymentRepository) {
+				paymentRepositoryMock.EXPECT().NotifyCardsReported(int64(1), []int64{2}, reportedAt).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "Success - Undecodable event skipped",
+			payload: `{"user_id":`,
+			on:      func(paymentRepositoryMock *mock.MockPaymentRepository) {},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "Failure - Payment service unavailable, event retried",
+			payload: `{"user_id":1,"card_id":2}`,
+			on: func(paymentRepositoryMock *mock.MockPaymentRepository) {
+				paymentRepositoryMock.EXPECT().NotifyCardsReported(int64(1), []int64{2}, reportedAt).Return(errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "connection refused")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
+			tt.on(paymentRepositoryMock)
+
+			service := &complianceService{paymentRepository: paymentRepositoryMock}
+			err := service.NotifyCardReported(repository.Event{ID: 7, Type: repository.EventCardReported, Payload: json.RawMessage(tt.payload),
+				CreatedAt: reportedAt})
+
+			tt.assertFunc(t, err)
+		})
+	}
+}
+
+func TestInvalidateCardVerdicts(t *testing.T) {
+	fingerprint := CardFingerprint("4111111111111111")
+	// user 2 has a card with the same PAN as user 1, user 3 another card
+	allCards := []repository.Card{
+		{ID: 1, UserID: 1, CardNumber: "4111-1111-1111-1111"},
+		{ID: 2, UserID: 1, CardNumber: "5500-0000-0000-0004"},
+		{ID: 5, UserID: 2, CardNumber: "4111111111111111"},
+		{ID: 6, UserID: 3, CardNumber: "4000056655665556"},
+	}
+
+	tests := []struct {
+		name       string
+		event      repository.Event
+		on         func(cardRepositoryMock *mock.MockCardRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:  "Success - Verdicts of the linked cards invalidated on a block",
+			event: repository.Event{ID: 7, Type: repository.EventCardBlocked, Payload: json.RawMessage(`{"card_fingerprint":"` + fingerprint + `","source":"card_report"}`)},
+			on: func(cardRepositoryMock *mock.MockCardRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				cardRepositoryMock.EXPECT().ListCards().Return(allCards, nil)
+				paymentRepositoryMock.EXPECT().InvalidateVerdicts([]repository.BlockedCard{{UserID: 1, CardID: 1}, {UserID: 2, CardID: 5}}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:  "Success - Verdicts of the linked cards invalidated on a reinstatement",
+			event: repository.Event{ID: 7, Type: repository.EventCardReinstated, Payload: json.RawMessage(`{"user_id":1,"card_id":1,"card_fingerprint":"` + fingerprint + `"}`)},
+			on: func(cardRepositoryMock *mock.MockCardRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				cardRepositoryMock.EXPECT().ListCards().Return(allCards, nil)
+				paymentRepositoryMock.EXPECT().InvalidateVerdicts([]repository.BlockedCard{{UserID: 1, CardID: 1}, {UserID: 2, CardID: 5}}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:  "Success - Every verdict invalidated for a card of a feed",
+			event: repository.Event{ID: 7, Type: repository.EventCardBlocked, Payload: json.RawMessage(`{"card_fingerprint":"` + fingerprint + `","source":"visa-cams","import_id":3}`)},
+			on: func(cardRepositoryMock *mock.MockCardRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:  "Success - Undecodable event skipped",
+			event: repository.Event{ID: 7, Type: repository.EventCardBlocked, Payload: json.RawMessage(`{"card_fingerprint":`)},
+			on:    func(cardRepositoryMock *mock.MockCardRepository, paymentRepositoryMock *mock.MockPaymentRepository) {},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:  "Failure - Cards not listed, event retried",
+			event: repository.Event{ID: 7, Type: repository.EventCardBlocked, Payload: json.RawMessage(`{"card_fingerprint":"` + fingerprint + `","source":"card_report"}`)},
+			on: func(cardRepositoryMock *mock.MockCardRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				cardRepositoryMock.EXPECT().ListCards().Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+		{
+			name:  "Failure - Payment service unavailable, event retried",
+			event: repository.Event{ID: 7, Type: repository.EventCardBlocked, Payload: json.RawMessage(`{"card_fingerprint":"` + fingerprint + `","source":"card_report"}`)},
+			on: func(cardRepositoryMock *mock.MockCardRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				cardRepositoryMock.EXPECT().ListCards().Return(allCards, nil)
+				paymentRepositoryMock.EXPECT().InvalidateVerdicts(gomock.Any()).Return(errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "connection refused")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cardRepositoryMock := mock.NewMockCardRepository(ctrl)
+			paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
+			tt.on(cardRepositoryMock, paymentRepositoryMock)
+
+			service := &complianceService{cardRepository: cardRepositoryMock, paymentRepository: paymentRepositoryMock}
+			err := service.InvalidateCardVerdicts(tt.event)
+
+			tt.assertFunc(t, err)
+		})
+	}
+}
+
+func TestCheckComplianceStatus(t *testing.T) {
+	cards := []repository.Card{{ID: 1, CardNumber: "4111-1111-1111-1111"}, {ID: 2, CardNumber: "5500-0000-0000-0004"}}
+	fingerprint := CardFingerprint("4111111111111111")
+
 	type output struct {
-		isComplaiance bool
-		message       string
-		err           error
+		result ComplianceResult
+		err    error
 	}
 
 	type depFields struct {
 		stolenCardRepositoryMock *mock.MockStolenCardRepository
 		cardRepositoryMock       *mock.MockCardRepository
+		listEntryRepositoryMock  *mock.MockListEntryRepository
+		sanctionsRepositoryMock  *mock.MockSanctionsRepository
+		kycRepositoryMock        *mock.MockKYCRepository
+		pepRepositoryMock        *mock.MockPEPRepository
 	}
 
 	tests := []struct {
 		name       string
-		input      input
-		on         func(*depFields, input)
+		input      ComplianceCheck
+		on         func(*depFields, ComplianceCheck)
 		assertFunc func(t *testing.T, out output)
 	}{
 		{
-			name: "Success - User is compliance",
-			input: input{
-				userID: 1,
-				cardID: 1,
+			name:  "Success - User is compliance",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{}, sql.ErrNoRows)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return("", sql.ErrNoRows)
 			},
-			on: func(dep *depFields, in input) {
-				dep.cardRepositoryMock.EXPECT().GetUserCards(in.userID).Return([]int64{1, 2, 3}, nil)
-				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.userID, in.cardID).Return(false, nil)
+			assertFunc: func(t *testing.T, out output) {
+				assert.True(t, out.result.IsCompliance)
+				assert.Equal(t, "user is compliance", out.result.Message)
+				assert.Equal(t, RiskRatingLow, out.result.RiskRating)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:  "Success - User is blocked",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(true, nil)
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.True(t, out.isComplaiance)
-				assert.Equal(t, "user is compliance", out.message)
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "user is currently blocked due to reported stolen card/s", out.result.Message)
 				assert.NoError(t, out.err)
 			},
 		},
 		{
-			name: "Success - User is blocked",
-			input: input{
-				userID: 1,
-				cardID: 1,
+			name:  "Success - Card reported through another user is blocked",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(true, nil)
 			},
-			on: func(dep *depFields, in input) {
-				dep.cardRepositoryMock.EXPECT().GetUserCards(in.userID).Return([]int64{1, 2, 3}, nil)
-				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.userID, in.cardID).Return(true, nil)
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "card is blocked due to being reported as stolen or compromised", out.result.Message)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:  "Failure - Error checking the card block",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, "error checking compliance status", out.result.Message)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+		{
+			name:  "Success - Payment blocked by a deny list entry",
+			input: ComplianceCheck{UserID: 1, CardID: 1, IPAddress: "203.0.113.7", Email: "alice@mail.example.com", DeviceID: "device-1"},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeEmailDomain, []string{"mail.example.com", "example.com"}, gomock.Any()).
+					Return([]repository.ListEntry{{ID: 4, ListType: ListTypeDeny, EntryType: EntryTypeEmailDomain, Value: "example.com", Reason: "disposable emails"}}, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeDeviceID, []string{"device-1"}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeIP, gomock.Any()).Return(nil, nil)
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.False(t, out.isComplaiance)
-				assert.Equal(t, "user is currently blocked due to reported stolen card/s", out.message)
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "payment blocked by deny list: email_domain example.com (disposable emails)", out.result.Message)
+				assert.Len(t, out.result.MatchedEntries, 1)
 				assert.NoError(t, out.err)
 			},
 		},
 		{
-			name: "Failure - Card does not belong to user",
-			input: input{
-				userID: 1,
-				cardID: 99,
+			name:  "Success - Unverified user over the tier 0 limit",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 150.01},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{}, sql.ErrNoRows)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return("", sql.ErrNoRows)
 			},
-			on: func(dep *depFields, in input) {
-				dep.cardRepositoryMock.EXPECT().GetUserCards(in.userID).Return([]int64{1, 2, 3}, nil)
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "payment amount 150.01 exceeds the 150.00 limit of KYC tier 0 (unverified), a higher verification tier is required", out.result.Message)
+				// the limits let payment-service decide the next payments of the user from a cached verdict
+				assert.Equal(t, &PaymentLimits{KYCStatus: KYCStatusUnverified, KYCTier: KYCTierNone, KYCLimit: 150}, out.result.Limits)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:  "Success - Pending profile keeps the tier 0 limit",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 500},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusPending}, nil)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return(RiskRatingLow, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Contains(t, out.result.Message, "limit of KYC tier 0 (pending)")
+			},
+		},
+		{
+			name:  "Success - Verified user within the tier 1 limit",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 2000},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusVerified, Tier: KYCTierBasic}, nil)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return(RiskRatingMedium, nil)
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.False(t, out.isComplaiance)
-				assert.Equal(t, "the provided card does not belong to the user", out.message)
+				assert.True(t, out.result.IsCompliance)
 				assert.NoError(t, out.err)
 			},
 		},
 		{
-			name: "Failure - Error retrieving user cards",
-			input: input{
-				userID: 1,
-				cardID: 1,
+			name:  "Success - Tier 2 has no limit",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 1000000},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusVerified, Tier: KYCTierFull}, nil)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return(RiskRatingLow, nil)
 			},
-			on: func(dep *depFields, in input) {
-				dep.cardRepositoryMock.EXPECT().GetUserCards(in.userID).Return(nil, errors.New("database error"))
+			assertFunc: func(t *testing.T, out output) {
+				assert.True(t, out.result.IsCompliance)
+			},
+		},
+		{
+			name:  "Success - High-risk user within the review amount",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 499.99},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusVerified, Tier: KYCTierFull}, nil)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return(RiskRatingHigh, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.True(t, out.result.IsCompliance)
+				assert.Equal(t, RiskRatingHigh, out.result.RiskRating)
+				assert.False(t, out.result.ManualReview)
+			},
+		},
+		{
+			name:  "Success - Large payment of a high-risk user held for manual review",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 500},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusVerified, Tier: KYCTierFull}, nil)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return(RiskRatingHigh, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.True(t, out.result.ManualReview)
+				assert.Equal(t, "payment amount 500.00 of a high-risk user requires manual review", out.result.Message)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:  "Success - High-risk user over the stricter limit, whatever the KYC tier",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 1000.01},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusVerified, Tier: KYCTierFull}, nil)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return(RiskRatingHigh, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.False(t, out.result.ManualReview)
+				assert.Equal(t, "payment amount 1000.01 exceeds the 1000.00 limit of high-risk users", out.result.Message)
+			},
+		},
+		{
+			name:  "Failure - Error checking the risk rating",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 10},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusVerified, Tier: KYCTierFull}, nil)
+				dep.pepRepositoryMock.EXPECT().GetRating(in.UserID).Return("", errors.New("database error"))
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.False(t, out.isComplaiance)
-				assert.Equal(t, "error retrieving user cards", out.message)
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "error checking risk rating", out.result.Message)
 				assert.EqualError(t, out.err, "database error")
 			},
 		},
 		{
-			name: "Failure - Unexpected error in IsCardReported",
-			input: input{
-				userID: 1,
-				cardID: 1,
+			name:  "Success - Rejected user is denied any amount",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusRejected}, nil)
 			},
-			on: func(dep *depFields, in input) {
-				dep.cardRepositoryMock.EXPECT().GetUserCards(in.userID).Return([]int64{1, 2, 3}, nil)
-				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.userID, in.cardID).Return(false, errors.New("database connection error"))
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "user identity verification was rejected", out.result.Message)
+				assert.Nil(t, out.result.Limits)
+			},
+		},
+		{
+			name:  "Failure - Error checking the KYC status",
+			input: ComplianceCheck{UserID: 1, CardID: 1, Amount: 10},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+				dep.kycRepositoryMock.EXPECT().GetProfile(in.UserID).Return(repository.KYCProfile{}, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "error checking KYC status", out.result.Message)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+		{
+			name:  "Success - User confirmed as sanctioned",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(true, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "user matches a sanctions list entry", out.result.Message)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:  "Failure - Error checking the sanctions hits",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "error checking compliance status", out.result.Message)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+		{
+			name:  "Failure - Card does not belong to user",
+			input: ComplianceCheck{UserID: 1, CardID: 99},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "the provided card does not belong to the user", out.result.Message)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:  "Failure - Error retrieving user cards",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(nil, errors.New("database error"))
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.False(t, out.isComplaiance)
-				assert.Equal(t, "error checking compliance status", out.message)
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "error retrieving user cards", out.result.Message)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+		{
+			name:  "Failure - Unexpected error in IsCardReported",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, errors.New("database connection error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "error checking compliance status", out.result.Message)
 				assert.EqualError(t, out.err, "database connection error")
 			},
 		},
+		{
+			name:  "Failure - Error checking the lists",
+			input: ComplianceCheck{UserID: 1, CardID: 1},
+			on: func(dep *depFields, in ComplianceCheck) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(in.UserID).Return(cards, nil)
+				dep.sanctionsRepositoryMock.EXPECT().HasConfirmedHit(in.UserID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardReported(in.UserID, in.CardID).Return(false, nil)
+				dep.stolenCardRepositoryMock.EXPECT().IsCardBlocked(fingerprint).Return(false, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{fingerprint}, gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.result.IsCompliance)
+				assert.Equal(t, "error checking deny lists", out.result.Message)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -261,22 +833,321 @@ func TestCheckComplianceStatus(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			stolenCardRepositoryMock := mock.NewMockStolenCardRepository(ctrl)
-			cardRepositoryMock := mock.NewMockCardRepository(ctrl)
 			dep := &depFields{
-				stolenCardRepositoryMock: stolenCardRepositoryMock,
-				cardRepositoryMock:       cardRepositoryMock,
+				stolenCardRepositoryMock: mock.NewMockStolenCardRepository(ctrl),
+				cardRepositoryMock:       mock.NewMockCardRepository(ctrl),
+				listEntryRepositoryMock:  mock.NewMockListEntryRepository(ctrl),
+				sanctionsRepositoryMock:  mock.NewMockSanctionsRepository(ctrl),
+				kycRepositoryMock:        mock.NewMockKYCRepository(ctrl),
+				pepRepositoryMock:        mock.NewMockPEPRepository(ctrl),
 			}
 
 			tt.on(dep, tt.input)
 
 			service := &complianceService{
-				cardRepository:       cardRepositoryMock,
-				stolenCardRepository: stolenCardRepositoryMock,
+				cardRepository:       dep.cardRepositoryMock,
+				stolenCardRepository: dep.stolenCardRepositoryMock,
+				listEntryRepository:  dep.listEntryRepositoryMock,
+				sanctionsRepository:  dep.sanctionsRepositoryMock,
+				kycRepository:        dep.kycRepositoryMock,
+				pepRepository:        dep.pepRepositoryMock,
+				kycTierLimits:        kycTierLimits{150, 2000, 0},
+				highRiskLimits:       highRiskLimits{paymentLimit: 1000, reviewAmount: 500},
+			}
+			result, err := service.CheckComplianceStatus(tt.input)
+
+			tt.assertFunc(t, output{result, err})
+		})
+	}
+}
+
+func TestCheckComplianceStatuses(t *testing.T) {
+	checks := []ComplianceCheck{
+		{UserID: 1, CardID: 1, Amount: 10},
+		{UserID: 1, CardID: 9, Amount: 10},
+		{UserID: 2, CardID: 3, Amount: 10},
+		{UserID: 3, CardID: 4, Amount: 10},
+		{UserID: 4, CardID: 5, Amount: 10},
+		{UserID: 5, CardID: 6, Amount: 10, MerchantID: "m-bad"},
+		{UserID: 6, CardID: 7, Amount: 300},
+		{UserID: 7, CardID: 8, Amount: 600},
+	}
+	// card 9 is not a card of user 1, so it has no status
+	statuses := []repository.CardStatus{
+		{UserID: 1, CardID: 1, CardNumber: "4000-0000-0000-0001", KYCStatus: KYCStatusVerified, KYCTier: KYCTierBasic},
+		{UserID: 2, CardID: 3, CardNumber: "4000-0000-0000-0003", Sanctioned: true},
+		{UserID: 3, CardID: 4, CardNumber: "4000-0000-0000-0004", Reported: true},
+		{UserID: 4, CardID: 5, CardNumber: "4000-0000-0000-0005"},
+		{UserID: 5, CardID: 6, CardNumber: "4000-0000-0000-0006"},
+		{UserID: 6, CardID: 7, CardNumber: "4000-0000-0000-0007"},
+		{UserID: 7, CardID: 8, CardNumber: "4000-0000-0000-0008", KYCStatus: KYCStatusVerified, KYCTier: KYCTierBasic, RiskRating: RiskRatingHigh},
+	}
+	fingerprints := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		fingerprints = append(fingerprints, CardFingerprint(status.CardNumber))
+	}
+	deniedMerchant := repository.ListEntry{ID: 2, ListType: ListTypeDeny, EntryType: EntryTypeMerchantID, Value: "m-bad", Reason: "chargeback abuse"}
+
+	type depFields struct {
+		cardRepositoryMock       *mock.MockCardRepository
+		stolenCardRepositoryMock *mock.MockStolenCardRepository
+		listEntryRepositoryMock  *mock.MockListEntryRepository
+	}
+
+	tests := []struct {
+		name       string
+		input      []ComplianceCheck
+		on         func(*depFields)
+		assertFunc func(t *testing.T, results []BatchComplianceResult, err error)
+	}{
+		{
+			name:  "Success - A verdict for each check",
+			input: checks,
+			on: func(dep *depFields) {
+				dep.cardRepositoryMock.EXPECT().GetCardStatuses([]repository.UserCard{{UserID: 1, CardID: 1}, {UserID: 1, CardID: 9}, {UserID: 2, CardID: 3},
+					{UserID: 3, CardID: 4}, {UserID: 4, CardID: 5}, {UserID: 5, CardID: 6}, {UserID: 6, CardID: 7}, {UserID: 7, CardID: 8}}).Return(statuses, nil)
+				dep.stolenCardRepositoryMock.EXPECT().FindBlockedFingerprints(fingerprints).Return([]string{CardFingerprint("4000000000000005")}, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, fingerprints, gomock.Any()).Return(nil, nil)
+				dep.listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeMerchantID, []string{"m-bad"}, gomock.Any()).
+					Return([]repository.ListEntry{deniedMerchant}, nil)
+				dep.listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, gomock.Any()).Return(nil, nil)
+			},
+			assertFunc: func(t *testing.T, results []BatchComplianceResult, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []BatchComplianceResult{
+					{UserID: 1, CardID: 1, ComplianceResult: ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: RiskRatingLow,
+						Limits: &PaymentLimits{KYCStatus: KYCStatusVerified, KYCTier: KYCTierBasic, KYCLimit: 2000}}},
+					{UserID: 1, CardID: 9, ComplianceResult: ComplianceResult{Message: "the provided card does not belong to the user"}},
+					{UserID: 2, CardID: 3, ComplianceResult: ComplianceResult{Message: "user matches a sanctions list entry"}},
+					{UserID: 3, CardID: 4, ComplianceResult: ComplianceResult{Message: "user is currently blocked due to reported stolen card/s"}},
+					{UserID: 4, CardID: 5, ComplianceResult: ComplianceResult{Message: "card is blocked due to being reported as stolen or compromised"}},
+					{UserID: 5, CardID: 6, ComplianceResult: ComplianceResult{Message: "payment blocked by deny list: merchant_id m-bad (chargeback abuse)",
+						MatchedEntries: []repository.ListEntry{deniedMerchant}}},
+					{UserID: 6, CardID: 7, ComplianceResult: ComplianceResult{
+						Message:    "payment amount 300.00 exceeds the 150.00 limit of KYC tier 0 (unverified), a higher verification tier is required",
+						RiskRating: RiskRatingLow, Limits: &PaymentLimits{KYCStatus: KYCStatusUnverified, KYCTier: KYCTierNone, KYCLimit: 150}}},
+					{UserID: 7, CardID: 8, ComplianceResult: ComplianceResult{Message: "payment amount 600.00 of a high-risk user requires manual review",
+						RiskRating: RiskRatingHigh, ManualReview: true,
+						Limits: &PaymentLimits{KYCStatus: KYCStatusVerified, KYCTier: KYCTierBasic, KYCLimit: 2000, HighRiskLimit: 1000, ReviewAmount: 500}}},
+				}, results)
+			},
+		},
+		{
+			name:  "Failure - Empty batch",
+			input: []ComplianceCheck{},
+			on:    func(dep *depFields) {},
+			assertFunc: func(t *testing.T, results []BatchComplianceResult, err error) {
+				assert.ErrorIs(t, err, ErrInvalidBatchCheck)
+				assert.Nil(t, results)
+			},
+		},
+		{
+			name:  "Failure - Batch too large",
+			input: make([]ComplianceCheck, MaxBatchComplianceChecks+1),
+			on:    func(dep *depFields) {},
+			assertFunc: func(t *testing.T, results []BatchComplianceResult, err error) {
+				assert.EqualError(t, err, "invalid batch compliance check: between 1 and 1000 checks are required")
+			},
+		},
+		{
+			name:  "Failure - Repository error",
+			input: checks[:1],
+			on: func(dep *depFields) {
+				dep.cardRepositoryMock.EXPECT().GetCardStatuses([]repository.UserCard{{UserID: 1, CardID: 1}}).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, results []BatchComplianceResult, err error) {
+				assert.EqualError(t, err, "database error")
+				assert.Nil(t, results)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dep := &depFields{
+				cardRepositoryMock:       mock.NewMockCardRepository(ctrl),
+				stolenCardRepositoryMock: mock.NewMockStolenCardRepository(ctrl),
+				listEntryRepositoryMock:  mock.NewMockListEntryRepository(ctrl),
+			}
+
+			tt.on(dep)
+
+			service := &complianceService{
+				cardRepository:       dep.cardRepositoryMock,
+				stolenCardRepository: dep.stolenCardRepositoryMock,
+				listEntryRepository:  dep.listEntryRepositoryMock,
+				kycTierLimits:        kycTierLimits{150, 2000, 0},
+				highRiskLimits:       highRiskLimits{paymentLimit: 1000, reviewAmount: 500},
+			}
+			results, err := service.CheckComplianceStatuses(tt.input)
+
+			tt.assertFunc(t, results, err)
+		})
+	}
+}
+
+func TestPaymentLimitsVerdict(t *testing.T) {
+	basic := PaymentLimits{KYCStatus: KYCStatusVerified, KYCTier: KYCTierBasic, KYCLimit: 2000}
+	highRisk := PaymentLimits{KYCStatus: KYCStatusVerified, KYCTier: KYCTierFull, HighRiskLimit: 1000, ReviewAmount: 500}
+
+	tests := []struct {
+		name   string
+		limits PaymentLimits
+		rating string
+		amount float64
+		want   ComplianceResult
+	}{
+		{
+			name:   "Success - Within the KYC limit",
+			limits: basic,
+			rating: RiskRatingLow,
+			amount: 2000,
+			want:   ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: RiskRatingLow},
+		},
+		{
+			name:   "Denied - Over the KYC limit",
+			limits: basic,
+			rating: RiskRatingLow,
+			amount: 2000.01,
+			want: ComplianceResult{Message: "payment amount 2000.01 exceeds the 2000.00 limit of KYC tier 1 (verified), a higher verification tier is required",
+				RiskRating: RiskRatingLow},
+		},
+		{
+			name:   "Success - High-risk user below the review amount",
+			limits: highRisk,
+			rating: RiskRatingHigh,
+			amount: 499.99,
+			want:   ComplianceResult{IsCompliance: true, Message: "user is compliance", RiskRating: RiskRatingHigh},
+		},
+		{
+			name:   "Review - High-risk user at the review amount",
+			limits: highRisk,
+			rating: RiskRatingHigh,
+			amount: 500,
+			want:   ComplianceResult{Message: "payment amount 500.00 of a high-risk user requires manual review", RiskRating: RiskRatingHigh, ManualReview: true},
+		},
+		{
+			name:   "Denied - High-risk user over the high-risk limit",
+			limits: highRisk,
+			rating: RiskRatingHigh,
+			amount: 1000.01,
+			want:   ComplianceResult{Message: "payment amount 1000.01 exceeds the 1000.00 limit of high-risk users", RiskRating: RiskRatingHigh},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.limits.verdict(tt.rating, tt.amount))
+		})
+	}
+}
+
+func TestListBlockedCards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stolenCardRepositoryMock := mock.NewMockStolenCardRepository(ctrl)
+	cardRepositoryMock := mock.NewMockCardRepository(ctrl)
+
+	// card 1 is both reported and blocked by a feed, card 4 shares the blocked PAN with card 1
+	stolenCardRepositoryMock.EXPECT().ListReportedCards().Return([]repository.BlockedCard{{UserID: 1, CardID: 1}}, nil)
+	stolenCardRepositoryMock.EXPECT().ListBlockedFingerprints().Return([]string{CardFingerprint("4111111111111111")}, nil)
+	cardRepositoryMock.EXPECT().ListCards().Return([]repository.Card{
+		{ID: 1, UserID: 1, CardNumber: "4111-1111-1111-1111"},
+		{ID: 2, UserID: 1, CardNumber: "5500-0000-0000-0004"},
+		{ID: 4, UserID: 3, CardNumber: "4111 1111 1111 1111"},
+	}, nil)
+
+	service := &complianceService{cardRepository: cardRepositoryMock, stolenCardRepository: stolenCardRepositoryMock}
+	blocked, err := service.ListBlockedCards()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.BlockedCard{{UserID: 1, CardID: 1}, {UserID: 3, CardID: 4}}, blocked)
+}
+
+func TestReinstateCard(t *testing.T) {
+	cards := []repository.Card{{ID: 7, CardNumber: "4111-1111-1111-1111"}}
+	cardFingerprint := CardFingerprint("4111111111111111")
+
+	type depFields struct {
+		cardRepositoryMock       *mock.MockCardRepository
+		stolenCardRepositoryMock *mock.MockStolenCardRepository
+		paymentRepositoryMock    *mock.MockPaymentRepository
+	}
+
+	tests := []struct {
+		name       string
+		cardID     int64
+		on         func(dep *depFields)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:   "Success - Card reinstated",
+			cardID: 7,
+			on: func(dep *depFields) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(3)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().ReinstateCard(int64(3), int64(7), cardFingerprint).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:   "Failure - Card not blocked",
+			cardID: 7,
+			on: func(dep *depFields) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(3)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().ReinstateCard(int64(3), int64(7), cardFingerprint).Return(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrCardNotBlocked)
+			},
+		},
+		{
+			name:   "Failure - Card of another user",
+			cardID: 8,
+			on: func(dep *depFields) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(3)).Return(cards, nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrCardNotFound)
+			},
+		},
+		{
+			name:   "Failure - Error reinstating the card",
+			cardID: 7,
+			on: func(dep *depFields) {
+				dep.cardRepositoryMock.EXPECT().GetUserCardDetails(int64(3)).Return(cards, nil)
+				dep.stolenCardRepositoryMock.EXPECT().ReinstateCard(int64(3), int64(7), cardFingerprint).Return(errors.New("database locked"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database locked")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dep := &depFields{
+				cardRepositoryMock:       mock.NewMockCardRepository(ctrl),
+				stolenCardRepositoryMock: mock.NewMockStolenCardRepository(ctrl),
+				paymentRepositoryMock:    mock.NewMockPaymentRepository(ctrl),
+			}
+			tt.on(dep)
+
+			service := &complianceService{
+				cardRepository:       dep.cardRepositoryMock,
+				stolenCardRepository: dep.stolenCardRepositoryMock,
+				paymentRepository:    dep.paymentRepositoryMock,
 			}
-			blocked, message, err := service.CheckComplianceStatus(tt.input.userID, tt.input.cardID)
 
-			tt.assertFunc(t, output{blocked, message, err})
+			tt.assertFunc(t, service.ReinstateCard(3, tt.cardID))
 		})
 	}
 }
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/logging"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	MaxEventBatch = 1000
+
+	defaultEventPollInterval = time.Second
+	defaultEventRetention    = 30 * 24 * time.Hour
+	eventPurgeInterval       = time.Hour
+	eventConsumerBatch       = 100
+	eventConsumerRetry       = 5 * time.Second
+)
+
+var (
+	ErrCursorExpired     = errors.New("events after the cursor were purged")
+	ErrInvalidEventQuery = errors.New("invalid event query")
+
+	eventTypes = map[string]bool{
+		repository.EventCardReported:   true,
+		repository.EventCardBlocked:    true,
+		repository.EventCardReinstated: true,
+		repository.EventUserLocked:     true,
+	}
+)
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source event_service.go -destination mock/event_service_mock.go -package mock
+type EventService interface {
+	WaitForEvents(ctx context.Context, after int64, eventTypes []string, limit int) ([]repository.Event, error)
+	RunRelay(ctx context.Context)
+	RunConsumer(ctx context.Context, consumer string, eventTypes []string, handle func(event repository.Event) error)
+}
+
+type eventService struct {
+	eventRepository repository.EventRepository
+	pollInterval    time.Duration
+	retention       time.Duration
+	consumerRetry   time.Duration
+
+	mu       sync.Mutex
+	latestID int64
+	// updated is closed, then replaced, every time the relay sees new events, waking up the consumers waiting for them.
+	updated chan struct{}
+	now     func() time.Time
+}
+
+// NewEventService reads EVENT_POLL_INTERVAL, how often the relay looks for new events, and EVENT_RETENTION, how long
+// events are kept for consumers to catch up, both durations such as 1s or 720h.
+func NewEventService(eventRepository repository.EventRepository) EventService {
+	return &eventService{
+		eventRepository: eventRepository,
+		pollInterval:    durationFromEnv("EVENT_POLL_INTERVAL", defaultEventPollInterval),
+		retention:       durationFromEnv("EVENT_RETENTION", defaultEventRetention),
+		consumerRetry:   eventConsumerRetry,
+		updated:         make(chan struct{}),
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	duration, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || duration <= 0 {
+		return defaultValue
+	}
+	return duration
+}
+
+// WaitForEvents returns up to limit events after the cursor, only of the given types if any. When there is none yet,
+// it waits for the relay to see new ones until ctx is done, then returns no event.
+func (s *eventService) WaitForEvents(ctx context.Context, after int64, eventTypes []string, limit int) ([]repository.Event, error) {
+	if err := validateEventQuery(after, eventTypes, limit); err != nil {
+		return nil, err
+	}
+
+	if after > 0 {
+		oldestID, _, err := s.eventRepository.GetEventBounds()
+		if err != nil {
+			return nil, err
+		}
+		if oldestID > after+1 {
+			return nil, fmt.Errorf("%w, resume from cursor %d", ErrCursorExpired, oldestID-1)
+		}
+	}
+
+	for {
+		// taken before the query, so events committed in between still wake this consumer up
+		updated := s.notifier()
+
+		events, err := s.eventRepository.ListEvents(after, eventTypes, limit)
+		if err != nil || len(events) > 0 {
+			return events, err
+		}
+
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return events, nil
+		}
+	}
+}
+
+func validateEventQuery(after int64, types []string, limit int) error {
+	if after < 0 {
+		return fmt.Errorf("%w: cursor must not be negative", ErrInvalidEventQuery)
+	}
+
+	if limit < 1 || limit > MaxEventBatch {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidEventQuery, MaxEventBatch)
+	}
+
+	for _, eventType := range types {
+		if !eventTypes[eventType] {
+			return fmt.Errorf("%w: unknown event type %s", ErrInvalidEventQuery, eventType)
+		}
+	}
+
+	return nil
+}
+
+func (s *eventService) notifier() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updated
+}
+
+// RunRelay watches the outbox until ctx is done, waking up the waiting consumers as soon as events are committed,
+// and purges the events older than the retention.
+func (s *eventService) RunRelay(ctx context.Context) {
+	poll := time.NewTicker(s.pollInterval)
+	defer poll.Stop()
+	purge := time.NewTicker(eventPurgeInterval)
+	defer purge.Stop()
+
+	s.purgeEvents()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			s.pollEvents()
+		case <-purge.C:
+			s.purgeEvents()
+		}
+	}
+}
+
+func (s *eventService) pollEvents() {
+	_, latestID, err := s.eventRepository.GetEventBounds()
+	if err != nil {
+		slog.Error("error polling the events", logging.Err(err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if latestID != s.latestID {
+		s.latestID = latestID
+		close(s.updated)
+		s.updated = make(chan struct{})
+	}
+}
+
+func (s *eventService) purgeEvents() {
+	deleted, err := s.eventRepository.DeleteEventsBefore(s.now().Add(-s.retention))
+	if err != nil {
+		slog.Error("error purging the events", logging.Err(err))
+		return
+	}
+
+	if deleted > 0 {
+		slog.Info("purged events", slog.Int64("deleted", deleted), slog.Duration("retention", s.retention))
+	}
+}
+
+// RunConsumer hands the events of the given types, or all of them, to handle in order until ctx is done. The cursor of
+// the consumer is saved after each event, so a restarted consumer resumes after the last event it handled: every event
+// is handled at least once, twice if the service stops in between. An event handle fails on is retried until it
+// succeeds.
+func (s *eventService) RunConsumer(ctx context.Context, consumer string, eventTypes []string, handle func(event repository.Event) error) {
+	after, err := s.eventRepository.GetCursor(consumer)
+	for err != nil {
+		slog.Error("error reading the cursor of event consumer", slog.String("consumer", consumer), logging.Err(err))
+		if !s.sleep(ctx) {
+			return
+		}
+		after, err = s.eventRepository.GetCursor(consumer)
+	}
+
+	for ctx.Err() == nil {
+		events, err := s.WaitForEvents(ctx, after, eventTypes, eventConsumerBatch)
+		if errors.Is(err, ErrCursorExpired) {
+			oldestID, _, boundsErr := s.eventRepository.GetEventBounds()
+			if boundsErr == nil {
+				slog.Warn("event consumer missed events purged before it handled them", slog.String("consumer", consumer),
+					slog.Int64("from_event_id", after+1), slog.Int64("to_event_id", oldestID-1))
+				after = oldestID - 1
+				continue
+			}
+			err = boundsErr
+		}
+		if err != nil {
+			slog.Error("error reading the events of consumer", slog.String("consumer", consumer), logging.Err(err))
+			s.sleep(ctx)
+			continue
+		}
+
+		for _, event := range events {
+			if err := handle(event); err != nil {
+				slog.Error("error handling event", slog.Int64("event_id", event.ID), slog.String("consumer", consumer), logging.Err(err))
+				s.sleep(ctx)
+				break
+			}
+
+			after = event.ID
+			if err := s.eventRepository.SaveCursor(consumer, after); err != nil {
+				slog.Error("error saving the cursor of event consumer", slog.String("consumer", consumer), logging.Err(err))
+			}
+		}
+	}
+}
+
+// sleep waits for the consumer retry interval, returning false when ctx is done first.
+func (s *eventService) sleep(ctx context.Context) bool {
+	timer := time.NewTimer(s.consumerRetry)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
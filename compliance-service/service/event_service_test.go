@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEventService(eventRepositoryMock *mock.MockEventRepository) *eventService {
+	return &eventService{
+		eventRepository: eventRepositoryMock,
+		pollInterval:    time.Millisecond,
+		retention:       time.Hour,
+		consumerRetry:   time.Millisecond,
+		updated:         make(chan struct{}),
+		now:             func() time.Time { return time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC) },
+	}
+}
+
+func TestWaitForEvents(t *testing.T) {
+	reported := repository.Event{ID: 42, Type: repository.EventCardReported, Payload: []byte(`{"user_id":3,"card_id":7}`)}
+
+	tests := []struct {
+		name       string
+		after      int64
+		eventTypes []string
+		limit      int
+		on         func(eventRepositoryMock *mock.MockEventRepository)
+		assertFunc func(t *testing.T, events []repository.Event, err error)
+	}{
+		{
+			name:  "Success - Events after the cursor",
+			after: 41,
+			limit: 100,
+			on: func(eventRepositoryMock *mock.MockEventRepository) {
+				eventRepositoryMock.EXPECT().GetEventBounds().Return(int64(30), int64(42), nil)
+				eventRepositoryMock.EXPECT().ListEvents(int64(41), nil, 100).Return([]repository.Event{reported}, nil)
+			},
+			assertFunc: func(t *testing.T, events []repository.Event, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []repository.Event{reported}, events)
+			},
+		},
+		{
+			name:       "Success - Nothing new until the wait is over",
+			after:      42,
+			eventTypes: []string{repository.EventUserLocked},
+			limit:      10,
+			on: func(eventRepositoryMock *mock.MockEventRepository) {
+				eventRepositoryMock.EXPECT().GetEventBounds().Return(int64(43), int64(50), nil)
+				eventRepositoryMock.EXPECT().ListEvents(int64(42), []string{repository.EventUserLocked}, 10).Return([]repository.Event{}, nil)
+			},
+			assertFunc: func(t *testing.T, events []repository.Event, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, events)
+			},
+		},
+		{
+			name:  "Failure - Events after the cursor purged",
+			after: 10,
+			limit: 100,
+			on: func(eventRepositoryMock *mock.MockEventRepository) {
+				eventRepositoryMock.EXPECT().GetEventBounds().Return(int64(30), int64(42), nil)
+			},
+			assertFunc: func(t *testing.T, events []repository.Event, err error) {
+				assert.ErrorIs(t, err, ErrCursorExpired)
+				assert.EqualError(t, err, "events after the cursor were purged, resume from cursor 29")
+			},
+		},
+		{
+			name:       "Failure - Unknown event type",
+			eventTypes: []string{"CardStolen"},
+			limit:      100,
+			on:         func(eventRepositoryMock *mock.MockEventRepository) {},
+			assertFunc: func(t *testing.T, events []repository.Event, err error) {
+				assert.ErrorIs(t, err, ErrInvalidEventQuery)
+			},
+		},
+		{
+			name:  "Failure - Limit too large",
+			limit: MaxEventBatch + 1,
+			on:    func(eventRepositoryMock *mock.MockEventRepository) {},
+			assertFunc: func(t *testing.T, events []repository.Event, err error) {
+				assert.ErrorIs(t, err, ErrInvalidEventQuery)
+			},
+		},
+		{
+			name:  "Failure - Repository error",
+			limit: 100,
+			on: func(eventRepositoryMock *mock.MockEventRepository) {
+				eventRepositoryMock.EXPECT().ListEvents(int64(0), nil, 100).Return(nil, errors.New("database is locked"))
+			},
+			assertFunc: func(t *testing.T, events []repository.Event, err error) {
+				assert.EqualError(t, err, "database is locked")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eventRepositoryMock := mock.NewMockEventRepository(ctrl)
+			tt.on(eventRepositoryMock)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			events, err := newTestEventService(eventRepositoryMock).WaitForEvents(ctx, tt.after, tt.eventTypes, tt.limit)
+			tt.assertFunc(t, events, err)
+		})
+	}
+}
+
+// A consumer waiting for events is woken up by the relay as soon as an event is committed.
+func TestWaitForEventsWokenUpByRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reinstated := repository.Event{ID: 43, Type: repository.EventCardReinstated}
+	committed := make(chan struct{})
+
+	eventRepositoryMock := mock.NewMockEventRepository(ctrl)
+	eventRepositoryMock.EXPECT().DeleteEventsBefore(time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)).Return(int64(0), nil)
+	eventRepositoryMock.EXPECT().GetEventBounds().DoAndReturn(func() (int64, int64, error) {
+		select {
+		case <-committed:
+			return int64(1), int64(43), nil
+		default:
+			return int64(1), int64(42), nil
+		}
+	}).AnyTimes()
+	gomock.InOrder(
+		eventRepositoryMock.EXPECT().ListEvents(int64(42), nil, 100).DoAndReturn(func(after int64, eventTypes []string, limit int) ([]repository.Event, error) {
+			close(committed)
+			return []repository.Event{}, nil
+		}),
+		eventRepositoryMock.EXPECT().ListEvents(int64(42), nil, 100).Return([]repository.Event{reinstated}, nil),
+	)
+
+	eventService := newTestEventService(eventRepositoryMock)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relayDone := make(chan struct{})
+	go func() {
+		eventService.RunRelay(ctx)
+		close(relayDone)
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+	events, err := eventService.WaitForEvents(waitCtx, 42, nil, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.Event{reinstated}, events)
+
+	cancel()
+	<-relayDone
+}
+
+func TestRunConsumer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reported := repository.Event{ID: 42, Type: repository.EventCardReported}
+	locked := repository.Event{ID: 43, Type: repository.EventUserLocked}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventRepositoryMock := mock.NewMockEventRepository(ctrl)
+	gomock.InOrder(
+		eventRepositoryMock.EXPECT().GetCursor("webhooks").Return(int64(0), errors.New("database is locked")),
+		eventRepositoryMock.EXPECT().GetCursor("webhooks").Return(int64(10), nil),
+		// the events up to 29 were purged while the consumer was down
+		eventRepositoryMock.EXPECT().GetEventBounds().Return(int64(30), int64(43), nil),
+		eventRepositoryMock.EXPECT().GetEventBounds().Return(int64(30), int64(43), nil),
+		eventRepositoryMock.EXPECT().GetEventBounds().Return(int64(30), int64(43), nil),
+		eventRepositoryMock.EXPECT().ListEvents(int64(29), nil, 100).Return([]repository.Event{reported, locked}, nil),
+		eventRepositoryMock.EXPECT().SaveCursor("webhooks", int64(42)).Return(nil),
+		// the failed event is handled again
+		eventRepositoryMock.EXPECT().GetEventBounds().Return(int64(30), int64(43), nil),
+		eventRepositoryMock.EXPECT().ListEvents(int64(42), nil, 100).Return([]repository.Event{locked}, nil),
+		eventRepositoryMock.EXPECT().SaveCursor("webhooks", int64(43)).DoAndReturn(func(consumer string, lastEventID int64) error {
+			cancel()
+			return nil
+		}),
+	)
+
+	var handled []int64
+	failures := 1
+	newTestEventService(eventRepositoryMock).RunConsumer(ctx, "webhooks", nil, func(event repository.Event) error {
+		handled = append(handled, event.ID)
+		if event.ID == 43 && failures > 0 {
+			failures--
+			return errors.New("webhook store unavailable")
+		}
+		return nil
+	})
+
+	assert.Equal(t, []int64{42, 43, 43}, handled)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// placeholderKeys are example values, e.g. of older compose files, refused as keys.
+var placeholderKeys = map[string]bool{"change-me": true, "change-me-too": true, "changeme": true, "secret": true}
+
+// secretKey reads a key from the environment variable, failing when it is not set or is a placeholder, so data is
+// never protected with a key anybody can guess.
+func secretKey(name string) (string, error) {
+	key := os.Getenv(name)
+	if strings.TrimSpace(key) == "" {
+		return "", fmt.Errorf("%s is not set", name)
+	}
+	if placeholderKeys[strings.ToLower(strings.TrimSpace(key))] {
+		return "", fmt.Errorf("%s is set to the placeholder %q, set a random key", name, key)
+	}
+	return key, nil
+}
+
+// fieldCipher encrypts sensitive fields before they are stored, with AES-256-GCM keyed by the SHA-256 of a
+// passphrase. The random nonce is prepended to the ciphertext and the result is base64 encoded.
+type fieldCipher struct {
+	aead cipher.AEAD
+}
+
+func newFieldCipher(passphrase string) fieldCipher {
+	key := sha256.Sum256([]byte(passphrase))
+	block, _ := aes.NewCipher(key[:]) // a 32 bytes key is always valid
+	aead, _ := cipher.NewGCM(block)
+	return fieldCipher{aead: aead}
+}
+
+func (c fieldCipher) encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+func (c fieldCipher) decrypt(encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("invalid encrypted field")
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("invalid encrypted field, was it encrypted with another key?")
+	}
+
+	return string(plaintext), nil
+}
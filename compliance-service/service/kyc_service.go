@@ -0,0 +1,277 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	KYCStatusUnverified = "unverified"
+	KYCStatusPending    = "pending"
+	KYCStatusVerified   = "verified"
+	KYCStatusRejected   = "rejected"
+
+	// KYCTierNone applies to the users not verified yet, KYCTierBasic and KYCTierFull are granted by the reviewer.
+	KYCTierNone  = 0
+	KYCTierBasic = 1
+	KYCTierFull  = 2
+
+	IDDocumentPassport       = "passport"
+	IDDocumentNationalID     = "national_id"
+	IDDocumentDrivingLicense = "driving_license"
+
+	minKYCAge           = 18
+	maxKYCFieldLength   = 256
+	maxKYCReviewLength  = 1000
+	dateOfBirthLayout   = "2006-01-02"
+	defaultTier0Limit   = 150
+	defaultTier1Limit   = 2000
+	defaultTier2Limit   = 0
+	idDocumentLastChars = 4
+)
+
+var (
+	ErrUserNotFound          = errors.New("user not found")
+	ErrKYCProfileNotFound    = errors.New("KYC profile not found")
+	ErrKYCProfileNotPending  = errors.New("KYC profile is not pending review")
+	ErrInvalidKYCProfile     = errors.New("invalid KYC profile")
+	ErrInvalidKYCReview      = errors.New("invalid KYC review")
+	countryCodePattern       = regexp.MustCompile(`^[A-Z]{2}$`)
+	idDocumentNumberPattern  = regexp.MustCompile(`^[A-Z0-9]{4,32}$`)
+	idDocumentNumberStripper = strings.NewReplacer(" ", "", "-", "")
+)
+
+type KYCSubmission struct {
+	LegalName        string `json:"legal_name"`
+	DateOfBirth      string `json:"date_of_birth"`
+	AddressLine      string `json:"address_line"`
+	City             string `json:"city"`
+	PostalCode       string `json:"postal_code"`
+	Country          string `json:"country"`
+	Nationality      string `json:"nationality"`
+	IDDocumentType   string `json:"id_document_type"`
+	IDDocumentNumber string `json:"id_document_number"`
+}
+
+// kycTierLimits caps the amount of a single payment for each tier, 0 meaning no cap.
+type kycTierLimits [KYCTierFull + 1]float64
+
+// loadKYCTierLimits reads the caps from KYC_TIER0_LIMIT, KYC_TIER1_LIMIT and KYC_TIER2_LIMIT.
+func loadKYCTierLimits() kycTierLimits {
+	limits := kycTierLimits{defaultTier0Limit, defaultTier1Limit, defaultTier2Limit}
+	for tier := range limits {
+		if limit, err := strconv.ParseFloat(os.Getenv(fmt.Sprintf("KYC_TIER%d_LIMIT", tier)), 64); err == nil && limit >= 0 {
+			limits[tier] = limit
+		}
+	}
+	return limits
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source kyc_service.go -destination mock/kyc_service_mock.go -package mock
+type KYCService interface {
+	SubmitProfile(userID int64, submission KYCSubmission) (repository.KYCProfile, error)
+	GetProfile(userID int64) (repository.KYCProfile, error)
+	ListProfiles(status string) ([]repository.KYCProfile, error)
+	ReviewProfile(userID int64, status string, tier int, reviewer string, note string) (repository.KYCProfile, error)
+	RevealIDDocumentNumber(userID int64) (string, error)
+}
+
+type kycService struct {
+	kycRepository     repository.KYCRepository
+	userRepository    repository.UserRepository
+	paymentRepository repository.PaymentRepository
+	cipher            fieldCipher
+	now               func() time.Time
+}
+
+// NewKYCService encrypts the ID document numbers with KYC_ENCRYPTION_KEY, failing when it is not set or is a placeholder.
+func NewKYCService(kycRepository repository.KYCRepository, userRepository repository.UserRepository, paymentRepository repository.PaymentRepository) (KYCService, error) {
+	encryptionKey, err := secretKey("KYC_ENCRYPTION_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	return &kycService{
+		kycRepository:     kycRepository,
+		userRepository:    userRepository,
+		paymentRepository: paymentRepository,
+		cipher:            newFieldCipher(encryptionKey),
+		now:               func() time.Time { return time.Now().UTC() },
+	}, nil
+}
+
+// SubmitProfile validates and stores the profile, which then waits for review. Submitting again, even after the
+// profile was verified, replaces it and sends it back to review with no tier.
+func (s *kycService) SubmitProfile(userID int64, submission KYCSubmission) (repository.KYCProfile, error) {
+	if err := s.ensureUserExists(userID); err != nil {
+		return repository.KYCProfile{}, err
+	}
+
+	now := s.now()
+	profile, err := s.validateSubmission(submission, now)
+	if err != nil {
+		return repository.KYCProfile{}, err
+	}
+
+	documentNumber := idDocumentNumberStripper.Replace(strings.ToUpper(strings.TrimSpace(submission.IDDocumentNumber)))
+	if !idDocumentNumberPattern.MatchString(documentNumber) {
+		return repository.KYCProfile{}, fmt.Errorf("%w: ID document number must be 4 to 32 letters or digits", ErrInvalidKYCProfile)
+	}
+	if profile.IDDocumentNumber, err = s.cipher.encrypt(documentNumber); err != nil {
+		return repository.KYCProfile{}, err
+	}
+
+	profile.UserID = userID
+	profile.IDDocumentLast4 = documentNumber[len(documentNumber)-idDocumentLastChars:]
+	profile.Status = KYCStatusPending
+	profile.Tier = KYCTierNone
+	profile.SubmittedAt = &now
+
+	if err := s.kycRepository.SaveProfile(profile); err != nil {
+		return repository.KYCProfile{}, err
+	}
+	// a verified user submitting again goes back to the limit of KYCTierNone
+	invalidateUserVerdicts(s.paymentRepository, userID)
+
+	return profile, nil
+}
+
+// GetProfile returns an empty unverified profile for the users who never submitted one.
+func (s *kycService) GetProfile(userID int64) (repository.KYCProfile, error) {
+	profile, err := s.kycRepository.GetProfile(userID)
+	if err == nil {
+		return profile, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return repository.KYCProfile{}, err
+	}
+
+	if err := s.ensureUserExists(userID); err != nil {
+		return repository.KYCProfile{}, err
+	}
+
+	return repository.KYCProfile{UserID: userID, Status: KYCStatusUnverified, Tier: KYCTierNone}, nil
+}
+
+func (s *kycService) ListProfiles(status string) ([]repository.KYCProfile, error) {
+	return s.kycRepository.ListProfiles(status)
+}
+
+// ReviewProfile verifies a pending profile with the given tier, or rejects it. A rejection needs a note and denies
+// every payment of the user until a new profile is submitted and verified.
+func (s *kycService) ReviewProfile(userID int64, status string, tier int, reviewer string, note string) (repository.KYCProfile, error) {
+	reviewer, note = strings.TrimSpace(reviewer), strings.TrimSpace(note)
+	switch {
+	case status == KYCStatusVerified && tier != KYCTierBasic && tier != KYCTierFull:
+		return repository.KYCProfile{}, fmt.Errorf("%w: a verified profile must get tier %d or %d", ErrInvalidKYCReview, KYCTierBasic, KYCTierFull)
+	case status == KYCStatusRejected && note == "":
+		return repository.KYCProfile{}, fmt.Errorf("%w: a rejection needs a note with the reason", ErrInvalidKYCReview)
+	case status != KYCStatusVerified && status != KYCStatusRejected:
+		return repository.KYCProfile{}, fmt.Errorf("%w: status must be %s or %s", ErrInvalidKYCReview, KYCStatusVerified, KYCStatusRejected)
+	case reviewer == "" || len(reviewer) > maxKYCFieldLength:
+		return repository.KYCProfile{}, fmt.Errorf("%w: reviewer must be between 1 and %d characters", ErrInvalidKYCReview, maxKYCFieldLength)
+	case len(note) > maxKYCReviewLength:
+		return repository.KYCProfile{}, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidKYCReview, maxKYCReviewLength)
+	}
+	if status == KYCStatusRejected {
+		tier = KYCTierNone
+	}
+
+	profile, err := s.kycRepository.GetProfile(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.KYCProfile{}, ErrKYCProfileNotFound
+		}
+		return repository.KYCProfile{}, err
+	}
+	if profile.Status != KYCStatusPending {
+		return repository.KYCProfile{}, fmt.Errorf("%w: profile is %s", ErrKYCProfileNotPending, profile.Status)
+	}
+
+	reviewedAt := s.now()
+	if err := s.kycRepository.ReviewProfile(userID, status, tier, reviewer, note, reviewedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the profile was reviewed or replaced in the meantime
+			return repository.KYCProfile{}, ErrKYCProfileNotPending
+		}
+		return repository.KYCProfile{}, err
+	}
+	invalidateUserVerdicts(s.paymentRepository, userID)
+
+	profile.Status, profile.Tier, profile.Reviewer, profile.ReviewNote, profile.ReviewedAt = status, tier, reviewer, note, &reviewedAt
+	return profile, nil
+}
+
+// RevealIDDocumentNumber decrypts the ID document number of the user, for the reviewers.
+func (s *kycService) RevealIDDocumentNumber(userID int64) (string, error) {
+	profile, err := s.kycRepository.GetProfile(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrKYCProfileNotFound
+		}
+		return "", err
+	}
+
+	return s.cipher.decrypt(profile.IDDocumentNumber)
+}
+
+func (s *kycService) ensureUserExists(userID int64) error {
+	if _, err := s.userRepository.GetUserName(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *kycService) validateSubmission(submission KYCSubmission, now time.Time) (repository.KYCProfile, error) {
+	profile := repository.KYCProfile{
+		LegalName:      strings.TrimSpace(submission.LegalName),
+		DateOfBirth:    strings.TrimSpace(submission.DateOfBirth),
+		AddressLine:    strings.TrimSpace(submission.AddressLine),
+		City:           strings.TrimSpace(submission.City),
+		PostalCode:     strings.TrimSpace(submission.PostalCode),
+		Country:        strings.ToUpper(strings.TrimSpace(submission.Country)),
+		Nationality:    strings.ToUpper(strings.TrimSpace(submission.Nationality)),
+		IDDocumentType: strings.ToLower(strings.TrimSpace(submission.IDDocumentType)),
+	}
+
+	for _, field := range []struct{ name, value string }{{"legal name", profile.LegalName}, {"address line", profile.AddressLine}, {"city", profile.City}} {
+		if field.value == "" || utf8.RuneCountInString(field.value) > maxKYCFieldLength {
+			return repository.KYCProfile{}, fmt.Errorf("%w: %s must be between 1 and %d characters", ErrInvalidKYCProfile, field.name, maxKYCFieldLength)
+		}
+	}
+	if utf8.RuneCountInString(profile.PostalCode) > maxKYCFieldLength {
+		return repository.KYCProfile{}, fmt.Errorf("%w: postal code must be at most %d characters", ErrInvalidKYCProfile, maxKYCFieldLength)
+	}
+
+	dateOfBirth, err := time.Parse(dateOfBirthLayout, profile.DateOfBirth)
+	if err != nil {
+		return repository.KYCProfile{}, fmt.Errorf("%w: date of birth must be in YYYY-MM-DD format", ErrInvalidKYCProfile)
+	}
+	if dateOfBirth.AddDate(minKYCAge, 0, 0).After(now) {
+		return repository.KYCProfile{}, fmt.Errorf("%w: the user must be at least %d years old", ErrInvalidKYCProfile, minKYCAge)
+	}
+
+	if !countryCodePattern.MatchString(profile.Country) || !countryCodePattern.MatchString(profile.Nationality) {
+		return repository.KYCProfile{}, fmt.Errorf("%w: country and nationality must be ISO 3166-1 alpha-2 codes", ErrInvalidKYCProfile)
+	}
+
+	switch profile.IDDocumentType {
+	case IDDocumentPassport, IDDocumentNationalID, IDDocumentDrivingLicense:
+	default:
+		return repository.KYCProfile{}, fmt.Errorf("%w: ID document type must be %s, %s or %s", ErrInvalidKYCProfile, IDDocumentPassport, IDDocumentNationalID, IDDocumentDrivingLicense)
+	}
+
+	return profile, nil
+}
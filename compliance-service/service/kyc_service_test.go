@@ -0,0 +1,378 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKYCService(ctrl *gomock.Controller, now time.Time) (*kycService, *mock.MockKYCRepository, *mock.MockUserRepository, *mock.MockPaymentRepository) {
+	kycRepositoryMock := mock.NewMockKYCRepository(ctrl)
+	userRepositoryMock := mock.NewMockUserRepository(ctrl)
+	paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
+	return &kycService{
+		kycRepository:     kycRepositoryMock,
+		userRepository:    userRepositoryMock,
+		paymentRepository: paymentRepositoryMock,
+		cipher:            newFieldCipher("test-key"),
+		now:               func() time.Time { return now },
+	}, kycRepositoryMock, userRepositoryMock, paymentRepositoryMock
+}
+
+func TestSubmitKYCProfile(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	submission := KYCSubmission{
+		LegalName:        " John Doe ",
+		DateOfBirth:      "1990-05-17",
+		AddressLine:      "1 Main St",
+		City:             "Springfield",
+		Country:          "us",
+		Nationality:      "US",
+		IDDocumentType:   "Passport",
+		IDDocumentNumber: "x12-345 6789",
+	}
+
+	tests := []struct {
+		name       string
+		input      func(submission KYCSubmission) KYCSubmission
+		on         func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, profile repository.KYCProfile, err error)
+	}{
+		{
+			name:  "Success - Profile submitted for review with the document number encrypted",
+			input: func(submission KYCSubmission) KYCSubmission { return submission },
+			on: func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+				kycRepositoryMock.EXPECT().SaveProfile(gomock.Any()).DoAndReturn(func(profile repository.KYCProfile) error {
+					assert.NotContains(t, profile.IDDocumentNumber, "X123456789")
+					assert.Equal(t, "6789", profile.IDDocumentLast4)
+					return nil
+				})
+				// a verified user submitting again loses its tier
+				paymentRepositoryMock.EXPECT().InvalidateUserVerdicts([]int64{1}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "John Doe", profile.LegalName)
+				assert.Equal(t, "US", profile.Country)
+				assert.Equal(t, IDDocumentPassport, profile.IDDocumentType)
+				assert.Equal(t, KYCStatusPending, profile.Status)
+				assert.Equal(t, KYCTierNone, profile.Tier)
+				assert.Equal(t, &now, profile.SubmittedAt)
+			},
+		},
+		{
+			name:  "Failure - User not found",
+			input: func(submission KYCSubmission) KYCSubmission { return submission },
+			on: func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("", sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.ErrorIs(t, err, ErrUserNotFound)
+			},
+		},
+		{
+			name: "Failure - Underage user",
+			input: func(submission KYCSubmission) KYCSubmission {
+				submission.DateOfBirth = "2007-03-02"
+				return submission
+			},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.EqualError(t, err, "invalid KYC profile: the user must be at least 18 years old")
+			},
+		},
+		{
+			name: "Failure - Invalid date of birth",
+			input: func(submission KYCSubmission) KYCSubmission {
+				submission.DateOfBirth = "17/05/1990"
+				return submission
+			},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.ErrorIs(t, err, ErrInvalidKYCProfile)
+			},
+		},
+		{
+			name: "Failure - Invalid nationality",
+			input: func(submission KYCSubmission) KYCSubmission {
+				submission.Nationality = "USA"
+				return submission
+			},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.EqualError(t, err, "invalid KYC profile: country and nationality must be ISO 3166-1 alpha-2 codes")
+			},
+		},
+		{
+			name: "Failure - Unsupported document type",
+			input: func(submission KYCSubmission) KYCSubmission {
+				submission.IDDocumentType = "library_card"
+				return submission
+			},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.ErrorIs(t, err, ErrInvalidKYCProfile)
+			},
+		},
+		{
+			name: "Failure - Document number too short",
+			input: func(submission KYCSubmission) KYCSubmission {
+				submission.IDDocumentNumber = "12-3"
+				return submission
+			},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(1)).Return("john_doe", nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.EqualError(t, err, "invalid KYC profile: ID document number must be 4 to 32 letters or digits")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, kycRepositoryMock, userRepositoryMock, paymentRepositoryMock := newTestKYCService(ctrl, now)
+			tt.on(kycRepositoryMock, userRepositoryMock, paymentRepositoryMock)
+
+			profile, err := service.SubmitProfile(1, tt.input(submission))
+			tt.assertFunc(t, profile, err)
+		})
+	}
+}
+
+func TestGetKYCProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, kycRepositoryMock, userRepositoryMock, _ := newTestKYCService(ctrl, time.Now())
+	kycRepositoryMock.EXPECT().GetProfile(int64(2)).Return(repository.KYCProfile{}, sql.ErrNoRows)
+	userRepositoryMock.EXPECT().GetUserName(int64(2)).Return("jane_smith", nil)
+	kycRepositoryMock.EXPECT().GetProfile(int64(9)).Return(repository.KYCProfile{}, sql.ErrNoRows)
+	userRepositoryMock.EXPECT().GetUserName(int64(9)).Return("", sql.ErrNoRows)
+
+	profile, err := service.GetProfile(2)
+	assert.NoError(t, err)
+	assert.Equal(t, repository.KYCProfile{UserID: 2, Status: KYCStatusUnverified, Tier: KYCTierNone}, profile)
+
+	_, err = service.GetProfile(9)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestReviewKYCProfile(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	pending := repository.KYCProfile{UserID: 1, LegalName: "John Doe", Status: KYCStatusPending}
+
+	type input struct {
+		status   string
+		tier     int
+		reviewer string
+		note     string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, profile repository.KYCProfile, err error)
+	}{
+		{
+			name:  "Success - Profile verified with tier 1",
+			input: input{status: KYCStatusVerified, tier: KYCTierBasic, reviewer: "alice"},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(pending, nil)
+				kycRepositoryMock.EXPECT().ReviewProfile(int64(1), KYCStatusVerified, KYCTierBasic, "alice", "", now).Return(nil)
+				paymentRepositoryMock.EXPECT().InvalidateUserVerdicts([]int64{1}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, KYCStatusVerified, profile.Status)
+				assert.Equal(t, KYCTierBasic, profile.Tier)
+				assert.Equal(t, &now, profile.ReviewedAt)
+			},
+		},
+		{
+			name:  "Success - Rejection clears the tier",
+			input: input{status: KYCStatusRejected, tier: KYCTierFull, reviewer: "alice", note: "document expired"},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(pending, nil)
+				kycRepositoryMock.EXPECT().ReviewProfile(int64(1), KYCStatusRejected, KYCTierNone, "alice", "document expired", now).Return(nil)
+				paymentRepositoryMock.EXPECT().InvalidateUserVerdicts([]int64{1}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, KYCTierNone, profile.Tier)
+			},
+		},
+		{
+			name:  "Success - Invalidation failure does not fail the review",
+			input: input{status: KYCStatusRejected, reviewer: "alice", note: "document forged"},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(pending, nil)
+				kycRepositoryMock.EXPECT().ReviewProfile(int64(1), KYCStatusRejected, KYCTierNone, "alice", "document forged", now).Return(nil)
+				paymentRepositoryMock.EXPECT().InvalidateUserVerdicts([]int64{1}).Return(errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, KYCStatusRejected, profile.Status)
+			},
+		},
+		{
+			name:  "Failure - Verification without a tier",
+			input: input{status: KYCStatusVerified, reviewer: "alice"},
+			on:    func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.EqualError(t, err, "invalid KYC review: a verified profile must get tier 1 or 2")
+			},
+		},
+		{
+			name:  "Failure - Rejection without a reason",
+			input: input{status: KYCStatusRejected, reviewer: "alice"},
+			on:    func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.ErrorIs(t, err, ErrInvalidKYCReview)
+			},
+		},
+		{
+			name:  "Failure - Profile already reviewed",
+			input: input{status: KYCStatusVerified, tier: KYCTierFull, reviewer: "alice"},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(repository.KYCProfile{UserID: 1, Status: KYCStatusVerified, Tier: KYCTierBasic}, nil)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.EqualError(t, err, "KYC profile is not pending review: profile is verified")
+			},
+		},
+		{
+			name:  "Failure - Profile replaced during the review",
+			input: input{status: KYCStatusVerified, tier: KYCTierFull, reviewer: "alice"},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(pending, nil)
+				kycRepositoryMock.EXPECT().ReviewProfile(int64(1), KYCStatusVerified, KYCTierFull, "alice", "", now).Return(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.ErrorIs(t, err, ErrKYCProfileNotPending)
+			},
+		},
+		{
+			name:  "Failure - Profile not found",
+			input: input{status: KYCStatusVerified, tier: KYCTierFull, reviewer: "alice"},
+			on: func(kycRepositoryMock *mock.MockKYCRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(repository.KYCProfile{}, sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, profile repository.KYCProfile, err error) {
+				assert.ErrorIs(t, err, ErrKYCProfileNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, kycRepositoryMock, _, paymentRepositoryMock := newTestKYCService(ctrl, now)
+			tt.on(kycRepositoryMock, paymentRepositoryMock)
+
+			profile, err := service.ReviewProfile(1, tt.input.status, tt.input.tier, tt.input.reviewer, tt.input.note)
+			tt.assertFunc(t, profile, err)
+		})
+	}
+}
+
+func TestRevealIDDocumentNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, kycRepositoryMock, _, _ := newTestKYCService(ctrl, time.Now())
+	encrypted, err := service.cipher.encrypt("X123456789")
+	assert.NoError(t, err)
+
+	kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(repository.KYCProfile{UserID: 1, IDDocumentNumber: encrypted}, nil)
+	kycRepositoryMock.EXPECT().GetProfile(int64(2)).Return(repository.KYCProfile{}, errors.New("database error"))
+
+	documentNumber, err := service.RevealIDDocumentNumber(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "X123456789", documentNumber)
+
+	_, err = service.RevealIDDocumentNumber(2)
+	assert.EqualError(t, err, "database error")
+}
+
+func TestFieldCipher(t *testing.T) {
+	cipher := newFieldCipher("key-1")
+
+	first, err := cipher.encrypt("X123456789")
+	assert.NoError(t, err)
+	second, err := cipher.encrypt("X123456789")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second, "every encryption uses a new nonce")
+
+	plaintext, err := cipher.decrypt(first)
+	assert.NoError(t, err)
+	assert.Equal(t, "X123456789", plaintext)
+
+	_, err = newFieldCipher("key-2").decrypt(first)
+	assert.Error(t, err)
+	_, err = cipher.decrypt("not base64!")
+	assert.Error(t, err)
+}
+
+func TestNewKYCServiceKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr string
+	}{
+		{name: "random key", key: "q3Zk9vX1yW2uT8rS7pO6nM5lK4jH3gF2"},
+		{name: "not set", key: "", wantErr: "KYC_ENCRYPTION_KEY is not set"},
+		{name: "blank", key: "  ", wantErr: "KYC_ENCRYPTION_KEY is not set"},
+		{name: "placeholder", key: "change-me-too", wantErr: `KYC_ENCRYPTION_KEY is set to the placeholder "change-me-too", set a random key`},
+		{name: "placeholder in another case", key: "CHANGE-ME", wantErr: `KYC_ENCRYPTION_KEY is set to the placeholder "CHANGE-ME", set a random key`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KYC_ENCRYPTION_KEY", tt.key)
+
+			kycService, err := NewKYCService(nil, nil, nil)
+			_, sarErr := NewSARService(nil, nil, nil)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.EqualError(t, sarErr, tt.wantErr)
+				assert.Nil(t, kycService)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, sarErr)
+			assert.NotNil(t, kycService)
+		})
+	}
+}
+
+func TestLoadKYCTierLimits(t *testing.T) {
+	t.Setenv("KYC_TIER0_LIMIT", "50")
+	t.Setenv("KYC_TIER1_LIMIT", "invalid")
+	t.Setenv("KYC_TIER2_LIMIT", "")
+
+	limits := loadKYCTierLimits()
+
+	assert.Equal(t, kycTierLimits{50, defaultTier1Limit, defaultTier2Limit}, limits)
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ListTypeDeny  = "deny"
+	ListTypeAllow = "allow"
+
+	EntryTypeBIN             = "bin"
+	EntryTypeCardFingerprint = "card_fingerprint"
+	EntryTypeIP              = "ip"
+	EntryTypeEmailDomain     = "email_domain"
+	EntryTypeDeviceID        = "device_id"
+	EntryTypeMerchantID      = "merchant_id"
+
+	maxListValueLength = 128
+)
+
+var (
+	ErrListEntryNotFound = errors.New("list entry not found")
+	ErrListEntryExists   = errors.New("list entry already exists")
+	ErrInvalidListEntry  = errors.New("invalid list entry")
+
+	binPattern         = regexp.MustCompile(`^[0-9]{6,8}$`)
+	fingerprintPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	domainPattern      = regexp.MustCompile(`^([a-z0-9-]+\.)+[a-z]{2,}$`)
+)
+
+// ListAttributes are the payment details checked against the deny and allow lists.
+type ListAttributes struct {
+	CardNumber string
+	IPAddress  string
+	Email      string
+	DeviceID   string
+	MerchantID string
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source list_service.go -destination mock/list_service_mock.go -package mock
+type ListService interface {
+	AddEntry(entry repository.ListEntry) (repository.ListEntry, error)
+	ImportEntries(entries []repository.ListEntry) (int, error)
+	ListEntries(listType string, entryType string) ([]repository.ListEntry, error)
+	UpdateEntry(entryID int64, reason string, expiresAt *time.Time) (repository.ListEntry, error)
+	DeleteEntry(entryID int64) error
+}
+
+type listService struct {
+	listEntryRepository repository.ListEntryRepository
+	paymentRepository   repository.PaymentRepository
+	now                 func() time.Time
+}
+
+// NewListService tells payment-service to drop every cached verdict whenever the lists change, as an entry may match
+// the payments of any user.
+func NewListService(listEntryRepository repository.ListEntryRepository, paymentRepository repository.PaymentRepository) ListService {
+	return &listService{
+		listEntryRepository: listEntryRepository,
+		paymentRepository:   paymentRepository,
+		now:                 func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *listService) AddEntry(entry repository.ListEntry) (repository.ListEntry, error) {
+	now := s.now()
+	entry, err := normalizeListEntry(entry, now)
+	if err != nil {
+		return repository.ListEntry{}, err
+	}
+
+	entry.CreatedAt, entry.UpdatedAt = now, now
+	entry.ID, err = s.listEntryRepository.CreateEntry(entry)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "UNIQUE constraint failed") {
+			return repository.ListEntry{}, ErrListEntryExists
+		}
+		return repository.ListEntry{}, err
+	}
+	invalidateAllVerdicts(s.paymentRepository)
+
+	return entry, nil
+}
+
+// ImportEntries validates every entry before storing any of them, so a file with a bad row is rejected as a whole.
+// Entries already on the lists are updated, which makes re-importing the same file safe.
+func (s *listService) ImportEntries(entries []repository.ListEntry) (int, error) {
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("%w: no entries to import", ErrInvalidListEntry)
+	}
+
+	now := s.now()
+	normalized := make([]repository.ListEntry, 0, len(entries))
+	for i, entry := range entries {
+		entry, err := normalizeListEntry(entry, now)
+		if err != nil {
+			return 0, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		entry.CreatedAt, entry.UpdatedAt = now, now
+		normalized = append(normalized, entry)
+	}
+
+	if err := s.listEntryRepository.UpsertEntries(normalized); err != nil {
+		return 0, err
+	}
+	invalidateAllVerdicts(s.paymentRepository)
+
+	return len(normalized), nil
+}
+
+func (s *listService) ListEntries(listType string, entryType string) ([]repository.ListEntry, error) {
+	return s.listEntryRepository.ListEntries(listType, entryType)
+}
+
+func (s *listService) UpdateEntry(entryID int64, reason string, expiresAt *time.Time) (repository.ListEntry, error) {
+	now := s.now()
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return repository.ListEntry{}, fmt.Errorf("%w: reason is required", ErrInvalidListEntry)
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return repository.ListEntry{}, fmt.Errorf("%w: expiry must be in the future", ErrInvalidListEntry)
+	}
+
+	if err := s.listEntryRepository.UpdateEntry(entryID, reason, utcTime(expiresAt), now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ListEntry{}, ErrListEntryNotFound
+		}
+		return repository.ListEntry{}, err
+	}
+	// a new expiry may lift or extend the entry
+	invalidateAllVerdicts(s.paymentRepository)
+
+	entry, err := s.listEntryRepository.GetEntry(entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ListEntry{}, ErrListEntryNotFound
+		}
+		return repository.ListEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (s *listService) DeleteEntry(entryID int64) error {
+	if err := s.listEntryRepository.DeleteEntry(entryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrListEntryNotFound
+		}
+		return err
+	}
+	invalidateAllVerdicts(s.paymentRepository)
+
+	return nil
+}
+
+// normalizeListEntry validates the entry and puts its value in the canonical form used for matching.
+func normalizeListEntry(entry repository.ListEntry, now time.Time) (repository.ListEntry, error) {
+	entry.ListType = strings.ToLower(strings.TrimSpace(entry.ListType))
+	if entry.ListType != ListTypeDeny && entry.ListType != ListTypeAllow {
+		return repository.ListEntry{}, fmt.Errorf("%w: list type must be %s or %s", ErrInvalidListEntry, ListTypeDeny, ListTypeAllow)
+	}
+
+	entry.Reason = strings.TrimSpace(entry.Reason)
+	if entry.Reason == "" {
+		return repository.ListEntry{}, fmt.Errorf("%w: reason is required", ErrInvalidListEntry)
+	}
+
+	if entry.ExpiresAt != nil && !entry.ExpiresAt.After(now) {
+		return repository.ListEntry{}, fmt.Errorf("%w: expiry must be in the future", ErrInvalidListEntry)
+	}
+	entry.ExpiresAt = utcTime(entry.ExpiresAt)
+
+	value, err := normalizeListValue(strings.ToLower(strings.TrimSpace(entry.EntryType)), strings.TrimSpace(entry.Value))
+	if err != nil {
+		return repository.ListEntry{}, fmt.Errorf("%w: %s", ErrInvalidListEntry, err)
+	}
+	entry.EntryType = strings.ToLower(strings.TrimSpace(entry.EntryType))
+	entry.Value = value
+
+	return entry, nil
+}
+
+func normalizeListValue(entryType string, value string) (string, error) {
+	switch entryType {
+	case EntryTypeBIN:
+		// Either a BIN prefix (411111) or an inclusive range of prefixes with the same length (411100-411199).
+		start, end, isRange := strings.Cut(value, "-")
+		if !binPattern.MatchString(start) || (isRange && (!binPattern.MatchString(end) || len(start) != len(end) || start > end)) {
+			return "", fmt.Errorf("invalid BIN or BIN range: %s", value)
+		}
+		return value, nil
+	case EntryTypeCardFingerprint:
+		value = strings.ToLower(value)
+		if !fingerprintPattern.MatchString(value) {
+			return "", fmt.Errorf("card fingerprint must be a hex SHA-256: %s", value)
+		}
+		return value, nil
+	case EntryTypeIP:
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String(), nil
+		}
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			return network.String(), nil
+		}
+		return "", fmt.Errorf("invalid IP address or CIDR: %s", value)
+	case EntryTypeEmailDomain:
+		value = strings.TrimPrefix(strings.ToLower(value), "@")
+		if !domainPattern.MatchString(value) {
+			return "", fmt.Errorf("invalid email domain: %s", value)
+		}
+		return value, nil
+	case EntryTypeDeviceID, EntryTypeMerchantID:
+		if value == "" || len(value) > maxListValueLength {
+			return "", fmt.Errorf("%s must be between 1 and %d characters", entryType, maxListValueLength)
+		}
+		return value, nil
+	}
+
+	return "", fmt.Errorf("unknown entry type: %s", entryType)
+}
+
+// matchListEntries returns every active entry of both lists matching the payment, and the deny entry blocking it, if any.
+// An allow entry only overrides deny entries of its own type, e.g. an allowed IP inside a denied CIDR.
+func matchListEntries(listEntryRepository repository.ListEntryRepository, attributes ListAttributes, now time.Time) ([]repository.ListEntry, *repository.ListEntry, error) {
+	matches, denied, err := matchListEntriesBatch(listEntryRepository, []ListAttributes{attributes}, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return matches[0], denied[0], nil
+}
+
+// matchListEntriesBatch matches many payments at once, with a query per entry type whatever the number of payments.
+// The matches and deny entries are returned in the order of the payments.
+func matchListEntriesBatch(listEntryRepository repository.ListEntryRepository, attributes []ListAttributes, now time.Time) ([][]repository.ListEntry, []*repository.ListEntry, error) {
+	exactTypes := []string{EntryTypeCardFingerprint, EntryTypeEmailDomain, EntryTypeDeviceID, EntryTypeMerchantID}
+
+	exactValues := make([]map[string][]string, len(attributes))
+	requested := map[string][]string{}
+	seen := map[string]bool{}
+	var hasDigits, hasIP bool
+	for i, payment := range attributes {
+		exactValues[i] = map[string][]string{
+			EntryTypeCardFingerprint: nonEmpty(cardFingerprintOf(payment.CardNumber)),
+			EntryTypeEmailDomain:     emailDomains(payment.Email),
+			EntryTypeDeviceID:        nonEmpty(payment.DeviceID),
+			EntryTypeMerchantID:      nonEmpty(payment.MerchantID),
+		}
+		for _, entryType := range exactTypes {
+			for _, value := range exactValues[i][entryType] {
+				if !seen[entryType+":"+value] {
+					seen[entryType+":"+value] = true
+					requested[entryType] = append(requested[entryType], value)
+				}
+			}
+		}
+		hasDigits = hasDigits || cardDigits(payment.CardNumber) != ""
+		hasIP = hasIP || net.ParseIP(payment.IPAddress) != nil
+	}
+
+	found := map[string][]repository.ListEntry{}
+	for _, entryType := range exactTypes {
+		if len(requested[entryType]) == 0 {
+			continue
+		}
+		entries, err := listEntryRepository.FindActiveEntries(entryType, requested[entryType], now)
+		if err != nil {
+			return nil, nil, err
+		}
+		found[entryType] = entries
+	}
+
+	var binEntries, ipEntries []repository.ListEntry
+	if hasDigits {
+		entries, err := listEntryRepository.GetActiveEntries(EntryTypeBIN, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		binEntries = entries
+	}
+	if hasIP {
+		entries, err := listEntryRepository.GetActiveEntries(EntryTypeIP, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		ipEntries = entries
+	}
+
+	matches := make([][]repository.ListEntry, len(attributes))
+	denied := make([]*repository.ListEntry, len(attributes))
+	for i, payment := range attributes {
+		for _, entryType := range exactTypes {
+			for _, entry := range found[entryType] {
+				if slices.Contains(exactValues[i][entryType], entry.Value) {
+					matches[i] = append(matches[i], entry)
+				}
+			}
+		}
+
+		if digits := cardDigits(payment.CardNumber); digits != "" {
+			for _, entry := range binEntries {
+				if binMatches(entry.Value, digits) {
+					matches[i] = append(matches[i], entry)
+				}
+			}
+		}
+
+		if ip := net.ParseIP(payment.IPAddress); ip != nil {
+			for _, entry := range ipEntries {
+				if ipMatches(entry.Value, ip) {
+					matches[i] = append(matches[i], entry)
+				}
+			}
+		}
+
+		denied[i] = deniedEntry(matches[i])
+	}
+
+	return matches, denied, nil
+}
+
+// deniedEntry returns the first deny entry not overridden by an allow entry of the same type.
+func deniedEntry(matches []repository.ListEntry) *repository.ListEntry {
+	allowed := map[string]bool{}
+	for _, entry := range matches {
+		if entry.ListType == ListTypeAllow {
+			allowed[entry.EntryType] = true
+		}
+	}
+	for i, entry := range matches {
+		if entry.ListType == ListTypeDeny && !allowed[entry.EntryType] {
+			return &matches[i]
+		}
+	}
+
+	return nil
+}
+
+func binMatches(value string, digits string) bool {
+	start, end, isRange := strings.Cut(value, "-")
+	if len(digits) < len(start) {
+		return false
+	}
+
+	prefix := digits[:len(start)]
+	if !isRange {
+		return prefix == start
+	}
+	return prefix >= start && prefix <= end
+}
+
+func ipMatches(value string, ip net.IP) bool {
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network.Contains(ip)
+	}
+	return ip.Equal(net.ParseIP(value))
+}
+
+// emailDomains returns the domain of the email and its parent domains, so that example.com also matches mail.example.com.
+func emailDomains(email string) []string {
+	_, domain, found := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !found {
+		return nil
+	}
+
+	var domains []string
+	for strings.Contains(domain, ".") {
+		domains = append(domains, domain)
+		_, domain, _ = strings.Cut(domain, ".")
+	}
+	return domains
+}
+
+func cardFingerprintOf(cardNumber string) string {
+	if cardDigits(cardNumber) == "" {
+		return ""
+	}
+	return CardFingerprint(cardNumber)
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestListService(ctrl *gomock.Controller, now time.Time) (*listService, *mock.MockListEntryRepository, *mock.MockPaymentRepository) {
+	listEntryRepositoryMock := mock.NewMockListEntryRepository(ctrl)
+	paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
+	return &listService{
+		listEntryRepository: listEntryRepositoryMock,
+		paymentRepository:   paymentRepositoryMock,
+		now:                 func() time.Time { return now },
+	}, listEntryRepositoryMock, paymentRepositoryMock
+}
+
+func TestAddListEntry(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		input      repository.ListEntry
+		on         func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, entry repository.ListEntry, err error)
+	}{
+		{
+			name:  "Success - CIDR normalized",
+			input: repository.ListEntry{ListType: "Deny", EntryType: "ip", Value: " 203.0.113.7/24 ", Reason: "botnet"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				listEntryRepositoryMock.EXPECT().CreateEntry(repository.ListEntry{ListType: "deny", EntryType: "ip", Value: "203.0.113.0/24", Reason: "botnet", CreatedAt: now, UpdatedAt: now}).
+					Return(int64(1), nil)
+				paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), entry.ID)
+				assert.Equal(t, "203.0.113.0/24", entry.Value)
+			},
+		},
+		{
+			name:  "Success - Email domain normalized",
+			input: repository.ListEntry{ListType: "deny", EntryType: "email_domain", Value: "@Mailinator.com", Reason: "disposable"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				listEntryRepositoryMock.EXPECT().CreateEntry(gomock.Any()).Return(int64(2), nil)
+				paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "mailinator.com", entry.Value)
+			},
+		},
+		{
+			name:  "Success - Invalidation failure does not fail the entry",
+			input: repository.ListEntry{ListType: "deny", EntryType: "device_id", Value: "device-1", Reason: "emulator"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				listEntryRepositoryMock.EXPECT().CreateEntry(gomock.Any()).Return(int64(3), nil)
+				paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(3), entry.ID)
+			},
+		},
+		{
+			name:  "Failure - Entry already on the list",
+			input: repository.ListEntry{ListType: "deny", EntryType: "bin", Value: "411111", Reason: "compromised issuer"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				listEntryRepositoryMock.EXPECT().CreateEntry(gomock.Any()).Return(int64(0), errors.New("UNIQUE constraint failed: list_entries.list_type, list_entries.entry_type, list_entries.value"))
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.ErrorIs(t, err, ErrListEntryExists)
+			},
+		},
+		{
+			name:  "Failure - Invalid BIN range",
+			input: repository.ListEntry{ListType: "deny", EntryType: "bin", Value: "411199-411100", Reason: "compromised issuer"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.ErrorIs(t, err, ErrInvalidListEntry)
+				assert.EqualError(t, err, "invalid list entry: invalid BIN or BIN range: 411199-411100")
+			},
+		},
+		{
+			name:  "Failure - Expiry in the past",
+			input: repository.ListEntry{ListType: "deny", EntryType: "device_id", Value: "device-1", Reason: "emulator", ExpiresAt: &past},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.EqualError(t, err, "invalid list entry: expiry must be in the future")
+			},
+		},
+		{
+			name:  "Failure - Unknown list type",
+			input: repository.ListEntry{ListType: "watch", EntryType: "device_id", Value: "device-1", Reason: "emulator"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.EqualError(t, err, "invalid list entry: list type must be deny or allow")
+			},
+		},
+		{
+			name:  "Failure - Missing reason",
+			input: repository.ListEntry{ListType: "deny", EntryType: "merchant_id", Value: "merchant-1"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, entry repository.ListEntry, err error) {
+				assert.EqualError(t, err, "invalid list entry: reason is required")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, listEntryRepositoryMock, paymentRepositoryMock := newTestListService(ctrl, now)
+			tt.on(listEntryRepositoryMock, paymentRepositoryMock)
+
+			entry, err := service.AddEntry(tt.input)
+			tt.assertFunc(t, entry, err)
+		})
+	}
+}
+
+func TestImportListEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Success - All entries imported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service, listEntryRepositoryMock, paymentRepositoryMock := newTestListService(ctrl, now)
+		listEntryRepositoryMock.EXPECT().UpsertEntries([]repository.ListEntry{
+			{ListType: "deny", EntryType: "device_id", Value: "device-1", Reason: "emulator", CreatedAt: now, UpdatedAt: now},
+			{ListType: "allow", EntryType: "ip", Value: "203.0.113.7", Reason: "office", CreatedAt: now, UpdatedAt: now},
+		}).Return(nil)
+		paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+
+		imported, err := service.ImportEntries([]repository.ListEntry{
+			{ListType: "deny", EntryType: "device_id", Value: "device-1", Reason: "emulator"},
+			{ListType: "allow", EntryType: "IP", Value: "203.0.113.7", Reason: "office"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, imported)
+	})
+
+	t.Run("Failure - Invalid row rejects the import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service, _, _ := newTestListService(ctrl, now)
+
+		imported, err := service.ImportEntries([]repository.ListEntry{
+			{ListType: "deny", EntryType: "device_id", Value: "device-1", Reason: "emulator"},
+			{ListType: "deny", EntryType: "ip", Value: "not-an-ip", Reason: "botnet"},
+		})
+
+		assert.Zero(t, imported)
+		assert.ErrorIs(t, err, ErrInvalidListEntry)
+		assert.EqualError(t, err, "row 2: invalid list entry: invalid IP address or CIDR: not-an-ip")
+	})
+}
+
+func TestUpdateAndDeleteListEntryService(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.AddDate(0, 0, 7)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, listEntryRepositoryMock, paymentRepositoryMock := newTestListService(ctrl, now)
+
+	listEntryRepositoryMock.EXPECT().UpdateEntry(int64(1), "confirmed", &expiresAt, now).Return(nil)
+	paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+	listEntryRepositoryMock.EXPECT().GetEntry(int64(1)).Return(repository.ListEntry{ID: 1, Reason: "confirmed", ExpiresAt: &expiresAt}, nil)
+	entry, err := service.UpdateEntry(1, "confirmed", &expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "confirmed", entry.Reason)
+
+	listEntryRepositoryMock.EXPECT().UpdateEntry(int64(2), "confirmed", nil, now).Return(sql.ErrNoRows)
+	_, err = service.UpdateEntry(2, "confirmed", nil)
+	assert.ErrorIs(t, err, ErrListEntryNotFound)
+
+	listEntryRepositoryMock.EXPECT().DeleteEntry(int64(1)).Return(nil)
+	paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+	assert.NoError(t, service.DeleteEntry(1))
+
+	listEntryRepositoryMock.EXPECT().DeleteEntry(int64(2)).Return(sql.ErrNoRows)
+	assert.ErrorIs(t, service.DeleteEntry(2), ErrListEntryNotFound)
+}
+
+func TestMatchListEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	cidrDeny := repository.ListEntry{ID: 1, ListType: ListTypeDeny, EntryType: EntryTypeIP, Value: "203.0.113.0/24", Reason: "botnet"}
+	ipAllow := repository.ListEntry{ID: 2, ListType: ListTypeAllow, EntryType: EntryTypeIP, Value: "203.0.113.7", Reason: "office"}
+	binDeny := repository.ListEntry{ID: 3, ListType: ListTypeDeny, EntryType: EntryTypeBIN, Value: "411100-411199", Reason: "compromised issuer"}
+
+	tests := []struct {
+		name       string
+		attributes ListAttributes
+		on         func(listEntryRepositoryMock *mock.MockListEntryRepository)
+		assertFunc func(t *testing.T, matches []repository.ListEntry, denied *repository.ListEntry)
+	}{
+		{
+			name:       "Denied - IP inside a denied CIDR",
+			attributes: ListAttributes{IPAddress: "203.0.113.9"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository) {
+				listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeIP, now).Return([]repository.ListEntry{cidrDeny, ipAllow}, nil)
+			},
+			assertFunc: func(t *testing.T, matches []repository.ListEntry, denied *repository.ListEntry) {
+				assert.Equal(t, []repository.ListEntry{cidrDeny}, matches)
+				assert.Equal(t, &cidrDeny, denied)
+			},
+		},
+		{
+			name:       "Allowed - Allowed IP overrides the denied CIDR",
+			attributes: ListAttributes{IPAddress: "203.0.113.7"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository) {
+				listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeIP, now).Return([]repository.ListEntry{cidrDeny, ipAllow}, nil)
+			},
+			assertFunc: func(t *testing.T, matches []repository.ListEntry, denied *repository.ListEntry) {
+				assert.Equal(t, []repository.ListEntry{cidrDeny, ipAllow}, matches)
+				assert.Nil(t, denied)
+			},
+		},
+		{
+			name:       "Denied - Allowed IP does not override a denied BIN",
+			attributes: ListAttributes{CardNumber: "4111-5000-0000-0001", IPAddress: "203.0.113.7"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository) {
+				listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, []string{CardFingerprint("4111500000000001")}, now).Return(nil, nil)
+				listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, now).Return([]repository.ListEntry{binDeny}, nil)
+				listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeIP, now).Return([]repository.ListEntry{ipAllow}, nil)
+			},
+			assertFunc: func(t *testing.T, matches []repository.ListEntry, denied *repository.ListEntry) {
+				assert.Equal(t, []repository.ListEntry{binDeny, ipAllow}, matches)
+				assert.Equal(t, &binDeny, denied)
+			},
+		},
+		{
+			name:       "Allowed - BIN outside the range",
+			attributes: ListAttributes{CardNumber: "4112000000000001"},
+			on: func(listEntryRepositoryMock *mock.MockListEntryRepository) {
+				listEntryRepositoryMock.EXPECT().FindActiveEntries(EntryTypeCardFingerprint, gomock.Any(), now).Return(nil, nil)
+				listEntryRepositoryMock.EXPECT().GetActiveEntries(EntryTypeBIN, now).Return([]repository.ListEntry{binDeny}, nil)
+			},
+			assertFunc: func(t *testing.T, matches []repository.ListEntry, denied *repository.ListEntry) {
+				assert.Empty(t, matches)
+				assert.Nil(t, denied)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			listEntryRepositoryMock := mock.NewMockListEntryRepository(ctrl)
+			tt.on(listEntryRepositoryMock)
+
+			matches, denied, err := matchListEntries(listEntryRepositoryMock, tt.attributes, now)
+
+			assert.NoError(t, err)
+			tt.assertFunc(t, matches, denied)
+		})
+	}
+}
+
+func TestListMatchingHelpers(t *testing.T) {
+	assert.True(t, binMatches("411111", "4111111111111111"))
+	assert.False(t, binMatches("411111", "4111"))
+	assert.True(t, binMatches("41110000-41119999", "4111500000000001"))
+	assert.True(t, ipMatches("2001:db8::/32", net.ParseIP("2001:db8::1")))
+	assert.False(t, ipMatches("198.51.100.1", net.ParseIP("198.51.100.2")))
+	assert.Equal(t, []string{"mail.example.co.uk", "example.co.uk", "co.uk"}, emailDomains("Alice@Mail.Example.co.uk"))
+	assert.Nil(t, emailDomains("not-an-email"))
+}
+
+func TestCardFingerprint(t *testing.T) {
+	assert.Equal(t, CardFingerprint("4111 1111 1111 1111"), CardFingerprint("4111-1111-1111-1111"))
+	assert.NotEqual(t, CardFingerprint("4111111111111111"), CardFingerprint("4111111111111112"))
+	assert.Regexp(t, `^[0-9a-f]{64}$`, CardFingerprint("4111111111111111"))
+
+	unkeyed := CardFingerprint("4111111111111111")
+	t.Cleanup(func() { cardFingerprintKey = nil })
+	t.Setenv("CARD_FINGERPRINT_KEY", "another-key")
+	assert.NoError(t, LoadCardFingerprintKey())
+	assert.NotEqual(t, unkeyed, CardFingerprint("4111111111111111"))
+
+	t.Setenv("CARD_FINGERPRINT_KEY", "")
+	assert.EqualError(t, LoadCardFingerprintKey(), "CARD_FINGERPRINT_KEY is not set")
+	t.Setenv("CARD_FINGERPRINT_KEY", "change-me")
+	assert.EqualError(t, LoadCardFingerprintKey(), `CARD_FINGERPRINT_KEY is set to the placeholder "change-me", set a random key`)
+}
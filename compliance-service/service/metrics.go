@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Reasons of the failed logins to the report page counted by failed_logins_total.
+const (
+	failedLoginUnknownUser   = "unknown_user"
+	failedLoginInvalidSecret = "invalid_secret"
+)
+
+var (
+	cardsReportedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cards_reported_total",
+		Help: "Cards blocked by their owner from the report page.",
+	})
+
+	failedLoginsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "failed_logins_total",
+		Help: "Stolen card reports rejected for their credentials, by reason (unknown_user or invalid_secret).",
+	}, []string{"reason"})
+)
@@ -0,0 +1,135 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: card_import_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCardImportService is a mock of CardImportService interface.
+type MockCardImportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCardImportServiceMockRecorder
+}
+
+// MockCardImportServiceMockRecorder is the mock recorder for MockCardImportService.
+type MockCardImportServiceMockRecorder struct {
+	mock *MockCardImportService
+}
+
+// NewMockCardImportService creates a new mock instance.
+func NewMockCardImportService(ctrl *gomock.Controller) *MockCardImportService {
+	mock := &MockCardImportService{ctrl: ctrl}
+	mock.recorder = &MockCardImportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCardImportService) EXPECT() *MockCardImportServiceMockRecorder {
+	return m.recorder
+}
+
+// GetImport mocks base method.
+func (m *MockCardImportService) GetImport(importID int64) (repository.CardImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImport", importID)
+	ret0, _ := ret[0].(repository.CardImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImport indicates an expected call of GetImport.
+func (mr *MockCardImportServiceMockRecorder) GetImport(importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImport", reflect.TypeOf((*MockCardImportService)(nil).GetImport), importID)
+}
+
+// ResumeImport mocks base method.
+func (m *MockCardImportService) ResumeImport(importID int64, feed io.Reader) (repository.CardImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeImport", importID, feed)
+	ret0, _ := ret[0].(repository.CardImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeImport indicates an expected call of ResumeImport.
+func (mr *MockCardImportServiceMockRecorder) ResumeImport(importID, feed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeImport", reflect.TypeOf((*MockCardImportService)(nil).ResumeImport), importID, feed)
+}
+
+// StartImport mocks base method.
+func (m *MockCardImportService) StartImport(source, fileName, format string, feed io.Reader) (repository.CardImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartImport", source, fileName, format, feed)
+	ret0, _ := ret[0].(repository.CardImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartImport indicates an expected call of StartImport.
+func (mr *MockCardImportServiceMockRecorder) StartImport(source, fileName, format, feed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartImport", reflect.TypeOf((*MockCardImportService)(nil).StartImport), source, fileName, format, feed)
+}
+
+// WriteReport mocks base method.
+func (m *MockCardImportService) WriteReport(importID int64, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteReport", importID, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteReport indicates an expected call of WriteReport.
+func (mr *MockCardImportServiceMockRecorder) WriteReport(importID, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteReport", reflect.TypeOf((*MockCardImportService)(nil).WriteReport), importID, w)
+}
+
+// MockcardFeedReader is a mock of cardFeedReader interface.
+type MockcardFeedReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockcardFeedReaderMockRecorder
+}
+
+// MockcardFeedReaderMockRecorder is the mock recorder for MockcardFeedReader.
+type MockcardFeedReaderMockRecorder struct {
+	mock *MockcardFeedReader
+}
+
+// NewMockcardFeedReader creates a new mock instance.
+func NewMockcardFeedReader(ctrl *gomock.Controller) *MockcardFeedReader {
+	mock := &MockcardFeedReader{ctrl: ctrl}
+	mock.recorder = &MockcardFeedReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcardFeedReader) EXPECT() *MockcardFeedReaderMockRecorder {
+	return m.recorder
+}
+
+// next mocks base method.
+func (m *MockcardFeedReader) next() (int64, string, error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "next")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// next indicates an expected call of next.
+func (mr *MockcardFeedReaderMockRecorder) next() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "next", reflect.TypeOf((*MockcardFeedReader)(nil).next))
+}
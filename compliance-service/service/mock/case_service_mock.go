@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: case_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	service "flarrocca/compliant-service/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCaseService is a mock of CaseService interface.
+type MockCaseService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaseServiceMockRecorder
+}
+
+// MockCaseServiceMockRecorder is the mock recorder for MockCaseService.
+type MockCaseServiceMockRecorder struct {
+	mock *MockCaseService
+}
+
+// NewMockCaseService creates a new mock instance.
+func NewMockCaseService(ctrl *gomock.Controller) *MockCaseService {
+	mock := &MockCaseService{ctrl: ctrl}
+	mock.recorder = &MockCaseServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaseService) EXPECT() *MockCaseServiceMockRecorder {
+	return m.recorder
+}
+
+// AddNote mocks base method.
+func (m *MockCaseService) AddNote(caseID int64, author, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNote", caseID, author, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNote indicates an expected call of AddNote.
+func (mr *MockCaseServiceMockRecorder) AddNote(caseID, author, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNote", reflect.TypeOf((*MockCaseService)(nil).AddNote), caseID, author, body)
+}
+
+// AssignCase mocks base method.
+func (m *MockCaseService) AssignCase(caseID int64, assignee string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignCase", caseID, assignee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignCase indicates an expected call of AssignCase.
+func (mr *MockCaseServiceMockRecorder) AssignCase(caseID, assignee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignCase", reflect.TypeOf((*MockCaseService)(nil).AssignCase), caseID, assignee)
+}
+
+// AttachTransaction mocks base method.
+func (m *MockCaseService) AttachTransaction(caseID int64, transaction repository.CaseTransaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTransaction", caseID, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTransaction indicates an expected call of AttachTransaction.
+func (mr *MockCaseServiceMockRecorder) AttachTransaction(caseID, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTransaction", reflect.TypeOf((*MockCaseService)(nil).AttachTransaction), caseID, transaction)
+}
+
+// ExportCase mocks base method.
+func (m *MockCaseService) ExportCase(caseID int64) (service.CaseBundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportCase", caseID)
+	ret0, _ := ret[0].(service.CaseBundle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportCase indicates an expected call of ExportCase.
+func (mr *MockCaseServiceMockRecorder) ExportCase(caseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportCase", reflect.TypeOf((*MockCaseService)(nil).ExportCase), caseID)
+}
+
+// ListCases mocks base method.
+func (m *MockCaseService) ListCases(status, assignee string) ([]repository.Case, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCases", status, assignee)
+	ret0, _ := ret[0].([]repository.Case)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCases indicates an expected call of ListCases.
+func (mr *MockCaseServiceMockRecorder) ListCases(status, assignee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCases", reflect.TypeOf((*MockCaseService)(nil).ListCases), status, assignee)
+}
+
+// OpenCase mocks base method.
+func (m *MockCaseService) OpenCase(userID, cardID int64, source string, transactions []repository.CaseTransaction) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenCase", userID, cardID, source, transactions)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenCase indicates an expected call of OpenCase.
+func (mr *MockCaseServiceMockRecorder) OpenCase(userID, cardID, source, transactions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenCase", reflect.TypeOf((*MockCaseService)(nil).OpenCase), userID, cardID, source, transactions)
+}
+
+// UpdateCaseStatus mocks base method.
+func (m *MockCaseService) UpdateCaseStatus(caseID int64, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCaseStatus", caseID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCaseStatus indicates an expected call of UpdateCaseStatus.
+func (mr *MockCaseServiceMockRecorder) UpdateCaseStatus(caseID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCaseStatus", reflect.TypeOf((*MockCaseService)(nil).UpdateCaseStatus), caseID, status)
+}
@@ -5,6 +5,8 @@
 package mock
 
 import (
+	repository "flarrocca/compliant-service/repository"
+	service "flarrocca/compliant-service/service"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,19 +36,90 @@ func (m *MockComplianceService) EXPECT() *MockComplianceServiceMockRecorder {
 }
 
 // CheckComplianceStatus mocks base method.
-func (m *MockComplianceService) CheckComplianceStatus(userID, cardID int64) (bool, string, error) {
+func (m *MockComplianceService) CheckComplianceStatus(check service.ComplianceCheck) (service.ComplianceResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckComplianceStatus", userID, cardID)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(string)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "CheckComplianceStatus", check)
+	ret0, _ := ret[0].(service.ComplianceResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CheckComplianceStatus indicates an expected call of CheckComplianceStatus.
-func (mr *MockComplianceServiceMockRecorder) CheckComplianceStatus(userID, cardID interface{}) *gomock.Call {
+func (mr *MockComplianceServiceMockRecorder) CheckComplianceStatus(check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckComplianceStatus", reflect.TypeOf((*MockComplianceService)(nil).CheckComplianceStatus), check)
+}
+
+// CheckComplianceStatuses mocks base method.
+func (m *MockComplianceService) CheckComplianceStatuses(checks []service.ComplianceCheck) ([]service.BatchComplianceResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckComplianceStatuses", checks)
+	ret0, _ := ret[0].([]service.BatchComplianceResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckComplianceStatuses indicates an expected call of CheckComplianceStatuses.
+func (mr *MockComplianceServiceMockRecorder) CheckComplianceStatuses(checks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckComplianceStatuses", reflect.TypeOf((*MockComplianceService)(nil).CheckComplianceStatuses), checks)
+}
+
+// InvalidateCardVerdicts mocks base method.
+func (m *MockComplianceService) InvalidateCardVerdicts(event repository.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateCardVerdicts", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateCardVerdicts indicates an expected call of InvalidateCardVerdicts.
+func (mr *MockComplianceServiceMockRecorder) InvalidateCardVerdicts(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateCardVerdicts", reflect.TypeOf((*MockComplianceService)(nil).InvalidateCardVerdicts), event)
+}
+
+// ListBlockedCards mocks base method.
+func (m *MockComplianceService) ListBlockedCards() ([]repository.BlockedCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlockedCards")
+	ret0, _ := ret[0].([]repository.BlockedCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlockedCards indicates an expected call of ListBlockedCards.
+func (mr *MockComplianceServiceMockRecorder) ListBlockedCards() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlockedCards", reflect.TypeOf((*MockComplianceService)(nil).ListBlockedCards))
+}
+
+// NotifyCardReported mocks base method.
+func (m *MockComplianceService) NotifyCardReported(event repository.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyCardReported", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyCardReported indicates an expected call of NotifyCardReported.
+func (mr *MockComplianceServiceMockRecorder) NotifyCardReported(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyCardReported", reflect.TypeOf((*MockComplianceService)(nil).NotifyCardReported), event)
+}
+
+// ReinstateCard mocks base method.
+func (m *MockComplianceService) ReinstateCard(userID, cardID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReinstateCard", userID, cardID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReinstateCard indicates an expected call of ReinstateCard.
+func (mr *MockComplianceServiceMockRecorder) ReinstateCard(userID, cardID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckComplianceStatus", reflect.TypeOf((*MockComplianceService)(nil).CheckComplianceStatus), userID, cardID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReinstateCard", reflect.TypeOf((*MockComplianceService)(nil).ReinstateCard), userID, cardID)
 }
 
 // ReportStolenCards mocks base method.
@@ -0,0 +1,75 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: event_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEventService is a mock of EventService interface.
+type MockEventService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventServiceMockRecorder
+}
+
+// MockEventServiceMockRecorder is the mock recorder for MockEventService.
+type MockEventServiceMockRecorder struct {
+	mock *MockEventService
+}
+
+// NewMockEventService creates a new mock instance.
+func NewMockEventService(ctrl *gomock.Controller) *MockEventService {
+	mock := &MockEventService{ctrl: ctrl}
+	mock.recorder = &MockEventServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventService) EXPECT() *MockEventServiceMockRecorder {
+	return m.recorder
+}
+
+// RunConsumer mocks base method.
+func (m *MockEventService) RunConsumer(ctx context.Context, consumer string, eventTypes []string, handle func(repository.Event) error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunConsumer", ctx, consumer, eventTypes, handle)
+}
+
+// RunConsumer indicates an expected call of RunConsumer.
+func (mr *MockEventServiceMockRecorder) RunConsumer(ctx, consumer, eventTypes, handle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunConsumer", reflect.TypeOf((*MockEventService)(nil).RunConsumer), ctx, consumer, eventTypes, handle)
+}
+
+// RunRelay mocks base method.
+func (m *MockEventService) RunRelay(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunRelay", ctx)
+}
+
+// RunRelay indicates an expected call of RunRelay.
+func (mr *MockEventServiceMockRecorder) RunRelay(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunRelay", reflect.TypeOf((*MockEventService)(nil).RunRelay), ctx)
+}
+
+// WaitForEvents mocks base method.
+func (m *MockEventService) WaitForEvents(ctx context.Context, after int64, eventTypes []string, limit int) ([]repository.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForEvents", ctx, after, eventTypes, limit)
+	ret0, _ := ret[0].([]repository.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitForEvents indicates an expected call of WaitForEvents.
+func (mr *MockEventServiceMockRecorder) WaitForEvents(ctx, after, eventTypes, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForEvents", reflect.TypeOf((*MockEventService)(nil).WaitForEvents), ctx, after, eventTypes, limit)
+}
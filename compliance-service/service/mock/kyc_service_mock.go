@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: kyc_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	service "flarrocca/compliant-service/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockKYCService is a mock of KYCService interface.
+type MockKYCService struct {
+	ctrl     *gomock.Controller
+	recorder *MockKYCServiceMockRecorder
+}
+
+// MockKYCServiceMockRecorder is the mock recorder for MockKYCService.
+type MockKYCServiceMockRecorder struct {
+	mock *MockKYCService
+}
+
+// NewMockKYCService creates a new mock instance.
+func NewMockKYCService(ctrl *gomock.Controller) *MockKYCService {
+	mock := &MockKYCService{ctrl: ctrl}
+	mock.recorder = &MockKYCServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKYCService) EXPECT() *MockKYCServiceMockRecorder {
+	return m.recorder
+}
+
+// GetProfile mocks base method.
+func (m *MockKYCService) GetProfile(userID int64) (repository.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", userID)
+	ret0, _ := ret[0].(repository.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockKYCServiceMockRecorder) GetProfile(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockKYCService)(nil).GetProfile), userID)
+}
+
+// ListProfiles mocks base method.
+func (m *MockKYCService) ListProfiles(status string) ([]repository.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProfiles", status)
+	ret0, _ := ret[0].([]repository.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProfiles indicates an expected call of ListProfiles.
+func (mr *MockKYCServiceMockRecorder) ListProfiles(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProfiles", reflect.TypeOf((*MockKYCService)(nil).ListProfiles), status)
+}
+
+// RevealIDDocumentNumber mocks base method.
+func (m *MockKYCService) RevealIDDocumentNumber(userID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevealIDDocumentNumber", userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevealIDDocumentNumber indicates an expected call of RevealIDDocumentNumber.
+func (mr *MockKYCServiceMockRecorder) RevealIDDocumentNumber(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevealIDDocumentNumber", reflect.TypeOf((*MockKYCService)(nil).RevealIDDocumentNumber), userID)
+}
+
+// ReviewProfile mocks base method.
+func (m *MockKYCService) ReviewProfile(userID int64, status string, tier int, reviewer, note string) (repository.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewProfile", userID, status, tier, reviewer, note)
+	ret0, _ := ret[0].(repository.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewProfile indicates an expected call of ReviewProfile.
+func (mr *MockKYCServiceMockRecorder) ReviewProfile(userID, status, tier, reviewer, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewProfile", reflect.TypeOf((*MockKYCService)(nil).ReviewProfile), userID, status, tier, reviewer, note)
+}
+
+// SubmitProfile mocks base method.
+func (m *MockKYCService) SubmitProfile(userID int64, submission service.KYCSubmission) (repository.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitProfile", userID, submission)
+	ret0, _ := ret[0].(repository.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitProfile indicates an expected call of SubmitProfile.
+func (mr *MockKYCServiceMockRecorder) SubmitProfile(userID, submission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitProfile", reflect.TypeOf((*MockKYCService)(nil).SubmitProfile), userID, submission)
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: list_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockListService is a mock of ListService interface.
+type MockListService struct {
+	ctrl     *gomock.Controller
+	recorder *MockListServiceMockRecorder
+}
+
+// MockListServiceMockRecorder is the mock recorder for MockListService.
+type MockListServiceMockRecorder struct {
+	mock *MockListService
+}
+
+// NewMockListService creates a new mock instance.
+func NewMockListService(ctrl *gomock.Controller) *MockListService {
+	mock := &MockListService{ctrl: ctrl}
+	mock.recorder = &MockListServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockListService) EXPECT() *MockListServiceMockRecorder {
+	return m.recorder
+}
+
+// AddEntry mocks base method.
+func (m *MockListService) AddEntry(entry repository.ListEntry) (repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEntry", entry)
+	ret0, _ := ret[0].(repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddEntry indicates an expected call of AddEntry.
+func (mr *MockListServiceMockRecorder) AddEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEntry", reflect.TypeOf((*MockListService)(nil).AddEntry), entry)
+}
+
+// DeleteEntry mocks base method.
+func (m *MockListService) DeleteEntry(entryID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEntry", entryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEntry indicates an expected call of DeleteEntry.
+func (mr *MockListServiceMockRecorder) DeleteEntry(entryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntry", reflect.TypeOf((*MockListService)(nil).DeleteEntry), entryID)
+}
+
+// ImportEntries mocks base method.
+func (m *MockListService) ImportEntries(entries []repository.ListEntry) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportEntries", entries)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportEntries indicates an expected call of ImportEntries.
+func (mr *MockListServiceMockRecorder) ImportEntries(entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportEntries", reflect.TypeOf((*MockListService)(nil).ImportEntries), entries)
+}
+
+// ListEntries mocks base method.
+func (m *MockListService) ListEntries(listType, entryType string) ([]repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", listType, entryType)
+	ret0, _ := ret[0].([]repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockListServiceMockRecorder) ListEntries(listType, entryType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockListService)(nil).ListEntries), listType, entryType)
+}
+
+// UpdateEntry mocks base method.
+func (m *MockListService) UpdateEntry(entryID int64, reason string, expiresAt *time.Time) (repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEntry", entryID, reason, expiresAt)
+	ret0, _ := ret[0].(repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEntry indicates an expected call of UpdateEntry.
+func (mr *MockListServiceMockRecorder) UpdateEntry(entryID, reason, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEntry", reflect.TypeOf((*MockListService)(nil).UpdateEntry), entryID, reason, expiresAt)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pep_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	service "flarrocca/compliant-service/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPEPService is a mock of PEPService interface.
+type MockPEPService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPEPServiceMockRecorder
+}
+
+// MockPEPServiceMockRecorder is the mock recorder for MockPEPService.
+type MockPEPServiceMockRecorder struct {
+	mock *MockPEPService
+}
+
+// NewMockPEPService creates a new mock instance.
+func NewMockPEPService(ctrl *gomock.Controller) *MockPEPService {
+	mock := &MockPEPService{ctrl: ctrl}
+	mock.recorder = &MockPEPServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPEPService) EXPECT() *MockPEPServiceMockRecorder {
+	return m.recorder
+}
+
+// GetRiskRating mocks base method.
+func (m *MockPEPService) GetRiskRating(userID int64) (repository.RiskRating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskRating", userID)
+	ret0, _ := ret[0].(repository.RiskRating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskRating indicates an expected call of GetRiskRating.
+func (mr *MockPEPServiceMockRecorder) GetRiskRating(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskRating", reflect.TypeOf((*MockPEPService)(nil).GetRiskRating), userID)
+}
+
+// ListRiskRatings mocks base method.
+func (m *MockPEPService) ListRiskRatings(rating string) ([]repository.RiskRating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRiskRatings", rating)
+	ret0, _ := ret[0].([]repository.RiskRating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRiskRatings indicates an expected call of ListRiskRatings.
+func (mr *MockPEPServiceMockRecorder) ListRiskRatings(rating interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRiskRatings", reflect.TypeOf((*MockPEPService)(nil).ListRiskRatings), rating)
+}
+
+// RefreshPEPList mocks base method.
+func (m *MockPEPService) RefreshPEPList() (service.PEPRefresh, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshPEPList")
+	ret0, _ := ret[0].(service.PEPRefresh)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshPEPList indicates an expected call of RefreshPEPList.
+func (mr *MockPEPServiceMockRecorder) RefreshPEPList() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshPEPList", reflect.TypeOf((*MockPEPService)(nil).RefreshPEPList))
+}
+
+// ScreenUser mocks base method.
+func (m *MockPEPService) ScreenUser(user repository.User) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScreenUser", user)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScreenUser indicates an expected call of ScreenUser.
+func (mr *MockPEPServiceMockRecorder) ScreenUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScreenUser", reflect.TypeOf((*MockPEPService)(nil).ScreenUser), user)
+}
+
+// SetRiskRating mocks base method.
+func (m *MockPEPService) SetRiskRating(userID int64, rating, reviewer, reason string) (repository.RiskRating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRiskRating", userID, rating, reviewer, reason)
+	ret0, _ := ret[0].(repository.RiskRating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRiskRating indicates an expected call of SetRiskRating.
+func (mr *MockPEPServiceMockRecorder) SetRiskRating(userID, rating, reviewer, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRiskRating", reflect.TypeOf((*MockPEPService)(nil).SetRiskRating), userID, rating, reviewer, reason)
+}
@@ -0,0 +1,156 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sar_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	service "flarrocca/compliant-service/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSARService is a mock of SARService interface.
+type MockSARService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSARServiceMockRecorder
+}
+
+// MockSARServiceMockRecorder is the mock recorder for MockSARService.
+type MockSARServiceMockRecorder struct {
+	mock *MockSARService
+}
+
+// NewMockSARService creates a new mock instance.
+func NewMockSARService(ctrl *gomock.Controller) *MockSARService {
+	mock := &MockSARService{ctrl: ctrl}
+	mock.recorder = &MockSARServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSARService) EXPECT() *MockSARServiceMockRecorder {
+	return m.recorder
+}
+
+// DraftSAR mocks base method.
+func (m *MockSARService) DraftSAR(officer string, caseID int64, activityTypes []string, narrative string) (repository.SAR, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DraftSAR", officer, caseID, activityTypes, narrative)
+	ret0, _ := ret[0].(repository.SAR)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DraftSAR indicates an expected call of DraftSAR.
+func (mr *MockSARServiceMockRecorder) DraftSAR(officer, caseID, activityTypes, narrative interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DraftSAR", reflect.TypeOf((*MockSARService)(nil).DraftSAR), officer, caseID, activityTypes, narrative)
+}
+
+// ExportSummary mocks base method.
+func (m *MockSARService) ExportSummary(officer string, sarID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportSummary", officer, sarID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportSummary indicates an expected call of ExportSummary.
+func (mr *MockSARServiceMockRecorder) ExportSummary(officer, sarID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportSummary", reflect.TypeOf((*MockSARService)(nil).ExportSummary), officer, sarID)
+}
+
+// ExportXML mocks base method.
+func (m *MockSARService) ExportXML(officer string, sarID int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportXML", officer, sarID)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportXML indicates an expected call of ExportXML.
+func (mr *MockSARServiceMockRecorder) ExportXML(officer, sarID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportXML", reflect.TypeOf((*MockSARService)(nil).ExportXML), officer, sarID)
+}
+
+// GetSAR mocks base method.
+func (m *MockSARService) GetSAR(officer string, sarID int64) (repository.SAR, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSAR", officer, sarID)
+	ret0, _ := ret[0].(repository.SAR)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSAR indicates an expected call of GetSAR.
+func (mr *MockSARServiceMockRecorder) GetSAR(officer, sarID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSAR", reflect.TypeOf((*MockSARService)(nil).GetSAR), officer, sarID)
+}
+
+// ListAccess mocks base method.
+func (m *MockSARService) ListAccess(officer string, sarID int64) ([]repository.SARAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccess", officer, sarID)
+	ret0, _ := ret[0].([]repository.SARAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccess indicates an expected call of ListAccess.
+func (mr *MockSARServiceMockRecorder) ListAccess(officer, sarID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccess", reflect.TypeOf((*MockSARService)(nil).ListAccess), officer, sarID)
+}
+
+// ListSARs mocks base method.
+func (m *MockSARService) ListSARs(officer, status string) ([]repository.SAR, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSARs", officer, status)
+	ret0, _ := ret[0].([]repository.SAR)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSARs indicates an expected call of ListSARs.
+func (mr *MockSARServiceMockRecorder) ListSARs(officer, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSARs", reflect.TypeOf((*MockSARService)(nil).ListSARs), officer, status)
+}
+
+// UpdateDraft mocks base method.
+func (m *MockSARService) UpdateDraft(officer string, sarID int64, update service.SARUpdate) (repository.SAR, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraft", officer, sarID, update)
+	ret0, _ := ret[0].(repository.SAR)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockSARServiceMockRecorder) UpdateDraft(officer, sarID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockSARService)(nil).UpdateDraft), officer, sarID, update)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockSARService) UpdateStatus(officer string, sarID int64, status, reference, note string) (repository.SAR, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", officer, sarID, status, reference, note)
+	ret0, _ := ret[0].(repository.SAR)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockSARServiceMockRecorder) UpdateStatus(officer, sarID, status, reference, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockSARService)(nil).UpdateStatus), officer, sarID, status, reference, note)
+}
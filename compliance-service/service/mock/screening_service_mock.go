@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: screening_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/compliant-service/repository"
+	service "flarrocca/compliant-service/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockScreeningService is a mock of ScreeningService interface.
+type MockScreeningService struct {
+	ctrl     *gomock.Controller
+	recorder *MockScreeningServiceMockRecorder
+}
+
+// MockScreeningServiceMockRecorder is the mock recorder for MockScreeningService.
+type MockScreeningServiceMockRecorder struct {
+	mock *MockScreeningService
+}
+
+// NewMockScreeningService creates a new mock instance.
+func NewMockScreeningService(ctrl *gomock.Controller) *MockScreeningService {
+	mock := &MockScreeningService{ctrl: ctrl}
+	mock.recorder = &MockScreeningServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScreeningService) EXPECT() *MockScreeningServiceMockRecorder {
+	return m.recorder
+}
+
+// ListHits mocks base method.
+func (m *MockScreeningService) ListHits(status string) ([]repository.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHits", status)
+	ret0, _ := ret[0].([]repository.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHits indicates an expected call of ListHits.
+func (mr *MockScreeningServiceMockRecorder) ListHits(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHits", reflect.TypeOf((*MockScreeningService)(nil).ListHits), status)
+}
+
+// RefreshSanctions mocks base method.
+func (m *MockScreeningService) RefreshSanctions() (service.SanctionsRefresh, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSanctions")
+	ret0, _ := ret[0].(service.SanctionsRefresh)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshSanctions indicates an expected call of RefreshSanctions.
+func (mr *MockScreeningServiceMockRecorder) RefreshSanctions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSanctions", reflect.TypeOf((*MockScreeningService)(nil).RefreshSanctions))
+}
+
+// ReviewHit mocks base method.
+func (m *MockScreeningService) ReviewHit(hitID int64, status, reviewer, note string) (repository.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewHit", hitID, status, reviewer, note)
+	ret0, _ := ret[0].(repository.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewHit indicates an expected call of ReviewHit.
+func (mr *MockScreeningServiceMockRecorder) ReviewHit(hitID, status, reviewer, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewHit", reflect.TypeOf((*MockScreeningService)(nil).ReviewHit), hitID, status, reviewer, note)
+}
+
+// ScreenUser mocks base method.
+func (m *MockScreeningService) ScreenUser(user repository.User) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScreenUser", user)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScreenUser indicates an expected call of ScreenUser.
+func (mr *MockScreeningServiceMockRecorder) ScreenUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScreenUser", reflect.TypeOf((*MockScreeningService)(nil).ScreenUser), user)
+}
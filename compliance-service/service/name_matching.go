@@ -0,0 +1,176 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// transliterations maps the accented Latin and the Cyrillic letters to ASCII, so "José" matches "Jose" and
+// "Иван" matches "Ivan". Letters not listed are kept as they are.
+var transliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ș': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'ț': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z", 'æ': "ae", 'œ': "oe",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i", 'й': "y",
+	'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+}
+
+// nameTokens transliterates the name to lowercase ASCII and splits it into sorted tokens, so the order of the names
+// does not matter: "DOE, John", "john_doe" and "John Doe" all give [doe john].
+func nameTokens(name string) []string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if ascii, found := transliterations[r]; found {
+			builder.WriteString(ascii)
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		} else if r != '\'' {
+			builder.WriteRune(' ')
+		}
+	}
+
+	tokens := strings.Fields(builder.String())
+	slices.Sort(tokens)
+	return tokens
+}
+
+// matchNames scores how similar two tokenized names are, from 0 to 1. The score is the best of the Jaro-Winkler
+// similarity of the whole names and the average similarity of their closest tokens, in both directions, so an extra
+// middle name lowers the score without hiding the match. phonetic tells whether every token sounds like a token of
+// the other name.
+func matchNames(a []string, b []string) (score float64, phonetic bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, false
+	}
+
+	score = jaroWinkler(strings.Join(a, " "), strings.Join(b, " "))
+	tokenScore := (closestTokensScore(a, b) + closestTokensScore(b, a)) / 2
+	if tokenScore > score {
+		score = tokenScore
+	}
+
+	return score, soundsAlike(a, b) && soundsAlike(b, a)
+}
+
+func closestTokensScore(from []string, to []string) float64 {
+	total := 0.0
+	for _, token := range from {
+		best := 0.0
+		for _, other := range to {
+			if similarity := jaroWinkler(token, other); similarity > best {
+				best = similarity
+			}
+		}
+		total += best
+	}
+	return total / float64(len(from))
+}
+
+func soundsAlike(from []string, to []string) bool {
+	for _, token := range from {
+		code := soundex(token)
+		if !slices.ContainsFunc(to, func(other string) bool { return soundex(other) == code }) {
+			return false
+		}
+	}
+	return true
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings, 1 meaning identical.
+func jaroWinkler(a string, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1, matched2 := make([]bool, len(s1)), make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// soundex returns the American Soundex code of a lowercase ASCII token, e.g. both "mohammed" and "muhamad" give M530.
+func soundex(token string) string {
+	codes := map[byte]byte{
+		'b': '1', 'f': '1', 'p': '1', 'v': '1',
+		'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+		'd': '3', 't': '3', 'l': '4', 'm': '5', 'n': '5', 'r': '6',
+	}
+
+	if token == "" {
+		return ""
+	}
+
+	code := []byte{byte(unicode.ToUpper(rune(token[0])))}
+	last := codes[token[0]]
+	for i := 1; i < len(token) && len(code) < 4; i++ {
+		c := token[i]
+		digit, found := codes[c]
+		switch {
+		case found && digit != last:
+			code = append(code, digit)
+			last = digit
+		case c == 'h' || c == 'w':
+			// h and w do not separate letters with the same code
+		default:
+			last = digit
+		}
+	}
+
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameTokens(t *testing.T) {
+	assert.Equal(t, []string{"doe", "john"}, nameTokens("DOE, John"))
+	assert.Equal(t, []string{"doe", "john"}, nameTokens("john_doe"))
+	assert.Equal(t, []string{"garcia", "jose"}, nameTokens("José García"))
+	assert.Equal(t, []string{"petrov", "vladimir"}, nameTokens("Владимир Петров"))
+	assert.Equal(t, []string{"obrien"}, nameTokens("O'Brien"))
+	assert.Empty(t, nameTokens(" - "))
+}
+
+func TestMatchNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		a            string
+		b            string
+		minScore     float64
+		maxScore     float64
+		wantPhonetic bool
+	}{
+		{name: "Reordered names", a: "john_doe", b: "DOE, John", minScore: 1, maxScore: 1, wantPhonetic: true},
+		{name: "Cyrillic alias", a: "Vladimir Petrov", b: "Владимир Петров", minScore: 1, maxScore: 1, wantPhonetic: true},
+		{name: "Spelling variant", a: "Mohammed Ali", b: "Muhamad ALI", minScore: 0.9, maxScore: 0.95, wantPhonetic: true},
+		{name: "Transliteration variant", a: "Osama bin Laden", b: "Usama BIN LADIN", minScore: 0.92, maxScore: 1},
+		{name: "Different first name", a: "Peter Parker", b: "Pedro Parque", minScore: 0, maxScore: 0.85},
+		{name: "Partial name", a: "Ali", b: "Ali Hassan", minScore: 0.8, maxScore: 0.9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, phonetic := matchNames(nameTokens(tt.a), nameTokens(tt.b))
+			assert.GreaterOrEqual(t, score, tt.minScore)
+			assert.LessOrEqual(t, score, tt.maxScore)
+			assert.Equal(t, tt.wantPhonetic, phonetic)
+		})
+	}
+
+	score, phonetic := matchNames(nil, nameTokens("John Doe"))
+	assert.Zero(t, score)
+	assert.False(t, phonetic)
+}
+
+func TestJaroWinkler(t *testing.T) {
+	assert.Equal(t, 1.0, jaroWinkler("martha", "martha"))
+	assert.InDelta(t, 0.961, jaroWinkler("martha", "marhta"), 0.001)
+	assert.InDelta(t, 0.840, jaroWinkler("dwayne", "duane"), 0.001)
+	assert.InDelta(t, 0.813, jaroWinkler("dixon", "dicksonx"), 0.001)
+	assert.Zero(t, jaroWinkler("abc", "xyz"))
+	assert.Zero(t, jaroWinkler("", "abc"))
+}
+
+func TestSoundex(t *testing.T) {
+	assert.Equal(t, "R163", soundex("robert"))
+	assert.Equal(t, "R163", soundex("rupert"))
+	assert.Equal(t, "A261", soundex("ashcraft"))
+	assert.Equal(t, "T522", soundex("tymczak"))
+	assert.Equal(t, "P236", soundex("pfister"))
+	assert.Equal(t, "M530", soundex("mohammed"))
+	assert.Equal(t, "M530", soundex("muhamad"))
+	assert.Equal(t, "L000", soundex("li"))
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"encoding/csv"
+	"flarrocca/compliant-service/repository"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+var pepColumns = []string{"id", "name", "category", "position", "country", "aliases"}
+
+// loadPEPFile reads the PEP list, a CSV file with the header id,name,category,position,country,aliases. The
+// category is pep, rca or adverse_media; position, country and aliases may be empty and the aliases are separated
+// by semicolons.
+func loadPEPFile(path string) ([]repository.PEPEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parsePEPCSV(file)
+}
+
+func parsePEPCSV(r io.Reader) ([]repository.PEPEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(pepColumns)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid PEP list: %w", err)
+	}
+	for i, column := range header {
+		if strings.ToLower(strings.TrimSpace(column)) != pepColumns[i] {
+			return nil, fmt.Errorf("invalid PEP list: the header must be %s", strings.Join(pepColumns, ","))
+		}
+	}
+
+	var entries []repository.PEPEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid PEP list: %w", err)
+		}
+
+		entry := repository.PEPEntry{
+			ExternalID: strings.TrimSpace(record[0]),
+			Name:       strings.TrimSpace(record[1]),
+			Category:   strings.ToLower(strings.TrimSpace(record[2])),
+			Position:   strings.TrimSpace(record[3]),
+			Country:    strings.ToUpper(strings.TrimSpace(record[4])),
+		}
+		for _, alias := range strings.Split(record[5], ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+
+		if entry.ExternalID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("invalid PEP list: entry without id or name on line %d", len(entries)+2)
+		}
+		if !slices.Contains(pepCategories, entry.Category) {
+			return nil, fmt.Errorf("invalid PEP list: entry %s has category %q, expected one of %s", entry.ExternalID, entry.Category, strings.Join(pepCategories, ", "))
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"flarrocca/compliant-service/repository"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePEPCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		assertFunc func(t *testing.T, entries []repository.PEPEntry, err error)
+	}{
+		{
+			name: "Success - Entries of every category with aliases",
+			input: "id,name,category,position,country,aliases\n" +
+				"PEP-1, Elena Marchetti ,PEP,Minister of Finance,it,Elena Marchetti-Rossi; E. Marchetti\n" +
+				"PEP-2,Paolo Marchetti,rca,,IT,\n" +
+				"PEP-3,Gregory Hale,adverse_media,\"Fraud investigation, 2024\",GB,\n",
+			assertFunc: func(t *testing.T, entries []repository.PEPEntry, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []repository.PEPEntry{
+					{ExternalID: "PEP-1", Name: "Elena Marchetti", Category: PEPCategoryPEP, Position: "Minister of Finance", Country: "IT",
+						Aliases: []string{"Elena Marchetti-Rossi", "E. Marchetti"}},
+					{ExternalID: "PEP-2", Name: "Paolo Marchetti", Category: PEPCategoryRCA, Country: "IT"},
+					{ExternalID: "PEP-3", Name: "Gregory Hale", Category: PEPCategoryAdverseMedia, Position: "Fraud investigation, 2024", Country: "GB"},
+				}, entries)
+			},
+		},
+		{
+			name:  "Failure - Unexpected header",
+			input: "uid,full_name,category,position,country,aliases\n",
+			assertFunc: func(t *testing.T, entries []repository.PEPEntry, err error) {
+				assert.EqualError(t, err, "invalid PEP list: the header must be id,name,category,position,country,aliases")
+			},
+		},
+		{
+			name:  "Failure - Unknown category",
+			input: "id,name,category,position,country,aliases\nPEP-1,Elena Marchetti,celebrity,,,\n",
+			assertFunc: func(t *testing.T, entries []repository.PEPEntry, err error) {
+				assert.EqualError(t, err, `invalid PEP list: entry PEP-1 has category "celebrity", expected one of pep, rca, adverse_media`)
+			},
+		},
+		{
+			name:  "Failure - Entry without a name",
+			input: "id,name,category,position,country,aliases\nPEP-1,,pep,,,\n",
+			assertFunc: func(t *testing.T, entries []repository.PEPEntry, err error) {
+				assert.EqualError(t, err, "invalid PEP list: entry without id or name on line 2")
+			},
+		},
+		{
+			name:  "Failure - Missing columns",
+			input: "id,name,category,position,country,aliases\nPEP-1,Elena Marchetti,pep\n",
+			assertFunc: func(t *testing.T, entries []repository.PEPEntry, err error) {
+				assert.ErrorContains(t, err, "invalid PEP list")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parsePEPCSV(strings.NewReader(tt.input))
+			tt.assertFunc(t, entries, err)
+		})
+	}
+}
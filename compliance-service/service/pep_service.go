@@ -0,0 +1,303 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PEPCategoryPEP          = "pep"
+	PEPCategoryRCA          = "rca"
+	PEPCategoryAdverseMedia = "adverse_media"
+
+	RiskRatingLow    = "low"
+	RiskRatingMedium = "medium"
+	RiskRatingHigh   = "high"
+
+	RiskRatingSourceScreening = "screening"
+	RiskRatingSourceManual    = "manual"
+
+	defaultPEPListPath       = "./database/pep.csv"
+	defaultHighRiskLimit     = 1000
+	defaultHighRiskReviewMin = 500
+)
+
+var (
+	ErrRiskRatingNotFound = errors.New("risk rating not found")
+	ErrInvalidRiskRating  = errors.New("invalid risk rating")
+
+	pepCategories = []string{PEPCategoryPEP, PEPCategoryRCA, PEPCategoryAdverseMedia}
+	riskRatings   = []string{RiskRatingLow, RiskRatingMedium, RiskRatingHigh}
+
+	// pepCategoryRatings is the rating of a user matching an entry of each category.
+	pepCategoryRatings = map[string]string{
+		PEPCategoryPEP:          RiskRatingHigh,
+		PEPCategoryRCA:          RiskRatingMedium,
+		PEPCategoryAdverseMedia: RiskRatingMedium,
+	}
+	pepCategoryNames = map[string]string{
+		PEPCategoryPEP:          "politically exposed person",
+		PEPCategoryRCA:          "relative or close associate of a politically exposed person",
+		PEPCategoryAdverseMedia: "adverse media subject",
+	}
+)
+
+// highRiskLimits apply to the users rated high risk on top of the KYC tier limits: payments above paymentLimit are
+// denied and payments of at least reviewAmount need a manual review. 0 disables either.
+type highRiskLimits struct {
+	paymentLimit float64
+	reviewAmount float64
+}
+
+// loadHighRiskLimits reads the limits from HIGH_RISK_PAYMENT_LIMIT and HIGH_RISK_REVIEW_AMOUNT.
+func loadHighRiskLimits() highRiskLimits {
+	limits := highRiskLimits{paymentLimit: defaultHighRiskLimit, reviewAmount: defaultHighRiskReviewMin}
+	if limit, err := strconv.ParseFloat(os.Getenv("HIGH_RISK_PAYMENT_LIMIT"), 64); err == nil && limit >= 0 {
+		limits.paymentLimit = limit
+	}
+	if amount, err := strconv.ParseFloat(os.Getenv("HIGH_RISK_REVIEW_AMOUNT"), 64); err == nil && amount >= 0 {
+		limits.reviewAmount = amount
+	}
+	return limits
+}
+
+type PEPRefresh struct {
+	Entries       int `json:"entries"`
+	UsersScreened int `json:"users_screened"`
+	MediumRisk    int `json:"medium_risk"`
+	HighRisk      int `json:"high_risk"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source pep_service.go -destination mock/pep_service_mock.go -package mock
+type PEPService interface {
+	RefreshPEPList() (PEPRefresh, error)
+	ScreenUser(user repository.User) (string, error)
+	GetRiskRating(userID int64) (repository.RiskRating, error)
+	ListRiskRatings(rating string) ([]repository.RiskRating, error)
+	SetRiskRating(userID int64, rating string, reviewer string, reason string) (repository.RiskRating, error)
+}
+
+type pepService struct {
+	pepRepository     repository.PEPRepository
+	userRepository    repository.UserRepository
+	paymentRepository repository.PaymentRepository
+	listPath          string
+	matchScore        float64
+	now               func() time.Time
+}
+
+// NewPEPService reads the PEP list from PEP_LIST_PATH. Names are matched as for the sanctions lists, with the minimum
+// score in PEP_MATCH_SCORE.
+func NewPEPService(pepRepository repository.PEPRepository, userRepository repository.UserRepository, paymentRepository repository.PaymentRepository) PEPService {
+	listPath := os.Getenv("PEP_LIST_PATH")
+	if listPath == "" {
+		listPath = defaultPEPListPath
+	}
+
+	matchScore, err := strconv.ParseFloat(os.Getenv("PEP_MATCH_SCORE"), 64)
+	if err != nil || matchScore <= 0 || matchScore > 1 {
+		matchScore = defaultSanctionsMatchScore
+	}
+
+	return &pepService{
+		pepRepository:     pepRepository,
+		userRepository:    userRepository,
+		paymentRepository: paymentRepository,
+		listPath:          listPath,
+		matchScore:        matchScore,
+		now:               func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// RefreshPEPList reloads the PEP list and rates every user again.
+func (s *pepService) RefreshPEPList() (PEPRefresh, error) {
+	entries, err := loadPEPFile(s.listPath)
+	if err != nil {
+		return PEPRefresh{}, err
+	}
+
+	if err := s.pepRepository.ReplaceEntries(entries, s.now()); err != nil {
+		return PEPRefresh{}, err
+	}
+
+	users, err := s.userRepository.ListUsers()
+	if err != nil {
+		return PEPRefresh{}, err
+	}
+
+	ratings, err := s.screen(users)
+	if err != nil {
+		return PEPRefresh{}, err
+	}
+	// any user may have been rated again, up or down
+	invalidateAllVerdicts(s.paymentRepository)
+
+	refresh := PEPRefresh{Entries: len(entries), UsersScreened: len(users)}
+	for _, rating := range ratings {
+		switch rating.Rating {
+		case RiskRatingMedium:
+			refresh.MediumRisk++
+		case RiskRatingHigh:
+			refresh.HighRisk++
+		}
+	}
+
+	return refresh, nil
+}
+
+// ScreenUser matches the user name and full name of the user against the PEP list and returns the rating computed
+// from the matches. The rating stored may differ when a reviewer set it.
+func (s *pepService) ScreenUser(user repository.User) (string, error) {
+	ratings, err := s.screen([]repository.User{user})
+	if err != nil {
+		return "", err
+	}
+	return ratings[0].Rating, nil
+}
+
+func (s *pepService) GetRiskRating(userID int64) (repository.RiskRating, error) {
+	rating, err := s.pepRepository.GetRiskRating(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.RiskRating{}, ErrRiskRatingNotFound
+		}
+		return repository.RiskRating{}, err
+	}
+	return rating, nil
+}
+
+func (s *pepService) ListRiskRatings(rating string) ([]repository.RiskRating, error) {
+	if rating != "" && !slices.Contains(riskRatings, rating) {
+		return nil, fmt.Errorf("%w: rating must be one of %s", ErrInvalidRiskRating, strings.Join(riskRatings, ", "))
+	}
+	return s.pepRepository.ListRiskRatings(rating)
+}
+
+// SetRiskRating records the outcome of the enhanced due diligence of a user, e.g. lowering the rating of a false
+// positive. It is kept until the user matches a new entry of the list.
+func (s *pepService) SetRiskRating(userID int64, rating string, reviewer string, reason string) (repository.RiskRating, error) {
+	reviewer, reason = strings.TrimSpace(reviewer), strings.TrimSpace(reason)
+	if !slices.Contains(riskRatings, rating) {
+		return repository.RiskRating{}, fmt.Errorf("%w: rating must be one of %s", ErrInvalidRiskRating, strings.Join(riskRatings, ", "))
+	}
+	if reviewer == "" || len(reviewer) > maxScreeningReviewerNameLength {
+		return repository.RiskRating{}, fmt.Errorf("%w: reviewer must be between 1 and %d characters", ErrInvalidRiskRating, maxScreeningReviewerNameLength)
+	}
+	if reason == "" || len(reason) > maxScreeningReviewNoteLength {
+		return repository.RiskRating{}, fmt.Errorf("%w: reason must be between 1 and %d characters", ErrInvalidRiskRating, maxScreeningReviewNoteLength)
+	}
+
+	if _, err := s.userRepository.GetUserName(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.RiskRating{}, ErrUserNotFound
+		}
+		return repository.RiskRating{}, err
+	}
+
+	if err := s.pepRepository.SetRating(repository.RiskRating{
+		UserID:   userID,
+		Rating:   rating,
+		Source:   RiskRatingSourceManual,
+		Reason:   reason,
+		Reviewer: reviewer,
+		RatedAt:  s.now(),
+	}); err != nil {
+		return repository.RiskRating{}, err
+	}
+	invalidateUserVerdicts(s.paymentRepository, userID)
+
+	return s.GetRiskRating(userID)
+}
+
+type tokenizedPEPName struct {
+	repository.PEPName
+	tokens []string
+}
+
+func (s *pepService) screen(users []repository.User) ([]repository.RiskRating, error) {
+	pepNames, err := s.pepRepository.GetActiveNames()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]tokenizedPEPName, 0, len(pepNames))
+	for _, name := range pepNames {
+		names = append(names, tokenizedPEPName{PEPName: name, tokens: nameTokens(name.Name)})
+	}
+
+	now := s.now()
+	ratings := make([]repository.RiskRating, 0, len(users))
+	for _, user := range users {
+		ratings = append(ratings, rateMatches(user.ID, s.matchUser(user, names, now), now))
+	}
+
+	if err := s.pepRepository.SaveScreenedRatings(ratings); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// matchUser returns at most one match per entry, for the best scoring pair of user and entry names.
+func (s *pepService) matchUser(user repository.User, names []tokenizedPEPName, now time.Time) []repository.PEPMatch {
+	var matches []repository.PEPMatch
+	matchIndexes := map[int64]int{}
+
+	for _, screenedName := range []string{user.FullName, user.UserName} {
+		tokens := nameTokens(screenedName)
+		for _, name := range names {
+			score, phonetic := matchNames(tokens, name.tokens)
+			if score < s.matchScore && !(phonetic && score >= s.matchScore-phoneticMatchScoreAllowance) {
+				continue
+			}
+
+			match := repository.PEPMatch{
+				EntryID:      name.EntryID,
+				EntryName:    name.EntryName,
+				Category:     name.Category,
+				ScreenedName: screenedName,
+				MatchedName:  name.Name,
+				Score:        score,
+				CreatedAt:    now,
+			}
+			if i, found := matchIndexes[name.EntryID]; !found {
+				matchIndexes[name.EntryID] = len(matches)
+				matches = append(matches, match)
+			} else if score > matches[i].Score {
+				matches[i] = match
+			}
+		}
+	}
+
+	return matches
+}
+
+// rateMatches rates the user by the riskiest category matched, low without any match.
+func rateMatches(userID int64, matches []repository.PEPMatch, now time.Time) repository.RiskRating {
+	rating := repository.RiskRating{
+		UserID:  userID,
+		Rating:  RiskRatingLow,
+		Source:  RiskRatingSourceScreening,
+		Reason:  "no match on the PEP list",
+		RatedAt: now,
+		Matches: matches,
+	}
+
+	for _, match := range matches {
+		matchRating := pepCategoryRatings[match.Category]
+		if slices.Index(riskRatings, matchRating) > slices.Index(riskRatings, rating.Rating) {
+			rating.Rating = matchRating
+			rating.Reason = fmt.Sprintf("matches %s %s", pepCategoryNames[match.Category], match.EntryName)
+		}
+	}
+
+	return rating
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPEPService(ctrl *gomock.Controller, now time.Time) (*pepService, *mock.MockPEPRepository, *mock.MockUserRepository, *mock.MockPaymentRepository) {
+	pepRepositoryMock := mock.NewMockPEPRepository(ctrl)
+	userRepositoryMock := mock.NewMockUserRepository(ctrl)
+	paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
+	return &pepService{
+		pepRepository:     pepRepositoryMock,
+		userRepository:    userRepositoryMock,
+		paymentRepository: paymentRepositoryMock,
+		listPath:          "../database/pep.csv",
+		matchScore:        defaultSanctionsMatchScore,
+		now:               func() time.Time { return now },
+	}, pepRepositoryMock, userRepositoryMock, paymentRepositoryMock
+}
+
+func TestRefreshPEPList(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	users := []repository.User{
+		{ID: 1, UserName: "john_doe", FullName: "John Doe"},
+		{ID: 2, UserName: "emarchetti", FullName: "MARCHETTI, Elena"},
+		{ID: 3, UserName: "paolo_m", FullName: "Paolo Marchetti"},
+	}
+	names := []repository.PEPName{
+		{EntryID: 1, EntryName: "Elena Marchetti", Category: PEPCategoryPEP, Name: "Elena Marchetti"},
+		{EntryID: 2, EntryName: "Paolo Marchetti", Category: PEPCategoryRCA, Name: "Paolo Marchetti"},
+	}
+
+	tests := []struct {
+		name       string
+		listPath   string
+		on         func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, refresh PEPRefresh, err error)
+	}{
+		{
+			name: "Success - Every user rated against the new list",
+			on: func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				pepRepositoryMock.EXPECT().ReplaceEntries(gomock.Len(4), now).Return(nil)
+				userRepositoryMock.EXPECT().ListUsers().Return(users, nil)
+				pepRepositoryMock.EXPECT().GetActiveNames().Return(names, nil)
+				pepRepositoryMock.EXPECT().SaveScreenedRatings(gomock.Any()).DoAndReturn(func(ratings []repository.RiskRating) error {
+					assert.Equal(t, []repository.RiskRating{
+						{UserID: 1, Rating: RiskRatingLow, Source: RiskRatingSourceScreening, Reason: "no match on the PEP list", RatedAt: now},
+						{UserID: 2, Rating: RiskRatingHigh, Source: RiskRatingSourceScreening, Reason: "matches politically exposed person Elena Marchetti", RatedAt: now,
+							Matches: []repository.PEPMatch{{EntryID: 1, EntryName: "Elena Marchetti", Category: PEPCategoryPEP, ScreenedName: "MARCHETTI, Elena",
+								MatchedName: "Elena Marchetti", Score: 1, CreatedAt: now}}},
+						{UserID: 3, Rating: RiskRatingMedium, Source: RiskRatingSourceScreening,
+							Reason: "matches relative or close associate of a politically exposed person Paolo Marchetti", RatedAt: now,
+							Matches: []repository.PEPMatch{{EntryID: 2, EntryName: "Paolo Marchetti", Category: PEPCategoryRCA, ScreenedName: "Paolo Marchetti",
+								MatchedName: "Paolo Marchetti", Score: 1, CreatedAt: now}}},
+					}, ratings)
+					return nil
+				})
+				paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+			},
+			assertFunc: func(t *testing.T, refresh PEPRefresh, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, PEPRefresh{Entries: 4, UsersScreened: 3, MediumRisk: 1, HighRisk: 1}, refresh)
+			},
+		},
+		{
+			name:     "Failure - List file missing",
+			listPath: "../database/missing.csv",
+			on: func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, refresh PEPRefresh, err error) {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			},
+		},
+		{
+			name: "Failure - Error saving the ratings",
+			on: func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				pepRepositoryMock.EXPECT().ReplaceEntries(gomock.Any(), now).Return(nil)
+				userRepositoryMock.EXPECT().ListUsers().Return(users, nil)
+				pepRepositoryMock.EXPECT().GetActiveNames().Return(names, nil)
+				pepRepositoryMock.EXPECT().SaveScreenedRatings(gomock.Any()).Return(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, refresh PEPRefresh, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, pepRepositoryMock, userRepositoryMock, paymentRepositoryMock := newTestPEPService(ctrl, now)
+			if tt.listPath != "" {
+				service.listPath = tt.listPath
+			}
+			tt.on(pepRepositoryMock, userRepositoryMock, paymentRepositoryMock)
+
+			refresh, err := service.RefreshPEPList()
+			tt.assertFunc(t, refresh, err)
+		})
+	}
+}
+
+func TestScreenUserAgainstPEPList(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, pepRepositoryMock, _, _ := newTestPEPService(ctrl, now)
+	pepRepositoryMock.EXPECT().GetActiveNames().Return([]repository.PEPName{
+		{EntryID: 2, EntryName: "Gregory Hale", Category: PEPCategoryAdverseMedia, Name: "Gregory Hale"},
+		{EntryID: 3, EntryName: "Dmitri Volkov", Category: PEPCategoryPEP, Name: "Dmitri Volkov"},
+		{EntryID: 3, EntryName: "Dmitri Volkov", Category: PEPCategoryPEP, Name: "Дмитрий Волков"},
+	}, nil)
+	pepRepositoryMock.EXPECT().SaveScreenedRatings(gomock.Any()).DoAndReturn(func(ratings []repository.RiskRating) error {
+		// the transliterated full name and the user name both match the PEP, only the best is kept
+		assert.Len(t, ratings[0].Matches, 1)
+		assert.Equal(t, "Дмитрий Волков", ratings[0].Matches[0].ScreenedName)
+		return nil
+	})
+
+	rating, err := service.ScreenUser(repository.User{ID: 4, UserName: "dmitri_volkov", FullName: "Дмитрий Волков"})
+	assert.NoError(t, err)
+	assert.Equal(t, RiskRatingHigh, rating)
+}
+
+func TestSetRiskRating(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type input struct {
+		userID   int64
+		rating   string
+		reviewer string
+		reason   string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, rating repository.RiskRating, err error)
+	}{
+		{
+			name:  "Success - False positive lowered by a reviewer",
+			input: input{userID: 2, rating: RiskRatingLow, reviewer: " alice ", reason: "different date of birth than the PEP"},
+			on: func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(2)).Return("emarchetti", nil)
+				pepRepositoryMock.EXPECT().SetRating(repository.RiskRating{UserID: 2, Rating: RiskRatingLow, Source: RiskRatingSourceManual,
+					Reason: "different date of birth than the PEP", Reviewer: "alice", RatedAt: now}).Return(nil)
+				paymentRepositoryMock.EXPECT().InvalidateUserVerdicts([]int64{2}).Return(nil)
+				pepRepositoryMock.EXPECT().GetRiskRating(int64(2)).Return(repository.RiskRating{UserID: 2, Rating: RiskRatingLow, Source: RiskRatingSourceManual}, nil)
+			},
+			assertFunc: func(t *testing.T, rating repository.RiskRating, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, RiskRatingLow, rating.Rating)
+				assert.Equal(t, RiskRatingSourceManual, rating.Source)
+			},
+		},
+		{
+			name:  "Failure - Unknown rating",
+			input: input{userID: 2, rating: "critical", reviewer: "alice", reason: "reason"},
+			on: func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, rating repository.RiskRating, err error) {
+				assert.EqualError(t, err, "invalid risk rating: rating must be one of low, medium, high")
+			},
+		},
+		{
+			name:  "Failure - Reason required",
+			input: input{userID: 2, rating: RiskRatingHigh, reviewer: "alice", reason: " "},
+			on: func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, rating repository.RiskRating, err error) {
+				assert.ErrorIs(t, err, ErrInvalidRiskRating)
+			},
+		},
+		{
+			name:  "Failure - User not found",
+			input: input{userID: 9, rating: RiskRatingHigh, reviewer: "alice", reason: "reason"},
+			on: func(pepRepositoryMock *mock.MockPEPRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				userRepositoryMock.EXPECT().GetUserName(int64(9)).Return("", sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, rating repository.RiskRating, err error) {
+				assert.ErrorIs(t, err, ErrUserNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, pepRepositoryMock, userRepositoryMock, paymentRepositoryMock := newTestPEPService(ctrl, now)
+			tt.on(pepRepositoryMock, userRepositoryMock, paymentRepositoryMock)
+
+			rating, err := service.SetRiskRating(tt.input.userID, tt.input.rating, tt.input.reviewer, tt.input.reason)
+			tt.assertFunc(t, rating, err)
+		})
+	}
+}
+
+func TestGetRiskRatingNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, pepRepositoryMock, _, _ := newTestPEPService(ctrl, time.Now())
+	pepRepositoryMock.EXPECT().GetRiskRating(int64(9)).Return(repository.RiskRating{}, sql.ErrNoRows)
+
+	_, err := service.GetRiskRating(9)
+	assert.ErrorIs(t, err, ErrRiskRatingNotFound)
+
+	_, err = service.ListRiskRatings("critical")
+	assert.ErrorIs(t, err, ErrInvalidRiskRating)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sdnNull is how the OFAC CSV files mark an empty field.
+const sdnNull = "-0-"
+
+// loadSanctionsFile reads an OFAC SDN list, either sdn.xml or sdn.csv. The CSV file has no aliases, they are read
+// from the optional alt.csv at aliasesPath. Vessels and aircraft are skipped, customers can only match people and entities.
+func loadSanctionsFile(path string, aliasesPath string) ([]repository.SanctionsEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return parseSDNXML(file)
+	case ".csv":
+		aliases := map[string][]string{}
+		if aliasesPath != "" {
+			aliasesFile, err := os.Open(aliasesPath)
+			if err != nil {
+				return nil, err
+			}
+			defer aliasesFile.Close()
+
+			if aliases, err = parseSDNAliasesCSV(aliasesFile); err != nil {
+				return nil, err
+			}
+		}
+		return parseSDNCSV(file, aliases)
+	}
+
+	return nil, fmt.Errorf("unsupported sanctions file %s, expected an .xml or .csv SDN file", path)
+}
+
+type sdnXMLEntry struct {
+	UID       string   `xml:"uid"`
+	FirstName string   `xml:"firstName"`
+	LastName  string   `xml:"lastName"`
+	SDNType   string   `xml:"sdnType"`
+	Programs  []string `xml:"programList>program"`
+	Akas      []struct {
+		FirstName string `xml:"firstName"`
+		LastName  string `xml:"lastName"`
+	} `xml:"akaList>aka"`
+}
+
+// parseSDNXML decodes the entries one at a time, so the whole document is never held in memory.
+func parseSDNXML(r io.Reader) ([]repository.SanctionsEntry, error) {
+	decoder := xml.NewDecoder(r)
+
+	var entries []repository.SanctionsEntry
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SDN XML: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "sdnEntry" {
+			continue
+		}
+
+		var sdnEntry sdnXMLEntry
+		if err := decoder.DecodeElement(&sdnEntry, &start); err != nil {
+			return nil, fmt.Errorf("invalid SDN XML: %w", err)
+		}
+
+		entry := repository.SanctionsEntry{
+			ExternalID: strings.TrimSpace(sdnEntry.UID),
+			Name:       strings.TrimSpace(sdnEntry.FirstName + " " + sdnEntry.LastName),
+			EntryType:  strings.ToLower(strings.TrimSpace(sdnEntry.SDNType)),
+			Programs:   strings.Join(sdnEntry.Programs, ", "),
+		}
+		for _, aka := range sdnEntry.Akas {
+			if alias := strings.TrimSpace(aka.FirstName + " " + aka.LastName); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+		if entry.ExternalID == "" || entry.Name == "" {
+			return nil, errors.New("invalid SDN XML: entry without uid or name")
+		}
+		if screenableEntry(entry.EntryType) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// parseSDNCSV reads sdn.csv, whose columns are ent_num, SDN_Name, SDN_Type, Program, followed by fields not used here.
+// Entities have no SDN_Type.
+func parseSDNCSV(r io.Reader, aliases map[string][]string) ([]repository.SanctionsEntry, error) {
+	records, err := sdnRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []repository.SanctionsEntry
+	for _, record := range records {
+		if len(record) < 4 {
+			return nil, fmt.Errorf("invalid SDN CSV: entry %s has %d fields, expected at least 4", record[0], len(record))
+		}
+
+		entry := repository.SanctionsEntry{
+			ExternalID: record[0],
+			Name:       sdnField(record[1]),
+			EntryType:  strings.ToLower(sdnField(record[2])),
+			Programs:   sdnField(record[3]),
+			Aliases:    aliases[record[0]],
+		}
+		if entry.EntryType == "" {
+			entry.EntryType = "entity"
+		}
+		if entry.Name == "" {
+			return nil, fmt.Errorf("invalid SDN CSV: entry %s has no name", entry.ExternalID)
+		}
+		if screenableEntry(entry.EntryType) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// parseSDNAliasesCSV reads alt.csv, whose columns are ent_num, alt_num, alt_type, alt_name and alt_remarks.
+func parseSDNAliasesCSV(r io.Reader) (map[string][]string, error) {
+	records, err := sdnRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := map[string][]string{}
+	for _, record := range records {
+		if len(record) < 4 {
+			return nil, fmt.Errorf("invalid SDN aliases CSV: alias of entry %s has %d fields, expected at least 4", record[0], len(record))
+		}
+		if alias := sdnField(record[3]); alias != "" {
+			aliases[record[0]] = append(aliases[record[0]], alias)
+		}
+	}
+
+	return aliases, nil
+}
+
+// sdnRecords reads an OFAC CSV file, which has no header and may end with a SUB (0x1A) character.
+func sdnRecords(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SDN CSV: %w", err)
+		}
+
+		record[0] = strings.TrimSpace(strings.Trim(record[0], "\x1a"))
+		if record[0] == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func sdnField(value string) string {
+	value = strings.TrimSpace(value)
+	if value == sdnNull {
+		return ""
+	}
+	return value
+}
+
+func screenableEntry(entryType string) bool {
+	return entryType != "vessel" && entryType != "aircraft"
+}
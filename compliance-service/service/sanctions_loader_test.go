@@ -0,0 +1,87 @@
+package service
+
+import (
+	"flarrocca/compliant-service/repository"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSDNXML(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		assertFunc func(t *testing.T, entries []repository.SanctionsEntry, err error)
+	}{
+		{
+			name: "Success - Individuals and entities with aliases, vessels skipped",
+			input: `<sdnList xmlns="http://tempuri.org/sdnList.xsd">
+				<sdnEntry><uid>1</uid><firstName>Viktor</firstName><lastName>KRAVCHENKO</lastName><sdnType>Individual</sdnType>
+					<programList><program>SAMPLE-1</program><program>SAMPLE-2</program></programList>
+					<akaList><aka><firstName>Виктор</firstName><lastName>КРАВЧЕНКО</lastName></aka></akaList></sdnEntry>
+				<sdnEntry><uid>2</uid><lastName>NORTHWIND TRADING FZE</lastName><sdnType>Entity</sdnType></sdnEntry>
+				<sdnEntry><uid>3</uid><lastName>SEA FALCON</lastName><sdnType>Vessel</sdnType></sdnEntry>
+			</sdnList>`,
+			assertFunc: func(t *testing.T, entries []repository.SanctionsEntry, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []repository.SanctionsEntry{
+					{ExternalID: "1", Name: "Viktor KRAVCHENKO", EntryType: "individual", Programs: "SAMPLE-1, SAMPLE-2", Aliases: []string{"Виктор КРАВЧЕНКО"}},
+					{ExternalID: "2", Name: "NORTHWIND TRADING FZE", EntryType: "entity"},
+				}, entries)
+			},
+		},
+		{
+			name:  "Failure - Entry without a name",
+			input: `<sdnList><sdnEntry><uid>1</uid><sdnType>Individual</sdnType></sdnEntry></sdnList>`,
+			assertFunc: func(t *testing.T, entries []repository.SanctionsEntry, err error) {
+				assert.EqualError(t, err, "invalid SDN XML: entry without uid or name")
+			},
+		},
+		{
+			name:  "Failure - Truncated document",
+			input: `<sdnList><sdnEntry><uid>1</uid>`,
+			assertFunc: func(t *testing.T, entries []repository.SanctionsEntry, err error) {
+				assert.ErrorContains(t, err, "invalid SDN XML")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parseSDNXML(strings.NewReader(tt.input))
+			tt.assertFunc(t, entries, err)
+		})
+	}
+}
+
+func TestParseSDNCSV(t *testing.T) {
+	aliases, err := parseSDNAliasesCSV(strings.NewReader(`1,101,"aka","KRAVCHENKO, Viktor Ivanovich",-0-` + "\n" + `2,102,"fka","NORTHWIND FZE",-0-` + "\n\x1a"))
+	assert.NoError(t, err)
+
+	entries, err := parseSDNCSV(strings.NewReader(`1,"KRAVCHENKO, Viktor","individual","SAMPLE-1",-0-,-0-`+"\n"+
+		`2,"NORTHWIND TRADING FZE",-0-,"SAMPLE-2",-0-,-0-`+"\n"+
+		`3,"SEA FALCON","vessel","SAMPLE-2",-0-,-0-`+"\n\x1a"), aliases)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.SanctionsEntry{
+		{ExternalID: "1", Name: "KRAVCHENKO, Viktor", EntryType: "individual", Programs: "SAMPLE-1", Aliases: []string{"KRAVCHENKO, Viktor Ivanovich"}},
+		{ExternalID: "2", Name: "NORTHWIND TRADING FZE", EntryType: "entity", Programs: "SAMPLE-2", Aliases: []string{"NORTHWIND FZE"}},
+	}, entries)
+
+	_, err = parseSDNCSV(strings.NewReader(`4,-0-,"individual","SAMPLE-1"`), nil)
+	assert.EqualError(t, err, "invalid SDN CSV: entry 4 has no name")
+}
+
+func TestLoadSanctionsFile(t *testing.T) {
+	entries, err := loadSanctionsFile("../database/sdn.xml", "")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	path := filepath.Join(t.TempDir(), "sdn.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("1,name"), 0o600))
+	_, err = loadSanctionsFile(path, "")
+	assert.ErrorContains(t, err, "unsupported sanctions file")
+}
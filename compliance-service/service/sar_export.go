@@ -0,0 +1,265 @@
+package service
+
+import (
+	"encoding/xml"
+	"flarrocca/compliant-service/repository"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// Party type codes of the FinCEN SAR.
+	sarPartyFilingInstitution = "30"
+	sarPartySubject           = "33"
+
+	sarDateLayout = "20060102"
+)
+
+// sarIDTypeCodes maps the KYC document types to the identification type codes of the FinCEN SAR.
+var sarIDTypeCodes = map[string]string{
+	IDDocumentDrivingLicense: "5",
+	IDDocumentPassport:       "6",
+	IDDocumentNationalID:     "999",
+}
+
+var sarActivityTypeNames = map[string]string{
+	SARActivityStructuring:        "Structuring",
+	SARActivityMoneyLaundering:    "Money laundering",
+	SARActivityFraud:              "Fraud",
+	SARActivityIdentityTheft:      "Identity theft",
+	SARActivityTerroristFinancing: "Terrorist financing",
+	SARActivityOther:              "Other suspicious activity",
+}
+
+// The sarXML types follow the element names of the FinCEN SAR XML batch schema, reduced to the parties and
+// activity details this service knows about, plus the list of the suspicious transactions.
+type sarXMLBatch struct {
+	XMLName       xml.Name       `xml:"EFilingBatchXML"`
+	ActivityCount int            `xml:"ActivityCount,attr"`
+	TotalAmount   int64          `xml:"TotalAmount,attr"`
+	FormTypeCode  string         `xml:"FormTypeCode"`
+	Activity      sarXMLActivity `xml:"Activity"`
+}
+
+type sarXMLActivity struct {
+	SeqNum                       int                           `xml:"SeqNum,attr"`
+	EFilingPriorDocumentNumber   string                        `xml:"EFilingPriorDocumentNumber,omitempty"`
+	FilingDateText               string                        `xml:"FilingDateText"`
+	ActivityAssociation          sarXMLActivityAssociation     `xml:"ActivityAssociation"`
+	Party                        []sarXMLParty                 `xml:"Party"`
+	SuspiciousActivity           sarXMLSuspiciousActivity      `xml:"SuspiciousActivity"`
+	ActivityNarrativeInformation sarXMLNarrativeInformation    `xml:"ActivityNarrativeInformation"`
+	SuspiciousTransaction        []sarXMLSuspiciousTransaction `xml:"SuspiciousTransaction"`
+}
+
+type sarXMLActivityAssociation struct {
+	InitialReportIndicator       string `xml:"InitialReportIndicator,omitempty"`
+	CorrectsAmendsPriorIndicator string `xml:"CorrectsAmendsPriorReportIndicator,omitempty"`
+}
+
+type sarXMLParty struct {
+	SeqNum                  int                        `xml:"SeqNum,attr"`
+	ActivityPartyTypeCode   string                     `xml:"ActivityPartyTypeCode"`
+	IndividualBirthDateText string                     `xml:"IndividualBirthDateText,omitempty"`
+	PartyName               sarXMLPartyName            `xml:"PartyName"`
+	Address                 *sarXMLAddress             `xml:"Address,omitempty"`
+	PartyIdentification     *sarXMLPartyIdentification `xml:"PartyIdentification,omitempty"`
+}
+
+type sarXMLPartyName struct {
+	PartyNameTypeCode           string `xml:"PartyNameTypeCode"`
+	RawEntityIndividualLastName string `xml:"RawEntityIndividualLastName,omitempty"`
+	RawIndividualFirstName      string `xml:"RawIndividualFirstName,omitempty"`
+	RawPartyFullName            string `xml:"RawPartyFullName,omitempty"`
+}
+
+type sarXMLAddress struct {
+	RawCityText           string `xml:"RawCityText"`
+	RawCountryCodeText    string `xml:"RawCountryCodeText"`
+	RawStreetAddress1Text string `xml:"RawStreetAddress1Text"`
+	RawZIPCode            string `xml:"RawZIPCode,omitempty"`
+}
+
+type sarXMLPartyIdentification struct {
+	PartyIdentificationNumberText string `xml:"PartyIdentificationNumberText"`
+	PartyIdentificationTypeCode   string `xml:"PartyIdentificationTypeCode"`
+}
+
+type sarXMLSuspiciousActivity struct {
+	SuspiciousActivityFromDateText   string                         `xml:"SuspiciousActivityFromDateText"`
+	SuspiciousActivityToDateText     string                         `xml:"SuspiciousActivityToDateText"`
+	TotalSuspiciousAmountText        int64                          `xml:"TotalSuspiciousAmountText"`
+	SuspiciousActivityClassification []sarXMLActivityClassification `xml:"SuspiciousActivityClassification"`
+}
+
+type sarXMLActivityClassification struct {
+	SeqNum                     int    `xml:"SeqNum,attr"`
+	SuspiciousActivityTypeText string `xml:"SuspiciousActivityTypeText"`
+}
+
+type sarXMLNarrativeInformation struct {
+	ActivityNarrativeSequenceNumber int    `xml:"ActivityNarrativeSequenceNumber"`
+	ActivityNarrativeText           string `xml:"ActivityNarrativeText"`
+}
+
+type sarXMLSuspiciousTransaction struct {
+	SeqNum                    int     `xml:"SeqNum,attr"`
+	TransactionIdentifierText string  `xml:"TransactionIdentifierText"`
+	TransactionDateText       string  `xml:"TransactionDateText"`
+	CardIdentifierText        int64   `xml:"CardIdentifierText"`
+	AmountText                float64 `xml:"AmountText"`
+}
+
+// sarActivityTotal returns the total of the transactions and the dates of the first and last one. The transactions
+// are ordered by time.
+func sarActivityTotal(sar repository.SAR) (float64, time.Time, time.Time) {
+	var total float64
+	for _, transaction := range sar.Transactions {
+		total += transaction.Amount
+	}
+	if len(sar.Transactions) == 0 {
+		return 0, time.Time{}, time.Time{}
+	}
+	return total, sar.Transactions[0].OccurredAt, sar.Transactions[len(sar.Transactions)-1].OccurredAt
+}
+
+func renderSARXML(sar repository.SAR, documentNumber string, filerName string, now time.Time) ([]byte, error) {
+	total, from, to := sarActivityTotal(sar)
+	// the SAR reports amounts in whole dollars, rounded up
+	totalDollars := int64(math.Ceil(total))
+
+	lastName, firstName := splitSubjectName(sar.Subject.Name)
+	subject := sarXMLParty{
+		SeqNum:                  2,
+		ActivityPartyTypeCode:   sarPartySubject,
+		IndividualBirthDateText: strings.ReplaceAll(sar.Subject.DateOfBirth, "-", ""),
+		PartyName:               sarXMLPartyName{PartyNameTypeCode: "L", RawEntityIndividualLastName: lastName, RawIndividualFirstName: firstName},
+		Address: &sarXMLAddress{
+			RawCityText:           sar.Subject.City,
+			RawCountryCodeText:    sar.Subject.Country,
+			RawStreetAddress1Text: sar.Subject.AddressLine,
+			RawZIPCode:            sar.Subject.PostalCode,
+		},
+	}
+	if documentNumber != "" {
+		subject.PartyIdentification = &sarXMLPartyIdentification{
+			PartyIdentificationNumberText: documentNumber,
+			PartyIdentificationTypeCode:   sarIDTypeCodes[sar.Subject.IDDocumentType],
+		}
+	}
+
+	activity := sarXMLActivity{
+		SeqNum:         1,
+		FilingDateText: now.Format(sarDateLayout),
+		Party: []sarXMLParty{
+			{SeqNum: 1, ActivityPartyTypeCode: sarPartyFilingInstitution, PartyName: sarXMLPartyName{PartyNameTypeCode: "L", RawPartyFullName: filerName}},
+			subject,
+		},
+		SuspiciousActivity: sarXMLSuspiciousActivity{
+			SuspiciousActivityFromDateText: from.Format(sarDateLayout),
+			SuspiciousActivityToDateText:   to.Format(sarDateLayout),
+			TotalSuspiciousAmountText:      totalDollars,
+		},
+		ActivityNarrativeInformation: sarXMLNarrativeInformation{ActivityNarrativeSequenceNumber: 1, ActivityNarrativeText: sar.Narrative},
+	}
+	// a report filed again after a rejection amends the previous filing
+	if sar.FilingReference != "" {
+		activity.EFilingPriorDocumentNumber = sar.FilingReference
+		activity.ActivityAssociation.CorrectsAmendsPriorIndicator = "Y"
+	} else {
+		activity.ActivityAssociation.InitialReportIndicator = "Y"
+	}
+	for i, activityType := range sar.ActivityTypes {
+		activity.SuspiciousActivity.SuspiciousActivityClassification = append(activity.SuspiciousActivity.SuspiciousActivityClassification,
+			sarXMLActivityClassification{SeqNum: i + 1, SuspiciousActivityTypeText: sarActivityTypeNames[activityType]})
+	}
+	for i, transaction := range sar.Transactions {
+		activity.SuspiciousTransaction = append(activity.SuspiciousTransaction, sarXMLSuspiciousTransaction{
+			SeqNum:                    i + 1,
+			TransactionIdentifierText: transaction.TransactionID,
+			TransactionDateText:       transaction.OccurredAt.Format(sarDateLayout),
+			CardIdentifierText:        transaction.CardID,
+			AmountText:                transaction.Amount,
+		})
+	}
+
+	body, err := xml.MarshalIndent(sarXMLBatch{ActivityCount: 1, TotalAmount: totalDollars, FormTypeCode: "SARX", Activity: activity}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+// renderSARSummary writes the report as plain text. incomplete, when not nil, lists what is missing for filing.
+func renderSARSummary(sar repository.SAR, filerName string, incomplete error) string {
+	total, from, to := sarActivityTotal(sar)
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "SUSPICIOUS ACTIVITY REPORT %d - CONFIDENTIAL\n", sar.ID)
+	fmt.Fprintln(&summary, "Do not disclose the existence of this report to the subject or any person involved in the transactions.")
+	fmt.Fprintln(&summary)
+	fmt.Fprintf(&summary, "Status:        %s\n", sar.Status)
+	if sar.FilingReference != "" {
+		fmt.Fprintf(&summary, "Reference:     %s\n", sar.FilingReference)
+	}
+	if sar.FiledAt != nil {
+		fmt.Fprintf(&summary, "Filed at:      %s\n", sar.FiledAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&summary, "Filer:         %s\n", filerName)
+	fmt.Fprintf(&summary, "Prepared by:   %s\n", sar.PreparedBy)
+	fmt.Fprintf(&summary, "Case:          %d\n", sar.CaseID)
+	if incomplete != nil {
+		fmt.Fprintf(&summary, "Not ready:     %s\n", strings.TrimPrefix(incomplete.Error(), ErrIncompleteSAR.Error()+": "))
+	}
+
+	fmt.Fprintln(&summary, "\nSUBJECT")
+	fmt.Fprintf(&summary, "Name:          %s\n", sar.Subject.Name)
+	fmt.Fprintf(&summary, "Date of birth: %s\n", sar.Subject.DateOfBirth)
+	fmt.Fprintf(&summary, "Address:       %s\n", joinNonEmpty(", ", sar.Subject.AddressLine, sar.Subject.City, sar.Subject.PostalCode, sar.Subject.Country))
+	if sar.Subject.IDDocumentType != "" {
+		fmt.Fprintf(&summary, "ID document:   %s ending in %s\n", sar.Subject.IDDocumentType, sar.Subject.IDDocumentLast4)
+	}
+
+	fmt.Fprintln(&summary, "\nSUSPICIOUS ACTIVITY")
+	activityTypes := make([]string, 0, len(sar.ActivityTypes))
+	for _, activityType := range sar.ActivityTypes {
+		activityTypes = append(activityTypes, sarActivityTypeNames[activityType])
+	}
+	fmt.Fprintf(&summary, "Types:         %s\n", strings.Join(activityTypes, ", "))
+	if len(sar.Transactions) > 0 {
+		fmt.Fprintf(&summary, "Period:        %s to %s\n", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+	fmt.Fprintf(&summary, "Total amount:  %.2f\n", total)
+
+	fmt.Fprintf(&summary, "\nTRANSACTIONS (%d)\n", len(sar.Transactions))
+	for _, transaction := range sar.Transactions {
+		fmt.Fprintf(&summary, "%s  %-20s card %-6d %12.2f\n", transaction.OccurredAt.Format(time.DateOnly), transaction.TransactionID, transaction.CardID, transaction.Amount)
+	}
+
+	fmt.Fprintln(&summary, "\nNARRATIVE")
+	fmt.Fprintln(&summary, sar.Narrative)
+
+	return summary.String()
+}
+
+// splitSubjectName splits a full name into the last name, taken as the last word, and the first names.
+func splitSubjectName(name string) (string, string) {
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return name, ""
+	}
+	return words[len(words)-1], strings.Join(words[:len(words)-1], " ")
+}
+
+func joinNonEmpty(separator string, values ...string) string {
+	var kept []string
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return strings.Join(kept, separator)
+}
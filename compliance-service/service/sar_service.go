@@ -0,0 +1,468 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/logging"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	SARStatusDraft        = "draft"
+	SARStatusFiled        = "filed"
+	SARStatusAcknowledged = "acknowledged"
+	SARStatusRejected     = "rejected"
+
+	SARActivityStructuring        = "structuring"
+	SARActivityMoneyLaundering    = "money_laundering"
+	SARActivityFraud              = "fraud"
+	SARActivityIdentityTheft      = "identity_theft"
+	SARActivityTerroristFinancing = "terrorist_financing"
+	SARActivityOther              = "other"
+
+	minSARNarrativeLength = 100
+	// maxSARNarrativeLength is the size of the narrative field of the FinCEN SAR.
+	maxSARNarrativeLength = 17000
+	defaultSARFilerName   = "Fraud Prevention System"
+)
+
+var (
+	ErrSARNotFound          = errors.New("SAR not found")
+	ErrSARExists            = errors.New("a SAR already exists for the case")
+	ErrSARNotDraft          = errors.New("SAR is not a draft")
+	ErrCaseNotConcluded     = errors.New("case is not concluded")
+	ErrInvalidSAR           = errors.New("invalid SAR")
+	ErrIncompleteSAR        = errors.New("incomplete SAR")
+	ErrInvalidSARTransition = errors.New("invalid SAR status transition")
+	ErrSARAccessDenied      = errors.New("access to SARs is restricted to compliance officers")
+
+	sarActivityTypes = []string{SARActivityStructuring, SARActivityMoneyLaundering, SARActivityFraud, SARActivityIdentityTheft,
+		SARActivityTerroristFinancing, SARActivityOther}
+)
+
+// sarTransitions lists, for each status, the statuses a report may move to. A report rejected by the regulator goes
+// back to draft to be corrected and filed again.
+var sarTransitions = map[string][]string{
+	SARStatusDraft:    {SARStatusFiled},
+	SARStatusFiled:    {SARStatusAcknowledged, SARStatusRejected},
+	SARStatusRejected: {SARStatusDraft},
+}
+
+// SARUpdate holds the changes to a draft report, nil fields are left as they are. The ID document of the subject
+// always comes from the KYC profile.
+type SARUpdate struct {
+	Subject       *repository.SARSubject `json:"subject"`
+	ActivityTypes []string               `json:"activity_types"`
+	Narrative     *string                `json:"narrative"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source sar_service.go -destination mock/sar_service_mock.go -package mock
+type SARService interface {
+	DraftSAR(officer string, caseID int64, activityTypes []string, narrative string) (repository.SAR, error)
+	GetSAR(officer string, sarID int64) (repository.SAR, error)
+	ListSARs(officer string, status string) ([]repository.SAR, error)
+	UpdateDraft(officer string, sarID int64, update SARUpdate) (repository.SAR, error)
+	UpdateStatus(officer string, sarID int64, status string, reference string, note string) (repository.SAR, error)
+	ExportXML(officer string, sarID int64) ([]byte, error)
+	ExportSummary(officer string, sarID int64) (string, error)
+	ListAccess(officer string, sarID int64) ([]repository.SARAccess, error)
+}
+
+type sarService struct {
+	sarRepository  repository.SARRepository
+	caseRepository repository.CaseRepository
+	kycRepository  repository.KYCRepository
+	cipher         fieldCipher
+	officers       []string
+	filerName      string
+	now            func() time.Time
+}
+
+// NewSARService reads the compliance officers allowed to access the reports from SAR_OFFICERS, a comma-separated
+// list, and the name of the filing institution from SAR_FILER_NAME. The ID document numbers copied from the KYC
+// profiles are decrypted with KYC_ENCRYPTION_KEY, which must be set.
+func NewSARService(sarRepository repository.SARRepository, caseRepository repository.CaseRepository, kycRepository repository.KYCRepository) (SARService, error) {
+	encryptionKey, err := secretKey("KYC_ENCRYPTION_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	var officers []string
+	for _, officer := range strings.Split(os.Getenv("SAR_OFFICERS"), ",") {
+		if officer = strings.TrimSpace(officer); officer != "" {
+			officers = append(officers, officer)
+		}
+	}
+	if len(officers) == 0 {
+		slog.Warn("SAR_OFFICERS is not set, nobody can access the Suspicious Activity Reports")
+	}
+
+	filerName := strings.TrimSpace(os.Getenv("SAR_FILER_NAME"))
+	if filerName == "" {
+		filerName = defaultSARFilerName
+	}
+
+	return &sarService{
+		sarRepository:  sarRepository,
+		caseRepository: caseRepository,
+		kycRepository:  kycRepository,
+		cipher:         newFieldCipher(encryptionKey),
+		officers:       officers,
+		filerName:      filerName,
+		now:            func() time.Time { return time.Now().UTC() },
+	}, nil
+}
+
+// DraftSAR starts a report from a case concluded as fraud. The subject comes from the KYC profile of the user, the
+// transactions from the case and, when no narrative is given, the narrative starts from the case notes.
+func (s *sarService) DraftSAR(officer string, caseID int64, activityTypes []string, narrative string) (repository.SAR, error) {
+	if err := s.authorize(officer); err != nil {
+		return repository.SAR{}, err
+	}
+
+	activityTypes, err := validateSARActivityTypes(activityTypes)
+	if err != nil {
+		return repository.SAR{}, err
+	}
+
+	c, err := s.caseRepository.GetCase(caseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.SAR{}, ErrCaseNotFound
+		}
+		return repository.SAR{}, err
+	}
+	if c.Status != CaseStatusConfirmedFraud {
+		return repository.SAR{}, fmt.Errorf("%w: a SAR can only be drafted for a case in %s, the case is %s", ErrCaseNotConcluded, CaseStatusConfirmedFraud, c.Status)
+	}
+
+	subject := repository.SARSubject{UserID: c.UserID}
+	profile, err := s.kycRepository.GetProfile(c.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return repository.SAR{}, err
+	}
+	if err == nil {
+		subject = repository.SARSubject{
+			UserID:           c.UserID,
+			Name:             profile.LegalName,
+			DateOfBirth:      profile.DateOfBirth,
+			AddressLine:      profile.AddressLine,
+			City:             profile.City,
+			PostalCode:       profile.PostalCode,
+			Country:          profile.Country,
+			IDDocumentType:   profile.IDDocumentType,
+			IDDocumentNumber: profile.IDDocumentNumber,
+			IDDocumentLast4:  profile.IDDocumentLast4,
+		}
+	}
+
+	caseTransactions, err := s.caseRepository.GetCaseTransactions(caseID)
+	if err != nil {
+		return repository.SAR{}, err
+	}
+	transactions := make([]repository.SARTransaction, 0, len(caseTransactions))
+	for _, transaction := range caseTransactions {
+		// the transactions attached before their date was recorded are dated when they were attached
+		occurredAt := transaction.AttachedAt
+		if transaction.OccurredAt != nil {
+			occurredAt = *transaction.OccurredAt
+		}
+		transactions = append(transactions, repository.SARTransaction{
+			TransactionID: transaction.TransactionID,
+			CardID:        transaction.CardID,
+			Amount:        transaction.Amount,
+			OccurredAt:    occurredAt,
+		})
+	}
+
+	narrative = strings.TrimSpace(narrative)
+	if narrative == "" {
+		notes, err := s.caseRepository.GetCaseNotes(caseID)
+		if err != nil {
+			return repository.SAR{}, err
+		}
+		narrative = caseNarrative(c, notes)
+	}
+	if utf8.RuneCountInString(narrative) > maxSARNarrativeLength {
+		return repository.SAR{}, fmt.Errorf("%w: narrative must be at most %d characters", ErrInvalidSAR, maxSARNarrativeLength)
+	}
+
+	now := s.now()
+	sar := repository.SAR{
+		CaseID:        caseID,
+		Status:        SARStatusDraft,
+		Subject:       subject,
+		ActivityTypes: activityTypes,
+		Narrative:     narrative,
+		PreparedBy:    officer,
+		Transactions:  transactions,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	sar.ID, err = s.sarRepository.CreateSAR(sar)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "UNIQUE constraint failed") {
+			return repository.SAR{}, ErrSARExists
+		}
+		return repository.SAR{}, err
+	}
+
+	s.logAccess(sar.ID, officer, "draft")
+	return sar, nil
+}
+
+func (s *sarService) GetSAR(officer string, sarID int64) (repository.SAR, error) {
+	return s.accessSAR(officer, sarID, "view")
+}
+
+func (s *sarService) ListSARs(officer string, status string) ([]repository.SAR, error) {
+	if err := s.authorize(officer); err != nil {
+		return nil, err
+	}
+	return s.sarRepository.ListSARs(status)
+}
+
+func (s *sarService) UpdateDraft(officer string, sarID int64, update SARUpdate) (repository.SAR, error) {
+	sar, err := s.accessSAR(officer, sarID, "update")
+	if err != nil {
+		return repository.SAR{}, err
+	}
+	if sar.Status != SARStatusDraft {
+		return repository.SAR{}, fmt.Errorf("%w: SAR is %s", ErrSARNotDraft, sar.Status)
+	}
+
+	if update.Subject != nil {
+		subject := *update.Subject
+		subject.UserID, subject.IDDocumentType, subject.IDDocumentNumber, subject.IDDocumentLast4 =
+			sar.Subject.UserID, sar.Subject.IDDocumentType, sar.Subject.IDDocumentNumber, sar.Subject.IDDocumentLast4
+		if sar.Subject, err = validateSARSubject(subject); err != nil {
+			return repository.SAR{}, err
+		}
+	}
+	if update.ActivityTypes != nil {
+		if sar.ActivityTypes, err = validateSARActivityTypes(update.ActivityTypes); err != nil {
+			return repository.SAR{}, err
+		}
+	}
+	if update.Narrative != nil {
+		sar.Narrative = strings.TrimSpace(*update.Narrative)
+		if utf8.RuneCountInString(sar.Narrative) > maxSARNarrativeLength {
+			return repository.SAR{}, fmt.Errorf("%w: narrative must be at most %d characters", ErrInvalidSAR, maxSARNarrativeLength)
+		}
+	}
+
+	sar.UpdatedAt = s.now()
+	if err := s.sarRepository.UpdateDraft(sar); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the report was filed in the meantime
+			return repository.SAR{}, ErrSARNotDraft
+		}
+		return repository.SAR{}, err
+	}
+
+	return sar, nil
+}
+
+// UpdateStatus tracks the filing of the report. Filing requires a complete report; the acknowledgement records the
+// reference given by the regulator (the BSA ID) and a rejection its reason.
+func (s *sarService) UpdateStatus(officer string, sarID int64, status string, reference string, note string) (repository.SAR, error) {
+	sar, err := s.accessSAR(officer, sarID, "status:"+status)
+	if err != nil {
+		return repository.SAR{}, err
+	}
+
+	if !slices.Contains(sarTransitions[sar.Status], status) {
+		return repository.SAR{}, fmt.Errorf("%w: from %s to %s", ErrInvalidSARTransition, sar.Status, status)
+	}
+
+	reference, note = strings.TrimSpace(reference), strings.TrimSpace(note)
+	switch {
+	case status == SARStatusAcknowledged && reference == "":
+		return repository.SAR{}, fmt.Errorf("%w: an acknowledgement needs the filing reference", ErrInvalidSAR)
+	case status == SARStatusRejected && note == "":
+		return repository.SAR{}, fmt.Errorf("%w: a rejection needs a note with the reason", ErrInvalidSAR)
+	case len(reference) > maxKYCFieldLength || len(note) > maxKYCReviewLength:
+		return repository.SAR{}, fmt.Errorf("%w: reference must be at most %d characters and note at most %d", ErrInvalidSAR, maxKYCFieldLength, maxKYCReviewLength)
+	}
+	if status == SARStatusFiled {
+		if err := validateSARForFiling(sar); err != nil {
+			return repository.SAR{}, err
+		}
+	}
+	if reference == "" {
+		reference = sar.FilingReference
+	}
+
+	now := s.now()
+	var filedAt *time.Time
+	if status == SARStatusFiled {
+		filedAt = &now
+	}
+	if err := s.sarRepository.UpdateStatus(sarID, sar.Status, status, reference, note, filedAt, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.SAR{}, fmt.Errorf("%w: the SAR was updated in the meantime", ErrInvalidSARTransition)
+		}
+		return repository.SAR{}, err
+	}
+
+	sar.Status, sar.FilingReference, sar.StatusNote, sar.UpdatedAt = status, reference, note, now
+	if filedAt != nil {
+		sar.FiledAt = filedAt
+	}
+	return sar, nil
+}
+
+// ExportXML renders a complete report in the FinCEN SAR XML layout, including the full ID document number.
+func (s *sarService) ExportXML(officer string, sarID int64) ([]byte, error) {
+	sar, err := s.accessSAR(officer, sarID, "export:xml")
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSARForFiling(sar); err != nil {
+		return nil, err
+	}
+
+	documentNumber := ""
+	if sar.Subject.IDDocumentNumber != "" {
+		if documentNumber, err = s.cipher.decrypt(sar.Subject.IDDocumentNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	return renderSARXML(sar, documentNumber, s.filerName, s.now())
+}
+
+// ExportSummary renders the report as plain text, for review before filing. It only shows the last characters of
+// the ID document number.
+func (s *sarService) ExportSummary(officer string, sarID int64) (string, error) {
+	sar, err := s.accessSAR(officer, sarID, "export:text")
+	if err != nil {
+		return "", err
+	}
+
+	return renderSARSummary(sar, s.filerName, validateSARForFiling(sar)), nil
+}
+
+func (s *sarService) ListAccess(officer string, sarID int64) ([]repository.SARAccess, error) {
+	if _, err := s.accessSAR(officer, sarID, "access_log"); err != nil {
+		return nil, err
+	}
+	return s.sarRepository.ListAccess(sarID)
+}
+
+func (s *sarService) authorize(officer string) error {
+	if officer == "" || !slices.Contains(s.officers, officer) {
+		return ErrSARAccessDenied
+	}
+	return nil
+}
+
+// accessSAR loads the report for an authorized officer and records the access in the audit trail.
+func (s *sarService) accessSAR(officer string, sarID int64, action string) (repository.SAR, error) {
+	if err := s.authorize(officer); err != nil {
+		return repository.SAR{}, err
+	}
+
+	sar, err := s.sarRepository.GetSAR(sarID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.SAR{}, ErrSARNotFound
+		}
+		return repository.SAR{}, err
+	}
+
+	s.logAccess(sarID, officer, action)
+	return sar, nil
+}
+
+func (s *sarService) logAccess(sarID int64, officer string, action string) {
+	if err := s.sarRepository.LogAccess(sarID, officer, action, s.now()); err != nil {
+		slog.Error("error logging SAR access", slog.String("action", action), slog.Int64("sar_id", sarID),
+			slog.String("officer", officer), logging.Err(err))
+	}
+}
+
+func validateSARActivityTypes(activityTypes []string) ([]string, error) {
+	validated := []string{}
+	for _, activityType := range activityTypes {
+		activityType = strings.ToLower(strings.TrimSpace(activityType))
+		if !slices.Contains(sarActivityTypes, activityType) {
+			return nil, fmt.Errorf("%w: activity type must be one of %s", ErrInvalidSAR, strings.Join(sarActivityTypes, ", "))
+		}
+		if !slices.Contains(validated, activityType) {
+			validated = append(validated, activityType)
+		}
+	}
+	return validated, nil
+}
+
+func validateSARSubject(subject repository.SARSubject) (repository.SARSubject, error) {
+	subject.Name, subject.DateOfBirth = strings.TrimSpace(subject.Name), strings.TrimSpace(subject.DateOfBirth)
+	subject.AddressLine, subject.City = strings.TrimSpace(subject.AddressLine), strings.TrimSpace(subject.City)
+	subject.PostalCode, subject.Country = strings.TrimSpace(subject.PostalCode), strings.ToUpper(strings.TrimSpace(subject.Country))
+
+	for _, value := range []string{subject.Name, subject.AddressLine, subject.City, subject.PostalCode} {
+		if utf8.RuneCountInString(value) > maxKYCFieldLength {
+			return repository.SARSubject{}, fmt.Errorf("%w: subject fields must be at most %d characters", ErrInvalidSAR, maxKYCFieldLength)
+		}
+	}
+	if subject.DateOfBirth != "" {
+		if _, err := time.Parse(dateOfBirthLayout, subject.DateOfBirth); err != nil {
+			return repository.SARSubject{}, fmt.Errorf("%w: date of birth must be in YYYY-MM-DD format", ErrInvalidSAR)
+		}
+	}
+	if subject.Country != "" && !countryCodePattern.MatchString(subject.Country) {
+		return repository.SARSubject{}, fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidSAR)
+	}
+
+	return subject, nil
+}
+
+// validateSARForFiling lists every required field missing from the report.
+func validateSARForFiling(sar repository.SAR) error {
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"subject name", sar.Subject.Name},
+		{"subject date of birth", sar.Subject.DateOfBirth},
+		{"subject address line", sar.Subject.AddressLine},
+		{"subject city", sar.Subject.City},
+		{"subject country", sar.Subject.Country},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(sar.ActivityTypes) == 0 {
+		missing = append(missing, "activity types")
+	}
+	if len(sar.Transactions) == 0 {
+		missing = append(missing, "transactions")
+	}
+	if utf8.RuneCountInString(sar.Narrative) < minSARNarrativeLength {
+		missing = append(missing, fmt.Sprintf("narrative of at least %d characters", minSARNarrativeLength))
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrIncompleteSAR, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// caseNarrative is the starting point of the narrative: how the case was opened and the investigators' notes.
+func caseNarrative(c repository.Case, notes []repository.CaseNote) string {
+	var narrative strings.Builder
+	fmt.Fprintf(&narrative, "Case %d was opened on %s from a %s and concluded as %s.", c.ID, c.CreatedAt.Format(time.DateOnly),
+		strings.ReplaceAll(c.Source, "_", " "), strings.ReplaceAll(c.Status, "_", " "))
+	for _, note := range notes {
+		fmt.Fprintf(&narrative, "\n%s, %s: %s", note.CreatedAt.Format(time.DateOnly), note.Author, note.Body)
+	}
+	return narrative.String()
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type sarMocks struct {
+	sarRepositoryMock  *mock.MockSARRepository
+	caseRepositoryMock *mock.MockCaseRepository
+	kycRepositoryMock  *mock.MockKYCRepository
+}
+
+func newTestSARService(ctrl *gomock.Controller, now time.Time) (*sarService, sarMocks) {
+	mocks := sarMocks{
+		sarRepositoryMock:  mock.NewMockSARRepository(ctrl),
+		caseRepositoryMock: mock.NewMockCaseRepository(ctrl),
+		kycRepositoryMock:  mock.NewMockKYCRepository(ctrl),
+	}
+	return &sarService{
+		sarRepository:  mocks.sarRepositoryMock,
+		caseRepository: mocks.caseRepositoryMock,
+		kycRepository:  mocks.kycRepositoryMock,
+		cipher:         newFieldCipher("test-key"),
+		officers:       []string{"alice"},
+		filerName:      "Test Bank",
+		now:            func() time.Time { return now },
+	}, mocks
+}
+
+func completeSAR(now time.Time) repository.SAR {
+	return repository.SAR{
+		ID:     3,
+		CaseID: 7,
+		Status: SARStatusDraft,
+		Subject: repository.SARSubject{UserID: 1, Name: "John Doe", DateOfBirth: "1990-05-17", AddressLine: "1 Main St", City: "Springfield",
+			Country: "US", IDDocumentType: IDDocumentPassport, IDDocumentLast4: "6789"},
+		ActivityTypes: []string{SARActivityStructuring},
+		Narrative:     strings.Repeat("Repeated payments just below the reporting threshold. ", 3),
+		PreparedBy:    "alice",
+		Transactions: []repository.SARTransaction{
+			{TransactionID: "txn_1", CardID: 2, Amount: 9500, OccurredAt: now.Add(-48 * time.Hour)},
+			{TransactionID: "txn_2", CardID: 2, Amount: 9800.5, OccurredAt: now.Add(-24 * time.Hour)},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestDraftSAR(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	occurredAt := now.Add(-30 * time.Hour)
+	confirmedCase := repository.Case{ID: 7, UserID: 1, Source: CaseSourceManual, Status: CaseStatusConfirmedFraud, CreatedAt: now.Add(-72 * time.Hour)}
+
+	tests := []struct {
+		name          string
+		officer       string
+		activityTypes []string
+		on            func(mocks sarMocks)
+		assertFunc    func(t *testing.T, sar repository.SAR, err error)
+	}{
+		{
+			name:          "Success - Subject from the KYC profile and narrative from the case notes",
+			officer:       "alice",
+			activityTypes: []string{"Structuring", "structuring", "fraud"},
+			on: func(mocks sarMocks) {
+				mocks.caseRepositoryMock.EXPECT().GetCase(int64(7)).Return(confirmedCase, nil)
+				mocks.kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(repository.KYCProfile{UserID: 1, LegalName: "John Doe",
+					Country: "US", IDDocumentType: IDDocumentPassport, IDDocumentNumber: "ciphertext", IDDocumentLast4: "6789"}, nil)
+				mocks.caseRepositoryMock.EXPECT().GetCaseTransactions(int64(7)).Return([]repository.CaseTransaction{
+					{TransactionID: "txn_1", CardID: 2, Amount: 9500, AttachedAt: now.Add(-time.Hour)},
+					{TransactionID: "txn_2", CardID: 2, Amount: 9800, OccurredAt: &occurredAt, AttachedAt: now.Add(-time.Hour)},
+				}, nil)
+				mocks.caseRepositoryMock.EXPECT().GetCaseNotes(int64(7)).Return([]repository.CaseNote{
+					{Author: "bob", Body: "five payments below 10000 in a day", CreatedAt: now.Add(-2 * time.Hour)},
+				}, nil)
+				mocks.sarRepositoryMock.EXPECT().CreateSAR(gomock.Any()).Return(int64(3), nil)
+				mocks.sarRepositoryMock.EXPECT().LogAccess(int64(3), "alice", "draft", now).Return(nil)
+			},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(3), sar.ID)
+				assert.Equal(t, SARStatusDraft, sar.Status)
+				assert.Equal(t, []string{SARActivityStructuring, SARActivityFraud}, sar.ActivityTypes)
+				assert.Equal(t, "John Doe", sar.Subject.Name)
+				assert.Equal(t, "ciphertext", sar.Subject.IDDocumentNumber)
+				assert.Equal(t, []repository.SARTransaction{
+					{TransactionID: "txn_1", CardID: 2, Amount: 9500, OccurredAt: now.Add(-time.Hour)},
+					{TransactionID: "txn_2", CardID: 2, Amount: 9800, OccurredAt: occurredAt},
+				}, sar.Transactions)
+				assert.Equal(t, "Case 7 was opened on 2025-02-26 from a manual and concluded as confirmed fraud.\n"+
+					"2025-03-01, bob: five payments below 10000 in a day", sar.Narrative)
+			},
+		},
+		{
+			name:    "Failure - Not a compliance officer",
+			officer: "mallory",
+			on:      func(mocks sarMocks) {},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrSARAccessDenied)
+			},
+		},
+		{
+			name:          "Failure - Unknown activity type",
+			officer:       "alice",
+			activityTypes: []string{"gambling"},
+			on:            func(mocks sarMocks) {},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrInvalidSAR)
+			},
+		},
+		{
+			name:    "Failure - Case still under investigation",
+			officer: "alice",
+			on: func(mocks sarMocks) {
+				investigating := confirmedCase
+				investigating.Status = CaseStatusInvestigating
+				mocks.caseRepositoryMock.EXPECT().GetCase(int64(7)).Return(investigating, nil)
+			},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrCaseNotConcluded)
+			},
+		},
+		{
+			name:    "Failure - A SAR already exists for the case",
+			officer: "alice",
+			on: func(mocks sarMocks) {
+				mocks.caseRepositoryMock.EXPECT().GetCase(int64(7)).Return(confirmedCase, nil)
+				mocks.kycRepositoryMock.EXPECT().GetProfile(int64(1)).Return(repository.KYCProfile{}, sql.ErrNoRows)
+				mocks.caseRepositoryMock.EXPECT().GetCaseTransactions(int64(7)).Return(nil, nil)
+				mocks.caseRepositoryMock.EXPECT().GetCaseNotes(int64(7)).Return(nil, nil)
+				mocks.sarRepositoryMock.EXPECT().CreateSAR(gomock.Any()).Return(int64(0), errors.New("UNIQUE constraint failed: sars.case_id"))
+			},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrSARExists)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s, mocks := newTestSARService(ctrl, now)
+			tt.on(mocks)
+
+			sar, err := s.DraftSAR(tt.officer, 7, tt.activityTypes, "")
+			tt.assertFunc(t, sar, err)
+		})
+	}
+}
+
+func TestUpdateSARStatus(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		sar        func(sar repository.SAR) repository.SAR
+		status     string
+		reference  string
+		note       string
+		on         func(mocks sarMocks)
+		assertFunc func(t *testing.T, sar repository.SAR, err error)
+	}{
+		{
+			name:   "Success - Complete draft filed",
+			sar:    func(sar repository.SAR) repository.SAR { return sar },
+			status: SARStatusFiled,
+			on: func(mocks sarMocks) {
+				mocks.sarRepositoryMock.EXPECT().UpdateStatus(int64(3), SARStatusDraft, SARStatusFiled, "", "", &now, now).Return(nil)
+			},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, SARStatusFiled, sar.Status)
+				assert.Equal(t, &now, sar.FiledAt)
+			},
+		},
+		{
+			name: "Failure - Incomplete draft lists the missing fields",
+			sar: func(sar repository.SAR) repository.SAR {
+				sar.Subject.DateOfBirth, sar.Transactions, sar.Narrative = "", nil, "too short"
+				return sar
+			},
+			status: SARStatusFiled,
+			on:     func(mocks sarMocks) {},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrIncompleteSAR)
+				assert.EqualError(t, err, "incomplete SAR: missing subject date of birth, transactions, narrative of at least 100 characters")
+			},
+		},
+		{
+			name: "Success - Acknowledgement records the reference",
+			sar: func(sar repository.SAR) repository.SAR {
+				sar.Status = SARStatusFiled
+				return sar
+			},
+			status:    SARStatusAcknowledged,
+			reference: " 31000123456789 ",
+			on: func(mocks sarMocks) {
+				mocks.sarRepositoryMock.EXPECT().UpdateStatus(int64(3), SARStatusFiled, SARStatusAcknowledged, "31000123456789", "", nil, now).Return(nil)
+			},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "31000123456789", sar.FilingReference)
+			},
+		},
+		{
+			name: "Failure - Rejection without a note",
+			sar: func(sar repository.SAR) repository.SAR {
+				sar.Status = SARStatusFiled
+				return sar
+			},
+			status: SARStatusRejected,
+			on:     func(mocks sarMocks) {},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrInvalidSAR)
+			},
+		},
+		{
+			name:   "Failure - Draft cannot be acknowledged",
+			sar:    func(sar repository.SAR) repository.SAR { return sar },
+			status: SARStatusAcknowledged,
+			on:     func(mocks sarMocks) {},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrInvalidSARTransition)
+			},
+		},
+		{
+			name:   "Failure - Filed concurrently",
+			sar:    func(sar repository.SAR) repository.SAR { return sar },
+			status: SARStatusFiled,
+			on: func(mocks sarMocks) {
+				mocks.sarRepositoryMock.EXPECT().UpdateStatus(int64(3), SARStatusDraft, SARStatusFiled, "", "", &now, now).Return(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, sar repository.SAR, err error) {
+				assert.ErrorIs(t, err, ErrInvalidSARTransition)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s, mocks := newTestSARService(ctrl, now)
+			mocks.sarRepositoryMock.EXPECT().GetSAR(int64(3)).Return(tt.sar(completeSAR(now)), nil)
+			mocks.sarRepositoryMock.EXPECT().LogAccess(int64(3), "alice", "status:"+tt.status, now).Return(nil)
+			tt.on(mocks)
+
+			sar, err := s.UpdateStatus("alice", 3, tt.status, tt.reference, tt.note)
+			tt.assertFunc(t, sar, err)
+		})
+	}
+}
+
+func TestUpdateSARDraft(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, mocks := newTestSARService(ctrl, now)
+	filed := completeSAR(now)
+	filed.Status = SARStatusFiled
+	narrative := "  updated narrative  "
+
+	gomock.InOrder(
+		mocks.sarRepositoryMock.EXPECT().GetSAR(int64(3)).Return(completeSAR(now), nil),
+		mocks.sarRepositoryMock.EXPECT().LogAccess(int64(3), "alice", "update", now).Return(nil),
+		mocks.sarRepositoryMock.EXPECT().UpdateDraft(gomock.Any()).DoAndReturn(func(sar repository.SAR) error {
+			// the ID document is kept from the KYC profile
+			assert.Equal(t, IDDocumentPassport, sar.Subject.IDDocumentType)
+			assert.Equal(t, "FR", sar.Subject.Country)
+			assert.Equal(t, "updated narrative", sar.Narrative)
+			return nil
+		}),
+		mocks.sarRepositoryMock.EXPECT().GetSAR(int64(3)).Return(filed, nil),
+		mocks.sarRepositoryMock.EXPECT().LogAccess(int64(3), "alice", "update", now).Return(nil),
+	)
+
+	_, err := s.UpdateDraft("alice", 3, SARUpdate{
+		Subject:   &repository.SARSubject{Name: "John Doe", DateOfBirth: "1990-05-17", AddressLine: "1 Rue", City: "Paris", Country: "fr", IDDocumentType: "other"},
+		Narrative: &narrative,
+	})
+	assert.NoError(t, err)
+
+	_, err = s.UpdateDraft("alice", 3, SARUpdate{Narrative: &narrative})
+	assert.ErrorIs(t, err, ErrSARNotDraft)
+}
+
+func TestExportSAR(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, mocks := newTestSARService(ctrl, now)
+	sar := completeSAR(now)
+	documentNumber, err := s.cipher.encrypt("X123456789")
+	assert.NoError(t, err)
+	sar.Subject.IDDocumentNumber = documentNumber
+	incomplete := completeSAR(now)
+	incomplete.ActivityTypes = nil
+
+	mocks.sarRepositoryMock.EXPECT().GetSAR(int64(3)).Return(sar, nil).Times(2)
+	mocks.sarRepositoryMock.EXPECT().GetSAR(int64(4)).Return(incomplete, nil).Times(2)
+	mocks.sarRepositoryMock.EXPECT().GetSAR(int64(5)).Return(repository.SAR{}, sql.ErrNoRows)
+	mocks.sarRepositoryMock.EXPECT().LogAccess(gomock.Any(), "alice", gomock.Any(), now).Return(nil).Times(4)
+
+	body, err := s.ExportXML("alice", 3)
+	assert.NoError(t, err)
+	xml := string(body)
+	assert.Contains(t, xml, "<FormTypeCode>SARX</FormTypeCode>")
+	assert.Contains(t, xml, `TotalAmount="19301"`)
+	assert.Contains(t, xml, "<RawPartyFullName>Test Bank</RawPartyFullName>")
+	assert.Contains(t, xml, "<RawEntityIndividualLastName>Doe</RawEntityIndividualLastName>")
+	assert.Contains(t, xml, "<IndividualBirthDateText>19900517</IndividualBirthDateText>")
+	assert.Contains(t, xml, "<PartyIdentificationNumberText>X123456789</PartyIdentificationNumberText>")
+	assert.Contains(t, xml, "<InitialReportIndicator>Y</InitialReportIndicator>")
+
+	summary, err := s.ExportSummary("alice", 3)
+	assert.NoError(t, err)
+	assert.Contains(t, summary, "CONFIDENTIAL")
+	assert.Contains(t, summary, "passport ending in 6789")
+	assert.NotContains(t, summary, "X123456789")
+	assert.NotContains(t, summary, "Not ready")
+
+	_, err = s.ExportXML("alice", 4)
+	assert.ErrorIs(t, err, ErrIncompleteSAR)
+
+	summary, err = s.ExportSummary("alice", 4)
+	assert.NoError(t, err)
+	assert.Contains(t, summary, "Not ready:     missing activity types")
+
+	_, err = s.ExportXML("alice", 5)
+	assert.ErrorIs(t, err, ErrSARNotFound)
+
+	_, err = s.ExportXML("", 3)
+	assert.ErrorIs(t, err, ErrSARAccessDenied)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ScreeningHitPending   = "pending"
+	ScreeningHitConfirmed = "confirmed"
+	ScreeningHitDismissed = "dismissed"
+
+	defaultSanctionsListPath       = "./database/sdn.xml"
+	defaultSanctionsListName       = "ofac_sdn"
+	defaultSanctionsMatchScore     = 0.92
+	phoneticMatchScoreAllowance    = 0.07
+	maxScreeningReviewNoteLength   = 1000
+	maxScreeningReviewerNameLength = 128
+)
+
+var (
+	ErrScreeningHitNotFound   = errors.New("screening hit not found")
+	ErrInvalidScreeningReview = errors.New("invalid screening review")
+)
+
+type SanctionsRefresh struct {
+	Entries       int `json:"entries"`
+	UsersScreened int `json:"users_screened"`
+	NewHits       int `json:"new_hits"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source screening_service.go -destination mock/screening_service_mock.go -package mock
+type ScreeningService interface {
+	RefreshSanctions() (SanctionsRefresh, error)
+	ScreenUser(user repository.User) (int, error)
+	ListHits(status string) ([]repository.ScreeningHit, error)
+	ReviewHit(hitID int64, status string, reviewer string, note string) (repository.ScreeningHit, error)
+}
+
+type screeningService struct {
+	sanctionsRepository repository.SanctionsRepository
+	userRepository      repository.UserRepository
+	paymentRepository   repository.PaymentRepository
+	listPath            string
+	aliasesPath         string
+	listName            string
+	matchScore          float64
+	now                 func() time.Time
+}
+
+func NewScreeningService(sanctionsRepository repository.SanctionsRepository, userRepository repository.UserRepository,
+	paymentRepository repository.PaymentRepository) ScreeningService {
+	listPath := os.Getenv("SANCTIONS_LIST_PATH")
+	if listPath == "" {
+		listPath = defaultSanctionsListPath
+	}
+
+	listName := os.Getenv("SANCTIONS_LIST_NAME")
+	if listName == "" {
+		listName = defaultSanctionsListName
+	}
+
+	matchScore, err := strconv.ParseFloat(os.Getenv("SANCTIONS_MATCH_SCORE"), 64)
+	if err != nil || matchScore <= 0 || matchScore > 1 {
+		matchScore = defaultSanctionsMatchScore
+	}
+
+	return &screeningService{
+		sanctionsRepository: sanctionsRepository,
+		userRepository:      userRepository,
+		paymentRepository:   paymentRepository,
+		listPath:            listPath,
+		aliasesPath:         os.Getenv("SANCTIONS_ALIASES_PATH"),
+		listName:            listName,
+		matchScore:          matchScore,
+		now:                 func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// RefreshSanctions reloads the sanctions file and screens every user against the new list.
+func (s *screeningService) RefreshSanctions() (SanctionsRefresh, error) {
+	entries, err := loadSanctionsFile(s.listPath, s.aliasesPath)
+	if err != nil {
+		return SanctionsRefresh{}, err
+	}
+
+	if err := s.sanctionsRepository.ReplaceEntries(s.listName, entries, s.now()); err != nil {
+		return SanctionsRefresh{}, err
+	}
+	// the confirmed hits of the entries removed from the list no longer block their user
+	invalidateAllVerdicts(s.paymentRepository)
+
+	users, err := s.userRepository.ListUsers()
+	if err != nil {
+		return SanctionsRefresh{}, err
+	}
+
+	newHits, err := s.screen(users)
+	if err != nil {
+		return SanctionsRefresh{}, err
+	}
+
+	return SanctionsRefresh{Entries: len(entries), UsersScreened: len(users), NewHits: newHits}, nil
+}
+
+// ScreenUser matches the user name and full name of the user against the sanctions lists and queues the hits for review.
+func (s *screeningService) ScreenUser(user repository.User) (int, error) {
+	return s.screen([]repository.User{user})
+}
+
+func (s *screeningService) ListHits(status string) ([]repository.ScreeningHit, error) {
+	return s.sanctionsRepository.ListHits(status)
+}
+
+func (s *screeningService) ReviewHit(hitID int64, status string, reviewer string, note string) (repository.ScreeningHit, error) {
+	reviewer, note = strings.TrimSpace(reviewer), strings.TrimSpace(note)
+	if status != ScreeningHitConfirmed && status != ScreeningHitDismissed {
+		return repository.ScreeningHit{}, fmt.Errorf("%w: status must be %s or %s", ErrInvalidScreeningReview, ScreeningHitConfirmed, ScreeningHitDismissed)
+	}
+	if reviewer == "" || len(reviewer) > maxScreeningReviewerNameLength {
+		return repository.ScreeningHit{}, fmt.Errorf("%w: reviewer must be between 1 and %d characters", ErrInvalidScreeningReview, maxScreeningReviewerNameLength)
+	}
+	if len(note) > maxScreeningReviewNoteLength {
+		return repository.ScreeningHit{}, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidScreeningReview, maxScreeningReviewNoteLength)
+	}
+
+	if err := s.sanctionsRepository.ReviewHit(hitID, status, reviewer, note, s.now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ScreeningHit{}, ErrScreeningHitNotFound
+		}
+		return repository.ScreeningHit{}, err
+	}
+
+	hit, err := s.sanctionsRepository.GetHit(hitID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ScreeningHit{}, ErrScreeningHitNotFound
+		}
+		return repository.ScreeningHit{}, err
+	}
+	// a confirmed hit blocks the user, a dismissed one may lift an earlier confirmation
+	invalidateUserVerdicts(s.paymentRepository, hit.UserID)
+
+	return hit, nil
+}
+
+type tokenizedSanctionsName struct {
+	repository.SanctionsName
+	tokens []string
+}
+
+func (s *screeningService) screen(users []repository.User) (int, error) {
+	sanctionsNames, err := s.sanctionsRepository.GetActiveNames()
+	if err != nil {
+		return 0, err
+	}
+
+	names := make([]tokenizedSanctionsName, 0, len(sanctionsNames))
+	for _, name := range sanctionsNames {
+		names = append(names, tokenizedSanctionsName{SanctionsName: name, tokens: nameTokens(name.Name)})
+	}
+
+	now := s.now()
+	var hits []repository.ScreeningHit
+	for _, user := range users {
+		hits = append(hits, s.screenUser(user, names, now)...)
+	}
+
+	return s.sanctionsRepository.CreateHits(hits)
+}
+
+// screenUser returns at most one hit per sanctions entry, for the best scoring pair of user and entry names.
+func (s *screeningService) screenUser(user repository.User, names []tokenizedSanctionsName, now time.Time) []repository.ScreeningHit {
+	var hits []repository.ScreeningHit
+	hitIndexes := map[int64]int{}
+
+	for _, screenedName := range []string{user.FullName, user.UserName} {
+		tokens := nameTokens(screenedName)
+		for _, name := range names {
+			score, phonetic := matchNames(tokens, name.tokens)
+			if score < s.matchScore && !(phonetic && score >= s.matchScore-phoneticMatchScoreAllowance) {
+				continue
+			}
+
+			hit := repository.ScreeningHit{
+				UserID:       user.ID,
+				EntryID:      name.EntryID,
+				ScreenedName: screenedName,
+				MatchedName:  name.Name,
+				Score:        score,
+				Status:       ScreeningHitPending,
+				CreatedAt:    now,
+			}
+			if i, found := hitIndexes[name.EntryID]; !found {
+				hitIndexes[name.EntryID] = len(hits)
+				hits = append(hits, hit)
+			} else if score > hits[i].Score {
+				hits[i] = hit
+			}
+		}
+	}
+
+	return hits
+}
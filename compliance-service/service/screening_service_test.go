@@ -0,0 +1,228 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScreeningService(ctrl *gomock.Controller, now time.Time) (*screeningService, *mock.MockSanctionsRepository, *mock.MockUserRepository, *mock.MockPaymentRepository) {
+	sanctionsRepositoryMock := mock.NewMockSanctionsRepository(ctrl)
+	userRepositoryMock := mock.NewMockUserRepository(ctrl)
+	paymentRepositoryMock := mock.NewMockPaymentRepository(ctrl)
+	return &screeningService{
+		sanctionsRepository: sanctionsRepositoryMock,
+		userRepository:      userRepositoryMock,
+		paymentRepository:   paymentRepositoryMock,
+		listPath:            "../database/sdn.xml",
+		listName:            defaultSanctionsListName,
+		matchScore:          defaultSanctionsMatchScore,
+		now:                 func() time.Time { return now },
+	}, sanctionsRepositoryMock, userRepositoryMock, paymentRepositoryMock
+}
+
+func TestRefreshSanctions(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	users := []repository.User{{ID: 1, UserName: "john_doe", FullName: "John Doe"}, {ID: 2, UserName: "vkravchenko", FullName: "Виктор Кравченко"}}
+	names := []repository.SanctionsName{
+		{EntryID: 4, EntryName: "Viktor KRAVCHENKO", Name: "Viktor KRAVCHENKO"},
+		{EntryID: 4, EntryName: "Viktor KRAVCHENKO", Name: "Виктор КРАВЧЕНКО"},
+		{EntryID: 5, EntryName: "NORTHWIND TRADING FZE", Name: "NORTHWIND TRADING FZE"},
+	}
+
+	tests := []struct {
+		name       string
+		listPath   string
+		on         func(sanctionsRepositoryMock *mock.MockSanctionsRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, refresh SanctionsRefresh, err error)
+	}{
+		{
+			name: "Success - Every user screened against the new list",
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				sanctionsRepositoryMock.EXPECT().ReplaceEntries(defaultSanctionsListName, gomock.Len(3), now).Return(nil)
+				paymentRepositoryMock.EXPECT().InvalidateAllVerdicts().Return(nil)
+				userRepositoryMock.EXPECT().ListUsers().Return(users, nil)
+				sanctionsRepositoryMock.EXPECT().GetActiveNames().Return(names, nil)
+				sanctionsRepositoryMock.EXPECT().CreateHits([]repository.ScreeningHit{
+					{UserID: 2, EntryID: 4, ScreenedName: "Виктор Кравченко", MatchedName: "Viktor KRAVCHENKO", Score: 1, Status: ScreeningHitPending, CreatedAt: now},
+				}).Return(1, nil)
+			},
+			assertFunc: func(t *testing.T, refresh SanctionsRefresh, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, SanctionsRefresh{Entries: 3, UsersScreened: 2, NewHits: 1}, refresh)
+			},
+		},
+		{
+			name:     "Failure - List file missing",
+			listPath: "../database/missing.xml",
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, refresh SanctionsRefresh, err error) {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			},
+		},
+		{
+			name: "Failure - Error loading the entries",
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, userRepositoryMock *mock.MockUserRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				sanctionsRepositoryMock.EXPECT().ReplaceEntries(defaultSanctionsListName, gomock.Any(), now).Return(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, refresh SanctionsRefresh, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, sanctionsRepositoryMock, userRepositoryMock, paymentRepositoryMock := newTestScreeningService(ctrl, now)
+			if tt.listPath != "" {
+				service.listPath = tt.listPath
+			}
+			tt.on(sanctionsRepositoryMock, userRepositoryMock, paymentRepositoryMock)
+
+			refresh, err := service.RefreshSanctions()
+			tt.assertFunc(t, refresh, err)
+		})
+	}
+}
+
+func TestScreenUser(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	names := []repository.SanctionsName{
+		{EntryID: 6, EntryName: "Mohammed Salim AL-HADDAD", Name: "Mohammed Salim AL-HADDAD"},
+		{EntryID: 6, EntryName: "Mohammed Salim AL-HADDAD", Name: "Muhamad HADAD"},
+		{EntryID: 7, EntryName: "Maria GARCIAS", Name: "Maria GARCIAS"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, sanctionsRepositoryMock, _, _ := newTestScreeningService(ctrl, now)
+	sanctionsRepositoryMock.EXPECT().GetActiveNames().Return(names, nil).Times(2)
+	sanctionsRepositoryMock.EXPECT().CreateHits(gomock.Any()).DoAndReturn(func(hits []repository.ScreeningHit) (int, error) {
+		// The alias scores better than the primary name, only the best hit of the entry is kept.
+		assert.Len(t, hits, 1)
+		assert.Equal(t, int64(6), hits[0].EntryID)
+		assert.Equal(t, "Muhamad HADAD", hits[0].MatchedName)
+		assert.Equal(t, "mohammed_haddad", hits[0].ScreenedName)
+		return 1, nil
+	})
+	sanctionsRepositoryMock.EXPECT().CreateHits(nil).Return(0, nil)
+
+	newHits, err := service.ScreenUser(repository.User{ID: 3, UserName: "mohammed_haddad", FullName: "M. Haddad"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, newHits)
+
+	newHits, err = service.ScreenUser(repository.User{ID: 4, UserName: "jane_smith", FullName: "Jane Smith"})
+	assert.NoError(t, err)
+	assert.Zero(t, newHits)
+}
+
+func TestReviewScreeningHit(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type input struct {
+		hitID    int64
+		status   string
+		reviewer string
+		note     string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(sanctionsRepositoryMock *mock.MockSanctionsRepository, paymentRepositoryMock *mock.MockPaymentRepository)
+		assertFunc func(t *testing.T, hit repository.ScreeningHit, err error)
+	}{
+		{
+			name:  "Success - Hit confirmed",
+			input: input{hitID: 8, status: ScreeningHitConfirmed, reviewer: " alice ", note: "same passport"},
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				sanctionsRepositoryMock.EXPECT().ReviewHit(int64(8), ScreeningHitConfirmed, "alice", "same passport", now).Return(nil)
+				sanctionsRepositoryMock.EXPECT().GetHit(int64(8)).Return(repository.ScreeningHit{ID: 8, UserID: 2, Status: ScreeningHitConfirmed, Reviewer: "alice"}, nil)
+				paymentRepositoryMock.EXPECT().InvalidateUserVerdicts([]int64{2}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, hit repository.ScreeningHit, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, ScreeningHitConfirmed, hit.Status)
+			},
+		},
+		{
+			name:  "Success - Hit dismissed",
+			input: input{hitID: 8, status: ScreeningHitDismissed, reviewer: "alice"},
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				sanctionsRepositoryMock.EXPECT().ReviewHit(int64(8), ScreeningHitDismissed, "alice", "", now).Return(nil)
+				sanctionsRepositoryMock.EXPECT().GetHit(int64(8)).Return(repository.ScreeningHit{ID: 8, UserID: 2, Status: ScreeningHitDismissed, Reviewer: "alice"}, nil)
+				paymentRepositoryMock.EXPECT().InvalidateUserVerdicts([]int64{2}).Return(errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, hit repository.ScreeningHit, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, ScreeningHitDismissed, hit.Status)
+			},
+		},
+		{
+			name:  "Failure - Hit cannot go back to pending",
+			input: input{hitID: 8, status: ScreeningHitPending, reviewer: "alice"},
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, hit repository.ScreeningHit, err error) {
+				assert.ErrorIs(t, err, ErrInvalidScreeningReview)
+			},
+		},
+		{
+			name:  "Failure - Reviewer required",
+			input: input{hitID: 8, status: ScreeningHitDismissed},
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+			},
+			assertFunc: func(t *testing.T, hit repository.ScreeningHit, err error) {
+				assert.ErrorIs(t, err, ErrInvalidScreeningReview)
+			},
+		},
+		{
+			name:  "Failure - Hit not found",
+			input: input{hitID: 9, status: ScreeningHitDismissed, reviewer: "alice"},
+			on: func(sanctionsRepositoryMock *mock.MockSanctionsRepository, paymentRepositoryMock *mock.MockPaymentRepository) {
+				sanctionsRepositoryMock.EXPECT().ReviewHit(int64(9), ScreeningHitDismissed, "alice", "", now).Return(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, hit repository.ScreeningHit, err error) {
+				assert.ErrorIs(t, err, ErrScreeningHitNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, sanctionsRepositoryMock, _, paymentRepositoryMock := newTestScreeningService(ctrl, now)
+			tt.on(sanctionsRepositoryMock, paymentRepositoryMock)
+
+			hit, err := service.ReviewHit(tt.input.hitID, tt.input.status, tt.input.reviewer, tt.input.note)
+			tt.assertFunc(t, hit, err)
+		})
+	}
+}
+
+func TestNewScreeningServiceConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	t.Setenv("SANCTIONS_LIST_PATH", path)
+	t.Setenv("SANCTIONS_MATCH_SCORE", "1.5")
+
+	service := NewScreeningService(nil, nil, nil).(*screeningService)
+
+	assert.Equal(t, path, service.listPath)
+	assert.Equal(t, defaultSanctionsListName, service.listName)
+	assert.Equal(t, defaultSanctionsMatchScore, service.matchScore)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/logging"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minSecretCodeLength = 8
+	maxFullNameLength   = 256
+)
+
+var (
+	ErrUserExists  = errors.New("user already exists")
+	ErrInvalidUser = errors.New("invalid user")
+
+	userNamePattern = regexp.MustCompile(`^[a-z0-9_.-]{3,64}$`)
+)
+
+type CreatedUser struct {
+	repository.User
+	ScreeningHits int    `json:"screening_hits"`
+	RiskRating    string `json:"risk_rating,omitempty"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source user_service.go -destination mock/user_service_mock.go -package mock
+type UserService interface {
+	CreateUser(userName string, fullName string, secretCode string) (CreatedUser, error)
+}
+
+type userService struct {
+	userRepository   repository.UserRepository
+	screeningService ScreeningService
+	pepService       PEPService
+}
+
+func NewUserService(userRepository repository.UserRepository, screeningService ScreeningService, pepService PEPService) UserService {
+	return &userService{
+		userRepository:   userRepository,
+		screeningService: screeningService,
+		pepService:       pepService,
+	}
+}
+
+// CreateUser registers the user, screens it against the sanctions lists and rates it against the PEP list. A
+// screening error does not undo the registration: the next refresh of the lists screens every user again.
+func (s *userService) CreateUser(userName string, fullName string, secretCode string) (CreatedUser, error) {
+	userName, fullName = strings.TrimSpace(userName), strings.TrimSpace(fullName)
+	if !userNamePattern.MatchString(userName) {
+		return CreatedUser{}, fmt.Errorf("%w: user name must be 3 to 64 lowercase letters, digits, '.', '_' or '-'", ErrInvalidUser)
+	}
+	if fullName == "" || utf8.RuneCountInString(fullName) > maxFullNameLength {
+		return CreatedUser{}, fmt.Errorf("%w: full name must be between 1 and %d characters", ErrInvalidUser, maxFullNameLength)
+	}
+	if len(secretCode) < minSecretCodeLength {
+		return CreatedUser{}, fmt.Errorf("%w: secret code must be at least %d characters", ErrInvalidUser, minSecretCodeLength)
+	}
+
+	hashedSecret, err := bcrypt.GenerateFromPassword([]byte(secretCode), bcrypt.DefaultCost)
+	if err != nil {
+		return CreatedUser{}, fmt.Errorf("%w: %s", ErrInvalidUser, err)
+	}
+
+	userID, err := s.userRepository.CreateUser(userName, fullName, string(hashedSecret))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "UNIQUE constraint failed") {
+			return CreatedUser{}, ErrUserExists
+		}
+		return CreatedUser{}, err
+	}
+
+	created := CreatedUser{User: repository.User{ID: userID, UserName: userName, FullName: fullName}}
+	created.ScreeningHits, err = s.screeningService.ScreenUser(created.User)
+	if err != nil {
+		slog.Error("error screening user against the sanctions lists", logging.UserID(userID), logging.Err(err))
+	}
+	created.RiskRating, err = s.pepService.ScreenUser(created.User)
+	if err != nil {
+		slog.Error("error screening user against the PEP list", logging.UserID(userID), logging.Err(err))
+	}
+
+	return created, nil
+}
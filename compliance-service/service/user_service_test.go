@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"flarrocca/compliant-service/repository"
+	"flarrocca/compliant-service/repository/mock"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// stubScreeningService stands in for the screening service, the service mocks cannot be imported from this package.
+type stubScreeningService struct {
+	ScreeningService
+	screened []repository.User
+	hits     int
+	err      error
+}
+
+func (s *stubScreeningService) ScreenUser(user repository.User) (int, error) {
+	s.screened = append(s.screened, user)
+	return s.hits, s.err
+}
+
+// stubPEPService stands in for the PEP service and rates every user the same.
+type stubPEPService struct {
+	PEPService
+	rating string
+}
+
+func (s *stubPEPService) ScreenUser(user repository.User) (string, error) {
+	return s.rating, nil
+}
+
+func TestCreateUser(t *testing.T) {
+	type input struct {
+		userName   string
+		fullName   string
+		secretCode string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		screening  *stubScreeningService
+		on         func(userRepositoryMock *mock.MockUserRepository)
+		assertFunc func(t *testing.T, user CreatedUser, err error, screening *stubScreeningService)
+	}{
+		{
+			name:      "Success - User created, screened and rated",
+			input:     input{userName: "maria_garcia", fullName: " María García ", secretCode: "secret-123"},
+			screening: &stubScreeningService{hits: 1},
+			on: func(userRepositoryMock *mock.MockUserRepository) {
+				userRepositoryMock.EXPECT().CreateUser("maria_garcia", "María García", gomock.Any()).DoAndReturn(func(userName, fullName, hashedSecret string) (int64, error) {
+					assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hashedSecret), []byte("secret-123")))
+					return 3, nil
+				})
+			},
+			assertFunc: func(t *testing.T, user CreatedUser, err error, screening *stubScreeningService) {
+				assert.NoError(t, err)
+				assert.Equal(t, CreatedUser{User: repository.User{ID: 3, UserName: "maria_garcia", FullName: "María García"}, ScreeningHits: 1, RiskRating: RiskRatingLow}, user)
+				assert.Equal(t, []repository.User{user.User}, screening.screened)
+			},
+		},
+		{
+			name:      "Success - Screening error does not fail the creation",
+			input:     input{userName: "maria_garcia", fullName: "María García", secretCode: "secret-123"},
+			screening: &stubScreeningService{err: errors.New("database error")},
+			on: func(userRepositoryMock *mock.MockUserRepository) {
+				userRepositoryMock.EXPECT().CreateUser("maria_garcia", "María García", gomock.Any()).Return(int64(3), nil)
+			},
+			assertFunc: func(t *testing.T, user CreatedUser, err error, screening *stubScreeningService) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(3), user.ID)
+			},
+		},
+		{
+			name:      "Failure - User name taken",
+			input:     input{userName: "john_doe", fullName: "John Doe", secretCode: "secret-123"},
+			screening: &stubScreeningService{},
+			on: func(userRepositoryMock *mock.MockUserRepository) {
+				userRepositoryMock.EXPECT().CreateUser("john_doe", "John Doe", gomock.Any()).Return(int64(0), errors.New("UNIQUE constraint failed: users.user_name"))
+			},
+			assertFunc: func(t *testing.T, user CreatedUser, err error, screening *stubScreeningService) {
+				assert.ErrorIs(t, err, ErrUserExists)
+				assert.Empty(t, screening.screened)
+			},
+		},
+		{
+			name:      "Failure - Invalid user name",
+			input:     input{userName: "John Doe", fullName: "John Doe", secretCode: "secret-123"},
+			screening: &stubScreeningService{},
+			on:        func(userRepositoryMock *mock.MockUserRepository) {},
+			assertFunc: func(t *testing.T, user CreatedUser, err error, screening *stubScreeningService) {
+				assert.ErrorIs(t, err, ErrInvalidUser)
+			},
+		},
+		{
+			name:      "Failure - Full name required",
+			input:     input{userName: "john_doe", fullName: " ", secretCode: "secret-123"},
+			screening: &stubScreeningService{},
+			on:        func(userRepositoryMock *mock.MockUserRepository) {},
+			assertFunc: func(t *testing.T, user CreatedUser, err error, screening *stubScreeningService) {
+				assert.ErrorIs(t, err, ErrInvalidUser)
+			},
+		},
+		{
+			name:      "Failure - Secret code too short",
+			input:     input{userName: "john_doe", fullName: "John Doe", secretCode: "1234"},
+			screening: &stubScreeningService{},
+			on:        func(userRepositoryMock *mock.MockUserRepository) {},
+			assertFunc: func(t *testing.T, user CreatedUser, err error, screening *stubScreeningService) {
+				assert.ErrorIs(t, err, ErrInvalidUser)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepositoryMock := mock.NewMockUserRepository(ctrl)
+			tt.on(userRepositoryMock)
+
+			service := &userService{userRepository: userRepositoryMock, screeningService: tt.screening, pepService: &stubPEPService{rating: RiskRatingLow}}
+			user, err := service.CreateUser(tt.input.userName, tt.input.fullName, tt.input.secretCode)
+			tt.assertFunc(t, user, err, tt.screening)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// MockServer adds the interaction to the pact and returns the URL of a server answering its request with its
+// response, for the client of the consumer to be tested against. A request other than the recorded one fails the test.
+func (p *Pact) MockServer(t testing.TB, interaction Interaction) string {
+	t.Helper()
+	p.Interactions = append(p.Interactions, interaction)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mismatch := requestMismatch(interaction.Request, r); mismatch != "" {
+			t.Errorf("%s: %s", interaction.Description, mismatch)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		for name, value := range interaction.Response.Headers {
+			w.Header().Set(name, value)
+		}
+		if len(interaction.Response.Body) > 0 && w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(interaction.Response.Status)
+		w.Write(interaction.Response.Body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// requestMismatch tells how the request differs from the recorded one, or returns an empty string. The query
+// parameters may come in any order, and the bodies are compared as JSON.
+func requestMismatch(expected Request, r *http.Request) string {
+	if r.Method != expected.Method || r.URL.Path != expected.Path {
+		return fmt.Sprintf("unexpected request %s %s, want %s %s", r.Method, r.URL.Path, expected.Method, expected.Path)
+	}
+
+	expectedQuery, err := url.ParseQuery(expected.Query)
+	if err != nil {
+		return fmt.Sprintf("invalid recorded query: %v", err)
+	}
+	if !reflect.DeepEqual(expectedQuery, r.URL.Query()) {
+		return fmt.Sprintf("unexpected query %s, want %s", r.URL.RawQuery, expected.Query)
+	}
+
+	for name, value := range expected.Headers {
+		if r.Header.Get(name) != value {
+			return fmt.Sprintf("unexpected %s header %s, want %s", name, r.Header.Get(name), value)
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Sprintf("error reading body: %v", err)
+	}
+	if len(expected.Body) == 0 {
+		return ""
+	}
+	if !jsonEqual(expected.Body, body) {
+		return fmt.Sprintf("unexpected body %s, want %s", bytes.TrimSpace(body), expected.Body)
+	}
+
+	return ""
+}
+
+func jsonEqual(expected []byte, actual []byte) bool {
+	var expectedValue, actualValue any
+	if json.Unmarshal(expected, &expectedValue) != nil || json.Unmarshal(actual, &actualValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(expectedValue, actualValue)
+}
@@ -0,0 +1,106 @@
+// Package contract holds the consumer-driven contract tests of the HTTP APIs between the services. The consumer
+// records the requests it sends and the responses it relies on as a pact-style JSON file, while testing its client
+// against a mock server answering them. The provider replays the pact against its real handlers, so a field renamed
+// on either side fails one of the two test suites.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir is where the pacts are written by the consumers and read by the providers, from the root of the repository.
+const Dir = "pacts"
+
+// MatchType is the matching rule of a value only required to have the type of the recorded one, e.g. a message the
+// consumer shows as is.
+const MatchType = "type"
+
+// Pact is the contract of a consumer with a provider, in the format of the Pact specification v2.
+type Pact struct {
+	Consumer     Pacticipant    `json:"consumer"`
+	Provider     Pacticipant    `json:"provider"`
+	Interactions []Interaction  `json:"interactions"`
+	Metadata     map[string]any `json:"metadata"`
+}
+
+type Pacticipant struct {
+	Name string `json:"name"`
+}
+
+// Interaction is a request of the consumer and the response it relies on. ProviderState is the data the provider
+// must hold for the response to be given, e.g. "user 1 owns card 1".
+type Interaction struct {
+	Description   string   `json:"description"`
+	ProviderState string   `json:"providerState,omitempty"`
+	Request       Request  `json:"request"`
+	Response      Response `json:"response"`
+}
+
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Response is the part of the response the consumer relies on: the fields of Body the provider adds are ignored,
+// and MatchingRules, keyed on the JSON path of the value, e.g. $.body.message, loosen the match of a value.
+type Response struct {
+	Status        int               `json:"status"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Body          json.RawMessage   `json:"body,omitempty"`
+	MatchingRules map[string]Rule   `json:"matchingRules,omitempty"`
+}
+
+type Rule struct {
+	Match string `json:"match"`
+}
+
+// NewPact returns an empty pact between the consumer and the provider.
+func NewPact(consumer string, provider string) *Pact {
+	return &Pact{
+		Consumer: Pacticipant{Name: consumer},
+		Provider: Pacticipant{Name: provider},
+		Metadata: map[string]any{"pactSpecification": map[string]string{"version": "2.0.0"}},
+	}
+}
+
+// FileName is the name of the pact file of the consumer with the provider.
+func FileName(consumer string, provider string) string {
+	return fmt.Sprintf("%s-%s.json", consumer, provider)
+}
+
+// Write writes the pact to dir, replacing the previous version.
+func (p *Pact) Write(dir string) error {
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(p); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, FileName(p.Consumer.Name, p.Provider.Name)), content.Bytes(), 0o644)
+}
+
+// Read reads the pact of the consumer with the provider from dir.
+func Read(dir string, consumer string, provider string) (Pact, error) {
+	content, err := os.ReadFile(filepath.Join(dir, FileName(consumer, provider)))
+	if err != nil {
+		return Pact{}, err
+	}
+
+	var pact Pact
+	if err := json.Unmarshal(content, &pact); err != nil {
+		return Pact{}, fmt.Errorf("error decoding pact: %w", err)
+	}
+	return pact, nil
+}
@@ -0,0 +1,92 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueMismatches(t *testing.T) {
+	rules := map[string]Rule{"$.body.message": {Match: MatchType}}
+
+	tests := []struct {
+		name           string
+		expected       string
+		actual         string
+		wantMismatches []string
+	}{
+		{
+			name:     "Success - Extra fields ignored",
+			expected: `{"compliant": true, "risk_rating": "low"}`,
+			actual:   `{"compliant": true, "message": "user is compliance", "risk_rating": "low", "matched_entries": []}`,
+		},
+		{
+			name:     "Success - Type rule",
+			expected: `{"message": "user is compliance"}`,
+			actual:   `{"message": "payment amount 750.00 of a high-risk user requires manual review"}`,
+		},
+		{
+			name:           "Fail - Renamed field",
+			expected:       `{"compliant": true}`,
+			actual:         `{"complaiance": true}`,
+			wantMismatches: []string{"$.body.compliant is missing"},
+		},
+		{
+			name:           "Fail - Different value",
+			expected:       `{"results": [{"compliant": true}, {"compliant": false}]}`,
+			actual:         `{"results": [{"compliant": true}, {"compliant": true}]}`,
+			wantMismatches: []string{"$.body.results[1].compliant is true, want false"},
+		},
+		{
+			name:           "Fail - Type rule with another type",
+			expected:       `{"message": "user is compliance"}`,
+			actual:         `{"message": null}`,
+			wantMismatches: []string{"$.body.message is null, want a string"},
+		},
+		{
+			name:           "Fail - Missing items",
+			expected:       `{"blocked_cards": [{"user_id": 1, "card_id": 2}]}`,
+			actual:         `{"blocked_cards": []}`,
+			wantMismatches: []string{"$.body.blocked_cards has 0 items, want 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected, actual any
+			assert.NoError(t, json.Unmarshal([]byte(tt.expected), &expected))
+			assert.NoError(t, json.Unmarshal([]byte(tt.actual), &actual))
+
+			assert.Equal(t, tt.wantMismatches, valueMismatches("$.body", expected, actual, rules))
+		})
+	}
+}
+
+func TestMockServer(t *testing.T) {
+	pact := NewPact("payment-service", "compliance-service")
+	baseURL := pact.MockServer(t, Interaction{
+		Description:   "a batch compliance check",
+		ProviderState: "user 1 owns card 1",
+		Request: Request{
+			Method: http.MethodPost,
+			Path:   "/v1/check_users",
+			Body:   json.RawMessage(`{"checks": [{"user_id": 1, "card_id": 1}]}`),
+		},
+		Response: Response{Status: http.StatusOK, Body: json.RawMessage(`{"results": [{"compliant": true}]}`)},
+	})
+
+	resp, err := http.Post(baseURL+"/v1/check_users", "application/json", strings.NewReader(`{"checks":[{"card_id":1,"user_id":1}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	dir := t.TempDir()
+	assert.NoError(t, pact.Write(dir))
+	written, err := Read(dir, "payment-service", "compliance-service")
+	assert.NoError(t, err)
+	assert.Len(t, written.Interactions, 1)
+	assert.Equal(t, "user 1 owns card 1", written.Interactions[0].ProviderState)
+}
@@ -0,0 +1,149 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Verify replays the interactions of the pact against the provider, each in a subtest. setUp puts the provider in
+// the state of the interaction, from a fresh set of data, and returns the function sending the requests to it.
+func Verify(t *testing.T, pact Pact, setUp func(t *testing.T, state string) func(*http.Request) (*http.Response, error)) {
+	if len(pact.Interactions) == 0 {
+		t.Fatalf("the pact of %s with %s has no interactions", pact.Consumer.Name, pact.Provider.Name)
+	}
+
+	for _, interaction := range pact.Interactions {
+		t.Run(interaction.Description, func(t *testing.T) {
+			send := setUp(t, interaction.ProviderState)
+
+			target := interaction.Request.Path
+			if interaction.Request.Query != "" {
+				target += "?" + interaction.Request.Query
+			}
+			req := httptest.NewRequest(interaction.Request.Method, target, bytes.NewReader(interaction.Request.Body))
+			for name, value := range interaction.Request.Headers {
+				req.Header.Set(name, value)
+			}
+			if len(interaction.Request.Body) > 0 && req.Header.Get("Content-Type") == "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			resp, err := send(req)
+			if err != nil {
+				t.Fatalf("error sending %s %s: %v", req.Method, target, err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("error reading response: %v", err)
+			}
+
+			for _, mismatch := range responseMismatches(interaction.Response, resp, body) {
+				t.Errorf("%s (given %q): %s", interaction.Description, interaction.ProviderState, mismatch)
+			}
+		})
+	}
+}
+
+// responseMismatches tells how the response differs from the recorded one.
+func responseMismatches(expected Response, resp *http.Response, body []byte) []string {
+	var mismatches []string
+	if resp.StatusCode != expected.Status {
+		mismatches = append(mismatches, fmt.Sprintf("status %d, want %d: %s", resp.StatusCode, expected.Status, body))
+	}
+	for name, value := range expected.Headers {
+		if !strings.HasPrefix(resp.Header.Get(name), value) {
+			mismatches = append(mismatches, fmt.Sprintf("%s header %q, want %q", name, resp.Header.Get(name), value))
+		}
+	}
+	if len(expected.Body) == 0 {
+		return mismatches
+	}
+
+	var expectedValue, actualValue any
+	if err := json.Unmarshal(expected.Body, &expectedValue); err != nil {
+		return append(mismatches, fmt.Sprintf("invalid recorded body: %v", err))
+	}
+	if err := json.Unmarshal(body, &actualValue); err != nil {
+		return append(mismatches, fmt.Sprintf("body is not JSON: %s", body))
+	}
+
+	return append(mismatches, valueMismatches("$.body", expectedValue, actualValue, expected.MatchingRules)...)
+}
+
+// valueMismatches matches the actual value against the expected one: objects must hold the expected fields, the
+// others being ignored, arrays the expected items, and the other values must be equal, or only of the same type
+// when a type rule is set at their path.
+func valueMismatches(path string, expected any, actual any, rules map[string]Rule) []string {
+	switch expected := expected.(type) {
+	case map[string]any:
+		actual, ok := actual.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s is %s, want an object", path, describe(actual))}
+		}
+		keys := make([]string, 0, len(expected))
+		for key := range expected {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var mismatches []string
+		for _, key := range keys {
+			value, found := actual[key]
+			if !found {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s is missing", path, key))
+				continue
+			}
+			mismatches = append(mismatches, valueMismatches(path+"."+key, expected[key], value, rules)...)
+		}
+		return mismatches
+	case []any:
+		actual, ok := actual.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s is %s, want an array", path, describe(actual))}
+		}
+		if len(actual) != len(expected) {
+			return []string{fmt.Sprintf("%s has %d items, want %d", path, len(actual), len(expected))}
+		}
+
+		var mismatches []string
+		for i := range expected {
+			mismatches = append(mismatches, valueMismatches(fmt.Sprintf("%s[%d]", path, i), expected[i], actual[i], rules)...)
+		}
+		return mismatches
+	}
+
+	if rule, found := rules[path]; found && rule.Match == MatchType {
+		if describe(expected) != describe(actual) {
+			return []string{fmt.Sprintf("%s is %s, want %s", path, describe(actual), describe(expected))}
+		}
+		return nil
+	}
+	if expected != actual {
+		return []string{fmt.Sprintf("%s is %v, want %v", path, actual, expected)}
+	}
+	return nil
+}
+
+func describe(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case []any:
+		return "an array"
+	}
+	return "an object"
+}
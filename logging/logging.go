@@ -0,0 +1,95 @@
+// Package logging sets up the structured JSON logs of the services with log/slog. Every record carries the service
+// and, when logged with the context of a request, its request ID, propagated from one service to the other with the
+// X-Request-ID header, so a payment can be followed to the compliance check it caused. Card numbers, secrets and
+// tokens are redacted from every record, whatever logged it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// HeaderRequestID carries the request ID from one service to the other.
+const HeaderRequestID = "X-Request-ID"
+
+// Keys of the fields shared by the records of both services.
+const (
+	KeyService       = "service"
+	KeyRequestID     = "request_id"
+	KeyUserID        = "user_id"
+	KeyCardID        = "card_id"
+	KeyCardToken     = "card_token"
+	KeyTransactionID = "transaction_id"
+	KeyDecision      = "decision"
+	KeyError         = "error"
+)
+
+type requestIDKey struct{}
+
+// New returns a logger writing JSON records to w, from the level set by LOG_LEVEL (debug, info, warn or error,
+// info by default).
+func New(service string, w io.Writer) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: levelFromEnv()})
+	return slog.New(newRedactingHandler(handler)).With(KeyService, service)
+}
+
+// Setup makes the logger of the service the default one, also used by the log package, e.g. in the shared modules.
+func Setup(service string) *slog.Logger {
+	logger := New(service, os.Stdout)
+	slog.SetDefault(logger)
+	return logger
+}
+
+func levelFromEnv() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(os.Getenv("LOG_LEVEL")))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// Fatal logs the error and exits, as log.Fatal does.
+func Fatal(msg string, err error) {
+	slog.Error(msg, Err(err))
+	os.Exit(1)
+}
+
+// WithRequestID returns a context carrying the request ID, added to the records logged with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by the context, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+func UserID(userID int64) slog.Attr {
+	return slog.Int64(KeyUserID, userID)
+}
+
+func CardID(cardID int64) slog.Attr {
+	return slog.Int64(KeyCardID, cardID)
+}
+
+// CardToken is the fingerprint of a card, logged instead of its number.
+func CardToken(cardFingerprint string) slog.Attr {
+	return slog.String(KeyCardToken, cardFingerprint)
+}
+
+func TransactionID(transactionID string) slog.Attr {
+	return slog.String(KeyTransactionID, transactionID)
+}
+
+// Decision is the outcome of a payment or a compliance check, e.g. approved or declined.
+func Decision(decision string) slog.Attr {
+	return slog.String(KeyDecision, decision)
+}
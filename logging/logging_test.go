@@ -0,0 +1,157 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flarrocca/logging"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "Success - Card number",
+			text: "error reporting card 4111111111111111",
+			want: "error reporting card ************1111",
+		},
+		{
+			name: "Success - Grouped card number",
+			text: "card 1234-5678-9012-3456 is blocked",
+			want: "card ************3456 is blocked",
+		},
+		{
+			name: "Success - Bearer token",
+			text: "Authorization: Bearer abc.def-ghi",
+			want: "Authorization: Bearer [REDACTED]",
+		},
+		{
+			name: "Success - JWT",
+			text: "invalid token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhbGljZSJ9.sig",
+			want: "invalid token [REDACTED]",
+		},
+		{
+			name: "Success - Secret in a query",
+			text: "POST /report_cards?user_name=john_doe&secret_code=hashed_secret_123",
+			want: "POST /report_cards?user_name=john_doe&secret_code=[REDACTED]",
+		},
+		{
+			name: "Success - IDs kept",
+			text: "transaction txn_1890758 of user 1 and card 2",
+			want: "transaction txn_1890758 of user 1 and card 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, logging.Redact(tt.text))
+		})
+	}
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]any
+		assert.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New("payment-service", &buf)
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	logger.InfoContext(ctx, "payment processed",
+		logging.UserID(1), logging.CardID(2), logging.TransactionID("txn_1"), logging.Decision("declined"),
+		slog.String("secret_code", "hashed_secret_123"),
+		slog.String("reason", "card 1234 5678 9012 3456 is reported"),
+		logging.Err(errors.New("error calling compliance-service with Bearer abc")),
+	)
+
+	records := decodeRecords(t, &buf)
+	assert.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "payment processed", record["msg"])
+	assert.Equal(t, "payment-service", record[logging.KeyService])
+	assert.Equal(t, "req-1", record[logging.KeyRequestID])
+	assert.Equal(t, float64(1), record[logging.KeyUserID])
+	assert.Equal(t, "txn_1", record[logging.KeyTransactionID])
+	assert.Equal(t, "declined", record[logging.KeyDecision])
+	assert.Equal(t, logging.Redacted, record["secret_code"])
+	assert.Equal(t, "card ************3456 is reported", record["reason"])
+	assert.Equal(t, "error calling compliance-service with Bearer [REDACTED]", record[logging.KeyError])
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New("compliance-service", &buf))
+	defer slog.SetDefault(previous)
+
+	app := fiber.New()
+	app.Use(requestid.New(), logging.Middleware())
+	app.Get("/v1/check_user", func(c *fiber.Ctx) error {
+		return c.SendString(logging.RequestID(c.UserContext()))
+	})
+	app.Get("/v1/cases/:id", func(c *fiber.Ctx) error {
+		return fiber.NewError(http.StatusNotFound, "case not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/check_user", nil)
+	req.Header.Set(logging.HeaderRequestID, "req-from-payment")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-from-payment", resp.Header.Get(logging.HeaderRequestID))
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/cases/7", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	records := decodeRecords(t, &buf)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "request", records[0]["msg"])
+	assert.Equal(t, "req-from-payment", records[0][logging.KeyRequestID])
+	assert.Equal(t, float64(http.StatusOK), records[0]["status"])
+	assert.Equal(t, "/v1/cases/7", records[1]["path"])
+	assert.Equal(t, float64(http.StatusNotFound), records[1]["status"])
+	assert.NotEmpty(t, records[1][logging.KeyRequestID])
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	assert.NoError(t, logging.UnaryClientInterceptor()(ctx, "/compliance.v1.ComplianceService/CheckCompliance", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"req-1"}, sent.Get("x-request-id"))
+
+	var received string
+	handler := func(ctx context.Context, req any) (any, error) {
+		received = logging.RequestID(ctx)
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/compliance.v1.ComplianceService/CheckCompliance"}
+	_, err := logging.UnaryServerInterceptor()(metadata.NewIncomingContext(context.Background(), sent), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", received)
+}
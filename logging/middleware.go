@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataRequestID is the gRPC metadata key of the request ID, the lower case of HeaderRequestID.
+const metadataRequestID = "x-request-id"
+
+// Middleware puts the request ID, set on the response by the requestid middleware running before it, in the user
+// context of the request for the handlers to pass on, and logs the request once answered. The errors of the handlers
+// are answered by the error handler of the app, for their status to be logged.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		method, path := c.Method(), c.Path()
+		ctx := WithRequestID(c.UserContext(), c.GetRespHeader(fiber.HeaderXRequestID))
+		c.SetUserContext(ctx)
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "request",
+			slog.String("method", method),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+		)
+		return nil
+	}
+}
+
+// UnaryClientInterceptor sends the request ID of the context with the calls.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestID := RequestID(ctx); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataRequestID, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerInterceptor puts the request ID sent by the client, or a new one, in the context of the call, and logs
+// the call once answered.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		requestID := ""
+		if md, found := metadata.FromIncomingContext(ctx); found && len(md.Get(metadataRequestID)) > 0 {
+			requestID = md.Get(metadataRequestID)[0]
+		}
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		ctx = WithRequestID(ctx, requestID)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID))
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss:
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "rpc",
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+		)
+		return resp, err
+	}
+}
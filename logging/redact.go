@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the values of the sensitive fields.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the fields whose values are never logged.
+var sensitiveKeys = map[string]bool{
+	"authorization":      true,
+	"card_number":        true,
+	"cvv":                true,
+	"id_document_number": true,
+	"pan":                true,
+	"password":           true,
+	"secret":             true,
+	"secret_code":        true,
+	"token":              true,
+}
+
+var (
+	// panPattern matches the card numbers, 13 to 19 digits optionally grouped with spaces or dashes.
+	panPattern    = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	secretPattern = regexp.MustCompile(`(?i)\b(secret_code|secret|password|token)=[^&\s]+`)
+)
+
+// Redact masks the card numbers, but for their last 4 digits, and removes the tokens and secrets from the text.
+func Redact(text string) string {
+	text = panPattern.ReplaceAllStringFunc(text, func(pan string) string {
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, pan)
+		return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+	})
+	text = bearerPattern.ReplaceAllString(text, "Bearer "+Redacted)
+	text = jwtPattern.ReplaceAllString(text, Redacted)
+	return secretPattern.ReplaceAllString(text, "$1="+Redacted)
+}
+
+// redactingHandler redacts the message and the fields of the records before handing them to the JSON handler, and
+// adds the request ID of their context.
+type redactingHandler struct {
+	slog.Handler
+}
+
+func newRedactingHandler(handler slog.Handler) slog.Handler {
+	return &redactingHandler{Handler: handler}
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	if requestID := RequestID(ctx); requestID != "" {
+		redacted.AddAttrs(slog.String(KeyRequestID, requestID))
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		redacted = append(redacted, redactAttr(attr))
+	}
+	return &redactingHandler{Handler: h.Handler.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// redactAttr redacts the strings, errors and fmt.Stringer values of the field. The other values, e.g. structs, are
+// logged as they are, so they must not hold card numbers or secrets.
+func redactAttr(attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, 0, len(group))
+		for _, groupAttr := range group {
+			redacted = append(redacted, redactAttr(groupAttr))
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, Redact(v.Error()))
+		case fmt.Stringer:
+			return slog.String(attr.Key, Redact(v.String()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,89 @@
+// Package metrics exposes the Prometheus metrics of the services on /metrics: the rate, errors and duration of the
+// requests of every route, the connection pool of the database, the Go runtime and the process, next to the business
+// metrics each service registers with the default registry. The labels only take bounded values, the route patterns
+// rather than the paths, so that no user or card ID ends up in a label.
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path serves the metrics, outside of the versioned API.
+const Path = "/metrics"
+
+// RouteUnmatched labels the requests no route matched, whatever their path.
+const RouteUnmatched = "unmatched"
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests answered, by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of the HTTP requests, by method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Middleware counts and times the requests by the pattern of the route that answered them, e.g. /v1/cases/:id, so
+// the paths holding IDs do not add labels. The deprecated aliases are counted with their /v1 successor. The errors of
+// the handlers are answered by the error handler of the app, for their status to be counted.
+func Middleware() fiber.Handler {
+	var once sync.Once
+	var routes map[string]bool
+
+	return func(c *fiber.Ctx) error {
+		// The routes are all registered by the time the first request is answered.
+		once.Do(func() {
+			routes = make(map[string]bool)
+			for _, route := range c.App().GetRoutes(true) {
+				routes[route.Method+" "+route.Path] = true
+			}
+		})
+
+		start := time.Now()
+		// The method is kept as a label value, it must not share the buffer fasthttp reuses for the next request.
+		method := utils.CopyString(c.Method())
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		route := c.Route()
+		label := route.Path
+		// Without a matching route the request ends on the last middleware it went through.
+		if status == fiber.StatusNotFound && !routes[route.Method+" "+route.Path] {
+			label = RouteUnmatched
+		}
+
+		requestsTotal.WithLabelValues(method, label, strconv.Itoa(status)).Inc()
+		requestDuration.WithLabelValues(method, label).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
+
+// Handler serves the metrics of the default registry in the Prometheus text format.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
+
+// RegisterDB exposes the statistics of the connection pool of the database, e.g. go_sql_open_connections, labelled
+// with its name.
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,79 @@
+package metrics_test
+
+import (
+	"database/sql"
+	"flarrocca/metrics"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, app *fiber.App) string {
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(metrics.Middleware())
+	app.Get(metrics.Path, metrics.Handler())
+	v1 := app.Group("/v1")
+	v1.Get("/cases/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "404" {
+			return fiber.NewError(http.StatusNotFound, "case not found")
+		}
+		return c.SendString("case")
+	})
+	v1.Post("/cases", func(c *fiber.Ctx) error {
+		return fiber.NewError(http.StatusInternalServerError, "error opening case")
+	})
+
+	requests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{method: http.MethodGet, path: "/v1/cases/1", status: http.StatusOK},
+		{method: http.MethodGet, path: "/v1/cases/2", status: http.StatusOK},
+		{method: http.MethodGet, path: "/v1/cases/404", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/v1/cases", status: http.StatusInternalServerError},
+		{method: http.MethodGet, path: "/v1/users/42", status: http.StatusNotFound},
+	}
+	for _, req := range requests {
+		resp, err := app.Test(httptest.NewRequest(req.method, req.path, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, req.status, resp.StatusCode)
+	}
+
+	body := scrape(t, app)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/v1/cases/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/v1/cases/:id",status="404"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="POST",route="/v1/cases",status="500"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/v1/cases/:id"} 3`)
+	assert.NotContains(t, body, "/v1/cases/1")
+	assert.NotContains(t, body, "/v1/users/42")
+}
+
+func TestRegisterDB(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	metrics.RegisterDB(db, "test")
+	app := fiber.New()
+	app.Get(metrics.Path, metrics.Handler())
+
+	body := scrape(t, app)
+	assert.Contains(t, body, `go_sql_max_open_connections{db_name="test"} 0`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="test"}`)
+}
@@ -0,0 +1,125 @@
+// Package migrate upgrades the SQLite database of a service to the schema of its database/init.sql. init.sql only
+// creates the tables missing from the database, so the columns and constraints later added to an existing table are
+// added by the migrations of the service, run before init.sql. The migrations applied are recorded in the
+// schema_version table, and each one is applied once, in its own transaction.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Migration upgrades the schema from the previous version to Version.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *sql.Tx) error
+}
+
+// Run applies the migrations newer than the version of the database, in order. On a new database the tables are not
+// created yet: the migrations leave them to init.sql and are only recorded. Foreign keys are not enforced while the
+// migrations run, for a table to be rebuilt without cascading the deletion of its rows.
+func Run(db *sql.DB, migrations []Migration) error {
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			return fmt.Errorf("migration %d is listed after migration %d", migrations[i].Version, migrations[i-1].Version)
+		}
+	}
+
+	ctx := context.Background()
+	// The pragma only applies to the connection it is run on.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("error creating schema_version: %w", err)
+	}
+
+	var current int
+	if err := conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&current); err != nil {
+		return fmt.Errorf("error reading the schema version: %w", err)
+	}
+
+	var foreignKeys bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return err
+	}
+	if foreignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+		if err := apply(ctx, conn, migration); err != nil {
+			return fmt.Errorf("error applying migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := migration.Up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_version (version, description) VALUES (?, ?)",
+		migration.Version, migration.Description); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Version returns the version of the last migration applied to the database, 0 when none was.
+func Version(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	return version, err
+}
+
+// AddColumn adds the column to the table. The migration does nothing when the table is missing, init.sql creating it
+// with the column, or when the column is already there, the database having been created by an init.sql that had it.
+func AddColumn(table, column, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		exists, err := TableExists(tx, table)
+		if err != nil || !exists {
+			return err
+		}
+		exists, err = columnExists(tx, table, column)
+		if err != nil || exists {
+			return err
+		}
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+		return err
+	}
+}
+
+// TableExists reports whether the table was created.
+func TableExists(tx *sql.Tx, table string) (bool, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	return count > 0, err
+}
+
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	return count > 0, err
+}
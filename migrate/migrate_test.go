@@ -0,0 +1,139 @@
+package migrate_test
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/migrate"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func columns(t *testing.T, db *sql.DB, table string) []string {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestRun(t *testing.T) {
+	migrations := []migrate.Migration{
+		{Version: 1, Description: "add users.full_name", Up: migrate.AddColumn("users", "full_name", "TEXT NOT NULL DEFAULT ''")},
+		{Version: 2, Description: "add users.email", Up: migrate.AddColumn("users", "email", "TEXT")},
+	}
+
+	tests := []struct {
+		name       string
+		schema     string
+		migrations []migrate.Migration
+		assertFunc func(t *testing.T, db *sql.DB, err error)
+	}{
+		{
+			name:       "Success - Columns added to an existing table",
+			schema:     "CREATE TABLE users (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL); INSERT INTO users (user_name) VALUES ('john_doe')",
+			migrations: migrations,
+			assertFunc: func(t *testing.T, db *sql.DB, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"id", "user_name", "full_name", "email"}, columns(t, db, "users"))
+				var fullName string
+				assert.NoError(t, db.QueryRow("SELECT full_name FROM users").Scan(&fullName))
+				assert.Empty(t, fullName)
+			},
+		},
+		{
+			name:       "Success - Column created by an older init.sql skipped",
+			schema:     "CREATE TABLE users (id INTEGER PRIMARY KEY, full_name TEXT NOT NULL DEFAULT '')",
+			migrations: migrations,
+			assertFunc: func(t *testing.T, db *sql.DB, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"id", "full_name", "email"}, columns(t, db, "users"))
+			},
+		},
+		{
+			name:       "Success - Migrations only recorded on a new database",
+			migrations: migrations,
+			assertFunc: func(t *testing.T, db *sql.DB, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, columns(t, db, "users"))
+			},
+		},
+		{
+			name:   "Failure - Failed migration rolled back",
+			schema: "CREATE TABLE users (id INTEGER PRIMARY KEY)",
+			migrations: []migrate.Migration{
+				migrations[0],
+				{Version: 2, Description: "broken", Up: func(tx *sql.Tx) error {
+					if _, err := tx.Exec("ALTER TABLE users ADD COLUMN email TEXT"); err != nil {
+						return err
+					}
+					return errors.New("database locked")
+				}},
+			},
+			assertFunc: func(t *testing.T, db *sql.DB, err error) {
+				assert.EqualError(t, err, "error applying migration 2 (broken): database locked")
+				assert.Equal(t, []string{"id", "full_name"}, columns(t, db, "users"))
+				version, err := migrate.Version(db)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, version)
+			},
+		},
+		{
+			name:       "Failure - Migrations out of order",
+			migrations: []migrate.Migration{migrations[1], migrations[0]},
+			assertFunc: func(t *testing.T, db *sql.DB, err error) {
+				assert.EqualError(t, err, "migration 1 is listed after migration 2")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openDB(t)
+			if tt.schema != "" {
+				_, err := db.Exec(tt.schema)
+				assert.NoError(t, err)
+			}
+
+			err := migrate.Run(db, tt.migrations)
+
+			tt.assertFunc(t, db, err)
+		})
+	}
+}
+
+func TestRunAppliesEachMigrationOnce(t *testing.T) {
+	db := openDB(t)
+	_, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	assert.NoError(t, err)
+
+	applied := 0
+	migrations := []migrate.Migration{{Version: 1, Description: "count", Up: func(tx *sql.Tx) error {
+		applied++
+		return nil
+	}}}
+	assert.NoError(t, migrate.Run(db, migrations))
+	assert.NoError(t, migrate.Run(db, migrations))
+
+	assert.Equal(t, 1, applied)
+	version, err := migrate.Version(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"flarrocca/payment-service/service"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// runCommand runs a one-off command instead of the HTTP server, e.g.:
+//
+//	payment-service aml-monitor -from 2025-03-01 -to 2025-03-08 -scenarios structuring,rapid_refund
+//	payment-service stand-in-reconcile
+func runCommand(args []string, amlMonitoringService service.AMLMonitoringService, standInService service.StandInService) error {
+	switch args[0] {
+	case "aml-monitor":
+		return monitorAML(args[1:], amlMonitoringService, time.Now().UTC())
+	case "stand-in-reconcile":
+		return reconcileStandIn(standInService)
+	}
+
+	return fmt.Errorf("unknown command %q, available commands: aml-monitor, stand-in-reconcile", args[0])
+}
+
+// reconcileStandIn syncs the snapshot of blocked cards and reconciles the pending stand-in payments once.
+func reconcileStandIn(standInService service.StandInService) error {
+	snapshot, err := standInService.SyncSnapshot()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("blocked card snapshot synced: %d cards\n", snapshot.Cards)
+
+	reconciliation, err := standInService.Reconcile()
+	fmt.Printf("stand-in reconciliation: %d checked, %d confirmed, %d rejected, %d failed\n",
+		reconciliation.Checked, reconciliation.Confirmed, reconciliation.Rejected, reconciliation.Failed)
+	return err
+}
+
+// monitorAML runs the AML scenarios over a range, by default the previous UTC day, meant to be scheduled daily.
+func monitorAML(args []string, amlMonitoringService service.AMLMonitoringService, now time.Time) error {
+	flags := flag.NewFlagSet("aml-monitor", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "start of the range, RFC 3339 or YYYY-MM-DD (default: start of the previous UTC day)")
+	toFlag := flags.String("to", "", "end of the range, excluded, RFC 3339 or YYYY-MM-DD (default: start of the current UTC day)")
+	scenariosFlag := flags.String("scenarios", "", "comma-separated scenarios to run (default: all)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	to := now.Truncate(24 * time.Hour)
+	if *toFlag != "" {
+		var err error
+		if to, err = parseRangeTime(*toFlag); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+	from := to.Add(-24 * time.Hour)
+	if *fromFlag != "" {
+		var err error
+		if from, err = parseRangeTime(*fromFlag); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+
+	var scenarios []string
+	if *scenariosFlag != "" {
+		scenarios = strings.Split(*scenariosFlag, ",")
+	}
+
+	run, err := amlMonitoringService.Run(from, to, scenarios)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("AML monitoring from %s to %s (%s): %d payments and %d reversals scanned, %d findings, %d new alerts\n",
+		run.From.Format(time.RFC3339), run.To.Format(time.RFC3339), strings.Join(run.Scenarios, ", "),
+		run.PaymentsScanned, run.ReversalsScanned, len(run.Findings), run.AlertsCreated)
+	for _, finding := range run.Findings {
+		fmt.Printf("  %s user %d: %s\n", finding.Scenario, finding.UserID, finding.Message)
+	}
+
+	return nil
+}
+
+func parseRangeTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.DateOnly, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
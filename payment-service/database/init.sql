@@ -0,0 +1,153 @@
+-- Create transactions table
+CREATE TABLE IF NOT EXISTS transactions (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    card_id INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    status TEXT NOT NULL,
+    suspected_fraud BOOLEAN NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    refunded_at TIMESTAMP,
+    reviewer TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP,
+    stand_in TEXT NOT NULL DEFAULT '',
+    stand_in_policy TEXT NOT NULL DEFAULT '',
+    stand_in_reconciled_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_card ON transactions (user_id, card_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_created ON transactions (created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_stand_in ON transactions (stand_in, created_at);
+
+-- Create transaction_contexts table
+CREATE TABLE IF NOT EXISTS transaction_contexts (
+    transaction_id TEXT PRIMARY KEY,
+    ip_address TEXT NOT NULL DEFAULT '',
+    ip_country TEXT NOT NULL DEFAULT '',
+    ip_asn INTEGER NOT NULL DEFAULT 0,
+    ip_latitude REAL,
+    ip_longitude REAL,
+    device_id TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    billing_line1 TEXT NOT NULL DEFAULT '',
+    billing_city TEXT NOT NULL DEFAULT '',
+    billing_postal_code TEXT NOT NULL DEFAULT '',
+    billing_country TEXT NOT NULL DEFAULT '',
+    shipping_line1 TEXT NOT NULL DEFAULT '',
+    shipping_city TEXT NOT NULL DEFAULT '',
+    shipping_postal_code TEXT NOT NULL DEFAULT '',
+    shipping_country TEXT NOT NULL DEFAULT '',
+    merchant_id TEXT NOT NULL DEFAULT '',
+    mcc TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_contexts_device ON transaction_contexts (device_id);
+
+-- Create alerts table
+CREATE TABLE IF NOT EXISTS alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_type TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    card_id INTEGER NOT NULL,
+    transaction_id TEXT,
+    message TEXT NOT NULL,
+    evidence TEXT,
+    dedup_key TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alerts_dedup_key ON alerts (dedup_key) WHERE dedup_key IS NOT NULL;
+
+-- Create disputes table
+CREATE TABLE IF NOT EXISTS disputes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id TEXT UNIQUE NOT NULL,
+    reason_code TEXT NOT NULL,
+    stage TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    amount REAL NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    opened_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+
+-- Create dispute_evidence table
+CREATE TABLE IF NOT EXISTS dispute_evidence (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dispute_id INTEGER NOT NULL,
+    file_name TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (dispute_id) REFERENCES disputes (id) ON DELETE CASCADE
+);
+
+-- Create ledger_entries table
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dispute_id INTEGER NOT NULL,
+    transaction_id TEXT NOT NULL,
+    entry_type TEXT NOT NULL,
+    amount REAL NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (dispute_id) REFERENCES disputes (id) ON DELETE CASCADE
+);
+
+-- Create stand_in_blocked_cards table, the local snapshot of the cards blocked by compliance-service used while it is down
+CREATE TABLE IF NOT EXISTS stand_in_blocked_cards (
+    user_id INTEGER NOT NULL,
+    card_id INTEGER NOT NULL,
+    PRIMARY KEY (user_id, card_id)
+);
+
+-- Create stand_in_snapshots table, a single row recording when the snapshot was last synced
+CREATE TABLE IF NOT EXISTS stand_in_snapshots (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    cards INTEGER NOT NULL,
+    synced_at TIMESTAMP NOT NULL
+);
+
+-- Create webhook_endpoints table, the partner URLs notified of the events of the given comma-separated types, or of
+-- every event when empty. Deleted endpoints are deactivated to keep their delivery log.
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Create webhook_deliveries table, an event to send to an endpoint. Deliveries failing every attempt stay with the dead
+-- status until redelivered.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+-- Create webhook_delivery_attempts table, the delivery log
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delivery_id INTEGER NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL,
+    attempted_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE
+);
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ComplianceCacheHandler struct {
+	complianceCacheService service.ComplianceCacheService
+}
+
+func NewComplianceCacheHandler(complianceCacheService service.ComplianceCacheService) *ComplianceCacheHandler {
+	return &ComplianceCacheHandler{complianceCacheService: complianceCacheService}
+}
+
+// Invalidate receives the invalidations compliance-service sends when a card is reported, reinstated or blocked, when
+// the sanctions, KYC or risk status of a user changes, and when the deny and allow lists change.
+func (h *ComplianceCacheHandler) Invalidate(c *fiber.Ctx) error {
+	var req struct {
+		Cards   []repository.BlockedCard `json:"cards"`
+		UserIDs []int64                  `json:"user_ids"`
+		All     bool                     `json:"all"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	if !req.All && len(req.Cards) == 0 && len(req.UserIDs) == 0 {
+		return fiber.NewError(http.StatusBadRequest, "cards, user_ids or all are required")
+	}
+
+	h.complianceCacheService.Invalidate(req.Cards, req.UserIDs, req.All)
+
+	return c.JSON(fiber.Map{"message": "compliance verdicts invalidated"})
+}
+
+func (h *ComplianceCacheHandler) Stats(c *fiber.Ctx) error {
+	return c.JSON(h.complianceCacheService.Stats())
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"flarrocca/api"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/service/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidateComplianceCacheHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		on         func(complianceCacheServiceMock *mock.MockComplianceCacheService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Cards invalidated",
+			body: `{"cards": [{"user_id": 3, "card_id": 7}]}`,
+			on: func(complianceCacheServiceMock *mock.MockComplianceCacheService) {
+				complianceCacheServiceMock.EXPECT().Invalidate([]repository.BlockedCard{{UserID: 3, CardID: 7}}, nil, false)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"message": "compliance verdicts invalidated"}`, string(body))
+			},
+		},
+		{
+			name: "Success - Users invalidated",
+			body: `{"user_ids": [3, 4]}`,
+			on: func(complianceCacheServiceMock *mock.MockComplianceCacheService) {
+				complianceCacheServiceMock.EXPECT().Invalidate(nil, []int64{3, 4}, false)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Every verdict invalidated",
+			body: `{"all": true}`,
+			on: func(complianceCacheServiceMock *mock.MockComplianceCacheService) {
+				complianceCacheServiceMock.EXPECT().Invalidate(nil, nil, true)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Nothing to invalidate",
+			body: `{"cards": []}`,
+			on:   func(complianceCacheServiceMock *mock.MockComplianceCacheService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "cards, user_ids or all are required", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid payload",
+			body: `{"cards": "abc"}`,
+			on:   func(complianceCacheServiceMock *mock.MockComplianceCacheService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceCacheServiceMock := mock.NewMockComplianceCacheService(ctrl)
+			tt.on(complianceCacheServiceMock)
+
+			handler := NewComplianceCacheHandler(complianceCacheServiceMock)
+			app.Post("/compliance_cache/invalidations", handler.Invalidate)
+
+			req := httptest.NewRequest(http.MethodPost, "/compliance_cache/invalidations", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestComplianceCacheStatsHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	complianceCacheServiceMock := mock.NewMockComplianceCacheService(ctrl)
+	complianceCacheServiceMock.EXPECT().Stats().Return(repository.ComplianceCacheStats{Hits: 3, Misses: 1, HitRatio: 0.75, Entries: 1, Capacity: 100})
+
+	handler := NewComplianceCacheHandler(complianceCacheServiceMock)
+	app.Get("/compliance_cache/stats", handler.Stats)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/compliance_cache/stats", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"hits": 3, "misses": 1, "hit_ratio": 0.75, "evicted": 0, "expired": 0, "invalidated": 0, "entries": 1, "capacity": 100}`, string(body))
+}
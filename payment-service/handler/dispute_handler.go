@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/payment-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DisputeHandler struct {
+	disputeService service.DisputeService
+}
+
+func NewDisputeHandler(disputeService service.DisputeService) *DisputeHandler {
+	return &DisputeHandler{disputeService: disputeService}
+}
+
+func (h *DisputeHandler) OpenDispute(c *fiber.Ctx) error {
+	var req struct {
+		TransactionID string `json:"transaction_id"`
+		ReasonCode    string `json:"reason_code"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	if req.TransactionID == "" || req.ReasonCode == "" {
+		return fiber.NewError(http.StatusBadRequest, "transaction id and reason code are required")
+	}
+
+	dispute, err := h.disputeService.OpenDispute(c.UserContext(), req.TransactionID, req.ReasonCode)
+	if err != nil {
+		return disputeErrorResponse(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(dispute)
+}
+
+func (h *DisputeHandler) GetDispute(c *fiber.Ctx) error {
+	disputeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for dispute ID: %s", err))
+	}
+
+	dispute, err := h.disputeService.GetDispute(disputeID)
+	if err != nil {
+		return disputeErrorResponse(err)
+	}
+
+	return c.JSON(dispute)
+}
+
+func (h *DisputeHandler) AdvanceDispute(c *fiber.Ctx) error {
+	disputeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for dispute ID: %s", err))
+	}
+
+	var req struct {
+		Stage string `json:"stage"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.Stage == "" {
+		return fiber.NewError(http.StatusBadRequest, "stage is required")
+	}
+
+	dispute, err := h.disputeService.AdvanceDispute(disputeID, req.Stage)
+	if err != nil {
+		return disputeErrorResponse(err)
+	}
+
+	return c.JSON(dispute)
+}
+
+func (h *DisputeHandler) ResolveDispute(c *fiber.Ctx) error {
+	disputeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for dispute ID: %s", err))
+	}
+
+	var req struct {
+		Outcome string `json:"outcome"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.Outcome == "" {
+		return fiber.NewError(http.StatusBadRequest, "outcome is required")
+	}
+
+	if err := h.disputeService.ResolveDispute(disputeID, req.Outcome); err != nil {
+		return disputeErrorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"message": fmt.Sprintf("dispute closed as %s", req.Outcome)})
+}
+
+func (h *DisputeHandler) AddEvidence(c *fiber.Ctx) error {
+	disputeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for dispute ID: %s", err))
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, "evidence file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, "evidence file cannot be read")
+	}
+	defer file.Close()
+
+	if err := h.disputeService.AddEvidence(disputeID, fileHeader.Filename, file); err != nil {
+		return disputeErrorResponse(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"message": fmt.Sprintf("evidence %s attached to the dispute", fileHeader.Filename)})
+}
+
+func disputeErrorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrDisputeNotFound), errors.Is(err, service.ErrTransactionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrDisputeAlreadyOpened), errors.Is(err, service.ErrDisputeClosed), errors.Is(err, service.ErrDisputeDeadlinePassed):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidReasonCode), errors.Is(err, service.ErrInvalidDisputeStage),
+		errors.Is(err, service.ErrInvalidDisputeOutcome), errors.Is(err, service.ErrTransactionNotSettled):
+		status = http.StatusBadRequest
+	}
+
+	return fiber.NewError(status, err.Error())
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"flarrocca/api"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/service"
+	"flarrocca/payment-service/service/mock"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDisputeApp(disputeServiceMock *mock.MockDisputeService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	handler := &DisputeHandler{disputeService: disputeServiceMock}
+
+	app.Post("/disputes", handler.OpenDispute)
+	app.Get("/disputes/:id", handler.GetDispute)
+	app.Put("/disputes/:id/stage", handler.AdvanceDispute)
+	app.Put("/disputes/:id/resolution", handler.ResolveDispute)
+	app.Post("/disputes/:id/evidence", handler.AddEvidence)
+
+	return app
+}
+
+func TestDisputeHandler(t *testing.T) {
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	type depFields struct {
+		disputeServiceMock *mock.MockDisputeService
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Dispute opened",
+			input: input{
+				method: http.MethodPost,
+				path:   "/disputes",
+				body:   `{"transaction_id": "txn_1", "reason_code": "10.4"}`,
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().OpenDispute(gomock.Any(), "txn_1", "10.4").Return(repository.Dispute{ID: 3, TransactionID: "txn_1", Stage: service.DisputeStageChargeback}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"stage":"chargeback"`)
+			},
+		},
+		{
+			name: "Failure - Missing reason code",
+			input: input{
+				method: http.MethodPost,
+				path:   "/disputes",
+				body:   `{"transaction_id": "txn_1"}`,
+			},
+			on: func(dep *depFields) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "transaction id and reason code are required", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Dispute already opened",
+			input: input{
+				method: http.MethodPost,
+				path:   "/disputes",
+				body:   `{"transaction_id": "txn_1", "reason_code": "10.4"}`,
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().OpenDispute(gomock.Any(), "txn_1", "10.4").Return(repository.Dispute{}, service.ErrDisputeAlreadyOpened)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Transaction not found",
+			input: input{
+				method: http.MethodPost,
+				path:   "/disputes",
+				body:   `{"transaction_id": "txn_404", "reason_code": "10.4"}`,
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().OpenDispute(gomock.Any(), "txn_404", "10.4").Return(repository.Dispute{}, service.ErrTransactionNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Dispute details",
+			input: input{
+				method: http.MethodGet,
+				path:   "/disputes/3",
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().GetDispute(int64(3)).Return(service.DisputeDetails{
+					Dispute:       repository.Dispute{ID: 3},
+					LedgerEntries: []repository.LedgerEntry{{EntryType: service.LedgerEntryChargebackDebit, Amount: -50}},
+				}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"entry_type":"chargeback_debit"`)
+			},
+		},
+		{
+			name: "Failure - Invalid dispute ID",
+			input: input{
+				method: http.MethodGet,
+				path:   "/disputes/abc",
+			},
+			on: func(dep *depFields) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Dispute advanced",
+			input: input{
+				method: http.MethodPut,
+				path:   "/disputes/3/stage",
+				body:   `{"stage": "representment"}`,
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().AdvanceDispute(int64(3), service.DisputeStageRepresentment).Return(repository.Dispute{ID: 3, Stage: service.DisputeStageRepresentment}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Deadline passed",
+			input: input{
+				method: http.MethodPut,
+				path:   "/disputes/3/stage",
+				body:   `{"stage": "representment"}`,
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().AdvanceDispute(int64(3), service.DisputeStageRepresentment).Return(repository.Dispute{}, service.ErrDisputeDeadlinePassed)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Success - Dispute resolved",
+			input: input{
+				method: http.MethodPut,
+				path:   "/disputes/3/resolution",
+				body:   `{"outcome": "won"}`,
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().ResolveDispute(int64(3), service.DisputeStatusWon).Return(nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"message": "dispute closed as won"}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Internal service error",
+			input: input{
+				method: http.MethodPut,
+				path:   "/disputes/3/resolution",
+				body:   `{"outcome": "lost"}`,
+			},
+			on: func(dep *depFields) {
+				dep.disputeServiceMock.EXPECT().ResolveDispute(int64(3), service.DisputeStatusLost).Return(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			disputeServiceMock := mock.NewMockDisputeService(ctrl)
+			tt.on(&depFields{disputeServiceMock: disputeServiceMock})
+
+			app := newTestDisputeApp(disputeServiceMock)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestAddEvidenceHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		withFile   bool
+		on         func(disputeServiceMock *mock.MockDisputeService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:     "Success - Evidence uploaded",
+			withFile: true,
+			on: func(disputeServiceMock *mock.MockDisputeService) {
+				disputeServiceMock.EXPECT().AddEvidence(int64(3), "receipt.pdf", gomock.Any()).DoAndReturn(func(disputeID int64, fileName string, content io.Reader) error {
+					data, _ := io.ReadAll(content)
+					assert.Equal(t, "receipt content", string(data))
+					return nil
+				})
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"message": "evidence receipt.pdf attached to the dispute"}`, string(body))
+			},
+		},
+		{
+			name:     "Failure - Missing file",
+			withFile: false,
+			on:       func(disputeServiceMock *mock.MockDisputeService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "evidence file is required", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name:     "Failure - Closed dispute",
+			withFile: true,
+			on: func(disputeServiceMock *mock.MockDisputeService) {
+				disputeServiceMock.EXPECT().AddEvidence(int64(3), "receipt.pdf", gomock.Any()).Return(service.ErrDisputeClosed)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			disputeServiceMock := mock.NewMockDisputeService(ctrl)
+			tt.on(disputeServiceMock)
+
+			app := newTestDisputeApp(disputeServiceMock)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			if tt.withFile {
+				part, _ := writer.CreateFormFile("file", "receipt.pdf")
+				part.Write([]byte("receipt content"))
+			}
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/disputes/3/evidence", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/service"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FraudFlaggingHandler struct {
+	fraudFlaggingService   service.FraudFlaggingService
+	complianceCacheService service.ComplianceCacheService
+}
+
+func NewFraudFlaggingHandler(fraudFlaggingService service.FraudFlaggingService, complianceCacheService service.ComplianceCacheService) *FraudFlaggingHandler {
+	return &FraudFlaggingHandler{fraudFlaggingService: fraudFlaggingService, complianceCacheService: complianceCacheService}
+}
+
+// CardsReported receives the card report events sent by compliance-service. compliance-service retries them until
+// they are answered, so the verdicts cached for the cards are dropped here too, before any payment can be flagged.
+func (h *FraudFlaggingHandler) CardsReported(c *fiber.Ctx) error {
+	var req struct {
+		UserID     int64     `json:"user_id"`
+		CardIDs    []int64   `json:"card_ids"`
+		ReportedAt time.Time `json:"reported_at"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	if req.UserID == 0 || len(req.CardIDs) == 0 {
+		return fiber.NewError(http.StatusBadRequest, "user id and card ids are required")
+	}
+
+	if req.ReportedAt.IsZero() {
+		req.ReportedAt = time.Now().UTC()
+	}
+
+	cards := make([]repository.BlockedCard, 0, len(req.CardIDs))
+	for _, cardID := range req.CardIDs {
+		cards = append(cards, repository.BlockedCard{UserID: req.UserID, CardID: cardID})
+	}
+	h.complianceCacheService.Invalidate(cards, nil, false)
+
+	transactionIDs, err := h.fraudFlaggingService.FlagReportedCards(req.UserID, req.CardIDs, req.ReportedAt)
+	if err != nil {
+		return fiber.NewError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{"flagged_transactions": transactionIDs})
+}
+
+func (h *FraudFlaggingHandler) ListAlerts(c *fiber.Ctx) error {
+	alerts, err := h.fraudFlaggingService.ListAlerts(c.Query("type"))
+	if err != nil {
+		return fiber.NewError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{"alerts": alerts})
+}
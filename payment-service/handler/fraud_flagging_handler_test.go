@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"flarrocca/api"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/service/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardsReportedHandler(t *testing.T) {
+	type depFields struct {
+		fraudFlaggingServiceMock   *mock.MockFraudFlaggingService
+		complianceCacheServiceMock *mock.MockComplianceCacheService
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		on         func(*depFields)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Transactions flagged",
+			body: `{"user_id": 1, "card_ids": [1, 2], "reported_at": "2025-03-01T12:00:00Z"}`,
+			on: func(dep *depFields) {
+				dep.complianceCacheServiceMock.EXPECT().Invalidate([]repository.BlockedCard{{UserID: 1, CardID: 1}, {UserID: 1, CardID: 2}}, nil, false)
+				dep.fraudFlaggingServiceMock.EXPECT().FlagReportedCards(int64(1), []int64{1, 2}, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)).
+					Return([]string{"txn_1"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"flagged_transactions": ["txn_1"]}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Missing card ids",
+			body: `{"user_id": 1}`,
+			on:   func(dep *depFields) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user id and card ids are required", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid payload",
+			body: `{"user_id": "abc"`,
+			on:   func(dep *depFields) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Internal service error, cached verdicts still dropped",
+			body: `{"user_id": 1, "card_ids": [1]}`,
+			on: func(dep *depFields) {
+				dep.complianceCacheServiceMock.EXPECT().Invalidate([]repository.BlockedCard{{UserID: 1, CardID: 1}}, nil, false)
+				dep.fraudFlaggingServiceMock.EXPECT().FlagReportedCards(int64(1), []int64{1}, gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "internal", "message": "database error", "request_id": ""}`, string(body))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fraudFlaggingServiceMock := mock.NewMockFraudFlaggingService(ctrl)
+			complianceCacheServiceMock := mock.NewMockComplianceCacheService(ctrl)
+			tt.on(&depFields{fraudFlaggingServiceMock: fraudFlaggingServiceMock, complianceCacheServiceMock: complianceCacheServiceMock})
+
+			handler := &FraudFlaggingHandler{fraudFlaggingService: fraudFlaggingServiceMock, complianceCacheService: complianceCacheServiceMock}
+			app.Post("/cards_reported", handler.CardsReported)
+
+			req := httptest.NewRequest(http.MethodPost, "/cards_reported", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestListAlertsHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fraudFlaggingServiceMock := mock.NewMockFraudFlaggingService(ctrl)
+	fraudFlaggingServiceMock.EXPECT().ListAlerts(repository.AlertTypeSuspectedFraud).Return([]repository.Alert{{ID: 1, AlertType: repository.AlertTypeSuspectedFraud, TransactionID: "txn_1"}}, nil)
+
+	handler := &FraudFlaggingHandler{fraudFlaggingService: fraudFlaggingServiceMock}
+	app.Get("/alerts", handler.ListAlerts)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/alerts?type=suspected_fraud", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"transaction_id":"txn_1"`)
+}
@@ -1,12 +1,37 @@
 package handler
 
 import (
+	"errors"
+	"flarrocca/api"
+	"flarrocca/payment-service/repository"
 	"flarrocca/payment-service/service"
+	"fmt"
+	"net"
 	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// codePaymentDenied is the code of the payments denied by the compliance checks or the stand-in policy.
+const codePaymentDenied = "payment_denied"
+
+const (
+	maxUserAgentLength  = 512
+	maxIdentifierLength = 128
+
+	defaultPaymentsLookback = 30 * 24 * time.Hour
+)
+
+var (
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	mccPattern         = regexp.MustCompile(`^[0-9]{4}$`)
+)
+
 type PaymentProcessorHandler struct {
 	paymentService service.PaymentProcessorService
 }
@@ -17,23 +42,159 @@ func NewPaymentProcessorHandler(complianceService service.PaymentProcessorServic
 
 func (p *PaymentProcessorHandler) ProcessPayment(c *fiber.Ctx) error {
 	var req struct {
-		UserID int64   `json:"user_id"`
-		CardID int64   `json:"card_id"`
-		Amount float64 `json:"amount"`
+		UserID          int64              `json:"user_id"`
+		CardID          int64              `json:"card_id"`
+		Amount          float64            `json:"amount"`
+		IPAddress       string             `json:"ip_address"`
+		DeviceID        string             `json:"device_id"`
+		UserAgent       string             `json:"user_agent"`
+		Email           string             `json:"email"`
+		BillingAddress  repository.Address `json:"billing_address"`
+		ShippingAddress repository.Address `json:"shipping_address"`
+		MerchantID      string             `json:"merchant_id"`
+		MCC             string             `json:"mcc"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"message": "invalid request payload"})
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
 	}
 
 	if req.UserID == 0 || req.CardID == 0 || req.Amount <= 0 {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"message": "user id, card id and valid amount are required"})
+		return fiber.NewError(http.StatusBadRequest, "user id, card id and valid amount are required")
 	}
 
-	message, err := p.paymentService.ProcessPayment(req.UserID, req.CardID, req.Amount)
+	paymentContext := &repository.PaymentContext{
+		IPAddress:       strings.TrimSpace(req.IPAddress),
+		DeviceID:        strings.TrimSpace(req.DeviceID),
+		UserAgent:       strings.TrimSpace(req.UserAgent),
+		Email:           strings.ToLower(strings.TrimSpace(req.Email)),
+		BillingAddress:  normalizeAddress(req.BillingAddress),
+		ShippingAddress: normalizeAddress(req.ShippingAddress),
+		MerchantID:      strings.TrimSpace(req.MerchantID),
+		MCC:             strings.TrimSpace(req.MCC),
+	}
+
+	if err := validatePaymentContext(paymentContext); err != nil {
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if *paymentContext == (repository.PaymentContext{}) {
+		paymentContext = nil
+	}
+
+	message, err := p.paymentService.ProcessPayment(c.UserContext(), req.UserID, req.CardID, req.Amount, paymentContext)
 	if err != nil {
-		return c.Status(http.StatusForbidden).JSON(fiber.Map{"message": err.Error()})
+		if errors.Is(err, service.ErrPaymentPendingReview) {
+			return c.Status(http.StatusAccepted).JSON(fiber.Map{"message": err.Error()})
+		}
+		return paymentErrorResponse(err)
 	}
 
 	return c.JSON(fiber.Map{"message": message})
 }
+
+// paymentErrorResponse tells a denied payment, answered with the payment_denied code, from a payment compliance-service
+// could not check.
+func paymentErrorResponse(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrComplianceUnavailable):
+		return api.NewError(http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, repository.ErrComplianceRequestFailed):
+		return api.NewError(http.StatusBadGateway, err.Error())
+	case errors.Is(err, service.ErrPaymentDenied):
+		return api.NewError(http.StatusForbidden, err.Error()).WithCode(codePaymentDenied)
+	}
+	return api.NewError(http.StatusInternalServerError, err.Error())
+}
+
+// ReviewPayment records the decision of a reviewer on a payment held for manual review.
+func (p *PaymentProcessorHandler) ReviewPayment(c *fiber.Ctx) error {
+	var req struct {
+		Status   string `json:"status"`
+		Reviewer string `json:"reviewer"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	transaction, err := p.paymentService.ReviewPayment(c.UserContext(), c.Params("id"), req.Status, req.Reviewer)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrTransactionNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPaymentNotPendingReview):
+			status = http.StatusConflict
+		case errors.Is(err, service.ErrInvalidPaymentReview):
+			status = http.StatusBadRequest
+		}
+		return fiber.NewError(status, err.Error())
+	}
+
+	return c.JSON(transaction)
+}
+
+// ListUserPayments returns the payments of a user since the RFC 3339 time in since, 30 days ago by default, for the case
+// exports of compliance-service.
+func (p *PaymentProcessorHandler) ListUserPayments(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		return fiber.NewError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	since := time.Now().UTC().Add(-defaultPaymentsLookback)
+	if c.Query("since") != "" {
+		since, err = time.Parse(time.RFC3339, c.Query("since"))
+		if err != nil {
+			return fiber.NewError(http.StatusBadRequest, "since must be an RFC 3339 time")
+		}
+	}
+
+	payments, err := p.paymentService.ListUserPayments(userID, since)
+	if err != nil {
+		return fiber.NewError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{"payments": payments})
+}
+
+func normalizeAddress(address repository.Address) repository.Address {
+	return repository.Address{
+		Line1:      strings.TrimSpace(address.Line1),
+		City:       strings.TrimSpace(address.City),
+		PostalCode: strings.ToUpper(strings.TrimSpace(address.PostalCode)),
+		Country:    strings.ToUpper(strings.TrimSpace(address.Country)),
+	}
+}
+
+func validatePaymentContext(paymentContext *repository.PaymentContext) error {
+	if paymentContext.IPAddress != "" && net.ParseIP(paymentContext.IPAddress) == nil {
+		return fmt.Errorf("invalid ip address: %s", paymentContext.IPAddress)
+	}
+
+	if paymentContext.Email != "" {
+		if address, err := mail.ParseAddress(paymentContext.Email); err != nil || address.Address != paymentContext.Email {
+			return fmt.Errorf("invalid email: %s", paymentContext.Email)
+		}
+	}
+
+	if len(paymentContext.DeviceID) > maxIdentifierLength || len(paymentContext.MerchantID) > maxIdentifierLength {
+		return fmt.Errorf("device id and merchant id must be at most %d characters", maxIdentifierLength)
+	}
+
+	if len(paymentContext.UserAgent) > maxUserAgentLength {
+		return fmt.Errorf("user agent must be at most %d characters", maxUserAgentLength)
+	}
+
+	if paymentContext.MCC != "" && !mccPattern.MatchString(paymentContext.MCC) {
+		return fmt.Errorf("invalid mcc: %s", paymentContext.MCC)
+	}
+
+	for _, country := range []string{paymentContext.BillingAddress.Country, paymentContext.ShippingAddress.Country} {
+		if country != "" && !countryCodePattern.MatchString(country) {
+			return fmt.Errorf("invalid country code: %s", country)
+		}
+	}
+
+	return nil
+}
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"flarrocca/api"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/service"
 	"flarrocca/payment-service/service/mock"
 
 	"github.com/gofiber/fiber/v2"
@@ -41,7 +46,7 @@ func TestProcessPaymentHandler(t *testing.T) {
 				amount: 100.50,
 			},
 			on: func(dep *depFields, in input) {
-				dep.paymentServiceMock.EXPECT().ProcessPayment(in.userID, in.cardID, 100.50).
+				dep.paymentServiceMock.EXPECT().ProcessPayment(gomock.Any(), in.userID, in.cardID, 100.50, gomock.Nil()).
 					Return("payment successful. Transaction ID: txn_123456", nil)
 			},
 			assertFunc: func(t *testing.T, resp *http.Response) {
@@ -61,7 +66,7 @@ func TestProcessPaymentHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.JSONEq(t, `{"message": "user id, card id and valid amount are required"}`, string(body))
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user id, card id and valid amount are required", "request_id": ""}`, string(body))
 			},
 		},
 		{
@@ -75,7 +80,7 @@ func TestProcessPaymentHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.JSONEq(t, `{"message": "user id, card id and valid amount are required"}`, string(body))
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user id, card id and valid amount are required", "request_id": ""}`, string(body))
 			},
 		},
 		{
@@ -89,7 +94,7 @@ func TestProcessPaymentHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.JSONEq(t, `{"message": "user id, card id and valid amount are required"}`, string(body))
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user id, card id and valid amount are required", "request_id": ""}`, string(body))
 			},
 		},
 		{
@@ -103,7 +108,7 @@ func TestProcessPaymentHandler(t *testing.T) {
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.JSONEq(t, `{"message": "user id, card id and valid amount are required"}`, string(body))
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "user id, card id and valid amount are required", "request_id": ""}`, string(body))
 			},
 		},
 		{
@@ -114,20 +119,88 @@ func TestProcessPaymentHandler(t *testing.T) {
 				amount: 100.50,
 			},
 			on: func(dep *depFields, in input) {
-				dep.paymentServiceMock.EXPECT().ProcessPayment(in.userID, in.cardID, 100.50).
-					Return("", errors.New("payment denied: Suspicious activity detected"))
+				dep.paymentServiceMock.EXPECT().ProcessPayment(gomock.Any(), in.userID, in.cardID, 100.50, gomock.Nil()).
+					Return("", fmt.Errorf("%w: Suspicious activity detected", service.ErrPaymentDenied))
 			},
 			assertFunc: func(t *testing.T, resp *http.Response) {
 				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 				body, _ := io.ReadAll(resp.Body)
-				assert.JSONEq(t, `{"message": "payment denied: Suspicious activity detected"}`, string(body))
+				assert.JSONEq(t, `{"code": "payment_denied", "message": "payment denied: Suspicious activity detected", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Success - Payment held for manual review",
+			input: input{
+				userID: int64(2),
+				cardID: int64(3),
+				amount: 750,
+			},
+			on: func(dep *depFields, in input) {
+				dep.paymentServiceMock.EXPECT().ProcessPayment(gomock.Any(), in.userID, in.cardID, 750.0, gomock.Nil()).
+					Return("", fmt.Errorf("%w: payment amount 750.00 of a high-risk user requires manual review. Transaction ID: txn_123456", service.ErrPaymentPendingReview))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), "payment held for manual review")
+			},
+		},
+		{
+			name: "Failure - Compliance service unavailable",
+			input: input{
+				userID: int64(1),
+				cardID: int64(1),
+				amount: 100.50,
+			},
+			on: func(dep *depFields, in input) {
+				dep.paymentServiceMock.EXPECT().ProcessPayment(gomock.Any(), in.userID, in.cardID, 100.50, gomock.Nil()).
+					Return("", fmt.Errorf("%w: %w: compliance service timed out", service.ErrPaymentDenied, repository.ErrComplianceUnavailable))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "unavailable", "message": "payment denied: compliance service unavailable: compliance service timed out", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Compliance request failed",
+			input: input{
+				userID: int64(1),
+				cardID: int64(1),
+				amount: 100.50,
+			},
+			on: func(dep *depFields, in input) {
+				dep.paymentServiceMock.EXPECT().ProcessPayment(gomock.Any(), in.userID, in.cardID, 100.50, gomock.Nil()).
+					Return("", fmt.Errorf("%w: %w: status 500", service.ErrPaymentDenied, repository.ErrComplianceRequestFailed))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"code":"bad_gateway"`)
+			},
+		},
+		{
+			name: "Failure - Transaction not recorded",
+			input: input{
+				userID: int64(1),
+				cardID: int64(1),
+				amount: 100.50,
+			},
+			on: func(dep *depFields, in input) {
+				dep.paymentServiceMock.EXPECT().ProcessPayment(gomock.Any(), in.userID, in.cardID, 100.50, gomock.Nil()).
+					Return("", errors.New("payment denied: error recording transaction"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "internal", "message": "payment denied: error recording transaction", "request_id": ""}`, string(body))
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			app := fiber.New()
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
 
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
@@ -153,3 +226,251 @@ func TestProcessPaymentHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessPaymentContextHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		on         func(paymentServiceMock *mock.MockPaymentProcessorService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Context normalized and forwarded",
+			body: `{"user_id": 1, "card_id": 1, "amount": 100.5, "ip_address": " 203.0.113.7 ", "device_id": "device-1",
+				"email": "Alice@Example.com", "billing_address": {"line1": "1 Main St", "city": "London", "postal_code": "n1 9gu", "country": "gb"},
+				"merchant_id": "merchant-1", "mcc": "5411"}`,
+			on: func(paymentServiceMock *mock.MockPaymentProcessorService) {
+				paymentServiceMock.EXPECT().ProcessPayment(gomock.Any(), int64(1), int64(1), 100.5, &repository.PaymentContext{
+					IPAddress:      "203.0.113.7",
+					DeviceID:       "device-1",
+					Email:          "alice@example.com",
+					BillingAddress: repository.Address{Line1: "1 Main St", City: "London", PostalCode: "N1 9GU", Country: "GB"},
+					MerchantID:     "merchant-1",
+					MCC:            "5411",
+				}).Return("payment successful. Transaction ID: txn_123456", nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Invalid IP address",
+			body: `{"user_id": 1, "card_id": 1, "amount": 100.5, "ip_address": "300.1.1.1"}`,
+			on:   func(paymentServiceMock *mock.MockPaymentProcessorService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "invalid ip address: 300.1.1.1", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid email",
+			body: `{"user_id": 1, "card_id": 1, "amount": 100.5, "email": "Alice <alice@example.com>"}`,
+			on:   func(paymentServiceMock *mock.MockPaymentProcessorService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "invalid email: alice <alice@example.com>", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid MCC",
+			body: `{"user_id": 1, "card_id": 1, "amount": 100.5, "mcc": "54A1"}`,
+			on:   func(paymentServiceMock *mock.MockPaymentProcessorService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "invalid mcc: 54A1", "request_id": ""}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid shipping country",
+			body: `{"user_id": 1, "card_id": 1, "amount": 100.5, "shipping_address": {"country": "GBR"}}`,
+			on:   func(paymentServiceMock *mock.MockPaymentProcessorService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"code": "invalid_argument", "message": "invalid country code: GBR", "request_id": ""}`, string(body))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			paymentServiceMock := mock.NewMockPaymentProcessorService(ctrl)
+			tt.on(paymentServiceMock)
+
+			handler := &PaymentProcessorHandler{paymentService: paymentServiceMock}
+			app.Post("/process_payment", handler.ProcessPayment)
+
+			req := httptest.NewRequest(http.MethodPost, "/process_payment", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestReviewPaymentHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		on         func(paymentServiceMock *mock.MockPaymentProcessorService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Payment approved",
+			path: "/transactions/txn_123456/review",
+			body: `{"status": "approved", "reviewer": "alice"}`,
+			on: func(paymentServiceMock *mock.MockPaymentProcessorService) {
+				paymentServiceMock.EXPECT().ReviewPayment(gomock.Any(), "txn_123456", "approved", "alice").
+					Return(repository.Transaction{ID: "txn_123456", Status: repository.TransactionStatusApproved, Reviewer: "alice"}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"status":"approved"`)
+				assert.Contains(t, string(body), `"reviewer":"alice"`)
+			},
+		},
+		{
+			name: "Failure - Transaction not found",
+			path: "/transactions/txn_999/review",
+			body: `{"status": "declined", "reviewer": "alice"}`,
+			on: func(paymentServiceMock *mock.MockPaymentProcessorService) {
+				paymentServiceMock.EXPECT().ReviewPayment(gomock.Any(), "txn_999", "declined", "alice").Return(repository.Transaction{}, service.ErrTransactionNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Payment already reviewed",
+			path: "/transactions/txn_123456/review",
+			body: `{"status": "declined", "reviewer": "alice"}`,
+			on: func(paymentServiceMock *mock.MockPaymentProcessorService) {
+				paymentServiceMock.EXPECT().ReviewPayment(gomock.Any(), "txn_123456", "declined", "alice").Return(repository.Transaction{}, service.ErrPaymentNotPendingReview)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Invalid status",
+			path: "/transactions/txn_123456/review",
+			body: `{"status": "refund_pending", "reviewer": "alice"}`,
+			on: func(paymentServiceMock *mock.MockPaymentProcessorService) {
+				paymentServiceMock.EXPECT().ReviewPayment(gomock.Any(), "txn_123456", "refund_pending", "alice").
+					Return(repository.Transaction{}, fmt.Errorf("%w: status must be approved or declined", service.ErrInvalidPaymentReview))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			paymentServiceMock := mock.NewMockPaymentProcessorService(ctrl)
+			tt.on(paymentServiceMock)
+
+			handler := &PaymentProcessorHandler{paymentService: paymentServiceMock}
+			app.Put("/transactions/:id/review", handler.ReviewPayment)
+
+			req := httptest.NewRequest(http.MethodPut, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
+
+func TestListUserPaymentsHandler(t *testing.T) {
+	since := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		path       string
+		on         func(paymentServiceMock *mock.MockPaymentProcessorService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "Success - Payments since the given time",
+			path: "/users/1/payments?since=2025-03-01T10:00:00Z",
+			on: func(paymentServiceMock *mock.MockPaymentProcessorService) {
+				paymentServiceMock.EXPECT().ListUserPayments(int64(1), since).
+					Return([]repository.Transaction{{ID: "txn_1", UserID: 1, CardID: 2, Amount: 10, Status: repository.TransactionStatusApproved}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"payments":[{"id":"txn_1"`)
+			},
+		},
+		{
+			name: "Success - Payments of the last 30 days by default",
+			path: "/users/1/payments",
+			on: func(paymentServiceMock *mock.MockPaymentProcessorService) {
+				paymentServiceMock.EXPECT().ListUserPayments(int64(1), gomock.Any()).DoAndReturn(func(userID int64, since time.Time) ([]repository.Transaction, error) {
+					assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), since, time.Minute)
+					return []repository.Transaction{}, nil
+				})
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"payments": []}`, string(body))
+			},
+		},
+		{
+			name: "Failure - Invalid user ID",
+			path: "/users/abc/payments",
+			on:   func(paymentServiceMock *mock.MockPaymentProcessorService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "Failure - Invalid since",
+			path: "/users/1/payments?since=yesterday",
+			on:   func(paymentServiceMock *mock.MockPaymentProcessorService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			paymentServiceMock := mock.NewMockPaymentProcessorService(ctrl)
+			tt.on(paymentServiceMock)
+
+			handler := &PaymentProcessorHandler{paymentService: paymentServiceMock}
+			app.Get("/users/:id/payments", handler.ListUserPayments)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.NoError(t, err)
+			tt.assertFunc(t, resp)
+		})
+	}
+}
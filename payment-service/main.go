@@ -1,23 +1,160 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"flarrocca/api"
+	"flarrocca/auth"
+	"flarrocca/logging"
+	"flarrocca/metrics"
+	"flarrocca/migrate"
 	"flarrocca/payment-service/handler"
 	"flarrocca/payment-service/repository"
 	"flarrocca/payment-service/service"
-	"log"
+	"flarrocca/webhook"
+	"log/slog"
+	"os"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	_ "github.com/mattn/go-sqlite3"
 )
 
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+func initDB() *sql.DB {
+	db, err := sql.Open("sqlite3", "./database/payment.db")
+	if err != nil {
+		logging.Fatal("error opening the database", err)
+	}
+
+	// The tables created by an older init.sql are upgraded first, init.sql only creating the missing ones.
+	if err := migrate.Run(db, repository.Migrations); err != nil {
+		logging.Fatal("error migrating the database", err)
+	}
+
+	initSQL, err := os.ReadFile("./database/init.sql")
+	if err != nil {
+		logging.Fatal("error reading init.sql", err)
+	}
+
+	_, err = db.Exec(string(initSQL))
+	if err != nil {
+		logging.Fatal("error executing init.sql", err)
+	}
+
+	metrics.RegisterDB(db, "payment")
+	return db
+}
+
+// newComplianceRepository calls compliance-service over gRPC when COMPLIANCE_TRANSPORT is grpc, over HTTP otherwise. The
+// calls are signed with AUTH_JWT_PRIVATE_KEY or AUTH_JWT_SECRET, for the scopes payment-service is allowed.
+func newComplianceRepository() repository.ComplianceRepository {
+	tokenSource, err := auth.NewTokenSource("payment-service", auth.AudienceCompliance,
+		[]string{auth.ScopeComplianceCheck, auth.ScopeComplianceCases})
+	if err != nil {
+		logging.Fatal("error setting up compliance authentication", err)
+	}
+	if tokenSource == nil {
+		slog.Warn("no signing key, compliance-service is called without a token")
+	}
+
+	if os.Getenv("COMPLIANCE_TRANSPORT") != "grpc" {
+		return repository.NewComplianceRepository(tokenSource)
+	}
+
+	complianceRepository, err := repository.NewComplianceGRPCRepository(tokenSource)
+	if err != nil {
+		logging.Fatal("error creating compliance gRPC client", err)
+	}
+	return complianceRepository
+}
+
 func main() {
-	app := fiber.New()
+	logging.Setup("payment-service")
+	db := initDB()
 
-	complianceRepository := repository.NewComplianceRepository()
+	complianceRepository := repository.NewCachedComplianceRepository(newComplianceRepository())
+	transactionRepository := repository.NewTransactionRepository(db)
+	alertRepository := repository.NewAlertRepository(db)
+	disputeRepository := repository.NewDisputeRepository(db)
+	evidenceRepository := repository.NewEvidenceRepository()
+	ipIntelligenceRepository := repository.NewIPIntelligenceRepository()
+	standInRepository := repository.NewStandInRepository(db)
 
-	paymentProcessorService := service.NewPaymentProcessorService(complianceRepository)
+	fraudRuleService := service.NewFraudRuleService(
+		service.NewIPCountryMismatchRule(),
+		service.NewImpossibleTravelRule(transactionRepository),
+		service.NewFirstSeenCountryRule(transactionRepository),
+	)
+	standInService := service.NewStandInService(complianceRepository, transactionRepository, alertRepository, standInRepository)
+	webhookService := webhook.NewService(webhook.NewRepository(db), service.PaymentEventTypes)
+	paymentProcessorService := service.NewPaymentProcessorService(complianceRepository, transactionRepository, alertRepository, ipIntelligenceRepository, fraudRuleService,
+		standInService, webhookService)
 	paymentProcessorHandler := handler.NewPaymentProcessorHandler(paymentProcessorService)
+	complianceCacheService := service.NewComplianceCacheService(complianceRepository)
+	complianceCacheHandler := handler.NewComplianceCacheHandler(complianceCacheService)
+	fraudFlaggingService := service.NewFraudFlaggingService(transactionRepository, alertRepository)
+	fraudFlaggingHandler := handler.NewFraudFlaggingHandler(fraudFlaggingService, complianceCacheService)
+	disputeService := service.NewDisputeService(disputeRepository, transactionRepository, evidenceRepository, complianceRepository)
+	disputeHandler := handler.NewDisputeHandler(disputeService)
+	amlMonitoringService := service.NewAMLMonitoringService(transactionRepository, alertRepository,
+		service.NewStructuringScenario(),
+		service.NewVolumeSpikeScenario(),
+		service.NewRapidRefundScenario(),
+	)
+
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], amlMonitoringService, standInService); err != nil {
+			logging.Fatal("error running command", err)
+		}
+		return
+	}
+
+	verifier, err := auth.NewVerifier(auth.AudiencePayment)
+	if err != nil {
+		logging.Fatal("error setting up authentication", err)
+	}
+	if verifier == nil {
+		slog.Warn("authentication is disabled, the internal API is open to anyone reaching the service")
+	}
+	notify := auth.RequireScope(verifier, auth.ScopePaymentNotify)
+	read := auth.RequireScope(verifier, auth.ScopePaymentRead)
+	admin := auth.RequireScope(verifier, auth.ScopePaymentAdmin)
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	// The request ID, sent on by the calls to compliance-service, is logged with every record of the request.
+	app.Use(requestid.New(), logging.Middleware(), metrics.Middleware())
+	app.Get(metrics.Path, metrics.Handler())
+	// The unversioned routes of the first releases are deprecated aliases of the /v1 routes.
+	app.Use(api.LegacyAliases())
+
+	// The payments and the OpenAPI document are public, the routes called by compliance-service and the back-office
+	// routes need a token granting their scope.
+	v1 := app.Group(api.Prefix)
+	v1.Get("/openapi.yaml", api.Spec(openAPISpec))
+	v1.Post("/process_payment", paymentProcessorHandler.ProcessPayment)
+	v1.Put("/transactions/:id/review", admin, paymentProcessorHandler.ReviewPayment)
+	v1.Get("/users/:id/payments", read, paymentProcessorHandler.ListUserPayments)
+	v1.Post("/cards_reported", notify, fraudFlaggingHandler.CardsReported)
+	v1.Get("/alerts", admin, fraudFlaggingHandler.ListAlerts)
+	v1.Post("/compliance_cache/invalidations", notify, complianceCacheHandler.Invalidate)
+	v1.Get("/compliance_cache/stats", admin, complianceCacheHandler.Stats)
+
+	v1.Use("/disputes", admin)
+	v1.Post("/disputes", disputeHandler.OpenDispute)
+	v1.Get("/disputes/:id", disputeHandler.GetDispute)
+	v1.Put("/disputes/:id/stage", disputeHandler.AdvanceDispute)
+	v1.Put("/disputes/:id/resolution", disputeHandler.ResolveDispute)
+	v1.Post("/disputes/:id/evidence", disputeHandler.AddEvidence)
+
+	v1.Use("/webhooks", admin)
+	webhook.NewHandler(webhookService).RegisterRoutes(v1)
 
-	app.Post("/process_payment", paymentProcessorHandler.ProcessPayment)
+	go runStandInWorker(standInService)
+	go webhookService.RunDispatcher(context.Background())
 
-	log.Fatal(app.Listen(":8081"))
+	logging.Fatal("error serving", app.Listen(":8081"))
 }
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AlertTypeSuspectedFraud  = "suspected_fraud"
+	AlertTypeFraudRule       = "fraud_rule"
+	AlertTypeManualReview    = "manual_review"
+	AlertTypeStandInRejected = "stand_in_rejected"
+)
+
+// Alert is raised for a payment, or for a pattern of payments of a user, to be reviewed by an analyst. Evidence holds
+// the details supporting the alert as JSON. Alerts with a DedupKey are raised once per key.
+type Alert struct {
+	ID            int64           `json:"id"`
+	AlertType     string          `json:"alert_type"`
+	UserID        int64           `json:"user_id"`
+	CardID        int64           `json:"card_id"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	Message       string          `json:"message"`
+	Evidence      json.RawMessage `json:"evidence,omitempty"`
+	DedupKey      string          `json:"-"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source alert_repository.go -destination mock/alert_repository_mock.go -package mock
+type AlertRepository interface {
+	CreateAlert(alert Alert) error
+	CreateAlerts(alerts []Alert) (int, error)
+	ListAlerts(alertType string) ([]Alert, error)
+}
+
+type alertRepository struct {
+	db *sql.DB
+}
+
+func NewAlertRepository(db *sql.DB) AlertRepository {
+	return &alertRepository{db: db}
+}
+
+func (r *alertRepository) CreateAlert(alert Alert) error {
+	var transactionID sql.NullString
+	if alert.TransactionID != "" {
+		transactionID = sql.NullString{String: alert.TransactionID, Valid: true}
+	}
+
+	_, err := r.db.Exec("INSERT INTO alerts (alert_type, user_id, card_id, transaction_id, message) VALUES (?, ?, ?, ?, ?)",
+		alert.AlertType, alert.UserID, alert.CardID, transactionID, alert.Message)
+	return err
+}
+
+// CreateAlerts stores the alerts in one transaction, skipping those whose dedup key was already raised, and returns
+// how many were stored.
+func (r *alertRepository) CreateAlerts(alerts []Alert) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO alerts (alert_type, user_id, card_id, transaction_id, message, evidence, dedup_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	created := 0
+	for _, alert := range alerts {
+		var transactionID, evidence, dedupKey sql.NullString
+		if alert.TransactionID != "" {
+			transactionID = sql.NullString{String: alert.TransactionID, Valid: true}
+		}
+		if len(alert.Evidence) > 0 {
+			evidence = sql.NullString{String: string(alert.Evidence), Valid: true}
+		}
+		if alert.DedupKey != "" {
+			dedupKey = sql.NullString{String: alert.DedupKey, Valid: true}
+		}
+
+		result, err := stmt.Exec(alert.AlertType, alert.UserID, alert.CardID, transactionID, alert.Message, evidence, dedupKey)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		created += int(affected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+func (r *alertRepository) ListAlerts(alertType string) ([]Alert, error) {
+	query := "SELECT id, alert_type, user_id, card_id, transaction_id, message, evidence, created_at FROM alerts"
+	var args []any
+	if alertType != "" {
+		query += " WHERE alert_type = ?"
+		args = append(args, alertType)
+	}
+	query += " ORDER BY id DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var alert Alert
+		var transactionID, evidence sql.NullString
+		if err := rows.Scan(&alert.ID, &alert.AlertType, &alert.UserID, &alert.CardID, &transactionID, &alert.Message, &evidence, &alert.CreatedAt); err != nil {
+			return nil, err
+		}
+		alert.TransactionID = transactionID.String
+		if evidence.Valid {
+			alert.Evidence = json.RawMessage(evidence.String)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAlert(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      Alert
+		on         func(dbMock sqlmock.Sqlmock, in Alert)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:  "Success - Alert for a transaction",
+			input: Alert{AlertType: AlertTypeSuspectedFraud, UserID: 1, CardID: 2, TransactionID: "txn_1", Message: "suspected fraud"},
+			on: func(dbMock sqlmock.Sqlmock, in Alert) {
+				dbMock.ExpectExec(`INSERT INTO alerts \(alert_type, user_id, card_id, transaction_id, message\) VALUES \(\?, \?, \?, \?, \?\)`).
+					WithArgs(in.AlertType, in.UserID, in.CardID, sql.NullString{String: "txn_1", Valid: true}, in.Message).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:  "Failure - Database error",
+			input: Alert{AlertType: AlertTypeSuspectedFraud, UserID: 1, CardID: 2, Message: "suspected fraud"},
+			on: func(dbMock sqlmock.Sqlmock, in Alert) {
+				dbMock.ExpectExec(`INSERT INTO alerts`).
+					WithArgs(in.AlertType, in.UserID, in.CardID, sql.NullString{}, in.Message).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			alertRepository := NewAlertRepository(db)
+			tt.on(dbMock, tt.input)
+
+			err := alertRepository.CreateAlert(tt.input)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateAlerts(t *testing.T) {
+	type output struct {
+		created int
+		err     error
+	}
+
+	alerts := []Alert{
+		{AlertType: "aml_structuring", UserID: 1, CardID: 2, Message: "structuring", Evidence: []byte(`{"count":3}`), DedupKey: "structuring:1:abc"},
+		{AlertType: "aml_structuring", UserID: 1, CardID: 2, Message: "structuring", Evidence: []byte(`{"count":4}`), DedupKey: "structuring:1:def"},
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Alerts already raised skipped",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO alerts \(alert_type, user_id, card_id, transaction_id, message, evidence, dedup_key\)`)
+				stmt.ExpectExec().WithArgs("aml_structuring", int64(1), int64(2), sql.NullString{}, "structuring",
+					sql.NullString{String: `{"count":3}`, Valid: true}, sql.NullString{String: "structuring:1:abc", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				stmt.ExpectExec().WithArgs("aml_structuring", int64(1), int64(2), sql.NullString{}, "structuring",
+					sql.NullString{String: `{"count":4}`, Valid: true}, sql.NullString{String: "structuring:1:def", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, 1, out.created)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Exec error rolls back",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO alerts`)
+				stmt.ExpectExec().WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.created)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			alertRepository := NewAlertRepository(db)
+			tt.on(dbMock)
+
+			created, err := alertRepository.CreateAlerts(alerts)
+			tt.assertFunc(t, output{created, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListAlerts(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type output struct {
+		alerts []Alert
+		err    error
+	}
+
+	tests := []struct {
+		name       string
+		alertType  string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name:      "Success - Alerts filtered by type",
+			alertType: AlertTypeSuspectedFraud,
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT id, alert_type, user_id, card_id, transaction_id, message, evidence, created_at FROM alerts WHERE alert_type = \? ORDER BY id DESC`).
+					WithArgs(AlertTypeSuspectedFraud).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "alert_type", "user_id", "card_id", "transaction_id", "message", "evidence", "created_at"}).
+						AddRow(1, AlertTypeSuspectedFraud, 1, 2, "txn_1", "suspected fraud", nil, createdAt).
+						AddRow(2, AlertTypeSuspectedFraud, 1, 2, nil, "suspected fraud", `{"count":3}`, createdAt))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Len(t, out.alerts, 2)
+				assert.Equal(t, "txn_1", out.alerts[0].TransactionID)
+				assert.Nil(t, out.alerts[0].Evidence)
+				assert.Empty(t, out.alerts[1].TransactionID)
+				assert.JSONEq(t, `{"count":3}`, string(out.alerts[1].Evidence))
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM alerts ORDER BY id DESC`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.alerts)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			alertRepository := NewAlertRepository(db)
+			tt.on(dbMock)
+
+			alerts, err := alertRepository.ListAlerts(tt.alertType)
+			tt.assertFunc(t, output{alerts, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
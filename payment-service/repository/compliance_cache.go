@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultComplianceCacheSize = 10000
+	defaultComplianceCacheTTL  = time.Minute
+)
+
+// ComplianceCacheStats counts the lookups of the verdict cache since the service started. Invalidated counts the
+// verdicts dropped by an invalidation from compliance-service.
+type ComplianceCacheStats struct {
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	HitRatio    float64 `json:"hit_ratio"`
+	Evicted     uint64  `json:"evicted"`
+	Expired     uint64  `json:"expired"`
+	Invalidated uint64  `json:"invalidated"`
+	Entries     int     `json:"entries"`
+	Capacity    int     `json:"capacity"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source compliance_cache.go -destination mock/compliance_cache_mock.go -package mock -aux_files flarrocca/payment-service/repository=compliance_repository.go
+type CachedComplianceRepository interface {
+	ComplianceRepository
+	Invalidate(cards []BlockedCard)
+	InvalidateUsers(userIDs []int64)
+	InvalidateAll()
+	Stats() ComplianceCacheStats
+}
+
+// verdictKey holds everything compliance-service decides on but the amount, so a verdict is only reused for the same
+// payment details. The amount limits returned with the verdict are applied to the amount of each payment.
+type verdictKey struct {
+	userID     int64
+	cardID     int64
+	ipAddress  string
+	email      string
+	deviceID   string
+	merchantID string
+}
+
+type cachedVerdict struct {
+	key       verdictKey
+	response  ComplianceResponse
+	expiresAt time.Time
+}
+
+type cachedComplianceRepository struct {
+	ComplianceRepository
+
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[verdictKey]*list.Element
+	lru      *list.List
+	byCard   map[BlockedCard]map[verdictKey]struct{}
+	// generation changes on every invalidation. A verdict fetched while an invalidation happened is not cached, as it
+	// may have been computed before the change compliance-service is telling about.
+	generation uint64
+	stats      ComplianceCacheStats
+	now        func() time.Time
+}
+
+// NewCachedComplianceRepository keeps up to COMPLIANCE_CACHE_SIZE verdicts of the compliance checks for
+// COMPLIANCE_CACHE_TTL, a duration such as 30s. A size of 0 disables the cache. Errors are never cached.
+func NewCachedComplianceRepository(complianceRepository ComplianceRepository) CachedComplianceRepository {
+	capacity, err := strconv.Atoi(os.Getenv("COMPLIANCE_CACHE_SIZE"))
+	if err != nil || capacity < 0 {
+		capacity = defaultComplianceCacheSize
+	}
+
+	return newCachedComplianceRepository(complianceRepository, capacity, durationFromEnv("COMPLIANCE_CACHE_TTL", defaultComplianceCacheTTL))
+}
+
+func newCachedComplianceRepository(complianceRepository ComplianceRepository, capacity int, ttl time.Duration) *cachedComplianceRepository {
+	return &cachedComplianceRepository{
+		ComplianceRepository: complianceRepository,
+		capacity:             capacity,
+		ttl:                  ttl,
+		entries:              map[verdictKey]*list.Element{},
+		lru:                  list.New(),
+		byCard:               map[BlockedCard]map[verdictKey]struct{}{},
+		now:                  time.Now,
+	}
+}
+
+func (c *cachedComplianceRepository) CheckUserComplianceStatus(ctx context.Context, userID int64, cardID int64, amount float64, paymentContext *PaymentContext) (ComplianceResponse, error) {
+	key := newVerdictKey(userID, cardID, paymentContext)
+
+	response, found, generation := c.get(key, amount)
+	if found {
+		return response, nil
+	}
+
+	response, err := c.ComplianceRepository.CheckUserComplianceStatus(ctx, userID, cardID, amount, paymentContext)
+	if err != nil {
+		return response, err
+	}
+
+	c.put(key, response, generation)
+	return response, nil
+}
+
+// CheckUserComplianceStatuses answers the checks with a cached verdict from the cache and fetches the others in a batch.
+func (c *cachedComplianceRepository) CheckUserComplianceStatuses(ctx context.Context, checks []ComplianceCheck) ([]ComplianceResponse, error) {
+	responses := make([]ComplianceResponse, len(checks))
+	keys := make([]verdictKey, len(checks))
+	generations := make([]uint64, len(checks))
+	var missed []int
+	var misses []ComplianceCheck
+	for i, check := range checks {
+		keys[i] = newVerdictKey(check.UserID, check.CardID, check.PaymentContext)
+
+		response, found, generation := c.get(keys[i], check.Amount)
+		if found {
+			responses[i] = response
+			continue
+		}
+		generations[i] = generation
+		missed = append(missed, i)
+		misses = append(misses, check)
+	}
+	if len(misses) == 0 {
+		return responses, nil
+	}
+
+	fetched, err := c.ComplianceRepository.CheckUserComplianceStatuses(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range missed {
+		responses[i] = fetched[j]
+		c.put(keys[i], fetched[j], generations[i])
+	}
+	return responses, nil
+}
+
+func newVerdictKey(userID int64, cardID int64, paymentContext *PaymentContext) verdictKey {
+	key := verdictKey{userID: userID, cardID: cardID}
+	if paymentContext != nil {
+		key.ipAddress = paymentContext.IPAddress
+		key.email = paymentContext.Email
+		key.deviceID = paymentContext.DeviceID
+		key.merchantID = paymentContext.MerchantID
+	}
+	return key
+}
+
+// get returns the cached verdict for the amount, or the generation to pass to put once the verdict is fetched.
+func (c *cachedComplianceRepository) get(key verdictKey, amount float64) (ComplianceResponse, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[key]; found {
+		verdict := element.Value.(*cachedVerdict)
+		if c.now().Before(verdict.expiresAt) {
+			c.lru.MoveToFront(element)
+			c.stats.Hits++
+			if verdict.response.Limits != nil {
+				return verdict.response.Limits.verdict(verdict.response.RiskRating, amount), true, c.generation
+			}
+			return verdict.response, true, c.generation
+		}
+		c.remove(element)
+		c.stats.Expired++
+	}
+
+	c.stats.Misses++
+	return ComplianceResponse{}, false, c.generation
+}
+
+// put caches the verdict, unless it may not hold for another amount: a verdict allowing or holding the payment without
+// the limits it was decided on, as sent by an older compliance-service.
+func (c *cachedComplianceRepository) put(key verdictKey, response ComplianceResponse, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.capacity == 0 || generation != c.generation {
+		return
+	}
+	if (response.IsComplaiance || response.ManualReview) && response.Limits == nil {
+		return
+	}
+
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+	for c.lru.Len() >= c.capacity {
+		c.remove(c.lru.Back())
+		c.stats.Evicted++
+	}
+
+	c.entries[key] = c.lru.PushFront(&cachedVerdict{key: key, response: response, expiresAt: c.now().Add(c.ttl)})
+	card := BlockedCard{UserID: key.userID, CardID: key.cardID}
+	if c.byCard[card] == nil {
+		c.byCard[card] = map[verdictKey]struct{}{}
+	}
+	c.byCard[card][key] = struct{}{}
+}
+
+func (c *cachedComplianceRepository) remove(element *list.Element) {
+	verdict := c.lru.Remove(element).(*cachedVerdict)
+	delete(c.entries, verdict.key)
+
+	card := BlockedCard{UserID: verdict.key.userID, CardID: verdict.key.cardID}
+	delete(c.byCard[card], verdict.key)
+	if len(c.byCard[card]) == 0 {
+		delete(c.byCard, card)
+	}
+}
+
+// Invalidate drops the verdicts of the cards, whatever the amount and payment details.
+func (c *cachedComplianceRepository) Invalidate(cards []BlockedCard) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, card := range cards {
+		for key := range c.byCard[card] {
+			c.remove(c.entries[key])
+			c.stats.Invalidated++
+		}
+	}
+}
+
+// InvalidateUsers drops the verdicts of every card of the users, for changes to the user rather than to a card, such as
+// a confirmed sanctions hit, a KYC review or a new risk rating.
+func (c *cachedComplianceRepository) InvalidateUsers(userIDs []int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	users := make(map[int64]bool, len(userIDs))
+	for _, userID := range userIDs {
+		users[userID] = true
+	}
+	for card, keys := range c.byCard {
+		if !users[card.UserID] {
+			continue
+		}
+		for key := range keys {
+			c.remove(c.entries[key])
+			c.stats.Invalidated++
+		}
+	}
+}
+
+func (c *cachedComplianceRepository) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.stats.Invalidated += uint64(c.lru.Len())
+	c.entries = map[verdictKey]*list.Element{}
+	c.byCard = map[BlockedCard]map[verdictKey]struct{}{}
+	c.lru.Init()
+}
+
+func (c *cachedComplianceRepository) Stats() ComplianceCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Capacity = c.capacity
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLimits are the limits of every user of fakeComplianceChecker.
+var fakeLimits = PaymentLimits{KYCStatus: "verified", KYCTier: 1, KYCLimit: 100}
+
+// fakeComplianceChecker denies the payments of the cards in blocked, applies fakeLimits to the others and counts the
+// checks reaching it.
+type fakeComplianceChecker struct {
+	ComplianceRepository
+
+	mu      sync.Mutex
+	blocked map[BlockedCard]bool
+	calls   atomic.Int32
+	// withoutLimits returns the verdicts without their limits, as an older compliance-service.
+	withoutLimits bool
+	// inFlight, when set, is called with the verdict before it is returned, to interleave a check with other calls.
+	inFlight func(response ComplianceResponse)
+	err      error
+}
+
+func (f *fakeComplianceChecker) CheckUserComplianceStatus(ctx context.Context, userID int64, cardID int64, amount float64, paymentContext *PaymentContext) (ComplianceResponse, error) {
+	f.calls.Add(1)
+	if f.err != nil {
+		return ComplianceResponse{}, f.err
+	}
+
+	f.mu.Lock()
+	response := f.verdict(userID, cardID, amount)
+	f.mu.Unlock()
+
+	if f.inFlight != nil {
+		f.inFlight(response)
+	}
+	return response, nil
+}
+
+// CheckUserComplianceStatuses counts a batch as a single check.
+func (f *fakeComplianceChecker) CheckUserComplianceStatuses(ctx context.Context, checks []ComplianceCheck) ([]ComplianceResponse, error) {
+	f.calls.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	responses := make([]ComplianceResponse, 0, len(checks))
+	for _, check := range checks {
+		responses = append(responses, f.verdict(check.UserID, check.CardID, check.Amount))
+	}
+	return responses, nil
+}
+
+func (f *fakeComplianceChecker) verdict(userID int64, cardID int64, amount float64) ComplianceResponse {
+	if f.blocked[BlockedCard{UserID: userID, CardID: cardID}] {
+		return ComplianceResponse{Message: "user is currently blocked due to reported stolen card/s"}
+	}
+
+	response := fakeLimits.verdict("", amount)
+	if f.withoutLimits {
+		response.Limits = nil
+	}
+	return response
+}
+
+func (f *fakeComplianceChecker) block(card BlockedCard, blocked bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocked[card] = blocked
+}
+
+func TestComplianceCache(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	paymentContext := &PaymentContext{IPAddress: "192.0.2.10", MerchantID: "grocer-001", UserAgent: "curl/8.0"}
+
+	tests := []struct {
+		name       string
+		run        func(cache *cachedComplianceRepository, checker *fakeComplianceChecker)
+		calls      int32
+		assertFunc func(t *testing.T, stats ComplianceCacheStats)
+	}{
+		{
+			name: "Success - Same payment served from the cache",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, paymentContext)
+				// the user agent is not sent to compliance-service
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, &PaymentContext{IPAddress: "192.0.2.10", MerchantID: "grocer-001"})
+			},
+			calls: 1,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, ComplianceCacheStats{Hits: 1, Misses: 1, HitRatio: 0.5, Entries: 1, Capacity: 2}, stats)
+			},
+		},
+		{
+			name: "Success - Limits of the cached verdict applied to other amounts",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, paymentContext)
+				response, _ := cache.CheckUserComplianceStatus(context.Background(), 1, 2, 100, paymentContext)
+				assert.True(t, response.IsComplaiance)
+				response, _ = cache.CheckUserComplianceStatus(context.Background(), 1, 2, 150, paymentContext)
+				assert.False(t, response.IsComplaiance)
+				assert.Equal(t, "payment amount 150.00 exceeds the 100.00 limit of KYC tier 1 (verified), a higher verification tier is required",
+					response.Message)
+				// a denial over the limit is reapplied to lower amounts too
+				cache.InvalidateAll()
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 150, paymentContext)
+				response, _ = cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, paymentContext)
+				assert.True(t, response.IsComplaiance)
+			},
+			calls: 2,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(3), stats.Hits)
+				assert.Equal(t, uint64(2), stats.Misses)
+			},
+		},
+		{
+			name: "Success - Other payment details checked again",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, paymentContext)
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 1, 3, 10, nil)
+			},
+			calls: 3,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(3), stats.Misses)
+				assert.Equal(t, uint64(1), stats.Evicted)
+			},
+		},
+		{
+			name: "Success - Denial not depending on the amount reused for any amount",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				checker.block(BlockedCard{UserID: 1, CardID: 2}, true)
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				response, _ := cache.CheckUserComplianceStatus(context.Background(), 1, 2, 20, nil)
+				assert.Equal(t, "user is currently blocked due to reported stolen card/s", response.Message)
+			},
+			calls: 1,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(1), stats.Hits)
+			},
+		},
+		{
+			name: "Success - Approval without limits not cached",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				checker.withoutLimits = true
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+			},
+			calls: 2,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, 0, stats.Entries)
+			},
+		},
+		{
+			name: "Success - Least recently used verdict evicted",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 2, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 3, 3, 10, nil)
+				// user 1 was used more recently than user 2
+				cache.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 2, 2, 10, nil)
+			},
+			calls: 4,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(2), stats.Hits)
+				assert.Equal(t, uint64(2), stats.Evicted)
+				assert.Equal(t, 2, stats.Entries)
+			},
+		},
+		{
+			name: "Success - Expired verdict checked again",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				now = now.Add(time.Minute)
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+			},
+			calls: 2,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(1), stats.Expired)
+				assert.Equal(t, 1, stats.Entries)
+			},
+		},
+		{
+			name: "Success - Invalidated card checked again, other cards kept",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 3, 4, 10, nil)
+				cache.Invalidate([]BlockedCard{{UserID: 1, CardID: 2}})
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 3, 4, 10, nil)
+			},
+			calls: 3,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(1), stats.Hits)
+				assert.Equal(t, uint64(1), stats.Invalidated)
+			},
+		},
+		{
+			name: "Success - Every card of an invalidated user checked again, other users kept",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 3, 4, 10, nil)
+				cache.InvalidateUsers([]int64{1})
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 3, 4, 10, nil)
+			},
+			calls: 3,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(1), stats.Hits)
+				assert.Equal(t, uint64(1), stats.Invalidated)
+			},
+		},
+		{
+			name: "Success - Every verdict invalidated",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				cache.CheckUserComplianceStatus(context.Background(), 3, 4, 10, nil)
+				cache.InvalidateAll()
+				cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+			},
+			calls: 3,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(2), stats.Invalidated)
+				assert.Equal(t, 1, stats.Entries)
+			},
+		},
+		{
+			name: "Failure - Errors not cached",
+			run: func(cache *cachedComplianceRepository, checker *fakeComplianceChecker) {
+				checker.err = ErrComplianceUnavailable
+				_, err := cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				assert.ErrorIs(t, err, ErrComplianceUnavailable)
+				checker.err = nil
+				response, err := cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+				assert.NoError(t, err)
+				assert.True(t, response.IsComplaiance)
+			},
+			calls: 2,
+			assertFunc: func(t *testing.T, stats ComplianceCacheStats) {
+				assert.Equal(t, uint64(2), stats.Misses)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &fakeComplianceChecker{blocked: map[BlockedCard]bool{}}
+			cache := newCachedComplianceRepository(checker, 2, time.Minute)
+			cache.now = func() time.Time { return now }
+
+			tt.run(cache, checker)
+
+			assert.Equal(t, tt.calls, checker.calls.Load())
+			tt.assertFunc(t, cache.Stats())
+		})
+	}
+}
+
+func TestComplianceCacheDisabled(t *testing.T) {
+	checker := &fakeComplianceChecker{blocked: map[BlockedCard]bool{}}
+	cache := newCachedComplianceRepository(checker, 0, time.Minute)
+
+	cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+	cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+
+	assert.Equal(t, int32(2), checker.calls.Load())
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+// A card reported while its check is in flight: the verdict computed before the report must not be cached once the
+// invalidation was received.
+func TestComplianceCacheInvalidationDuringCheck(t *testing.T) {
+	card := BlockedCard{UserID: 1, CardID: 2}
+	checker := &fakeComplianceChecker{blocked: map[BlockedCard]bool{}}
+	cache := newCachedComplianceRepository(checker, 10, time.Minute)
+
+	checker.inFlight = func(response ComplianceResponse) {
+		checker.inFlight = nil
+		checker.block(card, true)
+		cache.Invalidate([]BlockedCard{card})
+	}
+
+	response, err := cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+	assert.NoError(t, err)
+	assert.True(t, response.IsComplaiance, "the payment checked before the report goes through")
+
+	response, err = cache.CheckUserComplianceStatus(context.Background(), 1, 2, 10, nil)
+	assert.NoError(t, err)
+	assert.False(t, response.IsComplaiance, "the stale verdict must not be served")
+	assert.Equal(t, int32(2), checker.calls.Load())
+}
+
+// Payments and reports racing on the same cards: once a report is invalidated, no payment checked afterwards may be
+// approved from the cache. Run with -race to also check the locking.
+func TestComplianceCacheConcurrentReports(t *testing.T) {
+	const cards = 8
+	checker := &fakeComplianceChecker{blocked: map[BlockedCard]bool{}}
+	cache := newCachedComplianceRepository(checker, 4, time.Minute)
+
+	var reported [cards]atomic.Bool
+	var wg sync.WaitGroup
+	var violations atomic.Int32
+	stop := make(chan struct{})
+
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cardID := int64((worker + i) % cards)
+				wasReported := reported[cardID].Load()
+				response, err := cache.CheckUserComplianceStatus(context.Background(), 1, cardID, float64(i%3), nil)
+				if err != nil || (wasReported && response.IsComplaiance) {
+					violations.Add(1)
+				}
+			}
+		}(worker)
+	}
+
+	for cardID := int64(0); cardID < cards; cardID++ {
+		time.Sleep(2 * time.Millisecond)
+		card := BlockedCard{UserID: 1, CardID: cardID}
+		checker.block(card, true)
+		cache.Invalidate([]BlockedCard{card})
+		reported[cardID].Store(true)
+	}
+	time.Sleep(2 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	assert.Zero(t, violations.Load())
+	for cardID := int64(0); cardID < cards; cardID++ {
+		response, err := cache.CheckUserComplianceStatus(context.Background(), 1, cardID, 0, nil)
+		assert.NoError(t, err)
+		assert.False(t, response.IsComplaiance)
+	}
+
+	stats := cache.Stats()
+	assert.LessOrEqual(t, stats.Entries, 4)
+	assert.Equal(t, uint64(checker.calls.Load())+stats.Hits, stats.Hits+stats.Misses)
+}
+
+func TestComplianceCacheBatch(t *testing.T) {
+	checker := &fakeComplianceChecker{blocked: map[BlockedCard]bool{{UserID: 2, CardID: 5}: true}}
+	cache := newCachedComplianceRepository(checker, 10, time.Minute)
+
+	_, err := cache.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+	assert.NoError(t, err)
+
+	// only the payments missing from the cache are sent, in a single batch
+	responses, err := cache.CheckUserComplianceStatuses(context.Background(), []ComplianceCheck{{UserID: 1, CardID: 1, Amount: 10}, {UserID: 2, CardID: 5, Amount: 10},
+		{UserID: 3, CardID: 7, Amount: 10}})
+	assert.NoError(t, err)
+	assert.Equal(t, []ComplianceResponse{
+		{IsComplaiance: true, Message: "user is compliance", Limits: &fakeLimits},
+		{Message: "user is currently blocked due to reported stolen card/s"},
+		{IsComplaiance: true, Message: "user is compliance", Limits: &fakeLimits},
+	}, responses)
+	assert.Equal(t, int32(2), checker.calls.Load())
+
+	responses, err = cache.CheckUserComplianceStatuses(context.Background(), []ComplianceCheck{{UserID: 2, CardID: 5, Amount: 10}, {UserID: 3, CardID: 7, Amount: 10}})
+	assert.NoError(t, err)
+	assert.Len(t, responses, 2)
+	assert.Equal(t, int32(2), checker.calls.Load())
+	assert.Equal(t, uint64(3), cache.Stats().Hits)
+
+	checker.err = ErrComplianceUnavailable
+	responses, err = cache.CheckUserComplianceStatuses(context.Background(), []ComplianceCheck{{UserID: 4, CardID: 9, Amount: 10}})
+	assert.ErrorIs(t, err, ErrComplianceUnavailable)
+	assert.Nil(t, responses)
+	assert.Equal(t, 3, cache.Stats().Entries)
+}
+
+func TestComplianceCachePassThrough(t *testing.T) {
+	checker := &fakeComplianceChecker{blocked: map[BlockedCard]bool{}}
+	checker.ComplianceRepository = &fakeBlockedCardLister{cards: []BlockedCard{{UserID: 3, CardID: 7}}}
+	cache := newCachedComplianceRepository(checker, 10, time.Minute)
+
+	cards, err := cache.ListBlockedCards(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockedCard{{UserID: 3, CardID: 7}}, cards)
+}
+
+type fakeBlockedCardLister struct {
+	ComplianceRepository
+	cards []BlockedCard
+}
+
+func (f *fakeBlockedCardLister) ListBlockedCards(ctx context.Context) ([]BlockedCard, error) {
+	return f.cards, nil
+}
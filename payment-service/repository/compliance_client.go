@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	defaultComplianceTimeout         = 2 * time.Second
+	defaultComplianceMaxRetries      = 2
+	defaultComplianceRetryBackoff    = 100 * time.Millisecond
+	defaultComplianceMaxRetryBackoff = time.Second
+	defaultComplianceBreakerFailures = 5
+	defaultComplianceBreakerCooldown = 30 * time.Second
+
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half_open"
+)
+
+// Operations of the calls to compliance-service, labelling their metrics.
+const (
+	operationCheckUser         = "check_user"
+	operationCheckUsers        = "check_users"
+	operationRequestCardReview = "request_card_review"
+	operationListBlockedCards  = "list_blocked_cards"
+)
+
+var (
+	complianceRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "compliance_client_request_duration_seconds",
+		Help:    "Duration of the attempts to call compliance-service, by operation and result (ok, unavailable, failed or cancelled).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	complianceErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "compliance_client_errors_total",
+		Help: "Failed attempts to call compliance-service, by operation and error (unavailable, failed, cancelled or circuit_open).",
+	}, []string{"operation", "error"})
+)
+
+var (
+	// ErrComplianceUnavailable means compliance-service could not be reached, failed or did not answer in time: the
+	// payment was not checked, unlike a payment compliance-service denied.
+	ErrComplianceUnavailable = errors.New("compliance service unavailable")
+	// ErrCircuitOpen is returned, together with ErrComplianceUnavailable, without calling compliance-service while the
+	// circuit breaker is open.
+	ErrCircuitOpen = errors.New("circuit breaker open")
+	// ErrComplianceRequestFailed means compliance-service rejected the request or answered with an unexpected body,
+	// which retrying would not fix.
+	ErrComplianceRequestFailed = errors.New("compliance request failed")
+)
+
+// complianceCaller runs the calls to compliance-service through a circuit breaker, each attempt bounded by timeout.
+// Idempotent calls are retried on ErrComplianceUnavailable with a jittered exponential backoff.
+type complianceCaller struct {
+	timeout         time.Duration
+	maxRetries      int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	breaker         *circuitBreaker
+}
+
+// newComplianceCaller reads COMPLIANCE_TIMEOUT, COMPLIANCE_RETRY_BACKOFF and COMPLIANCE_BREAKER_COOLDOWN as durations,
+// e.g. 500ms, and COMPLIANCE_MAX_RETRIES and COMPLIANCE_BREAKER_FAILURES as integers.
+func newComplianceCaller() complianceCaller {
+	maxRetries, err := strconv.Atoi(os.Getenv("COMPLIANCE_MAX_RETRIES"))
+	if err != nil || maxRetries < 0 {
+		maxRetries = defaultComplianceMaxRetries
+	}
+
+	breakerFailures, err := strconv.Atoi(os.Getenv("COMPLIANCE_BREAKER_FAILURES"))
+	if err != nil || breakerFailures <= 0 {
+		breakerFailures = defaultComplianceBreakerFailures
+	}
+
+	return complianceCaller{
+		timeout:         durationFromEnv("COMPLIANCE_TIMEOUT", defaultComplianceTimeout),
+		maxRetries:      maxRetries,
+		retryBackoff:    durationFromEnv("COMPLIANCE_RETRY_BACKOFF", defaultComplianceRetryBackoff),
+		maxRetryBackoff: defaultComplianceMaxRetryBackoff,
+		breaker:         newCircuitBreaker(breakerFailures, durationFromEnv("COMPLIANCE_BREAKER_COOLDOWN", defaultComplianceBreakerCooldown)),
+	}
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// call makes a single attempt of the operation, within the timeout and the context of the request. Only
+// ErrComplianceUnavailable counts as a failure for the circuit breaker, a rejected request still shows
+// compliance-service is up. An attempt failing because the context of the request was cancelled, or expired, says
+// nothing about compliance-service: it is returned as ErrComplianceRequestFailed, not retried and not counted by the
+// breaker. The attempts are timed, the calls the open circuit breaker rejects only counted.
+func (c complianceCaller) call(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	if !c.breaker.allow() {
+		complianceErrorsTotal.WithLabelValues(operation, "circuit_open").Inc()
+		return fmt.Errorf("%w: %w", ErrComplianceUnavailable, ErrCircuitOpen)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(attemptCtx)
+	result := "ok"
+	switch {
+	case err != nil && ctx.Err() != nil:
+		result = "cancelled"
+		err = cancelledError(ctx)
+	case errors.Is(err, ErrComplianceUnavailable):
+		result = "unavailable"
+	case err != nil:
+		result = "failed"
+	}
+	complianceRequestDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+	if err != nil {
+		complianceErrorsTotal.WithLabelValues(operation, result).Inc()
+	}
+
+	switch result {
+	case "cancelled":
+		c.breaker.release()
+	case "unavailable":
+		c.breaker.failure()
+	default:
+		c.breaker.success()
+	}
+	return err
+}
+
+// callWithRetries retries fn, which must be idempotent, up to maxRetries times. It stops as soon as the circuit
+// breaker opens or the context of the request is done, without waiting for the end of the backoff.
+func (c complianceCaller) callWithRetries(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := c.call(ctx, operation, fn)
+		if !errors.Is(err, ErrComplianceUnavailable) || errors.Is(err, ErrCircuitOpen) || attempt >= c.maxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return cancelledError(ctx)
+		case <-timer.C:
+		}
+	}
+}
+
+func cancelledError(ctx context.Context) error {
+	return fmt.Errorf("%w: request cancelled: %w", ErrComplianceRequestFailed, ctx.Err())
+}
+
+// backoff doubles the wait after each attempt, up to maxRetryBackoff, and picks it at random in its upper half so
+// clients failing together do not retry together.
+func (c complianceCaller) backoff(attempt int) time.Duration {
+	wait := c.maxRetryBackoff
+	if attempt < 32 && c.retryBackoff<<attempt < wait {
+		wait = c.retryBackoff << attempt
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// circuitBreaker stops calling a failing dependency. After maxFailures consecutive failures it opens and rejects the
+// calls for cooldown, then lets a single probe through (half-open): the breaker closes when the probe succeeds and
+// opens again when it fails.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	maxFailures int
+	cooldown    time.Duration
+	now         func() time.Time
+	state       string
+	failures    int
+	openedAt    time.Time
+	probing     bool
+}
+
+func newCircuitBreaker(maxFailures int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		now:         time.Now,
+		state:       circuitClosed,
+	}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		return true
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = circuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// release ends the probe of a call that did not tell whether the dependency recovered, leaving the state unchanged.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == circuitHalfOpen || b.failures >= b.maxFailures {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestComplianceCaller retries without waiting and opens the circuit breaker only after many failures, so tests
+// not about the breaker are not affected by it.
+func newTestComplianceCaller(timeout time.Duration, maxRetries int) complianceCaller {
+	return complianceCaller{
+		timeout:         timeout,
+		maxRetries:      maxRetries,
+		retryBackoff:    time.Millisecond,
+		maxRetryBackoff: time.Millisecond,
+		breaker:         newCircuitBreaker(100, time.Minute),
+	}
+}
+
+func TestCheckUserComplianceStatusResilience(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		maxRetries    int
+		handler       func(attempt int32) (status int, body string, delay time.Duration)
+		expectedCalls int32
+		assertFunc    func(t *testing.T, response ComplianceResponse, err error)
+	}{
+		{
+			name:       "Success - Retried after a 503",
+			timeout:    time.Second,
+			maxRetries: 2,
+			handler: func(attempt int32) (int, string, time.Duration) {
+				if attempt == 1 {
+					return http.StatusServiceUnavailable, "", 0
+				}
+				return http.StatusOK, `{"compliant": true, "message": "user is compliant"}`, 0
+			},
+			expectedCalls: 2,
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.NoError(t, err)
+				assert.True(t, response.IsComplaiance)
+			},
+		},
+		{
+			name:       "Failure - Retries exhausted",
+			timeout:    time.Second,
+			maxRetries: 2,
+			handler: func(attempt int32) (int, string, time.Duration) {
+				return http.StatusBadGateway, "", 0
+			},
+			expectedCalls: 3,
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.EqualError(t, err, "compliance service unavailable: compliance service returned status code: 502")
+			},
+		},
+		{
+			name:       "Failure - Rejected request not retried",
+			timeout:    time.Second,
+			maxRetries: 2,
+			handler: func(attempt int32) (int, string, time.Duration) {
+				return http.StatusBadRequest, `{"message": "invalid amount"}`, 0
+			},
+			expectedCalls: 1,
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.ErrorIs(t, err, ErrComplianceRequestFailed)
+			},
+		},
+		{
+			name:       "Failure - Each attempt bounded by the timeout",
+			timeout:    20 * time.Millisecond,
+			maxRetries: 1,
+			handler: func(attempt int32) (int, string, time.Duration) {
+				return http.StatusOK, `{"compliant": true}`, 200 * time.Millisecond
+			},
+			expectedCalls: 2,
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.ErrorIs(t, err, ErrComplianceUnavailable)
+				assert.EqualError(t, err, "compliance service unavailable: no response within 20ms")
+				assert.Equal(t, ComplianceResponse{}, response)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status, body, delay := tt.handler(calls.Add(1))
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			complianceRepository := newTestComplianceRepository(server.URL, tt.timeout, tt.maxRetries)
+			response, err := complianceRepository.CheckUserComplianceStatus(context.Background(), 1, 1, 100.5, nil)
+
+			tt.assertFunc(t, response, err)
+			assert.Equal(t, tt.expectedCalls, calls.Load())
+		})
+	}
+}
+
+func TestCheckUserComplianceStatusCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"compliant": true, "message": "user is compliant"}`))
+	}))
+	defer server.Close()
+
+	complianceRepository := newTestComplianceRepository(server.URL, time.Second, 2)
+	complianceRepository.caller.breaker = newCircuitBreaker(3, 30*time.Second)
+	complianceRepository.caller.breaker.now = func() time.Time { return now }
+
+	// the third failed attempt opens the breaker, no more requests are sent
+	_, err := complianceRepository.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+	assert.ErrorIs(t, err, ErrComplianceUnavailable)
+	assert.Equal(t, int32(3), calls.Load())
+
+	_, err = complianceRepository.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.EqualError(t, err, "compliance service unavailable: circuit breaker open")
+	assert.Equal(t, int32(3), calls.Load())
+
+	// after the cooldown a failed probe opens the breaker again, without retrying
+	now = now.Add(30 * time.Second)
+	_, err = complianceRepository.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Equal(t, circuitOpen, complianceRepository.caller.breaker.state)
+
+	// a successful probe closes it
+	healthy.Store(true)
+	now = now.Add(30 * time.Second)
+	response, err := complianceRepository.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+	assert.NoError(t, err)
+	assert.True(t, response.IsComplaiance)
+	assert.Equal(t, int32(5), calls.Load())
+	assert.Equal(t, circuitClosed, complianceRepository.caller.breaker.state)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	// a success resets the consecutive failures
+	breaker.failure()
+	breaker.success()
+	breaker.failure()
+	assert.True(t, breaker.allow())
+	assert.Equal(t, circuitClosed, breaker.state)
+
+	breaker.failure()
+	assert.Equal(t, circuitOpen, breaker.state)
+	assert.False(t, breaker.allow())
+
+	// a single probe once the cooldown is over
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.allow())
+	assert.Equal(t, circuitHalfOpen, breaker.state)
+	assert.False(t, breaker.allow())
+
+	breaker.success()
+	assert.Equal(t, circuitClosed, breaker.state)
+	assert.True(t, breaker.allow())
+	assert.True(t, breaker.allow())
+}
+
+func TestComplianceCallerBackoff(t *testing.T) {
+	caller := complianceCaller{retryBackoff: 100 * time.Millisecond, maxRetryBackoff: time.Second}
+
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 2, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{attempt: 5, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 70, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			wait := caller.backoff(tt.attempt)
+			assert.GreaterOrEqual(t, wait, tt.min)
+			assert.LessOrEqual(t, wait, tt.max)
+		}
+	}
+}
+
+func TestComplianceCallerCancelled(t *testing.T) {
+	t.Run("Failure - Backoff interrupted by the cancelled request", func(t *testing.T) {
+		caller := newTestComplianceCaller(time.Second, 2)
+		caller.retryBackoff, caller.maxRetryBackoff = time.Hour, time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		start := time.Now()
+		err := caller.callWithRetries(ctx, operationCheckUser, func(ctx context.Context) error {
+			calls++
+			return ErrComplianceUnavailable
+		})
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, 1, calls)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, err, ErrComplianceRequestFailed)
+		assert.NotErrorIs(t, err, ErrComplianceUnavailable)
+	})
+
+	t.Run("Failure - Cancelled request not counted by the circuit breaker", func(t *testing.T) {
+		now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+		caller := newTestComplianceCaller(time.Second, 2)
+		caller.breaker = newCircuitBreaker(1, time.Minute)
+		caller.breaker.now = func() time.Time { return now }
+
+		calls := 0
+		cancelled := func(ctx context.Context) error {
+			calls++
+			return fmt.Errorf("%w: no response within 1s", ErrComplianceUnavailable)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := caller.callWithRetries(ctx, operationCheckUser, cancelled)
+		assert.EqualError(t, err, "compliance request failed: request cancelled: context canceled")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, circuitClosed, caller.breaker.state)
+
+		// a cancelled probe leaves the breaker half-open, for the next call to probe again
+		caller.breaker.failure()
+		now = now.Add(time.Minute)
+		assert.ErrorIs(t, caller.call(ctx, operationCheckUser, cancelled), context.Canceled)
+		assert.Equal(t, circuitHalfOpen, caller.breaker.state)
+		assert.True(t, caller.breaker.allow())
+	})
+}
+
+// sampleCount returns the number of observations of the histogram.
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	assert.NoError(t, observer.(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestComplianceCallerMetrics(t *testing.T) {
+	caller := newTestComplianceCaller(time.Second, 1)
+	caller.breaker = newCircuitBreaker(2, time.Minute)
+	ok := complianceRequestDuration.WithLabelValues(operationListBlockedCards, "ok")
+	unavailable := complianceErrorsTotal.WithLabelValues(operationListBlockedCards, "unavailable")
+	failed := complianceErrorsTotal.WithLabelValues(operationListBlockedCards, "failed")
+	circuitOpen := complianceErrorsTotal.WithLabelValues(operationListBlockedCards, "circuit_open")
+	okBefore := sampleCount(t, ok)
+	unavailableBefore, failedBefore, circuitOpenBefore := testutil.ToFloat64(unavailable), testutil.ToFloat64(failed), testutil.ToFloat64(circuitOpen)
+
+	assert.NoError(t, caller.callWithRetries(context.Background(), operationListBlockedCards, func(ctx context.Context) error {
+		return nil
+	}))
+	assert.ErrorIs(t, caller.call(context.Background(), operationListBlockedCards, func(ctx context.Context) error {
+		return ErrComplianceRequestFailed
+	}), ErrComplianceRequestFailed)
+	// both attempts fail and open the breaker, which rejects the next call without timing it
+	assert.ErrorIs(t, caller.callWithRetries(context.Background(), operationListBlockedCards, func(ctx context.Context) error {
+		return ErrComplianceUnavailable
+	}), ErrComplianceUnavailable)
+	assert.ErrorIs(t, caller.call(context.Background(), operationListBlockedCards, func(ctx context.Context) error {
+		return nil
+	}), ErrCircuitOpen)
+
+	assert.Equal(t, okBefore+1, sampleCount(t, ok))
+	assert.Equal(t, unavailableBefore+2, testutil.ToFloat64(unavailable))
+	assert.Equal(t, failedBefore+1, testutil.ToFloat64(failed))
+	assert.Equal(t, circuitOpenBefore+1, testutil.ToFloat64(circuitOpen))
+}
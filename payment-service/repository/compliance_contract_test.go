@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"flarrocca/contract"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pactDir is where the pact of payment-service with compliance-service is written, verified by the test suite of
+// compliance-service.
+var pactDir = filepath.Join("..", "..", contract.Dir)
+
+// messageRule only requires the messages of the verdicts to be strings, as they are recorded as the decline reason
+// without being parsed.
+var messageRule = map[string]contract.Rule{"$.body.message": {Match: contract.MatchType}}
+
+// limitRules only require the amount limits of the verdicts to be numbers, as they depend on the configuration of
+// compliance-service.
+var limitRules = map[string]contract.Rule{
+	"$.body.message":                {Match: contract.MatchType},
+	"$.body.limits.kyc_limit":       {Match: contract.MatchType},
+	"$.body.limits.high_risk_limit": {Match: contract.MatchType},
+	"$.body.limits.review_amount":   {Match: contract.MatchType},
+}
+
+func TestComplianceContract(t *testing.T) {
+	tests := []struct {
+		interaction contract.Interaction
+		assertFunc  func(t *testing.T, repository ComplianceRepository)
+	}{
+		{
+			interaction: contract.Interaction{
+				Description:   "a compliance check of a card the user owns",
+				ProviderState: "user 1 owns card 1",
+				Request:       contract.Request{Method: http.MethodGet, Path: "/v1/check_user", Query: "user_id=1&card_id=1&amount=100.5"},
+				Response: contract.Response{
+					Status: http.StatusOK,
+					Body: json.RawMessage(`{"compliant": true, "message": "user is compliance", "risk_rating": "low",
+						"limits": {"kyc_status": "unverified", "kyc_tier": 0, "kyc_limit": 150}}`),
+					MatchingRules: limitRules,
+				},
+			},
+			assertFunc: func(t *testing.T, repository ComplianceRepository) {
+				response, err := repository.CheckUserComplianceStatus(context.Background(), 1, 1, 100.5, nil)
+				assert.NoError(t, err)
+				assert.Equal(t, ComplianceResponse{IsComplaiance: true, Message: "user is compliance", RiskRating: "low",
+					Limits: &PaymentLimits{KYCStatus: "unverified", KYCLimit: 150}}, response)
+			},
+		},
+		{
+			interaction: contract.Interaction{
+				Description:   "a compliance check of a card the user does not own",
+				ProviderState: "user 1 owns card 1",
+				Request:       contract.Request{Method: http.MethodGet, Path: "/v1/check_user", Query: "user_id=1&card_id=3&amount=20"},
+				Response: contract.Response{
+					Status:        http.StatusOK,
+					Body:          json.RawMessage(`{"compliant": false, "message": "the provided card does not belong to the user"}`),
+					MatchingRules: messageRule,
+				},
+			},
+			assertFunc: func(t *testing.T, repository ComplianceRepository) {
+				response, err := repository.CheckUserComplianceStatus(context.Background(), 1, 3, 20, nil)
+				assert.NoError(t, err)
+				assert.False(t, response.IsComplaiance)
+				assert.False(t, response.ManualReview)
+				assert.Equal(t, "the provided card does not belong to the user", response.Message)
+			},
+		},
+		{
+			interaction: contract.Interaction{
+				Description:   "a compliance check of a payment of a high-risk user requiring a review",
+				ProviderState: "user 2 is rated high risk",
+				Request: contract.Request{Method: http.MethodGet, Path: "/v1/check_user",
+					Query: "user_id=2&card_id=3&amount=750&merchant_id=streaming-001"},
+				Response: contract.Response{
+					Status: http.StatusOK,
+					Body: json.RawMessage(`{"compliant": false, "message": "payment amount 750.00 of a high-risk user requires manual review", "risk_rating": "high", "manual_review": true,
+						"limits": {"kyc_status": "verified", "kyc_tier": 2, "high_risk_limit": 1000, "review_amount": 500}}`),
+					MatchingRules: limitRules,
+				},
+			},
+			assertFunc: func(t *testing.T, repository ComplianceRepository) {
+				response, err := repository.CheckUserComplianceStatus(context.Background(), 2, 3, 750, &PaymentContext{MerchantID: "streaming-001"})
+				assert.NoError(t, err)
+				assert.False(t, response.IsComplaiance)
+				assert.True(t, response.ManualReview)
+				assert.Equal(t, "high", response.RiskRating)
+				assert.Equal(t, &PaymentLimits{KYCStatus: "verified", KYCTier: 2, HighRiskLimit: 1000, ReviewAmount: 500}, response.Limits)
+			},
+		},
+		{
+			interaction: contract.Interaction{
+				Description:   "a batch compliance check",
+				ProviderState: "user 1 owns card 1",
+				Request: contract.Request{
+					Method: http.MethodPost,
+					Path:   "/v1/check_users",
+					Body:   json.RawMessage(`{"checks": [{"user_id": 1, "card_id": 1, "amount": 49.99, "email": "john@example.com"}, {"user_id": 1, "card_id": 3, "amount": 10}]}`),
+				},
+				Response: contract.Response{
+					Status: http.StatusOK,
+					Body: json.RawMessage(`{"results": [{"compliant": true, "message": "user is compliance", "risk_rating": "low",
+						"limits": {"kyc_status": "unverified", "kyc_tier": 0, "kyc_limit": 150}},
+						{"compliant": false, "message": "the provided card does not belong to the user"}]}`),
+					MatchingRules: map[string]contract.Rule{
+						"$.body.results[0].message":          {Match: contract.MatchType},
+						"$.body.results[0].limits.kyc_limit": {Match: contract.MatchType},
+						"$.body.results[1].message":          {Match: contract.MatchType},
+					},
+				},
+			},
+			assertFunc: func(t *testing.T, repository ComplianceRepository) {
+				responses, err := repository.CheckUserComplianceStatuses(context.Background(), []ComplianceCheck{
+					{UserID: 1, CardID: 1, Amount: 49.99, PaymentContext: &PaymentContext{Email: "john@example.com"}},
+					{UserID: 1, CardID: 3, Amount: 10},
+				})
+				assert.NoError(t, err)
+				assert.Len(t, responses, 2)
+				assert.True(t, responses[0].IsComplaiance)
+				assert.Equal(t, "low", responses[0].RiskRating)
+				assert.NotNil(t, responses[0].Limits)
+				assert.False(t, responses[1].IsComplaiance)
+				assert.Nil(t, responses[1].Limits)
+			},
+		},
+		{
+			interaction: contract.Interaction{
+				Description:   "a request to review the card of a disputed transaction",
+				ProviderState: "user 1 owns card 1",
+				Request: contract.Request{
+					Method: http.MethodPost,
+					Path:   "/v1/cases",
+					Body: json.RawMessage(`{"card_id": 1, "source": "chargeback",
+						"transactions": [{"amount": 100.5, "card_id": 1, "occurred_at": "2025-02-27T18:30:00Z", "transaction_id": "txn_123456"}], "user_id": 1}`),
+				},
+				Response: contract.Response{Status: http.StatusCreated},
+			},
+			assertFunc: func(t *testing.T, repository ComplianceRepository) {
+				err := repository.RequestCardReview(context.Background(), CaseSourceChargeback,
+					Transaction{ID: "txn_123456", UserID: 1, CardID: 1, Amount: 100.5, CreatedAt: time.Date(2025, 2, 27, 18, 30, 0, 0, time.UTC)})
+				assert.NoError(t, err)
+			},
+		},
+		{
+			interaction: contract.Interaction{
+				Description:   "a request to review the card of a high-risk payment held for review",
+				ProviderState: "user 2 is rated high risk",
+				Request: contract.Request{
+					Method: http.MethodPost,
+					Path:   "/v1/cases",
+					Body: json.RawMessage(`{"card_id": 3, "source": "high_risk_payment",
+						"transactions": [{"amount": 750, "card_id": 3, "occurred_at": "2025-03-01T10:00:00Z", "transaction_id": "txn_654321"}], "user_id": 2}`),
+				},
+				Response: contract.Response{Status: http.StatusCreated},
+			},
+			assertFunc: func(t *testing.T, repository ComplianceRepository) {
+				err := repository.RequestCardReview(context.Background(), CaseSourceHighRiskPayment,
+					Transaction{ID: "txn_654321", UserID: 2, CardID: 3, Amount: 750, CreatedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)})
+				assert.NoError(t, err)
+			},
+		},
+		{
+			interaction: contract.Interaction{
+				Description:   "a list of the blocked cards",
+				ProviderState: "card 2 of user 1 is reported stolen",
+				Request:       contract.Request{Method: http.MethodGet, Path: "/v1/blocked_cards"},
+				Response: contract.Response{
+					Status: http.StatusOK,
+					Body:   json.RawMessage(`{"blocked_cards": [{"user_id": 1, "card_id": 2}]}`),
+				},
+			},
+			assertFunc: func(t *testing.T, repository ComplianceRepository) {
+				blockedCards, err := repository.ListBlockedCards(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, []BlockedCard{{UserID: 1, CardID: 2}}, blockedCards)
+			},
+		},
+	}
+
+	pact := contract.NewPact("payment-service", "compliance-service")
+	for _, tt := range tests {
+		t.Run(tt.interaction.Description, func(t *testing.T) {
+			baseURL := pact.MockServer(t, tt.interaction)
+			tt.assertFunc(t, newTestComplianceRepository(baseURL, time.Second, 0))
+		})
+	}
+
+	if !t.Failed() {
+		assert.NoError(t, pact.Write(pactDir))
+	}
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"flarrocca/auth"
+	"flarrocca/logging"
+	"fmt"
+	"log/slog"
+	"os"
+
+	compliancev1 "flarrocca/proto/compliance/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultComplianceGRPCAddress = "localhost:9090"
+
+type complianceGRPCRepository struct {
+	client compliancev1.ComplianceServiceClient
+	caller complianceCaller
+}
+
+// NewComplianceGRPCRepository calls the compliance.v1 gRPC API of compliance-service at COMPLIANCE_GRPC_ADDRESS, with
+// the same timeout, retries and circuit breaker as the HTTP implementation, and a bearer token of the source, if not nil.
+// The request ID of the context of the calls is sent in their metadata. The connection is established on the first call.
+func NewComplianceGRPCRepository(tokenSource auth.TokenSource) (ComplianceRepository, error) {
+	address := os.Getenv("COMPLIANCE_GRPC_ADDRESS")
+	if address == "" {
+		address = defaultComplianceGRPCAddress
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(logging.UnaryClientInterceptor()),
+	}
+	if tokenSource != nil {
+		options = append(options, grpc.WithPerRPCCredentials(auth.PerRPCCredentials(tokenSource)))
+	}
+
+	conn, err := grpc.NewClient(address, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &complianceGRPCRepository{
+		client: compliancev1.NewComplianceServiceClient(conn),
+		caller: newComplianceCaller(),
+	}, nil
+}
+
+func (c *complianceGRPCRepository) CheckUserComplianceStatus(ctx context.Context, userID int64, cardID int64, amount float64, paymentContext *PaymentContext) (ComplianceResponse, error) {
+	req := &compliancev1.CheckComplianceRequest{UserId: userID, CardId: cardID, Amount: amount}
+	if paymentContext != nil {
+		req.Context = &compliancev1.PaymentContext{
+			IpAddress:  paymentContext.IPAddress,
+			Email:      paymentContext.Email,
+			DeviceId:   paymentContext.DeviceID,
+			MerchantId: paymentContext.MerchantID,
+		}
+	}
+
+	var resp *compliancev1.CheckComplianceResponse
+	err := c.caller.callWithRetries(ctx, operationCheckUser, func(ctx context.Context) error {
+		var err error
+		resp, err = c.client.CheckCompliance(ctx, req)
+		return complianceStatusError(err)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error calling compliance-service", logging.UserID(userID), logging.CardID(cardID), logging.Err(err))
+		return ComplianceResponse{}, err
+	}
+
+	return ComplianceResponse{
+		IsComplaiance: resp.GetCompliant(),
+		Message:       resp.GetMessage(),
+		RiskRating:    resp.GetRiskRating(),
+		ManualReview:  resp.GetManualReview(),
+		Limits:        paymentLimits(resp.GetLimits()),
+	}, nil
+}
+
+// CheckUserComplianceStatuses splits the checks in batches of up to maxComplianceBatch, each sent with a single call.
+func (c *complianceGRPCRepository) CheckUserComplianceStatuses(ctx context.Context, checks []ComplianceCheck) ([]ComplianceResponse, error) {
+	responses := make([]ComplianceResponse, 0, len(checks))
+	for start := 0; start < len(checks); start += maxComplianceBatch {
+		batch := checks[start:min(start+maxComplianceBatch, len(checks))]
+
+		req := &compliancev1.CheckComplianceBatchRequest{Checks: make([]*compliancev1.CheckComplianceRequest, 0, len(batch))}
+		for _, check := range batch {
+			item := &compliancev1.CheckComplianceRequest{UserId: check.UserID, CardId: check.CardID, Amount: check.Amount}
+			if check.PaymentContext != nil {
+				item.Context = &compliancev1.PaymentContext{
+					IpAddress:  check.PaymentContext.IPAddress,
+					Email:      check.PaymentContext.Email,
+					DeviceId:   check.PaymentContext.DeviceID,
+					MerchantId: check.PaymentContext.MerchantID,
+				}
+			}
+			req.Checks = append(req.Checks, item)
+		}
+
+		var resp *compliancev1.CheckComplianceBatchResponse
+		err := c.caller.callWithRetries(ctx, operationCheckUsers, func(ctx context.Context) error {
+			var err error
+			resp, err = c.client.CheckComplianceBatch(ctx, req)
+			return complianceStatusError(err)
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "error calling compliance-service", slog.Int("checks", len(batch)), logging.Err(err))
+			return nil, err
+		}
+		if len(resp.GetResults()) != len(batch) {
+			return nil, fmt.Errorf("%w: %d results for %d checks", ErrComplianceRequestFailed, len(resp.GetResults()), len(batch))
+		}
+
+		for _, result := range resp.GetResults() {
+			responses = append(responses, ComplianceResponse{
+				IsComplaiance: result.GetCompliant(),
+				Message:       result.GetMessage(),
+				RiskRating:    result.GetRiskRating(),
+				ManualReview:  result.GetManualReview(),
+				Limits:        paymentLimits(result.GetLimits()),
+			})
+		}
+	}
+
+	return responses, nil
+}
+
+func paymentLimits(limits *compliancev1.PaymentLimits) *PaymentLimits {
+	if limits == nil {
+		return nil
+	}
+	return &PaymentLimits{
+		KYCStatus:     limits.GetKycStatus(),
+		KYCTier:       int(limits.GetKycTier()),
+		KYCLimit:      limits.GetKycLimit(),
+		HighRiskLimit: limits.GetHighRiskLimit(),
+		ReviewAmount:  limits.GetReviewAmount(),
+	}
+}
+
+// complianceStatusError maps the gRPC status to ErrComplianceUnavailable when retrying may succeed, to
+// ErrComplianceRequestFailed otherwise.
+func complianceStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	s := status.Convert(err)
+	switch s.Code() {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: compliance service timed out", ErrComplianceUnavailable)
+	case codes.Unavailable, codes.Internal, codes.ResourceExhausted, codes.Aborted:
+		return fmt.Errorf("%w: compliance service returned status code: %s", ErrComplianceUnavailable, s.Code())
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: invalid compliance request: %s", ErrComplianceRequestFailed, s.Message())
+	default:
+		return fmt.Errorf("%w: compliance service returned status code: %s", ErrComplianceRequestFailed, s.Code())
+	}
+}
+
+// RequestCardReview is not retried, a retry could open the case twice.
+func (c *complianceGRPCRepository) RequestCardReview(ctx context.Context, source string, transaction Transaction) error {
+	caseTransaction := &compliancev1.CaseTransaction{
+		TransactionId: transaction.ID,
+		CardId:        transaction.CardID,
+		Amount:        transaction.Amount,
+	}
+	if !transaction.CreatedAt.IsZero() {
+		caseTransaction.OccurredAt = timestamppb.New(transaction.CreatedAt)
+	}
+
+	req := &compliancev1.RequestCardReviewRequest{
+		UserId:       transaction.UserID,
+		CardId:       transaction.CardID,
+		Source:       source,
+		Transactions: []*compliancev1.CaseTransaction{caseTransaction},
+	}
+
+	return c.caller.call(ctx, operationRequestCardReview, func(ctx context.Context) error {
+		_, err := c.client.RequestCardReview(ctx, req)
+		return complianceStatusError(err)
+	})
+}
+
+func (c *complianceGRPCRepository) ListBlockedCards(ctx context.Context) ([]BlockedCard, error) {
+	var resp *compliancev1.ListBlockedCardsResponse
+	err := c.caller.callWithRetries(ctx, operationListBlockedCards, func(ctx context.Context) error {
+		var err error
+		resp, err = c.client.ListBlockedCards(ctx, &compliancev1.ListBlockedCardsRequest{})
+		return complianceStatusError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]BlockedCard, 0, len(resp.GetCards()))
+	for _, card := range resp.GetCards() {
+		cards = append(cards, BlockedCard{UserID: card.GetUserId(), CardID: card.GetCardId()})
+	}
+
+	return cards, nil
+}
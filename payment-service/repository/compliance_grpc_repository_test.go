@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	compliancev1 "flarrocca/proto/compliance/v1"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeComplianceServer struct {
+	compliancev1.UnimplementedComplianceServiceServer
+	checkCompliance      func(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error)
+	checkComplianceBatch func(ctx context.Context, req *compliancev1.CheckComplianceBatchRequest) (*compliancev1.CheckComplianceBatchResponse, error)
+	requestCardReview    func(ctx context.Context, req *compliancev1.RequestCardReviewRequest) (*compliancev1.RequestCardReviewResponse, error)
+	listBlockedCards     func(ctx context.Context, req *compliancev1.ListBlockedCardsRequest) (*compliancev1.ListBlockedCardsResponse, error)
+}
+
+func (s *fakeComplianceServer) CheckCompliance(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+	return s.checkCompliance(ctx, req)
+}
+
+func (s *fakeComplianceServer) CheckComplianceBatch(ctx context.Context, req *compliancev1.CheckComplianceBatchRequest) (*compliancev1.CheckComplianceBatchResponse, error) {
+	return s.checkComplianceBatch(ctx, req)
+}
+
+func (s *fakeComplianceServer) RequestCardReview(ctx context.Context, req *compliancev1.RequestCardReviewRequest) (*compliancev1.RequestCardReviewResponse, error) {
+	return s.requestCardReview(ctx, req)
+}
+
+func (s *fakeComplianceServer) ListBlockedCards(ctx context.Context, req *compliancev1.ListBlockedCardsRequest) (*compliancev1.ListBlockedCardsResponse, error) {
+	return s.listBlockedCards(ctx, req)
+}
+
+// newTestComplianceGRPCRepository serves the fake server in memory and returns a repository connected to it.
+func newTestComplianceGRPCRepository(t *testing.T, server *fakeComplianceServer, timeout time.Duration) *complianceGRPCRepository {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	compliancev1.RegisterComplianceServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return &complianceGRPCRepository{client: compliancev1.NewComplianceServiceClient(conn), caller: newTestComplianceCaller(timeout, 0)}
+}
+
+func TestCheckUserComplianceStatusGRPC(t *testing.T) {
+	tests := []struct {
+		name           string
+		paymentContext *PaymentContext
+		server         *fakeComplianceServer
+		assertFunc     func(t *testing.T, response ComplianceResponse, err error)
+	}{
+		{
+			name:           "Success - Payment context sent and response mapped",
+			paymentContext: &PaymentContext{IPAddress: "203.0.113.7", Email: "john@example.com", DeviceID: "device-1", MerchantID: "merchant-1", MCC: "5411"},
+			server: &fakeComplianceServer{checkCompliance: func(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+				assert.Equal(t, int64(2), req.GetUserId())
+				assert.Equal(t, int64(3), req.GetCardId())
+				assert.Equal(t, 750.0, req.GetAmount())
+				assert.Equal(t, "203.0.113.7", req.GetContext().GetIpAddress())
+				assert.Equal(t, "merchant-1", req.GetContext().GetMerchantId())
+				_, hasDeadline := ctx.Deadline()
+				assert.True(t, hasDeadline)
+				return &compliancev1.CheckComplianceResponse{Message: "payment amount 750.00 of a high-risk user requires manual review", RiskRating: "high", ManualReview: true}, nil
+			}},
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, ComplianceResponse{Message: "payment amount 750.00 of a high-risk user requires manual review", RiskRating: "high", ManualReview: true}, response)
+			},
+		},
+		{
+			name: "Success - Check without payment context",
+			server: &fakeComplianceServer{checkCompliance: func(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+				assert.Nil(t, req.GetContext())
+				return &compliancev1.CheckComplianceResponse{Compliant: true, Message: "user is compliance", RiskRating: "low"}, nil
+			}},
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.NoError(t, err)
+				assert.True(t, response.IsComplaiance)
+				assert.Equal(t, "low", response.RiskRating)
+			},
+		},
+		{
+			name: "Failure - Deadline exceeded",
+			server: &fakeComplianceServer{checkCompliance: func(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}},
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.ErrorIs(t, err, ErrComplianceUnavailable)
+				assert.EqualError(t, err, "compliance service unavailable: compliance service timed out")
+			},
+		},
+		{
+			name: "Failure - Invalid request",
+			server: &fakeComplianceServer{checkCompliance: func(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+				return nil, status.Error(codes.InvalidArgument, "user id and card id are required")
+			}},
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.ErrorIs(t, err, ErrComplianceRequestFailed)
+				assert.EqualError(t, err, "compliance request failed: invalid compliance request: user id and card id are required")
+			},
+		},
+		{
+			name: "Failure - Internal error",
+			server: &fakeComplianceServer{checkCompliance: func(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+				return nil, status.Error(codes.Internal, "error checking user status: error retrieving user cards")
+			}},
+			assertFunc: func(t *testing.T, response ComplianceResponse, err error) {
+				assert.EqualError(t, err, "compliance service unavailable: compliance service returned status code: Internal")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			complianceRepository := newTestComplianceGRPCRepository(t, tt.server, 200*time.Millisecond)
+
+			response, err := complianceRepository.CheckUserComplianceStatus(context.Background(), 2, 3, 750, tt.paymentContext)
+			tt.assertFunc(t, response, err)
+		})
+	}
+}
+
+func TestCheckUserComplianceStatusGRPCRetry(t *testing.T) {
+	calls := 0
+	complianceRepository := newTestComplianceGRPCRepository(t, &fakeComplianceServer{
+		checkCompliance: func(ctx context.Context, req *compliancev1.CheckComplianceRequest) (*compliancev1.CheckComplianceResponse, error) {
+			calls++
+			if calls == 1 {
+				return nil, status.Error(codes.Unavailable, "shutting down")
+			}
+			return &compliancev1.CheckComplianceResponse{Compliant: true, Message: "user is compliance", RiskRating: "low"}, nil
+		},
+	}, time.Second)
+	complianceRepository.caller.maxRetries = 2
+
+	response, err := complianceRepository.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+	assert.NoError(t, err)
+	assert.True(t, response.IsComplaiance)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCheckUserComplianceStatusGRPCUnavailable(t *testing.T) {
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return nil, context.Canceled }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	complianceRepository := &complianceGRPCRepository{client: compliancev1.NewComplianceServiceClient(conn), caller: newTestComplianceCaller(time.Second, 0)}
+
+	_, err = complianceRepository.CheckUserComplianceStatus(context.Background(), 1, 1, 10, nil)
+	assert.ErrorIs(t, err, ErrComplianceUnavailable)
+	assert.ErrorContains(t, err, "status code: Unavailable")
+}
+
+func TestCheckUserComplianceStatusesGRPC(t *testing.T) {
+	var batches []int
+	complianceRepository := newTestComplianceGRPCRepository(t, &fakeComplianceServer{
+		checkComplianceBatch: func(ctx context.Context, req *compliancev1.CheckComplianceBatchRequest) (*compliancev1.CheckComplianceBatchResponse, error) {
+			batches = append(batches, len(req.GetChecks()))
+			resp := &compliancev1.CheckComplianceBatchResponse{}
+			for _, check := range req.GetChecks() {
+				if check.GetCardId() == 7 {
+					assert.Equal(t, "grocer-001", check.GetContext().GetMerchantId())
+					resp.Results = append(resp.Results, &compliancev1.CheckComplianceResponse{Message: "card is blocked due to being reported as stolen or compromised"})
+					continue
+				}
+				resp.Results = append(resp.Results, &compliancev1.CheckComplianceResponse{Compliant: true, Message: "user is compliance", RiskRating: "low"})
+			}
+			return resp, nil
+		},
+	}, time.Second)
+
+	checks := make([]ComplianceCheck, maxComplianceBatch+1)
+	checks[maxComplianceBatch] = ComplianceCheck{UserID: 3, CardID: 7, Amount: 25, PaymentContext: &PaymentContext{MerchantID: "grocer-001"}}
+
+	responses, err := complianceRepository.CheckUserComplianceStatuses(context.Background(), checks)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{maxComplianceBatch, 1}, batches)
+	assert.Len(t, responses, maxComplianceBatch+1)
+	assert.Equal(t, ComplianceResponse{IsComplaiance: true, Message: "user is compliance", RiskRating: "low"}, responses[0])
+	assert.Equal(t, ComplianceResponse{Message: "card is blocked due to being reported as stolen or compromised"}, responses[maxComplianceBatch])
+}
+
+func TestRequestCardReviewGRPC(t *testing.T) {
+	transaction := Transaction{ID: "txn_1", UserID: 1, CardID: 2, Amount: 99.5, CreatedAt: time.Date(2025, 2, 27, 18, 30, 0, 0, time.UTC)}
+
+	t.Run("Success - Case opened for the transaction", func(t *testing.T) {
+		complianceRepository := newTestComplianceGRPCRepository(t, &fakeComplianceServer{
+			requestCardReview: func(ctx context.Context, req *compliancev1.RequestCardReviewRequest) (*compliancev1.RequestCardReviewResponse, error) {
+				assert.Equal(t, "chargeback", req.GetSource())
+				assert.Equal(t, "txn_1", req.GetTransactions()[0].GetTransactionId())
+				assert.Equal(t, 99.5, req.GetTransactions()[0].GetAmount())
+				assert.Equal(t, transaction.CreatedAt, req.GetTransactions()[0].GetOccurredAt().AsTime())
+				return &compliancev1.RequestCardReviewResponse{CaseId: 7}, nil
+			},
+		}, time.Second)
+
+		assert.NoError(t, complianceRepository.RequestCardReview(context.Background(), CaseSourceChargeback, transaction))
+	})
+
+	t.Run("Failure - Case not opened twice", func(t *testing.T) {
+		calls := 0
+		complianceRepository := newTestComplianceGRPCRepository(t, &fakeComplianceServer{
+			requestCardReview: func(ctx context.Context, req *compliancev1.RequestCardReviewRequest) (*compliancev1.RequestCardReviewResponse, error) {
+				calls++
+				return nil, status.Error(codes.Internal, "database error")
+			},
+		}, time.Second)
+		complianceRepository.caller.maxRetries = 2
+
+		err := complianceRepository.RequestCardReview(context.Background(), CaseSourceChargeback, transaction)
+		assert.EqualError(t, err, "compliance service unavailable: compliance service returned status code: Internal")
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestListBlockedCardsGRPC(t *testing.T) {
+	complianceRepository := newTestComplianceGRPCRepository(t, &fakeComplianceServer{
+		listBlockedCards: func(ctx context.Context, req *compliancev1.ListBlockedCardsRequest) (*compliancev1.ListBlockedCardsResponse, error) {
+			return &compliancev1.ListBlockedCardsResponse{Cards: []*compliancev1.BlockedCard{{UserId: 1, CardId: 2}, {UserId: 3, CardId: 7}}}, nil
+		},
+	}, time.Second)
+
+	cards, err := complianceRepository.ListBlockedCards(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockedCard{{UserID: 1, CardID: 2}, {UserID: 3, CardID: 7}}, cards)
+}
@@ -1,58 +1,300 @@
 package repository
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"flarrocca/auth"
+	"flarrocca/logging"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 )
 
+// ComplianceResponse is the outcome of a compliance check. ManualReview is set, with IsComplaiance false, when the payment
+// must be held until a reviewer approves it.
 type ComplianceResponse struct {
-	IsComplaiance bool   `json:"complaiance"`
+	IsComplaiance bool   `json:"compliant"`
 	Message       string `json:"message"`
+	RiskRating    string `json:"risk_rating,omitempty"`
+	ManualReview  bool   `json:"manual_review,omitempty"`
+	// Limits is set when the verdict only depends on the amount. A verdict without limits holds for any amount.
+	Limits *PaymentLimits `json:"limits,omitempty"`
+}
+
+// PaymentLimits are the amount limits compliance-service applied to the payment. 0 means no limit.
+type PaymentLimits struct {
+	KYCStatus     string  `json:"kyc_status"`
+	KYCTier       int     `json:"kyc_tier"`
+	KYCLimit      float64 `json:"kyc_limit,omitempty"`
+	HighRiskLimit float64 `json:"high_risk_limit,omitempty"`
+	ReviewAmount  float64 `json:"review_amount,omitempty"`
+}
+
+// verdict applies the limits to another amount of the same payment details, as compliance-service would.
+func (l PaymentLimits) verdict(riskRating string, amount float64) ComplianceResponse {
+	response := ComplianceResponse{RiskRating: riskRating, Limits: &l}
+	switch {
+	case l.KYCLimit != 0 && amount > l.KYCLimit:
+		response.Message = fmt.Sprintf("payment amount %.2f exceeds the %.2f limit of KYC tier %d (%s), a higher verification tier is required",
+			amount, l.KYCLimit, l.KYCTier, l.KYCStatus)
+	case l.HighRiskLimit != 0 && amount > l.HighRiskLimit:
+		response.Message = fmt.Sprintf("payment amount %.2f exceeds the %.2f limit of high-risk users", amount, l.HighRiskLimit)
+	case l.ReviewAmount != 0 && amount >= l.ReviewAmount:
+		response.Message = fmt.Sprintf("payment amount %.2f of a high-risk user requires manual review", amount)
+		response.ManualReview = true
+	default:
+		response.IsComplaiance = true
+		response.Message = "user is compliance"
+	}
+	return response
+}
+
+// Sources of the cases opened in compliance-service for a payment.
+const (
+	CaseSourceChargeback      = "chargeback"
+	CaseSourceHighRiskPayment = "high_risk_payment"
+)
+
+// maxComplianceBatch is the number of checks compliance-service accepts in a batch. Larger batches are split.
+const maxComplianceBatch = 1000
+
+// ComplianceCheck is one of the payments of a batch compliance check.
+type ComplianceCheck struct {
+	UserID         int64
+	CardID         int64
+	Amount         float64
+	PaymentContext *PaymentContext
+}
+
+// BlockedCard is a card compliance-service denies the payments of.
+type BlockedCard struct {
+	UserID int64 `json:"user_id"`
+	CardID int64 `json:"card_id"`
 }
 
 // Run from the /repository folder the following command to generate the mock:
 // mockgen -source compliance_repository.go -destination mock/compliance_repository_mock.go -package mock
 type ComplianceRepository interface {
-	CheckUserComplianceStatus(userID int64, cardID int64) (bool, string)
+	CheckUserComplianceStatus(ctx context.Context, userID int64, cardID int64, amount float64, paymentContext *PaymentContext) (ComplianceResponse, error)
+	CheckUserComplianceStatuses(ctx context.Context, checks []ComplianceCheck) ([]ComplianceResponse, error)
+	RequestCardReview(ctx context.Context, source string, transaction Transaction) error
+	ListBlockedCards(ctx context.Context) ([]BlockedCard, error)
 }
 
 type complianceRepository struct {
 	complianceBaseURL string
+	client            *http.Client
+	caller            complianceCaller
+	tokenSource       auth.TokenSource
 }
 
-func NewComplianceRepository() ComplianceRepository {
+// NewComplianceRepository calls compliance-service at COMPLIANCE_SERVICE_URL, with a bearer token of the source, if not nil.
+func NewComplianceRepository(tokenSource auth.TokenSource) ComplianceRepository {
 	complianceBaseURL := os.Getenv("COMPLIANCE_SERVICE_URL")
 	if complianceBaseURL == "" {
 		complianceBaseURL = "http://localhost:8080"
 	}
 	return &complianceRepository{
 		complianceBaseURL: complianceBaseURL,
+		client:            &http.Client{},
+		caller:            newComplianceCaller(),
+		tokenSource:       tokenSource,
 	}
 }
 
-func (c *complianceRepository) CheckUserComplianceStatus(userID int64, cardID int64) (bool, string) {
-	resp, err := http.Get(c.complianceBaseURL + fmt.Sprintf("/check_user?user_id=%s&card_id=%s", strconv.FormatInt(userID, 10), strconv.FormatInt(cardID, 10)))
+// CheckUserComplianceStatus sends the amount, checked against the KYC tier limits of the user, and the payment context, if any,
+// so compliance-service can match it against its deny and allow lists. A payment compliance-service denied is returned without
+// error; the error, ErrComplianceUnavailable or ErrComplianceRequestFailed, means the payment could not be checked.
+func (c *complianceRepository) CheckUserComplianceStatus(ctx context.Context, userID int64, cardID int64, amount float64, paymentContext *PaymentContext) (ComplianceResponse, error) {
+	checkURL := c.complianceBaseURL + fmt.Sprintf("/v1/check_user?user_id=%s&card_id=%s&amount=%s",
+		strconv.FormatInt(userID, 10), strconv.FormatInt(cardID, 10), strconv.FormatFloat(amount, 'f', -1, 64)) + listQuery(paymentContext)
+
+	var result ComplianceResponse
+	err := c.caller.callWithRetries(ctx, operationCheckUser, func(ctx context.Context) error {
+		result = ComplianceResponse{}
+		return c.do(ctx, http.MethodGet, checkURL, nil, &result)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error calling compliance-service", logging.UserID(userID), logging.CardID(cardID), logging.Err(err))
+		return ComplianceResponse{}, err
+	}
+
+	return result, nil
+}
+
+// CheckUserComplianceStatuses checks the payments with as few requests to /check_users as the batch limit of
+// compliance-service allows. The responses are returned in the order of the checks; an error fails the whole batch.
+func (c *complianceRepository) CheckUserComplianceStatuses(ctx context.Context, checks []ComplianceCheck) ([]ComplianceResponse, error) {
+	type batchCheck struct {
+		UserID     int64   `json:"user_id"`
+		CardID     int64   `json:"card_id"`
+		Amount     float64 `json:"amount"`
+		IPAddress  string  `json:"ip_address,omitempty"`
+		Email      string  `json:"email,omitempty"`
+		DeviceID   string  `json:"device_id,omitempty"`
+		MerchantID string  `json:"merchant_id,omitempty"`
+	}
+
+	responses := make([]ComplianceResponse, 0, len(checks))
+	for start := 0; start < len(checks); start += maxComplianceBatch {
+		batch := checks[start:min(start+maxComplianceBatch, len(checks))]
+
+		req := struct {
+			Checks []batchCheck `json:"checks"`
+		}{Checks: make([]batchCheck, 0, len(batch))}
+		for _, check := range batch {
+			item := batchCheck{UserID: check.UserID, CardID: check.CardID, Amount: check.Amount}
+			if check.PaymentContext != nil {
+				item.IPAddress = check.PaymentContext.IPAddress
+				item.Email = check.PaymentContext.Email
+				item.DeviceID = check.PaymentContext.DeviceID
+				item.MerchantID = check.PaymentContext.MerchantID
+			}
+			req.Checks = append(req.Checks, item)
+		}
+
+		payload, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Results []ComplianceResponse `json:"results"`
+		}
+		err = c.caller.callWithRetries(ctx, operationCheckUsers, func(ctx context.Context) error {
+			result.Results = nil
+			return c.do(ctx, http.MethodPost, c.complianceBaseURL+"/v1/check_users", payload, &result)
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "error calling compliance-service", slog.Int("checks", len(batch)), logging.Err(err))
+			return nil, err
+		}
+		if len(result.Results) != len(batch) {
+			return nil, fmt.Errorf("%w: %d results for %d checks", ErrComplianceRequestFailed, len(result.Results), len(batch))
+		}
+
+		responses = append(responses, result.Results...)
+	}
+
+	return responses, nil
+}
+
+// do sends the request and decodes the JSON response into result, if not nil. Network errors, timeouts and 5xx responses
+// are returned as ErrComplianceUnavailable, other failures as ErrComplianceRequestFailed.
+func (c *complianceRepository) do(ctx context.Context, method string, requestURL string, body []byte, result any) error {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
 	if err != nil {
-		log.Printf("error calling compliance-service: %v", err)
-		return false, "error communicating with compliance service"
+		return fmt.Errorf("%w: %v", ErrComplianceRequestFailed, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token()
+		if err != nil {
+			return fmt.Errorf("%w: error signing the token: %v", ErrComplianceRequestFailed, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set(logging.HeaderRequestID, requestID)
 	}
 
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: no response within %s", ErrComplianceUnavailable, c.caller.timeout)
+		}
+		slog.ErrorContext(ctx, "error communicating with compliance-service", logging.Err(err))
+		return fmt.Errorf("%w: error communicating with compliance service", ErrComplianceUnavailable)
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%w: compliance service returned status code: %d", ErrComplianceUnavailable, resp.StatusCode)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Printf("compliance service returned non-2xx status: %d", resp.StatusCode)
-		return false, fmt.Sprintf("compliance service returned status code: %d", resp.StatusCode)
+		return fmt.Errorf("%w: compliance service returned status code: %d", ErrComplianceRequestFailed, resp.StatusCode)
 	}
 
-	var result ComplianceResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		log.Printf("error decoding compliance response: %v", err)
-		return false, "error processing compliance response"
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: no response within %s", ErrComplianceUnavailable, c.caller.timeout)
+		}
+		return fmt.Errorf("%w: error processing compliance response", ErrComplianceRequestFailed)
+	}
+
+	return nil
+}
+
+func listQuery(paymentContext *PaymentContext) string {
+	if paymentContext == nil {
+		return ""
+	}
+
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"ip_address":  paymentContext.IPAddress,
+		"email":       paymentContext.Email,
+		"device_id":   paymentContext.DeviceID,
+		"merchant_id": paymentContext.MerchantID,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if len(query) == 0 {
+		return ""
+	}
+
+	return "&" + query.Encode()
+}
+
+// RequestCardReview opens a case in compliance-service, with the source, a chargeback or a high-risk payment, so the card
+// used in the transaction gets reviewed. The transaction is sent with the time it was made, reported in the SARs.
+func (c *complianceRepository) RequestCardReview(ctx context.Context, source string, transaction Transaction) error {
+	caseTransaction := map[string]any{
+		"transaction_id": transaction.ID,
+		"card_id":        transaction.CardID,
+		"amount":         transaction.Amount,
+	}
+	if !transaction.CreatedAt.IsZero() {
+		caseTransaction["occurred_at"] = transaction.CreatedAt.UTC()
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"user_id":      transaction.UserID,
+		"card_id":      transaction.CardID,
+		"source":       source,
+		"transactions": []map[string]any{caseTransaction},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Opening a case is not idempotent, so it is not retried.
+	return c.caller.call(ctx, operationRequestCardReview, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodPost, c.complianceBaseURL+"/v1/cases", payload, nil)
+	})
+}
+
+func (c *complianceRepository) ListBlockedCards(ctx context.Context) ([]BlockedCard, error) {
+	var result struct {
+		BlockedCards []BlockedCard `json:"blocked_cards"`
+	}
+	err := c.caller.callWithRetries(ctx, operationListBlockedCards, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodGet, c.complianceBaseURL+"/v1/blocked_cards", nil, &result)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return result.IsComplaiance, result.Message
+	return result.BlockedCards, nil
 }
@@ -1,22 +1,38 @@
 package repository
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flarrocca/auth"
+	"flarrocca/logging"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestComplianceRepository(baseURL string, timeout time.Duration, maxRetries int) *complianceRepository {
+	return &complianceRepository{complianceBaseURL: baseURL, client: &http.Client{}, caller: newTestComplianceCaller(timeout, maxRetries)}
+}
+
 func TestIsUserBlocked(t *testing.T) {
 	type input struct {
-		userID int64
-		cardID int64
+		userID         int64
+		cardID         int64
+		amount         float64
+		paymentContext *PaymentContext
 	}
 
 	type output struct {
-		compliance bool
-		message    string
+		compliance   bool
+		message      string
+		riskRating   string
+		manualReview bool
+		err          error
 	}
 
 	tests := []struct {
@@ -30,15 +46,17 @@ func TestIsUserBlocked(t *testing.T) {
 			input: input{
 				userID: int64(1),
 				cardID: int64(1),
+				amount: 100.5,
 			},
 			mockServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					assert.Equal(t, "/check_user?user_id=1&card_id=1", r.URL.String())
+					assert.Equal(t, "/v1/check_user?user_id=1&card_id=1&amount=100.5", r.URL.String())
 					w.WriteHeader(http.StatusOK)
-					w.Write([]byte(`{"complaiance": false, "message": "user is blocked due to compliance reasons"}`))
+					w.Write([]byte(`{"compliant": false, "message": "user is blocked due to compliance reasons"}`))
 				}))
 			},
 			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
 				assert.False(t, out.compliance)
 				assert.Equal(t, "user is blocked due to compliance reasons", out.message)
 			},
@@ -48,12 +66,13 @@ func TestIsUserBlocked(t *testing.T) {
 			input: input{
 				userID: int64(1),
 				cardID: int64(1),
+				amount: 100.5,
 			},
 			mockServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					assert.Equal(t, "/check_user?user_id=1&card_id=1", r.URL.String())
+					assert.Equal(t, "/v1/check_user?user_id=1&card_id=1&amount=100.5", r.URL.String())
 					w.WriteHeader(http.StatusOK)
-					w.Write([]byte(`{"complaiance": true, "message": "user is complaiance"}`))
+					w.Write([]byte(`{"compliant": true, "message": "user is complaiance"}`))
 				}))
 			},
 			assertFunc: func(t *testing.T, out output) {
@@ -61,11 +80,52 @@ func TestIsUserBlocked(t *testing.T) {
 				assert.Equal(t, "user is complaiance", out.message)
 			},
 		},
+		{
+			name: "Success - Payment context sent for list matching",
+			input: input{
+				userID:         int64(1),
+				cardID:         int64(1),
+				amount:         100.5,
+				paymentContext: &PaymentContext{IPAddress: "203.0.113.7", Email: "john@example.com", DeviceID: "device-1"},
+			},
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/v1/check_user?user_id=1&card_id=1&amount=100.5&device_id=device-1&email=john%40example.com&ip_address=203.0.113.7", r.URL.String())
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(`{"compliant": false, "message": "payment blocked by deny list: device_id device-1 (emulator)"}`))
+				}))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.compliance)
+				assert.Equal(t, "payment blocked by deny list: device_id device-1 (emulator)", out.message)
+			},
+		},
+		{
+			name: "Success - Payment of a high-risk user held for manual review",
+			input: input{
+				userID: int64(2),
+				cardID: int64(3),
+				amount: 750,
+			},
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(`{"compliant": false, "message": "payment amount 750.00 of a high-risk user requires manual review", "risk_rating": "high", "manual_review": true}`))
+				}))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.False(t, out.compliance)
+				assert.True(t, out.manualReview)
+				assert.Equal(t, "high", out.riskRating)
+				assert.Equal(t, "payment amount 750.00 of a high-risk user requires manual review", out.message)
+			},
+		},
 		{
 			name: "Failure - Error communicating with compliance service",
 			input: input{
 				userID: int64(1),
 				cardID: int64(1),
+				amount: 100.5,
 			},
 			mockServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -73,8 +133,8 @@ func TestIsUserBlocked(t *testing.T) {
 				}))
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.False(t, out.compliance)
-				assert.Equal(t, "compliance service returned status code: 500", out.message)
+				assert.ErrorIs(t, out.err, ErrComplianceUnavailable)
+				assert.EqualError(t, out.err, "compliance service unavailable: compliance service returned status code: 500")
 			},
 		},
 		{
@@ -82,6 +142,7 @@ func TestIsUserBlocked(t *testing.T) {
 			input: input{
 				userID: int64(1),
 				cardID: int64(1),
+				amount: 100.5,
 			},
 			mockServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -90,8 +151,63 @@ func TestIsUserBlocked(t *testing.T) {
 				}))
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.False(t, out.compliance)
-				assert.Equal(t, "error processing compliance response", out.message)
+				assert.ErrorIs(t, out.err, ErrComplianceRequestFailed)
+				assert.EqualError(t, out.err, "compliance request failed: error processing compliance response")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.mockServer()
+			defer server.Close()
+
+			complianceRepository := newTestComplianceRepository(server.URL, time.Second, 0)
+			response, err := complianceRepository.CheckUserComplianceStatus(context.Background(), tt.input.userID, tt.input.cardID, tt.input.amount, tt.input.paymentContext)
+
+			tt.assertFunc(t, output{response.IsComplaiance, response.Message, response.RiskRating, response.ManualReview, err})
+		})
+	}
+}
+
+func TestRequestCardReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockServer func() *httptest.Server
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Chargeback case opened",
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.Equal(t, "/v1/cases", r.URL.Path)
+
+					var body map[string]any
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					assert.Equal(t, "chargeback", body["source"])
+					assert.Equal(t, float64(1), body["user_id"])
+					assert.Equal(t, float64(2), body["card_id"])
+					assert.Equal(t, []any{map[string]any{"transaction_id": "txn_1", "card_id": float64(2), "amount": float64(50),
+						"occurred_at": "2025-02-27T18:30:00Z"}}, body["transactions"])
+
+					w.WriteHeader(http.StatusCreated)
+				}))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Compliance service returned non-2xx status",
+			mockServer: func() *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+				}))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrComplianceRequestFailed)
+				assert.EqualError(t, err, "compliance request failed: compliance service returned status code: 400")
 			},
 		},
 	}
@@ -101,10 +217,221 @@ func TestIsUserBlocked(t *testing.T) {
 			server := tt.mockServer()
 			defer server.Close()
 
-			complianceRepository := &complianceRepository{complianceBaseURL: server.URL}
-			blocked, message := complianceRepository.CheckUserComplianceStatus(tt.input.userID, tt.input.cardID)
+			complianceRepository := newTestComplianceRepository(server.URL, time.Second, 0)
+			err := complianceRepository.RequestCardReview(context.Background(), CaseSourceChargeback,
+				Transaction{ID: "txn_1", UserID: 1, CardID: 2, Amount: 50, CreatedAt: time.Date(2025, 2, 27, 18, 30, 0, 0, time.UTC)})
+
+			tt.assertFunc(t, err)
+		})
+	}
+}
+
+func TestCheckUserComplianceStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []ComplianceCheck
+		handler    http.HandlerFunc
+		assertFunc func(t *testing.T, responses []ComplianceResponse, err error)
+	}{
+		{
+			name: "Success - Verdicts in the order of the checks",
+			checks: []ComplianceCheck{
+				{UserID: 1, CardID: 2, Amount: 9.99, PaymentContext: &PaymentContext{MerchantID: "grocer-001", UserAgent: "curl/8.0"}},
+				{UserID: 3, CardID: 7, Amount: 25},
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/v1/check_users", r.URL.Path)
+				body, _ := io.ReadAll(r.Body)
+				assert.JSONEq(t, `{"checks": [{"user_id": 1, "card_id": 2, "amount": 9.99, "merchant_id": "grocer-001"}, {"user_id": 3, "card_id": 7, "amount": 25}]}`, string(body))
+				w.Write([]byte(`{"results": [
+					{"user_id": 1, "card_id": 2, "compliant": true, "message": "user is compliance", "risk_rating": "low"},
+					{"user_id": 3, "card_id": 7, "compliant": false, "message": "user is currently blocked due to reported stolen card/s"}
+				]}`))
+			},
+			assertFunc: func(t *testing.T, responses []ComplianceResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []ComplianceResponse{
+					{IsComplaiance: true, Message: "user is compliance", RiskRating: "low"},
+					{Message: "user is currently blocked due to reported stolen card/s"},
+				}, responses)
+			},
+		},
+		{
+			name:   "Success - Large batches split",
+			checks: make([]ComplianceCheck, maxComplianceBatch+1),
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Checks []json.RawMessage `json:"checks"`
+				}
+				json.NewDecoder(r.Body).Decode(&req)
+				results := make([]ComplianceResponse, len(req.Checks))
+				json.NewEncoder(w).Encode(map[string]any{"results": results})
+			},
+			assertFunc: func(t *testing.T, responses []ComplianceResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, responses, maxComplianceBatch+1)
+			},
+		},
+		{
+			name:   "Failure - Results missing",
+			checks: []ComplianceCheck{{UserID: 1, CardID: 2}, {UserID: 3, CardID: 7}},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"results": [{"user_id": 1, "card_id": 2, "compliant": true}]}`))
+			},
+			assertFunc: func(t *testing.T, responses []ComplianceResponse, err error) {
+				assert.ErrorIs(t, err, ErrComplianceRequestFailed)
+				assert.Nil(t, responses)
+			},
+		},
+		{
+			name:   "Failure - Compliance service unavailable",
+			checks: []ComplianceCheck{{UserID: 1, CardID: 2}},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			assertFunc: func(t *testing.T, responses []ComplianceResponse, err error) {
+				assert.ErrorIs(t, err, ErrComplianceUnavailable)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			responses, err := newTestComplianceRepository(server.URL, time.Second, 0).CheckUserComplianceStatuses(context.Background(), tt.checks)
+			tt.assertFunc(t, responses, err)
+		})
+	}
+}
+
+func TestListBlockedCards(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		assertFunc func(t *testing.T, cards []BlockedCard, err error)
+	}{
+		{
+			name: "Success - Blocked cards listed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/blocked_cards", r.URL.Path)
+				w.Write([]byte(`{"blocked_cards": [{"user_id": 1, "card_id": 2}, {"user_id": 3, "card_id": 7}]}`))
+			},
+			assertFunc: func(t *testing.T, cards []BlockedCard, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []BlockedCard{{UserID: 1, CardID: 2}, {UserID: 3, CardID: 7}}, cards)
+			},
+		},
+		{
+			name: "Failure - Compliance service unavailable",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			assertFunc: func(t *testing.T, cards []BlockedCard, err error) {
+				assert.ErrorIs(t, err, ErrComplianceUnavailable)
+				assert.Nil(t, cards)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			cards, err := newTestComplianceRepository(server.URL, time.Second, 0).ListBlockedCards(context.Background())
+			tt.assertFunc(t, cards, err)
+		})
+	}
+}
+
+type fakeTokenSource struct {
+	token string
+	err   error
+}
+
+func (f fakeTokenSource) Token() (string, error) {
+	return f.token, f.err
+}
+
+func TestComplianceRepositoryToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokenSource auth.TokenSource
+		assertFunc  func(t *testing.T, authorization string, err error)
+	}{
+		{
+			name:        "Success - Bearer token sent",
+			tokenSource: fakeTokenSource{token: "signed-token"},
+			assertFunc: func(t *testing.T, authorization string, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "Bearer signed-token", authorization)
+			},
+		},
+		{
+			name: "Success - No token without a source",
+			assertFunc: func(t *testing.T, authorization string, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, authorization)
+			},
+		},
+		{
+			name:        "Failure - Token not signed",
+			tokenSource: fakeTokenSource{err: errors.New("no key")},
+			assertFunc: func(t *testing.T, authorization string, err error) {
+				assert.ErrorIs(t, err, ErrComplianceRequestFailed)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				w.Write([]byte(`{"blocked_cards": []}`))
+			}))
+			defer server.Close()
+
+			repo := newTestComplianceRepository(server.URL, time.Second, 0)
+			repo.tokenSource = tt.tokenSource
+			_, err := repo.ListBlockedCards(context.Background())
+			tt.assertFunc(t, authorization, err)
+		})
+	}
+}
+
+func TestComplianceRepositoryRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requestID string
+	}{
+		{
+			name:      "Success - Request ID sent",
+			ctx:       logging.WithRequestID(context.Background(), "req-1"),
+			requestID: "req-1",
+		},
+		{
+			name: "Success - No request ID outside a request",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestID string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID = r.Header.Get(logging.HeaderRequestID)
+				w.Write([]byte(`{"compliant": true, "message": "user is compliant"}`))
+			}))
+			defer server.Close()
 
-			tt.assertFunc(t, output{blocked, message})
+			_, err := newTestComplianceRepository(server.URL, time.Second, 0).CheckUserComplianceStatus(tt.ctx, 1, 1, 10, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.requestID, requestID)
 		})
 	}
 }
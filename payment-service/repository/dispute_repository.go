@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Dispute struct {
+	ID            int64     `json:"id"`
+	TransactionID string    `json:"transaction_id"`
+	ReasonCode    string    `json:"reason_code"`
+	Stage         string    `json:"stage"`
+	Status        string    `json:"status"`
+	Amount        float64   `json:"amount"`
+	DueAt         time.Time `json:"due_at"`
+	OpenedAt      time.Time `json:"opened_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type DisputeEvidence struct {
+	FileName   string    `json:"file_name"`
+	FilePath   string    `json:"file_path"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// LedgerEntry is a money movement caused by a dispute, signed from the merchant's point of view.
+type LedgerEntry struct {
+	TransactionID string    `json:"transaction_id"`
+	EntryType     string    `json:"entry_type"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source dispute_repository.go -destination mock/dispute_repository_mock.go -package mock
+type DisputeRepository interface {
+	CreateDispute(dispute Dispute, entries []LedgerEntry) (int64, error)
+	GetDispute(disputeID int64) (Dispute, error)
+	UpdateDisputeStage(disputeID int64, stage string, dueAt time.Time) error
+	CloseDispute(disputeID int64, status string, entries []LedgerEntry) error
+	AddEvidence(disputeID int64, evidence DisputeEvidence) error
+	GetDisputeEvidence(disputeID int64) ([]DisputeEvidence, error)
+	GetLedgerEntries(disputeID int64) ([]LedgerEntry, error)
+}
+
+type disputeRepository struct {
+	db *sql.DB
+}
+
+func NewDisputeRepository(db *sql.DB) DisputeRepository {
+	return &disputeRepository{db: db}
+}
+
+// CreateDispute stores the dispute together with the ledger entries it posts, in a single transaction.
+func (r *disputeRepository) CreateDispute(dispute Dispute, entries []LedgerEntry) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec("INSERT INTO disputes (transaction_id, reason_code, stage, status, amount, due_at, opened_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		dispute.TransactionID, dispute.ReasonCode, dispute.Stage, dispute.Status, dispute.Amount, dispute.DueAt, dispute.OpenedAt, dispute.OpenedAt)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	disputeID, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := insertLedgerEntries(tx, disputeID, entries); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return disputeID, tx.Commit()
+}
+
+func (r *disputeRepository) GetDispute(disputeID int64) (Dispute, error) {
+	var dispute Dispute
+	err := r.db.QueryRow("SELECT id, transaction_id, reason_code, stage, status, amount, due_at, opened_at, updated_at FROM disputes WHERE id = ?", disputeID).
+		Scan(&dispute.ID, &dispute.TransactionID, &dispute.ReasonCode, &dispute.Stage, &dispute.Status, &dispute.Amount, &dispute.DueAt, &dispute.OpenedAt, &dispute.UpdatedAt)
+	if err != nil {
+		return Dispute{}, err
+	}
+	return dispute, nil
+}
+
+func (r *disputeRepository) UpdateDisputeStage(disputeID int64, stage string, dueAt time.Time) error {
+	_, err := r.db.Exec("UPDATE disputes SET stage = ?, due_at = ?, updated_at = ? WHERE id = ?", stage, dueAt, time.Now().UTC(), disputeID)
+	return err
+}
+
+// CloseDispute sets the final status of the dispute and posts the resulting ledger entries, in a single transaction.
+func (r *disputeRepository) CloseDispute(disputeID int64, status string, entries []LedgerEntry) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE disputes SET status = ?, updated_at = ? WHERE id = ?", status, time.Now().UTC(), disputeID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertLedgerEntries(tx, disputeID, entries); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *disputeRepository) AddEvidence(disputeID int64, evidence DisputeEvidence) error {
+	_, err := r.db.Exec("INSERT INTO dispute_evidence (dispute_id, file_name, file_path) VALUES (?, ?, ?)", disputeID, evidence.FileName, evidence.FilePath)
+	return err
+}
+
+func (r *disputeRepository) GetDisputeEvidence(disputeID int64) ([]DisputeEvidence, error) {
+	rows, err := r.db.Query("SELECT file_name, file_path, uploaded_at FROM dispute_evidence WHERE dispute_id = ? ORDER BY id", disputeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evidence []DisputeEvidence
+	for rows.Next() {
+		var file DisputeEvidence
+		if err := rows.Scan(&file.FileName, &file.FilePath, &file.UploadedAt); err != nil {
+			return nil, err
+		}
+		evidence = append(evidence, file)
+	}
+
+	return evidence, nil
+}
+
+func (r *disputeRepository) GetLedgerEntries(disputeID int64) ([]LedgerEntry, error) {
+	rows, err := r.db.Query("SELECT transaction_id, entry_type, amount, created_at FROM ledger_entries WHERE dispute_id = ? ORDER BY id", disputeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []LedgerEntry
+	for rows.Next() {
+		var entry LedgerEntry
+		if err := rows.Scan(&entry.TransactionID, &entry.EntryType, &entry.Amount, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func insertLedgerEntries(tx *sql.Tx, disputeID int64, entries []LedgerEntry) error {
+	for _, entry := range entries {
+		_, err := tx.Exec("INSERT INTO ledger_entries (dispute_id, transaction_id, entry_type, amount, created_at) VALUES (?, ?, ?, ?, ?)",
+			disputeID, entry.TransactionID, entry.EntryType, entry.Amount, entry.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
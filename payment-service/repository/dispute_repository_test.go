@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDispute(t *testing.T) {
+	openedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	dispute := Dispute{TransactionID: "txn_1", ReasonCode: "10.4", Stage: "chargeback", Status: "open", Amount: 50, DueAt: openedAt.AddDate(0, 0, 30), OpenedAt: openedAt}
+	entries := []LedgerEntry{{TransactionID: "txn_1", EntryType: "chargeback_debit", Amount: -50, CreatedAt: openedAt}}
+
+	type output struct {
+		disputeID int64
+		err       error
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Dispute and ledger entries stored",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO disputes \(transaction_id, reason_code, stage, status, amount, due_at, opened_at, updated_at\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?\)`).
+					WithArgs(dispute.TransactionID, dispute.ReasonCode, dispute.Stage, dispute.Status, dispute.Amount, dispute.DueAt, dispute.OpenedAt, dispute.OpenedAt).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				dbMock.ExpectExec(`INSERT INTO ledger_entries \(dispute_id, transaction_id, entry_type, amount, created_at\) VALUES \(\?, \?, \?, \?, \?\)`).
+					WithArgs(int64(3), "txn_1", "chargeback_debit", -50.0, openedAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, int64(3), out.disputeID)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Dispute already opened",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO disputes`).
+					WillReturnError(errors.New("UNIQUE constraint failed: disputes.transaction_id"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.disputeID)
+				assert.EqualError(t, out.err, "UNIQUE constraint failed: disputes.transaction_id")
+			},
+		},
+		{
+			name: "Failure - Ledger entry error rolls back the dispute",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO disputes`).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				dbMock.ExpectExec(`INSERT INTO ledger_entries`).
+					WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.disputeID)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			disputeRepository := NewDisputeRepository(db)
+			tt.on(dbMock)
+
+			disputeID, err := disputeRepository.CreateDispute(dispute, entries)
+			tt.assertFunc(t, output{disputeID, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetDispute(t *testing.T) {
+	openedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type output struct {
+		dispute Dispute
+		err     error
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Dispute found",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT id, transaction_id, reason_code, stage, status, amount, due_at, opened_at, updated_at FROM disputes WHERE id = \?`).
+					WithArgs(int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "reason_code", "stage", "status", "amount", "due_at", "opened_at", "updated_at"}).
+						AddRow(3, "txn_1", "10.4", "chargeback", "open", 50.0, openedAt.AddDate(0, 0, 30), openedAt, openedAt))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, Dispute{ID: 3, TransactionID: "txn_1", ReasonCode: "10.4", Stage: "chargeback", Status: "open", Amount: 50, DueAt: openedAt.AddDate(0, 0, 30), OpenedAt: openedAt, UpdatedAt: openedAt}, out.dispute)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Dispute not found",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM disputes WHERE id = \?`).
+					WithArgs(int64(3)).
+					WillReturnError(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.dispute)
+				assert.ErrorIs(t, out.err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			disputeRepository := NewDisputeRepository(db)
+			tt.on(dbMock)
+
+			dispute, err := disputeRepository.GetDispute(3)
+			tt.assertFunc(t, output{dispute, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateDisputeStage(t *testing.T) {
+	dueAt := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`UPDATE disputes SET stage = \?, due_at = \?, updated_at = \? WHERE id = \?`).
+		WithArgs("representment", dueAt, sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := NewDisputeRepository(db).UpdateDisputeStage(3, "representment", dueAt)
+
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCloseDispute(t *testing.T) {
+	createdAt := time.Date(2025, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		status     string
+		entries    []LedgerEntry
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:    "Success - Dispute won with reversal",
+			status:  "won",
+			entries: []LedgerEntry{{TransactionID: "txn_1", EntryType: "chargeback_reversal", Amount: 50, CreatedAt: createdAt}},
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`UPDATE disputes SET status = \?, updated_at = \? WHERE id = \?`).
+					WithArgs("won", sqlmock.AnyArg(), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(`INSERT INTO ledger_entries`).
+					WithArgs(int64(3), "txn_1", "chargeback_reversal", 50.0, createdAt).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:   "Failure - Update error",
+			status: "lost",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`UPDATE disputes SET status = \?`).
+					WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			disputeRepository := NewDisputeRepository(db)
+			tt.on(dbMock)
+
+			err := disputeRepository.CloseDispute(3, tt.status, tt.entries)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDisputeEvidence(t *testing.T) {
+	uploadedAt := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`INSERT INTO dispute_evidence \(dispute_id, file_name, file_path\) VALUES \(\?, \?, \?\)`).
+		WithArgs(int64(3), "receipt.pdf", "evidence/3/receipt.pdf").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectQuery(`SELECT file_name, file_path, uploaded_at FROM dispute_evidence WHERE dispute_id = \? ORDER BY id`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"file_name", "file_path", "uploaded_at"}).AddRow("receipt.pdf", "evidence/3/receipt.pdf", uploadedAt))
+
+	disputeRepository := NewDisputeRepository(db)
+	err := disputeRepository.AddEvidence(3, DisputeEvidence{FileName: "receipt.pdf", FilePath: "evidence/3/receipt.pdf"})
+	assert.NoError(t, err)
+
+	evidence, err := disputeRepository.GetDisputeEvidence(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []DisputeEvidence{{FileName: "receipt.pdf", FilePath: "evidence/3/receipt.pdf", UploadedAt: uploadedAt}}, evidence)
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetLedgerEntries(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT transaction_id, entry_type, amount, created_at FROM ledger_entries WHERE dispute_id = \? ORDER BY id`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "entry_type", "amount", "created_at"}).
+			AddRow("txn_1", "chargeback_debit", -50.0, createdAt).
+			AddRow("txn_1", "chargeback_reversal", 50.0, createdAt))
+
+	entries, err := NewDisputeRepository(db).GetLedgerEntries(3)
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, -50.0, entries[0].Amount)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source evidence_repository.go -destination mock/evidence_repository_mock.go -package mock
+type EvidenceRepository interface {
+	SaveEvidenceFile(disputeID int64, fileName string, content io.Reader) (string, error)
+}
+
+type evidenceRepository struct {
+	evidenceDir string
+}
+
+func NewEvidenceRepository() EvidenceRepository {
+	evidenceDir := os.Getenv("DISPUTE_EVIDENCE_DIR")
+	if evidenceDir == "" {
+		evidenceDir = "./database/evidence"
+	}
+	return &evidenceRepository{
+		evidenceDir: evidenceDir,
+	}
+}
+
+// SaveEvidenceFile stores the file under a folder per dispute and returns the path it was written to.
+func (r *evidenceRepository) SaveEvidenceFile(disputeID int64, fileName string, content io.Reader) (string, error) {
+	baseName := filepath.Base(filepath.Clean("/" + fileName))
+	if baseName == "/" || baseName == "." {
+		return "", fmt.Errorf("invalid evidence file name: %q", fileName)
+	}
+
+	disputeDir := filepath.Join(r.evidenceDir, strconv.FormatInt(disputeID, 10))
+	if err := os.MkdirAll(disputeDir, 0o750); err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(disputeDir, baseName)
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, content); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+
+	return filePath, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveEvidenceFile(t *testing.T) {
+	type input struct {
+		fileName string
+		content  string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		setup      func(evidenceDir string)
+		assertFunc func(t *testing.T, evidenceDir string, filePath string, err error)
+	}{
+		{
+			name: "Success - File stored under the dispute folder",
+			input: input{
+				fileName: "receipt.pdf",
+				content:  "receipt content",
+			},
+			setup: func(evidenceDir string) {},
+			assertFunc: func(t *testing.T, evidenceDir string, filePath string, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, filepath.Join(evidenceDir, "3", "receipt.pdf"), filePath)
+				content, _ := os.ReadFile(filePath)
+				assert.Equal(t, "receipt content", string(content))
+			},
+		},
+		{
+			name: "Success - Path traversal is stripped from the file name",
+			input: input{
+				fileName: "../../etc/passwd",
+				content:  "content",
+			},
+			setup: func(evidenceDir string) {},
+			assertFunc: func(t *testing.T, evidenceDir string, filePath string, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, filepath.Join(evidenceDir, "3", "passwd"), filePath)
+			},
+		},
+		{
+			name: "Failure - Existing file is not overwritten",
+			input: input{
+				fileName: "receipt.pdf",
+				content:  "new content",
+			},
+			setup: func(evidenceDir string) {
+				os.MkdirAll(filepath.Join(evidenceDir, "3"), 0o750)
+				os.WriteFile(filepath.Join(evidenceDir, "3", "receipt.pdf"), []byte("original content"), 0o640)
+			},
+			assertFunc: func(t *testing.T, evidenceDir string, filePath string, err error) {
+				assert.Error(t, err)
+				assert.Empty(t, filePath)
+				content, _ := os.ReadFile(filepath.Join(evidenceDir, "3", "receipt.pdf"))
+				assert.Equal(t, "original content", string(content))
+			},
+		},
+		{
+			name: "Failure - Empty file name",
+			input: input{
+				fileName: "",
+				content:  "content",
+			},
+			setup: func(evidenceDir string) {},
+			assertFunc: func(t *testing.T, evidenceDir string, filePath string, err error) {
+				assert.EqualError(t, err, `invalid evidence file name: ""`)
+				assert.Empty(t, filePath)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evidenceDir := t.TempDir()
+			tt.setup(evidenceDir)
+
+			evidenceRepository := &evidenceRepository{evidenceDir: evidenceDir}
+			filePath, err := evidenceRepository.SaveEvidenceFile(3, tt.input.fileName, strings.NewReader(tt.input.content))
+
+			tt.assertFunc(t, evidenceDir, filePath, err)
+		})
+	}
+}
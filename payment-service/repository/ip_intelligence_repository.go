@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flarrocca/logging"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultIPDatabasePath = "./database/ip_database.csv"
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type IPInfo struct {
+	Country        string    `json:"country"`
+	ASN            int64     `json:"asn"`
+	ASOrganization string    `json:"as_organization"`
+	Location       *GeoPoint `json:"location,omitempty"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source ip_intelligence_repository.go -destination mock/ip_intelligence_repository_mock.go -package mock
+type IPIntelligenceRepository interface {
+	LookupIP(ipAddress string) (IPInfo, bool)
+}
+
+type ipRange struct {
+	start net.IP
+	end   net.IP
+	info  IPInfo
+}
+
+type ipIntelligenceRepository struct {
+	ranges []ipRange
+}
+
+// NewIPIntelligenceRepository loads the offline IP database from the CSV file set in IP_DATABASE_PATH.
+// When the file is missing or invalid, every lookup misses and the payments are processed without IP intelligence.
+func NewIPIntelligenceRepository() IPIntelligenceRepository {
+	path := os.Getenv("IP_DATABASE_PATH")
+	if path == "" {
+		path = defaultIPDatabasePath
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		slog.Warn("IP database not loaded", slog.String("path", path), logging.Err(err))
+		return &ipIntelligenceRepository{}
+	}
+	defer file.Close()
+
+	ranges, err := loadIPRanges(file)
+	if err != nil {
+		slog.Warn("IP database not loaded", slog.String("path", path), logging.Err(err))
+		return &ipIntelligenceRepository{}
+	}
+
+	return &ipIntelligenceRepository{ranges: ranges}
+}
+
+// loadIPRanges parses rows in the format range_start,range_end,country_code,asn,as_organization[,latitude,longitude].
+// Both IPv4 and IPv6 ranges are accepted; lines starting with # are ignored.
+func loadIPRanges(r io.Reader) ([]ipRange, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	var ranges []ipRange
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected at least 3 fields, got %d", line, len(record))
+		}
+
+		start := net.ParseIP(strings.TrimSpace(record[0])).To16()
+		end := net.ParseIP(strings.TrimSpace(record[1])).To16()
+		if start == nil || end == nil || bytes.Compare(start, end) > 0 {
+			return nil, fmt.Errorf("line %d: invalid IP range %s - %s", line, record[0], record[1])
+		}
+
+		info := IPInfo{Country: strings.ToUpper(strings.TrimSpace(record[2]))}
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			info.ASN, err = strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(record[3]), "AS"), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid ASN %s", line, record[3])
+			}
+		}
+		if len(record) > 4 {
+			info.ASOrganization = strings.TrimSpace(record[4])
+		}
+		if len(record) > 6 && strings.TrimSpace(record[5]) != "" {
+			info.Location, err = parseGeoPoint(record[5], record[6])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+
+		ranges = append(ranges, ipRange{start: start, end: end, info: info})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].start, ranges[j].start) < 0
+	})
+
+	return ranges, nil
+}
+
+func parseGeoPoint(latitude string, longitude string) (*GeoPoint, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude %s", latitude)
+	}
+
+	lon, err := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude %s", longitude)
+	}
+
+	return &GeoPoint{Latitude: lat, Longitude: lon}, nil
+}
+
+// LookupIP returns the country, ASN and location of the range containing the given address.
+func (r *ipIntelligenceRepository) LookupIP(ipAddress string) (IPInfo, bool) {
+	ip := net.ParseIP(ipAddress).To16()
+	if ip == nil {
+		return IPInfo{}, false
+	}
+
+	// Index of the first range starting after the address; the candidate is the one before it.
+	i := sort.Search(len(r.ranges), func(i int) bool {
+		return bytes.Compare(r.ranges[i].start, ip) > 0
+	})
+	if i == 0 || bytes.Compare(ip, r.ranges[i-1].end) > 0 {
+		return IPInfo{}, false
+	}
+
+	return r.ranges[i-1].info, true
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testIPDatabase = `# range_start,range_end,country_code,asn,as_organization,latitude,longitude
+203.0.113.0,203.0.113.255,AU,64500,Example Sydney,-33.87,151.21
+198.51.100.0,198.51.100.127,gb,AS64501,Example London
+2001:db8::,2001:db8::ffff,US,64502,Example IPv6
+`
+
+func TestLookupIP(t *testing.T) {
+	ranges, err := loadIPRanges(strings.NewReader(testIPDatabase))
+	assert.NoError(t, err)
+
+	ipIntelligenceRepository := &ipIntelligenceRepository{ranges: ranges}
+
+	tests := []struct {
+		name      string
+		ipAddress string
+		info      IPInfo
+		found     bool
+	}{
+		{
+			name:      "Success - IPv4 address in range",
+			ipAddress: "203.0.113.7",
+			info:      IPInfo{Country: "AU", ASN: 64500, ASOrganization: "Example Sydney", Location: &GeoPoint{Latitude: -33.87, Longitude: 151.21}},
+			found:     true,
+		},
+		{
+			name:      "Success - Range boundary and normalized fields",
+			ipAddress: "198.51.100.127",
+			info:      IPInfo{Country: "GB", ASN: 64501, ASOrganization: "Example London"},
+			found:     true,
+		},
+		{
+			name:      "Success - IPv6 address in range",
+			ipAddress: "2001:db8::1",
+			info:      IPInfo{Country: "US", ASN: 64502, ASOrganization: "Example IPv6"},
+			found:     true,
+		},
+		{
+			name:      "Failure - Address between ranges",
+			ipAddress: "198.51.100.128",
+		},
+		{
+			name:      "Failure - Address before every range",
+			ipAddress: "10.0.0.1",
+		},
+		{
+			name:      "Failure - Invalid address",
+			ipAddress: "not-an-ip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, found := ipIntelligenceRepository.LookupIP(tt.ipAddress)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.info, info)
+		})
+	}
+}
+
+func TestLoadIPRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "Failure - Invalid range",
+			content: "203.0.113.255,203.0.113.0,AU,64500,Example",
+			err:     "line 1: invalid IP range 203.0.113.255 - 203.0.113.0",
+		},
+		{
+			name:    "Failure - Invalid ASN",
+			content: "203.0.113.0,203.0.113.255,AU,sixty,Example",
+			err:     "line 1: invalid ASN sixty",
+		},
+		{
+			name:    "Failure - Invalid latitude",
+			content: "203.0.113.0,203.0.113.255,AU,64500,Example,95.2,151.21",
+			err:     "line 1: invalid latitude 95.2",
+		},
+		{
+			name:    "Failure - Missing fields",
+			content: "203.0.113.0,203.0.113.255",
+			err:     "line 1: expected at least 3 fields, got 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges, err := loadIPRanges(strings.NewReader(tt.content))
+
+			assert.Nil(t, ranges)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewIPIntelligenceRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip_database.csv")
+	os.WriteFile(path, []byte(testIPDatabase), 0o640)
+	t.Setenv("IP_DATABASE_PATH", path)
+
+	info, found := NewIPIntelligenceRepository().LookupIP("203.0.113.7")
+	assert.True(t, found)
+	assert.Equal(t, "AU", info.Country)
+
+	t.Setenv("IP_DATABASE_PATH", filepath.Join(t.TempDir(), "missing.csv"))
+
+	_, found = NewIPIntelligenceRepository().LookupIP("203.0.113.7")
+	assert.False(t, found)
+}
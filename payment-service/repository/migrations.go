@@ -0,0 +1,18 @@
+package repository
+
+import "flarrocca/migrate"
+
+// Migrations upgrade a database created by an older database/init.sql, they run before it. A column or a constraint
+// added to a table of init.sql needs a migration here, with the next version.
+var Migrations = []migrate.Migration{
+	{Version: 1, Description: "add transaction_contexts.ip_latitude", Up: migrate.AddColumn("transaction_contexts", "ip_latitude", "REAL")},
+	{Version: 2, Description: "add transaction_contexts.ip_longitude", Up: migrate.AddColumn("transaction_contexts", "ip_longitude", "REAL")},
+	{Version: 3, Description: "add transactions.refunded_at", Up: migrate.AddColumn("transactions", "refunded_at", "TIMESTAMP")},
+	{Version: 4, Description: "add alerts.evidence", Up: migrate.AddColumn("alerts", "evidence", "TEXT")},
+	{Version: 5, Description: "add alerts.dedup_key", Up: migrate.AddColumn("alerts", "dedup_key", "TEXT")},
+	{Version: 6, Description: "add transactions.reviewer", Up: migrate.AddColumn("transactions", "reviewer", "TEXT NOT NULL DEFAULT ''")},
+	{Version: 7, Description: "add transactions.reviewed_at", Up: migrate.AddColumn("transactions", "reviewed_at", "TIMESTAMP")},
+	{Version: 8, Description: "add transactions.stand_in", Up: migrate.AddColumn("transactions", "stand_in", "TEXT NOT NULL DEFAULT ''")},
+	{Version: 9, Description: "add transactions.stand_in_policy", Up: migrate.AddColumn("transactions", "stand_in_policy", "TEXT NOT NULL DEFAULT ''")},
+	{Version: 10, Description: "add transactions.stand_in_reconciled_at", Up: migrate.AddColumn("transactions", "stand_in_reconciled_at", "TIMESTAMP")},
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"flarrocca/migrate"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestDatabase(t *testing.T, schema string) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "payment.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	initSQL, err := os.ReadFile(schema)
+	assert.NoError(t, err)
+	_, err = db.Exec(string(initSQL))
+	assert.NoError(t, err)
+	return db
+}
+
+func columnNames(t *testing.T, db *sql.DB, table string) []string {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		columns map[string][]string
+	}{
+		{
+			name:   "Success - Database created by the first release",
+			schema: filepath.Join("testdata", "first_release_init.sql"),
+			columns: map[string][]string{
+				"transactions": {"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at", "refunded_at",
+					"reviewer", "reviewed_at", "stand_in", "stand_in_policy", "stand_in_reconciled_at"},
+				"alerts": {"id", "alert_type", "user_id", "card_id", "transaction_id", "message", "created_at", "evidence", "dedup_key"},
+			},
+		},
+		{
+			name:   "Success - Database created before the IP locations",
+			schema: filepath.Join("testdata", "payment_context_init.sql"),
+			columns: map[string][]string{
+				"transaction_contexts": {"transaction_id", "ip_address", "ip_country", "ip_asn", "device_id", "user_agent", "email",
+					"billing_line1", "billing_city", "billing_postal_code", "billing_country",
+					"shipping_line1", "shipping_city", "shipping_postal_code", "shipping_country", "merchant_id", "mcc",
+					"ip_latitude", "ip_longitude"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDatabase(t, tt.schema)
+
+			assert.NoError(t, migrate.Run(db, Migrations))
+			// The service restarts on a migrated database.
+			assert.NoError(t, migrate.Run(db, Migrations))
+
+			version, err := migrate.Version(db)
+			assert.NoError(t, err)
+			assert.Equal(t, Migrations[len(Migrations)-1].Version, version)
+			for table, columns := range tt.columns {
+				assert.Equal(t, columns, columnNames(t, db, table), table)
+			}
+		})
+	}
+}
+
+// TestStartupOnMigratedDatabase runs init.sql after the migrations, as the service does at startup, and stores a
+// payment with every column added since the database was created.
+func TestStartupOnMigratedDatabase(t *testing.T) {
+	schemas := []string{
+		filepath.Join("testdata", "first_release_init.sql"),
+		filepath.Join("testdata", "payment_context_init.sql"),
+		filepath.Join("..", "database", "init.sql"),
+	}
+
+	for _, schema := range schemas {
+		t.Run(filepath.Base(schema), func(t *testing.T) {
+			db := openTestDatabase(t, schema)
+			assert.NoError(t, migrate.Run(db, Migrations))
+			initSQL, err := os.ReadFile(filepath.Join("..", "database", "init.sql"))
+			assert.NoError(t, err)
+			_, err = db.Exec(string(initSQL))
+			assert.NoError(t, err)
+
+			transactionRepository := NewTransactionRepository(db)
+			now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+			err = transactionRepository.SaveTransaction(Transaction{ID: "txn_1", UserID: 1, CardID: 2, Amount: 100,
+				Status: TransactionStatusPendingReview, CreatedAt: now, StandIn: StandInPending, StandInPolicy: "default",
+				Context: &PaymentContext{IPAddress: "81.2.69.160", IPCountry: "GB", IPLocation: &GeoPoint{Latitude: 51.5, Longitude: -0.1}}})
+			assert.NoError(t, err)
+			assert.NoError(t, transactionRepository.ReviewTransaction("txn_1", TransactionStatusApproved, "alice", now))
+			assert.NoError(t, NewAlertRepository(db).CreateAlert(Alert{AlertType: "aml_structuring", UserID: 1, CardID: 2,
+				Message: "structuring", DedupKey: "structuring:1"}))
+
+			located, err := transactionRepository.GetLocatedPayments(1, 2, 1)
+			assert.NoError(t, err)
+			assert.Len(t, located, 1)
+		})
+	}
+}
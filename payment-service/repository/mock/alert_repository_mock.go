@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: alert_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAlertRepository is a mock of AlertRepository interface.
+type MockAlertRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertRepositoryMockRecorder
+}
+
+// MockAlertRepositoryMockRecorder is the mock recorder for MockAlertRepository.
+type MockAlertRepositoryMockRecorder struct {
+	mock *MockAlertRepository
+}
+
+// NewMockAlertRepository creates a new mock instance.
+func NewMockAlertRepository(ctrl *gomock.Controller) *MockAlertRepository {
+	mock := &MockAlertRepository{ctrl: ctrl}
+	mock.recorder = &MockAlertRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertRepository) EXPECT() *MockAlertRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAlert mocks base method.
+func (m *MockAlertRepository) CreateAlert(alert repository.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlert", alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAlert indicates an expected call of CreateAlert.
+func (mr *MockAlertRepositoryMockRecorder) CreateAlert(alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlert", reflect.TypeOf((*MockAlertRepository)(nil).CreateAlert), alert)
+}
+
+// CreateAlerts mocks base method.
+func (m *MockAlertRepository) CreateAlerts(alerts []repository.Alert) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlerts", alerts)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlerts indicates an expected call of CreateAlerts.
+func (mr *MockAlertRepositoryMockRecorder) CreateAlerts(alerts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlerts", reflect.TypeOf((*MockAlertRepository)(nil).CreateAlerts), alerts)
+}
+
+// ListAlerts mocks base method.
+func (m *MockAlertRepository) ListAlerts(alertType string) ([]repository.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlerts", alertType)
+	ret0, _ := ret[0].([]repository.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlerts indicates an expected call of ListAlerts.
+func (mr *MockAlertRepositoryMockRecorder) ListAlerts(alertType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlerts", reflect.TypeOf((*MockAlertRepository)(nil).ListAlerts), alertType)
+}
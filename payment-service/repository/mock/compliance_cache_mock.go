@@ -0,0 +1,145 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: compliance_cache.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCachedComplianceRepository is a mock of CachedComplianceRepository interface.
+type MockCachedComplianceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCachedComplianceRepositoryMockRecorder
+}
+
+// MockCachedComplianceRepositoryMockRecorder is the mock recorder for MockCachedComplianceRepository.
+type MockCachedComplianceRepositoryMockRecorder struct {
+	mock *MockCachedComplianceRepository
+}
+
+// NewMockCachedComplianceRepository creates a new mock instance.
+func NewMockCachedComplianceRepository(ctrl *gomock.Controller) *MockCachedComplianceRepository {
+	mock := &MockCachedComplianceRepository{ctrl: ctrl}
+	mock.recorder = &MockCachedComplianceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCachedComplianceRepository) EXPECT() *MockCachedComplianceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CheckUserComplianceStatus mocks base method.
+func (m *MockCachedComplianceRepository) CheckUserComplianceStatus(ctx context.Context, userID, cardID int64, amount float64, paymentContext *repository.PaymentContext) (repository.ComplianceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckUserComplianceStatus", ctx, userID, cardID, amount, paymentContext)
+	ret0, _ := ret[0].(repository.ComplianceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckUserComplianceStatus indicates an expected call of CheckUserComplianceStatus.
+func (mr *MockCachedComplianceRepositoryMockRecorder) CheckUserComplianceStatus(ctx, userID, cardID, amount, paymentContext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserComplianceStatus", reflect.TypeOf((*MockCachedComplianceRepository)(nil).CheckUserComplianceStatus), ctx, userID, cardID, amount, paymentContext)
+}
+
+// CheckUserComplianceStatuses mocks base method.
+func (m *MockCachedComplianceRepository) CheckUserComplianceStatuses(ctx context.Context, checks []repository.ComplianceCheck) ([]repository.ComplianceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckUserComplianceStatuses", ctx, checks)
+	ret0, _ := ret[0].([]repository.ComplianceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckUserComplianceStatuses indicates an expected call of CheckUserComplianceStatuses.
+func (mr *MockCachedComplianceRepositoryMockRecorder) CheckUserComplianceStatuses(ctx, checks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserComplianceStatuses", reflect.TypeOf((*MockCachedComplianceRepository)(nil).CheckUserComplianceStatuses), ctx, checks)
+}
+
+// Invalidate mocks base method.
+func (m *MockCachedComplianceRepository) Invalidate(cards []repository.BlockedCard) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Invalidate", cards)
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockCachedComplianceRepositoryMockRecorder) Invalidate(cards interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockCachedComplianceRepository)(nil).Invalidate), cards)
+}
+
+// InvalidateAll mocks base method.
+func (m *MockCachedComplianceRepository) InvalidateAll() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateAll")
+}
+
+// InvalidateAll indicates an expected call of InvalidateAll.
+func (mr *MockCachedComplianceRepositoryMockRecorder) InvalidateAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateAll", reflect.TypeOf((*MockCachedComplianceRepository)(nil).InvalidateAll))
+}
+
+// InvalidateUsers mocks base method.
+func (m *MockCachedComplianceRepository) InvalidateUsers(userIDs []int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateUsers", userIDs)
+}
+
+// InvalidateUsers indicates an expected call of InvalidateUsers.
+func (mr *MockCachedComplianceRepositoryMockRecorder) InvalidateUsers(userIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUsers", reflect.TypeOf((*MockCachedComplianceRepository)(nil).InvalidateUsers), userIDs)
+}
+
+// ListBlockedCards mocks base method.
+func (m *MockCachedComplianceRepository) ListBlockedCards(ctx context.Context) ([]repository.BlockedCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlockedCards", ctx)
+	ret0, _ := ret[0].([]repository.BlockedCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlockedCards indicates an expected call of ListBlockedCards.
+func (mr *MockCachedComplianceRepositoryMockRecorder) ListBlockedCards(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlockedCards", reflect.TypeOf((*MockCachedComplianceRepository)(nil).ListBlockedCards), ctx)
+}
+
+// RequestCardReview mocks base method.
+func (m *MockCachedComplianceRepository) RequestCardReview(ctx context.Context, source string, transaction repository.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestCardReview", ctx, source, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestCardReview indicates an expected call of RequestCardReview.
+func (mr *MockCachedComplianceRepositoryMockRecorder) RequestCardReview(ctx, source, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestCardReview", reflect.TypeOf((*MockCachedComplianceRepository)(nil).RequestCardReview), ctx, source, transaction)
+}
+
+// Stats mocks base method.
+func (m *MockCachedComplianceRepository) Stats() repository.ComplianceCacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(repository.ComplianceCacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockCachedComplianceRepositoryMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockCachedComplianceRepository)(nil).Stats))
+}
@@ -5,6 +5,8 @@
 package mock
 
 import (
+	context "context"
+	repository "flarrocca/payment-service/repository"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,16 +36,60 @@ func (m *MockComplianceRepository) EXPECT() *MockComplianceRepositoryMockRecorde
 }
 
 // CheckUserComplianceStatus mocks base method.
-func (m *MockComplianceRepository) CheckUserComplianceStatus(userID, cardID int64) (bool, string) {
+func (m *MockComplianceRepository) CheckUserComplianceStatus(ctx context.Context, userID, cardID int64, amount float64, paymentContext *repository.PaymentContext) (repository.ComplianceResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckUserComplianceStatus", userID, cardID)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(string)
+	ret := m.ctrl.Call(m, "CheckUserComplianceStatus", ctx, userID, cardID, amount, paymentContext)
+	ret0, _ := ret[0].(repository.ComplianceResponse)
+	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CheckUserComplianceStatus indicates an expected call of CheckUserComplianceStatus.
-func (mr *MockComplianceRepositoryMockRecorder) CheckUserComplianceStatus(userID, cardID interface{}) *gomock.Call {
+func (mr *MockComplianceRepositoryMockRecorder) CheckUserComplianceStatus(ctx, userID, cardID, amount, paymentContext interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserComplianceStatus", reflect.TypeOf((*MockComplianceRepository)(nil).CheckUserComplianceStatus), userID, cardID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserComplianceStatus", reflect.TypeOf((*MockComplianceRepository)(nil).CheckUserComplianceStatus), ctx, userID, cardID, amount, paymentContext)
+}
+
+// CheckUserComplianceStatuses mocks base method.
+func (m *MockComplianceRepository) CheckUserComplianceStatuses(ctx context.Context, checks []repository.ComplianceCheck) ([]repository.ComplianceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckUserComplianceStatuses", ctx, checks)
+	ret0, _ := ret[0].([]repository.ComplianceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckUserComplianceStatuses indicates an expected call of CheckUserComplianceStatuses.
+func (mr *MockComplianceRepositoryMockRecorder) CheckUserComplianceStatuses(ctx, checks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserComplianceStatuses", reflect.TypeOf((*MockComplianceRepository)(nil).CheckUserComplianceStatuses), ctx, checks)
+}
+
+// ListBlockedCards mocks base method.
+func (m *MockComplianceRepository) ListBlockedCards(ctx context.Context) ([]repository.BlockedCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlockedCards", ctx)
+	ret0, _ := ret[0].([]repository.BlockedCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlockedCards indicates an expected call of ListBlockedCards.
+func (mr *MockComplianceRepositoryMockRecorder) ListBlockedCards(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlockedCards", reflect.TypeOf((*MockComplianceRepository)(nil).ListBlockedCards), ctx)
+}
+
+// RequestCardReview mocks base method.
+func (m *MockComplianceRepository) RequestCardReview(ctx context.Context, source string, transaction repository.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestCardReview", ctx, source, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestCardReview indicates an expected call of RequestCardReview.
+func (mr *MockComplianceRepositoryMockRecorder) RequestCardReview(ctx, source, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestCardReview", reflect.TypeOf((*MockComplianceRepository)(nil).RequestCardReview), ctx, source, transaction)
 }
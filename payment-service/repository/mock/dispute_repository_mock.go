@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dispute_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDisputeRepository is a mock of DisputeRepository interface.
+type MockDisputeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDisputeRepositoryMockRecorder
+}
+
+// MockDisputeRepositoryMockRecorder is the mock recorder for MockDisputeRepository.
+type MockDisputeRepositoryMockRecorder struct {
+	mock *MockDisputeRepository
+}
+
+// NewMockDisputeRepository creates a new mock instance.
+func NewMockDisputeRepository(ctrl *gomock.Controller) *MockDisputeRepository {
+	mock := &MockDisputeRepository{ctrl: ctrl}
+	mock.recorder = &MockDisputeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDisputeRepository) EXPECT() *MockDisputeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddEvidence mocks base method.
+func (m *MockDisputeRepository) AddEvidence(disputeID int64, evidence repository.DisputeEvidence) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvidence", disputeID, evidence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvidence indicates an expected call of AddEvidence.
+func (mr *MockDisputeRepositoryMockRecorder) AddEvidence(disputeID, evidence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvidence", reflect.TypeOf((*MockDisputeRepository)(nil).AddEvidence), disputeID, evidence)
+}
+
+// CloseDispute mocks base method.
+func (m *MockDisputeRepository) CloseDispute(disputeID int64, status string, entries []repository.LedgerEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseDispute", disputeID, status, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseDispute indicates an expected call of CloseDispute.
+func (mr *MockDisputeRepositoryMockRecorder) CloseDispute(disputeID, status, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseDispute", reflect.TypeOf((*MockDisputeRepository)(nil).CloseDispute), disputeID, status, entries)
+}
+
+// CreateDispute mocks base method.
+func (m *MockDisputeRepository) CreateDispute(dispute repository.Dispute, entries []repository.LedgerEntry) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDispute", dispute, entries)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDispute indicates an expected call of CreateDispute.
+func (mr *MockDisputeRepositoryMockRecorder) CreateDispute(dispute, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDispute", reflect.TypeOf((*MockDisputeRepository)(nil).CreateDispute), dispute, entries)
+}
+
+// GetDispute mocks base method.
+func (m *MockDisputeRepository) GetDispute(disputeID int64) (repository.Dispute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDispute", disputeID)
+	ret0, _ := ret[0].(repository.Dispute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDispute indicates an expected call of GetDispute.
+func (mr *MockDisputeRepositoryMockRecorder) GetDispute(disputeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDispute", reflect.TypeOf((*MockDisputeRepository)(nil).GetDispute), disputeID)
+}
+
+// GetDisputeEvidence mocks base method.
+func (m *MockDisputeRepository) GetDisputeEvidence(disputeID int64) ([]repository.DisputeEvidence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDisputeEvidence", disputeID)
+	ret0, _ := ret[0].([]repository.DisputeEvidence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDisputeEvidence indicates an expected call of GetDisputeEvidence.
+func (mr *MockDisputeRepositoryMockRecorder) GetDisputeEvidence(disputeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDisputeEvidence", reflect.TypeOf((*MockDisputeRepository)(nil).GetDisputeEvidence), disputeID)
+}
+
+// GetLedgerEntries mocks base method.
+func (m *MockDisputeRepository) GetLedgerEntries(disputeID int64) ([]repository.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerEntries", disputeID)
+	ret0, _ := ret[0].([]repository.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerEntries indicates an expected call of GetLedgerEntries.
+func (mr *MockDisputeRepositoryMockRecorder) GetLedgerEntries(disputeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerEntries", reflect.TypeOf((*MockDisputeRepository)(nil).GetLedgerEntries), disputeID)
+}
+
+// UpdateDisputeStage mocks base method.
+func (m *MockDisputeRepository) UpdateDisputeStage(disputeID int64, stage string, dueAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDisputeStage", disputeID, stage, dueAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDisputeStage indicates an expected call of UpdateDisputeStage.
+func (mr *MockDisputeRepositoryMockRecorder) UpdateDisputeStage(disputeID, stage, dueAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDisputeStage", reflect.TypeOf((*MockDisputeRepository)(nil).UpdateDisputeStage), disputeID, stage, dueAt)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: evidence_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEvidenceRepository is a mock of EvidenceRepository interface.
+type MockEvidenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEvidenceRepositoryMockRecorder
+}
+
+// MockEvidenceRepositoryMockRecorder is the mock recorder for MockEvidenceRepository.
+type MockEvidenceRepositoryMockRecorder struct {
+	mock *MockEvidenceRepository
+}
+
+// NewMockEvidenceRepository creates a new mock instance.
+func NewMockEvidenceRepository(ctrl *gomock.Controller) *MockEvidenceRepository {
+	mock := &MockEvidenceRepository{ctrl: ctrl}
+	mock.recorder = &MockEvidenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEvidenceRepository) EXPECT() *MockEvidenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SaveEvidenceFile mocks base method.
+func (m *MockEvidenceRepository) SaveEvidenceFile(disputeID int64, fileName string, content io.Reader) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEvidenceFile", disputeID, fileName, content)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveEvidenceFile indicates an expected call of SaveEvidenceFile.
+func (mr *MockEvidenceRepositoryMockRecorder) SaveEvidenceFile(disputeID, fileName, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvidenceFile", reflect.TypeOf((*MockEvidenceRepository)(nil).SaveEvidenceFile), disputeID, fileName, content)
+}
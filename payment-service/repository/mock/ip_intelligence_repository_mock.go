@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ip_intelligence_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIPIntelligenceRepository is a mock of IPIntelligenceRepository interface.
+type MockIPIntelligenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPIntelligenceRepositoryMockRecorder
+}
+
+// MockIPIntelligenceRepositoryMockRecorder is the mock recorder for MockIPIntelligenceRepository.
+type MockIPIntelligenceRepositoryMockRecorder struct {
+	mock *MockIPIntelligenceRepository
+}
+
+// NewMockIPIntelligenceRepository creates a new mock instance.
+func NewMockIPIntelligenceRepository(ctrl *gomock.Controller) *MockIPIntelligenceRepository {
+	mock := &MockIPIntelligenceRepository{ctrl: ctrl}
+	mock.recorder = &MockIPIntelligenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPIntelligenceRepository) EXPECT() *MockIPIntelligenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// LookupIP mocks base method.
+func (m *MockIPIntelligenceRepository) LookupIP(ipAddress string) (repository.IPInfo, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupIP", ipAddress)
+	ret0, _ := ret[0].(repository.IPInfo)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// LookupIP indicates an expected call of LookupIP.
+func (mr *MockIPIntelligenceRepositoryMockRecorder) LookupIP(ipAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupIP", reflect.TypeOf((*MockIPIntelligenceRepository)(nil).LookupIP), ipAddress)
+}
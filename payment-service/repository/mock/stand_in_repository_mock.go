@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stand_in_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStandInRepository is a mock of StandInRepository interface.
+type MockStandInRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStandInRepositoryMockRecorder
+}
+
+// MockStandInRepositoryMockRecorder is the mock recorder for MockStandInRepository.
+type MockStandInRepositoryMockRecorder struct {
+	mock *MockStandInRepository
+}
+
+// NewMockStandInRepository creates a new mock instance.
+func NewMockStandInRepository(ctrl *gomock.Controller) *MockStandInRepository {
+	mock := &MockStandInRepository{ctrl: ctrl}
+	mock.recorder = &MockStandInRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStandInRepository) EXPECT() *MockStandInRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSnapshot mocks base method.
+func (m *MockStandInRepository) GetSnapshot() (repository.StandInSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSnapshot")
+	ret0, _ := ret[0].(repository.StandInSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSnapshot indicates an expected call of GetSnapshot.
+func (mr *MockStandInRepositoryMockRecorder) GetSnapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshot", reflect.TypeOf((*MockStandInRepository)(nil).GetSnapshot))
+}
+
+// IsCardBlocked mocks base method.
+func (m *MockStandInRepository) IsCardBlocked(userID, cardID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCardBlocked", userID, cardID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsCardBlocked indicates an expected call of IsCardBlocked.
+func (mr *MockStandInRepositoryMockRecorder) IsCardBlocked(userID, cardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCardBlocked", reflect.TypeOf((*MockStandInRepository)(nil).IsCardBlocked), userID, cardID)
+}
+
+// ReplaceBlockedCards mocks base method.
+func (m *MockStandInRepository) ReplaceBlockedCards(cards []repository.BlockedCard, syncedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceBlockedCards", cards, syncedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceBlockedCards indicates an expected call of ReplaceBlockedCards.
+func (mr *MockStandInRepositoryMockRecorder) ReplaceBlockedCards(cards, syncedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceBlockedCards", reflect.TypeOf((*MockStandInRepository)(nil).ReplaceBlockedCards), cards, syncedAt)
+}
@@ -0,0 +1,197 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transaction_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTransactionRepository is a mock of TransactionRepository interface.
+type MockTransactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionRepositoryMockRecorder
+}
+
+// MockTransactionRepositoryMockRecorder is the mock recorder for MockTransactionRepository.
+type MockTransactionRepositoryMockRecorder struct {
+	mock *MockTransactionRepository
+}
+
+// NewMockTransactionRepository creates a new mock instance.
+func NewMockTransactionRepository(ctrl *gomock.Controller) *MockTransactionRepository {
+	mock := &MockTransactionRepository{ctrl: ctrl}
+	mock.recorder = &MockTransactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FlagSuspectedFraud mocks base method.
+func (m *MockTransactionRepository) FlagSuspectedFraud(transactionIDs []string, status string, refundedAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagSuspectedFraud", transactionIDs, status, refundedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FlagSuspectedFraud indicates an expected call of FlagSuspectedFraud.
+func (mr *MockTransactionRepositoryMockRecorder) FlagSuspectedFraud(transactionIDs, status, refundedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagSuspectedFraud", reflect.TypeOf((*MockTransactionRepository)(nil).FlagSuspectedFraud), transactionIDs, status, refundedAt)
+}
+
+// GetApprovedTransactions mocks base method.
+func (m *MockTransactionRepository) GetApprovedTransactions(userID int64, cardIDs []int64, since time.Time) ([]repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApprovedTransactions", userID, cardIDs, since)
+	ret0, _ := ret[0].([]repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApprovedTransactions indicates an expected call of GetApprovedTransactions.
+func (mr *MockTransactionRepositoryMockRecorder) GetApprovedTransactions(userID, cardIDs, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApprovedTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetApprovedTransactions), userID, cardIDs, since)
+}
+
+// GetLocatedPayments mocks base method.
+func (m *MockTransactionRepository) GetLocatedPayments(userID, cardID int64, limit int) ([]repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocatedPayments", userID, cardID, limit)
+	ret0, _ := ret[0].([]repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocatedPayments indicates an expected call of GetLocatedPayments.
+func (mr *MockTransactionRepositoryMockRecorder) GetLocatedPayments(userID, cardID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocatedPayments", reflect.TypeOf((*MockTransactionRepository)(nil).GetLocatedPayments), userID, cardID, limit)
+}
+
+// GetPaymentsBetween mocks base method.
+func (m *MockTransactionRepository) GetPaymentsBetween(from, to time.Time) ([]repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentsBetween", from, to)
+	ret0, _ := ret[0].([]repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentsBetween indicates an expected call of GetPaymentsBetween.
+func (mr *MockTransactionRepositoryMockRecorder) GetPaymentsBetween(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentsBetween", reflect.TypeOf((*MockTransactionRepository)(nil).GetPaymentsBetween), from, to)
+}
+
+// GetReversalsBetween mocks base method.
+func (m *MockTransactionRepository) GetReversalsBetween(from, to time.Time) ([]repository.Reversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReversalsBetween", from, to)
+	ret0, _ := ret[0].([]repository.Reversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReversalsBetween indicates an expected call of GetReversalsBetween.
+func (mr *MockTransactionRepositoryMockRecorder) GetReversalsBetween(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReversalsBetween", reflect.TypeOf((*MockTransactionRepository)(nil).GetReversalsBetween), from, to)
+}
+
+// GetStandInTransactions mocks base method.
+func (m *MockTransactionRepository) GetStandInTransactions(standIn string, limit int) ([]repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStandInTransactions", standIn, limit)
+	ret0, _ := ret[0].([]repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStandInTransactions indicates an expected call of GetStandInTransactions.
+func (mr *MockTransactionRepositoryMockRecorder) GetStandInTransactions(standIn, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStandInTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetStandInTransactions), standIn, limit)
+}
+
+// GetTransaction mocks base method.
+func (m *MockTransactionRepository) GetTransaction(transactionID string) (repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", transactionID)
+	ret0, _ := ret[0].(repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockTransactionRepositoryMockRecorder) GetTransaction(transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).GetTransaction), transactionID)
+}
+
+// GetUserPayments mocks base method.
+func (m *MockTransactionRepository) GetUserPayments(userID int64, since time.Time, limit int) ([]repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPayments", userID, since, limit)
+	ret0, _ := ret[0].([]repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPayments indicates an expected call of GetUserPayments.
+func (mr *MockTransactionRepositoryMockRecorder) GetUserPayments(userID, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPayments", reflect.TypeOf((*MockTransactionRepository)(nil).GetUserPayments), userID, since, limit)
+}
+
+// ReconcileStandIn mocks base method.
+func (m *MockTransactionRepository) ReconcileStandIn(transactionID, standIn string, reconciledAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileStandIn", transactionID, standIn, reconciledAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReconcileStandIn indicates an expected call of ReconcileStandIn.
+func (mr *MockTransactionRepositoryMockRecorder) ReconcileStandIn(transactionID, standIn, reconciledAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileStandIn", reflect.TypeOf((*MockTransactionRepository)(nil).ReconcileStandIn), transactionID, standIn, reconciledAt)
+}
+
+// ReviewTransaction mocks base method.
+func (m *MockTransactionRepository) ReviewTransaction(transactionID, status, reviewer string, reviewedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewTransaction", transactionID, status, reviewer, reviewedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewTransaction indicates an expected call of ReviewTransaction.
+func (mr *MockTransactionRepositoryMockRecorder) ReviewTransaction(transactionID, status, reviewer, reviewedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).ReviewTransaction), transactionID, status, reviewer, reviewedAt)
+}
+
+// SaveTransaction mocks base method.
+func (m *MockTransactionRepository) SaveTransaction(transaction repository.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTransaction", transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTransaction indicates an expected call of SaveTransaction.
+func (mr *MockTransactionRepositoryMockRecorder) SaveTransaction(transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).SaveTransaction), transaction)
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// StandInSnapshot tells how many blocked cards the local snapshot holds and when it was synced with compliance-service.
+type StandInSnapshot struct {
+	Cards    int       `json:"cards"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source stand_in_repository.go -destination mock/stand_in_repository_mock.go -package mock
+type StandInRepository interface {
+	ReplaceBlockedCards(cards []BlockedCard, syncedAt time.Time) error
+	GetSnapshot() (StandInSnapshot, error)
+	IsCardBlocked(userID int64, cardID int64) (bool, error)
+}
+
+type standInRepository struct {
+	db *sql.DB
+}
+
+func NewStandInRepository(db *sql.DB) StandInRepository {
+	return &standInRepository{db: db}
+}
+
+// ReplaceBlockedCards replaces the whole snapshot, so a card unblocked in compliance-service is dropped from it.
+func (r *standInRepository) ReplaceBlockedCards(cards []BlockedCard, syncedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM stand_in_blocked_cards"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT OR IGNORE INTO stand_in_blocked_cards (user_id, card_id) VALUES (?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, card := range cards {
+		if _, err := stmt.Exec(card.UserID, card.CardID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO stand_in_snapshots (id, cards, synced_at) VALUES (1, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET cards = excluded.cards, synced_at = excluded.synced_at`, len(cards), syncedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSnapshot returns sql.ErrNoRows when the snapshot was never synced.
+func (r *standInRepository) GetSnapshot() (StandInSnapshot, error) {
+	var snapshot StandInSnapshot
+	err := r.db.QueryRow("SELECT cards, synced_at FROM stand_in_snapshots WHERE id = 1").Scan(&snapshot.Cards, &snapshot.SyncedAt)
+	return snapshot, err
+}
+
+func (r *standInRepository) IsCardBlocked(userID int64, cardID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM stand_in_blocked_cards WHERE user_id = ? AND card_id = ?)", userID, cardID).Scan(&exists)
+	return exists, err
+}
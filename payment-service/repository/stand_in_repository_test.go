@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceBlockedCards(t *testing.T) {
+	syncedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	cards := []BlockedCard{{UserID: 1, CardID: 2}, {UserID: 3, CardID: 7}}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Snapshot replaced",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`DELETE FROM stand_in_blocked_cards`).WillReturnResult(sqlmock.NewResult(0, 5))
+				insert := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO stand_in_blocked_cards \(user_id, card_id\) VALUES \(\?, \?\)`)
+				insert.ExpectExec().WithArgs(int64(1), int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
+				insert.ExpectExec().WithArgs(int64(3), int64(7)).WillReturnResult(sqlmock.NewResult(2, 1))
+				dbMock.ExpectExec(`INSERT INTO stand_in_snapshots (.+) ON CONFLICT \(id\) DO UPDATE`).WithArgs(2, syncedAt).WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Previous snapshot kept on error",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`DELETE FROM stand_in_blocked_cards`).WillReturnResult(sqlmock.NewResult(0, 5))
+				insert := dbMock.ExpectPrepare(`INSERT OR IGNORE INTO stand_in_blocked_cards`)
+				insert.ExpectExec().WithArgs(int64(1), int64(2)).WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewStandInRepository(db).ReplaceBlockedCards(cards, syncedAt)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetSnapshot(t *testing.T) {
+	syncedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT cards, synced_at FROM stand_in_snapshots WHERE id = 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"cards", "synced_at"}).AddRow(2, syncedAt))
+	dbMock.ExpectQuery(`SELECT cards, synced_at FROM stand_in_snapshots`).WillReturnError(sql.ErrNoRows)
+
+	standInRepository := NewStandInRepository(db)
+	snapshot, err := standInRepository.GetSnapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, StandInSnapshot{Cards: 2, SyncedAt: syncedAt}, snapshot)
+
+	_, err = standInRepository.GetSnapshot()
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestIsCardBlockedInSnapshot(t *testing.T) {
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM stand_in_blocked_cards WHERE user_id = \? AND card_id = \?\)`).
+		WithArgs(int64(3), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	blocked, err := NewStandInRepository(db).IsCardBlocked(3, 7)
+
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,82 @@
+-- Create transactions table
+CREATE TABLE IF NOT EXISTS transactions (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    card_id INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    status TEXT NOT NULL,
+    suspected_fraud BOOLEAN NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_card ON transactions (user_id, card_id, created_at);
+
+-- Create transaction_contexts table
+CREATE TABLE IF NOT EXISTS transaction_contexts (
+    transaction_id TEXT PRIMARY KEY,
+    ip_address TEXT NOT NULL DEFAULT '',
+    ip_country TEXT NOT NULL DEFAULT '',
+    ip_asn INTEGER NOT NULL DEFAULT 0,
+    device_id TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    billing_line1 TEXT NOT NULL DEFAULT '',
+    billing_city TEXT NOT NULL DEFAULT '',
+    billing_postal_code TEXT NOT NULL DEFAULT '',
+    billing_country TEXT NOT NULL DEFAULT '',
+    shipping_line1 TEXT NOT NULL DEFAULT '',
+    shipping_city TEXT NOT NULL DEFAULT '',
+    shipping_postal_code TEXT NOT NULL DEFAULT '',
+    shipping_country TEXT NOT NULL DEFAULT '',
+    merchant_id TEXT NOT NULL DEFAULT '',
+    mcc TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_contexts_device ON transaction_contexts (device_id);
+
+-- Create alerts table
+CREATE TABLE IF NOT EXISTS alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_type TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    card_id INTEGER NOT NULL,
+    transaction_id TEXT,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create disputes table
+CREATE TABLE IF NOT EXISTS disputes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id TEXT UNIQUE NOT NULL,
+    reason_code TEXT NOT NULL,
+    stage TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    amount REAL NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    opened_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+
+-- Create dispute_evidence table
+CREATE TABLE IF NOT EXISTS dispute_evidence (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dispute_id INTEGER NOT NULL,
+    file_name TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (dispute_id) REFERENCES disputes (id) ON DELETE CASCADE
+);
+
+-- Create ledger_entries table
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dispute_id INTEGER NOT NULL,
+    transaction_id TEXT NOT NULL,
+    entry_type TEXT NOT NULL,
+    amount REAL NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (dispute_id) REFERENCES disputes (id) ON DELETE CASCADE
+);
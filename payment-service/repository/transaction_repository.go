@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const (
+	TransactionStatusApproved      = "approved"
+	TransactionStatusDeclined      = "declined"
+	TransactionStatusRefundPending = "refund_pending"
+	TransactionStatusPendingReview = "pending_review"
+
+	// A payment approved by a stand-in policy while compliance-service was down is pending until checked again.
+	StandInPending   = "pending"
+	StandInConfirmed = "confirmed"
+	StandInRejected  = "rejected"
+
+	ReversalKindRefund     = "refund"
+	ReversalKindChargeback = "chargeback"
+)
+
+type Transaction struct {
+	ID             string          `json:"id"`
+	UserID         int64           `json:"user_id"`
+	CardID         int64           `json:"card_id"`
+	Amount         float64         `json:"amount"`
+	Status         string          `json:"status"`
+	SuspectedFraud bool            `json:"suspected_fraud"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Context        *PaymentContext `json:"context,omitempty"`
+	Reviewer       string          `json:"reviewer,omitempty"`
+	ReviewedAt     *time.Time      `json:"reviewed_at,omitempty"`
+	StandIn        string          `json:"stand_in,omitempty"`
+	StandInPolicy  string          `json:"stand_in_policy,omitempty"`
+}
+
+// Reversal is money flowing back out of a payment, either a refund or a chargeback.
+type Reversal struct {
+	TransactionID string    `json:"transaction_id"`
+	UserID        int64     `json:"user_id"`
+	CardID        int64     `json:"card_id"`
+	Amount        float64   `json:"amount"`
+	Kind          string    `json:"kind"`
+	PaidAt        time.Time `json:"paid_at"`
+	ReversedAt    time.Time `json:"reversed_at"`
+}
+
+type Address struct {
+	Line1      string `json:"line1,omitempty"`
+	City       string `json:"city,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+}
+
+// PaymentContext holds the optional client, customer and merchant details sent with a payment,
+// together with the IP intelligence resolved for the client IP.
+type PaymentContext struct {
+	IPAddress       string    `json:"ip_address,omitempty"`
+	IPCountry       string    `json:"ip_country,omitempty"`
+	IPASN           int64     `json:"ip_asn,omitempty"`
+	IPLocation      *GeoPoint `json:"ip_location,omitempty"`
+	DeviceID        string    `json:"device_id,omitempty"`
+	UserAgent       string    `json:"user_agent,omitempty"`
+	Email           string    `json:"email,omitempty"`
+	BillingAddress  Address   `json:"billing_address"`
+	ShippingAddress Address   `json:"shipping_address"`
+	MerchantID      string    `json:"merchant_id,omitempty"`
+	MCC             string    `json:"mcc,omitempty"`
+}
+
+// Run from the /repository folder the following command to generate the mock:
+// mockgen -source transaction_repository.go -destination mock/transaction_repository_mock.go -package mock
+type TransactionRepository interface {
+	SaveTransaction(transaction Transaction) error
+	GetTransaction(transactionID string) (Transaction, error)
+	GetApprovedTransactions(userID int64, cardIDs []int64, since time.Time) ([]Transaction, error)
+	GetLocatedPayments(userID int64, cardID int64, limit int) ([]Transaction, error)
+	GetUserPayments(userID int64, since time.Time, limit int) ([]Transaction, error)
+	FlagSuspectedFraud(transactionIDs []string, status string, refundedAt *time.Time) error
+	GetPaymentsBetween(from time.Time, to time.Time) ([]Transaction, error)
+	GetReversalsBetween(from time.Time, to time.Time) ([]Reversal, error)
+	ReviewTransaction(transactionID string, status string, reviewer string, reviewedAt time.Time) error
+	GetStandInTransactions(standIn string, limit int) ([]Transaction, error)
+	ReconcileStandIn(transactionID string, standIn string, reconciledAt time.Time) error
+}
+
+type transactionRepository struct {
+	db *sql.DB
+}
+
+func NewTransactionRepository(db *sql.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+// SaveTransaction stores the transaction and, when present, its payment context in the same database transaction.
+func (r *transactionRepository) SaveTransaction(transaction Transaction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO transactions (id, user_id, card_id, amount, status, suspected_fraud, created_at, stand_in, stand_in_policy) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		transaction.ID, transaction.UserID, transaction.CardID, transaction.Amount, transaction.Status, transaction.SuspectedFraud, transaction.CreatedAt,
+		transaction.StandIn, transaction.StandInPolicy)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if paymentContext := transaction.Context; paymentContext != nil {
+		var latitude, longitude sql.NullFloat64
+		if paymentContext.IPLocation != nil {
+			latitude = sql.NullFloat64{Float64: paymentContext.IPLocation.Latitude, Valid: true}
+			longitude = sql.NullFloat64{Float64: paymentContext.IPLocation.Longitude, Valid: true}
+		}
+
+		_, err = tx.Exec(`INSERT INTO transaction_contexts (transaction_id, ip_address, ip_country, ip_asn, ip_latitude, ip_longitude, device_id, user_agent, email,
+			billing_line1, billing_city, billing_postal_code, billing_country,
+			shipping_line1, shipping_city, shipping_postal_code, shipping_country,
+			merchant_id, mcc) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			transaction.ID, paymentContext.IPAddress, paymentContext.IPCountry, paymentContext.IPASN, latitude, longitude, paymentContext.DeviceID, paymentContext.UserAgent, paymentContext.Email,
+			paymentContext.BillingAddress.Line1, paymentContext.BillingAddress.City, paymentContext.BillingAddress.PostalCode, paymentContext.BillingAddress.Country,
+			paymentContext.ShippingAddress.Line1, paymentContext.ShippingAddress.City, paymentContext.ShippingAddress.PostalCode, paymentContext.ShippingAddress.Country,
+			paymentContext.MerchantID, paymentContext.MCC)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *transactionRepository) GetTransaction(transactionID string) (Transaction, error) {
+	var transaction Transaction
+	err := r.db.QueryRow("SELECT id, user_id, card_id, amount, status, suspected_fraud, created_at FROM transactions WHERE id = ?", transactionID).
+		Scan(&transaction.ID, &transaction.UserID, &transaction.CardID, &transaction.Amount, &transaction.Status, &transaction.SuspectedFraud, &transaction.CreatedAt)
+	if err != nil {
+		return Transaction{}, err
+	}
+	return transaction, nil
+}
+
+// GetApprovedTransactions returns the approved payments made with any of the given cards since the provided time.
+func (r *transactionRepository) GetApprovedTransactions(userID int64, cardIDs []int64, since time.Time) ([]Transaction, error) {
+	if len(cardIDs) == 0 {
+		return nil, nil
+	}
+
+	args := []any{userID, TransactionStatusApproved, since}
+	for _, cardID := range cardIDs {
+		args = append(args, cardID)
+	}
+
+	query := "SELECT id, user_id, card_id, amount, status, suspected_fraud, created_at FROM transactions WHERE user_id = ? AND status = ? AND created_at >= ? AND card_id IN (" +
+		placeholders(len(cardIDs)) + ") ORDER BY created_at"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []Transaction
+	for rows.Next() {
+		var transaction Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.CardID, &transaction.Amount, &transaction.Status, &transaction.SuspectedFraud, &transaction.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetLocatedPayments returns the most recent approved payments made by the user or with the card whose client IP was
+// resolved to a country, newest first. The payments held for review or flagged as fraud are left out, they may not
+// have been made by the cardholder.
+func (r *transactionRepository) GetLocatedPayments(userID int64, cardID int64, limit int) ([]Transaction, error) {
+	rows, err := r.db.Query(`SELECT t.id, t.user_id, t.card_id, t.amount, t.status, t.suspected_fraud, t.created_at,
+		c.ip_address, c.ip_country, c.ip_latitude, c.ip_longitude
+		FROM transactions t JOIN transaction_contexts c ON c.transaction_id = t.id
+		WHERE (t.user_id = ? OR t.card_id = ?) AND t.status = ? AND t.suspected_fraud = 0 AND c.ip_country != ''
+		ORDER BY t.created_at DESC LIMIT ?`, userID, cardID, TransactionStatusApproved, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []Transaction
+	for rows.Next() {
+		transaction := Transaction{Context: &PaymentContext{}}
+		var latitude, longitude sql.NullFloat64
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.CardID, &transaction.Amount, &transaction.Status, &transaction.SuspectedFraud, &transaction.CreatedAt,
+			&transaction.Context.IPAddress, &transaction.Context.IPCountry, &latitude, &longitude); err != nil {
+			return nil, err
+		}
+		if latitude.Valid && longitude.Valid {
+			transaction.Context.IPLocation = &GeoPoint{Latitude: latitude.Float64, Longitude: longitude.Float64}
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetUserPayments returns up to limit payments of the user since the provided time, whatever their status, newest first,
+// with the merchant and the country of the client IP when they were sent.
+func (r *transactionRepository) GetUserPayments(userID int64, since time.Time, limit int) ([]Transaction, error) {
+	rows, err := r.db.Query(`SELECT t.id, t.user_id, t.card_id, t.amount, t.status, t.suspected_fraud, t.created_at,
+		COALESCE(c.merchant_id, ''), COALESCE(c.ip_country, '')
+		FROM transactions t LEFT JOIN transaction_contexts c ON c.transaction_id = t.id
+		WHERE t.user_id = ? AND t.created_at >= ?
+		ORDER BY t.created_at DESC, t.id LIMIT ?`, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []Transaction{}
+	for rows.Next() {
+		var transaction Transaction
+		var merchantID, ipCountry string
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.CardID, &transaction.Amount, &transaction.Status, &transaction.SuspectedFraud, &transaction.CreatedAt,
+			&merchantID, &ipCountry); err != nil {
+			return nil, err
+		}
+		if merchantID != "" || ipCountry != "" {
+			transaction.Context = &PaymentContext{MerchantID: merchantID, IPCountry: ipCountry}
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// FlagSuspectedFraud marks the given transactions as suspected fraud and moves them to the provided status.
+// refundedAt, when not nil, records when the refund of the transactions was initiated.
+func (r *transactionRepository) FlagSuspectedFraud(transactionIDs []string, status string, refundedAt *time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var refundTime sql.NullTime
+	if refundedAt != nil {
+		refundTime = sql.NullTime{Time: *refundedAt, Valid: true}
+	}
+
+	stmt, err := tx.Prepare("UPDATE transactions SET suspected_fraud = 1, status = ?, refunded_at = COALESCE(?, refunded_at) WHERE id = ?")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, transactionID := range transactionIDs {
+		_, err := stmt.Exec(status, refundTime, transactionID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPaymentsBetween returns the non-declined payments made from the given time up to, excluding, the end time,
+// ordered by user and time.
+func (r *transactionRepository) GetPaymentsBetween(from time.Time, to time.Time) ([]Transaction, error) {
+	rows, err := r.db.Query(`SELECT id, user_id, card_id, amount, status, suspected_fraud, created_at FROM transactions
+		WHERE created_at >= ? AND created_at < ? AND status != ? ORDER BY user_id, created_at, id`, from, to, TransactionStatusDeclined)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []Transaction
+	for rows.Next() {
+		var transaction Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.CardID, &transaction.Amount, &transaction.Status, &transaction.SuspectedFraud, &transaction.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetReversalsBetween returns the refunds initiated and the chargebacks debited (the negative ledger entries) from the given time up to, excluding,
+// the end time, together with the payment they reverse, ordered by user and reversal time.
+func (r *transactionRepository) GetReversalsBetween(from time.Time, to time.Time) ([]Reversal, error) {
+	rows, err := r.db.Query(`SELECT id, user_id, card_id, amount, created_at, refunded_at, ? FROM transactions
+			WHERE refunded_at >= ? AND refunded_at < ?
+		UNION ALL
+		SELECT t.id, t.user_id, t.card_id, ABS(l.amount), t.created_at, l.created_at, ? FROM ledger_entries l JOIN transactions t ON t.id = l.transaction_id
+			WHERE l.amount < 0 AND l.created_at >= ? AND l.created_at < ?
+		ORDER BY 2, 6, 1`,
+		ReversalKindRefund, from, to, ReversalKindChargeback, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reversals []Reversal
+	for rows.Next() {
+		var reversal Reversal
+		if err := rows.Scan(&reversal.TransactionID, &reversal.UserID, &reversal.CardID, &reversal.Amount, &reversal.PaidAt, &reversal.ReversedAt, &reversal.Kind); err != nil {
+			return nil, err
+		}
+		reversals = append(reversals, reversal)
+	}
+
+	return reversals, nil
+}
+
+// ReviewTransaction records the decision of a reviewer on a payment held for manual review. It returns sql.ErrNoRows when
+// the transaction is not pending review.
+func (r *transactionRepository) ReviewTransaction(transactionID string, status string, reviewer string, reviewedAt time.Time) error {
+	result, err := r.db.Exec("UPDATE transactions SET status = ?, reviewer = ?, reviewed_at = ? WHERE id = ? AND status = ?",
+		status, reviewer, reviewedAt, transactionID, TransactionStatusPendingReview)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetStandInTransactions returns the oldest stand-in payments in the given stand-in state, with the payment context
+// details compliance-service checks.
+func (r *transactionRepository) GetStandInTransactions(standIn string, limit int) ([]Transaction, error) {
+	rows, err := r.db.Query(`SELECT t.id, t.user_id, t.card_id, t.amount, t.status, t.suspected_fraud, t.created_at, t.stand_in, t.stand_in_policy,
+		COALESCE(c.ip_address, ''), COALESCE(c.email, ''), COALESCE(c.device_id, ''), COALESCE(c.merchant_id, '')
+		FROM transactions t LEFT JOIN transaction_contexts c ON c.transaction_id = t.id
+		WHERE t.stand_in = ? ORDER BY t.created_at, t.id LIMIT ?`, standIn, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []Transaction
+	for rows.Next() {
+		var transaction Transaction
+		var paymentContext PaymentContext
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.CardID, &transaction.Amount, &transaction.Status, &transaction.SuspectedFraud, &transaction.CreatedAt,
+			&transaction.StandIn, &transaction.StandInPolicy,
+			&paymentContext.IPAddress, &paymentContext.Email, &paymentContext.DeviceID, &paymentContext.MerchantID); err != nil {
+			return nil, err
+		}
+		if paymentContext != (PaymentContext{}) {
+			transaction.Context = &paymentContext
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// ReconcileStandIn records the outcome of checking a stand-in payment again. It returns sql.ErrNoRows when the
+// payment is not pending reconciliation.
+func (r *transactionRepository) ReconcileStandIn(transactionID string, standIn string, reconciledAt time.Time) error {
+	result, err := r.db.Exec("UPDATE transactions SET stand_in = ?, stand_in_reconciled_at = ? WHERE id = ? AND stand_in = ?",
+		standIn, reconciledAt, transactionID, StandInPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
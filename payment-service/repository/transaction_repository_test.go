@@ -0,0 +1,656 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveTransaction(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		input      Transaction
+		on         func(dbMock sqlmock.Sqlmock, in Transaction)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:  "Success - Transaction saved",
+			input: Transaction{ID: "txn_1234567", UserID: 1, CardID: 2, Amount: 100.5, Status: TransactionStatusApproved, CreatedAt: createdAt},
+			on: func(dbMock sqlmock.Sqlmock, in Transaction) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO transactions \(id, user_id, card_id, amount, status, suspected_fraud, created_at, stand_in, stand_in_policy\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?, \?\)`).
+					WithArgs(in.ID, in.UserID, in.CardID, in.Amount, in.Status, false, in.CreatedAt, "", "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Success - Stand-in transaction saved with its payment context",
+			input: Transaction{ID: "txn_1234567", UserID: 1, CardID: 2, Amount: 100.5, Status: TransactionStatusApproved, SuspectedFraud: true, CreatedAt: createdAt,
+				StandIn: StandInPending, StandInPolicy: "fail_open",
+				Context: &PaymentContext{
+					IPAddress:      "203.0.113.7",
+					IPCountry:      "AU",
+					IPASN:          64500,
+					IPLocation:     &GeoPoint{Latitude: -33.87, Longitude: 151.21},
+					DeviceID:       "device-1",
+					Email:          "alice@example.com",
+					BillingAddress: Address{Line1: "1 Main St", City: "London", PostalCode: "N1 9GU", Country: "GB"},
+					MerchantID:     "merchant-1",
+					MCC:            "5411",
+				}},
+			on: func(dbMock sqlmock.Sqlmock, in Transaction) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(in.ID, in.UserID, in.CardID, in.Amount, in.Status, true, in.CreatedAt, StandInPending, "fail_open").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectExec(`INSERT INTO transaction_contexts`).
+					WithArgs(in.ID, "203.0.113.7", "AU", int64(64500), sql.NullFloat64{Float64: -33.87, Valid: true}, sql.NullFloat64{Float64: 151.21, Valid: true}, "device-1", "", "alice@example.com",
+						"1 Main St", "London", "N1 9GU", "GB", "", "", "", "", "merchant-1", "5411").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:  "Failure - Database error",
+			input: Transaction{ID: "txn_1234567", UserID: 1, CardID: 2, Amount: 100.5, Status: TransactionStatusApproved, CreatedAt: createdAt},
+			on: func(dbMock sqlmock.Sqlmock, in Transaction) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO transactions`).
+					WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+		{
+			name:  "Failure - Payment context error rolls back the transaction",
+			input: Transaction{ID: "txn_1234567", UserID: 1, CardID: 2, Amount: 100.5, Status: TransactionStatusApproved, CreatedAt: createdAt, Context: &PaymentContext{DeviceID: "device-1"}},
+			on: func(dbMock sqlmock.Sqlmock, in Transaction) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec(`INSERT INTO transactions`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				dbMock.ExpectExec(`INSERT INTO transaction_contexts`).
+					WillReturnError(errors.New("database error"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock, tt.input)
+
+			err := transactionRepository.SaveTransaction(tt.input)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetApprovedTransactions(t *testing.T) {
+	since := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type input struct {
+		userID  int64
+		cardIDs []int64
+		since   time.Time
+	}
+
+	type output struct {
+		transactions []Transaction
+		err          error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Transactions found",
+			input: input{
+				userID:  1,
+				cardIDs: []int64{1, 2},
+				since:   since,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery(`SELECT id, user_id, card_id, amount, status, suspected_fraud, created_at FROM transactions WHERE user_id = \? AND status = \? AND created_at >= \? AND card_id IN \(\?, \?\) ORDER BY created_at`).
+					WithArgs(in.userID, TransactionStatusApproved, in.since, int64(1), int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at"}).
+						AddRow("txn_1", 1, 2, 50.0, TransactionStatusApproved, false, since.Add(time.Hour)))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, []Transaction{{ID: "txn_1", UserID: 1, CardID: 2, Amount: 50, Status: TransactionStatusApproved, CreatedAt: since.Add(time.Hour)}}, out.transactions)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Success - No cards provided",
+			input: input{
+				userID: 1,
+				since:  since,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.transactions)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			input: input{
+				userID:  1,
+				cardIDs: []int64{1},
+				since:   since,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.transactions)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock, tt.input)
+
+			transactions, err := transactionRepository.GetApprovedTransactions(tt.input.userID, tt.input.cardIDs, tt.input.since)
+			tt.assertFunc(t, output{transactions, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFlagSuspectedFraud(t *testing.T) {
+	refundedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type input struct {
+		transactionIDs []string
+		status         string
+		refundedAt     *time.Time
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(dbMock sqlmock.Sqlmock, in input)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Transactions flagged",
+			input: input{
+				transactionIDs: []string{"txn_1", "txn_2"},
+				status:         TransactionStatusRefundPending,
+				refundedAt:     &refundedAt,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				refundTime := sql.NullTime{Time: refundedAt, Valid: true}
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`UPDATE transactions SET suspected_fraud = 1, status = \?, refunded_at = COALESCE\(\?, refunded_at\) WHERE id = \?`)
+				stmt.ExpectExec().WithArgs(in.status, refundTime, "txn_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				stmt.ExpectExec().WithArgs(in.status, refundTime, "txn_2").WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Exec error during update",
+			input: input{
+				transactionIDs: []string{"txn_1"},
+				status:         TransactionStatusApproved,
+			},
+			on: func(dbMock sqlmock.Sqlmock, in input) {
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`UPDATE transactions SET suspected_fraud = 1`)
+				stmt.ExpectExec().WithArgs(in.status, sql.NullTime{}, "txn_1").WillReturnError(errors.New("failed to execute update"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "failed to execute update")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock, tt.input)
+
+			err := transactionRepository.FlagSuspectedFraud(tt.input.transactionIDs, tt.input.status, tt.input.refundedAt)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetTransaction(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type output struct {
+		transaction Transaction
+		err         error
+	}
+
+	tests := []struct {
+		name          string
+		transactionID string
+		on            func(dbMock sqlmock.Sqlmock, transactionID string)
+		assertFunc    func(t *testing.T, out output)
+	}{
+		{
+			name:          "Success - Transaction found",
+			transactionID: "txn_1",
+			on: func(dbMock sqlmock.Sqlmock, transactionID string) {
+				dbMock.ExpectQuery(`SELECT id, user_id, card_id, amount, status, suspected_fraud, created_at FROM transactions WHERE id = \?`).
+					WithArgs(transactionID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at"}).
+						AddRow("txn_1", 1, 2, 50.0, TransactionStatusApproved, true, createdAt))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, Transaction{ID: "txn_1", UserID: 1, CardID: 2, Amount: 50, Status: TransactionStatusApproved, SuspectedFraud: true, CreatedAt: createdAt}, out.transaction)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name:          "Failure - Transaction not found",
+			transactionID: "txn_404",
+			on: func(dbMock sqlmock.Sqlmock, transactionID string) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions WHERE id = \?`).
+					WithArgs(transactionID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Zero(t, out.transaction)
+				assert.ErrorIs(t, out.err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock, tt.transactionID)
+
+			transaction, err := transactionRepository.GetTransaction(tt.transactionID)
+			tt.assertFunc(t, output{transaction, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLocatedPayments(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type output struct {
+		transactions []Transaction
+		err          error
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Payments with and without location",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions t JOIN transaction_contexts c ON c.transaction_id = t.id WHERE \(t.user_id = \? OR t.card_id = \?\) AND t.status = \? AND t.suspected_fraud = 0 AND c.ip_country != '' ORDER BY t.created_at DESC LIMIT \?`).
+					WithArgs(int64(1), int64(2), TransactionStatusApproved, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at", "ip_address", "ip_country", "ip_latitude", "ip_longitude"}).
+						AddRow("txn_2", 1, 2, 10.0, TransactionStatusApproved, false, createdAt, "192.0.2.1", "GB", 51.5, -0.12).
+						AddRow("txn_1", 1, 2, 20.0, TransactionStatusApproved, false, createdAt.Add(-time.Hour), "198.51.100.1", "US", nil, nil))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Len(t, out.transactions, 2)
+				assert.Equal(t, &PaymentContext{IPAddress: "192.0.2.1", IPCountry: "GB", IPLocation: &GeoPoint{Latitude: 51.5, Longitude: -0.12}}, out.transactions[0].Context)
+				assert.Equal(t, &PaymentContext{IPAddress: "198.51.100.1", IPCountry: "US"}, out.transactions[1].Context)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions t`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Nil(t, out.transactions)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock)
+
+			transactions, err := transactionRepository.GetLocatedPayments(1, 2, 10)
+			tt.assertFunc(t, output{transactions, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetUserPayments(t *testing.T) {
+	since := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type output struct {
+		transactions []Transaction
+		err          error
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Payments with and without context",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions t LEFT JOIN transaction_contexts c ON c.transaction_id = t.id WHERE t.user_id = \? AND t.created_at >= \? ORDER BY t.created_at DESC, t.id LIMIT \?`).
+					WithArgs(int64(1), since, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at", "merchant_id", "ip_country"}).
+						AddRow("txn_2", 1, 2, 10.0, TransactionStatusDeclined, false, since.Add(time.Hour), "grocer-001", "GB").
+						AddRow("txn_1", 1, 3, 20.0, TransactionStatusApproved, true, since, "", ""))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, []Transaction{
+					{ID: "txn_2", UserID: 1, CardID: 2, Amount: 10, Status: TransactionStatusDeclined, CreatedAt: since.Add(time.Hour),
+						Context: &PaymentContext{MerchantID: "grocer-001", IPCountry: "GB"}},
+					{ID: "txn_1", UserID: 1, CardID: 3, Amount: 20, Status: TransactionStatusApproved, SuspectedFraud: true, CreatedAt: since},
+				}, out.transactions)
+			},
+		},
+		{
+			name: "Success - No payment",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions t`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at", "merchant_id", "ip_country"}))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, []Transaction{}, out.transactions)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions t`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Nil(t, out.transactions)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock)
+
+			transactions, err := transactionRepository.GetUserPayments(1, since, 100)
+			tt.assertFunc(t, output{transactions, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetPaymentsBetween(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	type output struct {
+		transactions []Transaction
+		err          error
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Payments in the range",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT id, user_id, card_id, amount, status, suspected_fraud, created_at FROM transactions WHERE created_at >= \? AND created_at < \? AND status != \? ORDER BY user_id, created_at, id`).
+					WithArgs(from, to, TransactionStatusDeclined).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at"}).
+						AddRow("txn_1", 1, 2, 9500.0, TransactionStatusApproved, false, from.Add(time.Hour)).
+						AddRow("txn_2", 1, 2, 9600.0, TransactionStatusRefundPending, true, from.Add(2*time.Hour)))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, []Transaction{
+					{ID: "txn_1", UserID: 1, CardID: 2, Amount: 9500, Status: TransactionStatusApproved, CreatedAt: from.Add(time.Hour)},
+					{ID: "txn_2", UserID: 1, CardID: 2, Amount: 9600, Status: TransactionStatusRefundPending, SuspectedFraud: true, CreatedAt: from.Add(2 * time.Hour)},
+				}, out.transactions)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Nil(t, out.transactions)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock)
+
+			transactions, err := transactionRepository.GetPaymentsBetween(from, to)
+			tt.assertFunc(t, output{transactions, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetReversalsBetween(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	type output struct {
+		reversals []Reversal
+		err       error
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Refunds and chargebacks",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions WHERE refunded_at >= \? AND refunded_at < \? UNION ALL SELECT (.+) FROM ledger_entries l JOIN transactions t ON t.id = l.transaction_id WHERE l.amount < 0 AND l.created_at >= \? AND l.created_at < \? ORDER BY 2, 6, 1`).
+					WithArgs(ReversalKindRefund, from, to, ReversalKindChargeback, from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "created_at", "refunded_at", "kind"}).
+						AddRow("txn_1", 1, 2, 500.0, from, from.Add(time.Hour), ReversalKindRefund).
+						AddRow("txn_2", 1, 2, 700.0, from, from.Add(2*time.Hour), ReversalKindChargeback))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, []Reversal{
+					{TransactionID: "txn_1", UserID: 1, CardID: 2, Amount: 500, Kind: ReversalKindRefund, PaidAt: from, ReversedAt: from.Add(time.Hour)},
+					{TransactionID: "txn_2", UserID: 1, CardID: 2, Amount: 700, Kind: ReversalKindChargeback, PaidAt: from, ReversedAt: from.Add(2 * time.Hour)},
+				}, out.reversals)
+			},
+		},
+		{
+			name: "Failure - Database error",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectQuery(`SELECT (.+) FROM transactions`).
+					WillReturnError(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Nil(t, out.reversals)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			transactionRepository := NewTransactionRepository(db)
+			tt.on(dbMock)
+
+			reversals, err := transactionRepository.GetReversalsBetween(from, to)
+			tt.assertFunc(t, output{reversals, err})
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReviewTransaction(t *testing.T) {
+	reviewedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Held payment approved",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`UPDATE transactions SET status = \?, reviewer = \?, reviewed_at = \? WHERE id = \? AND status = \?`).
+					WithArgs(TransactionStatusApproved, "alice", reviewedAt, "txn_1", TransactionStatusPendingReview).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Payment not pending review",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectExec(`UPDATE transactions SET status = \?`).
+					WithArgs(TransactionStatusApproved, "alice", reviewedAt, "txn_1", TransactionStatusPendingReview).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewTransactionRepository(db).ReviewTransaction("txn_1", TransactionStatusApproved, "alice", reviewedAt)
+			tt.assertFunc(t, err)
+
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetStandInTransactions(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`FROM transactions t LEFT JOIN transaction_contexts c ON c.transaction_id = t.id WHERE t.stand_in = \? ORDER BY t.created_at, t.id LIMIT \?`).
+		WithArgs(StandInPending, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "card_id", "amount", "status", "suspected_fraud", "created_at", "stand_in", "stand_in_policy",
+			"ip_address", "email", "device_id", "merchant_id"}).
+			AddRow("txn_1", 1, 2, 40.0, TransactionStatusApproved, false, createdAt, StandInPending, "fail_open", "", "", "", "merchant-1").
+			AddRow("txn_2", 3, 7, 90.0, TransactionStatusApproved, false, createdAt, StandInPending, "snapshot", "", "", "", ""))
+
+	transactions, err := NewTransactionRepository(db).GetStandInTransactions(StandInPending, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Transaction{
+		{ID: "txn_1", UserID: 1, CardID: 2, Amount: 40, Status: TransactionStatusApproved, CreatedAt: createdAt, StandIn: StandInPending, StandInPolicy: "fail_open",
+			Context: &PaymentContext{MerchantID: "merchant-1"}},
+		{ID: "txn_2", UserID: 3, CardID: 7, Amount: 90, Status: TransactionStatusApproved, CreatedAt: createdAt, StandIn: StandInPending, StandInPolicy: "snapshot"},
+	}, transactions)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReconcileStandIn(t *testing.T) {
+	reconciledAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`UPDATE transactions SET stand_in = \?, stand_in_reconciled_at = \? WHERE id = \? AND stand_in = \?`).
+		WithArgs(StandInRejected, reconciledAt, "txn_1", StandInPending).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(`UPDATE transactions SET stand_in = \?`).
+		WithArgs(StandInConfirmed, reconciledAt, "txn_2", StandInPending).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	transactionRepository := NewTransactionRepository(db)
+	assert.NoError(t, transactionRepository.ReconcileStandIn("txn_1", StandInRejected, reconciledAt))
+	assert.ErrorIs(t, transactionRepository.ReconcileStandIn("txn_2", StandInConfirmed, reconciledAt), sql.ErrNoRows)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
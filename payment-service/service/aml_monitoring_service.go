@@ -0,0 +1,147 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flarrocca/payment-service/repository"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const amlAlertTypePrefix = "aml_"
+
+var (
+	ErrInvalidAMLRange    = errors.New("invalid monitoring range")
+	ErrUnknownAMLScenario = errors.New("unknown AML scenario")
+)
+
+// AMLRun summarises a monitoring run. AlertsCreated can be lower than the findings when the run covers a range
+// already monitored: a finding raises its alert only once.
+type AMLRun struct {
+	From             time.Time    `json:"from"`
+	To               time.Time    `json:"to"`
+	Scenarios        []string     `json:"scenarios"`
+	PaymentsScanned  int          `json:"payments_scanned"`
+	ReversalsScanned int          `json:"reversals_scanned"`
+	Findings         []AMLFinding `json:"findings"`
+	AlertsCreated    int          `json:"alerts_created"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source aml_monitoring_service.go -destination mock/aml_monitoring_service_mock.go -package mock
+type AMLMonitoringService interface {
+	Run(from time.Time, to time.Time, scenarioNames []string) (AMLRun, error)
+}
+
+type amlMonitoringService struct {
+	transactionRepository repository.TransactionRepository
+	alertRepository       repository.AlertRepository
+	scenarios             []AMLScenario
+}
+
+func NewAMLMonitoringService(transactionRepository repository.TransactionRepository, alertRepository repository.AlertRepository,
+	scenarios ...AMLScenario) AMLMonitoringService {
+	return &amlMonitoringService{
+		transactionRepository: transactionRepository,
+		alertRepository:       alertRepository,
+		scenarios:             scenarios,
+	}
+}
+
+// Run evaluates the named scenarios, or all of them when none is named, over the activity from the given time up
+// to, excluding, the end time, and raises an alert of type aml_<scenario> for each finding.
+func (s *amlMonitoringService) Run(from time.Time, to time.Time, scenarioNames []string) (AMLRun, error) {
+	from, to = from.UTC(), to.UTC()
+	if !from.Before(to) {
+		return AMLRun{}, fmt.Errorf("%w: from %s is not before to %s", ErrInvalidAMLRange, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	scenarios, err := s.selectScenarios(scenarioNames)
+	if err != nil {
+		return AMLRun{}, err
+	}
+
+	var lookback time.Duration
+	run := AMLRun{From: from, To: to, Findings: []AMLFinding{}}
+	for _, scenario := range scenarios {
+		lookback = max(lookback, scenario.Lookback())
+		run.Scenarios = append(run.Scenarios, scenario.Name())
+	}
+
+	activity := AMLActivity{From: from, To: to}
+	activity.Payments, err = s.transactionRepository.GetPaymentsBetween(from.Add(-lookback), to)
+	if err != nil {
+		return AMLRun{}, err
+	}
+	activity.Reversals, err = s.transactionRepository.GetReversalsBetween(from, to)
+	if err != nil {
+		return AMLRun{}, err
+	}
+	run.PaymentsScanned, run.ReversalsScanned = len(activity.Payments), len(activity.Reversals)
+
+	var alerts []repository.Alert
+	for _, scenario := range scenarios {
+		for _, finding := range scenario.Evaluate(activity) {
+			evidence, err := json.Marshal(finding.Evidence)
+			if err != nil {
+				return AMLRun{}, err
+			}
+
+			run.Findings = append(run.Findings, finding)
+			alerts = append(alerts, repository.Alert{
+				AlertType: amlAlertTypePrefix + finding.Scenario,
+				UserID:    finding.UserID,
+				CardID:    finding.CardID,
+				Message:   finding.Message,
+				Evidence:  evidence,
+				DedupKey:  amlDedupKey(finding),
+			})
+		}
+	}
+
+	if len(alerts) > 0 {
+		run.AlertsCreated, err = s.alertRepository.CreateAlerts(alerts)
+		if err != nil {
+			return AMLRun{}, err
+		}
+	}
+
+	return run, nil
+}
+
+func (s *amlMonitoringService) selectScenarios(names []string) ([]AMLScenario, error) {
+	if len(names) == 0 {
+		return s.scenarios, nil
+	}
+
+	var selected []AMLScenario
+	for _, name := range names {
+		found := false
+		for _, scenario := range s.scenarios {
+			if scenario.Name() == strings.TrimSpace(name) {
+				selected = append(selected, scenario)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAMLScenario, name)
+		}
+	}
+
+	return selected, nil
+}
+
+// amlDedupKey identifies a finding by its scenario, user and payments, so monitoring the same range twice does not
+// raise the same alert twice.
+func amlDedupKey(finding AMLFinding) string {
+	hash := sha256.New()
+	for _, transaction := range finding.Evidence.Transactions {
+		hash.Write([]byte(transaction.ID + "\n"))
+	}
+	return finding.Scenario + ":" + strconv.FormatInt(finding.UserID, 10) + ":" + hex.EncodeToString(hash.Sum(nil)[:16])
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/repository/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAMLMonitoringRun(t *testing.T) {
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	payments := []repository.Transaction{
+		amlPayment("txn_1", 1, 9500, from.Add(time.Hour)),
+		amlPayment("txn_2", 1, 9600, from.Add(2*time.Hour)),
+		amlPayment("txn_3", 1, 9700, from.Add(3*time.Hour)),
+	}
+
+	type input struct {
+		from      time.Time
+		to        time.Time
+		scenarios []string
+	}
+
+	type output struct {
+		run AMLRun
+		err error
+	}
+
+	type depFields struct {
+		transactionRepositoryMock *mock.MockTransactionRepository
+		alertRepositoryMock       *mock.MockAlertRepository
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name:  "Success - Alerts raised for the findings",
+			input: input{from: from, to: to},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetPaymentsBetween(from.Add(-11*24*time.Hour), to).Return(payments, nil)
+				dep.transactionRepositoryMock.EXPECT().GetReversalsBetween(from, to).Return(nil, nil)
+				dep.alertRepositoryMock.EXPECT().CreateAlerts(gomock.Any()).DoAndReturn(func(alerts []repository.Alert) (int, error) {
+					assert.Len(t, alerts, 2)
+					assert.Equal(t, "aml_structuring", alerts[0].AlertType)
+					assert.Equal(t, int64(1), alerts[0].UserID)
+					assert.Contains(t, string(alerts[0].Evidence), `"id":"txn_1"`)
+					assert.Regexp(t, `^structuring:1:[0-9a-f]{32}$`, alerts[0].DedupKey)
+					assert.Equal(t, "aml_volume_spike", alerts[1].AlertType)
+					return 1, nil
+				})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, []string{AMLScenarioStructuring, AMLScenarioVolumeSpike, AMLScenarioRapidRefund}, out.run.Scenarios)
+				assert.Equal(t, 3, out.run.PaymentsScanned)
+				assert.Len(t, out.run.Findings, 2)
+				assert.Equal(t, 1, out.run.AlertsCreated)
+			},
+		},
+		{
+			name:  "Success - Only the selected scenarios run",
+			input: input{from: from, to: to, scenarios: []string{AMLScenarioRapidRefund}},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetPaymentsBetween(from, to).Return(payments, nil)
+				dep.transactionRepositoryMock.EXPECT().GetReversalsBetween(from, to).Return(nil, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, []string{AMLScenarioRapidRefund}, out.run.Scenarios)
+				assert.Empty(t, out.run.Findings)
+				assert.Zero(t, out.run.AlertsCreated)
+			},
+		},
+		{
+			name:  "Failure - Range ending before it starts",
+			input: input{from: to, to: from},
+			on:    func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrInvalidAMLRange)
+			},
+		},
+		{
+			name:  "Failure - Unknown scenario",
+			input: input{from: from, to: to, scenarios: []string{"smurfing"}},
+			on:    func(dep *depFields, in input) {},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrUnknownAMLScenario)
+				assert.EqualError(t, out.err, "unknown AML scenario: smurfing")
+			},
+		},
+		{
+			name:  "Failure - Error loading payments",
+			input: input{from: from, to: to},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetPaymentsBetween(gomock.Any(), to).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+		{
+			name:  "Failure - Error creating alerts",
+			input: input{from: from, to: to},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetPaymentsBetween(gomock.Any(), to).Return(payments, nil)
+				dep.transactionRepositoryMock.EXPECT().GetReversalsBetween(from, to).Return(nil, nil)
+				dep.alertRepositoryMock.EXPECT().CreateAlerts(gomock.Any()).Return(0, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+			alertRepositoryMock := mock.NewMockAlertRepository(ctrl)
+			tt.on(&depFields{
+				transactionRepositoryMock: transactionRepositoryMock,
+				alertRepositoryMock:       alertRepositoryMock,
+			}, tt.input)
+
+			service := NewAMLMonitoringService(transactionRepositoryMock, alertRepositoryMock,
+				&structuringScenario{threshold: 10000, margin: 0.1, window: 72 * time.Hour, minCount: 3},
+				&volumeSpikeScenario{baselineDays: 10, factor: 5, minAmount: 1000},
+				&rapidRefundScenario{window: 48 * time.Hour, minCount: 2, minAmount: 1000},
+			)
+
+			run, err := service.Run(tt.input.from, tt.input.to, tt.input.scenarios)
+			tt.assertFunc(t, output{run, err})
+		})
+	}
+}
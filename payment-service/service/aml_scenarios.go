@@ -0,0 +1,374 @@
+package service
+
+import (
+	"flarrocca/payment-service/repository"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	AMLScenarioStructuring = "structuring"
+	AMLScenarioVolumeSpike = "volume_spike"
+	AMLScenarioRapidRefund = "rapid_refund"
+
+	defaultReportingThreshold     = 10000.0
+	defaultStructuringMargin      = 0.1
+	defaultStructuringWindowHours = 72
+	defaultStructuringMinCount    = 3
+	defaultSpikeBaselineDays      = 30
+	defaultSpikeFactor            = 5.0
+	defaultSpikeMinAmount         = 5000.0
+	defaultRapidRefundWindowHours = 48
+	defaultRapidRefundMinCount    = 2
+	defaultRapidRefundMinAmount   = 1000.0
+
+	oneDay = 24 * time.Hour
+)
+
+// AMLActivity is what a monitoring run hands to the scenarios. Payments start at From minus the longest look-back of
+// the scenarios and are ordered by user and time; reversals are those made within the range, ordered by user and time.
+type AMLActivity struct {
+	From      time.Time
+	To        time.Time
+	Payments  []repository.Transaction
+	Reversals []repository.Reversal
+}
+
+type AMLEvidenceTransaction struct {
+	ID           string     `json:"id"`
+	CardID       int64      `json:"card_id"`
+	Amount       float64    `json:"amount"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ReversalKind string     `json:"reversal_kind,omitempty"`
+	ReversedAt   *time.Time `json:"reversed_at,omitempty"`
+}
+
+// AMLEvidence lists the payments behind a finding and the figures the scenario compared them with.
+type AMLEvidence struct {
+	Transactions         []AMLEvidenceTransaction `json:"transactions"`
+	Total                float64                  `json:"total"`
+	WindowStart          time.Time                `json:"window_start"`
+	WindowEnd            time.Time                `json:"window_end"`
+	Threshold            float64                  `json:"threshold,omitempty"`
+	BaselineDailyAverage *float64                 `json:"baseline_daily_average,omitempty"`
+}
+
+type AMLFinding struct {
+	Scenario string      `json:"scenario"`
+	UserID   int64       `json:"user_id"`
+	CardID   int64       `json:"card_id"`
+	Message  string      `json:"message"`
+	Evidence AMLEvidence `json:"evidence"`
+}
+
+// AMLScenario looks for a money laundering pattern in the activity of a range. Lookback is how much payment history
+// before the range the scenario needs.
+type AMLScenario interface {
+	Name() string
+	Lookback() time.Duration
+	Evaluate(activity AMLActivity) []AMLFinding
+}
+
+// structuringScenario flags users splitting money into several payments just under the reporting threshold, so
+// that none of them has to be reported on its own.
+type structuringScenario struct {
+	threshold float64
+	margin    float64
+	window    time.Duration
+	minCount  int
+}
+
+// NewStructuringScenario reads AML_REPORTING_THRESHOLD (default 10000), AML_STRUCTURING_MARGIN (default 0.1, the
+// fraction under the threshold considered "just under"), AML_STRUCTURING_WINDOW_HOURS (default 72) and
+// AML_STRUCTURING_MIN_COUNT (default 3).
+func NewStructuringScenario() AMLScenario {
+	margin := floatFromEnv("AML_STRUCTURING_MARGIN", defaultStructuringMargin)
+	if margin >= 1 {
+		margin = defaultStructuringMargin
+	}
+
+	return &structuringScenario{
+		threshold: floatFromEnv("AML_REPORTING_THRESHOLD", defaultReportingThreshold),
+		margin:    margin,
+		window:    time.Duration(intFromEnv("AML_STRUCTURING_WINDOW_HOURS", defaultStructuringWindowHours)) * time.Hour,
+		minCount:  intFromEnv("AML_STRUCTURING_MIN_COUNT", defaultStructuringMinCount),
+	}
+}
+
+func (s *structuringScenario) Name() string {
+	return AMLScenarioStructuring
+}
+
+func (s *structuringScenario) Lookback() time.Duration {
+	return s.window
+}
+
+// Evaluate slides the window over the payments just under the threshold of each user. Overlapping windows with
+// enough payments are merged into one finding, reported when its last payment falls within the range.
+func (s *structuringScenario) Evaluate(activity AMLActivity) []AMLFinding {
+	floor := s.threshold * (1 - s.margin)
+
+	var findings []AMLFinding
+	for _, payments := range paymentsByUser(activity.Payments) {
+		var near []repository.Transaction
+		for _, payment := range payments {
+			if payment.Amount >= floor && payment.Amount < s.threshold {
+				near = append(near, payment)
+			}
+		}
+
+		var clusters [][2]int
+		start := 0
+		for end := range near {
+			for near[end].CreatedAt.Sub(near[start].CreatedAt) > s.window {
+				start++
+			}
+			if end-start+1 < s.minCount {
+				continue
+			}
+			if last := len(clusters) - 1; last >= 0 && start <= clusters[last][1] {
+				clusters[last][1] = end
+			} else {
+				clusters = append(clusters, [2]int{start, end})
+			}
+		}
+
+		for _, cluster := range clusters {
+			cluster := near[cluster[0] : cluster[1]+1]
+			if cluster[len(cluster)-1].CreatedAt.Before(activity.From) {
+				continue
+			}
+
+			evidence := paymentEvidence(cluster)
+			evidence.Threshold = s.threshold
+			findings = append(findings, AMLFinding{
+				Scenario: s.Name(),
+				UserID:   cluster[0].UserID,
+				CardID:   cluster[len(cluster)-1].CardID,
+				Message: fmt.Sprintf("%d payments between %.2f and %.2f, just under the %.2f reporting threshold, within %s, totalling %.2f",
+					len(cluster), floor, s.threshold, s.threshold, evidence.WindowEnd.Sub(evidence.WindowStart).Round(time.Minute), evidence.Total),
+				Evidence: evidence,
+			})
+		}
+	}
+
+	return findings
+}
+
+// volumeSpikeScenario flags the days in which the volume paid by a user is several times its daily average over
+// the previous days.
+type volumeSpikeScenario struct {
+	baselineDays int
+	factor       float64
+	minAmount    float64
+}
+
+// NewVolumeSpikeScenario reads AML_SPIKE_BASELINE_DAYS (default 30), AML_SPIKE_FACTOR (default 5) and
+// AML_SPIKE_MIN_AMOUNT (default 5000, the daily volume below which spikes are ignored).
+func NewVolumeSpikeScenario() AMLScenario {
+	return &volumeSpikeScenario{
+		baselineDays: intFromEnv("AML_SPIKE_BASELINE_DAYS", defaultSpikeBaselineDays),
+		factor:       floatFromEnv("AML_SPIKE_FACTOR", defaultSpikeFactor),
+		minAmount:    floatFromEnv("AML_SPIKE_MIN_AMOUNT", defaultSpikeMinAmount),
+	}
+}
+
+func (s *volumeSpikeScenario) Name() string {
+	return AMLScenarioVolumeSpike
+}
+
+// Lookback covers the baseline of the first day of the range, which may start before the range does.
+func (s *volumeSpikeScenario) Lookback() time.Duration {
+	return time.Duration(s.baselineDays+1) * oneDay
+}
+
+// Evaluate compares each UTC day of the range with the average of the baseline days before it. A user without
+// payments in the baseline is flagged as soon as the day reaches the minimum amount.
+func (s *volumeSpikeScenario) Evaluate(activity AMLActivity) []AMLFinding {
+	firstDay := activity.From.UTC().Truncate(oneDay)
+
+	var findings []AMLFinding
+	for _, payments := range paymentsByUser(activity.Payments) {
+		days := map[time.Time][]repository.Transaction{}
+		var order []time.Time
+		for _, payment := range payments {
+			paymentDay := payment.CreatedAt.UTC().Truncate(oneDay)
+			if _, found := days[paymentDay]; !found {
+				order = append(order, paymentDay)
+			}
+			days[paymentDay] = append(days[paymentDay], payment)
+		}
+
+		for _, spikeDay := range order {
+			if spikeDay.Before(firstDay) {
+				continue
+			}
+
+			volume := sumAmounts(days[spikeDay])
+			if volume < s.minAmount {
+				continue
+			}
+
+			baselineStart := spikeDay.Add(-time.Duration(s.baselineDays) * oneDay)
+			var baselineVolume float64
+			for paymentDay, dayPayments := range days {
+				if !paymentDay.Before(baselineStart) && paymentDay.Before(spikeDay) {
+					baselineVolume += sumAmounts(dayPayments)
+				}
+			}
+			baseline := baselineVolume / float64(s.baselineDays)
+			if baseline > 0 && volume < s.factor*baseline {
+				continue
+			}
+
+			message := fmt.Sprintf("daily volume of %.2f on %s with no payments in the previous %d days", volume, spikeDay.Format(time.DateOnly), s.baselineDays)
+			if baseline > 0 {
+				message = fmt.Sprintf("daily volume of %.2f on %s is %.1f times the %d-day average of %.2f",
+					volume, spikeDay.Format(time.DateOnly), volume/baseline, s.baselineDays, baseline)
+			}
+
+			dayPayments := days[spikeDay]
+			evidence := paymentEvidence(dayPayments)
+			evidence.WindowStart, evidence.WindowEnd = spikeDay, spikeDay.Add(oneDay)
+			evidence.Threshold = max(s.minAmount, s.factor*baseline)
+			evidence.BaselineDailyAverage = &baseline
+			findings = append(findings, AMLFinding{
+				Scenario: s.Name(),
+				UserID:   dayPayments[0].UserID,
+				CardID:   dayPayments[len(dayPayments)-1].CardID,
+				Message:  message,
+				Evidence: evidence,
+			})
+		}
+	}
+
+	return findings
+}
+
+// rapidRefundScenario flags users whose payments are refunded or charged back shortly after being made, moving
+// money in and out through the card networks.
+type rapidRefundScenario struct {
+	window    time.Duration
+	minCount  int
+	minAmount float64
+}
+
+// NewRapidRefundScenario reads AML_RAPID_REFUND_WINDOW_HOURS (default 48, the longest time between payment and
+// reversal), AML_RAPID_REFUND_MIN_COUNT (default 2) and AML_RAPID_REFUND_MIN_AMOUNT (default 1000).
+func NewRapidRefundScenario() AMLScenario {
+	return &rapidRefundScenario{
+		window:    time.Duration(intFromEnv("AML_RAPID_REFUND_WINDOW_HOURS", defaultRapidRefundWindowHours)) * time.Hour,
+		minCount:  intFromEnv("AML_RAPID_REFUND_MIN_COUNT", defaultRapidRefundMinCount),
+		minAmount: floatFromEnv("AML_RAPID_REFUND_MIN_AMOUNT", defaultRapidRefundMinAmount),
+	}
+}
+
+func (s *rapidRefundScenario) Name() string {
+	return AMLScenarioRapidRefund
+}
+
+func (s *rapidRefundScenario) Lookback() time.Duration {
+	return 0
+}
+
+// Evaluate reports, for each user, the reversals within the range made at most the window after their payment,
+// when there are enough of them and they add up to the minimum amount.
+func (s *rapidRefundScenario) Evaluate(activity AMLActivity) []AMLFinding {
+	byUser := map[int64][]repository.Reversal{}
+	var users []int64
+	for _, reversal := range activity.Reversals {
+		if reversal.ReversedAt.Sub(reversal.PaidAt) > s.window {
+			continue
+		}
+		if _, found := byUser[reversal.UserID]; !found {
+			users = append(users, reversal.UserID)
+		}
+		byUser[reversal.UserID] = append(byUser[reversal.UserID], reversal)
+	}
+
+	var findings []AMLFinding
+	for _, userID := range users {
+		reversals := byUser[userID]
+		if len(reversals) < s.minCount {
+			continue
+		}
+
+		evidence := AMLEvidence{
+			WindowStart: reversals[0].ReversedAt,
+			WindowEnd:   reversals[len(reversals)-1].ReversedAt,
+			Threshold:   s.minAmount,
+		}
+		for _, reversal := range reversals {
+			reversedAt := reversal.ReversedAt
+			evidence.Transactions = append(evidence.Transactions, AMLEvidenceTransaction{
+				ID:           reversal.TransactionID,
+				CardID:       reversal.CardID,
+				Amount:       reversal.Amount,
+				CreatedAt:    reversal.PaidAt,
+				ReversalKind: reversal.Kind,
+				ReversedAt:   &reversedAt,
+			})
+			evidence.Total += reversal.Amount
+		}
+		if evidence.Total < s.minAmount {
+			continue
+		}
+
+		findings = append(findings, AMLFinding{
+			Scenario: s.Name(),
+			UserID:   userID,
+			CardID:   reversals[len(reversals)-1].CardID,
+			Message:  fmt.Sprintf("%d payments totalling %.2f reversed within %s of being made", len(reversals), evidence.Total, s.window),
+			Evidence: evidence,
+		})
+	}
+
+	return findings
+}
+
+// paymentsByUser splits payments ordered by user into the payments of each user.
+func paymentsByUser(payments []repository.Transaction) [][]repository.Transaction {
+	var groups [][]repository.Transaction
+	for i, payment := range payments {
+		if i == 0 || payment.UserID != payments[i-1].UserID {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], payment)
+	}
+	return groups
+}
+
+func paymentEvidence(payments []repository.Transaction) AMLEvidence {
+	evidence := AMLEvidence{
+		WindowStart: payments[0].CreatedAt,
+		WindowEnd:   payments[len(payments)-1].CreatedAt,
+		Total:       sumAmounts(payments),
+	}
+	for _, payment := range payments {
+		evidence.Transactions = append(evidence.Transactions, AMLEvidenceTransaction{
+			ID:        payment.ID,
+			CardID:    payment.CardID,
+			Amount:    payment.Amount,
+			CreatedAt: payment.CreatedAt,
+		})
+	}
+	return evidence
+}
+
+func sumAmounts(payments []repository.Transaction) float64 {
+	var total float64
+	for _, payment := range payments {
+		total += payment.Amount
+	}
+	return total
+}
+
+func intFromEnv(name string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"flarrocca/payment-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func amlPayment(id string, userID int64, amount float64, createdAt time.Time) repository.Transaction {
+	return repository.Transaction{ID: id, UserID: userID, CardID: 2, Amount: amount, Status: repository.TransactionStatusApproved, CreatedAt: createdAt}
+}
+
+func evidenceIDs(finding AMLFinding) []string {
+	var ids []string
+	for _, transaction := range finding.Evidence.Transactions {
+		ids = append(ids, transaction.ID)
+	}
+	return ids
+}
+
+func TestStructuringScenario(t *testing.T) {
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	scenario := &structuringScenario{threshold: 10000, margin: 0.1, window: 72 * time.Hour, minCount: 3}
+
+	tests := []struct {
+		name       string
+		payments   []repository.Transaction
+		assertFunc func(t *testing.T, findings []AMLFinding)
+	}{
+		{
+			name: "Flagged - Payments just under the threshold within the window",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 9500, from.Add(time.Hour)),
+				amlPayment("txn_2", 1, 9800, from.Add(20*time.Hour)),
+				amlPayment("txn_3", 1, 500, from.Add(21*time.Hour)),
+				amlPayment("txn_4", 1, 9900, from.Add(40*time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Len(t, findings, 1)
+				assert.Equal(t, int64(1), findings[0].UserID)
+				assert.Equal(t, []string{"txn_1", "txn_2", "txn_4"}, evidenceIDs(findings[0]))
+				assert.Equal(t, 29200.0, findings[0].Evidence.Total)
+				assert.Equal(t, 10000.0, findings[0].Evidence.Threshold)
+				assert.Equal(t, "3 payments between 9000.00 and 10000.00, just under the 10000.00 reporting threshold, within 39h0m0s, totalling 29200.00", findings[0].Message)
+			},
+		},
+		{
+			name: "Flagged - Overlapping windows merged into one finding",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 9500, from),
+				amlPayment("txn_2", 1, 9500, from.Add(24*time.Hour)),
+				amlPayment("txn_3", 1, 9500, from.Add(48*time.Hour)),
+				amlPayment("txn_4", 1, 9500, from.Add(96*time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Len(t, findings, 1)
+				assert.Equal(t, []string{"txn_1", "txn_2", "txn_3", "txn_4"}, evidenceIDs(findings[0]))
+			},
+		},
+		{
+			name: "Not flagged - Payments spread beyond the window",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 9500, from),
+				amlPayment("txn_2", 1, 9500, from.Add(48*time.Hour)),
+				amlPayment("txn_3", 1, 9500, from.Add(96*time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+		{
+			name: "Not flagged - Payments at the threshold or of different users",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 10000, from),
+				amlPayment("txn_2", 1, 9500, from.Add(time.Hour)),
+				amlPayment("txn_3", 1, 9500, from.Add(2*time.Hour)),
+				amlPayment("txn_4", 2, 9500, from.Add(3*time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+		{
+			name: "Not flagged - Cluster completed before the range",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 9500, from.Add(-50*time.Hour)),
+				amlPayment("txn_2", 1, 9500, from.Add(-40*time.Hour)),
+				amlPayment("txn_3", 1, 9500, from.Add(-30*time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := scenario.Evaluate(AMLActivity{From: from, To: from.Add(7 * 24 * time.Hour), Payments: tt.payments})
+			tt.assertFunc(t, findings)
+		})
+	}
+}
+
+func TestVolumeSpikeScenario(t *testing.T) {
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	scenario := &volumeSpikeScenario{baselineDays: 10, factor: 5, minAmount: 1000}
+
+	tests := []struct {
+		name       string
+		payments   []repository.Transaction
+		assertFunc func(t *testing.T, findings []AMLFinding)
+	}{
+		{
+			name: "Flagged - Day volume several times the baseline",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 300, from.Add(-5*24*time.Hour)),
+				amlPayment("txn_2", 1, 200, from.Add(-2*24*time.Hour)),
+				amlPayment("txn_3", 1, 2000, from.Add(10*time.Hour)),
+				amlPayment("txn_4", 1, 1000, from.Add(11*time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Len(t, findings, 1)
+				assert.Equal(t, []string{"txn_3", "txn_4"}, evidenceIDs(findings[0]))
+				assert.Equal(t, 50.0, *findings[0].Evidence.BaselineDailyAverage)
+				assert.Equal(t, from, findings[0].Evidence.WindowStart)
+				assert.Equal(t, "daily volume of 3000.00 on 2025-03-10 is 60.0 times the 10-day average of 50.00", findings[0].Message)
+			},
+		},
+		{
+			name: "Flagged - No baseline",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 1500, from.Add(30*time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Len(t, findings, 1)
+				assert.Equal(t, "daily volume of 1500.00 on 2025-03-11 with no payments in the previous 10 days", findings[0].Message)
+			},
+		},
+		{
+			name: "Not flagged - Volume in line with the baseline",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 5000, from.Add(-3*24*time.Hour)),
+				amlPayment("txn_2", 1, 2000, from.Add(time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+		{
+			name: "Not flagged - Volume under the minimum amount",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 900, from.Add(time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+		{
+			name: "Not flagged - Spike before the range",
+			payments: []repository.Transaction{
+				amlPayment("txn_1", 1, 5000, from.Add(-time.Hour)),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := scenario.Evaluate(AMLActivity{From: from, To: from.Add(2 * 24 * time.Hour), Payments: tt.payments})
+			tt.assertFunc(t, findings)
+		})
+	}
+}
+
+func TestRapidRefundScenario(t *testing.T) {
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	scenario := &rapidRefundScenario{window: 48 * time.Hour, minCount: 2, minAmount: 1000}
+
+	reversal := func(id string, userID int64, amount float64, paidAt time.Time, after time.Duration) repository.Reversal {
+		return repository.Reversal{TransactionID: id, UserID: userID, CardID: 2, Amount: amount, Kind: repository.ReversalKindRefund,
+			PaidAt: paidAt, ReversedAt: paidAt.Add(after)}
+	}
+
+	tests := []struct {
+		name       string
+		reversals  []repository.Reversal
+		assertFunc func(t *testing.T, findings []AMLFinding)
+	}{
+		{
+			name: "Flagged - Payments reversed shortly after being made",
+			reversals: []repository.Reversal{
+				reversal("txn_1", 1, 600, from, time.Hour),
+				reversal("txn_2", 1, 300, from, 72*time.Hour),
+				reversal("txn_3", 1, 700, from.Add(time.Hour), 2*time.Hour),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Len(t, findings, 1)
+				assert.Equal(t, []string{"txn_1", "txn_3"}, evidenceIDs(findings[0]))
+				assert.Equal(t, repository.ReversalKindRefund, findings[0].Evidence.Transactions[0].ReversalKind)
+				assert.Equal(t, from.Add(time.Hour), *findings[0].Evidence.Transactions[0].ReversedAt)
+				assert.Equal(t, "2 payments totalling 1300.00 reversed within 48h0m0s of being made", findings[0].Message)
+			},
+		},
+		{
+			name: "Not flagged - Too few reversals",
+			reversals: []repository.Reversal{
+				reversal("txn_1", 1, 5000, from, time.Hour),
+				reversal("txn_2", 2, 5000, from, time.Hour),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+		{
+			name: "Not flagged - Total under the minimum amount",
+			reversals: []repository.Reversal{
+				reversal("txn_1", 1, 100, from, time.Hour),
+				reversal("txn_2", 1, 100, from, time.Hour),
+			},
+			assertFunc: func(t *testing.T, findings []AMLFinding) {
+				assert.Empty(t, findings)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := scenario.Evaluate(AMLActivity{From: from, To: from.Add(7 * 24 * time.Hour), Reversals: tt.reversals})
+			tt.assertFunc(t, findings)
+		})
+	}
+}
+
+func TestNewAMLScenarios(t *testing.T) {
+	t.Setenv("AML_REPORTING_THRESHOLD", "3000")
+	t.Setenv("AML_STRUCTURING_MARGIN", "2")
+	t.Setenv("AML_STRUCTURING_MIN_COUNT", "4")
+	t.Setenv("AML_SPIKE_BASELINE_DAYS", "7")
+	t.Setenv("AML_RAPID_REFUND_WINDOW_HOURS", "invalid")
+
+	structuring := NewStructuringScenario().(*structuringScenario)
+	assert.Equal(t, 3000.0, structuring.threshold)
+	assert.Equal(t, defaultStructuringMargin, structuring.margin)
+	assert.Equal(t, 4, structuring.minCount)
+	assert.Equal(t, 72*time.Hour, structuring.Lookback())
+
+	assert.Equal(t, 8*24*time.Hour, NewVolumeSpikeScenario().Lookback())
+	assert.Equal(t, 48*time.Hour, NewRapidRefundScenario().(*rapidRefundScenario).window)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"flarrocca/payment-service/repository"
+	"log/slog"
+)
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source compliance_cache_service.go -destination mock/compliance_cache_service_mock.go -package mock
+type ComplianceCacheService interface {
+	Invalidate(cards []repository.BlockedCard, userIDs []int64, all bool)
+	Stats() repository.ComplianceCacheStats
+}
+
+type complianceCacheService struct {
+	cachedComplianceRepository repository.CachedComplianceRepository
+}
+
+func NewComplianceCacheService(cachedComplianceRepository repository.CachedComplianceRepository) ComplianceCacheService {
+	return &complianceCacheService{cachedComplianceRepository: cachedComplianceRepository}
+}
+
+// Invalidate drops the cached verdicts of the cards compliance-service changed the status of and of every card of the
+// users it changed, or every verdict when the change may affect cards compliance-service cannot list, such as a
+// compromised card feed import or a new deny list entry.
+func (s *complianceCacheService) Invalidate(cards []repository.BlockedCard, userIDs []int64, all bool) {
+	if all {
+		s.cachedComplianceRepository.InvalidateAll()
+		slog.Info("compliance verdict cache cleared")
+		return
+	}
+
+	if len(cards) > 0 {
+		s.cachedComplianceRepository.Invalidate(cards)
+	}
+	if len(userIDs) > 0 {
+		s.cachedComplianceRepository.InvalidateUsers(userIDs)
+	}
+}
+
+func (s *complianceCacheService) Stats() repository.ComplianceCacheStats {
+	return s.cachedComplianceRepository.Stats()
+}
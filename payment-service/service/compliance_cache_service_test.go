@@ -0,0 +1,71 @@
+package service
+
+import (
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/repository/mock"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidateComplianceCache(t *testing.T) {
+	cards := []repository.BlockedCard{{UserID: 3, CardID: 7}}
+
+	tests := []struct {
+		name    string
+		cards   []repository.BlockedCard
+		userIDs []int64
+		all     bool
+		on      func(cachedComplianceRepositoryMock *mock.MockCachedComplianceRepository)
+	}{
+		{
+			name:  "Success - Cards invalidated",
+			cards: cards,
+			on: func(cachedComplianceRepositoryMock *mock.MockCachedComplianceRepository) {
+				cachedComplianceRepositoryMock.EXPECT().Invalidate(cards)
+			},
+		},
+		{
+			name:    "Success - Cards and users invalidated",
+			cards:   cards,
+			userIDs: []int64{4},
+			on: func(cachedComplianceRepositoryMock *mock.MockCachedComplianceRepository) {
+				cachedComplianceRepositoryMock.EXPECT().Invalidate(cards)
+				cachedComplianceRepositoryMock.EXPECT().InvalidateUsers([]int64{4})
+			},
+		},
+		{
+			name:    "Success - Every verdict invalidated",
+			cards:   cards,
+			userIDs: []int64{4},
+			all:     true,
+			on: func(cachedComplianceRepositoryMock *mock.MockCachedComplianceRepository) {
+				cachedComplianceRepositoryMock.EXPECT().InvalidateAll()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cachedComplianceRepositoryMock := mock.NewMockCachedComplianceRepository(ctrl)
+			tt.on(cachedComplianceRepositoryMock)
+
+			NewComplianceCacheService(cachedComplianceRepositoryMock).Invalidate(tt.cards, tt.userIDs, tt.all)
+		})
+	}
+}
+
+func TestComplianceCacheStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stats := repository.ComplianceCacheStats{Hits: 3, Misses: 1, HitRatio: 0.75, Entries: 1, Capacity: 100}
+	cachedComplianceRepositoryMock := mock.NewMockCachedComplianceRepository(ctrl)
+	cachedComplianceRepositoryMock.EXPECT().Stats().Return(stats)
+
+	assert.Equal(t, stats, NewComplianceCacheService(cachedComplianceRepositoryMock).Stats())
+}
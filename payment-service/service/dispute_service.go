@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flarrocca/logging"
+	"flarrocca/payment-service/repository"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	DisputeStageChargeback     = "chargeback"
+	DisputeStageRepresentment  = "representment"
+	DisputeStagePreArbitration = "pre_arbitration"
+	DisputeStageArbitration    = "arbitration"
+
+	DisputeStatusOpen = "open"
+	DisputeStatusWon  = "won"
+	DisputeStatusLost = "lost"
+
+	LedgerEntryChargebackDebit    = "chargeback_debit"
+	LedgerEntryChargebackReversal = "chargeback_reversal"
+)
+
+var (
+	ErrDisputeNotFound       = errors.New("dispute not found")
+	ErrDisputeAlreadyOpened  = errors.New("a dispute has already been opened for the transaction")
+	ErrDisputeClosed         = errors.New("dispute is already closed")
+	ErrDisputeDeadlinePassed = errors.New("the deadline of the current dispute stage has passed")
+	ErrInvalidDisputeStage   = errors.New("invalid dispute stage transition")
+	ErrInvalidDisputeOutcome = errors.New("invalid dispute outcome")
+	ErrInvalidReasonCode     = errors.New("invalid reason code")
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrTransactionNotSettled = errors.New("only approved transactions can be disputed")
+)
+
+type ReasonCode struct {
+	Description string
+	Fraud       bool
+}
+
+// reasonCodes holds the card network reason codes accepted when opening a dispute.
+var reasonCodes = map[string]ReasonCode{
+	"10.1": {Description: "EMV liability shift counterfeit fraud", Fraud: true},
+	"10.2": {Description: "EMV liability shift non-counterfeit fraud", Fraud: true},
+	"10.3": {Description: "other fraud, card present environment", Fraud: true},
+	"10.4": {Description: "other fraud, card absent environment", Fraud: true},
+	"10.5": {Description: "fraud monitoring program", Fraud: true},
+	"4837": {Description: "no cardholder authorization", Fraud: true},
+	"4863": {Description: "cardholder does not recognize", Fraud: true},
+	"4870": {Description: "chip liability shift", Fraud: true},
+	"4871": {Description: "chip/PIN liability shift", Fraud: true},
+	"12.6": {Description: "duplicate processing", Fraud: false},
+	"13.1": {Description: "merchandise or services not received", Fraud: false},
+	"13.3": {Description: "not as described or defective merchandise", Fraud: false},
+	"13.7": {Description: "cancelled merchandise or services", Fraud: false},
+	"4853": {Description: "cardholder dispute", Fraud: false},
+	"4834": {Description: "point of interaction error", Fraud: false},
+}
+
+// disputeStages maps each stage to the one that follows it and the days the next party has to respond.
+var disputeStages = map[string]struct {
+	next         string
+	responseDays int
+}{
+	DisputeStageChargeback:     {next: DisputeStageRepresentment, responseDays: 30},
+	DisputeStageRepresentment:  {next: DisputeStagePreArbitration, responseDays: 30},
+	DisputeStagePreArbitration: {next: DisputeStageArbitration, responseDays: 30},
+	DisputeStageArbitration:    {responseDays: 10},
+}
+
+type DisputeDetails struct {
+	repository.Dispute
+	Evidence      []repository.DisputeEvidence `json:"evidence"`
+	LedgerEntries []repository.LedgerEntry     `json:"ledger_entries"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source dispute_service.go -destination mock/dispute_service_mock.go -package mock
+type DisputeService interface {
+	OpenDispute(ctx context.Context, transactionID string, reasonCode string) (repository.Dispute, error)
+	GetDispute(disputeID int64) (DisputeDetails, error)
+	AdvanceDispute(disputeID int64, stage string) (repository.Dispute, error)
+	ResolveDispute(disputeID int64, outcome string) error
+	AddEvidence(disputeID int64, fileName string, content io.Reader) error
+}
+
+type disputeService struct {
+	disputeRepository     repository.DisputeRepository
+	transactionRepository repository.TransactionRepository
+	evidenceRepository    repository.EvidenceRepository
+	complianceRepository  repository.ComplianceRepository
+	now                   func() time.Time
+}
+
+func NewDisputeService(disputeRepository repository.DisputeRepository, transactionRepository repository.TransactionRepository, evidenceRepository repository.EvidenceRepository, complianceRepository repository.ComplianceRepository) DisputeService {
+	return &disputeService{
+		disputeRepository:     disputeRepository,
+		transactionRepository: transactionRepository,
+		evidenceRepository:    evidenceRepository,
+		complianceRepository:  complianceRepository,
+		now:                   func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// OpenDispute registers the first chargeback against a transaction and debits its amount from the merchant.
+// Disputes filed with a fraud reason code also ask compliance-service to review the card.
+func (s *disputeService) OpenDispute(ctx context.Context, transactionID string, reasonCode string) (repository.Dispute, error) {
+	reason, ok := reasonCodes[reasonCode]
+	if !ok {
+		return repository.Dispute{}, ErrInvalidReasonCode
+	}
+
+	transaction, err := s.transactionRepository.GetTransaction(transactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.Dispute{}, ErrTransactionNotFound
+		}
+		return repository.Dispute{}, err
+	}
+
+	// a declined or held payment was never settled, a refunded one was already paid back
+	if transaction.Status != repository.TransactionStatusApproved {
+		return repository.Dispute{}, ErrTransactionNotSettled
+	}
+
+	now := s.now()
+	dispute := repository.Dispute{
+		TransactionID: transaction.ID,
+		ReasonCode:    reasonCode,
+		Stage:         DisputeStageChargeback,
+		Status:        DisputeStatusOpen,
+		Amount:        transaction.Amount,
+		DueAt:         now.AddDate(0, 0, disputeStages[DisputeStageChargeback].responseDays),
+		OpenedAt:      now,
+		UpdatedAt:     now,
+	}
+
+	dispute.ID, err = s.disputeRepository.CreateDispute(dispute, []repository.LedgerEntry{{
+		TransactionID: transaction.ID,
+		EntryType:     LedgerEntryChargebackDebit,
+		Amount:        -transaction.Amount,
+		CreatedAt:     now,
+	}})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "UNIQUE constraint failed") {
+			return repository.Dispute{}, ErrDisputeAlreadyOpened
+		}
+		return repository.Dispute{}, err
+	}
+
+	if reason.Fraud {
+		if err := s.complianceRepository.RequestCardReview(ctx, repository.CaseSourceChargeback, transaction); err != nil {
+			slog.ErrorContext(ctx, "error requesting card review", logging.CardID(transaction.CardID), slog.Int64("dispute_id", dispute.ID), logging.Err(err))
+		}
+	}
+
+	return dispute, nil
+}
+
+func (s *disputeService) GetDispute(disputeID int64) (DisputeDetails, error) {
+	dispute, err := s.getDispute(disputeID)
+	if err != nil {
+		return DisputeDetails{}, err
+	}
+
+	evidence, err := s.disputeRepository.GetDisputeEvidence(disputeID)
+	if err != nil {
+		return DisputeDetails{}, err
+	}
+
+	entries, err := s.disputeRepository.GetLedgerEntries(disputeID)
+	if err != nil {
+		return DisputeDetails{}, err
+	}
+
+	return DisputeDetails{Dispute: dispute, Evidence: evidence, LedgerEntries: entries}, nil
+}
+
+// AdvanceDispute moves an open dispute to the following stage, as long as it happens before the current deadline.
+func (s *disputeService) AdvanceDispute(disputeID int64, stage string) (repository.Dispute, error) {
+	dispute, err := s.getOpenDispute(disputeID)
+	if err != nil {
+		return repository.Dispute{}, err
+	}
+
+	if disputeStages[dispute.Stage].next != stage || stage == "" {
+		return repository.Dispute{}, ErrInvalidDisputeStage
+	}
+
+	now := s.now()
+	if now.After(dispute.DueAt) {
+		return repository.Dispute{}, ErrDisputeDeadlinePassed
+	}
+
+	dueAt := now.AddDate(0, 0, disputeStages[stage].responseDays)
+	if err := s.disputeRepository.UpdateDisputeStage(disputeID, stage, dueAt); err != nil {
+		return repository.Dispute{}, err
+	}
+
+	dispute.Stage = stage
+	dispute.DueAt = dueAt
+	dispute.UpdatedAt = now
+	return dispute, nil
+}
+
+// ResolveDispute closes the dispute. When the merchant wins, the chargeback debit is reversed.
+func (s *disputeService) ResolveDispute(disputeID int64, outcome string) error {
+	if outcome != DisputeStatusWon && outcome != DisputeStatusLost {
+		return ErrInvalidDisputeOutcome
+	}
+
+	dispute, err := s.getOpenDispute(disputeID)
+	if err != nil {
+		return err
+	}
+
+	var entries []repository.LedgerEntry
+	if outcome == DisputeStatusWon {
+		entries = append(entries, repository.LedgerEntry{
+			TransactionID: dispute.TransactionID,
+			EntryType:     LedgerEntryChargebackReversal,
+			Amount:        dispute.Amount,
+			CreatedAt:     s.now(),
+		})
+	}
+
+	return s.disputeRepository.CloseDispute(disputeID, outcome, entries)
+}
+
+func (s *disputeService) AddEvidence(disputeID int64, fileName string, content io.Reader) error {
+	if _, err := s.getOpenDispute(disputeID); err != nil {
+		return err
+	}
+
+	filePath, err := s.evidenceRepository.SaveEvidenceFile(disputeID, fileName, content)
+	if err != nil {
+		return err
+	}
+
+	return s.disputeRepository.AddEvidence(disputeID, repository.DisputeEvidence{FileName: fileName, FilePath: filePath})
+}
+
+func (s *disputeService) getDispute(disputeID int64) (repository.Dispute, error) {
+	dispute, err := s.disputeRepository.GetDispute(disputeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Dispute{}, ErrDisputeNotFound
+	}
+	return dispute, err
+}
+
+func (s *disputeService) getOpenDispute(disputeID int64) (repository.Dispute, error) {
+	dispute, err := s.getDispute(disputeID)
+	if err != nil {
+		return repository.Dispute{}, err
+	}
+
+	if dispute.Status != DisputeStatusOpen {
+		return repository.Dispute{}, ErrDisputeClosed
+	}
+
+	return dispute, nil
+}
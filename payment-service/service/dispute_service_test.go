@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/repository/mock"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type disputeDepFields struct {
+	disputeRepositoryMock     *mock.MockDisputeRepository
+	transactionRepositoryMock *mock.MockTransactionRepository
+	evidenceRepositoryMock    *mock.MockEvidenceRepository
+	complianceRepositoryMock  *mock.MockComplianceRepository
+}
+
+func newTestDisputeService(ctrl *gomock.Controller, now time.Time) (*disputeService, *disputeDepFields) {
+	dep := &disputeDepFields{
+		disputeRepositoryMock:     mock.NewMockDisputeRepository(ctrl),
+		transactionRepositoryMock: mock.NewMockTransactionRepository(ctrl),
+		evidenceRepositoryMock:    mock.NewMockEvidenceRepository(ctrl),
+		complianceRepositoryMock:  mock.NewMockComplianceRepository(ctrl),
+	}
+
+	return &disputeService{
+		disputeRepository:     dep.disputeRepositoryMock,
+		transactionRepository: dep.transactionRepositoryMock,
+		evidenceRepository:    dep.evidenceRepositoryMock,
+		complianceRepository:  dep.complianceRepositoryMock,
+		now:                   func() time.Time { return now },
+	}, dep
+}
+
+func TestOpenDispute(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	transaction := repository.Transaction{ID: "txn_1", UserID: 1, CardID: 2, Amount: 50, Status: repository.TransactionStatusApproved}
+
+	type input struct {
+		transactionID string
+		reasonCode    string
+	}
+
+	type output struct {
+		dispute repository.Dispute
+		err     error
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*disputeDepFields, input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Fraud dispute opened and card sent to review",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "10.4",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(transaction, nil)
+				dep.disputeRepositoryMock.EXPECT().CreateDispute(repository.Dispute{
+					TransactionID: "txn_1",
+					ReasonCode:    "10.4",
+					Stage:         DisputeStageChargeback,
+					Status:        DisputeStatusOpen,
+					Amount:        50,
+					DueAt:         now.AddDate(0, 0, 30),
+					OpenedAt:      now,
+					UpdatedAt:     now,
+				}, []repository.LedgerEntry{{TransactionID: "txn_1", EntryType: LedgerEntryChargebackDebit, Amount: -50, CreatedAt: now}}).Return(int64(3), nil)
+				dep.complianceRepositoryMock.EXPECT().RequestCardReview(gomock.Any(), repository.CaseSourceChargeback, transaction).Return(nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, int64(3), out.dispute.ID)
+				assert.Equal(t, DisputeStageChargeback, out.dispute.Stage)
+			},
+		},
+		{
+			name: "Success - Non-fraud dispute does not involve compliance",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "13.1",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(transaction, nil)
+				dep.disputeRepositoryMock.EXPECT().CreateDispute(gomock.Any(), gomock.Any()).Return(int64(4), nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, int64(4), out.dispute.ID)
+			},
+		},
+		{
+			name: "Success - Review request failure does not fail the dispute",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "4837",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(transaction, nil)
+				dep.disputeRepositoryMock.EXPECT().CreateDispute(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+				dep.complianceRepositoryMock.EXPECT().RequestCardReview(gomock.Any(), repository.CaseSourceChargeback, transaction).Return(errors.New("connection refused"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, int64(5), out.dispute.ID)
+			},
+		},
+		{
+			name: "Failure - Unknown reason code",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "99.9",
+			},
+			on: func(dep *disputeDepFields, in input) {},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrInvalidReasonCode)
+			},
+		},
+		{
+			name: "Failure - Transaction not found",
+			input: input{
+				transactionID: "txn_404",
+				reasonCode:    "10.4",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(repository.Transaction{}, sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrTransactionNotFound)
+			},
+		},
+		{
+			name: "Failure - Declined transaction",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "10.4",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(repository.Transaction{ID: "txn_1", Status: repository.TransactionStatusDeclined}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrTransactionNotSettled)
+			},
+		},
+		{
+			name: "Failure - Payment held for manual review",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "10.4",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(repository.Transaction{ID: "txn_1", Status: repository.TransactionStatusPendingReview}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrTransactionNotSettled)
+			},
+		},
+		{
+			name: "Failure - Payment pending a refund",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "10.4",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(repository.Transaction{ID: "txn_1", Status: repository.TransactionStatusRefundPending}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrTransactionNotSettled)
+			},
+		},
+		{
+			name: "Failure - Dispute already opened",
+			input: input{
+				transactionID: "txn_1",
+				reasonCode:    "10.4",
+			},
+			on: func(dep *disputeDepFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetTransaction(in.transactionID).Return(transaction, nil)
+				dep.disputeRepositoryMock.EXPECT().CreateDispute(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("UNIQUE constraint failed: disputes.transaction_id"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrDisputeAlreadyOpened)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestDisputeService(ctrl, now)
+			tt.on(dep, tt.input)
+
+			dispute, err := service.OpenDispute(context.Background(), tt.input.transactionID, tt.input.reasonCode)
+			tt.assertFunc(t, output{dispute, err})
+		})
+	}
+}
+
+func TestAdvanceDispute(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	openDispute := repository.Dispute{ID: 3, TransactionID: "txn_1", Stage: DisputeStageChargeback, Status: DisputeStatusOpen, Amount: 50, DueAt: now.AddDate(0, 0, 5)}
+
+	type output struct {
+		dispute repository.Dispute
+		err     error
+	}
+
+	tests := []struct {
+		name       string
+		stage      string
+		on         func(*disputeDepFields)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name:  "Success - Merchant represents the chargeback",
+			stage: DisputeStageRepresentment,
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(openDispute, nil)
+				dep.disputeRepositoryMock.EXPECT().UpdateDisputeStage(int64(3), DisputeStageRepresentment, now.AddDate(0, 0, 30)).Return(nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Equal(t, DisputeStageRepresentment, out.dispute.Stage)
+				assert.Equal(t, now.AddDate(0, 0, 30), out.dispute.DueAt)
+			},
+		},
+		{
+			name:  "Failure - Stages cannot be skipped",
+			stage: DisputeStageArbitration,
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(openDispute, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrInvalidDisputeStage)
+			},
+		},
+		{
+			name:  "Failure - Arbitration is the last stage",
+			stage: "",
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(repository.Dispute{ID: 3, Stage: DisputeStageArbitration, Status: DisputeStatusOpen, DueAt: now.AddDate(0, 0, 5)}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrInvalidDisputeStage)
+			},
+		},
+		{
+			name:  "Failure - Deadline passed",
+			stage: DisputeStageRepresentment,
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(repository.Dispute{ID: 3, Stage: DisputeStageChargeback, Status: DisputeStatusOpen, DueAt: now.AddDate(0, 0, -1)}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrDisputeDeadlinePassed)
+			},
+		},
+		{
+			name:  "Failure - Dispute closed",
+			stage: DisputeStageRepresentment,
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(repository.Dispute{ID: 3, Stage: DisputeStageChargeback, Status: DisputeStatusLost}, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrDisputeClosed)
+			},
+		},
+		{
+			name:  "Failure - Dispute not found",
+			stage: DisputeStageRepresentment,
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(repository.Dispute{}, sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.ErrorIs(t, out.err, ErrDisputeNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestDisputeService(ctrl, now)
+			tt.on(dep)
+
+			dispute, err := service.AdvanceDispute(3, tt.stage)
+			tt.assertFunc(t, output{dispute, err})
+		})
+	}
+}
+
+func TestResolveDispute(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	openDispute := repository.Dispute{ID: 3, TransactionID: "txn_1", Stage: DisputeStageRepresentment, Status: DisputeStatusOpen, Amount: 50}
+
+	tests := []struct {
+		name       string
+		outcome    string
+		on         func(*disputeDepFields)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:    "Success - Won dispute reverses the chargeback",
+			outcome: DisputeStatusWon,
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(openDispute, nil)
+				dep.disputeRepositoryMock.EXPECT().CloseDispute(int64(3), DisputeStatusWon, []repository.LedgerEntry{
+					{TransactionID: "txn_1", EntryType: LedgerEntryChargebackReversal, Amount: 50, CreatedAt: now},
+				}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "Success - Lost dispute keeps the chargeback",
+			outcome: DisputeStatusLost,
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(openDispute, nil)
+				dep.disputeRepositoryMock.EXPECT().CloseDispute(int64(3), DisputeStatusLost, nil).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "Failure - Invalid outcome",
+			outcome: "draw",
+			on:      func(dep *disputeDepFields) {},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrInvalidDisputeOutcome)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestDisputeService(ctrl, now)
+			tt.on(dep)
+
+			tt.assertFunc(t, service.ResolveDispute(3, tt.outcome))
+		})
+	}
+}
+
+func TestAddDisputeEvidence(t *testing.T) {
+	tests := []struct {
+		name       string
+		on         func(*disputeDepFields)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Evidence stored and registered",
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(repository.Dispute{ID: 3, Status: DisputeStatusOpen}, nil)
+				dep.evidenceRepositoryMock.EXPECT().SaveEvidenceFile(int64(3), "receipt.pdf", gomock.Any()).Return("evidence/3/receipt.pdf", nil)
+				dep.disputeRepositoryMock.EXPECT().AddEvidence(int64(3), repository.DisputeEvidence{FileName: "receipt.pdf", FilePath: "evidence/3/receipt.pdf"}).Return(nil)
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - File cannot be stored",
+			on: func(dep *disputeDepFields) {
+				dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(repository.Dispute{ID: 3, Status: DisputeStatusOpen}, nil)
+				dep.evidenceRepositoryMock.EXPECT().SaveEvidenceFile(int64(3), "receipt.pdf", gomock.Any()).Return("", errors.New("file already exists"))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "file already exists")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestDisputeService(ctrl, time.Now())
+			tt.on(dep)
+
+			tt.assertFunc(t, service.AddEvidence(3, "receipt.pdf", strings.NewReader("content")))
+		})
+	}
+}
+
+func TestGetDisputeDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, dep := newTestDisputeService(ctrl, time.Now())
+	dep.disputeRepositoryMock.EXPECT().GetDispute(int64(3)).Return(repository.Dispute{ID: 3, TransactionID: "txn_1"}, nil)
+	dep.disputeRepositoryMock.EXPECT().GetDisputeEvidence(int64(3)).Return([]repository.DisputeEvidence{{FileName: "receipt.pdf"}}, nil)
+	dep.disputeRepositoryMock.EXPECT().GetLedgerEntries(int64(3)).Return([]repository.LedgerEntry{{EntryType: LedgerEntryChargebackDebit, Amount: -50}}, nil)
+
+	details, err := service.GetDispute(3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), details.ID)
+	assert.Len(t, details.Evidence, 1)
+	assert.Len(t, details.LedgerEntries, 1)
+}
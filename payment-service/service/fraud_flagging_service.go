@@ -0,0 +1,104 @@
+package service
+
+import (
+	"flarrocca/logging"
+	"flarrocca/payment-service/repository"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultLookbackHours = 24
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source fraud_flagging_service.go -destination mock/fraud_flagging_service_mock.go -package mock
+type FraudFlaggingService interface {
+	FlagReportedCards(userID int64, cardIDs []int64, reportedAt time.Time) ([]string, error)
+	ListAlerts(alertType string) ([]repository.Alert, error)
+}
+
+type fraudFlaggingService struct {
+	transactionRepository repository.TransactionRepository
+	alertRepository       repository.AlertRepository
+	lookbackWindow        time.Duration
+	autoRefund            bool
+}
+
+func NewFraudFlaggingService(transactionRepository repository.TransactionRepository, alertRepository repository.AlertRepository) FraudFlaggingService {
+	lookbackHours, err := strconv.Atoi(os.Getenv("FRAUD_LOOKBACK_HOURS"))
+	if err != nil || lookbackHours <= 0 {
+		lookbackHours = defaultLookbackHours
+	}
+
+	autoRefund, _ := strconv.ParseBool(os.Getenv("AUTO_REFUND_SUSPECTED_FRAUD"))
+
+	return &fraudFlaggingService{
+		transactionRepository: transactionRepository,
+		alertRepository:       alertRepository,
+		lookbackWindow:        time.Duration(lookbackHours) * time.Hour,
+		autoRefund:            autoRefund,
+	}
+}
+
+// FlagReportedCards marks as suspected fraud the approved payments made with the reported cards
+// within the look-back window before the report, and raises an alert for each one of them. compliance-service delivers
+// each report at least once: a report received again raises no new alert.
+func (s *fraudFlaggingService) FlagReportedCards(userID int64, cardIDs []int64, reportedAt time.Time) ([]string, error) {
+	transactions, err := s.transactionRepository.GetApprovedTransactions(userID, cardIDs, reportedAt.Add(-s.lookbackWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transactions) == 0 {
+		return []string{}, nil
+	}
+
+	status := repository.TransactionStatusApproved
+	var refundedAt *time.Time
+	if s.autoRefund {
+		status = repository.TransactionStatusRefundPending
+		refundedAt = &reportedAt
+	}
+
+	transactionIDs := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionIDs = append(transactionIDs, transaction.ID)
+	}
+
+	if err := s.transactionRepository.FlagSuspectedFraud(transactionIDs, status, refundedAt); err != nil {
+		return nil, err
+	}
+
+	alerts := make([]repository.Alert, 0, len(transactions))
+	for _, transaction := range transactions {
+		message := fmt.Sprintf("payment of %.2f made %s before card %d was reported", transaction.Amount, reportedAt.Sub(transaction.CreatedAt).Round(time.Minute), transaction.CardID)
+		if s.autoRefund {
+			message += ", refund initiated"
+		}
+
+		slog.Warn("suspected fraud", logging.TransactionID(transaction.ID), logging.UserID(transaction.UserID),
+			logging.CardID(transaction.CardID), slog.String("reason", message))
+
+		alerts = append(alerts, repository.Alert{
+			AlertType:     repository.AlertTypeSuspectedFraud,
+			UserID:        transaction.UserID,
+			CardID:        transaction.CardID,
+			TransactionID: transaction.ID,
+			Message:       message,
+			DedupKey:      "card_reported:" + transaction.ID,
+		})
+	}
+
+	// the payments are flagged again, with no effect, when the report is retried after an error raising the alerts
+	if _, err := s.alertRepository.CreateAlerts(alerts); err != nil {
+		return nil, fmt.Errorf("error raising alerts: %w", err)
+	}
+
+	return transactionIDs, nil
+}
+
+func (s *fraudFlaggingService) ListAlerts(alertType string) ([]repository.Alert, error) {
+	return s.alertRepository.ListAlerts(alertType)
+}
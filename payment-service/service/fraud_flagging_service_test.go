@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/repository/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagReportedCards(t *testing.T) {
+	reportedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type input struct {
+		userID     int64
+		cardIDs    []int64
+		reportedAt time.Time
+		autoRefund bool
+	}
+
+	type output struct {
+		transactionIDs []string
+		err            error
+	}
+
+	type depFields struct {
+		transactionRepositoryMock *mock.MockTransactionRepository
+		alertRepositoryMock       *mock.MockAlertRepository
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(*depFields, input)
+		assertFunc func(t *testing.T, out output)
+	}{
+		{
+			name: "Success - Payments within the look-back window flagged",
+			input: input{
+				userID:     1,
+				cardIDs:    []int64{1, 2},
+				reportedAt: reportedAt,
+			},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetApprovedTransactions(in.userID, in.cardIDs, reportedAt.Add(-24*time.Hour)).Return([]repository.Transaction{
+					{ID: "txn_1", UserID: 1, CardID: 1, Amount: 50, CreatedAt: reportedAt.Add(-2 * time.Hour)},
+					{ID: "txn_2", UserID: 1, CardID: 2, Amount: 75, CreatedAt: reportedAt.Add(-30 * time.Minute)},
+				}, nil)
+				dep.transactionRepositoryMock.EXPECT().FlagSuspectedFraud([]string{"txn_1", "txn_2"}, repository.TransactionStatusApproved, nil).Return(nil)
+				dep.alertRepositoryMock.EXPECT().CreateAlerts([]repository.Alert{
+					{
+						AlertType:     repository.AlertTypeSuspectedFraud,
+						UserID:        1,
+						CardID:        1,
+						TransactionID: "txn_1",
+						Message:       "payment of 50.00 made 2h0m0s before card 1 was reported",
+						DedupKey:      "card_reported:txn_1",
+					},
+					{
+						AlertType:     repository.AlertTypeSuspectedFraud,
+						UserID:        1,
+						CardID:        2,
+						TransactionID: "txn_2",
+						Message:       "payment of 75.00 made 30m0s before card 2 was reported",
+						DedupKey:      "card_reported:txn_2",
+					},
+				}).Return(2, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, []string{"txn_1", "txn_2"}, out.transactionIDs)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Success - Refunds initiated when enabled",
+			input: input{
+				userID:     1,
+				cardIDs:    []int64{1},
+				reportedAt: reportedAt,
+				autoRefund: true,
+			},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetApprovedTransactions(in.userID, in.cardIDs, gomock.Any()).Return([]repository.Transaction{
+					{ID: "txn_1", UserID: 1, CardID: 1, Amount: 50, CreatedAt: reportedAt.Add(-time.Hour)},
+				}, nil)
+				dep.transactionRepositoryMock.EXPECT().FlagSuspectedFraud([]string{"txn_1"}, repository.TransactionStatusRefundPending, &reportedAt).Return(nil)
+				dep.alertRepositoryMock.EXPECT().CreateAlerts(gomock.Any()).DoAndReturn(func(alerts []repository.Alert) (int, error) {
+					assert.Contains(t, alerts[0].Message, "refund initiated")
+					return 1, nil
+				})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, []string{"txn_1"}, out.transactionIDs)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Success - No payments to flag",
+			input: input{
+				userID:     1,
+				cardIDs:    []int64{1},
+				reportedAt: reportedAt,
+			},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetApprovedTransactions(in.userID, in.cardIDs, gomock.Any()).Return(nil, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.transactionIDs)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Success - Report received again raises no new alert",
+			input: input{
+				userID:     1,
+				cardIDs:    []int64{1},
+				reportedAt: reportedAt,
+			},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetApprovedTransactions(in.userID, in.cardIDs, gomock.Any()).Return([]repository.Transaction{{ID: "txn_1"}}, nil)
+				dep.transactionRepositoryMock.EXPECT().FlagSuspectedFraud([]string{"txn_1"}, repository.TransactionStatusApproved, nil).Return(nil)
+				dep.alertRepositoryMock.EXPECT().CreateAlerts(gomock.Any()).Return(0, nil)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Equal(t, []string{"txn_1"}, out.transactionIDs)
+				assert.NoError(t, out.err)
+			},
+		},
+		{
+			name: "Failure - Error raising alerts",
+			input: input{
+				userID:     1,
+				cardIDs:    []int64{1},
+				reportedAt: reportedAt,
+			},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetApprovedTransactions(in.userID, in.cardIDs, gomock.Any()).Return([]repository.Transaction{{ID: "txn_1"}}, nil)
+				dep.transactionRepositoryMock.EXPECT().FlagSuspectedFraud([]string{"txn_1"}, repository.TransactionStatusApproved, nil).Return(nil)
+				dep.alertRepositoryMock.EXPECT().CreateAlerts(gomock.Any()).Return(0, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.transactionIDs)
+				assert.EqualError(t, out.err, "error raising alerts: database error")
+			},
+		},
+		{
+			name: "Failure - Error flagging transactions",
+			input: input{
+				userID:     1,
+				cardIDs:    []int64{1},
+				reportedAt: reportedAt,
+			},
+			on: func(dep *depFields, in input) {
+				dep.transactionRepositoryMock.EXPECT().GetApprovedTransactions(in.userID, in.cardIDs, gomock.Any()).Return([]repository.Transaction{{ID: "txn_1"}}, nil)
+				dep.transactionRepositoryMock.EXPECT().FlagSuspectedFraud([]string{"txn_1"}, repository.TransactionStatusApproved, nil).Return(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.transactionIDs)
+				assert.EqualError(t, out.err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+			alertRepositoryMock := mock.NewMockAlertRepository(ctrl)
+			tt.on(&depFields{
+				transactionRepositoryMock: transactionRepositoryMock,
+				alertRepositoryMock:       alertRepositoryMock,
+			}, tt.input)
+
+			service := &fraudFlaggingService{
+				transactionRepository: transactionRepositoryMock,
+				alertRepository:       alertRepositoryMock,
+				lookbackWindow:        24 * time.Hour,
+				autoRefund:            tt.input.autoRefund,
+			}
+
+			transactionIDs, err := service.FlagReportedCards(tt.input.userID, tt.input.cardIDs, tt.input.reportedAt)
+			tt.assertFunc(t, output{transactionIDs, err})
+		})
+	}
+}
+
+func TestNewFraudFlaggingService(t *testing.T) {
+	t.Setenv("FRAUD_LOOKBACK_HOURS", "6")
+	t.Setenv("AUTO_REFUND_SUSPECTED_FRAUD", "true")
+
+	service := NewFraudFlaggingService(nil, nil).(*fraudFlaggingService)
+
+	assert.Equal(t, 6*time.Hour, service.lookbackWindow)
+	assert.True(t, service.autoRefund)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"flarrocca/payment-service/repository"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	RuleIPCountryMismatch = "ip_country_mismatch"
+	// defaultFraudDeclineRules are the rules whose signals decline the payment by default.
+	defaultFraudDeclineRules = RuleImpossibleTravel
+)
+
+// FraudSignal is raised by a rule for a suspicious payment. The payment is declined when Decline is set, held for
+// manual review otherwise.
+type FraudSignal struct {
+	Rule    string `json:"rule"`
+	Reason  string `json:"reason"`
+	Decline bool   `json:"decline,omitempty"`
+}
+
+// FraudRule inspects a payment, including its context, and reports whether it looks suspicious.
+type FraudRule interface {
+	Name() string
+	Evaluate(transaction repository.Transaction) (FraudSignal, bool)
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source fraud_rule_service.go -destination mock/fraud_rule_service_mock.go -package mock
+type FraudRuleService interface {
+	Evaluate(transaction repository.Transaction) []FraudSignal
+}
+
+type fraudRuleService struct {
+	rules        []FraudRule
+	declineRules map[string]bool
+}
+
+// NewFraudRuleService reads the rules whose signals decline the payment from FRAUD_DECLINE_RULES, a comma-separated
+// list (default impossible_travel). The signals of the other rules hold the payment for manual review.
+func NewFraudRuleService(rules ...FraudRule) FraudRuleService {
+	declineRules := os.Getenv("FRAUD_DECLINE_RULES")
+	if declineRules == "" {
+		declineRules = defaultFraudDeclineRules
+	}
+
+	service := &fraudRuleService{rules: rules, declineRules: map[string]bool{}}
+	for _, rule := range strings.Split(declineRules, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			service.declineRules[rule] = true
+		}
+	}
+	return service
+}
+
+// Evaluate runs every rule against the payment and returns the signals raised, in rule order.
+func (s *fraudRuleService) Evaluate(transaction repository.Transaction) []FraudSignal {
+	var signals []FraudSignal
+	for _, rule := range s.rules {
+		if signal, flagged := rule.Evaluate(transaction); flagged {
+			signal.Decline = s.declineRules[signal.Rule]
+			fraudRuleHitsTotal.WithLabelValues(signal.Rule).Inc()
+			signals = append(signals, signal)
+		}
+	}
+	return signals
+}
+
+// ipCountryMismatchRule flags payments whose client IP resolves to a country other than the card's
+// billing country, which is the best proxy for the card country available to payment-service.
+type ipCountryMismatchRule struct{}
+
+func NewIPCountryMismatchRule() FraudRule {
+	return ipCountryMismatchRule{}
+}
+
+func (ipCountryMismatchRule) Name() string {
+	return RuleIPCountryMismatch
+}
+
+func (r ipCountryMismatchRule) Evaluate(transaction repository.Transaction) (FraudSignal, bool) {
+	paymentContext := transaction.Context
+	if paymentContext == nil || paymentContext.IPCountry == "" || paymentContext.BillingAddress.Country == "" {
+		return FraudSignal{}, false
+	}
+
+	if paymentContext.IPCountry == paymentContext.BillingAddress.Country {
+		return FraudSignal{}, false
+	}
+
+	return FraudSignal{
+		Rule:   r.Name(),
+		Reason: fmt.Sprintf("IP country %s differs from billing country %s", paymentContext.IPCountry, paymentContext.BillingAddress.Country),
+	}, true
+}
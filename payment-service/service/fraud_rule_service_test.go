@@ -0,0 +1,85 @@
+package service
+
+import (
+	"flarrocca/payment-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staticRule struct {
+	name    string
+	flagged bool
+}
+
+func (r staticRule) Name() string {
+	return r.name
+}
+
+func (r staticRule) Evaluate(transaction repository.Transaction) (FraudSignal, bool) {
+	return FraudSignal{Rule: r.name, Reason: "static"}, r.flagged
+}
+
+func TestFraudRuleServiceEvaluate(t *testing.T) {
+	fraudRuleService := NewFraudRuleService(
+		staticRule{name: "first", flagged: true},
+		staticRule{name: "second", flagged: false},
+		staticRule{name: "third", flagged: true},
+	)
+
+	signals := fraudRuleService.Evaluate(repository.Transaction{ID: "txn_1"})
+
+	assert.Equal(t, []FraudSignal{{Rule: "first", Reason: "static"}, {Rule: "third", Reason: "static"}}, signals)
+	assert.Empty(t, NewFraudRuleService().Evaluate(repository.Transaction{ID: "txn_1"}))
+}
+
+func TestFraudRuleServiceDeclineRules(t *testing.T) {
+	signals := NewFraudRuleService(staticRule{name: RuleImpossibleTravel, flagged: true}, staticRule{name: RuleFirstSeenCountry, flagged: true}).
+		Evaluate(repository.Transaction{ID: "txn_1"})
+	assert.Equal(t, []FraudSignal{{Rule: RuleImpossibleTravel, Reason: "static", Decline: true}, {Rule: RuleFirstSeenCountry, Reason: "static"}}, signals)
+
+	t.Setenv("FRAUD_DECLINE_RULES", " first_seen_country , ip_country_mismatch")
+	signals = NewFraudRuleService(staticRule{name: RuleImpossibleTravel, flagged: true}, staticRule{name: RuleFirstSeenCountry, flagged: true}).
+		Evaluate(repository.Transaction{ID: "txn_1"})
+	assert.Equal(t, []FraudSignal{{Rule: RuleImpossibleTravel, Reason: "static"}, {Rule: RuleFirstSeenCountry, Reason: "static", Decline: true}}, signals)
+}
+
+func TestIPCountryMismatchRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		paymentContext *repository.PaymentContext
+		signal         FraudSignal
+		flagged        bool
+	}{
+		{
+			name:           "Flagged - IP country differs from billing country",
+			paymentContext: &repository.PaymentContext{IPCountry: "AU", BillingAddress: repository.Address{Country: "GB"}},
+			signal:         FraudSignal{Rule: RuleIPCountryMismatch, Reason: "IP country AU differs from billing country GB"},
+			flagged:        true,
+		},
+		{
+			name:           "Not flagged - Same country",
+			paymentContext: &repository.PaymentContext{IPCountry: "GB", BillingAddress: repository.Address{Country: "GB"}},
+		},
+		{
+			name:           "Not flagged - IP not resolved",
+			paymentContext: &repository.PaymentContext{IPAddress: "10.0.0.1", BillingAddress: repository.Address{Country: "GB"}},
+		},
+		{
+			name:           "Not flagged - Billing country missing",
+			paymentContext: &repository.PaymentContext{IPCountry: "AU"},
+		},
+		{
+			name: "Not flagged - No context",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal, flagged := NewIPCountryMismatchRule().Evaluate(repository.Transaction{ID: "txn_1", Context: tt.paymentContext})
+
+			assert.Equal(t, tt.flagged, flagged)
+			assert.Equal(t, tt.signal, signal)
+		})
+	}
+}
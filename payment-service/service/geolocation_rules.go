@@ -0,0 +1,159 @@
+package service
+
+import (
+	"flarrocca/logging"
+	"flarrocca/payment-service/repository"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	RuleImpossibleTravel  = "impossible_travel"
+	RuleFirstSeenCountry  = "first_seen_country"
+	earthRadiusKm         = 6371.0
+	defaultMaxTravelSpeed = 900.0
+	defaultMinTravelKm    = 100.0
+	defaultGeoHistorySize = 50
+)
+
+// impossibleTravelRule compares the payment location with the previous located payments of the same user or card,
+// not only the last one, and flags the payment when the speed needed to cover the distance from any of them is not
+// physically plausible.
+type impossibleTravelRule struct {
+	transactionRepository repository.TransactionRepository
+	maxSpeedKmh           float64
+	minDistanceKm         float64
+}
+
+// NewImpossibleTravelRule reads its thresholds from IMPOSSIBLE_TRAVEL_MAX_SPEED_KMH (default 900, roughly a
+// commercial flight) and IMPOSSIBLE_TRAVEL_MIN_DISTANCE_KM (default 100, to absorb geolocation inaccuracy).
+func NewImpossibleTravelRule(transactionRepository repository.TransactionRepository) FraudRule {
+	return &impossibleTravelRule{
+		transactionRepository: transactionRepository,
+		maxSpeedKmh:           floatFromEnv("IMPOSSIBLE_TRAVEL_MAX_SPEED_KMH", defaultMaxTravelSpeed),
+		minDistanceKm:         floatFromEnv("IMPOSSIBLE_TRAVEL_MIN_DISTANCE_KM", defaultMinTravelKm),
+	}
+}
+
+func (r *impossibleTravelRule) Name() string {
+	return RuleImpossibleTravel
+}
+
+func (r *impossibleTravelRule) Evaluate(transaction repository.Transaction) (FraudSignal, bool) {
+	if transaction.Context == nil || transaction.Context.IPLocation == nil {
+		return FraudSignal{}, false
+	}
+
+	history, err := r.transactionRepository.GetLocatedPayments(transaction.UserID, transaction.CardID, defaultGeoHistorySize)
+	if err != nil {
+		slog.Error("error loading payment history", slog.String("rule", r.Name()), logging.TransactionID(transaction.ID), logging.Err(err))
+		return FraudSignal{}, false
+	}
+
+	// no distance on Earth needs more time than this at the maximum speed, older payments cannot be flagged
+	maxElapsed := time.Duration(math.Pi * earthRadiusKm / r.maxSpeedKmh * float64(time.Hour))
+	for _, previous := range history {
+		elapsed := transaction.CreatedAt.Sub(previous.CreatedAt)
+		if elapsed > maxElapsed {
+			break
+		}
+		if previous.Context.IPLocation == nil {
+			continue
+		}
+
+		distance := haversineKm(*previous.Context.IPLocation, *transaction.Context.IPLocation)
+		if distance < r.minDistanceKm {
+			continue
+		}
+
+		speed := math.Inf(1)
+		if elapsed > 0 {
+			speed = distance / elapsed.Hours()
+		}
+		if speed <= r.maxSpeedKmh {
+			continue
+		}
+
+		return FraudSignal{
+			Rule: r.Name(),
+			Reason: fmt.Sprintf("%.0f km from %s (transaction %s) in %s, above %.0f km/h",
+				distance, previous.Context.IPCountry, previous.ID, elapsed.Round(time.Second), r.maxSpeedKmh),
+		}, true
+	}
+
+	return FraudSignal{}, false
+}
+
+// firstSeenCountryRule flags payments from a country never seen in the recent history of the user or card.
+// Users without any located payment are not flagged, as every country would be new to them.
+type firstSeenCountryRule struct {
+	transactionRepository repository.TransactionRepository
+	historySize           int
+}
+
+// NewFirstSeenCountryRule reads the number of past payments to consider from GEO_HISTORY_SIZE (default 50).
+func NewFirstSeenCountryRule(transactionRepository repository.TransactionRepository) FraudRule {
+	historySize, err := strconv.Atoi(os.Getenv("GEO_HISTORY_SIZE"))
+	if err != nil || historySize <= 0 {
+		historySize = defaultGeoHistorySize
+	}
+
+	return &firstSeenCountryRule{
+		transactionRepository: transactionRepository,
+		historySize:           historySize,
+	}
+}
+
+func (r *firstSeenCountryRule) Name() string {
+	return RuleFirstSeenCountry
+}
+
+func (r *firstSeenCountryRule) Evaluate(transaction repository.Transaction) (FraudSignal, bool) {
+	if transaction.Context == nil || transaction.Context.IPCountry == "" {
+		return FraudSignal{}, false
+	}
+
+	history, err := r.transactionRepository.GetLocatedPayments(transaction.UserID, transaction.CardID, r.historySize)
+	if err != nil {
+		slog.Error("error loading payment history", slog.String("rule", r.Name()), logging.TransactionID(transaction.ID), logging.Err(err))
+		return FraudSignal{}, false
+	}
+
+	if len(history) == 0 {
+		return FraudSignal{}, false
+	}
+
+	for _, previous := range history {
+		if previous.Context.IPCountry == transaction.Context.IPCountry {
+			return FraudSignal{}, false
+		}
+	}
+
+	return FraudSignal{
+		Rule:   r.Name(),
+		Reason: fmt.Sprintf("first payment from %s in the last %d located payments", transaction.Context.IPCountry, len(history)),
+	}, true
+}
+
+// haversineKm returns the great-circle distance between two points.
+func haversineKm(from repository.GeoPoint, to repository.GeoPoint) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	deltaLat := (to.Latitude - from.Latitude) * math.Pi / 180
+	deltaLon := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+func floatFromEnv(name string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
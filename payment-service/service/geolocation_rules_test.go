@@ -0,0 +1,273 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/repository/mock"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	london = &repository.GeoPoint{Latitude: 51.5074, Longitude: -0.1278}
+	paris  = &repository.GeoPoint{Latitude: 48.8566, Longitude: 2.3522}
+	sydney = &repository.GeoPoint{Latitude: -33.8688, Longitude: 151.2093}
+)
+
+func locatedPayment(id string, country string, location *repository.GeoPoint, createdAt time.Time) repository.Transaction {
+	return repository.Transaction{
+		ID:        id,
+		UserID:    1,
+		CardID:    2,
+		CreatedAt: createdAt,
+		Context:   &repository.PaymentContext{IPCountry: country, IPLocation: location},
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	assert.InDelta(t, 344, haversineKm(*london, *paris), 1)
+	assert.InDelta(t, 16994, haversineKm(*london, *sydney), 5)
+	assert.Zero(t, haversineKm(*london, *london))
+}
+
+func TestImpossibleTravelRule(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		transaction repository.Transaction
+		on          func(transactionRepositoryMock *mock.MockTransactionRepository)
+		signal      FraudSignal
+		flagged     bool
+	}{
+		{
+			name:        "Flagged - London then Sydney 10 minutes later",
+			transaction: locatedPayment("txn_2", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+					Return([]repository.Transaction{locatedPayment("txn_1", "GB", london, now.Add(-10*time.Minute))}, nil)
+			},
+			signal:  FraudSignal{Rule: RuleImpossibleTravel, Reason: "16994 km from GB (transaction txn_1) in 10m0s, above 900 km/h"},
+			flagged: true,
+		},
+		{
+			name:        "Flagged - Previous payment without location is skipped",
+			transaction: locatedPayment("txn_3", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+					Return([]repository.Transaction{
+						locatedPayment("txn_2", "AU", nil, now.Add(-5*time.Minute)),
+						locatedPayment("txn_1", "GB", london, now.Add(-time.Hour)),
+					}, nil)
+			},
+			signal:  FraudSignal{Rule: RuleImpossibleTravel, Reason: "16994 km from GB (transaction txn_1) in 1h0m0s, above 900 km/h"},
+			flagged: true,
+		},
+		{
+			name:        "Flagged - An older payment is too far away, not only the last one",
+			transaction: locatedPayment("txn_3", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+					Return([]repository.Transaction{
+						locatedPayment("txn_2", "AU", sydney, now.Add(-5*time.Minute)),
+						locatedPayment("txn_1", "GB", london, now.Add(-20*time.Minute)),
+					}, nil)
+			},
+			signal:  FraudSignal{Rule: RuleImpossibleTravel, Reason: "16994 km from GB (transaction txn_1) in 20m0s, above 900 km/h"},
+			flagged: true,
+		},
+		{
+			name:        "Not flagged - London then Sydney a day later",
+			transaction: locatedPayment("txn_2", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+					Return([]repository.Transaction{locatedPayment("txn_1", "GB", london, now.Add(-24*time.Hour))}, nil)
+			},
+		},
+		{
+			name:        "Not flagged - Below the minimum distance",
+			transaction: locatedPayment("txn_2", "GB", &repository.GeoPoint{Latitude: 51.75, Longitude: -1.25}, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+					Return([]repository.Transaction{locatedPayment("txn_1", "GB", london, now.Add(-time.Minute))}, nil)
+			},
+		},
+		{
+			name:        "Not flagged - No previous payment",
+			transaction: locatedPayment("txn_1", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).Return(nil, nil)
+			},
+		},
+		{
+			name:        "Not flagged - Payment without location",
+			transaction: locatedPayment("txn_1", "AU", nil, now),
+			on:          func(transactionRepositoryMock *mock.MockTransactionRepository) {},
+		},
+		{
+			name:        "Not flagged - History cannot be loaded",
+			transaction: locatedPayment("txn_1", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).Return(nil, errors.New("database error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+			tt.on(transactionRepositoryMock)
+
+			signal, flagged := NewImpossibleTravelRule(transactionRepositoryMock).Evaluate(tt.transaction)
+
+			assert.Equal(t, tt.flagged, flagged)
+			assert.Equal(t, tt.signal, signal)
+		})
+	}
+}
+
+func TestImpossibleTravelRuleThresholds(t *testing.T) {
+	t.Setenv("IMPOSSIBLE_TRAVEL_MAX_SPEED_KMH", "300")
+	t.Setenv("IMPOSSIBLE_TRAVEL_MIN_DISTANCE_KM", "50")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+	transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+		Return([]repository.Transaction{locatedPayment("txn_1", "GB", london, now.Add(-30*time.Minute))}, nil)
+
+	signal, flagged := NewImpossibleTravelRule(transactionRepositoryMock).Evaluate(locatedPayment("txn_2", "FR", paris, now))
+
+	assert.True(t, flagged)
+	assert.Equal(t, "344 km from GB (transaction txn_1) in 30m0s, above 300 km/h", signal.Reason)
+}
+
+func TestFirstSeenCountryRule(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		transaction repository.Transaction
+		on          func(transactionRepositoryMock *mock.MockTransactionRepository)
+		signal      FraudSignal
+		flagged     bool
+	}{
+		{
+			name:        "Flagged - Country never seen before",
+			transaction: locatedPayment("txn_3", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+					Return([]repository.Transaction{
+						locatedPayment("txn_2", "GB", london, now.Add(-24*time.Hour)),
+						locatedPayment("txn_1", "FR", paris, now.Add(-48*time.Hour)),
+					}, nil)
+			},
+			signal:  FraudSignal{Rule: RuleFirstSeenCountry, Reason: "first payment from AU in the last 2 located payments"},
+			flagged: true,
+		},
+		{
+			name:        "Not flagged - Country seen before",
+			transaction: locatedPayment("txn_3", "FR", paris, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).
+					Return([]repository.Transaction{
+						locatedPayment("txn_2", "GB", london, now.Add(-24*time.Hour)),
+						locatedPayment("txn_1", "FR", paris, now.Add(-48*time.Hour)),
+					}, nil)
+			},
+		},
+		{
+			name:        "Not flagged - No history",
+			transaction: locatedPayment("txn_1", "AU", sydney, now),
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetLocatedPayments(int64(1), int64(2), defaultGeoHistorySize).Return(nil, nil)
+			},
+		},
+		{
+			name:        "Not flagged - IP not resolved",
+			transaction: locatedPayment("txn_1", "", nil, now),
+			on:          func(transactionRepositoryMock *mock.MockTransactionRepository) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+			tt.on(transactionRepositoryMock)
+
+			signal, flagged := NewFirstSeenCountryRule(transactionRepositoryMock).Evaluate(tt.transaction)
+
+			assert.Equal(t, tt.flagged, flagged)
+			assert.Equal(t, tt.signal, signal)
+		})
+	}
+}
+
+func TestGeolocationRulesHistory(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		previous       repository.Transaction
+		travelFlagged  bool
+		countryFlagged bool
+	}{
+		{
+			name:           "Flagged - Approved payment from London 10 minutes earlier",
+			previous:       repository.Transaction{Status: repository.TransactionStatusApproved},
+			travelFlagged:  true,
+			countryFlagged: true,
+		},
+		{
+			name:     "Not flagged - Payment from London held for review",
+			previous: repository.Transaction{Status: repository.TransactionStatusPendingReview},
+		},
+		{
+			name:     "Not flagged - Payment from London flagged as suspected fraud",
+			previous: repository.Transaction{Status: repository.TransactionStatusApproved, SuspectedFraud: true},
+		},
+		{
+			name:     "Not flagged - Payment from London declined",
+			previous: repository.Transaction{Status: repository.TransactionStatusDeclined},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "payment.db"))
+			assert.NoError(t, err)
+			defer db.Close()
+			initSQL, err := os.ReadFile(filepath.Join("..", "database", "init.sql"))
+			assert.NoError(t, err)
+			_, err = db.Exec(string(initSQL))
+			assert.NoError(t, err)
+
+			transactionRepository := repository.NewTransactionRepository(db)
+			previous := locatedPayment("txn_1", "GB", london, now.Add(-10*time.Minute))
+			previous.Status, previous.SuspectedFraud = tt.previous.Status, tt.previous.SuspectedFraud
+			assert.NoError(t, transactionRepository.SaveTransaction(previous))
+
+			transaction := locatedPayment("txn_2", "AU", sydney, now)
+			_, travelFlagged := NewImpossibleTravelRule(transactionRepository).Evaluate(transaction)
+			_, countryFlagged := NewFirstSeenCountryRule(transactionRepository).Evaluate(transaction)
+
+			assert.Equal(t, tt.travelFlagged, travelFlagged)
+			assert.Equal(t, tt.countryFlagged, countryFlagged)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Reasons of the declined payments counted by payments_total, bounded unlike the messages of compliance-service.
+const (
+	declineReasonComplianceDenied      = "compliance_denied"
+	declineReasonComplianceError       = "compliance_error"
+	declineReasonComplianceUnavailable = "compliance_unavailable"
+	declineReasonCardBlocked           = "card_blocked"
+	declineReasonSuspectedFraud        = "suspected_fraud"
+	approvedStandIn                    = "stand_in"
+)
+
+var (
+	paymentsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payments_total",
+		Help: "Payments processed, by decision (approved, declined or pending_review) and reason: the decline reason, stand_in for the payments approved in stand-in mode, empty otherwise.",
+	}, []string{"decision", "reason"})
+
+	paymentReviewsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_reviews_total",
+		Help: "Payments held for manual review a reviewer decided on, by decision.",
+	}, []string{"decision"})
+
+	fraudRuleHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fraud_rule_hits_total",
+		Help: "Payments flagged by the fraud rules, by rule.",
+	}, []string{"rule"})
+)
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: aml_monitoring_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	service "flarrocca/payment-service/service"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAMLMonitoringService is a mock of AMLMonitoringService interface.
+type MockAMLMonitoringService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAMLMonitoringServiceMockRecorder
+}
+
+// MockAMLMonitoringServiceMockRecorder is the mock recorder for MockAMLMonitoringService.
+type MockAMLMonitoringServiceMockRecorder struct {
+	mock *MockAMLMonitoringService
+}
+
+// NewMockAMLMonitoringService creates a new mock instance.
+func NewMockAMLMonitoringService(ctrl *gomock.Controller) *MockAMLMonitoringService {
+	mock := &MockAMLMonitoringService{ctrl: ctrl}
+	mock.recorder = &MockAMLMonitoringServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAMLMonitoringService) EXPECT() *MockAMLMonitoringServiceMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockAMLMonitoringService) Run(from, to time.Time, scenarioNames []string) (service.AMLRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", from, to, scenarioNames)
+	ret0, _ := ret[0].(service.AMLRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockAMLMonitoringServiceMockRecorder) Run(from, to, scenarioNames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockAMLMonitoringService)(nil).Run), from, to, scenarioNames)
+}
@@ -0,0 +1,61 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: compliance_cache_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockComplianceCacheService is a mock of ComplianceCacheService interface.
+type MockComplianceCacheService struct {
+	ctrl     *gomock.Controller
+	recorder *MockComplianceCacheServiceMockRecorder
+}
+
+// MockComplianceCacheServiceMockRecorder is the mock recorder for MockComplianceCacheService.
+type MockComplianceCacheServiceMockRecorder struct {
+	mock *MockComplianceCacheService
+}
+
+// NewMockComplianceCacheService creates a new mock instance.
+func NewMockComplianceCacheService(ctrl *gomock.Controller) *MockComplianceCacheService {
+	mock := &MockComplianceCacheService{ctrl: ctrl}
+	mock.recorder = &MockComplianceCacheServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComplianceCacheService) EXPECT() *MockComplianceCacheServiceMockRecorder {
+	return m.recorder
+}
+
+// Invalidate mocks base method.
+func (m *MockComplianceCacheService) Invalidate(cards []repository.BlockedCard, userIDs []int64, all bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Invalidate", cards, userIDs, all)
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockComplianceCacheServiceMockRecorder) Invalidate(cards, userIDs, all interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockComplianceCacheService)(nil).Invalidate), cards, userIDs, all)
+}
+
+// Stats mocks base method.
+func (m *MockComplianceCacheService) Stats() repository.ComplianceCacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(repository.ComplianceCacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockComplianceCacheServiceMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockComplianceCacheService)(nil).Stats))
+}
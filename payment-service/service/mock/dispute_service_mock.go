@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dispute_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	repository "flarrocca/payment-service/repository"
+	service "flarrocca/payment-service/service"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDisputeService is a mock of DisputeService interface.
+type MockDisputeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDisputeServiceMockRecorder
+}
+
+// MockDisputeServiceMockRecorder is the mock recorder for MockDisputeService.
+type MockDisputeServiceMockRecorder struct {
+	mock *MockDisputeService
+}
+
+// NewMockDisputeService creates a new mock instance.
+func NewMockDisputeService(ctrl *gomock.Controller) *MockDisputeService {
+	mock := &MockDisputeService{ctrl: ctrl}
+	mock.recorder = &MockDisputeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDisputeService) EXPECT() *MockDisputeServiceMockRecorder {
+	return m.recorder
+}
+
+// AddEvidence mocks base method.
+func (m *MockDisputeService) AddEvidence(disputeID int64, fileName string, content io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvidence", disputeID, fileName, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvidence indicates an expected call of AddEvidence.
+func (mr *MockDisputeServiceMockRecorder) AddEvidence(disputeID, fileName, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvidence", reflect.TypeOf((*MockDisputeService)(nil).AddEvidence), disputeID, fileName, content)
+}
+
+// AdvanceDispute mocks base method.
+func (m *MockDisputeService) AdvanceDispute(disputeID int64, stage string) (repository.Dispute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceDispute", disputeID, stage)
+	ret0, _ := ret[0].(repository.Dispute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceDispute indicates an expected call of AdvanceDispute.
+func (mr *MockDisputeServiceMockRecorder) AdvanceDispute(disputeID, stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceDispute", reflect.TypeOf((*MockDisputeService)(nil).AdvanceDispute), disputeID, stage)
+}
+
+// GetDispute mocks base method.
+func (m *MockDisputeService) GetDispute(disputeID int64) (service.DisputeDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDispute", disputeID)
+	ret0, _ := ret[0].(service.DisputeDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDispute indicates an expected call of GetDispute.
+func (mr *MockDisputeServiceMockRecorder) GetDispute(disputeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDispute", reflect.TypeOf((*MockDisputeService)(nil).GetDispute), disputeID)
+}
+
+// OpenDispute mocks base method.
+func (m *MockDisputeService) OpenDispute(ctx context.Context, transactionID, reasonCode string) (repository.Dispute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDispute", ctx, transactionID, reasonCode)
+	ret0, _ := ret[0].(repository.Dispute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDispute indicates an expected call of OpenDispute.
+func (mr *MockDisputeServiceMockRecorder) OpenDispute(ctx, transactionID, reasonCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDispute", reflect.TypeOf((*MockDisputeService)(nil).OpenDispute), ctx, transactionID, reasonCode)
+}
+
+// ResolveDispute mocks base method.
+func (m *MockDisputeService) ResolveDispute(disputeID int64, outcome string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveDispute", disputeID, outcome)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveDispute indicates an expected call of ResolveDispute.
+func (mr *MockDisputeServiceMockRecorder) ResolveDispute(disputeID, outcome interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveDispute", reflect.TypeOf((*MockDisputeService)(nil).ResolveDispute), disputeID, outcome)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: fraud_flagging_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFraudFlaggingService is a mock of FraudFlaggingService interface.
+type MockFraudFlaggingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFraudFlaggingServiceMockRecorder
+}
+
+// MockFraudFlaggingServiceMockRecorder is the mock recorder for MockFraudFlaggingService.
+type MockFraudFlaggingServiceMockRecorder struct {
+	mock *MockFraudFlaggingService
+}
+
+// NewMockFraudFlaggingService creates a new mock instance.
+func NewMockFraudFlaggingService(ctrl *gomock.Controller) *MockFraudFlaggingService {
+	mock := &MockFraudFlaggingService{ctrl: ctrl}
+	mock.recorder = &MockFraudFlaggingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFraudFlaggingService) EXPECT() *MockFraudFlaggingServiceMockRecorder {
+	return m.recorder
+}
+
+// FlagReportedCards mocks base method.
+func (m *MockFraudFlaggingService) FlagReportedCards(userID int64, cardIDs []int64, reportedAt time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagReportedCards", userID, cardIDs, reportedAt)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlagReportedCards indicates an expected call of FlagReportedCards.
+func (mr *MockFraudFlaggingServiceMockRecorder) FlagReportedCards(userID, cardIDs, reportedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagReportedCards", reflect.TypeOf((*MockFraudFlaggingService)(nil).FlagReportedCards), userID, cardIDs, reportedAt)
+}
+
+// ListAlerts mocks base method.
+func (m *MockFraudFlaggingService) ListAlerts(alertType string) ([]repository.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlerts", alertType)
+	ret0, _ := ret[0].([]repository.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlerts indicates an expected call of ListAlerts.
+func (mr *MockFraudFlaggingServiceMockRecorder) ListAlerts(alertType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlerts", reflect.TypeOf((*MockFraudFlaggingService)(nil).ListAlerts), alertType)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: fraud_rule_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	service "flarrocca/payment-service/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFraudRule is a mock of FraudRule interface.
+type MockFraudRule struct {
+	ctrl     *gomock.Controller
+	recorder *MockFraudRuleMockRecorder
+}
+
+// MockFraudRuleMockRecorder is the mock recorder for MockFraudRule.
+type MockFraudRuleMockRecorder struct {
+	mock *MockFraudRule
+}
+
+// NewMockFraudRule creates a new mock instance.
+func NewMockFraudRule(ctrl *gomock.Controller) *MockFraudRule {
+	mock := &MockFraudRule{ctrl: ctrl}
+	mock.recorder = &MockFraudRuleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFraudRule) EXPECT() *MockFraudRuleMockRecorder {
+	return m.recorder
+}
+
+// Evaluate mocks base method.
+func (m *MockFraudRule) Evaluate(transaction repository.Transaction) (service.FraudSignal, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evaluate", transaction)
+	ret0, _ := ret[0].(service.FraudSignal)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Evaluate indicates an expected call of Evaluate.
+func (mr *MockFraudRuleMockRecorder) Evaluate(transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockFraudRule)(nil).Evaluate), transaction)
+}
+
+// Name mocks base method.
+func (m *MockFraudRule) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockFraudRuleMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockFraudRule)(nil).Name))
+}
+
+// MockFraudRuleService is a mock of FraudRuleService interface.
+type MockFraudRuleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFraudRuleServiceMockRecorder
+}
+
+// MockFraudRuleServiceMockRecorder is the mock recorder for MockFraudRuleService.
+type MockFraudRuleServiceMockRecorder struct {
+	mock *MockFraudRuleService
+}
+
+// NewMockFraudRuleService creates a new mock instance.
+func NewMockFraudRuleService(ctrl *gomock.Controller) *MockFraudRuleService {
+	mock := &MockFraudRuleService{ctrl: ctrl}
+	mock.recorder = &MockFraudRuleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFraudRuleService) EXPECT() *MockFraudRuleServiceMockRecorder {
+	return m.recorder
+}
+
+// Evaluate mocks base method.
+func (m *MockFraudRuleService) Evaluate(transaction repository.Transaction) []service.FraudSignal {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evaluate", transaction)
+	ret0, _ := ret[0].([]service.FraudSignal)
+	return ret0
+}
+
+// Evaluate indicates an expected call of Evaluate.
+func (mr *MockFraudRuleServiceMockRecorder) Evaluate(transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockFraudRuleService)(nil).Evaluate), transaction)
+}
@@ -5,7 +5,10 @@
 package mock
 
 import (
+	context "context"
+	repository "flarrocca/payment-service/repository"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -33,17 +36,47 @@ func (m *MockPaymentProcessorService) EXPECT() *MockPaymentProcessorServiceMockR
 	return m.recorder
 }
 
+// ListUserPayments mocks base method.
+func (m *MockPaymentProcessorService) ListUserPayments(userID int64, since time.Time) ([]repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserPayments", userID, since)
+	ret0, _ := ret[0].([]repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserPayments indicates an expected call of ListUserPayments.
+func (mr *MockPaymentProcessorServiceMockRecorder) ListUserPayments(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserPayments", reflect.TypeOf((*MockPaymentProcessorService)(nil).ListUserPayments), userID, since)
+}
+
 // ProcessPayment mocks base method.
-func (m *MockPaymentProcessorService) ProcessPayment(userID, cardID int64, amount float64) (string, error) {
+func (m *MockPaymentProcessorService) ProcessPayment(ctx context.Context, userID, cardID int64, amount float64, paymentContext *repository.PaymentContext) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessPayment", userID, cardID, amount)
+	ret := m.ctrl.Call(m, "ProcessPayment", ctx, userID, cardID, amount, paymentContext)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ProcessPayment indicates an expected call of ProcessPayment.
-func (mr *MockPaymentProcessorServiceMockRecorder) ProcessPayment(userID, cardID, amount interface{}) *gomock.Call {
+func (mr *MockPaymentProcessorServiceMockRecorder) ProcessPayment(ctx, userID, cardID, amount, paymentContext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPayment", reflect.TypeOf((*MockPaymentProcessorService)(nil).ProcessPayment), ctx, userID, cardID, amount, paymentContext)
+}
+
+// ReviewPayment mocks base method.
+func (m *MockPaymentProcessorService) ReviewPayment(ctx context.Context, transactionID, status, reviewer string) (repository.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewPayment", ctx, transactionID, status, reviewer)
+	ret0, _ := ret[0].(repository.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewPayment indicates an expected call of ReviewPayment.
+func (mr *MockPaymentProcessorServiceMockRecorder) ReviewPayment(ctx, transactionID, status, reviewer interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPayment", reflect.TypeOf((*MockPaymentProcessorService)(nil).ProcessPayment), userID, cardID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewPayment", reflect.TypeOf((*MockPaymentProcessorService)(nil).ReviewPayment), ctx, transactionID, status, reviewer)
 }
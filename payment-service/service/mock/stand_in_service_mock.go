@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stand_in_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	repository "flarrocca/payment-service/repository"
+	service "flarrocca/payment-service/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStandInService is a mock of StandInService interface.
+type MockStandInService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStandInServiceMockRecorder
+}
+
+// MockStandInServiceMockRecorder is the mock recorder for MockStandInService.
+type MockStandInServiceMockRecorder struct {
+	mock *MockStandInService
+}
+
+// NewMockStandInService creates a new mock instance.
+func NewMockStandInService(ctrl *gomock.Controller) *MockStandInService {
+	mock := &MockStandInService{ctrl: ctrl}
+	mock.recorder = &MockStandInServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStandInService) EXPECT() *MockStandInServiceMockRecorder {
+	return m.recorder
+}
+
+// Decide mocks base method.
+func (m *MockStandInService) Decide(transaction repository.Transaction) (service.StandInDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Decide", transaction)
+	ret0, _ := ret[0].(service.StandInDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Decide indicates an expected call of Decide.
+func (mr *MockStandInServiceMockRecorder) Decide(transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decide", reflect.TypeOf((*MockStandInService)(nil).Decide), transaction)
+}
+
+// Reconcile mocks base method.
+func (m *MockStandInService) Reconcile() (service.StandInReconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile")
+	ret0, _ := ret[0].(service.StandInReconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockStandInServiceMockRecorder) Reconcile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockStandInService)(nil).Reconcile))
+}
+
+// SyncSnapshot mocks base method.
+func (m *MockStandInService) SyncSnapshot() (repository.StandInSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncSnapshot")
+	ret0, _ := ret[0].(repository.StandInSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncSnapshot indicates an expected call of SyncSnapshot.
+func (mr *MockStandInServiceMockRecorder) SyncSnapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncSnapshot", reflect.TypeOf((*MockStandInService)(nil).SyncSnapshot))
+}
@@ -1,32 +1,342 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flarrocca/logging"
 	"flarrocca/payment-service/repository"
+	"flarrocca/webhook"
 	"fmt"
-	"math/rand"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxReviewerNameLength = 128
+	// MaxUserPayments caps the payments returned by ListUserPayments.
+	MaxUserPayments = 500
+)
+
+// Payment outcomes published to the webhook endpoints.
+const (
+	EventPaymentApproved      = "PaymentApproved"
+	EventPaymentDeclined      = "PaymentDeclined"
+	EventPaymentPendingReview = "PaymentPendingReview"
 )
 
+var PaymentEventTypes = []string{EventPaymentApproved, EventPaymentDeclined, EventPaymentPendingReview}
+
+var (
+	ErrPaymentDenied           = errors.New("payment denied")
+	ErrPaymentPendingReview    = errors.New("payment held for manual review")
+	ErrPaymentNotPendingReview = errors.New("payment is not pending review")
+	ErrInvalidPaymentReview    = errors.New("invalid payment review")
+)
+
+// PaymentEvent is the payload of the payment outcomes. Reviewer is set when a reviewer decided on a held payment.
+type PaymentEvent struct {
+	TransactionID string  `json:"transaction_id"`
+	UserID        int64   `json:"user_id"`
+	CardID        int64   `json:"card_id"`
+	Amount        float64 `json:"amount"`
+	MerchantID    string  `json:"merchant_id,omitempty"`
+	Status        string  `json:"status"`
+	Reason        string  `json:"reason,omitempty"`
+	StandInPolicy string  `json:"stand_in_policy,omitempty"`
+	Reviewer      string  `json:"reviewer,omitempty"`
+}
+
 // Run from the /service folder the following command to generate the mock:
 // mockgen -source payment_processor_service.go -destination mock/payment_processor_service_mock.go -package mock
 type PaymentProcessorService interface {
-	ProcessPayment(userID int64, cardID int64, amount float64) (string, error)
+	ProcessPayment(ctx context.Context, userID int64, cardID int64, amount float64, paymentContext *repository.PaymentContext) (string, error)
+	ReviewPayment(ctx context.Context, transactionID string, status string, reviewer string) (repository.Transaction, error)
+	ListUserPayments(userID int64, since time.Time) ([]repository.Transaction, error)
 }
 
 type paymentProcessorService struct {
-	complianceRepository repository.ComplianceRepository
+	complianceRepository     repository.ComplianceRepository
+	transactionRepository    repository.TransactionRepository
+	alertRepository          repository.AlertRepository
+	ipIntelligenceRepository repository.IPIntelligenceRepository
+	fraudRuleService         FraudRuleService
+	standInService           StandInService
+	webhookService           webhook.Service
+}
+
+func NewPaymentProcessorService(complianceRepository repository.ComplianceRepository, transactionRepository repository.TransactionRepository,
+	alertRepository repository.AlertRepository, ipIntelligenceRepository repository.IPIntelligenceRepository, fraudRuleService FraudRuleService,
+	standInService StandInService, webhookService webhook.Service) PaymentProcessorService {
+	return &paymentProcessorService{
+		complianceRepository:     complianceRepository,
+		transactionRepository:    transactionRepository,
+		alertRepository:          alertRepository,
+		ipIntelligenceRepository: ipIntelligenceRepository,
+		fraudRuleService:         fraudRuleService,
+		standInService:           standInService,
+		webhookService:           webhookService,
+	}
+}
+
+func (p *paymentProcessorService) ProcessPayment(ctx context.Context, userID int64, cardID int64, amount float64, paymentContext *repository.PaymentContext) (string, error) {
+	transaction := repository.Transaction{
+		// the ID is the primary key of the transactions, a random UUID never colliding with the payments of other instances
+		ID:        "txn_" + uuid.NewString(),
+		UserID:    userID,
+		CardID:    cardID,
+		Amount:    amount,
+		Status:    repository.TransactionStatusApproved,
+		CreatedAt: time.Now().UTC(),
+		Context:   paymentContext,
+	}
+
+	if paymentContext != nil && paymentContext.IPAddress != "" {
+		if info, found := p.ipIntelligenceRepository.LookupIP(paymentContext.IPAddress); found {
+			paymentContext.IPCountry = info.Country
+			paymentContext.IPASN = info.ASN
+			paymentContext.IPLocation = info.Location
+		}
+	}
+
+	compliance, err := p.complianceRepository.CheckUserComplianceStatus(ctx, userID, cardID, amount, paymentContext)
+	switch {
+	case errors.Is(err, repository.ErrComplianceUnavailable):
+		if err := p.approveStandIn(ctx, &transaction, err); err != nil {
+			return "", err
+		}
+	case err != nil:
+		p.saveDeclined(ctx, transaction, err.Error(), declineReasonComplianceError)
+		return "", fmt.Errorf("%w: %w", ErrPaymentDenied, err)
+	case compliance.ManualReview:
+		err := p.holdForReview(ctx, transaction, compliance.Message)
+		if errors.Is(err, ErrPaymentPendingReview) {
+			// the payment is held either way, the case gathers what the investigators need to review it
+			if err := p.complianceRepository.RequestCardReview(ctx, repository.CaseSourceHighRiskPayment, transaction); err != nil {
+				slog.ErrorContext(ctx, "error opening high-risk payment case", logging.TransactionID(transaction.ID), logging.Err(err))
+			}
+		}
+		return "", err
+	case !compliance.IsComplaiance:
+		p.saveDeclined(ctx, transaction, compliance.Message, declineReasonComplianceDenied)
+		return "", fmt.Errorf("%w: %s", ErrPaymentDenied, compliance.Message)
+	}
+
+	if signals := p.fraudRuleService.Evaluate(transaction); len(signals) > 0 {
+		return "", p.rejectSuspectedFraud(ctx, transaction, signals)
+	}
+
+	if err := p.transactionRepository.SaveTransaction(transaction); err != nil {
+		slog.ErrorContext(ctx, "error saving transaction", logging.TransactionID(transaction.ID), logging.Err(err))
+		return "", fmt.Errorf("payment denied: error recording transaction")
+	}
+	logDecision(ctx, transaction, "")
+	approvedReason := ""
+	if transaction.StandIn != "" {
+		approvedReason = approvedStandIn
+	}
+	paymentsTotal.WithLabelValues(transaction.Status, approvedReason).Inc()
+	p.publishOutcome(ctx, EventPaymentApproved, transaction, "")
+
+	if transaction.StandIn != "" {
+		return fmt.Sprintf("payment successful, approved in stand-in mode pending compliance reconciliation. Transaction ID: %s", transaction.ID), nil
+	}
+	return fmt.Sprintf("payment successful. Transaction ID: %s", transaction.ID), nil
+}
+
+// rejectSuspectedFraud declines the payment flagged by the fraud rules when a signal requires it, holds it for manual
+// review otherwise, and raises an alert per signal.
+func (p *paymentProcessorService) rejectSuspectedFraud(ctx context.Context, transaction repository.Transaction, signals []FraudSignal) error {
+	transaction.SuspectedFraud = true
+
+	decline := false
+	messages := make([]string, 0, len(signals))
+	for _, signal := range signals {
+		decline = decline || signal.Decline
+		messages = append(messages, fmt.Sprintf("%s: %s", signal.Rule, signal.Reason))
+	}
+	reason := "suspected fraud, " + strings.Join(messages, "; ")
+
+	var err error
+	if decline {
+		// a declined payment is not reconciled with compliance-service
+		transaction.StandIn, transaction.StandInPolicy = "", ""
+		p.saveDeclined(ctx, transaction, reason, declineReasonSuspectedFraud)
+		err = fmt.Errorf("%w: %s", ErrPaymentDenied, reason)
+	} else if err = p.holdForReview(ctx, transaction, reason); !errors.Is(err, ErrPaymentPendingReview) {
+		// the payment was not recorded
+		return err
+	}
+
+	for i, signal := range signals {
+		alert := repository.Alert{
+			AlertType:     repository.AlertTypeFraudRule,
+			UserID:        transaction.UserID,
+			CardID:        transaction.CardID,
+			TransactionID: transaction.ID,
+			Message:       messages[i],
+		}
+		if err := p.alertRepository.CreateAlert(alert); err != nil {
+			slog.ErrorContext(ctx, "error creating alert", logging.TransactionID(transaction.ID), logging.Err(err), slog.String("rule", signal.Rule))
+		}
+	}
+
+	return err
+}
+
+// approveStandIn applies the stand-in policy to a payment compliance-service could not check. A declined payment is
+// denied with the unavailability error, unless the card is blocked in the local snapshot.
+func (p *paymentProcessorService) approveStandIn(ctx context.Context, transaction *repository.Transaction, unavailable error) error {
+	decision, err := p.standInService.Decide(*transaction)
+	if err != nil {
+		slog.ErrorContext(ctx, "error applying stand-in policy", logging.TransactionID(transaction.ID), logging.Err(err))
+	}
+	if err != nil || !decision.Approved {
+		if decision.CardBlocked {
+			p.saveDeclined(ctx, *transaction, decision.Reason, declineReasonCardBlocked)
+			return fmt.Errorf("%w: %s", ErrPaymentDenied, decision.Reason)
+		}
+		p.saveDeclined(ctx, *transaction, unavailable.Error(), declineReasonComplianceUnavailable)
+		return fmt.Errorf("%w: %w", ErrPaymentDenied, unavailable)
+	}
+
+	slog.WarnContext(ctx, "payment approved by stand-in policy while compliance-service is unavailable",
+		logging.TransactionID(transaction.ID), slog.String("stand_in_policy", decision.Policy))
+	transaction.StandIn = repository.StandInPending
+	transaction.StandInPolicy = decision.Policy
+	return nil
+}
+
+// saveDeclined records the declined payment. declineReason is the bounded reason it is counted under.
+func (p *paymentProcessorService) saveDeclined(ctx context.Context, transaction repository.Transaction, reason string, declineReason string) {
+	transaction.Status = repository.TransactionStatusDeclined
+	logDecision(ctx, transaction, reason)
+	paymentsTotal.WithLabelValues(transaction.Status, declineReason).Inc()
+	if err := p.transactionRepository.SaveTransaction(transaction); err != nil {
+		slog.ErrorContext(ctx, "error saving declined transaction", logging.TransactionID(transaction.ID), logging.Err(err))
+		return
+	}
+	p.publishOutcome(ctx, EventPaymentDeclined, transaction, reason)
 }
 
-func NewPaymentProcessorService(complianceRepository repository.ComplianceRepository) PaymentProcessorService {
-	return &paymentProcessorService{complianceRepository: complianceRepository}
+// logDecision logs the outcome of a payment, its status.
+func logDecision(ctx context.Context, transaction repository.Transaction, reason string) {
+	attrs := []slog.Attr{
+		logging.TransactionID(transaction.ID),
+		logging.UserID(transaction.UserID),
+		logging.CardID(transaction.CardID),
+		slog.Float64("amount", transaction.Amount),
+		logging.Decision(transaction.Status),
+	}
+	if reason != "" {
+		attrs = append(attrs, slog.String("reason", reason))
+	}
+	if transaction.StandInPolicy != "" {
+		attrs = append(attrs, slog.String("stand_in_policy", transaction.StandInPolicy))
+	}
+	if transaction.Reviewer != "" {
+		attrs = append(attrs, slog.String("reviewer", transaction.Reviewer))
+	}
+	slog.LogAttrs(ctx, slog.LevelInfo, "payment processed", attrs...)
 }
 
-func (p *paymentProcessorService) ProcessPayment(userID int64, cardID int64, amount float64) (string, error) {
-	isComplaiance, message := p.complianceRepository.CheckUserComplianceStatus(userID, cardID)
-	if !isComplaiance {
-		return "", fmt.Errorf("payment denied: %s", message)
+// publishOutcome notifies the webhook endpoints of a recorded payment. A payment is not failed because its outcome
+// could not be published.
+func (p *paymentProcessorService) publishOutcome(ctx context.Context, eventType string, transaction repository.Transaction, reason string) {
+	event := PaymentEvent{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		CardID:        transaction.CardID,
+		Amount:        transaction.Amount,
+		Status:        transaction.Status,
+		Reason:        reason,
+		StandInPolicy: transaction.StandInPolicy,
+		Reviewer:      transaction.Reviewer,
+	}
+	if transaction.Context != nil {
+		event.MerchantID = transaction.Context.MerchantID
 	}
 
-	transactionID := fmt.Sprintf("txn_%d", 1000000+rand.Intn(9000000))
+	if err := p.webhookService.Publish(transaction.ID+"."+transaction.Status, eventType, event); err != nil {
+		slog.ErrorContext(ctx, "error publishing payment outcome", slog.String("event_type", eventType),
+			logging.TransactionID(transaction.ID), logging.Err(err))
+	}
+}
+
+// holdForReview saves the payment as pending review and raises an alert for the reviewers. The payment is denied when
+// it cannot be recorded.
+func (p *paymentProcessorService) holdForReview(ctx context.Context, transaction repository.Transaction, reason string) error {
+	transaction.Status = repository.TransactionStatusPendingReview
+	if err := p.transactionRepository.SaveTransaction(transaction); err != nil {
+		slog.ErrorContext(ctx, "error saving transaction", logging.TransactionID(transaction.ID), logging.Err(err))
+		return fmt.Errorf("payment denied: error recording transaction")
+	}
+	logDecision(ctx, transaction, reason)
+	paymentsTotal.WithLabelValues(transaction.Status, "").Inc()
+	p.publishOutcome(ctx, EventPaymentPendingReview, transaction, reason)
+
+	alert := repository.Alert{
+		AlertType:     repository.AlertTypeManualReview,
+		UserID:        transaction.UserID,
+		CardID:        transaction.CardID,
+		TransactionID: transaction.ID,
+		Message:       reason,
+	}
+	if err := p.alertRepository.CreateAlert(alert); err != nil {
+		slog.ErrorContext(ctx, "error creating alert", logging.TransactionID(transaction.ID), logging.Err(err))
+	}
+
+	return fmt.Errorf("%w: %s. Transaction ID: %s", ErrPaymentPendingReview, reason, transaction.ID)
+}
+
+// ReviewPayment approves or declines a payment held for manual review.
+func (p *paymentProcessorService) ReviewPayment(ctx context.Context, transactionID string, status string, reviewer string) (repository.Transaction, error) {
+	reviewer = strings.TrimSpace(reviewer)
+	if status != repository.TransactionStatusApproved && status != repository.TransactionStatusDeclined {
+		return repository.Transaction{}, fmt.Errorf("%w: status must be approved or declined", ErrInvalidPaymentReview)
+	}
+	if reviewer == "" || len(reviewer) > maxReviewerNameLength {
+		return repository.Transaction{}, fmt.Errorf("%w: reviewer must be between 1 and %d characters", ErrInvalidPaymentReview, maxReviewerNameLength)
+	}
+
+	transaction, err := p.transactionRepository.GetTransaction(transactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.Transaction{}, ErrTransactionNotFound
+		}
+		return repository.Transaction{}, err
+	}
+	if transaction.Status != repository.TransactionStatusPendingReview {
+		return repository.Transaction{}, ErrPaymentNotPendingReview
+	}
+
+	reviewedAt := time.Now().UTC()
+	if err := p.transactionRepository.ReviewTransaction(transactionID, status, reviewer, reviewedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.Transaction{}, ErrPaymentNotPendingReview
+		}
+		return repository.Transaction{}, err
+	}
+
+	transaction.Status = status
+	transaction.Reviewer = reviewer
+	transaction.ReviewedAt = &reviewedAt
+
+	eventType := EventPaymentApproved
+	if status == repository.TransactionStatusDeclined {
+		eventType = EventPaymentDeclined
+	}
+	logDecision(ctx, transaction, "")
+	paymentReviewsTotal.WithLabelValues(status).Inc()
+	p.publishOutcome(ctx, eventType, transaction, "")
+	return transaction, nil
+}
 
-	return fmt.Sprintf("payment successful. Transaction ID: %s", transactionID), nil
+// ListUserPayments returns the last MaxUserPayments payments of the user since the provided time, whatever their
+// status, newest first.
+func (p *paymentProcessorService) ListUserPayments(userID int64, since time.Time) ([]repository.Transaction, error) {
+	return p.transactionRepository.GetUserPayments(userID, since, MaxUserPayments)
 }
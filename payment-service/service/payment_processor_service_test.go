@@ -1,18 +1,28 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flarrocca/payment-service/repository"
 	"flarrocca/payment-service/repository/mock"
+	webhookmock "flarrocca/webhook/mock"
+	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestProcessPayment(t *testing.T) {
 	type input struct {
-		userID int64
-		cardID int64
-		amount float64
+		userID         int64
+		cardID         int64
+		amount         float64
+		paymentContext *repository.PaymentContext
 	}
 
 	type output struct {
@@ -21,7 +31,14 @@ func TestProcessPayment(t *testing.T) {
 	}
 
 	type depFields struct {
-		complianceRepositoryMock *mock.MockComplianceRepository
+		complianceRepositoryMock     *mock.MockComplianceRepository
+		transactionRepositoryMock    *mock.MockTransactionRepository
+		alertRepositoryMock          *mock.MockAlertRepository
+		ipIntelligenceRepositoryMock *mock.MockIPIntelligenceRepository
+		standInRepositoryMock        *mock.MockStandInRepository
+		webhookServiceMock           *webhookmock.MockService
+		fraudRules                   []FraudRule
+		standInPolicies              standInPolicies
 	}
 
 	tests := []struct {
@@ -38,10 +55,19 @@ func TestProcessPayment(t *testing.T) {
 				amount: 100.50,
 			},
 			on: func(dep *depFields, in input) {
-				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(in.userID, in.cardID).Return(true, "User is complaiance")
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).Return(repository.ComplianceResponse{IsComplaiance: true, Message: "User is complaiance"}, nil)
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, in.userID, transaction.UserID)
+					assert.Equal(t, in.cardID, transaction.CardID)
+					assert.Equal(t, in.amount, transaction.Amount)
+					assert.Equal(t, repository.TransactionStatusApproved, transaction.Status)
+					assert.False(t, transaction.SuspectedFraud)
+					assert.Nil(t, transaction.Context)
+					return nil
+				})
 			},
 			assertFunc: func(t *testing.T, out output) {
-				assert.Contains(t, out.response, "payment successful. Transaction ID: txn_")
+				assert.Regexp(t, `^payment successful. Transaction ID: txn_[0-9a-f-]{36}$`, out.response)
 				assert.NoError(t, out.err)
 			},
 		},
@@ -53,13 +79,230 @@ func TestProcessPayment(t *testing.T) {
 				amount: 250.00,
 			},
 			on: func(dep *depFields, in input) {
-				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(in.userID, in.cardID).Return(false, "User is currently blocked due to reported stolen card/s")
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).Return(repository.ComplianceResponse{Message: "User is currently blocked due to reported stolen card/s"}, nil)
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusDeclined, transaction.Status)
+					return nil
+				})
 			},
 			assertFunc: func(t *testing.T, out output) {
 				assert.Empty(t, out.response)
 				assert.EqualError(t, out.err, "payment denied: User is currently blocked due to reported stolen card/s")
 			},
 		},
+		{
+			name: "Failure - Transaction cannot be recorded",
+			input: input{
+				userID: int64(1),
+				cardID: int64(1),
+				amount: 250.00,
+			},
+			on: func(dep *depFields, in input) {
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).Return(repository.ComplianceResponse{IsComplaiance: true, Message: "User is complaiance"}, nil)
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).Return(errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.EqualError(t, out.err, "payment denied: error recording transaction")
+			},
+		},
+		{
+			name: "Pending review - Context enriched with IP intelligence and rule signals raised",
+			input: input{
+				userID: int64(1),
+				cardID: int64(1),
+				amount: 100.50,
+				paymentContext: &repository.PaymentContext{
+					IPAddress:      "203.0.113.7",
+					BillingAddress: repository.Address{Country: "GB"},
+				},
+			},
+			on: func(dep *depFields, in input) {
+				dep.ipIntelligenceRepositoryMock.EXPECT().LookupIP("203.0.113.7").Return(repository.IPInfo{Country: "AU", ASN: 64500}, true)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).Return(repository.ComplianceResponse{IsComplaiance: true, Message: "User is complaiance"}, nil)
+				dep.fraudRules = []FraudRule{NewIPCountryMismatchRule(), staticRule{name: "never", flagged: false}}
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusPendingReview, transaction.Status)
+					assert.True(t, transaction.SuspectedFraud)
+					assert.Equal(t, "AU", transaction.Context.IPCountry)
+					assert.Equal(t, int64(64500), transaction.Context.IPASN)
+					return nil
+				})
+				dep.alertRepositoryMock.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert repository.Alert) error {
+					assert.Equal(t, repository.AlertTypeManualReview, alert.AlertType)
+					assert.Equal(t, "suspected fraud, ip_country_mismatch: IP country AU differs from billing country GB", alert.Message)
+					return nil
+				})
+				dep.alertRepositoryMock.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert repository.Alert) error {
+					assert.Equal(t, repository.AlertTypeFraudRule, alert.AlertType)
+					assert.Equal(t, "ip_country_mismatch: IP country AU differs from billing country GB", alert.Message)
+					return errors.New("database error")
+				})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.ErrorIs(t, out.err, ErrPaymentPendingReview)
+				assert.Regexp(t, `^payment held for manual review: suspected fraud, ip_country_mismatch: IP country AU differs from billing country GB. Transaction ID: txn_[0-9a-f-]{36}$`, out.err.Error())
+			},
+		},
+		{
+			name: "Failure - Payment declined by a fraud rule signal",
+			input: input{
+				userID: int64(1),
+				cardID: int64(1),
+				amount: 100.50,
+				paymentContext: &repository.PaymentContext{
+					IPAddress:      "203.0.113.7",
+					BillingAddress: repository.Address{Country: "GB"},
+				},
+			},
+			on: func(dep *depFields, in input) {
+				dep.ipIntelligenceRepositoryMock.EXPECT().LookupIP("203.0.113.7").Return(repository.IPInfo{Country: "AU"}, true)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).Return(repository.ComplianceResponse{IsComplaiance: true, Message: "User is complaiance"}, nil)
+				dep.fraudRules = []FraudRule{NewIPCountryMismatchRule(), staticRule{name: RuleImpossibleTravel, flagged: true}}
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusDeclined, transaction.Status)
+					assert.True(t, transaction.SuspectedFraud)
+					return nil
+				})
+				dep.alertRepositoryMock.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert repository.Alert) error {
+					assert.Equal(t, repository.AlertTypeFraudRule, alert.AlertType)
+					return nil
+				}).Times(2)
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.EqualError(t, out.err, "payment denied: suspected fraud, ip_country_mismatch: IP country AU differs from billing country GB; impossible_travel: static")
+			},
+		},
+		{
+			name: "Failure - Declined payment keeps its context",
+			input: input{
+				userID:         int64(1),
+				cardID:         int64(1),
+				amount:         100.50,
+				paymentContext: &repository.PaymentContext{IPAddress: "10.0.0.1", DeviceID: "device-1"},
+			},
+			on: func(dep *depFields, in input) {
+				dep.ipIntelligenceRepositoryMock.EXPECT().LookupIP("10.0.0.1").Return(repository.IPInfo{}, false)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).Return(repository.ComplianceResponse{Message: "User is currently blocked due to reported stolen card/s"}, nil)
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusDeclined, transaction.Status)
+					assert.Equal(t, "device-1", transaction.Context.DeviceID)
+					assert.Empty(t, transaction.Context.IPCountry)
+					return nil
+				})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.EqualError(t, out.err, "payment denied: User is currently blocked due to reported stolen card/s")
+			},
+		},
+		{
+			name: "Success - Payment of a high-risk user held for manual review",
+			input: input{
+				userID: int64(2),
+				cardID: int64(3),
+				amount: 750,
+			},
+			on: func(dep *depFields, in input) {
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).Return(repository.ComplianceResponse{
+					Message: "payment amount 750.00 of a high-risk user requires manual review", RiskRating: "high", ManualReview: true}, nil)
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusPendingReview, transaction.Status)
+					return nil
+				})
+				dep.alertRepositoryMock.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert repository.Alert) error {
+					assert.Equal(t, repository.AlertTypeManualReview, alert.AlertType)
+					assert.Equal(t, "payment amount 750.00 of a high-risk user requires manual review", alert.Message)
+					return nil
+				})
+				dep.complianceRepositoryMock.EXPECT().RequestCardReview(gomock.Any(), repository.CaseSourceHighRiskPayment, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, source string, transaction repository.Transaction) error {
+						assert.Equal(t, int64(3), transaction.CardID)
+						assert.Equal(t, float64(750), transaction.Amount)
+						return errors.New("connection refused")
+					})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.ErrorIs(t, out.err, ErrPaymentPendingReview)
+				assert.Contains(t, out.err.Error(), "requires manual review. Transaction ID: txn_")
+			},
+		},
+		{
+			name: "Failure - Compliance service unavailable",
+			input: input{
+				userID: int64(1),
+				cardID: int64(1),
+				amount: 100.5,
+			},
+			on: func(dep *depFields, in input) {
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).
+					Return(repository.ComplianceResponse{}, fmt.Errorf("%w: %w", repository.ErrComplianceUnavailable, repository.ErrCircuitOpen))
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusDeclined, transaction.Status)
+					return nil
+				})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.ErrorIs(t, out.err, repository.ErrComplianceUnavailable)
+				assert.EqualError(t, out.err, "payment denied: compliance service unavailable: circuit breaker open")
+			},
+		},
+		{
+			name: "Success - Approved in stand-in mode under the fail-open limit",
+			input: input{
+				userID:         int64(1),
+				cardID:         int64(1),
+				amount:         40,
+				paymentContext: &repository.PaymentContext{MerchantID: "merchant-1"},
+			},
+			on: func(dep *depFields, in input) {
+				dep.standInPolicies = standInPolicies{
+					{MerchantID: "merchant-1", MaxAmount: 50, Mode: StandInFailOpen},
+					{MerchantID: "merchant-1", MaxAmount: math.Inf(1), Mode: StandInFailClosed},
+				}
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).
+					Return(repository.ComplianceResponse{}, fmt.Errorf("%w: compliance service timed out", repository.ErrComplianceUnavailable))
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusApproved, transaction.Status)
+					assert.Equal(t, repository.StandInPending, transaction.StandIn)
+					assert.Equal(t, StandInFailOpen, transaction.StandInPolicy)
+					return nil
+				})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.NoError(t, out.err)
+				assert.Contains(t, out.response, "approved in stand-in mode pending compliance reconciliation. Transaction ID: txn_")
+			},
+		},
+		{
+			name: "Failure - Card blocked in the stand-in snapshot",
+			input: input{
+				userID: int64(3),
+				cardID: int64(7),
+				amount: 40,
+			},
+			on: func(dep *depFields, in input) {
+				dep.standInPolicies = standInPolicies{{MerchantID: standInAnyMerchant, MaxAmount: math.Inf(1), Mode: StandInSnapshot}}
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), in.userID, in.cardID, in.amount, gomock.Any()).
+					Return(repository.ComplianceResponse{}, fmt.Errorf("%w: compliance service timed out", repository.ErrComplianceUnavailable))
+				dep.standInRepositoryMock.EXPECT().GetSnapshot().Return(repository.StandInSnapshot{Cards: 1, SyncedAt: time.Now().UTC()}, nil)
+				dep.standInRepositoryMock.EXPECT().IsCardBlocked(in.userID, in.cardID).Return(true, nil)
+				dep.transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).DoAndReturn(func(transaction repository.Transaction) error {
+					assert.Equal(t, repository.TransactionStatusDeclined, transaction.Status)
+					assert.Empty(t, transaction.StandIn)
+					return nil
+				})
+			},
+			assertFunc: func(t *testing.T, out output) {
+				assert.Empty(t, out.response)
+				assert.NotErrorIs(t, out.err, repository.ErrComplianceUnavailable)
+				assert.EqualError(t, out.err, "payment denied: card is blocked due to being reported as stolen or compromised")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -67,13 +310,278 @@ func TestProcessPayment(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			complianceRepositoryMock := mock.NewMockComplianceRepository(ctrl)
-			tt.on(&depFields{complianceRepositoryMock: complianceRepositoryMock}, tt.input)
+			dep := &depFields{
+				complianceRepositoryMock:     mock.NewMockComplianceRepository(ctrl),
+				transactionRepositoryMock:    mock.NewMockTransactionRepository(ctrl),
+				alertRepositoryMock:          mock.NewMockAlertRepository(ctrl),
+				ipIntelligenceRepositoryMock: mock.NewMockIPIntelligenceRepository(ctrl),
+				standInRepositoryMock:        mock.NewMockStandInRepository(ctrl),
+				webhookServiceMock:           webhookmock.NewMockService(ctrl),
+			}
+			tt.on(dep, tt.input)
+			dep.webhookServiceMock.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-			service := &paymentProcessorService{complianceRepository: complianceRepositoryMock}
-			response, err := service.ProcessPayment(tt.input.userID, tt.input.cardID, tt.input.amount)
+			service := &paymentProcessorService{
+				complianceRepository:     dep.complianceRepositoryMock,
+				transactionRepository:    dep.transactionRepositoryMock,
+				alertRepository:          dep.alertRepositoryMock,
+				ipIntelligenceRepository: dep.ipIntelligenceRepositoryMock,
+				fraudRuleService:         NewFraudRuleService(dep.fraudRules...),
+				standInService: &standInService{
+					standInRepository: dep.standInRepositoryMock,
+					policies:          dep.standInPolicies,
+					snapshotMaxAge:    time.Hour,
+					now:               time.Now,
+				},
+				webhookService: dep.webhookServiceMock,
+			}
+			response, err := service.ProcessPayment(context.Background(), tt.input.userID, tt.input.cardID, tt.input.amount, tt.input.paymentContext)
 
 			tt.assertFunc(t, output{response, err})
 		})
 	}
 }
+
+func TestReviewPayment(t *testing.T) {
+	type input struct {
+		transactionID string
+		status        string
+		reviewer      string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(transactionRepositoryMock *mock.MockTransactionRepository)
+		assertFunc func(t *testing.T, transaction repository.Transaction, err error)
+	}{
+		{
+			name:  "Success - Held payment approved",
+			input: input{transactionID: "txn_123456", status: repository.TransactionStatusApproved, reviewer: " alice "},
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetTransaction("txn_123456").
+					Return(repository.Transaction{ID: "txn_123456", Status: repository.TransactionStatusPendingReview}, nil)
+				transactionRepositoryMock.EXPECT().ReviewTransaction("txn_123456", repository.TransactionStatusApproved, "alice", gomock.Any()).Return(nil)
+			},
+			assertFunc: func(t *testing.T, transaction repository.Transaction, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, repository.TransactionStatusApproved, transaction.Status)
+				assert.Equal(t, "alice", transaction.Reviewer)
+				assert.NotNil(t, transaction.ReviewedAt)
+			},
+		},
+		{
+			name:  "Failure - Invalid status",
+			input: input{transactionID: "txn_123456", status: repository.TransactionStatusRefundPending, reviewer: "alice"},
+			on:    func(transactionRepositoryMock *mock.MockTransactionRepository) {},
+			assertFunc: func(t *testing.T, transaction repository.Transaction, err error) {
+				assert.EqualError(t, err, "invalid payment review: status must be approved or declined")
+			},
+		},
+		{
+			name:  "Failure - Reviewer required",
+			input: input{transactionID: "txn_123456", status: repository.TransactionStatusDeclined, reviewer: " "},
+			on:    func(transactionRepositoryMock *mock.MockTransactionRepository) {},
+			assertFunc: func(t *testing.T, transaction repository.Transaction, err error) {
+				assert.ErrorIs(t, err, ErrInvalidPaymentReview)
+			},
+		},
+		{
+			name:  "Failure - Transaction not found",
+			input: input{transactionID: "txn_999", status: repository.TransactionStatusDeclined, reviewer: "alice"},
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetTransaction("txn_999").Return(repository.Transaction{}, sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, transaction repository.Transaction, err error) {
+				assert.ErrorIs(t, err, ErrTransactionNotFound)
+			},
+		},
+		{
+			name:  "Failure - Payment not held for review",
+			input: input{transactionID: "txn_123456", status: repository.TransactionStatusDeclined, reviewer: "alice"},
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetTransaction("txn_123456").
+					Return(repository.Transaction{ID: "txn_123456", Status: repository.TransactionStatusApproved}, nil)
+			},
+			assertFunc: func(t *testing.T, transaction repository.Transaction, err error) {
+				assert.ErrorIs(t, err, ErrPaymentNotPendingReview)
+			},
+		},
+		{
+			name:  "Failure - Payment reviewed concurrently",
+			input: input{transactionID: "txn_123456", status: repository.TransactionStatusDeclined, reviewer: "alice"},
+			on: func(transactionRepositoryMock *mock.MockTransactionRepository) {
+				transactionRepositoryMock.EXPECT().GetTransaction("txn_123456").
+					Return(repository.Transaction{ID: "txn_123456", Status: repository.TransactionStatusPendingReview}, nil)
+				transactionRepositoryMock.EXPECT().ReviewTransaction("txn_123456", repository.TransactionStatusDeclined, "alice", gomock.Any()).Return(sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, transaction repository.Transaction, err error) {
+				assert.ErrorIs(t, err, ErrPaymentNotPendingReview)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+			tt.on(transactionRepositoryMock)
+			webhookServiceMock := webhookmock.NewMockService(ctrl)
+			webhookServiceMock.EXPECT().Publish("txn_123456.approved", EventPaymentApproved, gomock.Any()).Return(nil).MaxTimes(1)
+
+			service := &paymentProcessorService{transactionRepository: transactionRepositoryMock, webhookService: webhookServiceMock}
+			transaction, err := service.ReviewPayment(context.Background(), tt.input.transactionID, tt.input.status, tt.input.reviewer)
+
+			tt.assertFunc(t, transaction, err)
+		})
+	}
+}
+
+func TestProcessPaymentPublishesOutcome(t *testing.T) {
+	tests := []struct {
+		name       string
+		compliance repository.ComplianceResponse
+		saveErr    error
+		publishErr error
+		assertFunc func(t *testing.T, eventType string, event PaymentEvent, err error)
+	}{
+		{
+			name:       "Success - Approved payment published",
+			compliance: repository.ComplianceResponse{IsComplaiance: true, Message: "user is compliance"},
+			assertFunc: func(t *testing.T, eventType string, event PaymentEvent, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, EventPaymentApproved, eventType)
+				assert.Equal(t, PaymentEvent{TransactionID: event.TransactionID, UserID: 1, CardID: 2, Amount: 100, MerchantID: "grocer-001",
+					Status: repository.TransactionStatusApproved}, event)
+			},
+		},
+		{
+			name:       "Success - Declined payment published with the reason",
+			compliance: repository.ComplianceResponse{Message: "user is currently blocked due to reported stolen card/s"},
+			assertFunc: func(t *testing.T, eventType string, event PaymentEvent, err error) {
+				assert.Error(t, err)
+				assert.Equal(t, EventPaymentDeclined, eventType)
+				assert.Equal(t, "user is currently blocked due to reported stolen card/s", event.Reason)
+			},
+		},
+		{
+			name:       "Success - Held payment published",
+			compliance: repository.ComplianceResponse{ManualReview: true, Message: "high-risk user requires manual review"},
+			assertFunc: func(t *testing.T, eventType string, event PaymentEvent, err error) {
+				assert.ErrorIs(t, err, ErrPaymentPendingReview)
+				assert.Equal(t, EventPaymentPendingReview, eventType)
+				assert.Equal(t, repository.TransactionStatusPendingReview, event.Status)
+			},
+		},
+		{
+			name:       "Success - Payment goes through when the outcome cannot be published",
+			compliance: repository.ComplianceResponse{IsComplaiance: true, Message: "user is compliance"},
+			publishErr: errors.New("database is locked"),
+			assertFunc: func(t *testing.T, eventType string, event PaymentEvent, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, EventPaymentApproved, eventType)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceRepositoryMock := mock.NewMockComplianceRepository(ctrl)
+			complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), int64(1), int64(2), float64(100), gomock.Any()).Return(tt.compliance, nil)
+			complianceRepositoryMock.EXPECT().RequestCardReview(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+			transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).Return(nil)
+			alertRepositoryMock := mock.NewMockAlertRepository(ctrl)
+			alertRepositoryMock.EXPECT().CreateAlert(gomock.Any()).Return(nil).AnyTimes()
+
+			var eventType string
+			var event PaymentEvent
+			webhookServiceMock := webhookmock.NewMockService(ctrl)
+			webhookServiceMock.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(eventID string, publishedType string, payload any) error {
+					eventType, event = publishedType, payload.(PaymentEvent)
+					assert.Equal(t, event.TransactionID+"."+event.Status, eventID)
+					return tt.publishErr
+				})
+
+			service := &paymentProcessorService{
+				complianceRepository:  complianceRepositoryMock,
+				transactionRepository: transactionRepositoryMock,
+				alertRepository:       alertRepositoryMock,
+				fraudRuleService:      NewFraudRuleService(),
+				webhookService:        webhookServiceMock,
+			}
+			_, err := service.ProcessPayment(context.Background(), 1, 2, 100, &repository.PaymentContext{MerchantID: "grocer-001"})
+
+			tt.assertFunc(t, eventType, event, err)
+		})
+	}
+}
+
+func TestProcessPaymentMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		compliance repository.ComplianceResponse
+		err        error
+		decision   string
+		reason     string
+	}{
+		{
+			name:       "Success - Approved payment counted",
+			compliance: repository.ComplianceResponse{IsComplaiance: true, Message: "user is compliance"},
+			decision:   repository.TransactionStatusApproved,
+		},
+		{
+			name:       "Success - Denied payment counted with its reason",
+			compliance: repository.ComplianceResponse{Message: "user is currently blocked due to reported stolen card/s"},
+			decision:   repository.TransactionStatusDeclined,
+			reason:     declineReasonComplianceDenied,
+		},
+		{
+			name:     "Success - Payment compliance-service rejected counted as a compliance error",
+			err:      fmt.Errorf("%w: unexpected status 400", repository.ErrComplianceRequestFailed),
+			decision: repository.TransactionStatusDeclined,
+			reason:   declineReasonComplianceError,
+		},
+		{
+			name:       "Success - Held payment counted",
+			compliance: repository.ComplianceResponse{ManualReview: true, Message: "high-risk user requires manual review"},
+			decision:   repository.TransactionStatusPendingReview,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			complianceRepositoryMock := mock.NewMockComplianceRepository(ctrl)
+			complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), int64(1), int64(2), float64(100), gomock.Any()).Return(tt.compliance, tt.err)
+			complianceRepositoryMock.EXPECT().RequestCardReview(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			transactionRepositoryMock := mock.NewMockTransactionRepository(ctrl)
+			transactionRepositoryMock.EXPECT().SaveTransaction(gomock.Any()).Return(nil)
+			alertRepositoryMock := mock.NewMockAlertRepository(ctrl)
+			alertRepositoryMock.EXPECT().CreateAlert(gomock.Any()).Return(nil).AnyTimes()
+			webhookServiceMock := webhookmock.NewMockService(ctrl)
+			webhookServiceMock.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+			service := &paymentProcessorService{
+				complianceRepository:  complianceRepositoryMock,
+				transactionRepository: transactionRepositoryMock,
+				alertRepository:       alertRepositoryMock,
+				fraudRuleService:      NewFraudRuleService(),
+				webhookService:        webhookServiceMock,
+			}
+			counter := paymentsTotal.WithLabelValues(tt.decision, tt.reason)
+			before := testutil.ToFloat64(counter)
+			_, _ = service.ProcessPayment(context.Background(), 1, 2, 100, nil)
+
+			assert.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
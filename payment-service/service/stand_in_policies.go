@@ -0,0 +1,124 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	StandInFailClosed = "fail_closed"
+	StandInFailOpen   = "fail_open"
+	StandInSnapshot   = "snapshot"
+
+	standInAnyMerchant       = "*"
+	defaultStandInPolicyPath = "./database/stand_in_policies.csv"
+)
+
+var (
+	standInModes         = []string{StandInFailClosed, StandInFailOpen, StandInSnapshot}
+	standInPolicyColumns = []string{"merchant_id", "max_amount", "mode"}
+	defaultStandInPolicy = StandInPolicy{MerchantID: standInAnyMerchant, MaxAmount: math.Inf(1), Mode: StandInFailClosed}
+)
+
+// StandInPolicy decides the payments of a merchant, or of every merchant for *, up to MaxAmount while
+// compliance-service is down.
+type StandInPolicy struct {
+	MerchantID string
+	MaxAmount  float64
+	Mode       string
+}
+
+// standInPolicies holds the amount bands of the merchants, sorted by amount.
+type standInPolicies []StandInPolicy
+
+// match returns the first band of the merchant covering the amount, then the first band of every merchant. Payments
+// no band covers are declined.
+func (p standInPolicies) match(merchantID string, amount float64) StandInPolicy {
+	for _, merchant := range []string{merchantID, standInAnyMerchant} {
+		for _, policy := range p {
+			if policy.MerchantID == merchant && amount <= policy.MaxAmount {
+				return policy
+			}
+		}
+	}
+	return defaultStandInPolicy
+}
+
+// loadStandInPolicies reads the policies from the CSV file set in STAND_IN_POLICY_PATH. When the file is missing or
+// invalid, every payment is declined while compliance-service is down.
+func loadStandInPolicies() (standInPolicies, error) {
+	path := os.Getenv("STAND_IN_POLICY_PATH")
+	if path == "" {
+		path = defaultStandInPolicyPath
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseStandInPolicies(file)
+}
+
+// parseStandInPolicies reads a CSV file with the header merchant_id,max_amount,mode. An empty max_amount covers any
+// amount; lines starting with # are ignored.
+func parseStandInPolicies(r io.Reader) (standInPolicies, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = len(standInPolicyColumns)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid stand-in policies: %w", err)
+	}
+	for i, column := range header {
+		if strings.ToLower(strings.TrimSpace(column)) != standInPolicyColumns[i] {
+			return nil, fmt.Errorf("invalid stand-in policies: the header must be %s", strings.Join(standInPolicyColumns, ","))
+		}
+	}
+
+	var policies standInPolicies
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid stand-in policies: %w", err)
+		}
+
+		policy := StandInPolicy{
+			MerchantID: strings.TrimSpace(record[0]),
+			MaxAmount:  math.Inf(1),
+			Mode:       strings.ToLower(strings.TrimSpace(record[2])),
+		}
+		if policy.MerchantID == "" {
+			return nil, fmt.Errorf("invalid stand-in policies: policy without merchant_id, use %s for every merchant", standInAnyMerchant)
+		}
+		if maxAmount := strings.TrimSpace(record[1]); maxAmount != "" {
+			policy.MaxAmount, err = strconv.ParseFloat(maxAmount, 64)
+			if err != nil || policy.MaxAmount <= 0 || math.IsNaN(policy.MaxAmount) {
+				return nil, fmt.Errorf("invalid stand-in policies: merchant %s has max_amount %q, expected a positive amount or nothing", policy.MerchantID, maxAmount)
+			}
+		}
+		if !slices.Contains(standInModes, policy.Mode) {
+			return nil, fmt.Errorf("invalid stand-in policies: merchant %s has mode %q, expected one of %s", policy.MerchantID, policy.Mode, strings.Join(standInModes, ", "))
+		}
+		policies = append(policies, policy)
+	}
+
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].MaxAmount < policies[j].MaxAmount
+	})
+
+	return policies, nil
+}
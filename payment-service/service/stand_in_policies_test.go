@@ -0,0 +1,97 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStandInPolicies(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		assertFunc func(t *testing.T, policies standInPolicies, err error)
+	}{
+		{
+			name: "Success - Bands sorted by amount",
+			input: "merchant_id,max_amount,mode\n" +
+				"# the grocer fails open on small baskets\n" +
+				"grocer-001,,snapshot\n" +
+				"grocer-001, 50 ,FAIL_OPEN\n" +
+				"*,,fail_closed\n",
+			assertFunc: func(t *testing.T, policies standInPolicies, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, standInPolicies{
+					{MerchantID: "grocer-001", MaxAmount: 50, Mode: StandInFailOpen},
+					{MerchantID: "grocer-001", MaxAmount: math.Inf(1), Mode: StandInSnapshot},
+					{MerchantID: standInAnyMerchant, MaxAmount: math.Inf(1), Mode: StandInFailClosed},
+				}, policies)
+			},
+		},
+		{
+			name:  "Failure - Unexpected header",
+			input: "merchant,limit,mode\n",
+			assertFunc: func(t *testing.T, policies standInPolicies, err error) {
+				assert.EqualError(t, err, "invalid stand-in policies: the header must be merchant_id,max_amount,mode")
+			},
+		},
+		{
+			name:  "Failure - Unknown mode",
+			input: "merchant_id,max_amount,mode\ngrocer-001,50,approve\n",
+			assertFunc: func(t *testing.T, policies standInPolicies, err error) {
+				assert.EqualError(t, err, `invalid stand-in policies: merchant grocer-001 has mode "approve", expected one of fail_closed, fail_open, snapshot`)
+			},
+		},
+		{
+			name:  "Failure - Invalid amount",
+			input: "merchant_id,max_amount,mode\ngrocer-001,-5,fail_open\n",
+			assertFunc: func(t *testing.T, policies standInPolicies, err error) {
+				assert.ErrorContains(t, err, `merchant grocer-001 has max_amount "-5"`)
+			},
+		},
+		{
+			name:  "Failure - Policy without merchant",
+			input: "merchant_id,max_amount,mode\n,50,fail_open\n",
+			assertFunc: func(t *testing.T, policies standInPolicies, err error) {
+				assert.ErrorContains(t, err, "policy without merchant_id")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies, err := parseStandInPolicies(strings.NewReader(tt.input))
+			tt.assertFunc(t, policies, err)
+		})
+	}
+}
+
+func TestMatchStandInPolicy(t *testing.T) {
+	policies := standInPolicies{
+		{MerchantID: standInAnyMerchant, MaxAmount: 20, Mode: StandInFailOpen},
+		{MerchantID: "grocer-001", MaxAmount: 50, Mode: StandInFailOpen},
+		{MerchantID: "grocer-001", MaxAmount: 500, Mode: StandInSnapshot},
+		{MerchantID: standInAnyMerchant, MaxAmount: 200, Mode: StandInSnapshot},
+	}
+
+	tests := []struct {
+		merchantID string
+		amount     float64
+		mode       string
+	}{
+		{merchantID: "grocer-001", amount: 10, mode: StandInFailOpen},
+		{merchantID: "grocer-001", amount: 50, mode: StandInFailOpen},
+		{merchantID: "grocer-001", amount: 120, mode: StandInSnapshot},
+		{merchantID: "grocer-001", amount: 900, mode: StandInFailClosed},
+		{merchantID: "jeweller-7", amount: 10, mode: StandInFailOpen},
+		{merchantID: "", amount: 120, mode: StandInSnapshot},
+		{merchantID: "", amount: 300, mode: StandInFailClosed},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.mode, policies.match(tt.merchantID, tt.amount).Mode, "merchant %q amount %.2f", tt.merchantID, tt.amount)
+	}
+	assert.Equal(t, StandInFailClosed, standInPolicies(nil).match("grocer-001", 1).Mode)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flarrocca/logging"
+	"flarrocca/payment-service/repository"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	defaultStandInSnapshotMaxAge = 24 * time.Hour
+	standInReconcileBatchSize    = 500
+)
+
+// StandInDecision tells whether a payment compliance-service could not check is approved anyway. CardBlocked is set
+// when the payment is declined because the card is in the local snapshot of blocked cards.
+type StandInDecision struct {
+	Approved    bool
+	Policy      string
+	Reason      string
+	CardBlocked bool
+}
+
+// StandInReconciliation summarises a reconciliation run. Failed payments stay pending until the next run.
+type StandInReconciliation struct {
+	Checked   int `json:"checked"`
+	Confirmed int `json:"confirmed"`
+	Rejected  int `json:"rejected"`
+	Failed    int `json:"failed"`
+}
+
+// Run from the /service folder the following command to generate the mock:
+// mockgen -source stand_in_service.go -destination mock/stand_in_service_mock.go -package mock
+type StandInService interface {
+	Decide(transaction repository.Transaction) (StandInDecision, error)
+	SyncSnapshot() (repository.StandInSnapshot, error)
+	Reconcile() (StandInReconciliation, error)
+}
+
+type standInService struct {
+	complianceRepository  repository.ComplianceRepository
+	transactionRepository repository.TransactionRepository
+	alertRepository       repository.AlertRepository
+	standInRepository     repository.StandInRepository
+	policies              standInPolicies
+	snapshotMaxAge        time.Duration
+	now                   func() time.Time
+}
+
+// NewStandInService loads the stand-in policies and reads STAND_IN_SNAPSHOT_MAX_AGE, a duration such as 12h, after
+// which the snapshot of blocked cards is too old to approve payments.
+func NewStandInService(complianceRepository repository.ComplianceRepository, transactionRepository repository.TransactionRepository,
+	alertRepository repository.AlertRepository, standInRepository repository.StandInRepository) StandInService {
+	policies, err := loadStandInPolicies()
+	if err != nil {
+		slog.Warn("stand-in policies not loaded, payments are declined while compliance-service is down", logging.Err(err))
+	}
+
+	snapshotMaxAge, err := time.ParseDuration(os.Getenv("STAND_IN_SNAPSHOT_MAX_AGE"))
+	if err != nil || snapshotMaxAge <= 0 {
+		snapshotMaxAge = defaultStandInSnapshotMaxAge
+	}
+
+	return &standInService{
+		complianceRepository:  complianceRepository,
+		transactionRepository: transactionRepository,
+		alertRepository:       alertRepository,
+		standInRepository:     standInRepository,
+		policies:              policies,
+		snapshotMaxAge:        snapshotMaxAge,
+		now:                   func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Decide applies the policy of the merchant and amount of the payment. The snapshot policy declines the payment when
+// the snapshot was never synced or is older than snapshotMaxAge.
+func (s *standInService) Decide(transaction repository.Transaction) (StandInDecision, error) {
+	var merchantID string
+	if transaction.Context != nil {
+		merchantID = transaction.Context.MerchantID
+	}
+
+	policy := s.policies.match(merchantID, transaction.Amount)
+	decision := StandInDecision{Policy: policy.Mode}
+	switch policy.Mode {
+	case StandInFailOpen:
+		decision.Approved = true
+		return decision, nil
+	case StandInSnapshot:
+		snapshot, err := s.standInRepository.GetSnapshot()
+		if errors.Is(err, sql.ErrNoRows) {
+			decision.Reason = "blocked card snapshot never synced"
+			return decision, nil
+		}
+		if err != nil {
+			return decision, err
+		}
+		if age := s.now().Sub(snapshot.SyncedAt); age > s.snapshotMaxAge {
+			decision.Reason = fmt.Sprintf("blocked card snapshot synced %s ago", age.Round(time.Minute))
+			return decision, nil
+		}
+
+		blocked, err := s.standInRepository.IsCardBlocked(transaction.UserID, transaction.CardID)
+		if err != nil {
+			return decision, err
+		}
+		if blocked {
+			decision.CardBlocked = true
+			decision.Reason = "card is blocked due to being reported as stolen or compromised"
+			return decision, nil
+		}
+		decision.Approved = true
+		return decision, nil
+	}
+
+	decision.Reason = "stand-in policy is fail_closed"
+	return decision, nil
+}
+
+// SyncSnapshot replaces the local snapshot with the cards currently blocked by compliance-service.
+func (s *standInService) SyncSnapshot() (repository.StandInSnapshot, error) {
+	cards, err := s.complianceRepository.ListBlockedCards(context.Background())
+	if err != nil {
+		return repository.StandInSnapshot{}, err
+	}
+
+	snapshot := repository.StandInSnapshot{Cards: len(cards), SyncedAt: s.now()}
+	if err := s.standInRepository.ReplaceBlockedCards(cards, snapshot.SyncedAt); err != nil {
+		return repository.StandInSnapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// Reconcile checks the oldest pending stand-in payments with compliance-service. A payment compliance-service would
+// have denied, or held for manual review, is rejected and raises an alert so it can be refunded or reviewed. The run
+// stops as soon as compliance-service is unavailable again.
+func (s *standInService) Reconcile() (StandInReconciliation, error) {
+	var reconciliation StandInReconciliation
+
+	transactions, err := s.transactionRepository.GetStandInTransactions(repository.StandInPending, standInReconcileBatchSize)
+	if err != nil {
+		return reconciliation, err
+	}
+
+	for _, transaction := range transactions {
+		compliance, err := s.complianceRepository.CheckUserComplianceStatus(context.Background(), transaction.UserID, transaction.CardID, transaction.Amount, transaction.Context)
+		if errors.Is(err, repository.ErrComplianceUnavailable) {
+			return reconciliation, err
+		}
+		reconciliation.Checked++
+		if err != nil {
+			slog.Error("error reconciling stand-in transaction", logging.TransactionID(transaction.ID), logging.Err(err))
+			reconciliation.Failed++
+			continue
+		}
+
+		standIn := repository.StandInConfirmed
+		if !compliance.IsComplaiance || compliance.ManualReview {
+			standIn = repository.StandInRejected
+		}
+
+		if err := s.transactionRepository.ReconcileStandIn(transaction.ID, standIn, s.now()); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// reconciled by a concurrent run
+				continue
+			}
+			return reconciliation, err
+		}
+
+		if standIn == repository.StandInConfirmed {
+			reconciliation.Confirmed++
+			continue
+		}
+
+		reconciliation.Rejected++
+		alert := repository.Alert{
+			AlertType:     repository.AlertTypeStandInRejected,
+			UserID:        transaction.UserID,
+			CardID:        transaction.CardID,
+			TransactionID: transaction.ID,
+			Message:       fmt.Sprintf("payment of %.2f approved by stand-in policy %s was not compliant: %s", transaction.Amount, transaction.StandInPolicy, compliance.Message),
+		}
+		if err := s.alertRepository.CreateAlert(alert); err != nil {
+			slog.Error("error creating alert", logging.TransactionID(transaction.ID), logging.Err(err))
+		}
+	}
+
+	return reconciliation, nil
+}
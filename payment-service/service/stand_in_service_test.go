@@ -0,0 +1,249 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/repository/mock"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type standInDepFields struct {
+	complianceRepositoryMock  *mock.MockComplianceRepository
+	transactionRepositoryMock *mock.MockTransactionRepository
+	alertRepositoryMock       *mock.MockAlertRepository
+	standInRepositoryMock     *mock.MockStandInRepository
+}
+
+func newTestStandInService(ctrl *gomock.Controller, now time.Time, policies standInPolicies) (*standInService, *standInDepFields) {
+	dep := &standInDepFields{
+		complianceRepositoryMock:  mock.NewMockComplianceRepository(ctrl),
+		transactionRepositoryMock: mock.NewMockTransactionRepository(ctrl),
+		alertRepositoryMock:       mock.NewMockAlertRepository(ctrl),
+		standInRepositoryMock:     mock.NewMockStandInRepository(ctrl),
+	}
+	return &standInService{
+		complianceRepository:  dep.complianceRepositoryMock,
+		transactionRepository: dep.transactionRepositoryMock,
+		alertRepository:       dep.alertRepositoryMock,
+		standInRepository:     dep.standInRepositoryMock,
+		policies:              policies,
+		snapshotMaxAge:        time.Hour,
+		now:                   func() time.Time { return now },
+	}, dep
+}
+
+func TestDecideStandIn(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	policies := standInPolicies{
+		{MerchantID: "grocer-001", MaxAmount: 50, Mode: StandInFailOpen},
+		{MerchantID: standInAnyMerchant, MaxAmount: 500, Mode: StandInSnapshot},
+	}
+	transaction := repository.Transaction{ID: "txn_1", UserID: 3, CardID: 7, Amount: 120}
+
+	tests := []struct {
+		name       string
+		input      repository.Transaction
+		on         func(dep *standInDepFields)
+		assertFunc func(t *testing.T, decision StandInDecision, err error)
+	}{
+		{
+			name:  "Success - Merchant fails open under its limit",
+			input: repository.Transaction{ID: "txn_1", UserID: 3, CardID: 7, Amount: 45, Context: &repository.PaymentContext{MerchantID: "grocer-001"}},
+			on:    func(dep *standInDepFields) {},
+			assertFunc: func(t *testing.T, decision StandInDecision, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, StandInDecision{Approved: true, Policy: StandInFailOpen}, decision)
+			},
+		},
+		{
+			name:  "Success - Card not in a recent snapshot",
+			input: transaction,
+			on: func(dep *standInDepFields) {
+				dep.standInRepositoryMock.EXPECT().GetSnapshot().Return(repository.StandInSnapshot{Cards: 12, SyncedAt: now.Add(-10 * time.Minute)}, nil)
+				dep.standInRepositoryMock.EXPECT().IsCardBlocked(int64(3), int64(7)).Return(false, nil)
+			},
+			assertFunc: func(t *testing.T, decision StandInDecision, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, StandInDecision{Approved: true, Policy: StandInSnapshot}, decision)
+			},
+		},
+		{
+			name:  "Failure - Card in the snapshot",
+			input: transaction,
+			on: func(dep *standInDepFields) {
+				dep.standInRepositoryMock.EXPECT().GetSnapshot().Return(repository.StandInSnapshot{Cards: 12, SyncedAt: now.Add(-10 * time.Minute)}, nil)
+				dep.standInRepositoryMock.EXPECT().IsCardBlocked(int64(3), int64(7)).Return(true, nil)
+			},
+			assertFunc: func(t *testing.T, decision StandInDecision, err error) {
+				assert.NoError(t, err)
+				assert.False(t, decision.Approved)
+				assert.True(t, decision.CardBlocked)
+			},
+		},
+		{
+			name:  "Failure - Snapshot too old",
+			input: transaction,
+			on: func(dep *standInDepFields) {
+				dep.standInRepositoryMock.EXPECT().GetSnapshot().Return(repository.StandInSnapshot{Cards: 12, SyncedAt: now.Add(-3 * time.Hour)}, nil)
+			},
+			assertFunc: func(t *testing.T, decision StandInDecision, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, StandInDecision{Policy: StandInSnapshot, Reason: "blocked card snapshot synced 3h0m0s ago"}, decision)
+			},
+		},
+		{
+			name:  "Failure - Snapshot never synced",
+			input: transaction,
+			on: func(dep *standInDepFields) {
+				dep.standInRepositoryMock.EXPECT().GetSnapshot().Return(repository.StandInSnapshot{}, sql.ErrNoRows)
+			},
+			assertFunc: func(t *testing.T, decision StandInDecision, err error) {
+				assert.NoError(t, err)
+				assert.False(t, decision.Approved)
+				assert.Equal(t, "blocked card snapshot never synced", decision.Reason)
+			},
+		},
+		{
+			name:  "Failure - Amount above every band",
+			input: repository.Transaction{ID: "txn_1", UserID: 3, CardID: 7, Amount: 900},
+			on:    func(dep *standInDepFields) {},
+			assertFunc: func(t *testing.T, decision StandInDecision, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, StandInDecision{Policy: StandInFailClosed, Reason: "stand-in policy is fail_closed"}, decision)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestStandInService(ctrl, now, policies)
+			tt.on(dep)
+
+			decision, err := service.Decide(tt.input)
+			tt.assertFunc(t, decision, err)
+		})
+	}
+}
+
+func TestSyncStandInSnapshot(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, dep := newTestStandInService(ctrl, now, nil)
+	cards := []repository.BlockedCard{{UserID: 1, CardID: 2}, {UserID: 3, CardID: 7}}
+	dep.complianceRepositoryMock.EXPECT().ListBlockedCards(gomock.Any()).Return(cards, nil)
+	dep.standInRepositoryMock.EXPECT().ReplaceBlockedCards(cards, now).Return(nil)
+
+	snapshot, err := service.SyncSnapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, repository.StandInSnapshot{Cards: 2, SyncedAt: now}, snapshot)
+
+	// the previous snapshot is kept while compliance-service is down
+	dep.complianceRepositoryMock.EXPECT().ListBlockedCards(gomock.Any()).Return(nil, repository.ErrComplianceUnavailable)
+	_, err = service.SyncSnapshot()
+	assert.ErrorIs(t, err, repository.ErrComplianceUnavailable)
+}
+
+func TestReconcileStandIn(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	pending := []repository.Transaction{
+		{ID: "txn_1", UserID: 1, CardID: 2, Amount: 40, StandIn: repository.StandInPending, StandInPolicy: StandInFailOpen,
+			Context: &repository.PaymentContext{MerchantID: "grocer-001"}},
+		{ID: "txn_2", UserID: 3, CardID: 7, Amount: 120, StandIn: repository.StandInPending, StandInPolicy: StandInSnapshot},
+		{ID: "txn_3", UserID: 4, CardID: 8, Amount: 20, StandIn: repository.StandInPending, StandInPolicy: StandInFailOpen},
+	}
+
+	tests := []struct {
+		name       string
+		on         func(dep *standInDepFields)
+		assertFunc func(t *testing.T, reconciliation StandInReconciliation, err error)
+	}{
+		{
+			name: "Success - Compliant payments confirmed, the others rejected with an alert",
+			on: func(dep *standInDepFields) {
+				dep.transactionRepositoryMock.EXPECT().GetStandInTransactions(repository.StandInPending, standInReconcileBatchSize).Return(pending, nil)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), int64(1), int64(2), 40.0, pending[0].Context).
+					Return(repository.ComplianceResponse{IsComplaiance: true, Message: "user is compliance"}, nil)
+				dep.transactionRepositoryMock.EXPECT().ReconcileStandIn("txn_1", repository.StandInConfirmed, now).Return(nil)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), int64(3), int64(7), 120.0, gomock.Nil()).
+					Return(repository.ComplianceResponse{Message: "user is currently blocked due to reported stolen card/s"}, nil)
+				dep.transactionRepositoryMock.EXPECT().ReconcileStandIn("txn_2", repository.StandInRejected, now).Return(nil)
+				dep.alertRepositoryMock.EXPECT().CreateAlert(repository.Alert{
+					AlertType:     repository.AlertTypeStandInRejected,
+					UserID:        3,
+					CardID:        7,
+					TransactionID: "txn_2",
+					Message:       "payment of 120.00 approved by stand-in policy snapshot was not compliant: user is currently blocked due to reported stolen card/s",
+				}).Return(nil)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), int64(4), int64(8), 20.0, gomock.Nil()).
+					Return(repository.ComplianceResponse{}, fmt.Errorf("%w: compliance service returned status code: 400", repository.ErrComplianceRequestFailed))
+			},
+			assertFunc: func(t *testing.T, reconciliation StandInReconciliation, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, StandInReconciliation{Checked: 3, Confirmed: 1, Rejected: 1, Failed: 1}, reconciliation)
+			},
+		},
+		{
+			name: "Failure - Stops when compliance service is unavailable again",
+			on: func(dep *standInDepFields) {
+				dep.transactionRepositoryMock.EXPECT().GetStandInTransactions(repository.StandInPending, standInReconcileBatchSize).Return(pending, nil)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), int64(1), int64(2), 40.0, pending[0].Context).
+					Return(repository.ComplianceResponse{IsComplaiance: true}, nil)
+				dep.transactionRepositoryMock.EXPECT().ReconcileStandIn("txn_1", repository.StandInConfirmed, now).Return(nil)
+				dep.complianceRepositoryMock.EXPECT().CheckUserComplianceStatus(gomock.Any(), int64(3), int64(7), 120.0, gomock.Nil()).
+					Return(repository.ComplianceResponse{}, fmt.Errorf("%w: %w", repository.ErrComplianceUnavailable, repository.ErrCircuitOpen))
+			},
+			assertFunc: func(t *testing.T, reconciliation StandInReconciliation, err error) {
+				assert.ErrorIs(t, err, repository.ErrComplianceUnavailable)
+				assert.Equal(t, StandInReconciliation{Checked: 1, Confirmed: 1}, reconciliation)
+			},
+		},
+		{
+			name: "Failure - Error listing the pending payments",
+			on: func(dep *standInDepFields) {
+				dep.transactionRepositoryMock.EXPECT().GetStandInTransactions(repository.StandInPending, standInReconcileBatchSize).Return(nil, errors.New("database error"))
+			},
+			assertFunc: func(t *testing.T, reconciliation StandInReconciliation, err error) {
+				assert.EqualError(t, err, "database error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, dep := newTestStandInService(ctrl, now, nil)
+			tt.on(dep)
+
+			reconciliation, err := service.Reconcile()
+			tt.assertFunc(t, reconciliation, err)
+		})
+	}
+}
+
+func TestNewStandInServiceWithoutPolicies(t *testing.T) {
+	t.Setenv("STAND_IN_POLICY_PATH", "../database/missing.csv")
+	t.Setenv("STAND_IN_SNAPSHOT_MAX_AGE", "")
+
+	service := NewStandInService(nil, nil, nil, nil).(*standInService)
+	assert.Empty(t, service.policies)
+	assert.Equal(t, defaultStandInSnapshotMaxAge, service.snapshotMaxAge)
+
+	decision, err := service.Decide(repository.Transaction{Amount: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, StandInFailClosed, decision.Policy)
+	assert.Equal(t, math.Inf(1), defaultStandInPolicy.MaxAmount)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"flarrocca/logging"
+	"flarrocca/payment-service/repository"
+	"flarrocca/payment-service/service"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const defaultStandInSyncInterval = 5 * time.Minute
+
+// runStandInWorker refreshes the snapshot of blocked cards and reconciles the stand-in payments every
+// STAND_IN_SYNC_INTERVAL, a duration such as 1m.
+func runStandInWorker(standInService service.StandInService) {
+	interval, err := time.ParseDuration(os.Getenv("STAND_IN_SYNC_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = defaultStandInSyncInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		syncStandIn(standInService)
+		<-ticker.C
+	}
+}
+
+func syncStandIn(standInService service.StandInService) {
+	if _, err := standInService.SyncSnapshot(); err != nil {
+		slog.Error("error syncing blocked card snapshot", logging.Err(err))
+		if errors.Is(err, repository.ErrComplianceUnavailable) {
+			return
+		}
+	}
+
+	reconciliation, err := standInService.Reconcile()
+	if err != nil {
+		slog.Error("error reconciling stand-in payments", logging.Err(err))
+	}
+	if reconciliation.Checked > 0 {
+		slog.Info("stand-in reconciliation", slog.Int("checked", reconciliation.Checked), slog.Int("confirmed", reconciliation.Confirmed),
+			slog.Int("rejected", reconciliation.Rejected), slog.Int("failed", reconciliation.Failed))
+	}
+}
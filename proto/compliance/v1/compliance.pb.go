@@ -0,0 +1,1115 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: compliance/v1/compliance.proto
+
+// Version 1 of the API compliance-service exposes to payment-service. Fields may be added to the messages, but never
+// renumbered or removed; a breaking change goes in a new compliance.v2 package served next to this one.
+
+package compliancev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PaymentContext holds the details of a payment matched against the deny and allow lists. Empty fields are ignored.
+type PaymentContext struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IpAddress  string `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Email      string `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	DeviceId   string `protobuf:"bytes,3,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	MerchantId string `protobuf:"bytes,4,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+}
+
+func (x *PaymentContext) Reset() {
+	*x = PaymentContext{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaymentContext) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentContext) ProtoMessage() {}
+
+func (x *PaymentContext) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentContext.ProtoReflect.Descriptor instead.
+func (*PaymentContext) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PaymentContext) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *PaymentContext) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *PaymentContext) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *PaymentContext) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type CheckComplianceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId int64 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CardId int64 `protobuf:"varint,2,opt,name=card_id,json=cardId,proto3" json:"card_id,omitempty"`
+	// Amount of the payment, checked against the KYC tier and high-risk limits of the user.
+	Amount  float64         `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Context *PaymentContext `protobuf:"bytes,4,opt,name=context,proto3" json:"context,omitempty"`
+}
+
+func (x *CheckComplianceRequest) Reset() {
+	*x = CheckComplianceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckComplianceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckComplianceRequest) ProtoMessage() {}
+
+func (x *CheckComplianceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckComplianceRequest.ProtoReflect.Descriptor instead.
+func (*CheckComplianceRequest) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckComplianceRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CheckComplianceRequest) GetCardId() int64 {
+	if x != nil {
+		return x.CardId
+	}
+	return 0
+}
+
+func (x *CheckComplianceRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CheckComplianceRequest) GetContext() *PaymentContext {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+type CheckComplianceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Compliant bool   `protobuf:"varint,1,opt,name=compliant,proto3" json:"compliant,omitempty"`
+	Message   string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Risk rating of the user: low, medium or high. Empty when the check stopped before rating the user.
+	RiskRating string `protobuf:"bytes,3,opt,name=risk_rating,json=riskRating,proto3" json:"risk_rating,omitempty"`
+	// Set, with compliant false, when the payment must be held until a reviewer approves it.
+	ManualReview bool `protobuf:"varint,4,opt,name=manual_review,json=manualReview,proto3" json:"manual_review,omitempty"`
+	// Set when the verdict only depends on the amount, so it can be reused for other amounts of the same payment details.
+	// A verdict without limits holds for any amount.
+	Limits *PaymentLimits `protobuf:"bytes,5,opt,name=limits,proto3" json:"limits,omitempty"`
+}
+
+func (x *CheckComplianceResponse) Reset() {
+	*x = CheckComplianceResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckComplianceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckComplianceResponse) ProtoMessage() {}
+
+func (x *CheckComplianceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckComplianceResponse.ProtoReflect.Descriptor instead.
+func (*CheckComplianceResponse) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckComplianceResponse) GetCompliant() bool {
+	if x != nil {
+		return x.Compliant
+	}
+	return false
+}
+
+func (x *CheckComplianceResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CheckComplianceResponse) GetRiskRating() string {
+	if x != nil {
+		return x.RiskRating
+	}
+	return ""
+}
+
+func (x *CheckComplianceResponse) GetManualReview() bool {
+	if x != nil {
+		return x.ManualReview
+	}
+	return false
+}
+
+func (x *CheckComplianceResponse) GetLimits() *PaymentLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+// PaymentLimits are the amount limits of a user. A zero limit does not apply.
+type PaymentLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// KYC status of the user: unverified, pending, verified or rejected.
+	KycStatus string `protobuf:"bytes,1,opt,name=kyc_status,json=kycStatus,proto3" json:"kyc_status,omitempty"`
+	KycTier   int32  `protobuf:"varint,2,opt,name=kyc_tier,json=kycTier,proto3" json:"kyc_tier,omitempty"`
+	// Largest amount the KYC tier allows.
+	KycLimit float64 `protobuf:"fixed64,3,opt,name=kyc_limit,json=kycLimit,proto3" json:"kyc_limit,omitempty"`
+	// Largest amount a high-risk user may pay.
+	HighRiskLimit float64 `protobuf:"fixed64,4,opt,name=high_risk_limit,json=highRiskLimit,proto3" json:"high_risk_limit,omitempty"`
+	// Amount from which a payment of a high-risk user is held for manual review.
+	ReviewAmount float64 `protobuf:"fixed64,5,opt,name=review_amount,json=reviewAmount,proto3" json:"review_amount,omitempty"`
+}
+
+func (x *PaymentLimits) Reset() {
+	*x = PaymentLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaymentLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentLimits) ProtoMessage() {}
+
+func (x *PaymentLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentLimits.ProtoReflect.Descriptor instead.
+func (*PaymentLimits) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PaymentLimits) GetKycStatus() string {
+	if x != nil {
+		return x.KycStatus
+	}
+	return ""
+}
+
+func (x *PaymentLimits) GetKycTier() int32 {
+	if x != nil {
+		return x.KycTier
+	}
+	return 0
+}
+
+func (x *PaymentLimits) GetKycLimit() float64 {
+	if x != nil {
+		return x.KycLimit
+	}
+	return 0
+}
+
+func (x *PaymentLimits) GetHighRiskLimit() float64 {
+	if x != nil {
+		return x.HighRiskLimit
+	}
+	return 0
+}
+
+func (x *PaymentLimits) GetReviewAmount() float64 {
+	if x != nil {
+		return x.ReviewAmount
+	}
+	return 0
+}
+
+type CheckComplianceBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Checks []*CheckComplianceRequest `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+}
+
+func (x *CheckComplianceBatchRequest) Reset() {
+	*x = CheckComplianceBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckComplianceBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckComplianceBatchRequest) ProtoMessage() {}
+
+func (x *CheckComplianceBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckComplianceBatchRequest.ProtoReflect.Descriptor instead.
+func (*CheckComplianceBatchRequest) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckComplianceBatchRequest) GetChecks() []*CheckComplianceRequest {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+type CheckComplianceBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The verdicts, in the order of the checks.
+	Results []*CheckComplianceResponse `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *CheckComplianceBatchResponse) Reset() {
+	*x = CheckComplianceBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckComplianceBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckComplianceBatchResponse) ProtoMessage() {}
+
+func (x *CheckComplianceBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckComplianceBatchResponse.ProtoReflect.Descriptor instead.
+func (*CheckComplianceBatchResponse) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckComplianceBatchResponse) GetResults() []*CheckComplianceResponse {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type CaseTransaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TransactionId string  `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	CardId        int64   `protobuf:"varint,2,opt,name=card_id,json=cardId,proto3" json:"card_id,omitempty"`
+	Amount        float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// When the payment was made, unset if unknown.
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+}
+
+func (x *CaseTransaction) Reset() {
+	*x = CaseTransaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CaseTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaseTransaction) ProtoMessage() {}
+
+func (x *CaseTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaseTransaction.ProtoReflect.Descriptor instead.
+func (*CaseTransaction) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CaseTransaction) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *CaseTransaction) GetCardId() int64 {
+	if x != nil {
+		return x.CardId
+	}
+	return 0
+}
+
+func (x *CaseTransaction) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CaseTransaction) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+type RequestCardReviewRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId int64 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CardId int64 `protobuf:"varint,2,opt,name=card_id,json=cardId,proto3" json:"card_id,omitempty"`
+	// Why the review is requested, e.g. chargeback.
+	Source       string             `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Transactions []*CaseTransaction `protobuf:"bytes,4,rep,name=transactions,proto3" json:"transactions,omitempty"`
+}
+
+func (x *RequestCardReviewRequest) Reset() {
+	*x = RequestCardReviewRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestCardReviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestCardReviewRequest) ProtoMessage() {}
+
+func (x *RequestCardReviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestCardReviewRequest.ProtoReflect.Descriptor instead.
+func (*RequestCardReviewRequest) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RequestCardReviewRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RequestCardReviewRequest) GetCardId() int64 {
+	if x != nil {
+		return x.CardId
+	}
+	return 0
+}
+
+func (x *RequestCardReviewRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *RequestCardReviewRequest) GetTransactions() []*CaseTransaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type RequestCardReviewResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CaseId int64 `protobuf:"varint,1,opt,name=case_id,json=caseId,proto3" json:"case_id,omitempty"`
+}
+
+func (x *RequestCardReviewResponse) Reset() {
+	*x = RequestCardReviewResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestCardReviewResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestCardReviewResponse) ProtoMessage() {}
+
+func (x *RequestCardReviewResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestCardReviewResponse.ProtoReflect.Descriptor instead.
+func (*RequestCardReviewResponse) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RequestCardReviewResponse) GetCaseId() int64 {
+	if x != nil {
+		return x.CaseId
+	}
+	return 0
+}
+
+type ListBlockedCardsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListBlockedCardsRequest) Reset() {
+	*x = ListBlockedCardsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBlockedCardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlockedCardsRequest) ProtoMessage() {}
+
+func (x *ListBlockedCardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlockedCardsRequest.ProtoReflect.Descriptor instead.
+func (*ListBlockedCardsRequest) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{9}
+}
+
+type BlockedCard struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId int64 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CardId int64 `protobuf:"varint,2,opt,name=card_id,json=cardId,proto3" json:"card_id,omitempty"`
+}
+
+func (x *BlockedCard) Reset() {
+	*x = BlockedCard{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockedCard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockedCard) ProtoMessage() {}
+
+func (x *BlockedCard) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockedCard.ProtoReflect.Descriptor instead.
+func (*BlockedCard) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BlockedCard) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *BlockedCard) GetCardId() int64 {
+	if x != nil {
+		return x.CardId
+	}
+	return 0
+}
+
+type ListBlockedCardsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cards []*BlockedCard `protobuf:"bytes,1,rep,name=cards,proto3" json:"cards,omitempty"`
+}
+
+func (x *ListBlockedCardsResponse) Reset() {
+	*x = ListBlockedCardsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_v1_compliance_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBlockedCardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlockedCardsResponse) ProtoMessage() {}
+
+func (x *ListBlockedCardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_v1_compliance_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlockedCardsResponse.ProtoReflect.Descriptor instead.
+func (*ListBlockedCardsResponse) Descriptor() ([]byte, []int) {
+	return file_compliance_v1_compliance_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListBlockedCardsResponse) GetCards() []*BlockedCard {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+var File_compliance_v1_compliance_proto protoreflect.FileDescriptor
+
+var file_compliance_v1_compliance_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x2f,
+	0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0d, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x1a,
+	0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0x83, 0x01, 0x0a, 0x0e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x65, 0x72, 0x63, 0x68, 0x61, 0x6e,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x65, 0x72, 0x63,
+	0x68, 0x61, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x9b, 0x01, 0x0a, 0x16, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61,
+	0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x61, 0x72,
+	0x64, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x37, 0x0a, 0x07, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x63,
+	0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x79,
+	0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x07, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x78, 0x74, 0x22, 0xcd, 0x01, 0x0a, 0x17, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f,
+	0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x69, 0x73, 0x6b,
+	0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72,
+	0x69, 0x73, 0x6b, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x61, 0x6e,
+	0x75, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x6d, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x12, 0x34,
+	0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x06, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x22, 0xb3, 0x01, 0x0a, 0x0d, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x79, 0x63, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x79, 0x63, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x79, 0x63, 0x5f, 0x74, 0x69, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6b, 0x79, 0x63, 0x54, 0x69, 0x65, 0x72,
+	0x12, 0x1b, 0x0a, 0x09, 0x6b, 0x79, 0x63, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x6b, 0x79, 0x63, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x26, 0x0a,
+	0x0f, 0x68, 0x69, 0x67, 0x68, 0x5f, 0x72, 0x69, 0x73, 0x6b, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x68, 0x69, 0x67, 0x68, 0x52, 0x69, 0x73, 0x6b,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x5f,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x72, 0x65,
+	0x76, 0x69, 0x65, 0x77, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x5c, 0x0a, 0x1b, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3d, 0x0a, 0x06, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x63, 0x6f, 0x6d, 0x70,
+	0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43,
+	0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x52, 0x06, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x22, 0x60, 0x0a, 0x1c, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x63, 0x6f, 0x6d, 0x70,
+	0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43,
+	0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0xa6, 0x01, 0x0a, 0x0f, 0x43,
+	0x61, 0x73, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25,
+	0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x61, 0x72, 0x64, 0x49, 0x64, 0x12, 0x16,
+	0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x3b, 0x0a, 0x0b, 0x6f, 0x63, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x6f, 0x63, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x64, 0x41, 0x74, 0x22, 0xa8, 0x01, 0x0a, 0x18, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43,
+	0x61, 0x72, 0x64, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x72,
+	0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x61, 0x72, 0x64,
+	0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x42, 0x0a, 0x0c, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x73, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x34,
+	0x0a, 0x19, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x76,
+	0x69, 0x65, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x63,
+	0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x61,
+	0x73, 0x65, 0x49, 0x64, 0x22, 0x19, 0x0a, 0x17, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x65, 0x64, 0x43, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22,
+	0x3f, 0x0a, 0x0b, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x43, 0x61, 0x72, 0x64, 0x12, 0x17,
+	0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x72, 0x64, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x61, 0x72, 0x64, 0x49, 0x64,
+	0x22, 0x4c, 0x0a, 0x18, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x43,
+	0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x05,
+	0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x6f,
+	0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x65, 0x64, 0x43, 0x61, 0x72, 0x64, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x32, 0xb3,
+	0x03, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x60, 0x0a, 0x0f, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d,
+	0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x25, 0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69,
+	0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d,
+	0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26,
+	0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6f, 0x0a, 0x14, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43,
+	0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x2a,
+	0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x63, 0x6f, 0x6d,
+	0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x66, 0x0a, 0x11, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x12, 0x27, 0x2e, 0x63,
+	0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43, 0x61, 0x72,
+	0x64, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x63, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x43, 0x61,
+	0x72, 0x64, 0x73, 0x12, 0x26, 0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x43,
+	0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x63, 0x6f,
+	0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x43, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2c, 0x5a, 0x2a, 0x66, 0x6c, 0x61, 0x72, 0x72, 0x6f, 0x63, 0x63,
+	0x61, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x2f, 0x76, 0x31, 0x3b, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65,
+	0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_compliance_v1_compliance_proto_rawDescOnce sync.Once
+	file_compliance_v1_compliance_proto_rawDescData = file_compliance_v1_compliance_proto_rawDesc
+)
+
+func file_compliance_v1_compliance_proto_rawDescGZIP() []byte {
+	file_compliance_v1_compliance_proto_rawDescOnce.Do(func() {
+		file_compliance_v1_compliance_proto_rawDescData = protoimpl.X.CompressGZIP(file_compliance_v1_compliance_proto_rawDescData)
+	})
+	return file_compliance_v1_compliance_proto_rawDescData
+}
+
+var file_compliance_v1_compliance_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_compliance_v1_compliance_proto_goTypes = []any{
+	(*PaymentContext)(nil),               // 0: compliance.v1.PaymentContext
+	(*CheckComplianceRequest)(nil),       // 1: compliance.v1.CheckComplianceRequest
+	(*CheckComplianceResponse)(nil),      // 2: compliance.v1.CheckComplianceResponse
+	(*PaymentLimits)(nil),                // 3: compliance.v1.PaymentLimits
+	(*CheckComplianceBatchRequest)(nil),  // 4: compliance.v1.CheckComplianceBatchRequest
+	(*CheckComplianceBatchResponse)(nil), // 5: compliance.v1.CheckComplianceBatchResponse
+	(*CaseTransaction)(nil),              // 6: compliance.v1.CaseTransaction
+	(*RequestCardReviewRequest)(nil),     // 7: compliance.v1.RequestCardReviewRequest
+	(*RequestCardReviewResponse)(nil),    // 8: compliance.v1.RequestCardReviewResponse
+	(*ListBlockedCardsRequest)(nil),      // 9: compliance.v1.ListBlockedCardsRequest
+	(*BlockedCard)(nil),                  // 10: compliance.v1.BlockedCard
+	(*ListBlockedCardsResponse)(nil),     // 11: compliance.v1.ListBlockedCardsResponse
+	(*timestamppb.Timestamp)(nil),        // 12: google.protobuf.Timestamp
+}
+var file_compliance_v1_compliance_proto_depIdxs = []int32{
+	0,  // 0: compliance.v1.CheckComplianceRequest.context:type_name -> compliance.v1.PaymentContext
+	3,  // 1: compliance.v1.CheckComplianceResponse.limits:type_name -> compliance.v1.PaymentLimits
+	1,  // 2: compliance.v1.CheckComplianceBatchRequest.checks:type_name -> compliance.v1.CheckComplianceRequest
+	2,  // 3: compliance.v1.CheckComplianceBatchResponse.results:type_name -> compliance.v1.CheckComplianceResponse
+	12, // 4: compliance.v1.CaseTransaction.occurred_at:type_name -> google.protobuf.Timestamp
+	6,  // 5: compliance.v1.RequestCardReviewRequest.transactions:type_name -> compliance.v1.CaseTransaction
+	10, // 6: compliance.v1.ListBlockedCardsResponse.cards:type_name -> compliance.v1.BlockedCard
+	1,  // 7: compliance.v1.ComplianceService.CheckCompliance:input_type -> compliance.v1.CheckComplianceRequest
+	4,  // 8: compliance.v1.ComplianceService.CheckComplianceBatch:input_type -> compliance.v1.CheckComplianceBatchRequest
+	7,  // 9: compliance.v1.ComplianceService.RequestCardReview:input_type -> compliance.v1.RequestCardReviewRequest
+	9,  // 10: compliance.v1.ComplianceService.ListBlockedCards:input_type -> compliance.v1.ListBlockedCardsRequest
+	2,  // 11: compliance.v1.ComplianceService.CheckCompliance:output_type -> compliance.v1.CheckComplianceResponse
+	5,  // 12: compliance.v1.ComplianceService.CheckComplianceBatch:output_type -> compliance.v1.CheckComplianceBatchResponse
+	8,  // 13: compliance.v1.ComplianceService.RequestCardReview:output_type -> compliance.v1.RequestCardReviewResponse
+	11, // 14: compliance.v1.ComplianceService.ListBlockedCards:output_type -> compliance.v1.ListBlockedCardsResponse
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_compliance_v1_compliance_proto_init() }
+func file_compliance_v1_compliance_proto_init() {
+	if File_compliance_v1_compliance_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_compliance_v1_compliance_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*PaymentContext); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckComplianceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckComplianceResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*PaymentLimits); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckComplianceBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*CheckComplianceBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*CaseTransaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*RequestCardReviewRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*RequestCardReviewResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ListBlockedCardsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*BlockedCard); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_v1_compliance_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*ListBlockedCardsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_compliance_v1_compliance_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_compliance_v1_compliance_proto_goTypes,
+		DependencyIndexes: file_compliance_v1_compliance_proto_depIdxs,
+		MessageInfos:      file_compliance_v1_compliance_proto_msgTypes,
+	}.Build()
+	File_compliance_v1_compliance_proto = out.File
+	file_compliance_v1_compliance_proto_rawDesc = nil
+	file_compliance_v1_compliance_proto_goTypes = nil
+	file_compliance_v1_compliance_proto_depIdxs = nil
+}
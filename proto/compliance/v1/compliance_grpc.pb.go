@@ -0,0 +1,241 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: compliance/v1/compliance.proto
+
+// Version 1 of the API compliance-service exposes to payment-service. Fields may be added to the messages, but never
+// renumbered or removed; a breaking change goes in a new compliance.v2 package served next to this one.
+
+package compliancev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	ComplianceService_CheckCompliance_FullMethodName      = "/compliance.v1.ComplianceService/CheckCompliance"
+	ComplianceService_CheckComplianceBatch_FullMethodName = "/compliance.v1.ComplianceService/CheckComplianceBatch"
+	ComplianceService_RequestCardReview_FullMethodName    = "/compliance.v1.ComplianceService/RequestCardReview"
+	ComplianceService_ListBlockedCards_FullMethodName     = "/compliance.v1.ComplianceService/ListBlockedCards"
+)
+
+// ComplianceServiceClient is the client API for ComplianceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ComplianceServiceClient interface {
+	// CheckCompliance tells whether a payment can go through. A request the service cannot process is answered with
+	// a status other than OK: INVALID_ARGUMENT for malformed requests, INTERNAL when the check itself failed.
+	CheckCompliance(ctx context.Context, in *CheckComplianceRequest, opts ...grpc.CallOption) (*CheckComplianceResponse, error)
+	// CheckComplianceBatch checks many payments at once, e.g. for settlement batches and subscription billing runs.
+	// Each check is answered as by CheckCompliance, in the same order; up to 1000 checks are accepted.
+	CheckComplianceBatch(ctx context.Context, in *CheckComplianceBatchRequest, opts ...grpc.CallOption) (*CheckComplianceBatchResponse, error)
+	// RequestCardReview opens a case so the card used in a payment gets reviewed, e.g. after a chargeback.
+	RequestCardReview(ctx context.Context, in *RequestCardReviewRequest, opts ...grpc.CallOption) (*RequestCardReviewResponse, error)
+	// ListBlockedCards returns every card a payment would be denied for because it was reported stolen or blocked by a
+	// compromised card feed, so payment-service can keep a local snapshot for when compliance-service is down.
+	ListBlockedCards(ctx context.Context, in *ListBlockedCardsRequest, opts ...grpc.CallOption) (*ListBlockedCardsResponse, error)
+}
+
+type complianceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewComplianceServiceClient(cc grpc.ClientConnInterface) ComplianceServiceClient {
+	return &complianceServiceClient{cc}
+}
+
+func (c *complianceServiceClient) CheckCompliance(ctx context.Context, in *CheckComplianceRequest, opts ...grpc.CallOption) (*CheckComplianceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckComplianceResponse)
+	err := c.cc.Invoke(ctx, ComplianceService_CheckCompliance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *complianceServiceClient) CheckComplianceBatch(ctx context.Context, in *CheckComplianceBatchRequest, opts ...grpc.CallOption) (*CheckComplianceBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckComplianceBatchResponse)
+	err := c.cc.Invoke(ctx, ComplianceService_CheckComplianceBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *complianceServiceClient) RequestCardReview(ctx context.Context, in *RequestCardReviewRequest, opts ...grpc.CallOption) (*RequestCardReviewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestCardReviewResponse)
+	err := c.cc.Invoke(ctx, ComplianceService_RequestCardReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *complianceServiceClient) ListBlockedCards(ctx context.Context, in *ListBlockedCardsRequest, opts ...grpc.CallOption) (*ListBlockedCardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBlockedCardsResponse)
+	err := c.cc.Invoke(ctx, ComplianceService_ListBlockedCards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ComplianceServiceServer is the server API for ComplianceService service.
+// All implementations must embed UnimplementedComplianceServiceServer
+// for forward compatibility
+type ComplianceServiceServer interface {
+	// CheckCompliance tells whether a payment can go through. A request the service cannot process is answered with
+	// a status other than OK: INVALID_ARGUMENT for malformed requests, INTERNAL when the check itself failed.
+	CheckCompliance(context.Context, *CheckComplianceRequest) (*CheckComplianceResponse, error)
+	// CheckComplianceBatch checks many payments at once, e.g. for settlement batches and subscription billing runs.
+	// Each check is answered as by CheckCompliance, in the same order; up to 1000 checks are accepted.
+	CheckComplianceBatch(context.Context, *CheckComplianceBatchRequest) (*CheckComplianceBatchResponse, error)
+	// RequestCardReview opens a case so the card used in a payment gets reviewed, e.g. after a chargeback.
+	RequestCardReview(context.Context, *RequestCardReviewRequest) (*RequestCardReviewResponse, error)
+	// ListBlockedCards returns every card a payment would be denied for because it was reported stolen or blocked by a
+	// compromised card feed, so payment-service can keep a local snapshot for when compliance-service is down.
+	ListBlockedCards(context.Context, *ListBlockedCardsRequest) (*ListBlockedCardsResponse, error)
+	mustEmbedUnimplementedComplianceServiceServer()
+}
+
+// UnimplementedComplianceServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedComplianceServiceServer struct {
+}
+
+func (UnimplementedComplianceServiceServer) CheckCompliance(context.Context, *CheckComplianceRequest) (*CheckComplianceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckCompliance not implemented")
+}
+func (UnimplementedComplianceServiceServer) CheckComplianceBatch(context.Context, *CheckComplianceBatchRequest) (*CheckComplianceBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckComplianceBatch not implemented")
+}
+func (UnimplementedComplianceServiceServer) RequestCardReview(context.Context, *RequestCardReviewRequest) (*RequestCardReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestCardReview not implemented")
+}
+func (UnimplementedComplianceServiceServer) ListBlockedCards(context.Context, *ListBlockedCardsRequest) (*ListBlockedCardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlockedCards not implemented")
+}
+func (UnimplementedComplianceServiceServer) mustEmbedUnimplementedComplianceServiceServer() {}
+
+// UnsafeComplianceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ComplianceServiceServer will
+// result in compilation errors.
+type UnsafeComplianceServiceServer interface {
+	mustEmbedUnimplementedComplianceServiceServer()
+}
+
+func RegisterComplianceServiceServer(s grpc.ServiceRegistrar, srv ComplianceServiceServer) {
+	s.RegisterService(&ComplianceService_ServiceDesc, srv)
+}
+
+func _ComplianceService_CheckCompliance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckComplianceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComplianceServiceServer).CheckCompliance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ComplianceService_CheckCompliance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComplianceServiceServer).CheckCompliance(ctx, req.(*CheckComplianceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ComplianceService_CheckComplianceBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckComplianceBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComplianceServiceServer).CheckComplianceBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ComplianceService_CheckComplianceBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComplianceServiceServer).CheckComplianceBatch(ctx, req.(*CheckComplianceBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ComplianceService_RequestCardReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestCardReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComplianceServiceServer).RequestCardReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ComplianceService_RequestCardReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComplianceServiceServer).RequestCardReview(ctx, req.(*RequestCardReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ComplianceService_ListBlockedCards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlockedCardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComplianceServiceServer).ListBlockedCards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ComplianceService_ListBlockedCards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComplianceServiceServer).ListBlockedCards(ctx, req.(*ListBlockedCardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ComplianceService_ServiceDesc is the grpc.ServiceDesc for ComplianceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ComplianceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "compliance.v1.ComplianceService",
+	HandlerType: (*ComplianceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckCompliance",
+			Handler:    _ComplianceService_CheckCompliance_Handler,
+		},
+		{
+			MethodName: "CheckComplianceBatch",
+			Handler:    _ComplianceService_CheckComplianceBatch_Handler,
+		},
+		{
+			MethodName: "RequestCardReview",
+			Handler:    _ComplianceService_RequestCardReview_Handler,
+		},
+		{
+			MethodName: "ListBlockedCards",
+			Handler:    _ComplianceService_ListBlockedCards_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "compliance/v1/compliance.proto",
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes serves the webhook API of a service under /webhooks.
+func (h *Handler) RegisterRoutes(router fiber.Router) {
+	router.Post("/webhooks", h.RegisterEndpoint)
+	router.Get("/webhooks", h.ListEndpoints)
+	router.Delete("/webhooks/:id", h.DeleteEndpoint)
+	router.Get("/webhooks/deliveries", h.ListDeliveries)
+	router.Get("/webhooks/deliveries/:id", h.GetDelivery)
+	router.Post("/webhooks/deliveries/:id/redeliver", h.Redeliver)
+}
+
+func (h *Handler) RegisterEndpoint(c *fiber.Ctx) error {
+	var req struct {
+		URL        string   `json:"url"`
+		EventTypes []string `json:"event_types"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	endpoint, err := h.service.RegisterEndpoint(req.URL, req.EventTypes)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(endpoint)
+}
+
+func (h *Handler) ListEndpoints(c *fiber.Ctx) error {
+	endpoints, err := h.service.ListEndpoints()
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"endpoints": endpoints})
+}
+
+func (h *Handler) DeleteEndpoint(c *fiber.Ctx) error {
+	endpointID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for endpoint ID: %s", err))
+	}
+
+	if err := h.service.DeleteEndpoint(endpointID); err != nil {
+		return errorResponse(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ListDeliveries returns the delivery log, filtered by the status and endpoint_id query parameters.
+func (h *Handler) ListDeliveries(c *fiber.Ctx) error {
+	var endpointID int64
+	if param := c.Query("endpoint_id"); param != "" {
+		value, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for endpoint ID: %s", err))
+		}
+		endpointID = value
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Query("status"), endpointID)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return c.JSON(fiber.Map{"deliveries": deliveries})
+}
+
+func (h *Handler) GetDelivery(c *fiber.Ctx) error {
+	deliveryID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for delivery ID: %s", err))
+	}
+
+	delivery, err := h.service.GetDelivery(deliveryID)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return c.JSON(delivery)
+}
+
+func (h *Handler) Redeliver(c *fiber.Ctx) error {
+	deliveryID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(http.StatusBadRequest, fmt.Sprintf("invalid data type for delivery ID: %s", err))
+	}
+
+	delivery, err := h.service.Redeliver(deliveryID)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return c.Status(http.StatusAccepted).JSON(delivery)
+}
+
+func errorResponse(err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrEndpointNotFound), errors.Is(err, ErrDeliveryNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidEndpoint), errors.Is(err, ErrInvalidDeliveryQuery):
+		status = http.StatusBadRequest
+	}
+	return fiber.NewError(status, err.Error())
+}
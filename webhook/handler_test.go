@@ -0,0 +1,188 @@
+package webhook_test
+
+import (
+	"errors"
+	"flarrocca/webhook"
+	"flarrocca/webhook/mock"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	tests := []struct {
+		name       string
+		input      input
+		on         func(serviceMock *mock.MockService)
+		assertFunc func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:  "Success - Endpoint registered",
+			input: input{method: http.MethodPost, path: "/webhooks", body: `{"url": "https://partner.example/hooks", "event_types": ["CardBlocked"]}`},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().RegisterEndpoint("https://partner.example/hooks", []string{"CardBlocked"}).
+					Return(webhook.Endpoint{ID: 3, URL: "https://partner.example/hooks", EventTypes: []string{"CardBlocked"}, Secret: "whsec_test", CreatedAt: now}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"id":3,"url":"https://partner.example/hooks","event_types":["CardBlocked"],"secret":"whsec_test","created_at":"2025-03-01T10:00:00Z"}`, string(body))
+			},
+		},
+		{
+			name:  "Failure - Invalid endpoint",
+			input: input{method: http.MethodPost, path: "/webhooks", body: `{"url": "partner"}`},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().RegisterEndpoint("partner", nil).Return(webhook.Endpoint{}, fmt.Errorf("%w: url must be an http or https URL", webhook.ErrInvalidEndpoint))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Success - Endpoints listed",
+			input: input{method: http.MethodGet, path: "/webhooks"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().ListEndpoints().Return([]webhook.Endpoint{{ID: 3, URL: "https://partner.example/hooks", EventTypes: []string{}, CreatedAt: now}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, `{"endpoints":[{"id":3,"url":"https://partner.example/hooks","event_types":[],"created_at":"2025-03-01T10:00:00Z"}]}`, string(body))
+			},
+		},
+		{
+			name:  "Success - Endpoint deleted",
+			input: input{method: http.MethodDelete, path: "/webhooks/3"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().DeleteEndpoint(int64(3)).Return(nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Failure - Endpoint not found",
+			input: input{method: http.MethodDelete, path: "/webhooks/9"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().DeleteEndpoint(int64(9)).Return(webhook.ErrEndpointNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Success - Dead letters listed",
+			input: input{method: http.MethodGet, path: "/webhooks/deliveries?status=dead&endpoint_id=3"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().ListDeliveries(webhook.DeliveryStatusDead, int64(3)).
+					Return([]webhook.Delivery{{ID: 1, EndpointID: 3, EventID: "evt_42", EventType: "CardBlocked", Payload: []byte(`{}`), Status: "dead", Attempts: 8,
+						LastError: "connection refused", CreatedAt: now, UpdatedAt: now}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"status":"dead","attempts":8`)
+			},
+		},
+		{
+			name:  "Failure - Invalid delivery status",
+			input: input{method: http.MethodGet, path: "/webhooks/deliveries?status=lost"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().ListDeliveries("lost", int64(0)).Return(nil, fmt.Errorf("%w: status must be pending, delivered or dead", webhook.ErrInvalidDeliveryQuery))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Success - Delivery with its log",
+			input: input{method: http.MethodGet, path: "/webhooks/deliveries/1"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().GetDelivery(int64(1)).Return(webhook.Delivery{ID: 1, Status: "delivered", Payload: []byte(`{}`),
+					Log: []webhook.DeliveryAttempt{{Attempt: 1, StatusCode: 200, DurationMS: 12, AttemptedAt: now}}}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), `"log":[{"attempt":1,"status_code":200,"duration_ms":12,"attempted_at":"2025-03-01T10:00:00Z"}]`)
+			},
+		},
+		{
+			name:  "Success - Delivery redelivered",
+			input: input{method: http.MethodPost, path: "/webhooks/deliveries/1/redeliver"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().Redeliver(int64(1)).Return(webhook.Delivery{ID: 1, Status: "pending", Payload: []byte(`{}`)}, nil)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Failure - Redelivered delivery not found",
+			input: input{method: http.MethodPost, path: "/webhooks/deliveries/9/redeliver"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().Redeliver(int64(9)).Return(webhook.Delivery{}, webhook.ErrDeliveryNotFound)
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Failure - Invalid delivery ID",
+			input: input{method: http.MethodGet, path: "/webhooks/deliveries/abc"},
+			on:    func(serviceMock *mock.MockService) {},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "Failure - Service error",
+			input: input{method: http.MethodGet, path: "/webhooks"},
+			on: func(serviceMock *mock.MockService) {
+				serviceMock.EXPECT().ListEndpoints().Return(nil, errors.New("database is locked"))
+			},
+			assertFunc: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock.NewMockService(ctrl)
+			tt.on(serviceMock)
+
+			app := fiber.New()
+			webhook.NewHandler(serviceMock).RegisterRoutes(app)
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			tt.assertFunc(t, resp)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	webhook "flarrocca/webhook"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteEndpoint mocks base method.
+func (m *MockService) DeleteEndpoint(endpointID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEndpoint", endpointID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEndpoint indicates an expected call of DeleteEndpoint.
+func (mr *MockServiceMockRecorder) DeleteEndpoint(endpointID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEndpoint", reflect.TypeOf((*MockService)(nil).DeleteEndpoint), endpointID)
+}
+
+// GetDelivery mocks base method.
+func (m *MockService) GetDelivery(deliveryID int64) (webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelivery", deliveryID)
+	ret0, _ := ret[0].(webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelivery indicates an expected call of GetDelivery.
+func (mr *MockServiceMockRecorder) GetDelivery(deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelivery", reflect.TypeOf((*MockService)(nil).GetDelivery), deliveryID)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockService) ListDeliveries(status string, endpointID int64) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", status, endpointID)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockServiceMockRecorder) ListDeliveries(status, endpointID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockService)(nil).ListDeliveries), status, endpointID)
+}
+
+// ListEndpoints mocks base method.
+func (m *MockService) ListEndpoints() ([]webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEndpoints")
+	ret0, _ := ret[0].([]webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEndpoints indicates an expected call of ListEndpoints.
+func (mr *MockServiceMockRecorder) ListEndpoints() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEndpoints", reflect.TypeOf((*MockService)(nil).ListEndpoints))
+}
+
+// Publish mocks base method.
+func (m *MockService) Publish(eventID, eventType string, payload any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", eventID, eventType, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockServiceMockRecorder) Publish(eventID, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockService)(nil).Publish), eventID, eventType, payload)
+}
+
+// Redeliver mocks base method.
+func (m *MockService) Redeliver(deliveryID int64) (webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", deliveryID)
+	ret0, _ := ret[0].(webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockServiceMockRecorder) Redeliver(deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockService)(nil).Redeliver), deliveryID)
+}
+
+// RegisterEndpoint mocks base method.
+func (m *MockService) RegisterEndpoint(endpointURL string, eventTypes []string) (webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterEndpoint", endpointURL, eventTypes)
+	ret0, _ := ret[0].(webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterEndpoint indicates an expected call of RegisterEndpoint.
+func (mr *MockServiceMockRecorder) RegisterEndpoint(endpointURL, eventTypes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterEndpoint", reflect.TypeOf((*MockService)(nil).RegisterEndpoint), endpointURL, eventTypes)
+}
+
+// RunDispatcher mocks base method.
+func (m *MockService) RunDispatcher(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunDispatcher", ctx)
+}
+
+// RunDispatcher indicates an expected call of RunDispatcher.
+func (mr *MockServiceMockRecorder) RunDispatcher(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunDispatcher", reflect.TypeOf((*MockService)(nil).RunDispatcher), ctx)
+}
@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Run from the /webhook folder the following command to generate the mock:
+// mockgen -source repository.go -destination mock/repository_mock.go -package mock
+type Repository interface {
+	CreateEndpoint(endpoint Endpoint) (int64, error)
+	ListEndpoints() ([]Endpoint, error)
+	DeleteEndpoint(endpointID int64) error
+	CreateDeliveries(endpointIDs []int64, eventID string, eventType string, payload []byte, now time.Time) error
+	ListDueDeliveries(now time.Time, limit int) ([]Delivery, error)
+	RecordAttempt(deliveryID int64, attempt DeliveryAttempt, status string, nextAttemptAt *time.Time) error
+	ListDeliveries(status string, endpointID int64, limit int) ([]Delivery, error)
+	GetDelivery(deliveryID int64) (Delivery, error)
+	ResetDelivery(deliveryID int64, now time.Time) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateEndpoint(endpoint Endpoint) (int64, error) {
+	result, err := r.db.Exec("INSERT INTO webhook_endpoints (url, event_types, secret, created_at) VALUES (?, ?, ?, ?)",
+		endpoint.URL, strings.Join(endpoint.EventTypes, ","), endpoint.Secret, endpoint.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// ListEndpoints returns the endpoints not deleted, with their secrets.
+func (r *repository) ListEndpoints() ([]Endpoint, error) {
+	rows, err := r.db.Query("SELECT id, url, event_types, secret, created_at FROM webhook_endpoints WHERE active = 1 ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []Endpoint{}
+	for rows.Next() {
+		var endpoint Endpoint
+		var eventTypes string
+		if err := rows.Scan(&endpoint.ID, &endpoint.URL, &eventTypes, &endpoint.Secret, &endpoint.CreatedAt); err != nil {
+			return nil, err
+		}
+		endpoint.EventTypes = splitEventTypes(eventTypes)
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// DeleteEndpoint deactivates the endpoint, keeping its deliveries in the log. Its pending deliveries are not sent
+// anymore. It returns sql.ErrNoRows when there is no such endpoint.
+func (r *repository) DeleteEndpoint(endpointID int64) error {
+	result, err := r.db.Exec("UPDATE webhook_endpoints SET active = 0 WHERE id = ? AND active = 1", endpointID)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// CreateDeliveries queues the event for each endpoint, to be sent right away.
+func (r *repository) CreateDeliveries(endpointIDs []int64, eventID string, eventType string, payload []byte, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, endpointID := range endpointIDs {
+		if _, err := stmt.Exec(endpointID, eventID, eventType, string(payload), DeliveryStatusPending, now, now, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListDueDeliveries returns the oldest pending deliveries to send by now, to endpoints not deleted.
+func (r *repository) ListDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	return r.queryDeliveries(`SELECT `+deliveryColumns+` FROM webhook_deliveries d JOIN webhook_endpoints e ON e.id = d.endpoint_id
+		WHERE d.status = ? AND d.next_attempt_at <= ? AND e.active = 1 ORDER BY d.id LIMIT ?`, DeliveryStatusPending, now, limit)
+}
+
+// RecordAttempt adds the attempt to the delivery log and moves the delivery to its new status. nextAttemptAt is nil
+// once the delivery is delivered or dead.
+func (r *repository) RecordAttempt(deliveryID int64, attempt DeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at) VALUES (?, ?, ?, ?, ?, ?)",
+		deliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.DurationMS, attempt.AttemptedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, updated_at = ?
+		WHERE id = ?`, status, attempt.Attempt, nextAttemptAt, attempt.StatusCode, attempt.Error, attempt.AttemptedAt, deliveryID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListDeliveries returns the latest deliveries, only those with the status and to the endpoint when given.
+func (r *repository) ListDeliveries(status string, endpointID int64, limit int) ([]Delivery, error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries d WHERE 1 = 1"
+	var args []any
+	if status != "" {
+		query += " AND d.status = ?"
+		args = append(args, status)
+	}
+	if endpointID != 0 {
+		query += " AND d.endpoint_id = ?"
+		args = append(args, endpointID)
+	}
+	query += " ORDER BY d.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	return r.queryDeliveries(query, args...)
+}
+
+// GetDelivery returns the delivery with its attempts, or sql.ErrNoRows when there is no such delivery.
+func (r *repository) GetDelivery(deliveryID int64) (Delivery, error) {
+	delivery, err := scanDelivery(r.db.QueryRow("SELECT "+deliveryColumns+" FROM webhook_deliveries d WHERE d.id = ?", deliveryID))
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	rows, err := r.db.Query("SELECT attempt, status_code, error, duration_ms, attempted_at FROM webhook_delivery_attempts WHERE delivery_id = ? ORDER BY id", deliveryID)
+	if err != nil {
+		return Delivery{}, err
+	}
+	defer rows.Close()
+
+	delivery.Log = []DeliveryAttempt{}
+	for rows.Next() {
+		var attempt DeliveryAttempt
+		if err := rows.Scan(&attempt.Attempt, &attempt.StatusCode, &attempt.Error, &attempt.DurationMS, &attempt.AttemptedAt); err != nil {
+			return Delivery{}, err
+		}
+		delivery.Log = append(delivery.Log, attempt)
+	}
+
+	return delivery, rows.Err()
+}
+
+// ResetDelivery queues the delivery again with a fresh set of attempts, whatever its status. The previous attempts
+// stay in the log. It returns sql.ErrNoRows when there is no such delivery.
+func (r *repository) ResetDelivery(deliveryID int64, now time.Time) error {
+	result, err := r.db.Exec("UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?, updated_at = ? WHERE id = ?",
+		DeliveryStatusPending, now, now, deliveryID)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+const deliveryColumns = `d.id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_status_code,
+	d.last_error, d.created_at, d.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDelivery(row rowScanner) (Delivery, error) {
+	var delivery Delivery
+	var payload string
+	var nextAttemptAt sql.NullTime
+	if err := row.Scan(&delivery.ID, &delivery.EndpointID, &delivery.EventID, &delivery.EventType, &payload, &delivery.Status, &delivery.Attempts,
+		&nextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.UpdatedAt); err != nil {
+		return Delivery{}, err
+	}
+
+	delivery.Payload = []byte(payload)
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	return delivery, nil
+}
+
+func (r *repository) queryDeliveries(query string, args ...any) ([]Delivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func splitEventTypes(eventTypes string) []string {
+	if eventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(eventTypes, ",")
+}
+
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var deliveryRows = []string{"id", "endpoint_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at", "last_status_code",
+	"last_error", "created_at", "updated_at"}
+
+func TestEndpoints(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectExec(`INSERT INTO webhook_endpoints \(url, event_types, secret, created_at\) VALUES \(\?, \?, \?, \?\)`).
+		WithArgs("https://partner.example/hooks", "CardBlocked,UserLocked", "whsec_test", now).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	dbMock.ExpectQuery(`SELECT id, url, event_types, secret, created_at FROM webhook_endpoints WHERE active = 1 ORDER BY id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "event_types", "secret", "created_at"}).
+			AddRow(3, "https://partner.example/hooks", "CardBlocked,UserLocked", "whsec_test", now).
+			AddRow(4, "https://other.example/hooks", "", "whsec_other", now))
+	dbMock.ExpectExec(`UPDATE webhook_endpoints SET active = 0 WHERE id = \? AND active = 1`).WithArgs(int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(`UPDATE webhook_endpoints SET active = 0`).WithArgs(int64(3)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repository := NewRepository(db)
+
+	endpointID, err := repository.CreateEndpoint(Endpoint{URL: "https://partner.example/hooks", EventTypes: []string{"CardBlocked", "UserLocked"},
+		Secret: "whsec_test", CreatedAt: now})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), endpointID)
+
+	endpoints, err := repository.ListEndpoints()
+	assert.NoError(t, err)
+	assert.Equal(t, []Endpoint{
+		{ID: 3, URL: "https://partner.example/hooks", EventTypes: []string{"CardBlocked", "UserLocked"}, Secret: "whsec_test", CreatedAt: now},
+		{ID: 4, URL: "https://other.example/hooks", EventTypes: []string{}, Secret: "whsec_other", CreatedAt: now},
+	}, endpoints)
+
+	assert.NoError(t, repository.DeleteEndpoint(3))
+	assert.ErrorIs(t, repository.DeleteEndpoint(3), sql.ErrNoRows)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateDeliveries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		on         func(dbMock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name: "Success - Delivery queued for each endpoint",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`INSERT INTO webhook_deliveries \(endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at\)`)
+				stmt.ExpectExec().WithArgs(int64(3), "evt_42", "CardBlocked", `{"card_fingerprint":"abc"}`, DeliveryStatusPending, now, now, now).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				stmt.ExpectExec().WithArgs(int64(4), "evt_42", "CardBlocked", `{"card_fingerprint":"abc"}`, DeliveryStatusPending, now, now, now).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				dbMock.ExpectCommit()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Failure - Insert error rolls back",
+			on: func(dbMock sqlmock.Sqlmock) {
+				dbMock.ExpectBegin()
+				stmt := dbMock.ExpectPrepare(`INSERT INTO webhook_deliveries`)
+				stmt.ExpectExec().WithArgs(int64(3), "evt_42", "CardBlocked", `{"card_fingerprint":"abc"}`, DeliveryStatusPending, now, now, now).
+					WillReturnError(errors.New("database is locked"))
+				dbMock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "database is locked")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.on(dbMock)
+
+			err := NewRepository(db).CreateDeliveries([]int64{3, 4}, "evt_42", "CardBlocked", []byte(`{"card_fingerprint":"abc"}`), now)
+			tt.assertFunc(t, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeliveryLifecycle(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	retryAt := now.Add(30 * time.Second)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT d.id, (.+) FROM webhook_deliveries d JOIN webhook_endpoints e ON e.id = d.endpoint_id\s+WHERE d.status = \? AND d.next_attempt_at <= \? AND e.active = 1 ORDER BY d.id LIMIT \?`).
+		WithArgs(DeliveryStatusPending, now, 100).
+		WillReturnRows(sqlmock.NewRows(deliveryRows).AddRow(1, 3, "evt_42", "CardBlocked", `{"card_fingerprint":"abc"}`, "pending", 0, now, 0, "", now, now))
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`INSERT INTO webhook_delivery_attempts \(delivery_id, attempt, status_code, error, duration_ms, attempted_at\) VALUES \(\?, \?, \?, \?, \?, \?\)`).
+		WithArgs(int64(1), 1, 500, "unexpected status 500", int64(12), now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec(`UPDATE webhook_deliveries SET status = \?, attempts = \?, next_attempt_at = \?, last_status_code = \?, last_error = \?, updated_at = \?\s+WHERE id = \?`).
+		WithArgs(DeliveryStatusPending, 1, &retryAt, 500, "unexpected status 500", now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+	dbMock.ExpectExec(`UPDATE webhook_deliveries SET status = \?, attempts = 0, next_attempt_at = \?, updated_at = \? WHERE id = \?`).
+		WithArgs(DeliveryStatusPending, now, now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(`SELECT (.+) FROM webhook_deliveries d WHERE d.id = \?`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(deliveryRows).AddRow(1, 3, "evt_42", "CardBlocked", `{"card_fingerprint":"abc"}`, "pending", 0, now, 500, "unexpected status 500", now, now))
+	dbMock.ExpectQuery(`SELECT attempt, status_code, error, duration_ms, attempted_at FROM webhook_delivery_attempts WHERE delivery_id = \? ORDER BY id`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"attempt", "status_code", "error", "duration_ms", "attempted_at"}).AddRow(1, 500, "unexpected status 500", 12, now))
+	dbMock.ExpectExec(`UPDATE webhook_deliveries SET status = \?, attempts = 0`).
+		WithArgs(DeliveryStatusPending, now, now, int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repository := NewRepository(db)
+
+	deliveries, err := repository.ListDueDeliveries(now, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []Delivery{{ID: 1, EndpointID: 3, EventID: "evt_42", EventType: "CardBlocked", Payload: []byte(`{"card_fingerprint":"abc"}`),
+		Status: "pending", NextAttemptAt: &now, CreatedAt: now, UpdatedAt: now}}, deliveries)
+
+	assert.NoError(t, repository.RecordAttempt(1, DeliveryAttempt{Attempt: 1, StatusCode: 500, Error: "unexpected status 500", DurationMS: 12, AttemptedAt: now},
+		DeliveryStatusPending, &retryAt))
+	assert.NoError(t, repository.ResetDelivery(1, now))
+
+	delivery, err := repository.GetDelivery(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []DeliveryAttempt{{Attempt: 1, StatusCode: 500, Error: "unexpected status 500", DurationMS: 12, AttemptedAt: now}}, delivery.Log)
+
+	assert.ErrorIs(t, repository.ResetDelivery(9, now), sql.ErrNoRows)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestListDeliveries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	db, dbMock, _ := sqlmock.New()
+	defer db.Close()
+
+	dbMock.ExpectQuery(`SELECT (.+) FROM webhook_deliveries d WHERE 1 = 1 AND d.status = \? AND d.endpoint_id = \? ORDER BY d.id DESC LIMIT \?`).
+		WithArgs(DeliveryStatusDead, int64(3), 500).
+		WillReturnRows(sqlmock.NewRows(deliveryRows).AddRow(1, 3, "evt_42", "CardBlocked", `{}`, "dead", 8, nil, 0, "connection refused", now, now))
+	dbMock.ExpectQuery(`SELECT (.+) FROM webhook_deliveries d WHERE 1 = 1 ORDER BY d.id DESC LIMIT \?`).
+		WithArgs(500).
+		WillReturnRows(sqlmock.NewRows(deliveryRows))
+
+	repository := NewRepository(db)
+
+	deliveries, err := repository.ListDeliveries(DeliveryStatusDead, 3, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, []Delivery{{ID: 1, EndpointID: 3, EventID: "evt_42", EventType: "CardBlocked", Payload: []byte(`{}`), Status: "dead", Attempts: 8,
+		LastError: "connection refused", CreatedAt: now, UpdatedAt: now}}, deliveries)
+
+	deliveries, err = repository.ListDeliveries("", 0, 500)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
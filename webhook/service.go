@@ -0,0 +1,345 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxEndpointURLLength = 2048
+	maxDeliveryBatch     = 100
+	maxDeliveryList      = 500
+	maxRetryBackoff      = time.Hour
+
+	defaultMaxAttempts  = 8
+	defaultRetryBackoff = 30 * time.Second
+	defaultTimeout      = 5 * time.Second
+	defaultPollInterval = time.Second
+)
+
+var (
+	ErrEndpointNotFound     = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidEndpoint      = errors.New("invalid webhook endpoint")
+	ErrInvalidDeliveryQuery = errors.New("invalid webhook delivery query")
+)
+
+// Run from the /webhook folder the following command to generate the mock:
+// mockgen -source service.go -destination mock/service_mock.go -package mock
+type Service interface {
+	RegisterEndpoint(endpointURL string, eventTypes []string) (Endpoint, error)
+	ListEndpoints() ([]Endpoint, error)
+	DeleteEndpoint(endpointID int64) error
+	Publish(eventID string, eventType string, payload any) error
+	ListDeliveries(status string, endpointID int64) ([]Delivery, error)
+	GetDelivery(deliveryID int64) (Delivery, error)
+	Redeliver(deliveryID int64) (Delivery, error)
+	RunDispatcher(ctx context.Context)
+}
+
+type service struct {
+	repository   Repository
+	eventTypes   map[string]bool
+	client       *http.Client
+	maxAttempts  int
+	retryBackoff time.Duration
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+// NewService publishes the given event types. Deliveries are sent within WEBHOOK_TIMEOUT (default 5s) and retried up
+// to WEBHOOK_MAX_ATTEMPTS times (default 8), waiting WEBHOOK_RETRY_BACKOFF (default 30s) doubled after every failed
+// attempt, capped at an hour. The dispatcher looks for deliveries to send every WEBHOOK_POLL_INTERVAL (default 1s).
+func NewService(repository Repository, eventTypes []string) Service {
+	maxAttempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	known := map[string]bool{}
+	for _, eventType := range eventTypes {
+		known[eventType] = true
+	}
+
+	return &service{
+		repository:   repository,
+		eventTypes:   known,
+		client:       &http.Client{Timeout: durationFromEnv("WEBHOOK_TIMEOUT", defaultTimeout)},
+		maxAttempts:  maxAttempts,
+		retryBackoff: durationFromEnv("WEBHOOK_RETRY_BACKOFF", defaultRetryBackoff),
+		pollInterval: durationFromEnv("WEBHOOK_POLL_INTERVAL", defaultPollInterval),
+		now:          func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	duration, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || duration <= 0 {
+		return defaultValue
+	}
+	return duration
+}
+
+// RegisterEndpoint returns the endpoint with the secret its deliveries are signed with, which cannot be read again.
+func (s *service) RegisterEndpoint(endpointURL string, eventTypes []string) (Endpoint, error) {
+	endpointURL = strings.TrimSpace(endpointURL)
+	parsed, err := url.Parse(endpointURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(endpointURL) > maxEndpointURLLength {
+		return Endpoint{}, fmt.Errorf("%w: url must be an http or https URL of at most %d characters", ErrInvalidEndpoint, maxEndpointURLLength)
+	}
+
+	filter := []string{}
+	for _, eventType := range eventTypes {
+		if !s.eventTypes[eventType] {
+			return Endpoint{}, fmt.Errorf("%w: unknown event type %s", ErrInvalidEndpoint, eventType)
+		}
+		filter = append(filter, eventType)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return Endpoint{}, err
+	}
+
+	endpoint := Endpoint{URL: endpointURL, EventTypes: filter, Secret: "whsec_" + hex.EncodeToString(secret), CreatedAt: s.now()}
+	endpoint.ID, err = s.repository.CreateEndpoint(endpoint)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	return endpoint, nil
+}
+
+func (s *service) ListEndpoints() ([]Endpoint, error) {
+	endpoints, err := s.repository.ListEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+func (s *service) DeleteEndpoint(endpointID int64) error {
+	if err := s.repository.DeleteEndpoint(endpointID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEndpointNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Publish queues the event for every endpoint subscribed to its type. Receivers may get an event more than once and
+// should skip the event IDs they have already handled.
+func (s *service) Publish(eventID string, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := s.repository.ListEndpoints()
+	if err != nil {
+		return err
+	}
+
+	var endpointIDs []int64
+	for _, endpoint := range endpoints {
+		if subscribed(endpoint, eventType) {
+			endpointIDs = append(endpointIDs, endpoint.ID)
+		}
+	}
+
+	if len(endpointIDs) == 0 {
+		return nil
+	}
+	return s.repository.CreateDeliveries(endpointIDs, eventID, eventType, data, s.now())
+}
+
+func subscribed(endpoint Endpoint, eventType string) bool {
+	if len(endpoint.EventTypes) == 0 {
+		return true
+	}
+	for _, subscribedType := range endpoint.EventTypes {
+		if subscribedType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDeliveries returns the delivery log, newest first. The dead letters are listed with the dead status.
+func (s *service) ListDeliveries(status string, endpointID int64) ([]Delivery, error) {
+	switch status {
+	case "", DeliveryStatusPending, DeliveryStatusDelivered, DeliveryStatusDead:
+	default:
+		return nil, fmt.Errorf("%w: status must be %s, %s or %s", ErrInvalidDeliveryQuery, DeliveryStatusPending, DeliveryStatusDelivered, DeliveryStatusDead)
+	}
+
+	return s.repository.ListDeliveries(status, endpointID, maxDeliveryList)
+}
+
+func (s *service) GetDelivery(deliveryID int64) (Delivery, error) {
+	delivery, err := s.repository.GetDelivery(deliveryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Delivery{}, ErrDeliveryNotFound
+		}
+		return Delivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// Redeliver sends the delivery again on the next dispatch, with the same event ID, whether it was delivered or dead.
+func (s *service) Redeliver(deliveryID int64) (Delivery, error) {
+	if err := s.repository.ResetDelivery(deliveryID, s.now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Delivery{}, ErrDeliveryNotFound
+		}
+		return Delivery{}, err
+	}
+
+	return s.GetDelivery(deliveryID)
+}
+
+// RunDispatcher sends the due deliveries until ctx is done. It must run in a single process per database, as the
+// deliveries are not locked while being sent.
+func (s *service) RunDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatch()
+		}
+	}
+}
+
+// dispatch sends the due deliveries, one endpoint after the other in order and the endpoints in parallel, so a slow
+// endpoint does not hold back the others.
+func (s *service) dispatch() {
+	deliveries, err := s.repository.ListDueDeliveries(s.now(), maxDeliveryBatch)
+	if err != nil {
+		slog.Error("error listing the webhook deliveries to send", slog.Any("error", err))
+		return
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	endpoints, err := s.repository.ListEndpoints()
+	if err != nil {
+		slog.Error("error listing the webhook endpoints", slog.Any("error", err))
+		return
+	}
+
+	byEndpoint := map[int64][]Delivery{}
+	for _, delivery := range deliveries {
+		byEndpoint[delivery.EndpointID] = append(byEndpoint[delivery.EndpointID], delivery)
+	}
+
+	var wg sync.WaitGroup
+	for _, endpoint := range endpoints {
+		if len(byEndpoint[endpoint.ID]) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(endpoint Endpoint, deliveries []Delivery) {
+			defer wg.Done()
+			for _, delivery := range deliveries {
+				s.deliver(endpoint, delivery)
+			}
+		}(endpoint, byEndpoint[endpoint.ID])
+	}
+	wg.Wait()
+}
+
+// deliver sends the delivery once and records the outcome. Any answer other than 2xx is retried.
+func (s *service) deliver(endpoint Endpoint, delivery Delivery) {
+	body, err := json.Marshal(message{ID: delivery.EventID, Type: delivery.EventType, CreatedAt: delivery.CreatedAt, Data: delivery.Payload})
+	if err != nil {
+		slog.Error("error encoding webhook delivery", slog.Int64("delivery_id", delivery.ID), slog.Any("error", err))
+		return
+	}
+
+	attempt := DeliveryAttempt{Attempt: delivery.Attempts + 1, AttemptedAt: s.now()}
+	statusCode, err := s.post(endpoint, delivery, body, attempt.AttemptedAt)
+	attempt.DurationMS = s.now().Sub(attempt.AttemptedAt).Milliseconds()
+	attempt.StatusCode = statusCode
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("unexpected status %d", statusCode)
+	}
+
+	status := DeliveryStatusDelivered
+	var nextAttemptAt *time.Time
+	if err != nil {
+		attempt.Error = err.Error()
+		status = DeliveryStatusDead
+		if attempt.Attempt < s.maxAttempts {
+			status = DeliveryStatusPending
+			next := attempt.AttemptedAt.Add(s.backoff(attempt.Attempt))
+			nextAttemptAt = &next
+		} else {
+			slog.Warn("webhook delivery is dead", slog.Int64("delivery_id", delivery.ID), slog.String("event_id", delivery.EventID),
+				slog.Int64("endpoint_id", endpoint.ID), slog.Int("attempts", attempt.Attempt), slog.Any("error", err))
+		}
+	}
+
+	if err := s.repository.RecordAttempt(delivery.ID, attempt, status, nextAttemptAt); err != nil {
+		slog.Error("error recording webhook delivery attempt", slog.Int64("delivery_id", delivery.ID), slog.Int("attempt", attempt.Attempt),
+			slog.Any("error", err))
+	}
+}
+
+func (s *service) post(endpoint Endpoint, delivery Delivery, body []byte, timestamp time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drained so the connection is reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// backoff is the wait after the given failed attempt: the retry backoff doubled after each attempt, capped at an hour.
+func (s *service) backoff(attempt int) time.Duration {
+	backoff := s.retryBackoff
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
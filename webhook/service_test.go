@@ -0,0 +1,344 @@
+package webhook
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepository keeps the endpoints and deliveries in memory, so the dispatcher can be run against httptest receivers.
+type fakeRepository struct {
+	mu         sync.Mutex
+	endpoints  map[int64]Endpoint
+	deliveries map[int64]*Delivery
+	nextID     int64
+}
+
+func newFakeRepository(endpoints ...Endpoint) *fakeRepository {
+	repository := &fakeRepository{endpoints: map[int64]Endpoint{}, deliveries: map[int64]*Delivery{}}
+	for _, endpoint := range endpoints {
+		repository.endpoints[endpoint.ID] = endpoint
+	}
+	return repository
+}
+
+func (f *fakeRepository) CreateEndpoint(endpoint Endpoint) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	endpoint.ID = f.nextID
+	f.endpoints[endpoint.ID] = endpoint
+	return endpoint.ID, nil
+}
+
+func (f *fakeRepository) ListEndpoints() ([]Endpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	endpoints := []Endpoint{}
+	for _, endpoint := range f.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+	return endpoints, nil
+}
+
+func (f *fakeRepository) DeleteEndpoint(endpointID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, found := f.endpoints[endpointID]; !found {
+		return sql.ErrNoRows
+	}
+	delete(f.endpoints, endpointID)
+	return nil
+}
+
+func (f *fakeRepository) CreateDeliveries(endpointIDs []int64, eventID string, eventType string, payload []byte, now time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, endpointID := range endpointIDs {
+		f.nextID++
+		f.deliveries[f.nextID] = &Delivery{ID: f.nextID, EndpointID: endpointID, EventID: eventID, EventType: eventType, Payload: payload,
+			Status: DeliveryStatusPending, NextAttemptAt: &now, CreatedAt: now, UpdatedAt: now}
+	}
+	return nil
+}
+
+func (f *fakeRepository) ListDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	deliveries := []Delivery{}
+	for _, delivery := range f.deliveries {
+		if _, active := f.endpoints[delivery.EndpointID]; active && delivery.Status == DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (f *fakeRepository) RecordAttempt(deliveryID int64, attempt DeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delivery := f.deliveries[deliveryID]
+	delivery.Log = append(delivery.Log, attempt)
+	delivery.Status = status
+	delivery.Attempts = attempt.Attempt
+	delivery.NextAttemptAt = nextAttemptAt
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	return nil
+}
+
+func (f *fakeRepository) ListDeliveries(status string, endpointID int64, limit int) ([]Delivery, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeRepository) GetDelivery(deliveryID int64) (Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delivery, found := f.deliveries[deliveryID]
+	if !found {
+		return Delivery{}, sql.ErrNoRows
+	}
+	return *delivery, nil
+}
+
+func (f *fakeRepository) ResetDelivery(deliveryID int64, now time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delivery, found := f.deliveries[deliveryID]
+	if !found {
+		return sql.ErrNoRows
+	}
+	delivery.Status = DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	return nil
+}
+
+func newTestService(repository Repository, now *time.Time) *service {
+	return &service{
+		repository:   repository,
+		eventTypes:   map[string]bool{"CardBlocked": true, "UserLocked": true},
+		client:       &http.Client{Timeout: time.Second},
+		maxAttempts:  3,
+		retryBackoff: 30 * time.Second,
+		pollInterval: time.Millisecond,
+		now:          func() time.Time { return *now },
+	}
+}
+
+// receiver is a partner endpoint answering the given status codes in turn, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestRegisterEndpoint(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []string
+		assertFunc func(t *testing.T, endpoint Endpoint, err error)
+	}{
+		{
+			name:       "Success - Endpoint registered with a secret",
+			url:        " https://partner.example/hooks ",
+			eventTypes: []string{"CardBlocked"},
+			assertFunc: func(t *testing.T, endpoint Endpoint, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), endpoint.ID)
+				assert.Equal(t, "https://partner.example/hooks", endpoint.URL)
+				assert.Equal(t, []string{"CardBlocked"}, endpoint.EventTypes)
+				assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, endpoint.Secret)
+				assert.Equal(t, now, endpoint.CreatedAt)
+			},
+		},
+		{
+			name: "Success - No filter subscribes to every event",
+			url:  "http://localhost:9000/hooks",
+			assertFunc: func(t *testing.T, endpoint Endpoint, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{}, endpoint.EventTypes)
+			},
+		},
+		{
+			name: "Failure - Not an http URL",
+			url:  "ftp://partner.example/hooks",
+			assertFunc: func(t *testing.T, endpoint Endpoint, err error) {
+				assert.ErrorIs(t, err, ErrInvalidEndpoint)
+			},
+		},
+		{
+			name:       "Failure - Unknown event type",
+			url:        "https://partner.example/hooks",
+			eventTypes: []string{"PaymentApproved"},
+			assertFunc: func(t *testing.T, endpoint Endpoint, err error) {
+				assert.EqualError(t, err, "invalid webhook endpoint: unknown event type PaymentApproved")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, err := newTestService(newFakeRepository(), &now).RegisterEndpoint(tt.url, tt.eventTypes)
+			tt.assertFunc(t, endpoint, err)
+		})
+	}
+}
+
+func TestListEndpointsHidesSecrets(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	repository := newFakeRepository(Endpoint{ID: 1, URL: "https://partner.example/hooks", Secret: "whsec_test"})
+
+	endpoints, err := newTestService(repository, &now).ListEndpoints()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Endpoint{{ID: 1, URL: "https://partner.example/hooks"}}, endpoints)
+}
+
+func TestPublishAndDispatch(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	blocks, locks := &receiver{}, &receiver{}
+	blocksServer, locksServer := httptest.NewServer(blocks), httptest.NewServer(locks)
+	defer blocksServer.Close()
+	defer locksServer.Close()
+
+	repository := newFakeRepository(
+		Endpoint{ID: 1, URL: blocksServer.URL, EventTypes: []string{"CardBlocked"}, Secret: "whsec_blocks"},
+		Endpoint{ID: 2, URL: locksServer.URL, EventTypes: []string{"UserLocked"}, Secret: "whsec_locks"},
+	)
+	repository.nextID = 2
+	webhooks := newTestService(repository, &now)
+
+	assert.NoError(t, webhooks.Publish("evt_42", "CardBlocked", map[string]string{"card_fingerprint": "abc"}))
+	webhooks.dispatch()
+
+	assert.Len(t, blocks.requests, 1)
+	assert.Empty(t, locks.requests, "only the endpoints subscribed to the event get it")
+
+	req, body := blocks.requests[0], blocks.bodies[0]
+	assert.Equal(t, "evt_42", req.Header.Get(HeaderID))
+	assert.Equal(t, "CardBlocked", req.Header.Get(HeaderEvent))
+	assert.Equal(t, "1740823200", req.Header.Get(HeaderTimestamp))
+	assert.NoError(t, Verify("whsec_blocks", req.Header.Get(HeaderSignature), body, now, 5*time.Minute))
+	assert.JSONEq(t, `{"id":"evt_42","type":"CardBlocked","created_at":"2025-03-01T10:00:00Z","data":{"card_fingerprint":"abc"}}`, string(body))
+
+	delivery, err := webhooks.GetDelivery(3)
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryStatusDelivered, delivery.Status)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Equal(t, []DeliveryAttempt{{Attempt: 1, StatusCode: http.StatusOK, AttemptedAt: now}}, delivery.Log)
+}
+
+func TestDispatchRetriesThenDeadLetters(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	partner := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}}
+	server := httptest.NewServer(partner)
+	defer server.Close()
+
+	repository := newFakeRepository(Endpoint{ID: 1, URL: server.URL, Secret: "whsec_test"})
+	repository.nextID = 1
+	webhooks := newTestService(repository, &now)
+	assert.NoError(t, webhooks.Publish("evt_42", "UserLocked", map[string]any{"user_id": 2}))
+
+	webhooks.dispatch()
+	delivery, _ := webhooks.GetDelivery(2)
+	assert.Equal(t, DeliveryStatusPending, delivery.Status)
+	assert.Equal(t, now.Add(30*time.Second), *delivery.NextAttemptAt)
+
+	// not due yet
+	webhooks.dispatch()
+	assert.Len(t, partner.requests, 1)
+
+	now = now.Add(30 * time.Second)
+	webhooks.dispatch()
+	delivery, _ = webhooks.GetDelivery(2)
+	assert.Equal(t, now.Add(time.Minute), *delivery.NextAttemptAt, "the backoff doubles after each attempt")
+
+	now = now.Add(time.Minute)
+	webhooks.dispatch()
+	delivery, _ = webhooks.GetDelivery(2)
+	assert.Equal(t, DeliveryStatusDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Equal(t, "unexpected status 503", delivery.LastError)
+
+	now = now.Add(time.Hour)
+	webhooks.dispatch()
+	assert.Len(t, partner.requests, 3, "dead letters are not retried")
+
+	delivery, err := webhooks.Redeliver(2)
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryStatusPending, delivery.Status)
+	webhooks.dispatch()
+
+	delivery, _ = webhooks.GetDelivery(2)
+	assert.Equal(t, DeliveryStatusDelivered, delivery.Status)
+	assert.Len(t, delivery.Log, 4)
+
+	var first, last message
+	json.Unmarshal(partner.bodies[0], &first)
+	json.Unmarshal(partner.bodies[3], &last)
+	assert.Equal(t, first, last, "a redelivery sends the same event")
+}
+
+func TestDispatchUnreachableEndpoint(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(&receiver{})
+	server.Close()
+
+	repository := newFakeRepository(Endpoint{ID: 1, URL: server.URL, Secret: "whsec_test"})
+	repository.nextID = 1
+	webhooks := newTestService(repository, &now)
+	assert.NoError(t, webhooks.Publish("evt_42", "CardBlocked", map[string]string{}))
+
+	webhooks.dispatch()
+
+	delivery, _ := webhooks.GetDelivery(2)
+	assert.Equal(t, DeliveryStatusPending, delivery.Status)
+	assert.Zero(t, delivery.LastStatusCode)
+	assert.Contains(t, delivery.LastError, "connection refused")
+}
+
+func TestRedeliverNotFound(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	_, err := newTestService(newFakeRepository(), &now).Redeliver(9)
+
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+}
+
+func TestBackoff(t *testing.T) {
+	webhooks := &service{retryBackoff: 30 * time.Second}
+
+	assert.Equal(t, 30*time.Second, webhooks.backoff(1))
+	assert.Equal(t, 4*time.Minute, webhooks.backoff(4))
+	assert.Equal(t, time.Hour, webhooks.backoff(20))
+}
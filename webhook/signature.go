@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the Webhook-Signature header of a delivery: the HMAC-SHA256 of the timestamp and the body, keyed with
+// the endpoint secret, as t=<unix seconds>,v1=<hex>.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp.Unix(), signature(secret, timestamp.Unix(), body))
+}
+
+// Verify checks the Webhook-Signature header of a delivery received at now. Signatures older than tolerance are
+// rejected, so a captured delivery cannot be replayed later.
+func Verify(secret string, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+			}
+			timestamp = parsed
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == 0 || len(signatures) == 0 {
+		return fmt.Errorf("%w: timestamp and v1 signature are required", ErrInvalidSignature)
+	}
+
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp outside of the tolerance", ErrInvalidSignature)
+	}
+
+	expected := signature(secret, timestamp, body)
+	for _, candidate := range signatures {
+		if hmac.Equal([]byte(candidate), []byte(expected)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+}
+
+func signature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
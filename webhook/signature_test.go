@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature(t *testing.T) {
+	signedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"evt_42","type":"CardBlocked"}`)
+	header := Sign("whsec_test", signedAt, body)
+
+	assert.Equal(t, "t=1740823200,v1=", header[:16])
+
+	tests := []struct {
+		name       string
+		secret     string
+		header     string
+		body       []byte
+		receivedAt time.Time
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:       "Success - Signature verified",
+			secret:     "whsec_test",
+			header:     header,
+			body:       body,
+			receivedAt: signedAt.Add(time.Minute),
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:       "Success - One of several signatures verified during a secret rotation",
+			secret:     "whsec_test",
+			header:     "t=1740823200,v1=" + signature("whsec_old", 1740823200, body) + "," + header[2+len("1740823200,"):],
+			body:       body,
+			receivedAt: signedAt,
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:       "Failure - Body tampered with",
+			secret:     "whsec_test",
+			header:     header,
+			body:       []byte(`{"id":"evt_43","type":"CardBlocked"}`),
+			receivedAt: signedAt,
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+				assert.EqualError(t, err, "invalid webhook signature: signature mismatch")
+			},
+		},
+		{
+			name:       "Failure - Other secret",
+			secret:     "whsec_other",
+			header:     header,
+			body:       body,
+			receivedAt: signedAt,
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		{
+			name:       "Failure - Replayed too late",
+			secret:     "whsec_test",
+			header:     header,
+			body:       body,
+			receivedAt: signedAt.Add(6 * time.Minute),
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "invalid webhook signature: timestamp outside of the tolerance")
+			},
+		},
+		{
+			name:       "Failure - Missing signature",
+			secret:     "whsec_test",
+			header:     "t=1740823200",
+			body:       body,
+			receivedAt: signedAt,
+			assertFunc: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "invalid webhook signature: timestamp and v1 signature are required")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assertFunc(t, Verify(tt.secret, tt.header, tt.body, tt.receivedAt, 5*time.Minute))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package webhook notifies partners of the events of a service over HTTP. Both services register endpoints, publish
+// their events and redeliver failed ones through it, each storing its webhooks in the webhook_* tables of its own
+// database/init.sql.
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+)
+
+// Endpoint is a partner URL receiving the events of the given types, or every event when EventTypes is empty. Secret
+// signs the deliveries and is only shown when the endpoint is registered.
+type Endpoint struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Delivery is an event sent to an endpoint. A delivery failing MaxAttempts times is dead, kept until redelivered by
+// hand. Log holds the attempts and is only loaded by GetDelivery.
+type Delivery struct {
+	ID             int64             `json:"id"`
+	EndpointID     int64             `json:"endpoint_id"`
+	EventID        string            `json:"event_id"`
+	EventType      string            `json:"event_type"`
+	Payload        json.RawMessage   `json:"payload"`
+	Status         string            `json:"status"`
+	Attempts       int               `json:"attempts"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at,omitempty"`
+	LastStatusCode int               `json:"last_status_code,omitempty"`
+	LastError      string            `json:"last_error,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	Log            []DeliveryAttempt `json:"log,omitempty"`
+}
+
+// DeliveryAttempt is a request sent to an endpoint, with the status code it answered or the error when it did not.
+type DeliveryAttempt struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// message is the body of a delivery. It does not change across attempts, only the signature timestamp does.
+type message struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}